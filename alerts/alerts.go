@@ -0,0 +1,306 @@
+// Package alerts checks the rules of the [alerts] config at a fixed
+// interval. A condition sustained for the duration of its rule raises an
+// alert, sent to the sinks and as an events.AlertRaised event, and an
+// events.AlertResolved event once the condition is gone.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	// sinkTimeout is the time given to a sink to deliver an alert.
+	sinkTimeout = 30 * time.Second
+	// alertsBuffer is the number of alerts waiting for the sinks.
+	alertsBuffer = 64
+)
+
+type Level int
+
+const (
+	Warning Level = iota
+	Critical
+)
+
+func (l Level) String() string {
+	if l == Critical {
+		return "critical"
+	}
+	return "warning"
+}
+
+type Alert struct {
+	Rule     string
+	Key      string
+	Level    Level
+	Message  string
+	Since    time.Time
+	Resolved bool
+}
+
+// Condition is a problem found by a rule, identified by its key.
+type Condition struct {
+	Key     string
+	Level   Level
+	Message string
+	// For is the duration the condition must last to raise an alert.
+	For time.Duration
+}
+
+type Rule interface {
+	Name() string
+	// Check returns the conditions true at the time of the call.
+	Check(context.Context, *network.Network) ([]Condition, error)
+}
+
+// Watcher is a rule checked again as soon as it sends on changed,
+// between two intervals.
+type Watcher interface {
+	Watch(ctx context.Context, n *network.Network, changed chan<- struct{})
+}
+
+// Sink delivers the alerts, e.g. to the log or to a command.
+type Sink interface {
+	Notify(context.Context, *Alert) error
+}
+
+type pending struct {
+	since  time.Time
+	raised *Alert
+}
+
+type Manager struct {
+	logger   logging.Logger
+	network  *network.Network
+	interval time.Duration
+	rules    []Rule
+	sinks    []Sink
+	pending  map[string]*pending
+	// alerts are delivered to the sinks apart from the checks, a slow
+	// sink does not delay them.
+	alerts chan *Alert
+}
+
+func (m *Manager) AddRule(r Rule) {
+	m.rules = append(m.rules, r)
+}
+
+func (m *Manager) AddSink(s Sink) {
+	m.sinks = append(m.sinks, s)
+}
+
+// Run checks the rules until the context is done.
+func (m *Manager) Run(ctx context.Context, sub chan *events.Event) {
+	if len(m.rules) == 0 {
+		return
+	}
+
+	go m.deliver(ctx)
+
+	changed := make(chan struct{}, 1)
+	for _, rule := range m.rules {
+		if w, ok := rule.(Watcher); ok {
+			go w.Watch(ctx, m.network, changed)
+		}
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check(ctx, sub, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-changed:
+		}
+	}
+}
+
+func (m *Manager) check(ctx context.Context, sub chan *events.Event, now time.Time) {
+	seen := make(map[string]bool)
+	for i, rule := range m.rules {
+		// the index keeps the keys of rules of the same type apart.
+		prefix := fmt.Sprintf("%d:%s:", i, rule.Name())
+		conditions, err := rule.Check(ctx, m.network)
+		if err != nil {
+			m.logger.Error("alert rule failed",
+				logging.String("rule", rule.Name()), logging.Error(err))
+			// keep the state of the rule until the next check.
+			for key := range m.pending {
+				if strings.HasPrefix(key, prefix) {
+					seen[key] = true
+				}
+			}
+			continue
+		}
+
+		for _, c := range conditions {
+			key := prefix + c.Key
+			seen[key] = true
+			p, ok := m.pending[key]
+			if !ok {
+				p = &pending{since: now}
+				m.pending[key] = p
+			}
+			if p.raised == nil && now.Sub(p.since) >= c.For {
+				p.raised = &Alert{
+					Rule:    rule.Name(),
+					Key:     key,
+					Level:   c.Level,
+					Message: c.Message,
+					Since:   p.since,
+				}
+				m.notify(ctx, sub, events.AlertRaised, p.raised)
+			}
+		}
+	}
+
+	for key, p := range m.pending {
+		if seen[key] {
+			continue
+		}
+		delete(m.pending, key)
+		if p.raised != nil {
+			resolved := *p.raised
+			resolved.Resolved = true
+			m.notify(ctx, sub, events.AlertResolved, &resolved)
+		}
+	}
+}
+
+func (m *Manager) notify(ctx context.Context, sub chan *events.Event, kind string, a *Alert) {
+	select {
+	case m.alerts <- a:
+	default:
+		m.logger.Error("alert sinks too slow, alert dropped", logging.String("rule", a.Rule))
+	}
+
+	select {
+	case sub <- events.NewWithData(kind, a):
+	case <-ctx.Done():
+	}
+}
+
+// deliver sends the alerts to the sinks in their order until the context
+// is done, each sink has sinkTimeout to deliver an alert.
+func (m *Manager) deliver(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case a := <-m.alerts:
+			for _, s := range m.sinks {
+				sctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+				err := s.Notify(sctx, a)
+				cancel()
+				if err != nil {
+					m.logger.Error("alert sink failed", logging.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// New returns the manager of the rules and of the sinks of the config,
+// the requests of the sinks use the http config. An invalid rule or sink
+// is an error.
+func New(cfg config.Alerts, http config.HTTP, logger logging.Logger, network *network.Network) (*Manager, error) {
+	m := &Manager{
+		logger:   logger.With(logging.String("logger", "alerts")),
+		network:  network,
+		interval: defaultInterval,
+		pending:  make(map[string]*pending),
+		alerts:   make(chan *Alert, alertsBuffer),
+	}
+	if cfg.Interval > 0 {
+		m.interval = time.Duration(cfg.Interval) * time.Second
+	}
+
+	m.AddSink(&LogSink{logger: m.logger})
+	if len(cfg.Command) > 0 {
+		m.AddSink(&CommandSink{Command: cfg.Command})
+	}
+	if cfg.Webhook != "" {
+		s, err := NewWebhookSink(cfg.Webhook, http)
+		if err != nil {
+			return nil, err
+		}
+		m.AddSink(s)
+	}
+	telegram, err := NewTelegramSink(cfg.Telegram, http)
+	if err != nil {
+		return nil, err
+	}
+	if telegram != nil {
+		m.AddSink(telegram)
+	}
+
+	for i := range cfg.Liquidity {
+		r, err := NewLiquidityRule(cfg.Liquidity[i])
+		if err != nil {
+			return nil, err
+		}
+		m.AddRule(r)
+	}
+	peers, err := NewPeerOfflineRule(cfg.PeerOffline)
+	if err != nil {
+		return nil, err
+	}
+	if peers != nil {
+		m.AddRule(peers)
+	}
+	inactive, err := NewChannelInactiveRule(cfg.ChannelInactive)
+	if err != nil {
+		return nil, err
+	}
+	if inactive != nil {
+		m.AddRule(inactive)
+	}
+	disabled, err := NewPeerDisabledRule(cfg.PeerDisabled, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	if disabled != nil {
+		m.AddRule(disabled)
+	}
+	htlcs, err := NewHTLCFailuresRule(cfg.HTLCFailures, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	if htlcs != nil {
+		m.AddRule(htlcs)
+	}
+	if r := NewWalletBalanceRule(cfg.WalletBalance); r != nil {
+		m.AddRule(r)
+	}
+	if !cfg.ForceClose.Disabled {
+		m.AddRule(NewForceCloseRule(m.logger))
+	}
+
+	return m, nil
+}
+
+// parseDuration returns the duration of the config of the rule, zero if
+// it is empty, an invalid or a negative one is an error.
+func parseDuration(rule, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.Errorf("invalid %s duration %q", rule, s)
+	}
+	return d, nil
+}
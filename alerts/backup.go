@@ -0,0 +1,120 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/backup"
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/pubsub"
+)
+
+const (
+	// verifyTimeout and uploadTimeout are the deadlines of the verify and
+	// of the upload of a snapshot, a stuck one does not hold the next
+	// snapshots.
+	verifyTimeout = 30 * time.Second
+	uploadTimeout = 2 * time.Minute
+)
+
+// BackupRule verifies and uploads the channel backup snapshots sent by
+// the node, an alert is raised while the last snapshot failed.
+type BackupRule struct {
+	logger      logging.Logger
+	verify      bool
+	destination backup.Destination
+
+	mu      sync.Mutex
+	failure string
+}
+
+func (r *BackupRule) Name() string {
+	return "backup"
+}
+
+func (r *BackupRule) Watch(ctx context.Context, n *network.Network, changed chan<- struct{}) {
+	updates := make(chan *models.ChannelBackup)
+	go func() {
+		pubsub.Retry(ctx, r.logger, "SubscribeChannelBackups", func(ctx context.Context) error {
+			return n.SubscribeChannelBackups(ctx, updates)
+		}, nil)
+		close(updates)
+	}()
+
+	for snapshot := range updates {
+		failure := ""
+		err := r.process(ctx, n, snapshot)
+		if err != nil {
+			r.logger.Error("channel backup failed", logging.Error(err))
+			failure = err.Error()
+		} else {
+			r.logger.Info("channel backup done",
+				logging.Int("channels", len(snapshot.ChanPoints)))
+		}
+
+		r.mu.Lock()
+		r.failure = failure
+		r.mu.Unlock()
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *BackupRule) process(ctx context.Context, n *network.Network, snapshot *models.ChannelBackup) error {
+	if r.verify {
+		vctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+		err := n.VerifyChannelBackup(vctx, snapshot)
+		cancel()
+		if err != nil {
+			return errors.Wrap(err, "verify")
+		}
+	}
+	if r.destination == nil {
+		return nil
+	}
+	uctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+	err := r.destination.Upload(uctx, backup.FileName(time.Now()), snapshot.Multi)
+	if err != nil {
+		return errors.Wrap(err, "upload")
+	}
+	return nil
+}
+
+func (r *BackupRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failure == "" {
+		return nil, nil
+	}
+	return []Condition{{
+		Key:     "snapshot",
+		Level:   Warning,
+		Message: "channel backup " + r.failure,
+	}}, nil
+}
+
+// NewBackupRule returns the rule of the backup config, nil if the
+// snapshots are neither verified nor uploaded.
+func NewBackupRule(cfg config.Backup, httpCfg config.HTTP, logger logging.Logger) (*BackupRule, error) {
+	destination, err := backup.New(cfg, httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Verify && destination == nil {
+		return nil, nil
+	}
+	return &BackupRule{
+		logger:      logger.With(logging.String("logger", "backup")),
+		verify:      cfg.Verify,
+		destination: destination,
+	}, nil
+}
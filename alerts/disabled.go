@@ -0,0 +1,152 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/pubsub"
+)
+
+// PeerDisabledRule raises a warning when the peer of a channel keeps its
+// policy toward the node disabled for longer than the duration, it often
+// precedes a force close. The policies are fetched once by channel and
+// then followed in the graph updates.
+type PeerDisabledRule struct {
+	logger   logging.Logger
+	duration time.Duration
+	aliases  aliases
+
+	mu     sync.Mutex
+	pubkey string
+	// channels are the open channels of the node with the last policy of
+	// their peer.
+	channels map[string]*models.Channel
+}
+
+func (r *PeerDisabledRule) Name() string {
+	return "peer_disabled"
+}
+
+func (r *PeerDisabledRule) Watch(ctx context.Context, n *network.Network, changed chan<- struct{}) {
+	updates := make(chan *models.ChannelEdgeUpdate)
+	go func() {
+		pubsub.Retry(ctx, r.logger, "SubscribeGraphEvents", func(ctx context.Context) error {
+			return n.SubscribeGraphEvents(ctx, updates)
+		}, nil)
+		close(updates)
+	}()
+
+	for update := range updates {
+		r.mu.Lock()
+		result := false
+		for _, p := range update.Toward(r.pubkey) {
+			ch, ok := r.channels[p.ChanPoint]
+			if !ok || ch.RemotePolicy == nil || ch.RemotePolicy.Disabled == p.Policy.Disabled {
+				continue
+			}
+			policy := *p.Policy
+			ch.RemotePolicy = &policy
+			result = true
+		}
+		r.mu.Unlock()
+		if result {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (r *PeerDisabledRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	err := r.refreshChannels(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	disabled := []*models.Channel{}
+	for _, ch := range r.channels {
+		if ch.RemotePolicy != nil && ch.RemotePolicy.Disabled {
+			disabled = append(disabled, ch)
+		}
+	}
+	r.mu.Unlock()
+
+	conditions := []Condition{}
+	for _, ch := range disabled {
+		conditions = append(conditions, Condition{
+			Key:   ch.ChannelPoint,
+			Level: Warning,
+			Message: fmt.Sprintf("%s: channel %s disabled by the peer for more than %s",
+				r.aliases.get(ctx, n, ch), models.ToScid(ch.ID), r.duration),
+			For: r.duration,
+		})
+	}
+	return conditions, nil
+}
+
+// refreshChannels adds the channels opened since the last check with the
+// policy of their peer, and removes the closed ones.
+func (r *PeerDisabledRule) refreshChannels(ctx context.Context, n *network.Network) error {
+	if r.pubkey == "" {
+		info, err := n.Info(ctx)
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		r.pubkey = info.PubKey
+		r.mu.Unlock()
+	}
+
+	channels, err := n.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]*models.Channel, len(channels))
+	r.mu.Lock()
+	for _, ch := range channels {
+		if known, ok := r.channels[ch.ChannelPoint]; ok {
+			current[ch.ChannelPoint] = known
+		}
+	}
+	r.mu.Unlock()
+
+	for _, ch := range channels {
+		if _, ok := current[ch.ChannelPoint]; ok || ch.ID == 0 {
+			continue
+		}
+		err := n.GetChannelInfo(ctx, ch)
+		if err != nil {
+			return err
+		}
+		current[ch.ChannelPoint] = ch
+	}
+
+	r.mu.Lock()
+	r.channels = current
+	r.mu.Unlock()
+	return nil
+}
+
+// NewPeerDisabledRule returns the rule of the config, nil if it has no
+// duration.
+func NewPeerDisabledRule(cfg config.PeerDisabledAlert, logger logging.Logger) (*PeerDisabledRule, error) {
+	duration, err := parseDuration("peer_disabled", cfg.Duration)
+	if err != nil || duration == 0 {
+		return nil, err
+	}
+	return &PeerDisabledRule{
+		logger:   logger,
+		duration: duration,
+		aliases:  make(aliases),
+		channels: make(map[string]*models.Channel),
+	}, nil
+}
@@ -0,0 +1,258 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/pubsub"
+	"github.com/edouardparis/lntop/store"
+)
+
+// FeeChangeName is the name of the FeeChangeRule.
+const FeeChangeName = "fee_change"
+
+const (
+	defaultFeeMinIncrease = 100
+	defaultFeeDuration    = 24 * time.Hour
+)
+
+// feeRaise is a raise of the fee rate of a peer, from the rate before
+// the first raise of the alert.
+type feeRaise struct {
+	channel *models.Channel
+	from    int64
+	to      int64
+	at      time.Time
+}
+
+// FeeChangeRule watches the graph updates of the channels of the node,
+// records the changes of their policies, by the node or by the peers, in
+// the store and raises a warning when a peer raises its fee rate toward
+// the node by minIncrease ppm or more, kept for the duration unless
+// lowered back. Nothing is raised unless alert, the changes are still
+// recorded.
+type FeeChangeRule struct {
+	logger      logging.Logger
+	store       *store.Store
+	aliases     aliases
+	alert       bool
+	minIncrease int64
+	duration    time.Duration
+
+	mu sync.Mutex
+	// channels are the channels of the node with their last policies.
+	channels map[string]*models.Channel
+	raised   map[string]*feeRaise
+}
+
+func (r *FeeChangeRule) Name() string {
+	return FeeChangeName
+}
+
+func (r *FeeChangeRule) Watch(ctx context.Context, n *network.Network, changed chan<- struct{}) {
+	updates := make(chan *models.ChannelEdgeUpdate)
+	go func() {
+		pubsub.Retry(ctx, r.logger, "SubscribeGraphEvents", func(ctx context.Context) error {
+			return n.SubscribeGraphEvents(ctx, updates)
+		}, nil)
+		close(updates)
+	}()
+
+	for update := range updates {
+		if r.update(ctx, n, update.ChanPoints, time.Now()) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// update compares the policies of the channels of the node among the
+// channel points with the last ones, true if a raise changed.
+func (r *FeeChangeRule) update(ctx context.Context, n *network.Network, chanPoints []string, now time.Time) bool {
+	result := false
+	for _, point := range chanPoints {
+		r.mu.Lock()
+		old, ok := r.channels[point]
+		r.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		ch := *old
+		err := n.GetChannelInfo(ctx, &ch)
+		if err != nil {
+			r.logger.Error("GetChannelInfo returned an error", logging.Error(err))
+			continue
+		}
+		if policyChanged(old.LocalPolicy, ch.LocalPolicy) {
+			r.record(&store.PolicyChange{
+				ChannelPoint: point,
+				ChannelID:    ch.ID,
+				Local:        true,
+				Time:         now,
+				Old:          old.LocalPolicy,
+				New:          ch.LocalPolicy,
+			})
+		}
+		remote := policyChanged(old.RemotePolicy, ch.RemotePolicy)
+		if remote {
+			r.record(&store.PolicyChange{
+				ChannelPoint: point,
+				ChannelID:    ch.ID,
+				PubKey:       ch.RemotePubKey,
+				Time:         now,
+				Old:          old.RemotePolicy,
+				New:          ch.RemotePolicy,
+			})
+		}
+
+		r.mu.Lock()
+		r.channels[point] = &ch
+		if remote && r.alert && r.raise(point, &ch, old.RemotePolicy, ch.RemotePolicy, now) {
+			result = true
+		}
+		r.mu.Unlock()
+	}
+	return result
+}
+
+// raise updates the raise of the channel, true if it changed.
+func (r *FeeChangeRule) raise(point string, ch *models.Channel, old, new *models.RoutingPolicy, now time.Time) bool {
+	if old == nil || new == nil {
+		return false
+	}
+	from := old.FeeRateMilliMsat
+	if raised, ok := r.raised[point]; ok {
+		from = raised.from
+	}
+	if new.FeeRateMilliMsat-from < r.minIncrease {
+		_, ok := r.raised[point]
+		delete(r.raised, point)
+		return ok
+	}
+	r.raised[point] = &feeRaise{channel: ch, from: from, to: new.FeeRateMilliMsat, at: now}
+	return true
+}
+
+func (r *FeeChangeRule) record(c *store.PolicyChange) {
+	if r.store == nil {
+		return
+	}
+	err := r.store.AddPolicyChange(c)
+	if err != nil {
+		r.logger.Error("cannot record policy change", logging.Error(err))
+	}
+}
+
+func (r *FeeChangeRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	err := r.refreshChannels(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	raised := make(map[string]*feeRaise, len(r.raised))
+	now := time.Now()
+	for point, raise := range r.raised {
+		if now.Sub(raise.at) >= r.duration {
+			delete(r.raised, point)
+			continue
+		}
+		raised[point] = raise
+	}
+	r.mu.Unlock()
+
+	conditions := []Condition{}
+	for point, raise := range raised {
+		conditions = append(conditions, Condition{
+			Key:   point,
+			Level: Warning,
+			Message: fmt.Sprintf("%s: fee rate toward the node raised from %d to %d ppm on channel %s",
+				r.aliases.get(ctx, n, raise.channel), raise.from, raise.to, point),
+		})
+	}
+	return conditions, nil
+}
+
+// refreshChannels adds the channels opened since the last check with
+// their policies, and removes the closed ones.
+func (r *FeeChangeRule) refreshChannels(ctx context.Context, n *network.Network) error {
+	channels, err := n.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]*models.Channel, len(channels))
+	r.mu.Lock()
+	for _, ch := range channels {
+		if known, ok := r.channels[ch.ChannelPoint]; ok {
+			current[ch.ChannelPoint] = known
+		}
+	}
+	r.mu.Unlock()
+
+	for _, ch := range channels {
+		if _, ok := current[ch.ChannelPoint]; ok || ch.ID == 0 {
+			continue
+		}
+		err := n.GetChannelInfo(ctx, ch)
+		if err != nil {
+			return err
+		}
+		current[ch.ChannelPoint] = ch
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for point, ch := range r.channels {
+		// keep the policies updated by the graph updates meanwhile.
+		if _, ok := current[point]; ok {
+			current[point] = ch
+		}
+	}
+	r.channels = current
+	return nil
+}
+
+// policyChanged returns true if the policy changed, a policy announced
+// again without change is the same.
+func policyChanged(old, new *models.RoutingPolicy) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	o, n := *old, *new
+	o.LastUpdate, n.LastUpdate = time.Time{}, time.Time{}
+	return o != n
+}
+
+func NewFeeChangeRule(cfg config.FeeChangeAlert, logger logging.Logger, s *store.Store) (*FeeChangeRule, error) {
+	r := &FeeChangeRule{
+		logger:      logger,
+		store:       s,
+		aliases:     make(aliases),
+		alert:       !cfg.Disabled,
+		minIncrease: cfg.MinIncrease,
+		duration:    defaultFeeDuration,
+		channels:    make(map[string]*models.Channel),
+		raised:      make(map[string]*feeRaise),
+	}
+	if r.minIncrease <= 0 {
+		r.minIncrease = defaultFeeMinIncrease
+	}
+	d, err := parseDuration("fee_change", cfg.Duration)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Duration != "" {
+		r.duration = d
+	}
+	return r, nil
+}
@@ -0,0 +1,104 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+	"github.com/edouardparis/lntop/pubsub"
+)
+
+// ForceCloseName is the name of the ForceCloseRule.
+const ForceCloseName = "force_close"
+
+// ForceCloseRule raises a critical alert for the channels force closed,
+// found in the pending channels or in the close events of the channels.
+type ForceCloseRule struct {
+	logger  logging.Logger
+	aliases aliases
+
+	mu sync.Mutex
+	// closed are the force closes received from the events, reported
+	// once by the next check.
+	closed map[string]*models.ChannelUpdate
+}
+
+func (r *ForceCloseRule) Name() string {
+	return ForceCloseName
+}
+
+func (r *ForceCloseRule) Watch(ctx context.Context, n *network.Network, changed chan<- struct{}) {
+	updates := make(chan *models.ChannelUpdate)
+	go func() {
+		pubsub.Retry(ctx, r.logger, "SubscribeChannels", func(ctx context.Context) error {
+			return n.SubscribeChannels(ctx, updates)
+		}, nil)
+		close(updates)
+	}()
+
+	for update := range updates {
+		if !models.IsForceClose(update.CloseType) {
+			continue
+		}
+		r.mu.Lock()
+		r.closed[update.ChannelPoint] = update
+		r.mu.Unlock()
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *ForceCloseRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	channels, err := n.ListChannels(ctx, options.WithChannelPending)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []Condition{}
+	seen := make(map[string]bool)
+	for _, ch := range channels {
+		if ch.Status != models.ChannelForceClosing &&
+			!(ch.Status == models.ChannelWaitingClose && models.IsForceClose(ch.CloseType)) {
+			continue
+		}
+		seen[ch.ChannelPoint] = true
+		conditions = append(conditions, r.condition(ctx, n, ch, ch.CloseType))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for point, update := range r.closed {
+		if !seen[point] {
+			ch := &models.Channel{ChannelPoint: point, RemotePubKey: update.RemotePubKey}
+			conditions = append(conditions, r.condition(ctx, n, ch, update.CloseType))
+		}
+		delete(r.closed, point)
+	}
+	return conditions, nil
+}
+
+func (r *ForceCloseRule) condition(ctx context.Context, n *network.Network, ch *models.Channel, closeType int) Condition {
+	kind := "force closed"
+	if closeType != 0 {
+		kind = models.CloseTypeName(closeType) + " closed"
+	}
+	return Condition{
+		Key:     ch.ChannelPoint,
+		Level:   Critical,
+		Message: fmt.Sprintf("%s: channel %s %s", r.aliases.get(ctx, n, ch), ch.ChannelPoint, kind),
+	}
+}
+
+func NewForceCloseRule(logger logging.Logger) *ForceCloseRule {
+	return &ForceCloseRule{
+		logger:  logger,
+		aliases: make(aliases),
+		closed:  make(map[string]*models.ChannelUpdate),
+	}
+}
@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/pubsub"
+)
+
+const (
+	defaultHTLCWindow = time.Hour
+	defaultMinHTLCs   = 10
+)
+
+type forward struct {
+	at     time.Time
+	failed bool
+}
+
+// HTLCFailuresRule watches the routing events and raises an alert when
+// the percentage of the forwards failing over the window is above the
+// max rate, once the window counts enough forwards.
+type HTLCFailuresRule struct {
+	logger  logging.Logger
+	maxRate float64
+	window  time.Duration
+	min     int
+
+	mu sync.Mutex
+	// forwards are the settled and failed forwards of the window, oldest
+	// first.
+	forwards []forward
+}
+
+func (r *HTLCFailuresRule) Name() string {
+	return "htlc_failures"
+}
+
+func (r *HTLCFailuresRule) Watch(ctx context.Context, n *network.Network, changed chan<- struct{}) {
+	events := make(chan *models.RoutingEvent)
+	go func() {
+		pubsub.Retry(ctx, r.logger, "SubscribeRoutingEvents", func(ctx context.Context) error {
+			return n.SubscribeRoutingEvents(ctx, events)
+		}, nil)
+		close(events)
+	}()
+
+	for e := range events {
+		if e.Direction != models.RoutingForward || e.Status == models.RoutingStatusActive {
+			continue
+		}
+		r.mu.Lock()
+		r.forwards = append(r.forwards, forward{at: time.Now(), failed: e.Status != models.RoutingStatusSettled})
+		r.mu.Unlock()
+	}
+}
+
+func (r *HTLCFailuresRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start := time.Now().Add(-r.window)
+	i := 0
+	for i < len(r.forwards) && r.forwards[i].at.Before(start) {
+		i++
+	}
+	r.forwards = r.forwards[i:]
+
+	if len(r.forwards) < r.min {
+		return []Condition{}, nil
+	}
+	failed := 0
+	for _, f := range r.forwards {
+		if f.failed {
+			failed++
+		}
+	}
+	rate := float64(failed) * 100 / float64(len(r.forwards))
+	if rate <= r.maxRate {
+		return []Condition{}, nil
+	}
+	return []Condition{{
+		Key:   "forwards",
+		Level: Warning,
+		Message: fmt.Sprintf("%.0f%% of the %d forwards of the last %s failed",
+			rate, len(r.forwards), r.window),
+	}}, nil
+}
+
+// NewHTLCFailuresRule returns the rule of the config, nil if it has no
+// max rate.
+func NewHTLCFailuresRule(cfg config.HTLCFailuresAlert, logger logging.Logger) (*HTLCFailuresRule, error) {
+	if cfg.MaxRate <= 0 {
+		return nil, nil
+	}
+	window, err := parseDuration("htlc_failures window", cfg.Window)
+	if err != nil {
+		return nil, err
+	}
+	r := &HTLCFailuresRule{
+		logger:  logger,
+		maxRate: cfg.MaxRate,
+		window:  defaultHTLCWindow,
+		min:     defaultMinHTLCs,
+	}
+	if window > 0 {
+		r.window = window
+	}
+	if cfg.MinHTLCs > 0 {
+		r.min = cfg.MinHTLCs
+	}
+	return r, nil
+}
@@ -0,0 +1,54 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// ChannelInactiveRule raises an alert when a channel stays inactive for
+// longer than the duration, its peer may be connected.
+type ChannelInactiveRule struct {
+	duration time.Duration
+	aliases  aliases
+}
+
+func (r *ChannelInactiveRule) Name() string {
+	return "channel_inactive"
+}
+
+func (r *ChannelInactiveRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	channels, err := n.ListChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []Condition{}
+	for _, ch := range channels {
+		if ch.Status != models.ChannelInactive {
+			continue
+		}
+		conditions = append(conditions, Condition{
+			Key:   ch.ChannelPoint,
+			Level: Warning,
+			Message: fmt.Sprintf("%s: channel %s inactive for more than %s",
+				r.aliases.get(ctx, n, ch), models.ToScid(ch.ID), r.duration),
+			For: r.duration,
+		})
+	}
+	return conditions, nil
+}
+
+// NewChannelInactiveRule returns the rule of the config, nil if it has
+// no duration.
+func NewChannelInactiveRule(cfg config.ChannelInactiveAlert) (*ChannelInactiveRule, error) {
+	duration, err := parseDuration("channel_inactive", cfg.Duration)
+	if err != nil || duration == 0 {
+		return nil, err
+	}
+	return &ChannelInactiveRule{duration: duration, aliases: make(aliases)}, nil
+}
@@ -0,0 +1,112 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+// LiquidityRule raises an alert when the outbound or the inbound
+// liquidity of a channel is below a percentage of its capacity.
+type LiquidityRule struct {
+	cfg      config.LiquidityAlert
+	duration time.Duration
+	aliases  aliases
+}
+
+func (r *LiquidityRule) Name() string {
+	return "liquidity"
+}
+
+// matches returns true if the channel is the one of the rule, all the
+// channels match if the rule has no channel.
+func (r *LiquidityRule) matches(ch *models.Channel) bool {
+	switch r.cfg.Channel {
+	case "", "*":
+		return true
+	case ch.ChannelPoint, ch.RemotePubKey, strconv.FormatUint(ch.ID, 10), models.ToScid(ch.ID):
+		return true
+	}
+	return false
+}
+
+func (r *LiquidityRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	channels, err := n.ListChannels(ctx, options.WithChannelActive(true))
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []Condition{}
+	for _, ch := range channels {
+		if ch.Capacity == 0 || !r.matches(ch) {
+			continue
+		}
+
+		outbound := float64(ch.LocalBalance) * 100 / float64(ch.Capacity)
+		inbound := float64(ch.RemoteBalance) * 100 / float64(ch.Capacity)
+		if r.cfg.MinOutbound > 0 && outbound < r.cfg.MinOutbound {
+			conditions = append(conditions, Condition{
+				Key:   ch.ChannelPoint + ":outbound",
+				Level: Warning,
+				Message: fmt.Sprintf("%s: outbound liquidity %.1f%% below %.1f%%",
+					r.alias(ctx, n, ch), outbound, r.cfg.MinOutbound),
+				For: r.duration,
+			})
+		}
+		if r.cfg.MinInbound > 0 && inbound < r.cfg.MinInbound {
+			conditions = append(conditions, Condition{
+				Key:   ch.ChannelPoint + ":inbound",
+				Level: Warning,
+				Message: fmt.Sprintf("%s: inbound liquidity %.1f%% below %.1f%%",
+					r.alias(ctx, n, ch), inbound, r.cfg.MinInbound),
+				For: r.duration,
+			})
+		}
+	}
+	return conditions, nil
+}
+
+func (r *LiquidityRule) alias(ctx context.Context, n *network.Network, ch *models.Channel) string {
+	alias := r.aliases.get(ctx, n, ch)
+	if ch.ID == 0 {
+		return alias
+	}
+	return fmt.Sprintf("%s (%s)", alias, models.ToScid(ch.ID))
+}
+
+// aliases caches the aliases of the peers of the channels.
+type aliases map[string]string
+
+func (a aliases) get(ctx context.Context, n *network.Network, ch *models.Channel) string {
+	if ch.RemotePubKey == "" {
+		return ""
+	}
+	alias, ok := a[ch.RemotePubKey]
+	if !ok {
+		node, err := n.GetNode(ctx, ch.RemotePubKey, false)
+		if err == nil {
+			ch.Node = node
+		}
+		alias, _ = ch.ShortAlias()
+		a[ch.RemotePubKey] = alias
+	}
+	return alias
+}
+
+func NewLiquidityRule(cfg config.LiquidityAlert) (*LiquidityRule, error) {
+	duration, err := parseDuration("liquidity", cfg.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return &LiquidityRule{
+		cfg:      cfg,
+		duration: duration,
+		aliases:  make(aliases),
+	}, nil
+}
@@ -0,0 +1,106 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network"
+)
+
+// PeerOfflineRule raises an alert when the peer of a channel is not
+// connected for longer than the duration of the rule or of the peer.
+type PeerOfflineRule struct {
+	duration time.Duration
+	peers    map[string]time.Duration
+	disabled map[string]bool
+	aliases  aliases
+}
+
+func (r *PeerOfflineRule) Name() string {
+	return "peer_offline"
+}
+
+// durationOf returns the duration of the peer, zero if it is disabled.
+func (r *PeerOfflineRule) durationOf(pubkey string) time.Duration {
+	if r.disabled[pubkey] {
+		return 0
+	}
+	if d, ok := r.peers[pubkey]; ok {
+		return d
+	}
+	return r.duration
+}
+
+func (r *PeerOfflineRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	peers, err := n.ListPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	online := make(map[string]bool, len(peers))
+	for _, p := range peers {
+		online[p.PubKey] = true
+	}
+
+	channels, err := n.ListChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []Condition{}
+	seen := make(map[string]bool)
+	for _, ch := range channels {
+		if online[ch.RemotePubKey] || seen[ch.RemotePubKey] {
+			continue
+		}
+		seen[ch.RemotePubKey] = true
+
+		d := r.durationOf(ch.RemotePubKey)
+		if d == 0 {
+			continue
+		}
+		conditions = append(conditions, Condition{
+			Key:   ch.RemotePubKey,
+			Level: Warning,
+			Message: fmt.Sprintf("%s: peer offline for more than %s",
+				r.aliases.get(ctx, n, ch), d),
+			For: d,
+		})
+	}
+	return conditions, nil
+}
+
+// NewPeerOfflineRule returns the rule of the config, nil if it has
+// neither a duration nor peers.
+func NewPeerOfflineRule(cfg config.PeerOfflineAlert) (*PeerOfflineRule, error) {
+	duration, err := parseDuration("peer_offline", cfg.Duration)
+	if err != nil {
+		return nil, err
+	}
+	r := &PeerOfflineRule{
+		duration: duration,
+		peers:    make(map[string]time.Duration),
+		disabled: make(map[string]bool),
+		aliases:  make(aliases),
+	}
+	for pubkey, s := range cfg.Peers {
+		if s == "off" {
+			r.disabled[pubkey] = true
+			continue
+		}
+		d, err := parseDuration("peer_offline", s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "peer %s", pubkey)
+		}
+		if d > 0 {
+			r.peers[pubkey] = d
+		}
+	}
+	if r.duration == 0 && len(r.peers) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
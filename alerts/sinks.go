@@ -0,0 +1,160 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+)
+
+type LogSink struct {
+	logger logging.Logger
+}
+
+func (s *LogSink) Notify(ctx context.Context, a *Alert) error {
+	fields := []logging.Field{
+		logging.String("rule", a.Rule),
+		logging.String("level", a.Level.String()),
+	}
+	if a.Resolved {
+		s.logger.Info("alert resolved: "+a.Message, fields...)
+		return nil
+	}
+	s.logger.Info("alert: "+a.Message, fields...)
+	return nil
+}
+
+// CommandSink runs the command with the message as last argument, the
+// alert is also described by the LNTOP_ALERT_* environment variables.
+type CommandSink struct {
+	Command []string
+}
+
+func (s *CommandSink) Notify(ctx context.Context, a *Alert) error {
+	args := append(append([]string{}, s.Command[1:]...), a.Message)
+	cmd := exec.CommandContext(ctx, s.Command[0], args...)
+	cmd.Env = append(os.Environ(),
+		"LNTOP_ALERT_RULE="+a.Rule,
+		"LNTOP_ALERT_KEY="+a.Key,
+		"LNTOP_ALERT_LEVEL="+a.Level.String(),
+		"LNTOP_ALERT_STATUS="+status(a),
+	)
+	return errors.WithStack(cmd.Run())
+}
+
+// WebhookSink posts the alert to the URL as a JSON object.
+type WebhookSink struct {
+	url  string
+	http *http.Client
+}
+
+type webhookAlert struct {
+	Rule    string    `json:"rule"`
+	Key     string    `json:"key"`
+	Level   string    `json:"level"`
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+func (s *WebhookSink) Notify(ctx context.Context, a *Alert) error {
+	body, err := json.Marshal(webhookAlert{
+		Rule:    a.Rule,
+		Key:     a.Key,
+		Level:   a.Level.String(),
+		Status:  status(a),
+		Message: a.Message,
+		Since:   a.Since,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return post(ctx, s.http, s.url, "application/json", bytes.NewReader(body))
+}
+
+// NewWebhookSink returns the sink of the URL, the requests go through the
+// proxy of the http config.
+func NewWebhookSink(u string, cfg config.HTTP) (*WebhookSink, error) {
+	client, err := cfg.Client()
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSink{url: u, http: client}, nil
+}
+
+// TelegramSink sends the message of the alert to a chat with the bot of
+// the token.
+type TelegramSink struct {
+	token  string
+	chatID string
+	http   *http.Client
+}
+
+func (s *TelegramSink) Notify(ctx context.Context, a *Alert) error {
+	text := fmt.Sprintf("lntop %s: %s", a.Level, a.Message)
+	if a.Resolved {
+		text = "lntop resolved: " + a.Message
+	}
+	form := url.Values{"chat_id": {s.chatID}, "text": {text}}
+	return post(ctx, s.http, "https://api.telegram.org/bot"+s.token+"/sendMessage",
+		"application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+// NewTelegramSink returns the sink of the config, nil if it has no token.
+func NewTelegramSink(cfg config.TelegramAlert, httpCfg config.HTTP) (*TelegramSink, error) {
+	if cfg.Token == "" {
+		return nil, nil
+	}
+	if cfg.ChatID == "" {
+		return nil, errors.New("alerts.telegram requires a chat_id")
+	}
+	client, err := httpCfg.Client()
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramSink{token: cfg.Token, chatID: cfg.ChatID, http: client}, nil
+}
+
+func status(a *Alert) string {
+	if a.Resolved {
+		return "resolved"
+	}
+	return "raised"
+}
+
+// post sends the body to the URL, the errors do not contain the URL which
+// may hold a token.
+func post(ctx context.Context, client *http.Client, u, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return errors.New("invalid alert sink url")
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return errors.Errorf("%s: %s", req.URL.Hostname(), err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("%s: %s", req.URL.Hostname(), resp.Status)
+	}
+	return nil
+}
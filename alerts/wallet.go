@@ -0,0 +1,60 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network"
+)
+
+// WalletBalanceName is the name of the WalletBalanceRule, its alert is
+// kept in the header until it is resolved.
+const WalletBalanceName = "wallet_balance"
+
+// WalletBalanceRule raises an alert when the confirmed on-chain balance
+// is below the floor required to bump the fees of anchor channels and to
+// sweep the outputs of force-closes.
+type WalletBalanceRule struct {
+	min     int64
+	reserve bool
+}
+
+func (r *WalletBalanceRule) Name() string {
+	return WalletBalanceName
+}
+
+func (r *WalletBalanceRule) Check(ctx context.Context, n *network.Network) ([]Condition, error) {
+	balance, err := n.GetWalletBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []Condition
+	if balance.ConfirmedBalance < r.min {
+		conditions = append(conditions, Condition{
+			Key:   "confirmed",
+			Level: Warning,
+			Message: fmt.Sprintf("on-chain balance %d sat below %d sat",
+				balance.ConfirmedBalance, r.min),
+		})
+	}
+	if r.reserve && balance.ConfirmedBalance < balance.AnchorReserve {
+		conditions = append(conditions, Condition{
+			Key:   "anchor_reserve",
+			Level: Warning,
+			Message: fmt.Sprintf("on-chain balance %d sat below the anchor reserve of %d sat, force closes cannot be fee bumped",
+				balance.ConfirmedBalance, balance.AnchorReserve),
+		})
+	}
+	return conditions, nil
+}
+
+// NewWalletBalanceRule returns the rule of the config, nil if it has no
+// floor and does not check the anchor reserve.
+func NewWalletBalanceRule(cfg config.WalletBalanceAlert) *WalletBalanceRule {
+	if cfg.Min <= 0 && !cfg.AnchorReserve {
+		return nil
+	}
+	return &WalletBalanceRule{min: cfg.Min, reserve: cfg.AnchorReserve}
+}
@@ -1,15 +1,31 @@
+// Package app gathers the config, the logger and the network shared by
+// the ui, the pubsub and the commands.
 package app
 
 import (
 	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/firewall"
 	"github.com/edouardparis/lntop/logging"
 	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/store"
 )
 
+// App is the entry point of programs embedding lntop:
+//
+//	cfg, _ := config.Load("")
+//	a, _ := app.New(cfg)
+//	m := models.New(a)
+//	err := m.RefreshAllChannels(ctx)
 type App struct {
 	Config  *config.Config
 	Logger  logging.Logger
 	Network *network.Network
+	// Store is nil until OpenStore is called, or if no store is
+	// configured.
+	Store *store.Store
+	// Firewall is nil until OpenFirewall is called, or if the firewall
+	// is not enabled.
+	Firewall *firewall.Firewall
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -29,3 +45,68 @@ func New(cfg *config.Config) (*App, error) {
 		Network: network,
 	}, nil
 }
+
+// NewWithNetwork returns an App using the given logger and network, e.g.
+// a network wrapping a mock backend.
+func NewWithNetwork(cfg *config.Config, logger logging.Logger, network *network.Network) *App {
+	return &App{
+		Config:  cfg,
+		Logger:  logger,
+		Network: network,
+	}
+}
+
+// Nodes returns the apps of the other nodes of the config, each with the
+// network of its node and no store.
+func (a *App) Nodes() ([]*App, error) {
+	nodes := make([]*App, len(a.Config.Nodes))
+	for i := range a.Config.Nodes {
+		cfg := a.Config.NodeConfig(i)
+		logger := a.Logger.With(logging.String("node", cfg.Network.Name))
+		network, err := network.New(&cfg.Network, logger)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = NewWithNetwork(cfg, logger, network)
+	}
+	return nodes, nil
+}
+
+// OpenStore opens the store of the config, if any.
+func (a *App) OpenStore() error {
+	if a.Config.Store.Path == "" {
+		return nil
+	}
+	s, err := store.Open(a.Config.Store.Path)
+	if err != nil {
+		return err
+	}
+	a.Store = s
+	return nil
+}
+
+// OpenFirewall creates the firewall of the config, if it is enabled, it
+// intercepts the HTLCs of the node once it runs. There is none in
+// read-only mode, it resolves the HTLCs.
+func (a *App) OpenFirewall() error {
+	if a.Config.ReadOnly {
+		if a.Config.Firewall.Enabled {
+			a.Logger.Info("the firewall does not intercept the HTLCs in read-only mode")
+		}
+		return nil
+	}
+	f, err := firewall.New(a.Config.Firewall, a.Logger, a.Network)
+	if err != nil {
+		return err
+	}
+	a.Firewall = f
+	return nil
+}
+
+// Close closes the store.
+func (a *App) Close() error {
+	if a.Store == nil {
+		return nil
+	}
+	return a.Store.Close()
+}
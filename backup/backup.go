@@ -0,0 +1,98 @@
+// Package backup uploads the static channel backups (SCB) of the node to
+// the destination of the [backup] config: a local directory with
+// rotation, a scp target, a S3-compatible bucket or a http url.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	filePrefix = "channel-backup-"
+	fileSuffix = ".backup"
+)
+
+type Destination interface {
+	Upload(ctx context.Context, name string, data []byte) error
+}
+
+// FileName returns the name of the snapshot taken at t, the names sort
+// in the order of the snapshots.
+func FileName(t time.Time) string {
+	return fmt.Sprintf("%s%s%s", filePrefix, t.UTC().Format("20060102T150405Z"), fileSuffix)
+}
+
+// New returns the destination of the config, nil if it has no type. The
+// uploads to S3 and http go through the proxy of the http config.
+func New(cfg config.Backup, httpCfg config.HTTP) (Destination, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case "local":
+		if cfg.Path == "" {
+			return nil, errors.New("backup: missing local path")
+		}
+		return &Local{Dir: cfg.Path, Keep: cfg.Keep}, nil
+	case "scp":
+		if cfg.Path == "" {
+			return nil, errors.New("backup: missing scp target")
+		}
+		return &SCP{Target: cfg.Path}, nil
+	case "http":
+		if cfg.Path == "" {
+			return nil, errors.New("backup: missing http url")
+		}
+		return newHTTP(cfg.Path, httpCfg)
+	case "s3":
+		if cfg.Endpoint == "" || cfg.Bucket == "" {
+			return nil, errors.New("backup: missing s3 endpoint or bucket")
+		}
+		client, err := httpCfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		return &S3{
+			http:      client,
+			Endpoint:  cfg.Endpoint,
+			Bucket:    cfg.Bucket,
+			Region:    cfg.Region,
+			Prefix:    cfg.Path,
+			AccessKey: cfg.AccessKey,
+			SecretKey: cfg.SecretKey,
+		}, nil
+	}
+	return nil, errors.Errorf("backup: unknown destination type %q", cfg.Type)
+}
+
+func newHTTP(u string, httpCfg config.HTTP) (*HTTP, error) {
+	client, err := httpCfg.Client()
+	if err != nil {
+		return nil, err
+	}
+	return &HTTP{http: client, URL: u}, nil
+}
+
+// NewExport returns the destination of the backups exported on demand,
+// the one of the config if Export is empty. Export is a http(s) url, a
+// user@host:dir scp target or a local directory.
+func NewExport(cfg config.Backup, httpCfg config.HTTP) (Destination, error) {
+	target := cfg.Export
+	switch {
+	case target == "":
+		return New(cfg, httpCfg)
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return newHTTP(target, httpCfg)
+	}
+	// like scp, a colon before any slash is the one of a host.
+	if host, _, ok := strings.Cut(target, ":"); ok && !strings.Contains(host, "/") {
+		return &SCP{Target: target}, nil
+	}
+	return &Local{Dir: target}, nil
+}
@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Local copies the snapshots to a directory and removes the oldest ones
+// to keep at most Keep copies, all of them if Keep is zero.
+type Local struct {
+	Dir  string
+	Keep int
+}
+
+func (l *Local) Upload(ctx context.Context, name string, data []byte) error {
+	err := os.MkdirAll(l.Dir, 0700)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// write then rename, a copy is never partially written.
+	path := filepath.Join(l.Dir, name)
+	err = os.WriteFile(path+".tmp", data, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Rename(path+".tmp", path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return l.rotate()
+}
+
+func (l *Local) rotate() error {
+	if l.Keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), filePrefix) && strings.HasSuffix(e.Name(), fileSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) <= l.Keep {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-l.Keep] {
+		err = os.Remove(filepath.Join(l.Dir, name))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
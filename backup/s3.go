@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultRegion = "us-east-1"
+
+// S3 puts the snapshots in a bucket of a S3-compatible storage, the
+// requests are path-style and signed with AWS Signature Version 4.
+type S3 struct {
+	http      *http.Client
+	Endpoint  string
+	Bucket    string
+	Region    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+func (s *S3) Upload(ctx context.Context, name string, data []byte) error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	key := name
+	if s.Prefix != "" {
+		key = strings.Trim(s.Prefix, "/") + "/" + name
+	}
+	u := *endpoint
+	u.Path = "/" + s.Bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s.sign(req, u.EscapedPath(), data, time.Now())
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("s3 put %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (s *S3) sign(req *http.Request, path string, data []byte, now time.Time) {
+	region := s.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	date := now.UTC().Format("20060102T150405Z")
+	day := date[:8]
+	hash := sha256Hex(data)
+
+	req.Header.Set("x-amz-date", date)
+	req.Header.Set("x-amz-content-sha256", hash)
+
+	signed := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + hash,
+		"x-amz-date:" + date,
+		"",
+		signed,
+		hash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", day, region)
+	toSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", date, scope, sha256Hex([]byte(canonical)),
+	}, "\n")
+
+	k := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, "s3")
+	k = hmacSHA256(k, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(k, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signed, signature))
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
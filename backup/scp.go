@@ -0,0 +1,38 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SCP copies the snapshots with the scp command to Target, user@host:dir,
+// the authentication is the one of the ssh config of the user.
+type SCP struct {
+	Target string
+}
+
+func (s *SCP) Upload(ctx context.Context, name string, data []byte) error {
+	dir, err := os.MkdirTemp("", "lntop-backup")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, name)
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	target := strings.TrimSuffix(s.Target, "/") + "/" + name
+	out, err := exec.CommandContext(ctx, "scp", "-q", "-B", path, target).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("scp %s: %v: %s", target, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
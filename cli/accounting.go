@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/export"
+	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+	"github.com/edouardparis/lntop/price"
+)
+
+// exportRun writes the movements of the funds of the node as the
+// accounting CSV of the format.
+func exportRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	since, err := parseRange(c.String("since"))
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(c.String("timezone"))
+	if err != nil {
+		return errors.Errorf("invalid timezone %q", c.String("timezone"))
+	}
+
+	ctx := context.Background()
+	info, err := app.Network.Info(ctx)
+	if err != nil {
+		return err
+	}
+	txs, err := app.Network.GetTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	channels, err := app.Network.ListChannels(ctx, options.WithChannelPending)
+	if err != nil {
+		return err
+	}
+	closed, err := app.Network.ClosedChannels(ctx)
+	if err != nil {
+		return err
+	}
+	invoices, payments, err := nodeHistory(ctx, app.Network.Backend)
+	if err != nil {
+		return err
+	}
+	start := "0"
+	if since > 0 {
+		start = strconv.FormatInt(time.Now().Add(-since).Unix(), 10)
+	}
+	forwards, err := app.Network.GetForwardingHistory(ctx, start, 0)
+	if err != nil {
+		return err
+	}
+
+	entries := export.NewEntries(txs, channels, closed, invoices, payments, forwards)
+	if since > 0 {
+		from := time.Now().Add(-since)
+		kept := entries[:0]
+		for _, e := range entries {
+			if !e.Time.Before(from) {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+
+	accounting := export.Accounting{
+		Format:   c.String("format"),
+		Location: loc,
+		Wallet:   info.Alias,
+	}
+	if c.Bool("fiat") {
+		cfg := app.Config.Price
+		if currency := c.String("currency"); currency != "" {
+			cfg.Currency = currency
+		}
+		if cfg.Provider == "" {
+			return errors.New("the fiat values require the provider of the price config")
+		}
+		provider, err := price.New(cfg, app.Config.HTTP)
+		if err != nil {
+			return err
+		}
+		history, ok := provider.(price.History)
+		if !ok {
+			return errors.Errorf("the price provider %s has no history", cfg.Provider)
+		}
+		missing := 0
+		for _, e := range entries {
+			e.Rate, err = history.RateAt(ctx, e.Time)
+			if errors.Is(err, price.ErrNoRate) {
+				missing++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if missing > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %d entries without price in %s of their day have no fiat value\n",
+				missing, provider.Currency())
+		}
+		accounting.Currency = strings.ToUpper(provider.Currency())
+	}
+
+	var w io.Writer = os.Stdout
+	if path := c.String("out"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	return accounting.Write(w, entries)
+}
+
+// nodeHistory returns all the invoices and the payments of the node, not
+// only the last ones of the lists of the ui.
+func nodeHistory(ctx context.Context, b backend.Backend) ([]*models.Invoice, []*models.Payment, error) {
+	h, ok := b.(backend.History)
+	if !ok {
+		invoices, err := b.ListInvoices(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		payments, err := b.ListPayments(ctx)
+		return invoices, payments, err
+	}
+	invoices, err := h.AllInvoices(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	payments, err := h.AllPayments(ctx)
+	return invoices, payments, err
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	cli "gopkg.in/urfave/cli.v2"
 
+	"github.com/edouardparis/lntop/alerts"
 	"github.com/edouardparis/lntop/app"
 	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/export"
 	"github.com/edouardparis/lntop/logging"
+	netmodels "github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/pubsub"
 	"github.com/edouardparis/lntop/ui"
 )
@@ -34,6 +39,47 @@ func New(version string) *cli.App {
 				Aliases: []string{"c"},
 				Usage:   "path to config file",
 			},
+			&cli.StringFlag{
+				Name:  "network",
+				Usage: "connect with the profile of the `NETWORK` of the config, e.g. testnet",
+			},
+			&cli.BoolFlag{
+				Name:  "demo",
+				Usage: "run against a generated node instead of the node of the config",
+			},
+			&cli.BoolFlag{
+				Name:  "read-only",
+				Usage: "disable the actions changing the node, e.g. opening a channel or paying",
+			},
+			&cli.BoolFlag{
+				Name:  "headless",
+				Usage: "run without the ui and write the events of the node as JSON lines to stdout",
+			},
+			&cli.StringFlag{
+				Name:  "events-socket",
+				Usage: "with --headless, write the events to the clients of the unix socket at `PATH` instead",
+			},
+			&cli.IntFlag{
+				Name:   "load-routing",
+				Usage:  "inject `N` synthetic routing events per second, for benchmarks",
+				Hidden: true,
+			},
+			&cli.IntFlag{
+				Name:   "load-graph",
+				Usage:  "inject `N` synthetic graph updates per second, for benchmarks",
+				Hidden: true,
+			},
+			&cli.DurationFlag{
+				Name:   "load-burst",
+				Usage:  "duration of the bursts of the synthetic load, continuous if zero",
+				Hidden: true,
+			},
+			&cli.DurationFlag{
+				Name:   "load-pause",
+				Usage:  "duration between two bursts of the synthetic load",
+				Value:  10 * time.Second,
+				Hidden: true,
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -42,54 +88,574 @@ func New(version string) *cli.App {
 				Usage:   "run the pubsub only",
 				Action:  pubsubRun,
 			},
+			{
+				Name:   "channels",
+				Usage:  "print the channels and exit",
+				Action: channelsRun,
+				Flags: []cli.Flag{
+					jsonFlag,
+					&cli.StringFlag{
+						Name:  "view",
+						Usage: "print the columns of the channels view of the config as `FORMAT`, csv or json",
+					},
+				},
+			},
+			{
+				Name:   "forwards",
+				Usage:  "print the forwarding history and exit",
+				Action: forwardsRun,
+				Flags: []cli.Flag{
+					jsonFlag,
+					&cli.StringFlag{
+						Name:  "start-time",
+						Usage: "start time of the history, e.g. \"-24h\" or a unix timestamp",
+					},
+					&cli.IntFlag{
+						Name:  "max",
+						Usage: "maximum number of forwarding events",
+					},
+				},
+			},
+			{
+				Name:   "peers",
+				Usage:  "print the connected peers and exit",
+				Action: peersRun,
+				Flags:  []cli.Flag{jsonFlag},
+			},
+			{
+				Name:   "closed",
+				Usage:  "print the closed channels with their breakdown by close type and exit",
+				Action: closedRun,
+				Flags: []cli.Flag{
+					jsonFlag,
+					&cli.StringFlag{
+						Name:  "since",
+						Value: "30d",
+						Usage: "time range of the closes, e.g. \"7d\", \"12h\" or \"all\"",
+					},
+				},
+			},
+			{
+				Name:   "export",
+				Usage:  "export the transactions, invoices, payments and forwarding fees as accounting CSV and exit",
+				Action: exportRun,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "generic",
+						Usage: "`FORMAT` of the CSV, generic, koinly or cointracking",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Value: "all",
+						Usage: "time range of the entries, e.g. \"365d\" or \"all\"",
+					},
+					&cli.StringFlag{
+						Name:  "timezone",
+						Value: "UTC",
+						Usage: "time zone of the dates, e.g. \"Europe/Paris\" or \"Local\"",
+					},
+					&cli.BoolFlag{
+						Name:  "fiat",
+						Usage: "add the fiat values at the price of the day of the provider of the price config",
+					},
+					&cli.StringFlag{
+						Name:  "currency",
+						Usage: "fiat currency of the values, defaults to the one of the price config",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "write the CSV to the file at `PATH` instead of stdout",
+					},
+				},
+			},
+			{
+				Name:   "utxos",
+				Usage:  "print the unspent outputs of the wallet with their labels and exit",
+				Action: utxosRun,
+				Flags:  []cli.Flag{jsonFlag},
+			},
+			{
+				Name:   "watch",
+				Usage:  "exit once the expression of --until is true, 1 on timeout and 2 on error",
+				Action: watchRun,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "condition on the node, e.g. \"channel_active('bluewhale')\"",
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Value: 5 * time.Second,
+						Usage: "duration between two checks of the condition",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "maximum duration of the watch, none if zero",
+					},
+				},
+			},
+			{
+				Name:      "label",
+				Usage:     "label the transaction of an output of the wallet",
+				ArgsUsage: "<txid|outpoint> <label>",
+				Action:    mutating(labelRun),
+			},
+			{
+				Name:      "send",
+				Usage:     "send on chain spending the chosen outputs, after confirmation",
+				ArgsUsage: "<address>",
+				Action:    mutating(sendRun),
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "amount",
+						Usage: "amount in satoshis",
+					},
+					feeRateFlag,
+					utxoFlag,
+					&cli.BoolFlag{
+						Name:  "yes",
+						Usage: "do not ask for confirmation",
+					},
+				},
+			},
+			{
+				Name:      "open",
+				Usage:     "open a channel with a connected peer funded by the chosen outputs, after confirmation",
+				ArgsUsage: "<pubkey>",
+				Action:    mutating(openRun),
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "amount",
+						Usage: "capacity of the channel in satoshis",
+					},
+					feeRateFlag,
+					utxoFlag,
+					&cli.BoolFlag{
+						Name:  "private",
+						Usage: "do not announce the channel",
+					},
+					&cli.BoolFlag{
+						Name:  "yes",
+						Usage: "do not ask for confirmation",
+					},
+				},
+			},
+			{
+				Name:      "decode",
+				Usage:     "decode a BOLT11 payment request without paying it",
+				ArgsUsage: "<invoice>",
+				Action:    decodeRun,
+				Flags:     []cli.Flag{jsonFlag},
+			},
+			{
+				Name:      "qr",
+				Usage:     "print the QR code of a new invoice, a new address or the lndconnect uri",
+				ArgsUsage: "<invoice|address|lndconnect>",
+				Action:    mutating(qrRun),
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "amount",
+						Usage: "amount of the invoice in satoshis",
+					},
+					&cli.StringFlag{
+						Name:  "memo",
+						Usage: "description of the invoice",
+					},
+				},
+			},
+			{
+				Name:      "lnurl",
+				Aliases:   []string{"pay"},
+				Usage:     "pay a lightning address or a LNURL, or withdraw with a LNURL, after confirmation",
+				ArgsUsage: "<lnurl|user@domain>",
+				Action:    mutating(lnurlRun),
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "amount",
+						Usage: "amount in satoshis, required if the service accepts a range",
+					},
+					&cli.StringFlag{
+						Name:  "comment",
+						Usage: "comment sent with the payment",
+					},
+					&cli.BoolFlag{
+						Name:  "yes",
+						Usage: "do not ask for confirmation",
+					},
+				},
+			},
+			{
+				Name:   "bake-macaroon",
+				Usage:  "bake a macaroon with only the read permissions lntop needs, with the macaroon of the config",
+				Action: bakeMacaroonRun,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "write the macaroon to the file at `PATH` instead of printing it as hex",
+					},
+				},
+			},
+			{
+				Name:      "record",
+				Usage:     "run lntop and record the node to the file, for lntop replay",
+				ArgsUsage: "<file>",
+				Action:    recordRun,
+			},
+			{
+				Name:      "replay",
+				Usage:     "run lntop on the recording of the file instead of the node",
+				ArgsUsage: "<file>",
+				Action:    replayRun,
+			},
+			{
+				Name:      "ctl",
+				Usage:     "send a command to the control socket of a running lntop",
+				ArgsUsage: "<view|filter|export|refresh> [json params]",
+				Action:    ctlRun,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "socket",
+						Usage: "path of the control socket, defaults to control.socket of the config",
+					},
+				},
+			},
 		},
 	}
 }
 
 func run(c *cli.Context) error {
-	cfg, err := config.Load(c.String("config"))
+	app, err := loadApp(c)
 	if err != nil {
 		return err
 	}
+	return runApp(c, app)
+}
+
+// runApp runs the ui on the app, or the pubsub writing the events with
+// --headless.
+func runApp(c *cli.Context, app *app.App) error {
+	if c.Bool("headless") {
+		return headlessRun(c, app)
+	}
 
-	app, err := app.New(cfg)
+	openStore(app)
+	defer app.Close()
+
+	m, err := newAlerts(app)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	err = app.OpenFirewall()
+	if err != nil {
+		return err
+	}
+
+	others, err := app.Nodes()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 
 	events := make(chan *events.Event)
-	ps := pubsub.New(app.Logger, app.Network)
+	ps := pubsub.New(app.Logger, app.Network).
+		WithLoad(loadFlags(c)).
+		WithRefresh(app.Config.Refresh).
+		WithConfig(app.Config.Path)
+	defer exportRouting(app, ps)()
+	recordRouting(app, ps)
+	reloadOnHangup(ps)
+	go sampleBalances(ctx, app)
 
+	nodes, touch, stop := runNodes(ctx, app, others, events, ps)
 	go func() {
-		err := ui.Run(ctx, app, events)
+		err := ui.RunNodes(ctx, nodes, touch)
 		if err != nil {
 			app.Logger.Debug("ui", logging.String("error", err.Error()))
 		}
 		ps.Stop()
+		stop()
 	}()
 
+	done := runAlerts(ctx, m, events)
+	intercepted := runFirewall(ctx, app, ps, events)
 	ps.Run(ctx, events)
+	cancel()
+	<-done
+	<-intercepted
 	close(events)
 
 	return nil
 }
 
+// runNodes runs the pubsubs of the other nodes for the ui, displaying them
+// after the node of the app. The returned functions touch all the pubsubs
+// and stop the ones of the other nodes. The alerts, the routing export and
+// the synthetic load are only the ones of the node of the app, the config
+// reloaded by its pubsub sets the refresh intervals of all the pubsubs.
+func runNodes(ctx context.Context, app *app.App, others []*app.App, sub chan *events.Event,
+	ps *pubsub.PubSub) ([]ui.Node, func(), func()) {
+	nodes := []ui.Node{{App: app, Events: sub}}
+	pubsubs := []*pubsub.PubSub{ps}
+	for _, other := range others {
+		sub := make(chan *events.Event)
+		ps := pubsub.New(other.Logger, other.Network).WithRefresh(app.Config.Refresh)
+		pubsubs[0].WithConfigReload(func(cfg *config.Config) {
+			ps.SetRefresh(cfg.Refresh)
+		})
+		go func() {
+			ps.Run(ctx, sub)
+			close(sub)
+		}()
+		nodes = append(nodes, ui.Node{App: other, Events: sub})
+		pubsubs = append(pubsubs, ps)
+	}
+	touch := func() {
+		for _, ps := range pubsubs {
+			ps.Touch()
+		}
+	}
+	stop := func() {
+		for _, ps := range pubsubs[1:] {
+			ps.Stop()
+		}
+	}
+	return nodes, touch, stop
+}
+
+// newAlerts returns the manager of the alerts of the config, of the
+// checks of the channel backups and of the recording of the policy
+// changes.
+func newAlerts(app *app.App) (*alerts.Manager, error) {
+	m, err := alerts.New(app.Config.Alerts, app.Config.HTTP, app.Logger, app.Network)
+	if err != nil {
+		return nil, err
+	}
+	r, err := alerts.NewBackupRule(app.Config.Backup, app.Config.HTTP, app.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		m.AddRule(r)
+	}
+	if !app.Config.Alerts.FeeChange.Disabled || app.Store != nil {
+		r, err := alerts.NewFeeChangeRule(app.Config.Alerts.FeeChange, app.Logger, app.Store)
+		if err != nil {
+			return nil, err
+		}
+		m.AddRule(r)
+	}
+	return m, nil
+}
+
+// exportRouting appends the routing events of the pubsub to the file of
+// the export config, the returned function closes it. lntop runs without
+// exporting if it cannot be opened.
+func exportRouting(app *app.App, ps *pubsub.PubSub) func() {
+	path := app.Config.Export.Routing
+	if path == "" {
+		return func() {}
+	}
+	feed, err := export.OpenFeed(path)
+	if err != nil {
+		app.Logger.Error("cannot open routing export, nothing is exported", logging.Error(err))
+		return func() {}
+	}
+	ps.WithRoutingExport(func(e *netmodels.RoutingEvent) {
+		err := feed.WriteRoutingEvent(e)
+		if err != nil {
+			app.Logger.Error("cannot export routing event", logging.Error(err))
+		}
+	})
+	return func() {
+		err := feed.Close()
+		if err != nil {
+			app.Logger.Error("cannot close routing export", logging.Error(err))
+		}
+	}
+}
+
+// recordRouting records the routing events of the pubsub in the store of
+// the app, if it is open, the ui loads the last ones at startup.
+func recordRouting(app *app.App, ps *pubsub.PubSub) {
+	if app.Store == nil {
+		return
+	}
+	ps.WithRoutingExport(func(e *netmodels.RoutingEvent) {
+		err := app.Store.AddRoutingEvent(e)
+		if err != nil {
+			app.Logger.Error("cannot record routing event", logging.Error(err))
+		}
+	})
+}
+
+// sampleBalances records the balances of the channels in the store of the
+// app at the interval of its config until the context is done, and
+// deletes the samples older than the retention.
+func sampleBalances(ctx context.Context, app *app.App) {
+	interval, days := app.Config.Store.BalanceSampling()
+	if app.Store == nil || interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		channels, err := app.Network.ListChannels(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			app.Logger.Error("cannot sample balances", logging.Error(err))
+		} else {
+			now := time.Now()
+			err = app.Store.AddBalances(now, channels)
+			if err == nil {
+				err = app.Store.PruneBalances(now.AddDate(0, 0, -days))
+			}
+			if err != nil {
+				app.Logger.Error("cannot record balances", logging.Error(err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// openStore opens the store of the app, lntop runs without recording
+// if it cannot be opened.
+func openStore(app *app.App) {
+	err := app.OpenStore()
+	if err != nil {
+		app.Logger.Error("cannot open store, nothing is recorded", logging.Error(err))
+	}
+}
+
+// runAlerts checks the alert rules until the context is done, the
+// returned channel is closed once the manager stopped sending events.
+func runAlerts(ctx context.Context, m *alerts.Manager, sub chan *events.Event) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx, sub)
+		close(done)
+	}()
+	return done
+}
+
+// runFirewall runs the firewall of the app until the context is done, the
+// routing events of the pubsub end the HTLCs in flight of its exposure.
+func runFirewall(ctx context.Context, app *app.App, ps *pubsub.PubSub, sub chan *events.Event) chan struct{} {
+	done := make(chan struct{})
+	if app.Firewall == nil {
+		close(done)
+		return done
+	}
+	ps.WithRoutingExport(app.Firewall.RoutingEvent)
+	go func() {
+		app.Firewall.Run(ctx, sub)
+		close(done)
+	}()
+	return done
+}
+
+// reloadOnHangup reloads the config of the pubsub at SIGHUP. The pubsub
+// is stopped instead if the terminal it was started from is closed.
+func reloadOnHangup(ps *pubsub.PubSub) {
+	tty := hasTerminal()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		for range sig {
+			if tty && !hasTerminal() {
+				signal.Stop(sig)
+				ps.Stop()
+				return
+			}
+			ps.ReloadConfig()
+		}
+	}()
+}
+
+// hasTerminal returns true if the process has a controlling terminal.
+func hasTerminal() bool {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// loadFlags returns the synthetic load of the flags, disabled unless a
+// rate is set.
+func loadFlags(c *cli.Context) pubsub.Load {
+	return pubsub.Load{
+		RoutingRate: c.Int("load-routing"),
+		GraphRate:   c.Int("load-graph"),
+		Burst:       c.Duration("load-burst"),
+		Pause:       c.Duration("load-pause"),
+	}
+}
+
 func pubsubRun(c *cli.Context) error {
-	cfg, err := config.Load(c.String("config"))
+	app, err := loadApp(c)
 	if err != nil {
 		return err
 	}
+	return runPubSub(c, app, func(*events.Event) {})
+}
 
-	app, err := app.New(cfg)
+// headlessRun writes the events of the pubsub to stdout, or to the clients
+// of the events socket, until an interrupt.
+func headlessRun(c *cli.Context, app *app.App) error {
+	stream := export.NewStream(os.Stdout)
+	if path := c.String("events-socket"); path != "" {
+		var err error
+		stream, err = export.ListenStream(path)
+		if err != nil {
+			return err
+		}
+	}
+	defer stream.Close()
+
+	return runPubSub(c, app, func(e *events.Event) {
+		// a closed stdout ends lntop with a SIGPIPE, the clients of the
+		// socket failing to read are dropped by the stream.
+		stream.WriteEvent(e)
+	})
+}
+
+// runPubSub runs the pubsub and the alerts of the app without the ui,
+// every event is passed to consume until an interrupt.
+func runPubSub(c *cli.Context, app *app.App, consume func(*events.Event)) error {
+	openStore(app)
+	defer app.Close()
+
+	m, err := newAlerts(app)
 	if err != nil {
 		return err
 	}
 
-	events := make(chan *events.Event)
-	ps := pubsub.New(app.Logger, app.Network)
-	ps.Run(context.Background(), events)
+	err = app.OpenFirewall()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := make(chan *events.Event)
+	ps := pubsub.New(app.Logger, app.Network).
+		WithLoad(loadFlags(c)).
+		WithRefresh(app.Config.Refresh).
+		WithConfig(app.Config.Path)
+	defer exportRouting(app, ps)()
+	recordRouting(app, ps)
+	reloadOnHangup(ps)
+	go sampleBalances(ctx, app)
 
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt)
@@ -98,5 +664,22 @@ func pubsubRun(c *cli.Context) error {
 		ps.Stop()
 	}()
 
+	consumed := make(chan struct{})
+	go func() {
+		for e := range sub {
+			consume(e)
+		}
+		close(consumed)
+	}()
+
+	done := runAlerts(ctx, m, sub)
+	intercepted := runFirewall(ctx, app, ps, sub)
+	ps.Run(ctx, sub)
+	cancel()
+	<-done
+	<-intercepted
+	close(sub)
+	<-consumed
+
 	return nil
 }
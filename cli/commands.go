@@ -0,0 +1,565 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/app"
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/control"
+	"github.com/edouardparis/lntop/export"
+	"github.com/edouardparis/lntop/logging"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/qr"
+	"github.com/edouardparis/lntop/ui/models"
+	"github.com/edouardparis/lntop/ui/views"
+)
+
+var jsonFlag = &cli.BoolFlag{
+	Name:  "json",
+	Usage: "print the result as JSON instead of a table",
+}
+
+func loadApp(c *cli.Context) (*app.App, error) {
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return app.New(cfg)
+}
+
+// loadConfig loads the config of the flags.
+func loadConfig(c *cli.Context) (*config.Config, error) {
+	cfg, err := config.Load(c.String("config"))
+	if err != nil {
+		return nil, err
+	}
+
+	if network := c.String("network"); network != "" {
+		err = cfg.UseNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Bool("demo") {
+		cfg.Network = config.Network{
+			Name:    "demo",
+			Type:    "demo",
+			Aliases: cfg.Network.Aliases,
+		}
+		for i, node := range cfg.Nodes {
+			cfg.Nodes[i] = config.Network{
+				Name:    node.Name,
+				Type:    "demo",
+				Aliases: node.Aliases,
+			}
+		}
+	}
+
+	cfg.ReadOnly = c.Bool("read-only")
+
+	return cfg, nil
+}
+
+// mutating returns the action of a command changing the node, refused in
+// read-only mode.
+func mutating(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.Bool("read-only") {
+			return errors.Errorf("lntop %s is disabled in read-only mode", c.Command.Name)
+		}
+		return action(c)
+	}
+}
+
+func channelsRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	m := models.New(app)
+	err = m.RefreshInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshAllChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format := c.String("view"); format != "" {
+		// the fiat columns are blank if the rate cannot be fetched.
+		err := m.RefreshPrice(ctx)
+		if err != nil {
+			app.Logger.Error("cannot refresh price", logging.Error(err))
+		}
+		err = m.RefreshPeerData(ctx)
+		if err != nil {
+			app.Logger.Error("cannot refresh peer data", logging.Error(err))
+		}
+		// the channels are the ones of the view, filtered like at start.
+		if cfg := app.Config.Views.Channels; cfg != nil {
+			err = m.Channels.SetFilterExpr(cfg.Filter)
+			if err != nil {
+				return errors.Wrap(err, "views.channels: filter")
+			}
+		}
+		v := views.NewChannels(app.Config.Views.Channels, m.Channels, m.Plugins, m.Price, m.PeerData)
+		header, rows := v.Table()
+		return export.Table(os.Stdout, format, header, rows)
+	}
+
+	channels := m.Channels.List()
+	if c.Bool("json") {
+		return export.Channels(os.Stdout, channels)
+	}
+	return printChannelsTable(os.Stdout, channels)
+}
+
+func printChannelsTable(w io.Writer, channels []*netmodels.Channel) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "STATUS\tALIAS\tLOCAL\tREMOTE\tCAP\tHTLC\tPRIVATE\tSCID\t")
+	for _, ch := range channels {
+		alias, _ := ch.ShortAlias()
+		scid := ""
+		if ch.ID != 0 {
+			scid = netmodels.ToScid(ch.ID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%t\t%s\t\n",
+			ch.StatusName(), alias,
+			ch.LocalBalance, ch.RemoteBalance, ch.Capacity,
+			len(ch.PendingHTLC), ch.Private, scid,
+		)
+	}
+	return tw.Flush()
+}
+
+func forwardsRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	m := models.New(app)
+	if c.IsSet("start-time") {
+		m.FwdingHist.StartTime = c.String("start-time")
+	}
+	if c.IsSet("max") {
+		m.FwdingHist.MaxNumEvents = uint32(c.Int("max"))
+	}
+
+	err = m.RefreshForwardingHistory(context.Background())
+	if err != nil {
+		return err
+	}
+
+	events := m.FwdingHist.List()
+	if c.Bool("json") {
+		return printForwardsJSON(os.Stdout, events)
+	}
+	return printForwardsTable(os.Stdout, events)
+}
+
+type forwardJSON struct {
+	Time      time.Time `json:"time"`
+	ChanIdIn  uint64    `json:"chan_id_in"`
+	ChanIdOut uint64    `json:"chan_id_out"`
+	AliasIn   string    `json:"alias_in"`
+	AliasOut  string    `json:"alias_out"`
+	AmtIn     uint64    `json:"amt_in"`
+	AmtOut    uint64    `json:"amt_out"`
+	FeeMsat   uint64    `json:"fee_msat"`
+}
+
+func printForwardsJSON(w io.Writer, events []*netmodels.ForwardingEvent) error {
+	out := make([]forwardJSON, len(events))
+	for i, e := range events {
+		out[i] = forwardJSON{
+			Time:      e.EventTime,
+			ChanIdIn:  e.ChanIdIn,
+			ChanIdOut: e.ChanIdOut,
+			AliasIn:   e.PeerAliasIn,
+			AliasOut:  e.PeerAliasOut,
+			AmtIn:     e.AmtIn,
+			AmtOut:    e.AmtOut,
+			FeeMsat:   e.FeeMsat,
+		}
+	}
+	return export.JSON(w, out)
+}
+
+func printForwardsTable(w io.Writer, events []*netmodels.ForwardingEvent) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TIME\tALIAS_IN\tALIAS_OUT\tRECEIVED\tSENT\tEARNED\t")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t\n",
+			e.EventTime.Format("15:04:05 Jan _2"),
+			e.PeerAliasIn, e.PeerAliasOut,
+			e.AmtIn, e.AmtOut, e.Fee,
+		)
+	}
+	return tw.Flush()
+}
+
+func closedRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	since, err := parseRange(c.String("since"))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	m := models.NewWithNetwork(app.Network, app.Logger)
+	err = m.RefreshInfo(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.RefreshClosedChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	closed := models.InRange(m.ClosedChannels.All(), since)
+	breakdown := models.NewCloseBreakdown(closed)
+	if c.Bool("json") {
+		return printClosedJSON(os.Stdout, closed, breakdown)
+	}
+	return printClosedTable(os.Stdout, closed, breakdown)
+}
+
+// parseRange parses a duration with the d unit for days, zero for all.
+func parseRange(s string) (time.Duration, error) {
+	if s == "" || s == "all" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.Errorf("invalid range %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid range %q", s)
+	}
+	return d, nil
+}
+
+type closedJSON struct {
+	Time           time.Time `json:"time"`
+	ChannelPoint   string    `json:"channel_point"`
+	PubKey         string    `json:"pubkey"`
+	Alias          string    `json:"alias"`
+	CloseType      string    `json:"close_type"`
+	OpenInitiator  string    `json:"open_initiator"`
+	CloseInitiator string    `json:"close_initiator"`
+	Capacity       int64     `json:"capacity"`
+	Settled        int64     `json:"settled_balance"`
+	TimeLocked     int64     `json:"time_locked_balance"`
+	ClosingFee     int64     `json:"closing_fee"`
+	CloseHeight    uint32    `json:"close_height"`
+	ClosingTxHash  string    `json:"closing_tx_hash"`
+}
+
+func printClosedJSON(w io.Writer, closed []*netmodels.ClosedChannel, breakdown *models.CloseBreakdown) error {
+	out := struct {
+		Breakdown *models.CloseBreakdown `json:"breakdown"`
+		Channels  []closedJSON           `json:"channels"`
+	}{breakdown, make([]closedJSON, len(closed))}
+	for i, ch := range closed {
+		out.Channels[i] = closedJSON{
+			Time:           ch.CloseTime,
+			ChannelPoint:   ch.ChannelPoint,
+			PubKey:         ch.RemotePubKey,
+			Alias:          ch.Alias(),
+			CloseType:      netmodels.CloseTypeName(ch.CloseType),
+			OpenInitiator:  netmodels.InitiatorName(ch.OpenInitiator),
+			CloseInitiator: netmodels.InitiatorName(ch.CloseInitiator),
+			Capacity:       ch.Capacity,
+			Settled:        ch.SettledBalance,
+			TimeLocked:     ch.TimeLockedBalance,
+			ClosingFee:     ch.ClosingFee,
+			CloseHeight:    ch.CloseHeight,
+			ClosingTxHash:  ch.ClosingTxHash,
+		}
+	}
+	return export.JSON(w, out)
+}
+
+func printClosedTable(w io.Writer, closed []*netmodels.ClosedChannel, breakdown *models.CloseBreakdown) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TIME\tALIAS\tTYPE\tOPENER\tCLOSER\tCAPACITY\tSETTLED\tFEE\t")
+	for _, ch := range closed {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t\n",
+			ch.CloseTime.Format("15:04:05 Jan _2"), ch.Alias(),
+			netmodels.CloseTypeName(ch.CloseType),
+			netmodels.InitiatorName(ch.OpenInitiator),
+			netmodels.InitiatorName(ch.CloseInitiator),
+			ch.Capacity, ch.SettledBalance, ch.ClosingFee,
+		)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d closed, %d sat of closing fees\n", breakdown.Total, breakdown.ClosingFees)
+	for _, group := range []map[string]int{breakdown.ByType, breakdown.ByInitiator} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "  %-16s %d\n", name, group[name])
+		}
+	}
+	return nil
+}
+
+func peersRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	peers, err := app.Network.ListPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range peers {
+		peers[i].Node, err = app.Network.GetNode(ctx, peers[i].PubKey, false)
+		if err != nil {
+			app.Logger.Debug("peers: cannot find Node")
+		}
+	}
+
+	if c.Bool("json") {
+		return printPeersJSON(os.Stdout, peers)
+	}
+	return printPeersTable(os.Stdout, peers)
+}
+
+type peerJSON struct {
+	PubKey        string `json:"pubkey"`
+	Alias         string `json:"alias"`
+	Address       string `json:"address"`
+	Inbound       bool   `json:"inbound"`
+	PingTime      int64  `json:"ping_time_us"`
+	SatSent       int64  `json:"sat_sent"`
+	SatRecv       int64  `json:"sat_recv"`
+	BytesSent     uint64 `json:"bytes_sent"`
+	BytesRecv     uint64 `json:"bytes_recv"`
+	LastError     string `json:"last_error,omitempty"`
+	LastErrorTime int64  `json:"last_error_time,omitempty"`
+}
+
+func peerAlias(p *netmodels.Peer) string {
+	if p.Node == nil {
+		return ""
+	}
+	if p.Node.ForcedAlias != "" {
+		return p.Node.ForcedAlias
+	}
+	return p.Node.Alias
+}
+
+func printPeersJSON(w io.Writer, peers []*netmodels.Peer) error {
+	out := make([]peerJSON, len(peers))
+	for i, p := range peers {
+		out[i] = peerJSON{
+			PubKey:    p.PubKey,
+			Alias:     peerAlias(p),
+			Address:   p.Address,
+			Inbound:   p.Inbound,
+			PingTime:  p.PingTime.Microseconds(),
+			SatSent:   p.SatSent,
+			SatRecv:   p.SatRecv,
+			BytesSent: p.BytesSent,
+			BytesRecv: p.BytesRecv,
+			LastError: p.LastError,
+		}
+		if p.LastError != "" {
+			out[i].LastErrorTime = p.LastErrorTime.Unix()
+		}
+	}
+	return export.JSON(w, out)
+}
+
+func printPeersTable(w io.Writer, peers []*netmodels.Peer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ALIAS\tPUBKEY\tADDRESS\tPING\tSAT_SENT\tSAT_RECV\t")
+	for _, p := range peers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t\n",
+			peerAlias(p), p.PubKey, p.Address,
+			p.PingTime.Round(time.Millisecond),
+			p.SatSent, p.SatRecv,
+		)
+	}
+	return tw.Flush()
+}
+
+func ctlRun(c *cli.Context) error {
+	method := c.Args().First()
+	if method == "" {
+		return errors.New("missing control method")
+	}
+
+	socket := c.String("socket")
+	if socket == "" {
+		cfg, err := config.Load(c.String("config"))
+		if err != nil {
+			return err
+		}
+		socket = cfg.Control.Socket
+	}
+	if socket == "" {
+		return errors.New("control socket is not configured")
+	}
+
+	var params interface{}
+	if raw := c.Args().Get(1); raw != "" {
+		params = json.RawMessage(raw)
+	}
+
+	result, err := control.Call(socket, method, params)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, string(result))
+	return err
+}
+
+func qrRun(c *cli.Context) error {
+	var content string
+	switch c.Args().First() {
+	case "lndconnect":
+		cfg, err := config.Load(c.String("config"))
+		if err != nil {
+			return err
+		}
+		content, err = qr.LNDConnect(&cfg.Network)
+		if err != nil {
+			return err
+		}
+	case "invoice":
+		app, err := loadApp(c)
+		if err != nil {
+			return err
+		}
+		invoice, err := app.Network.CreateInvoice(context.Background(), c.Int64("amount"), c.String("memo"))
+		if err != nil {
+			return err
+		}
+		content = qr.Invoice(invoice.PaymentRequest)
+	case "address":
+		app, err := loadApp(c)
+		if err != nil {
+			return err
+		}
+		address, err := app.Network.NewAddress(context.Background())
+		if err != nil {
+			return err
+		}
+		content = qr.Address(address)
+	default:
+		return errors.New("expected invoice, address or lndconnect")
+	}
+
+	code, err := qr.Render(content)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s%s\n", code, content)
+	return err
+}
+
+// bakeMacaroonRun bakes a macaroon of the monitoring permissions, the
+// macaroon of the config must allow to generate macaroons, e.g. the admin
+// one.
+func bakeMacaroonRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	mac, err := app.Network.BakeMacaroon(context.Background(), netmodels.MonitoringPermissions)
+	if err != nil {
+		return err
+	}
+
+	if out := c.String("out"); out != "" {
+		err := os.WriteFile(out, mac, 0600)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Fprintf(os.Stderr, "macaroon written to %s\n", out)
+		return nil
+	}
+	_, err = fmt.Fprintln(os.Stdout, hex.EncodeToString(mac))
+	return err
+}
+
+func decodeRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	m := models.NewWithNetwork(app.Network, app.Logger)
+	payreq, alias, err := m.DecodePayReq(context.Background(), c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		return export.JSON(os.Stdout, payreq)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "amount\t%d sat (%d msat)\n", payreq.Amount, payreq.AmountMsat)
+	fmt.Fprintf(tw, "destination\t%s %s\n", payreq.Destination, alias)
+	fmt.Fprintf(tw, "description\t%s\n", payreq.Description)
+	if payreq.DescriptionHash != "" {
+		fmt.Fprintf(tw, "description hash\t%s\n", payreq.DescriptionHash)
+	}
+	fmt.Fprintf(tw, "payment hash\t%s\n", payreq.PaymentHash)
+	fmt.Fprintf(tw, "created\t%s\n", time.Unix(payreq.Timestamp, 0).Format(time.RFC3339))
+	fmt.Fprintf(tw, "expiry\t%s\n", time.Duration(payreq.Expiry)*time.Second)
+	fmt.Fprintf(tw, "cltv expiry\t%d\n", payreq.CltvExpiry)
+	for i, hint := range payreq.RouteHints {
+		for _, hop := range hint.Hops {
+			fmt.Fprintf(tw, "route hint %d\t%s %s base %d msat %d ppm cltv %d\n", i+1,
+				hop.NodeID, netmodels.ToScid(hop.ChanID),
+				hop.FeeBaseMsat, hop.FeeProportionalMillionths, hop.CltvExpiryDelta)
+		}
+	}
+	for _, f := range payreq.Features {
+		fmt.Fprintf(tw, "feature %d\t%s required=%t known=%t\n", f.Bit, f.Name, f.Required, f.Known)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/lnurl"
+)
+
+func lnurlRun(c *cli.Context) error {
+	arg := strings.TrimPrefix(c.Args().First(), "lightning:")
+	u, err := lnurl.Decode(arg)
+	if err != nil {
+		return err
+	}
+
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+	client, err := lnurl.NewClient(app.Config.HTTP)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	params, err := client.Fetch(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	msat := c.Int64("amount") * 1000
+	switch params.Tag {
+	case lnurl.TagPay:
+		p := params.Pay
+		fmt.Printf("pay %s\n%s\namount: %s\n", params.Domain(), p.Description(), p.Range())
+		if id := p.Identifier(); id != "" {
+			fmt.Printf("address: %s\n", id)
+			if lnurl.IsAddress(arg) && !strings.EqualFold(id, arg) {
+				return errors.Errorf("the service is the one of %s, not of %s", id, arg)
+			}
+		}
+		if msat == 0 && p.MinSendable == p.MaxSendable {
+			msat = p.MinSendable
+		}
+		if msat == 0 {
+			return errors.New("missing amount")
+		}
+
+		invoice, err := client.RequestInvoice(ctx, app.Network, p, msat, c.String("comment"))
+		if err != nil {
+			return err
+		}
+
+		// the destination is known once the invoice is requested.
+		destination := invoice.PayReq.Destination
+		node, err := app.Network.GetNode(ctx, destination, false)
+		if err == nil && node.Alias != "" {
+			destination = fmt.Sprintf("%s (%s)", node.Alias, destination)
+		}
+		fmt.Printf("node: %s\n", destination)
+		if !c.Bool("yes") && !confirm(fmt.Sprintf("pay %d sat to %s?", invoice.PayReq.Amount, params.Domain())) {
+			return nil
+		}
+
+		payment, err := lnurl.Pay(ctx, app.Network, invoice)
+		if err != nil {
+			return err
+		}
+		fmt.Println("paid")
+		if invoice.SuccessAction != nil {
+			text, err := invoice.SuccessAction.Text(payment.PaymentPreimage)
+			if err != nil {
+				return err
+			}
+			fmt.Println(text)
+		}
+
+	case lnurl.TagWithdraw:
+		p := params.Withdraw
+		fmt.Printf("withdraw from %s\n%s\namount: %s\n", params.Domain(), p.DefaultDescription, p.Range())
+		if msat == 0 {
+			msat = p.MaxWithdrawable
+		}
+		if !c.Bool("yes") && !confirm(fmt.Sprintf("withdraw %d sat from %s?", msat/1000, params.Domain())) {
+			return nil
+		}
+
+		err = client.Withdraw(ctx, app.Network, p, msat)
+		if err != nil {
+			return err
+		}
+		fmt.Println("invoice sent, the service pays it")
+	}
+	return nil
+}
+
+// confirm asks the question on the terminal and returns true if the
+// answer is yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
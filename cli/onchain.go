@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/export"
+	netmodels "github.com/edouardparis/lntop/network/models"
+)
+
+var utxoFlag = &cli.StringSliceFlag{
+	Name:  "utxo",
+	Usage: "outpoint \"txid:index\" of a wallet output to spend, repeat it to spend several, the wallet selects the outputs if omitted",
+}
+
+var feeRateFlag = &cli.Uint64Flag{
+	Name:  "fee-rate",
+	Usage: "fee rate in sat/vbyte",
+}
+
+func utxosRun(c *cli.Context) error {
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	utxos, err := app.Network.ListUnspent(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		return printUTXOsJSON(os.Stdout, utxos)
+	}
+	return printUTXOsTable(os.Stdout, utxos)
+}
+
+type utxoJSON struct {
+	Outpoint      string `json:"outpoint"`
+	Address       string `json:"address"`
+	AddressType   string `json:"address_type"`
+	Amount        int64  `json:"amount"`
+	Confirmations int64  `json:"confirmations"`
+	Label         string `json:"label,omitempty"`
+	// LeaseExpiration is the unix time of the end of the lease of the
+	// output.
+	LeaseExpiration int64 `json:"lease_expiration,omitempty"`
+}
+
+func printUTXOsJSON(w io.Writer, utxos []*netmodels.UTXO) error {
+	out := make([]utxoJSON, len(utxos))
+	for i, u := range utxos {
+		out[i] = utxoJSON{
+			Outpoint:      u.Outpoint,
+			Address:       u.Address,
+			AddressType:   u.AddressType,
+			Amount:        u.Amount,
+			Confirmations: u.Confirmations,
+			Label:         u.Label,
+		}
+		if u.Leased() {
+			out[i].LeaseExpiration = u.LeaseExpiration.Unix()
+		}
+	}
+	return export.JSON(w, out)
+}
+
+func printUTXOsTable(w io.Writer, utxos []*netmodels.UTXO) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OUTPOINT\tTYPE\tAMOUNT\tCONFS\tLEASE\tLABEL\t")
+	for _, u := range utxos {
+		lease := ""
+		if u.Leased() {
+			lease = u.LeaseExpiration.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t\n",
+			u.Outpoint, u.AddressType, u.Amount, u.Confirmations, lease, u.Label)
+	}
+	return tw.Flush()
+}
+
+func labelRun(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return errors.New("usage: lntop label <txid|outpoint> <label>")
+	}
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	utxo := &netmodels.UTXO{Outpoint: c.Args().Get(0)}
+	return app.Network.LabelTransaction(context.Background(), utxo.TxID(), c.Args().Get(1))
+}
+
+func sendRun(c *cli.Context) error {
+	address := c.Args().First()
+	amount := c.Int64("amount")
+	if address == "" || amount <= 0 {
+		return errors.New("usage: lntop send -amount <sat> [-fee-rate <sat/vbyte>] [-utxo <outpoint>]... <address>")
+	}
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	outpoints := c.StringSlice("utxo")
+	question := fmt.Sprintf("send %d sat to %s", amount, address)
+	if !c.Bool("yes") && !confirm(question+spending(outpoints)+"?") {
+		return nil
+	}
+
+	txid, err := app.Network.SendOnChain(context.Background(), address, amount, c.Uint64("fee-rate"), outpoints)
+	if err != nil {
+		return err
+	}
+	fmt.Println(txid)
+	return nil
+}
+
+func openRun(c *cli.Context) error {
+	pubkey := c.Args().First()
+	amount := c.Int64("amount")
+	if pubkey == "" || amount <= 0 {
+		return errors.New("usage: lntop open -amount <sat> [-fee-rate <sat/vbyte>] [-utxo <outpoint>]... <pubkey>")
+	}
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	outpoints := c.StringSlice("utxo")
+	peer := pubkey
+	node, err := app.Network.GetNode(ctx, pubkey, false)
+	if err == nil && node.Alias != "" {
+		peer = fmt.Sprintf("%s (%s)", node.Alias, pubkey)
+	}
+	question := fmt.Sprintf("open a %d sat channel with %s", amount, peer)
+	if !c.Bool("yes") && !confirm(question+spending(outpoints)+"?") {
+		return nil
+	}
+
+	point, err := app.Network.OpenChannel(ctx, pubkey, amount, c.Uint64("fee-rate"), c.Bool("private"), outpoints)
+	if err != nil {
+		return err
+	}
+	fmt.Println(point)
+	return nil
+}
+
+func spending(outpoints []string) string {
+	switch len(outpoints) {
+	case 0:
+		return ""
+	case 1:
+		return " spending " + outpoints[0]
+	}
+	return fmt.Sprintf(" spending %d outputs", len(outpoints))
+}
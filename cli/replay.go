@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/app"
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/backend/replay"
+)
+
+// recordRun runs lntop on the node of the config and records it to the
+// file, the other nodes of the config are not displayed.
+func recordRun(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("the file of the recording is missing")
+	}
+
+	app, err := loadApp(c)
+	if err != nil {
+		return err
+	}
+	app.Config.Nodes = nil
+
+	// the recording holds the channels and the payments of the node.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	r, err := replay.NewRecorder(app.Network.Backend, f, app.Logger)
+	if err != nil {
+		return err
+	}
+	app.Network = network.NewWithBackend(r)
+	return runApp(c, app)
+}
+
+// replayRun runs lntop on the recording of the file in read-only mode. The
+// replayed events are not recorded in the store, exported or sent to the
+// alert sinks again.
+func replayRun(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("the file of the recording is missing")
+	}
+
+	cfg, err := loadConfig(c)
+	if err != nil {
+		return err
+	}
+	cfg.Network = config.Network{
+		Name:    "replay",
+		Type:    "replay",
+		Address: path,
+		Aliases: cfg.Network.Aliases,
+	}
+	cfg.Nodes = nil
+	cfg.ReadOnly = true
+	cfg.Store.Path = ""
+	cfg.Export.Routing = ""
+	cfg.Alerts.Command = nil
+	cfg.Alerts.Webhook = ""
+	cfg.Alerts.Telegram = config.TelegramAlert{}
+
+	app, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	return runApp(c, app)
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cli "gopkg.in/urfave/cli.v2"
+
+	"github.com/edouardparis/lntop/expr"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+// The exit codes of the watch command.
+const (
+	watchTimeout = 1
+	watchFailed  = 2
+)
+
+const watchUsage = "usage: lntop watch --until <expression> [--interval 5s] [--timeout 1h]"
+
+// watchRun checks the condition of the until flag at every interval and
+// exits once it is true, with watchTimeout if the timeout is reached first
+// and watchFailed if it cannot be evaluated. The node is polled again if
+// it does not answer.
+func watchRun(c *cli.Context) error {
+	if c.String("until") == "" {
+		return cli.Exit(watchUsage, watchFailed)
+	}
+	if c.Duration("interval") <= 0 {
+		return cli.Exit("the interval must be positive, "+watchUsage, watchFailed)
+	}
+	app, err := loadApp(c)
+	if err != nil {
+		return cli.Exit(err, watchFailed)
+	}
+
+	w := &watcher{models: models.New(app), network: app.Network}
+	e, err := expr.ParseFuncs(c.String("until"), w.funcs())
+	if err != nil {
+		return cli.Exit(err, watchFailed)
+	}
+
+	ctx := context.Background()
+	if timeout := c.Duration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(c.Duration("interval"))
+	defer ticker.Stop()
+	for {
+		err := w.refresh(ctx)
+		if err == nil {
+			var ok bool
+			ok, err = e.Bool(w.env())
+			var nerr *nodeError
+			if err != nil && !errors.As(err, &nerr) {
+				return cli.Exit(err, watchFailed)
+			}
+			if ok {
+				return nil
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			app.Logger.Error("watch cannot refresh", logging.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return cli.Exit(fmt.Sprintf("timeout, %s is still false", e), watchTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// nodeError is an error of the node in a function of the condition, the
+// condition is checked again at the next interval.
+type nodeError struct {
+	err error
+}
+
+func (e *nodeError) Error() string { return e.err.Error() }
+func (e *nodeError) Unwrap() error { return e.err }
+
+// watcher is the state of the node of the last refresh for the condition.
+type watcher struct {
+	models  *models.Models
+	network *network.Network
+	// ctx is the context of the calls of the functions.
+	ctx context.Context
+}
+
+func (w *watcher) refresh(ctx context.Context) error {
+	w.ctx = ctx
+	for _, refresh := range []func(context.Context) error{
+		w.models.RefreshInfo,
+		w.models.RefreshWalletBalance,
+		w.models.RefreshChannelsBalance,
+		w.models.RefreshAllChannels,
+	} {
+		err := refresh(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *watcher) env() expr.Env {
+	info := w.models.Info
+	return expr.Env{
+		"alias":                info.Alias,
+		"pubkey":               info.PubKey,
+		"synced":               info.Synced,
+		"block_height":         float64(info.BlockHeight),
+		"peers":                float64(info.NumPeers),
+		"active_channels":      float64(info.NumActiveChannels),
+		"inactive_channels":    float64(info.NumInactiveChannels),
+		"pending_channels":     float64(info.NumPendingChannels),
+		"wallet_balance":       float64(w.models.WalletBalance.ConfirmedBalance),
+		"unconfirmed_balance":  float64(w.models.WalletBalance.UnconfirmedBalance),
+		"channels_balance":     float64(w.models.ChannelsBalance.Balance),
+		"pending_open_balance": float64(w.models.ChannelsBalance.PendingOpenBalance),
+	}
+}
+
+// funcs are the functions of the conditions on a channel, given by its
+// channel point, id, short channel id, peer pubkey or alias, and on an
+// invoice given by its payment hash.
+func (w *watcher) funcs() map[string]expr.Func {
+	return map[string]expr.Func{
+		"channel_active": w.channelFunc(func(channels []*netmodels.Channel) interface{} {
+			for _, ch := range channels {
+				if ch.Status == netmodels.ChannelActive {
+					return true
+				}
+			}
+			return false
+		}),
+		"channel_status": w.channelFunc(func(channels []*netmodels.Channel) interface{} {
+			if len(channels) == 0 {
+				return ""
+			}
+			return channels[0].StatusName()
+		}),
+		"channel_local": w.channelFunc(func(channels []*netmodels.Channel) interface{} {
+			sum := 0.0
+			for _, ch := range channels {
+				sum += float64(ch.LocalBalance)
+			}
+			return sum
+		}),
+		"channel_remote": w.channelFunc(func(channels []*netmodels.Channel) interface{} {
+			sum := 0.0
+			for _, ch := range channels {
+				sum += float64(ch.RemoteBalance)
+			}
+			return sum
+		}),
+		"invoice_settled": func(args []interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, errors.New("expects one argument")
+			}
+			invoice, err := w.network.GetInvoice(w.ctx, fmt.Sprint(args[0]))
+			if err != nil {
+				return nil, &nodeError{err: err}
+			}
+			return invoice.Settled, nil
+		},
+	}
+}
+
+func (w *watcher) channelFunc(f func([]*netmodels.Channel) interface{}) expr.Func {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("expects one argument")
+		}
+		var channels []*netmodels.Channel
+		for _, ch := range w.models.Channels.List() {
+			if matchChannel(ch, args[0]) {
+				channels = append(channels, ch)
+			}
+		}
+		return f(channels), nil
+	}
+}
+
+func matchChannel(ch *netmodels.Channel, arg interface{}) bool {
+	// an unquoted channel id is a number, rounded like the id.
+	if f, ok := arg.(float64); ok {
+		return float64(ch.ID) == f
+	}
+	s := fmt.Sprint(arg)
+	alias, _ := ch.ShortAlias()
+	switch s {
+	case ch.ChannelPoint, ch.RemotePubKey, alias, strconv.FormatUint(ch.ID, 10):
+		return true
+	}
+	return ch.ID != 0 && s == netmodels.ToScid(ch.ID)
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+var (
+	columnsLine = regexp.MustCompile(`^\s*columns\s*=`)
+	widthsLine  = regexp.MustCompile(`^\s*widths\s*=`)
+	sortLine    = regexp.MustCompile(`^\s*sort\s*=`)
+	// columnEntry is a column of the array, commented out or not, with
+	// its comment.
+	columnEntry = regexp.MustCompile(`^(\s*)(#\s*)?"([^"]+)",?\s*(#.*)?$`)
+	// quotedColumn is a column of an array written on a single line.
+	quotedColumn = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// columnLine is a column of the columns array of a file, with the
+// comment lines following it.
+type columnLine struct {
+	comment string
+	extra   []string
+}
+
+// SaveColumns writes the columns and the widths of the view to the
+// config file, e.g. "channels" for [views.channels]. The columns keep
+// their comments, the hidden ones are commented out after the visible
+// ones and the other lines of the file are left as they are.
+func SaveColumns(path, view string, columns []string, widths map[string]int) error {
+	return saveSection(path, view, func(section []string) []string {
+		section = setColumns(section, columns)
+		return setWidths(section, columns, widths)
+	})
+}
+
+// SaveSort writes the sort keys of the view to the config file, the line
+// is removed without keys.
+func SaveSort(path, view string, keys []string) error {
+	return saveSection(path, view, func(section []string) []string {
+		return setSort(section, keys)
+	})
+}
+
+// saveSection edits the lines of the section of the view in the config
+// file, the section is added at the end if missing.
+func saveSection(path, view string, edit func([]string) []string) error {
+	if path == "" {
+		return errors.New("the config was not loaded from a file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+
+	header := "[views." + view + "]"
+	start := -1
+	for i := range lines {
+		if strings.TrimSpace(lines[i]) == header {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		lines = append(lines, "", header)
+		start = len(lines) - 1
+	}
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			end = i
+			break
+		}
+	}
+	// the keys are added at the end of the section, before the blank
+	// lines separating it from the next one.
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	section := edit(append([]string{}, lines[start+1:end]...))
+
+	out := append(append(append([]string{}, lines[:start+1]...), section...), lines[end:]...)
+	content := strings.Join(out, "\n") + "\n"
+	_, err = toml.Decode(content, &Config{})
+	if err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+	return writeFile(path, []byte(content))
+}
+
+// setColumns replaces the columns array of the lines of the section, or
+// adds it at the end.
+func setColumns(section []string, columns []string) []string {
+	start, end := -1, -1
+	for i := range section {
+		if columnsLine.MatchString(section[i]) {
+			start = i
+			break
+		}
+	}
+	indent := "\t"
+	entries := map[string]*columnLine{}
+	var order []string
+	if start >= 0 && strings.Contains(section[start], "]") {
+		// the array opens and closes on the line, its columns have no
+		// comments.
+		end = start
+		array := section[start][:strings.Index(section[start], "]")]
+		for _, m := range quotedColumn.FindAllStringSubmatch(array, -1) {
+			if _, ok := entries[m[1]]; !ok {
+				entries[m[1]] = &columnLine{}
+				order = append(order, m[1])
+			}
+		}
+	} else if start >= 0 {
+		for end = start + 1; end < len(section); end++ {
+			if strings.HasPrefix(strings.TrimSpace(section[end]), "]") {
+				break
+			}
+		}
+		var last *columnLine
+		for _, line := range section[start+1 : min(end, len(section))] {
+			m := columnEntry.FindStringSubmatch(line)
+			if m == nil {
+				// the comments are kept, the other lines are
+				// columns replaced by the new ones.
+				if last != nil && strings.HasPrefix(strings.TrimSpace(line), "#") {
+					last.extra = append(last.extra, line)
+				}
+				continue
+			}
+			if len(order) == 0 {
+				indent = m[1]
+			}
+			last = &columnLine{comment: m[4]}
+			if _, ok := entries[m[3]]; !ok {
+				entries[m[3]] = last
+				order = append(order, m[3])
+			}
+		}
+	}
+
+	block := []string{"columns = ["}
+	entry := func(name string, hidden bool) {
+		token := fmt.Sprintf("%q,", name)
+		if hidden {
+			token = "# " + token
+		}
+		e, ok := entries[name]
+		if !ok || e.comment == "" {
+			block = append(block, indent+token)
+		} else {
+			block = append(block, fmt.Sprintf("%s%-14s %s", indent, token, e.comment))
+		}
+		if ok {
+			block = append(block, e.extra...)
+		}
+	}
+	visible := map[string]bool{}
+	for _, name := range columns {
+		visible[name] = true
+		entry(name, false)
+	}
+	for _, name := range order {
+		if !visible[name] {
+			entry(name, true)
+		}
+	}
+	block = append(block, "]")
+
+	if start < 0 {
+		return append(section, block...)
+	}
+	if end >= len(section) {
+		end = len(section) - 1
+	}
+	return append(append(append([]string{}, section[:start]...), block...), section[end+1:]...)
+}
+
+// setWidths replaces the widths of the lines of the section, or adds
+// them after the columns, the line is removed without widths.
+func setWidths(section []string, columns []string, widths map[string]int) []string {
+	line := ""
+	if len(widths) > 0 {
+		pairs := make([]string, 0, len(widths))
+		for _, name := range columns {
+			if w, ok := widths[name]; ok {
+				pairs = append(pairs, fmt.Sprintf("%q = %d", name, w))
+			}
+		}
+		if len(pairs) > 0 {
+			line = "widths = { " + strings.Join(pairs, ", ") + " }"
+		}
+	}
+
+	for i := range section {
+		if !widthsLine.MatchString(section[i]) {
+			continue
+		}
+		if line == "" {
+			return append(section[:i], section[i+1:]...)
+		}
+		section[i] = line
+		return section
+	}
+	if line == "" {
+		return section
+	}
+	for i := range section {
+		if strings.HasPrefix(strings.TrimSpace(section[i]), "]") {
+			return append(section[:i+1], append([]string{line}, section[i+1:]...)...)
+		}
+	}
+	return append(section, line)
+}
+
+// setSort replaces the sort keys of the lines of the section, or adds them
+// after the columns.
+func setSort(section []string, keys []string) []string {
+	line := ""
+	if len(keys) > 0 {
+		quoted := make([]string, len(keys))
+		for i := range keys {
+			quoted[i] = fmt.Sprintf("%q", keys[i])
+		}
+		line = "sort = [" + strings.Join(quoted, ", ") + "]"
+	}
+
+	for i := range section {
+		if !sortLine.MatchString(section[i]) {
+			continue
+		}
+		if line == "" {
+			return append(section[:i], section[i+1:]...)
+		}
+		section[i] = line
+		return section
+	}
+	if line == "" {
+		return section
+	}
+	for i := range section {
+		if strings.HasPrefix(strings.TrimSpace(section[i]), "]") {
+			return append(section[:i+1], append([]string{line}, section[i+1:]...)...)
+		}
+	}
+	return append(section, line)
+}
+
+// writeFile replaces the file with a renamed temporary file, keeping its
+// permissions.
+func writeFile(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".config-*.toml")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(f.Name(), path))
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readConfig(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSaveColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		columns []string
+		widths  map[string]int
+		want    string
+	}{
+		{
+			name:    "single line",
+			content: "[views.channels]\ncolumns = [\"STATUS\", \"ALIAS\"]\n",
+			columns: []string{"ALIAS"},
+			want:    "[views.channels]\ncolumns = [\n\t\"ALIAS\",\n\t# \"STATUS\",\n]\n",
+		},
+		{
+			name:    "single line with a comment",
+			content: "[views.channels]\ncolumns = [\"STATUS\"] # \"ALIAS\"\nwidths = { \"STATUS\" = 8 }\n",
+			columns: []string{"STATUS", "ALIAS"},
+			want:    "[views.channels]\ncolumns = [\n\t\"STATUS\",\n\t\"ALIAS\",\n]\n",
+		},
+		{
+			name: "multi line",
+			content: "[views.channels]\ncolumns = [\n" +
+				"\t\"STATUS\", # status of the channel\n" +
+				"\t# more about the status\n" +
+				"\t\"ALIAS\",  # alias of the peer\n" +
+				"\t# \"GAUGE\",\n" +
+				"]\n\n[views.transactions]\ncolumns = [\"DATE\"]\n",
+			columns: []string{"GAUGE", "STATUS"},
+			widths:  map[string]int{"STATUS": 12},
+			want: "[views.channels]\ncolumns = [\n" +
+				"\t\"GAUGE\",\n" +
+				"\t\"STATUS\",      # status of the channel\n" +
+				"\t# more about the status\n" +
+				"\t# \"ALIAS\",     # alias of the peer\n" +
+				"]\nwidths = { \"STATUS\" = 12 }\n\n[views.transactions]\ncolumns = [\"DATE\"]\n",
+		},
+		{
+			name:    "missing columns",
+			content: "[views.channels]\nsort = [\"STATUS asc\"]\n\n[network]\nname = \"lnd\"\n",
+			columns: []string{"STATUS"},
+			want:    "[views.channels]\nsort = [\"STATUS asc\"]\ncolumns = [\n\t\"STATUS\",\n]\n\n[network]\nname = \"lnd\"\n",
+		},
+		{
+			name:    "missing section",
+			content: "[network]\nname = \"lnd\"\n",
+			columns: []string{"STATUS"},
+			want:    "[network]\nname = \"lnd\"\n\n[views.channels]\ncolumns = [\n\t\"STATUS\",\n]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.content)
+			err := SaveColumns(path, "channels", tt.columns, tt.widths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readConfig(t, path); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keys    []string
+		want    string
+	}{
+		{
+			name:    "single line columns",
+			content: "[views.channels]\ncolumns = [\"STATUS\", \"ALIAS\"]\n",
+			keys:    []string{"STATUS asc", "LOCAL desc"},
+			want:    "[views.channels]\ncolumns = [\"STATUS\", \"ALIAS\"]\nsort = [\"STATUS asc\", \"LOCAL desc\"]\n",
+		},
+		{
+			name:    "multi line columns",
+			content: "[views.channels]\ncolumns = [\n\t\"STATUS\",\n]\nsort = [\"ALIAS asc\"]\n",
+			keys:    []string{"LOCAL desc"},
+			want:    "[views.channels]\ncolumns = [\n\t\"STATUS\",\n]\nsort = [\"LOCAL desc\"]\n",
+		},
+		{
+			name:    "missing columns",
+			content: "[views.channels]\n",
+			keys:    []string{"LOCAL desc"},
+			want:    "[views.channels]\nsort = [\"LOCAL desc\"]\n",
+		},
+		{
+			name:    "cleared",
+			content: "[views.channels]\nsort = [\"ALIAS asc\"]\n",
+			want:    "[views.channels]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.content)
+			err := SaveSort(path, "channels", tt.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readConfig(t, path); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
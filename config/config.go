@@ -4,17 +4,56 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/expr"
+	"github.com/edouardparis/lntop/ui/color"
 )
 
 type Config struct {
 	Logger  Logger  `toml:"logger"`
 	Network Network `toml:"network"`
-	Views   Views   `toml:"views"`
+	// Nodes are the other nodes displayed by the ui, switched with n.
+	Nodes    []Network `toml:"nodes"`
+	Views    Views     `toml:"views"`
+	Control  Control   `toml:"control"`
+	Plugins  []Plugin  `toml:"plugins"`
+	Alerts   Alerts    `toml:"alerts"`
+	Backup   Backup    `toml:"backup"`
+	Firewall Firewall  `toml:"firewall"`
+	HTTP     HTTP      `toml:"http"`
+	Health   Health    `toml:"health"`
+	Refresh  Refresh   `toml:"refresh"`
+	Store    Store     `toml:"store"`
+	Export   Export    `toml:"export"`
+	Price    Price     `toml:"price"`
+	Fees     Fees      `toml:"fees"`
+	Theme    Theme     `toml:"theme"`
+	Mouse    Mouse     `toml:"mouse"`
+	Keys     Keys      `toml:"keys"`
+	PeerData PeerData  `toml:"peerdata"`
+	// Notifications are popped in the ui at the large forwards and the
+	// settled invoices.
+	Notifications Notifications `toml:"notifications"`
+	// Networks are the profiles of the bitcoin networks by name.
+	Networks map[string]NetworkProfile `toml:"networks"`
+	// Path is the file the config was loaded from, the columns chosen
+	// in the ui are written back to it.
+	Path string `toml:"-"`
+	// ReadOnly disables the actions changing the node, set by the
+	// --read-only flag.
+	ReadOnly bool `toml:"-"`
 }
 
 type Logger struct {
@@ -34,20 +73,696 @@ type Network struct {
 	ConnTimeout     int     `toml:"conn_timeout"`
 	PoolCapacity    int     `toml:"pool_capacity"`
 	Aliases         Aliases `toml:"aliases"`
+	// LNDConnect is an lndconnect uri replacing the address, the cert and
+	// the macaroon, e.g. the one of a hosted node.
+	LNDConnect string `toml:"lndconnect"`
+	// CertHex and MacaroonHex are the cert and the macaroon inline in hex,
+	// replacing the files.
+	CertHex     string `toml:"cert_hex"`
+	MacaroonHex string `toml:"macaroon_hex"`
+	// Proxy is the url of the SOCKS5 proxy of the connection to the node,
+	// e.g. socks5://127.0.0.1:9050 for Tor and an .onion address. The one
+	// of [network] is the proxy of [http] if it has none.
+	Proxy string `toml:"proxy"`
+	// APIKey is the invoice/read key of the wallet of type lnbits.
+	APIKey string `toml:"api_key"`
+	// InfoTimeout and ListChannelsTimeout are the deadlines of the calls
+	// to lnd, e.g. "10s", of the info and the balances of the node and of
+	// the lists of channels, none if empty. SubscribeKeepalive is the
+	// interval of the pings of the connection to lnd detecting a dead one
+	// under the subscriptions, none if empty.
+	InfoTimeout         string `toml:"info_timeout"`
+	ListChannelsTimeout string `toml:"listchannels_timeout"`
+	SubscribeKeepalive  string `toml:"subscribe_keepalive"`
+}
+
+// Timeouts are the deadlines of the calls to the node by category and the
+// interval of the keepalive pings, zero if none.
+type Timeouts struct {
+	Info         time.Duration
+	ListChannels time.Duration
+	Keepalive    time.Duration
+}
+
+// Timeouts returns the durations of InfoTimeout, ListChannelsTimeout and
+// SubscribeKeepalive.
+func (n *Network) Timeouts() (Timeouts, error) {
+	t := Timeouts{}
+	for _, d := range []struct {
+		name  string
+		value string
+		out   *time.Duration
+	}{
+		{"info_timeout", n.InfoTimeout, &t.Info},
+		{"listchannels_timeout", n.ListChannelsTimeout, &t.ListChannels},
+		{"subscribe_keepalive", n.SubscribeKeepalive, &t.Keepalive},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v < 0 {
+			return t, errors.Errorf("invalid %s %q", d.name, d.value)
+		}
+		*d.out = v
+	}
+	return t, nil
+}
+
+// NetworkProfile is the config of a bitcoin network. The profile given with
+// the --network flag overrides the connection of the network config, the
+// macaroon of the network is used if none. The explorer of the network of
+// the node links the transactions.
+type NetworkProfile struct {
+	Address  string `toml:"address"`
+	Cert     string `toml:"cert"`
+	Macaroon string `toml:"macaroon"`
+	// Explorer is the url of a transaction with %s for the txid, the
+	// mempool.space one of the network if empty, none if "off".
+	Explorer string `toml:"explorer"`
+}
+
+var defaultExplorers = map[string]string{
+	"mainnet":  "https://mempool.space/tx/%s",
+	"testnet":  "https://mempool.space/testnet/tx/%s",
+	"testnet4": "https://mempool.space/testnet4/tx/%s",
+	"signet":   "https://mempool.space/signet/tx/%s",
+}
+
+// UseNetwork overrides the connection of the network config with the
+// profile of the network name.
+func (c *Config) UseNetwork(name string) error {
+	p, ok := c.Networks[name]
+	if !ok && !isNetwork(name) {
+		return errors.Errorf("unknown network %q", name)
+	}
+	if p.Address != "" {
+		c.Network.Address = p.Address
+	}
+	if p.Cert != "" {
+		c.Network.Cert = p.Cert
+	}
+	if p.Macaroon != "" {
+		c.Network.Macaroon = p.Macaroon
+	} else if dir := path.Dir(c.Network.Macaroon); isNetwork(path.Base(dir)) {
+		// .lnd/data/chain/bitcoin/<network>/readonly.macaroon
+		c.Network.Macaroon = path.Join(path.Dir(dir), name, path.Base(c.Network.Macaroon))
+	}
+	return nil
+}
+
+// NodeConfig returns the config of the ith node of Nodes: its network
+// replaces the network config, the settings it does not set are the ones
+// of the network config.
+func (c *Config) NodeConfig(i int) *Config {
+	n := c.Nodes[i]
+	if n.Type == "" {
+		n.Type = c.Network.Type
+	}
+	if n.MacaroonTimeOut == 0 {
+		n.MacaroonTimeOut = c.Network.MacaroonTimeOut
+	}
+	if n.MaxMsgRecvSize == 0 {
+		n.MaxMsgRecvSize = c.Network.MaxMsgRecvSize
+	}
+	if n.ConnTimeout == 0 {
+		n.ConnTimeout = c.Network.ConnTimeout
+	}
+	if n.PoolCapacity == 0 {
+		n.PoolCapacity = c.Network.PoolCapacity
+	}
+	if n.Aliases == nil {
+		n.Aliases = c.Network.Aliases
+	}
+	if n.Proxy == "" {
+		n.Proxy = c.Network.Proxy
+	}
+	if n.InfoTimeout == "" {
+		n.InfoTimeout = c.Network.InfoTimeout
+	}
+	if n.ListChannelsTimeout == "" {
+		n.ListChannelsTimeout = c.Network.ListChannelsTimeout
+	}
+	if n.SubscribeKeepalive == "" {
+		n.SubscribeKeepalive = c.Network.SubscribeKeepalive
+	}
+	cfg := *c
+	cfg.Network = n
+	cfg.Nodes = nil
+	return &cfg
+}
+
+// Explorer returns the url of a transaction of the explorer of the
+// network, empty if none.
+func (c *Config) Explorer(network string) string {
+	explorer := c.Networks[network].Explorer
+	if explorer == "" {
+		return defaultExplorers[network]
+	}
+	if explorer == "off" {
+		return ""
+	}
+	return explorer
+}
+
+func isNetwork(name string) bool {
+	switch name {
+	case "mainnet", "testnet", "testnet4", "signet", "regtest", "simnet":
+		return true
+	}
+	return false
+}
+
+type Control struct {
+	// Socket is the path of the unix socket of the JSON-RPC
+	// control server, the server is disabled if empty.
+	Socket string `toml:"socket"`
+}
+
+type Alerts struct {
+	// Interval is the number of seconds between two checks of the rules.
+	Interval int `toml:"interval"`
+	// Command is run for each alert with the message as last argument.
+	Command []string `toml:"command"`
+	// Webhook is the URL receiving each alert as a JSON POST.
+	Webhook         string               `toml:"webhook"`
+	Telegram        TelegramAlert        `toml:"telegram"`
+	Liquidity       []LiquidityAlert     `toml:"liquidity"`
+	PeerOffline     PeerOfflineAlert     `toml:"peer_offline"`
+	ChannelInactive ChannelInactiveAlert `toml:"channel_inactive"`
+	PeerDisabled    PeerDisabledAlert    `toml:"peer_disabled"`
+	HTLCFailures    HTLCFailuresAlert    `toml:"htlc_failures"`
+	WalletBalance   WalletBalanceAlert   `toml:"wallet_balance"`
+	ForceClose      ForceCloseAlert      `toml:"force_close"`
+	FeeChange       FeeChangeAlert       `toml:"fee_change"`
+}
+
+// TelegramAlert sends the alerts to a chat with a bot, disabled if the
+// token is empty.
+type TelegramAlert struct {
+	Token  string `toml:"token"`
+	ChatID string `toml:"chat_id"`
+}
+
+type LiquidityAlert struct {
+	// Channel is a channel point, a channel id, a short channel id or
+	// a peer pubkey, the rule applies to every channel if empty.
+	Channel     string  `toml:"channel"`
+	MinOutbound float64 `toml:"min_outbound"`
+	MinInbound  float64 `toml:"min_inbound"`
+	// Duration is how long the liquidity must stay below the threshold.
+	Duration string `toml:"duration"`
+}
+
+type PeerOfflineAlert struct {
+	// Duration is how long a channel peer must be offline, disabled if
+	// empty.
+	Duration string `toml:"duration"`
+	// Peers overrides the duration by peer pubkey, "off" disables the
+	// alert for the peer.
+	Peers map[string]string `toml:"peers"`
+}
+
+type ChannelInactiveAlert struct {
+	// Duration is how long a channel must be inactive, disabled if
+	// empty.
+	Duration string `toml:"duration"`
+}
+
+type PeerDisabledAlert struct {
+	// Duration is how long the peer of a channel must keep its policy
+	// toward the node disabled, disabled if empty.
+	Duration string `toml:"duration"`
+}
+
+type HTLCFailuresAlert struct {
+	// MaxRate is the percentage of the forwarded HTLCs failing over the
+	// window above which the alert is raised, disabled if zero.
+	MaxRate float64 `toml:"max_rate"`
+	// Window is the duration of the forwards counted, one hour if empty.
+	Window string `toml:"window"`
+	// MinHTLCs is the number of forwards of the window needed to compute
+	// the rate.
+	MinHTLCs int `toml:"min_htlcs"`
+}
+
+type WalletBalanceAlert struct {
+	// Min is the floor of the confirmed on-chain balance in satoshis,
+	// disabled if zero.
+	Min int64 `toml:"min"`
+	// AnchorReserve raises the alert when the confirmed balance is below
+	// the reserve of the anchor channels reported by the node.
+	AnchorReserve bool `toml:"anchor_reserve"`
+}
+
+// ForceCloseAlert is enabled by default, the alert is critical and pinned
+// in the ui until acknowledged.
+type ForceCloseAlert struct {
+	Disabled bool `toml:"disabled"`
+}
+
+// FeeChangeAlert is enabled by default, a peer raising the fee rate of
+// its policy toward the node by MinIncrease ppm or more raises a warning
+// kept for Duration. The policy changes are recorded even if Disabled.
+type FeeChangeAlert struct {
+	Disabled    bool   `toml:"disabled"`
+	MinIncrease int64  `toml:"min_increase"`
+	Duration    string `toml:"duration"`
+}
+
+type Backup struct {
+	// Verify checks every channel backup snapshot with the node.
+	Verify bool `toml:"verify"`
+	// Type is the destination of the snapshots: "local", "scp", "s3" or
+	// "http", they are not uploaded if empty.
+	Type string `toml:"type"`
+	// Path is the directory of the local copies, the scp target
+	// (user@host:dir), the prefix of the s3 keys or the url the
+	// snapshots are posted to.
+	Path string `toml:"path"`
+	// Keep is the number of local copies kept, all if zero.
+	Keep      int    `toml:"keep"`
+	Endpoint  string `toml:"endpoint"`
+	Bucket    string `toml:"bucket"`
+	Region    string `toml:"region"`
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key"`
+	// Export is the destination of the backup exported from the ui, a
+	// directory, a scp target or a http(s) url, the one of Type if empty.
+	Export string `toml:"export"`
+}
+
+// Firewall is the config of the HTLC interceptor, the HTLCs forwarded
+// through the node breaking a rule are failed and the other ones resumed,
+// or held until they are resolved in the ui.
+type Firewall struct {
+	Enabled bool `toml:"enabled"`
+	// Hold holds the HTLCs breaking no rule instead of resuming them.
+	Hold bool `toml:"hold"`
+	// HoldTimeout is the number of seconds a held HTLC waits before it
+	// is failed, 60 if zero.
+	HoldTimeout int `toml:"hold_timeout"`
+	// MinFeePPM fails the forwards paying a lower fee rate.
+	MinFeePPM uint64 `toml:"min_fee_ppm"`
+	// MaxHTLC is the largest amount in sat of an HTLC, no limit if zero.
+	MaxHTLC int64 `toml:"max_htlc"`
+	// MaxExposure is the largest total in sat of the HTLCs resumed and
+	// not yet settled or failed, no limit if zero.
+	MaxExposure int64          `toml:"max_exposure"`
+	Peers       []FirewallPeer `toml:"peers"`
+}
+
+// FirewallPeer is the rule of the HTLCs from or to a peer, its limits
+// apply with the ones of the firewall.
+type FirewallPeer struct {
+	// Peer is the pubkey of the peer.
+	Peer string `toml:"peer"`
+	// Action is "resume", "hold" or "fail" for the HTLCs breaking no
+	// limit, the one of the firewall if empty.
+	Action      string `toml:"action"`
+	MaxHTLC     int64  `toml:"max_htlc"`
+	MaxExposure int64  `toml:"max_exposure"`
+}
+
+// Health is the weights of the components of the channel health score,
+// the defaults are used if they are all zero.
+type Health struct {
+	Uptime   float64 `toml:"uptime"`
+	Balance  float64 `toml:"balance"`
+	Forwards float64 `toml:"forwards"`
+	Fees     float64 `toml:"fees"`
+	HTLCs    float64 `toml:"htlcs"`
+	// ForwardsTarget is the number of forwards of the history for the
+	// full forwards component.
+	ForwardsTarget int `toml:"forwards_target"`
+}
+
+// Refresh is the number of seconds between two polls of the node: Min
+// while events flow or keys are pressed, doubled up to Max once nothing
+// happened for Idle seconds. The defaults are used if zero.
+type Refresh struct {
+	Min  int `toml:"min"`
+	Max  int `toml:"max"`
+	Idle int `toml:"idle"`
+	// InfoInterval, BalanceInterval and PeersInterval are the number of
+	// seconds between two polls of the info, the channels balance and the
+	// peers of the node, at the polls of Min and Max if zero and never if
+	// negative.
+	InfoInterval    int `toml:"info_interval"`
+	BalanceInterval int `toml:"balance_interval"`
+	PeersInterval   int `toml:"peers_interval"`
+}
+
+// Store is the file of the data recorded across restarts, nothing is
+// recorded if Path is empty.
+type Store struct {
+	Path string `toml:"path"`
+	// BalanceInterval is the number of seconds between two samples of the
+	// balances of the channels, 300 if zero and none if negative.
+	BalanceInterval int `toml:"balance_interval"`
+	// BalanceDays is the number of days the samples are kept, 30 if zero.
+	BalanceDays int `toml:"balance_days"`
+	// AliasTTL is the number of hours the aliases of the nodes of the
+	// channels recorded are used before they are fetched again, 24 if
+	// zero and none recorded if negative.
+	AliasTTL int `toml:"alias_ttl"`
+}
+
+// BalanceSampling returns the interval between two samples of the
+// balances and the number of days they are kept, the interval is zero if
+// the balances are not sampled.
+func (s Store) BalanceSampling() (time.Duration, int) {
+	if s.Path == "" || s.BalanceInterval < 0 {
+		return 0, 0
+	}
+	interval := time.Duration(s.BalanceInterval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Minute
+	}
+	days := s.BalanceDays
+	if days <= 0 {
+		days = 30
+	}
+	return interval, days
+}
+
+// AliasCache returns how long the aliases recorded are used before they
+// are fetched again, zero if they are not recorded.
+func (s Store) AliasCache() time.Duration {
+	if s.Path == "" || s.AliasTTL < 0 {
+		return 0
+	}
+	if s.AliasTTL == 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(s.AliasTTL) * time.Hour
+}
+
+// Export is the config of the files fed continuously with the data of the
+// node.
+type Export struct {
+	// Routing is the file every routing event is appended to as a JSON
+	// line, disabled if empty.
+	Routing string `toml:"routing"`
+	// Dir is the directory of the views exported from the ui, the one of
+	// the config file if empty.
+	Dir string `toml:"dir"`
+	// Format is the format of the views exported from the ui, csv or
+	// json, csv if empty.
+	Format string `toml:"format"`
+}
+
+// Price is the config of the price feed of the fiat columns, which are
+// empty if Provider is empty.
+type Price struct {
+	// Provider is kraken, coingecko or static.
+	Provider string `toml:"provider"`
+	// Currency is the fiat currency, e.g. USD or EUR.
+	Currency string `toml:"currency"`
+	// Rate is the price of a bitcoin with the static provider.
+	Rate float64 `toml:"rate"`
+	// Interval is the number of seconds between two fetches of the rate.
+	Interval int `toml:"interval"`
+}
+
+// Fees is the source of the on-chain fee rates of the header, the dashboard
+// and the dialogs.
+type Fees struct {
+	// Source is node, the estimates of the wallet, or mempool, the api
+	// of URL. node if empty.
+	Source string `toml:"source"`
+	// URL is the mempool.space compatible api, https://mempool.space if
+	// empty.
+	URL string `toml:"url"`
+	// Interval is the number of seconds between two estimates, 60 if
+	// zero.
+	Interval int `toml:"interval"`
+}
+
+// Theme is the config of the colors of the ui.
+type Theme struct {
+	// Name is dark, light, solarized or high-contrast, dark if empty.
+	Name string `toml:"name"`
+	// TrueColor displays the #rrggbb colors as is, they are the nearest
+	// of the 256 colors otherwise.
+	TrueColor bool `toml:"truecolor"`
+	// Colors overrides the colors of the theme, e.g. header_bg = "#005f87".
+	Colors map[string]string `toml:"colors"`
+}
+
+// Mouse is enabled by default, a click selects a row or sorts a column,
+// the wheel scrolls and the column headers dragged move the split between
+// the summary and the view. The terminal selects the text only with a key
+// held, often shift, while it is enabled.
+type Mouse struct {
+	Disabled bool `toml:"disabled"`
+}
+
+// Keys is the keymap of the global actions of the ui: the keys of a
+// profile, default or vim, replaced by the ones given by action, e.g.
+// down = ["j", "ctrl+n"].
+type Keys struct {
+	Profile string
+	Actions map[string][]string
+}
+
+// UnmarshalTOML decodes the profile and the keys of the actions, a key
+// alone or a list of keys.
+func (k *Keys) UnmarshalTOML(data interface{}) error {
+	table, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.New("keys must be a table")
+	}
+	k.Actions = make(map[string][]string)
+	for name, value := range table {
+		switch v := value.(type) {
+		case string:
+			if name == "profile" {
+				k.Profile = v
+				continue
+			}
+			k.Actions[name] = []string{v}
+		case []interface{}:
+			keys := make([]string, len(v))
+			for i := range v {
+				key, ok := v[i].(string)
+				if !ok {
+					return errors.Errorf("keys of %q must be strings", name)
+				}
+				keys[i] = key
+			}
+			k.Actions[name] = keys
+		default:
+			return errors.Errorf("keys of %q must be a string or a list", name)
+		}
+	}
+	return nil
+}
+
+// HTTP is the config of the requests to web services, like LNURL.
+type HTTP struct {
+	// Proxy is the url of the proxy, e.g. socks5://127.0.0.1:9050 for
+	// Tor, the proxy of [network] or the HTTP_PROXY and HTTPS_PROXY
+	// variables are used if empty.
+	Proxy string `toml:"proxy"`
+	// Timeout is the number of seconds to wait for an answer.
+	Timeout int `toml:"timeout"`
+}
+
+// defaultHTTPTimeout is the timeout of the requests without one in the
+// config.
+const defaultHTTPTimeout = 30 * time.Second
+
+// Client returns the http client of the requests to web services, through
+// the proxy and with the timeout of the config.
+func (h HTTP) Client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if h.Proxy != "" {
+		proxy, err := url.Parse(h.Proxy)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	timeout := defaultHTTPTimeout
+	if h.Timeout > 0 {
+		timeout = time.Duration(h.Timeout) * time.Second
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// PeerData is the source of the metadata of the peers of the SCORE, TAGS
+// and RANK columns of the channels view.
+type PeerData struct {
+	// Source is the url of the JSON document of the metadata or the path
+	// of a local file, disabled if empty.
+	Source string `toml:"source"`
+	// Interval is the number of seconds between two fetches, an hour if
+	// zero.
+	Interval int `toml:"interval"`
+	// Cache is the file the document of the url is kept in,
+	// peerdata.json in the directory of the config file by default.
+	Cache string `toml:"cache"`
+}
+
+// Notifications is the config of the notifications popped in the ui, kept
+// in the notifications view.
+type Notifications struct {
+	// Duration is how long a notification is displayed, "5s" if empty.
+	Duration string           `toml:"duration"`
+	Forwards NotificationRule `toml:"forwards"`
+	Invoices NotificationRule `toml:"invoices"`
+}
+
+// NotificationRule notifies the events of at least MinAmount satoshis, for
+// the forwards 1000000 if zero.
+type NotificationRule struct {
+	Disabled  bool  `toml:"disabled"`
+	MinAmount int64 `toml:"min_amount"`
+}
+
+type Plugin struct {
+	Name    string   `toml:"name"`
+	Command []string `toml:"command"`
+	// Interval is the number of seconds between two renders.
+	Interval int `toml:"interval"`
+	// Timeout is the number of seconds to wait for a response.
+	Timeout int `toml:"timeout"`
 }
 
 type Views struct {
-	Channels     *View `toml:"channels"`
-	Transactions *View `toml:"transactions"`
-	Routing      *View `toml:"routing"`
-	FwdingHist   *View `toml:"fwdinghist"`
+	Channels       *View `toml:"channels"`
+	Pending        *View `toml:"pending"`
+	Transactions   *View `toml:"transactions"`
+	Routing        *View `toml:"routing"`
+	FwdingHist     *View `toml:"fwdinghist"`
+	Peers          *View `toml:"peers"`
+	Closed         *View `toml:"closed"`
+	Sweeps         *View `toml:"sweeps"`
+	Payments       *View `toml:"payments"`
+	Invoices       *View `toml:"invoices"`
+	UTXOs          *View `toml:"utxos"`
+	Towers         *View `toml:"towers"`
+	Offers         *View `toml:"offers"`
+	MissionControl *View `toml:"missioncontrol"`
+	Graph          *View `toml:"graph"`
+	Firewall       *View `toml:"firewall"`
+	// Session is the bar of the rates of the forwards of the session.
+	Session Session `toml:"session"`
+	// RoutingChart is the chart below the routing view.
+	RoutingChart RoutingChart `toml:"routing_chart"`
+	// Main is the view displayed at start, e.g. "dashboard", the channels
+	// view if empty.
+	Main string `toml:"main"`
+}
+
+// Session is the bar at the bottom of the screen of the forwards per
+// minute, the amounts forwarded and the fees earned by hour and the part of
+// the forwards settled, over the rolling Window, 15m if empty.
+type Session struct {
+	Disabled bool   `toml:"disabled"`
+	Window   string `toml:"window"`
+}
+
+// RoutingChart is the chart of the forwards settled and of the fees earned
+// by Interval over the last Window, "1h" and "24h" if empty, drawn from the
+// routing events recorded in the store.
+type RoutingChart struct {
+	Disabled bool   `toml:"disabled"`
+	Window   string `toml:"window"`
+	Interval string `toml:"interval"`
 }
 
 type ColumnOptions map[string]map[string]string
 
 type View struct {
-	Columns []string      `toml:"columns"`
-	Options ColumnOptions `toml:"options"`
+	Columns    []string                  `toml:"columns"`
+	Options    ColumnOptions             `toml:"options"`
+	Computed   map[string]ComputedColumn `toml:"computed"`
+	Highlights []Highlight               `toml:"highlights"`
+	Presets    []ColumnPreset            `toml:"presets"`
+	// Widths are the widths of the columns overriding their default
+	// ones, the cells are padded or cut.
+	Widths map[string]int `toml:"widths"`
+	// Filter is the expression of the rows displayed at start, for the
+	// channels view.
+	Filter string `toml:"filter"`
+	// Sort are the sort keys of the channels view, a column followed by
+	// asc or desc, the rows equal by a key are sorted by the next one.
+	Sort []string `toml:"sort"`
+}
+
+// ColumnPreset is the set of columns of a view for the terminals from
+// MinWidth to MaxWidth columns wide, MaxWidth is unbounded if zero.
+type ColumnPreset struct {
+	MinWidth int      `toml:"min_width"`
+	MaxWidth int      `toml:"max_width"`
+	Columns  []string `toml:"columns"`
+}
+
+// ColumnsFor returns the columns of the first preset of the width,
+// Columns if none matches.
+func (v *View) ColumnsFor(width int) []string {
+	for _, p := range v.Presets {
+		if width >= p.MinWidth && (p.MaxWidth == 0 || width <= p.MaxWidth) && len(p.Columns) > 0 {
+			return p.Columns
+		}
+	}
+	return v.Columns
+}
+
+// Highlight colors the rows for which the expression is true, or only
+// the cell of Column if set. The first matching highlight is used.
+type Highlight struct {
+	Expr       string `toml:"expr"`
+	Color      string `toml:"color"`
+	Background bool   `toml:"background"`
+	Column     string `toml:"column"`
+}
+
+// ComputedColumn is a column whose value is the result of an expression,
+// see the expr package.
+type ComputedColumn struct {
+	Expr   string `toml:"expr"`
+	Format string `toml:"format"`
+	Width  int    `toml:"width"`
+}
+
+// validate parses the expressions of the filter, of the computed columns
+// and of the highlights of the view, the error names the column or the
+// number of the highlight of the invalid one.
+func (v *View) validate() error {
+	if v == nil {
+		return nil
+	}
+	if strings.TrimSpace(v.Filter) != "" {
+		_, err := expr.Parse(v.Filter)
+		if err != nil {
+			return errors.Wrap(err, "filter")
+		}
+	}
+	names := make([]string, 0, len(v.Computed))
+	for name := range v.Computed {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		_, err := expr.Parse(v.Computed[name].Expr)
+		if err != nil {
+			return errors.Wrapf(err, "computed column %s", name)
+		}
+	}
+	for i, h := range v.Highlights {
+		_, err := expr.Parse(h.Expr)
+		if err != nil {
+			return errors.Wrapf(err, "highlight %d", i+1)
+		}
+		if _, ok := color.ByName(strings.ToLower(h.Color)); !ok {
+			return errors.Errorf("highlight %d: unknown color %q", i+1, h.Color)
+		}
+	}
+	return nil
 }
 
 func (co ColumnOptions) GetOption(columnName, option string) string {
@@ -76,6 +791,37 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
+	err = c.Network.applyLNDConnect()
+	if err != nil {
+		return nil, err
+	}
+	for i := range c.Nodes {
+		err = c.Nodes[i].applyLNDConnect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.Views.Channels.validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "views.channels")
+	}
+
+	if c.HTTP.Proxy == "" {
+		c.HTTP.Proxy = c.Network.Proxy
+	}
+
+	c.Path = path
+	if c.Export.Dir == "" {
+		c.Export.Dir = filepath.Dir(path)
+	}
+	if c.Export.Format == "" {
+		c.Export.Format = "csv"
+	}
+	if c.PeerData.Cache == "" {
+		c.PeerData.Cache = filepath.Join(filepath.Dir(path), "peerdata.json")
+	}
+
 	return c, nil
 }
 
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadViews(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "valid computed column",
+			content: "[views.channels.computed]\nLOCAL_PCT = { expr = \"local_balance / capacity * 100\" }\n",
+		},
+		{
+			name:    "invalid computed column",
+			content: "[views.channels.computed]\nLOCAL_PCT = { expr = \"local_balance /\" }\n",
+			err:     "views.channels: computed column LOCAL_PCT",
+		},
+		{
+			name:    "valid filter",
+			content: "[views.channels]\nfilter = \"active && capacity > 1000000\"\n",
+		},
+		{
+			name:    "invalid filter",
+			content: "[views.channels]\nfilter = \"active &&\"\n",
+			err:     "views.channels: filter",
+		},
+		{
+			name:    "valid highlight",
+			content: "[[views.channels.highlights]]\nexpr = \"!active\"\ncolor = \"Red\"\n",
+		},
+		{
+			name: "invalid highlight expression",
+			content: "[[views.channels.highlights]]\nexpr = \"!active\"\ncolor = \"red\"\n" +
+				"[[views.channels.highlights]]\nexpr = \"active &&\"\ncolor = \"red\"\n",
+			err: "views.channels: highlight 2",
+		},
+		{
+			name:    "unknown highlight color",
+			content: "[[views.channels.highlights]]\nexpr = \"!active\"\ncolor = \"pink\"\n",
+			err:     `views.channels: highlight 1: unknown color "pink"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// applyLNDConnect sets the address, the cert and the macaroon of the
+// network from its lndconnect uri, if any: the base64url cert and
+// macaroon of the uri replace the hex ones.
+func (n *Network) applyLNDConnect() error {
+	if n.LNDConnect == "" {
+		return nil
+	}
+	// the errors do not quote the uri, it holds the macaroon.
+	u, err := url.Parse(n.LNDConnect)
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return errors.Wrap(err, "invalid lndconnect uri")
+	}
+	if u.Scheme != "lndconnect" || u.Host == "" {
+		return errors.New("lndconnect is not an lndconnect://host:port uri")
+	}
+	n.Address = "//" + u.Host
+
+	query := u.Query()
+	if cert := query.Get("cert"); cert != "" {
+		data, err := decodeBase64URL(cert)
+		if err != nil {
+			return errors.Wrap(err, "invalid cert of the lndconnect uri")
+		}
+		n.CertHex = hex.EncodeToString(data)
+	}
+	if mac := query.Get("macaroon"); mac != "" {
+		data, err := decodeBase64URL(mac)
+		if err != nil {
+			return errors.Wrap(err, "invalid macaroon of the lndconnect uri")
+		}
+		n.MacaroonHex = hex.EncodeToString(data)
+	}
+	return nil
+}
+
+// MacaroonBytes returns the macaroon of the network, the hex one if set,
+// otherwise the one of the macaroon file.
+func (n *Network) MacaroonBytes() ([]byte, error) {
+	if n.MacaroonHex != "" {
+		data, err := hex.DecodeString(stripSpaces(n.MacaroonHex))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid macaroon_hex")
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(n.Macaroon)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// CertDER returns the DER TLS certificate of the network, the hex one if
+// set, of a PEM or a DER certificate, otherwise the one of the cert file.
+// It is nil if the network has none, the node certificate is then
+// verified with the roots of the system.
+func (n *Network) CertDER() ([]byte, error) {
+	var data []byte
+	source := n.Cert
+	switch {
+	case n.CertHex != "":
+		var err error
+		data, err = hex.DecodeString(stripSpaces(n.CertHex))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cert_hex")
+		}
+		source = "cert_hex"
+		if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+			return data, nil
+		}
+	case n.Cert != "":
+		var err error
+		data, err = os.ReadFile(n.Cert)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	default:
+		return nil, nil
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("%s is not a PEM certificate", source)
+	}
+	return block.Bytes, nil
+}
+
+// decodeBase64URL decodes the base64url of an lndconnect uri, padded or
+// not.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// stripSpaces removes the spaces and the line breaks of a hex string, e.g.
+// the lines of xxd -p.
+func stripSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
@@ -0,0 +1,245 @@
+// Package control implements a small JSON-RPC server listening on a
+// unix socket, used by external tools to drive a running lntop.
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/logging"
+)
+
+const (
+	ErrCodeParse          = -32700
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInternal       = -32603
+)
+
+// Request is a JSON-RPC 2.0 request, one per line.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response, one per line.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// Handler handles the params of a request and returns its result.
+type Handler func(params json.RawMessage) (interface{}, error)
+
+type Server struct {
+	path     string
+	logger   logging.Logger
+	handlers map[string]Handler
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+// Handle registers the handler of the given method.
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+// ListenAndServe listens on the unix socket and serves the requests
+// until the context is done or the server is closed.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	l, err := Listen(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.Close()
+	}()
+
+	s.logger.Info("control socket listening", logging.String("path", s.path))
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.WithStack(err)
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handle(line)
+		err := enc.Encode(resp)
+		if err != nil {
+			s.logger.Debug("control: cannot write response", logging.Error(err))
+			return
+		}
+	}
+}
+
+func (s *Server) handle(line []byte) *Response {
+	resp := &Response{JSONRPC: "2.0"}
+
+	req := Request{}
+	err := json.Unmarshal(line, &req)
+	if err != nil {
+		resp.Error = &Error{Code: ErrCodeParse, Message: err.Error()}
+		return resp
+	}
+	resp.ID = req.ID
+
+	h, ok := s.handlers[req.Method]
+	if !ok {
+		resp.Error = &Error{
+			Code:    ErrCodeMethodNotFound,
+			Message: fmt.Sprintf("method %q not found", req.Method),
+		}
+		return resp
+	}
+
+	s.logger.Debug("control request", logging.String("method", req.Method))
+	result, err := h(req.Params)
+	if err != nil {
+		resp.Error = &Error{Code: ErrCodeInternal, Message: err.Error()}
+		return resp
+	}
+	if result == nil {
+		result = "ok"
+	}
+	resp.Result = result
+	return resp
+}
+
+// Listen listens on the unix socket of the path, replacing the one left
+// by a previous instance. The socket is bound in a directory only the
+// user can enter and moved to the path once its mode is 0600, the other
+// users never see it with the mode of the umask. It is not removed from
+// the path when the listener is closed.
+func Listen(path string) (net.Listener, error) {
+	// a socket left by a previous instance prevents to listen.
+	if _, err := os.Stat(path); err == nil {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+			return nil, errors.Errorf("socket %s already in use", path)
+		}
+		os.Remove(path)
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".lntop-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// the socket is removed from its path by the caller.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	err = os.Chmod(tmp, 0600)
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		l.Close()
+		return nil, errors.WithStack(err)
+	}
+	return l, nil
+}
+
+// Close stops the listener and removes the socket file.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.listener = nil
+	os.Remove(s.path)
+	return err
+}
+
+// Call sends a single request to the socket and returns the raw result.
+func Call(path, method string, params interface{}) (json.RawMessage, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer conn.Close()
+
+	req := Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method}
+	if params != nil {
+		req.Params, err = json.Marshal(params)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}{}
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+func New(path string, logger logging.Logger) *Server {
+	return &Server{
+		path:     path,
+		logger:   logger.With(logging.String("logger", "control")),
+		handlers: make(map[string]Handler),
+	}
+}
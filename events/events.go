@@ -1,18 +1,67 @@
+// Package events defines the events sent by the pubsub package. The
+// types are stable, Data is set only for the events documented with a
+// data type and is read with the typed accessors.
 package events
 
+import (
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/models"
+)
+
 const (
 	BlockReceived         = "block.received"
-	ChannelActive         = "channel.active"
 	ChannelBalanceUpdated = "channel.balance.updated"
-	ChannelInactive       = "channel.inactive"
 	ChannelPending        = "channel.pending"
-	InvoiceCreated        = "invoice.created"
-	InvoiceSettled        = "invoice.settled"
 	PeerUpdated           = "peer.updated"
+	PeerTrafficUpdated    = "peer.traffic.updated"
 	TransactionCreated    = "transaction.created"
 	WalletBalanceUpdated  = "wallet.balance.updated"
-	RoutingEventUpdated   = "routing.event.updated"
-	GraphUpdated          = "graph.updated"
+	// ChannelActive and ChannelInactive carry the *models.ChannelUpdate
+	// of the channel when it is reported by the node, ChannelActive the
+	// one of its open as well.
+	ChannelActive   = "channel.active"
+	ChannelInactive = "channel.inactive"
+	// InvoiceCreated and InvoiceSettled carry the *models.Invoice when it
+	// is reported by the node.
+	InvoiceCreated = "invoice.created"
+	InvoiceSettled = "invoice.settled"
+	// PaymentUpdated carries the *models.Payment of an outgoing payment.
+	PaymentUpdated = "payment.updated"
+	// KeysendUpdated carries the *models.Payment of a keysend or an AMP
+	// payment sent from the ui, at each update until it succeeded or
+	// failed.
+	KeysendUpdated = "payment.keysend.updated"
+	// RoutingEventUpdated carries a *models.RoutingEvent.
+	RoutingEventUpdated = "routing.event.updated"
+	// GraphUpdated carries a *models.ChannelEdgeUpdate.
+	GraphUpdated = "graph.updated"
+	// ChannelClosing carries the *models.ChannelUpdate of a close
+	// requested from the ui, until the node reports the channel closing.
+	ChannelClosing = "channel.closing"
+	// ChannelClosed carries the *models.ChannelUpdate of the close.
+	ChannelClosed = "channel.closed"
+	// ChannelDisabledByPeer carries the *models.ChannelUpdate of a
+	// channel whose peer announced its policy toward the node disabled.
+	ChannelDisabledByPeer = "channel.disabled.peer"
+	// ChannelBackupUpdated carries the *models.ChannelBackup written by
+	// the node at each open and close of a channel.
+	ChannelBackupUpdated = "channel.backup.updated"
+	// AlertRaised and AlertResolved carry an *alerts.Alert.
+	AlertRaised   = "alert.raised"
+	AlertResolved = "alert.resolved"
+	// NodeStateChanged carries the models.NodeState of the node when it
+	// becomes ready or stops being ready.
+	NodeStateChanged = "node.state.changed"
+	// ConnectionChanged carries the models.Connection of the node when
+	// it stops answering, at each attempt to reach it and once it answers
+	// again.
+	ConnectionChanged = "connection.changed"
+	// HTLCIntercepted carries the *models.InterceptedHTLC of an HTLC held
+	// by the firewall, and again once it is resolved.
+	HTLCIntercepted = "htlc.intercepted"
+	// ConfigReloaded carries the *config.Config loaded again from the
+	// config file after it changed or at SIGHUP.
+	ConfigReloaded = "config.reloaded"
 )
 
 type Event struct {
@@ -21,6 +70,61 @@ type Event struct {
 	Data interface{}
 }
 
+// RoutingEvent returns the data of a RoutingEventUpdated event.
+func (e *Event) RoutingEvent() (*models.RoutingEvent, bool) {
+	re, ok := e.Data.(*models.RoutingEvent)
+	return re, ok
+}
+
+// Payment returns the data of a PaymentUpdated or KeysendUpdated event.
+func (e *Event) Payment() (*models.Payment, bool) {
+	p, ok := e.Data.(*models.Payment)
+	return p, ok
+}
+
+// ChannelEdgeUpdate returns the data of a GraphUpdated event.
+func (e *Event) ChannelEdgeUpdate() (*models.ChannelEdgeUpdate, bool) {
+	gu, ok := e.Data.(*models.ChannelEdgeUpdate)
+	return gu, ok
+}
+
+// ChannelUpdate returns the data of a ChannelActive, ChannelInactive,
+// ChannelClosing or ChannelClosed event.
+func (e *Event) ChannelUpdate() (*models.ChannelUpdate, bool) {
+	cu, ok := e.Data.(*models.ChannelUpdate)
+	return cu, ok
+}
+
+// InterceptedHTLC returns the data of an HTLCIntercepted event.
+func (e *Event) InterceptedHTLC() (*models.InterceptedHTLC, bool) {
+	h, ok := e.Data.(*models.InterceptedHTLC)
+	return h, ok
+}
+
+// Invoice returns the data of an InvoiceCreated or InvoiceSettled event.
+func (e *Event) Invoice() (*models.Invoice, bool) {
+	i, ok := e.Data.(*models.Invoice)
+	return i, ok
+}
+
+// NodeState returns the data of a NodeStateChanged event.
+func (e *Event) NodeState() (models.NodeState, bool) {
+	s, ok := e.Data.(models.NodeState)
+	return s, ok
+}
+
+// Connection returns the data of a ConnectionChanged event.
+func (e *Event) Connection() (models.Connection, bool) {
+	c, ok := e.Data.(models.Connection)
+	return c, ok
+}
+
+// Config returns the data of a ConfigReloaded event.
+func (e *Event) Config() (*config.Config, bool) {
+	c, ok := e.Data.(*config.Config)
+	return c, ok
+}
+
 func New(kind string) *Event {
 	return &Event{Type: kind}
 }
@@ -0,0 +1,335 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// Kinds of the accounting entries.
+const (
+	Deposit    = "deposit"
+	Withdrawal = "withdrawal"
+	Received   = "received"
+	Sent       = "sent"
+	Rebalance  = "rebalance"
+	Forward    = "forward"
+	// Transfer is a transaction moving the funds between the wallet and
+	// the channels of the node, only its fee is an entry.
+	Transfer = "transfer"
+)
+
+// Formats of the accounting export.
+const (
+	Generic      = "generic"
+	Koinly       = "koinly"
+	CoinTracking = "cointracking"
+)
+
+// Entry is a movement of the funds of the node, the amounts are in msat.
+type Entry struct {
+	Time time.Time
+	Kind string
+	// Amount is the amount received or sent without the fee, the fee
+	// earned for a forward, zero for a rebalance and a transfer.
+	Amount      int64
+	Fee         int64
+	ID          string
+	Description string
+	// Rate is the price of a bitcoin in the fiat currency at the time of
+	// the entry, zero without fiat values.
+	Rate float64
+}
+
+// NewEntries returns the entries of the confirmed transactions, of the
+// settled invoices, of the succeeded payments and of the forwards, by
+// time. A payment of an invoice of the node is a rebalance, only its fee
+// is an entry. The fundings of the channels, open, pending or closed,
+// their closings and the sweeps of their outputs are transfers.
+func NewEntries(txs []*models.Transaction, channels []*models.Channel, closed []*models.ClosedChannel,
+	invoices []*models.Invoice, payments []*models.Payment, forwards []*models.ForwardingEvent) []*Entry {
+	transfers := map[string]bool{}
+	for _, c := range channels {
+		transfers[txid(c.ChannelPoint)] = true
+		if c.Closing != nil && c.Closing.ClosingTxID != "" {
+			transfers[c.Closing.ClosingTxID] = true
+		}
+	}
+	// the fee of a closing is paid by the funds of the channel, it is not
+	// one of the wallet.
+	closingFees := map[string]int64{}
+	for _, c := range closed {
+		transfers[txid(c.ChannelPoint)] = true
+		if c.ClosingTxHash != "" {
+			transfers[c.ClosingTxHash] = true
+			closingFees[c.ClosingTxHash] = c.ClosingFee
+		}
+	}
+
+	entries := []*Entry{}
+	for _, tx := range txs {
+		if tx.NumConfirmations == 0 || tx.Amount == 0 {
+			continue
+		}
+		if transfers[tx.TxHash] || channelLabel(tx.Label) {
+			fee := tx.TotalFees
+			if f, ok := closingFees[tx.TxHash]; ok && fee == 0 {
+				fee = f
+			}
+			if fee > 0 {
+				entries = append(entries, &Entry{Time: tx.Date, Kind: Transfer, Fee: fee * 1000,
+					ID: tx.TxHash, Description: tx.Label})
+			}
+			continue
+		}
+		e := &Entry{Time: tx.Date, Kind: Deposit, Amount: tx.Amount * 1000, ID: tx.TxHash}
+		if tx.Amount < 0 {
+			// the amount of a spending transaction includes its fees.
+			e.Kind = Withdrawal
+			e.Amount = (-tx.Amount - tx.TotalFees) * 1000
+			e.Fee = tx.TotalFees * 1000
+		}
+		entries = append(entries, e)
+	}
+
+	own := map[string]bool{}
+	for _, inv := range invoices {
+		if inv.Settled {
+			own[hex.EncodeToString(inv.RHash)] = true
+		}
+	}
+	rebalances := map[string]bool{}
+	for _, p := range payments {
+		if p.Status != models.PaymentSucceeded {
+			continue
+		}
+		e := &Entry{Time: p.CreationDate, Kind: Sent, Amount: p.Amount * 1000, Fee: p.Fee * 1000, ID: p.Hash}
+		if p.PayReq != nil {
+			e.Description = p.PayReq.Description
+		}
+		if own[p.Hash] {
+			e.Kind = Rebalance
+			e.Amount = 0
+			rebalances[p.Hash] = true
+		}
+		entries = append(entries, e)
+	}
+
+	for _, inv := range invoices {
+		hash := hex.EncodeToString(inv.RHash)
+		if !inv.Settled || rebalances[hash] {
+			continue
+		}
+		entries = append(entries, &Entry{
+			Time:        time.Unix(inv.SettleDate, 0),
+			Kind:        Received,
+			Amount:      inv.AmountPaidInMSat,
+			ID:          hash,
+			Description: inv.Description,
+		})
+	}
+
+	for _, f := range forwards {
+		entries = append(entries, &Entry{
+			Time:   f.EventTime,
+			Kind:   Forward,
+			Amount: int64(f.FeeMsat),
+			ID:     fmt.Sprintf("%s>%s", models.ToScid(f.ChanIdIn), models.ToScid(f.ChanIdOut)),
+			Description: fmt.Sprintf("forward of %d sat from %s to %s",
+				f.AmtOut, f.PeerAliasIn, f.PeerAliasOut),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries
+}
+
+// txid returns the txid of the outpoint txid:index.
+func txid(outpoint string) string {
+	i := strings.IndexByte(outpoint, ':')
+	if i < 0 {
+		return outpoint
+	}
+	return outpoint[:i]
+}
+
+// channelLabel is true for the labels given by lnd to the fundings, the
+// closings, the sweeps and the justice transactions of the channels.
+func channelLabel(label string) bool {
+	for _, prefix := range []string{"0:openchannel", "0:closechannel", "0:sweep", "0:justicetx"} {
+		if strings.HasPrefix(label, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Accounting writes the entries as the CSV of a format, generic or the
+// one of an import of Koinly or CoinTracking.
+type Accounting struct {
+	Format string
+	// Location is the time zone of the dates.
+	Location *time.Location
+	// Currency is the fiat currency of the rates of the entries.
+	Currency string
+	// Wallet is the name of the wallet of the entries, the exchange
+	// column of CoinTracking.
+	Wallet string
+}
+
+func (a Accounting) Write(w io.Writer, entries []*Entry) error {
+	var header []string
+	var record func(e *Entry) []string
+	switch a.Format {
+	case Generic, "":
+		header, record = a.generic()
+	case Koinly:
+		header, record = a.koinly()
+	case CoinTracking:
+		header, record = a.cointracking()
+	default:
+		return errors.Errorf("unknown format %q, expected generic, koinly or cointracking", a.Format)
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, e := range entries {
+		err = cw.Write(record(e))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+func (a Accounting) generic() ([]string, func(*Entry) []string) {
+	header := []string{"time", "kind", "amount_msat", "fee_msat", "fiat_amount", "fiat_fee", "currency",
+		"id", "description"}
+	return header, func(e *Entry) []string {
+		return []string{
+			e.Time.In(a.Location).Format(time.RFC3339),
+			e.Kind,
+			strconv.FormatInt(e.Amount, 10),
+			strconv.FormatInt(e.Fee, 10),
+			fiat(e.Amount, e.Rate),
+			fiat(e.Fee, e.Rate),
+			a.currency(e),
+			e.ID,
+			e.Description,
+		}
+	}
+}
+
+// koinly writes the universal format of Koinly, the forwards are income
+// and the rebalances and the transfers a cost of their fee.
+func (a Accounting) koinly() ([]string, func(*Entry) []string) {
+	header := []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description",
+		"TxHash"}
+	return header, func(e *Entry) []string {
+		r := make([]string, len(header))
+		r[0] = e.Time.In(a.Location).Format("2006-01-02 15:04:05")
+		amount := e.Amount
+		switch e.Kind {
+		case Deposit, Received, Forward:
+			r[3], r[4] = btc(e.Amount), "BTC"
+		case Withdrawal, Sent:
+			r[1], r[2] = btc(e.Amount), "BTC"
+		case Rebalance, Transfer:
+			r[1], r[2] = btc(e.Fee), "BTC"
+			amount = e.Fee
+		}
+		if e.Fee > 0 && e.Kind != Rebalance && e.Kind != Transfer {
+			r[5], r[6] = btc(e.Fee), "BTC"
+		}
+		r[7], r[8] = fiat(amount, e.Rate), a.currency(e)
+		switch e.Kind {
+		case Forward:
+			r[9] = "income"
+		case Rebalance, Transfer:
+			r[9] = "cost"
+		}
+		r[10] = description(e)
+		r[11] = e.ID
+		return r
+	}
+}
+
+// cointracking writes the CSV import of CoinTracking, the forwards are
+// income and the rebalances and the transfers an other fee.
+func (a Accounting) cointracking() ([]string, func(*Entry) []string) {
+	header := []string{"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency", "Fee",
+		"Fee Currency", "Exchange", "Trade-Group", "Comment", "Date", "Tx-ID",
+		"Buy Value in Account Currency", "Sell Value in Account Currency"}
+	return header, func(e *Entry) []string {
+		r := make([]string, len(header))
+		switch e.Kind {
+		case Deposit, Received:
+			r[0], r[1], r[2] = "Deposit", btc(e.Amount), "BTC"
+			r[12] = fiat(e.Amount, e.Rate)
+		case Forward:
+			r[0], r[1], r[2] = "Income", btc(e.Amount), "BTC"
+			r[12] = fiat(e.Amount, e.Rate)
+		case Withdrawal, Sent:
+			r[0], r[3], r[4] = "Withdrawal", btc(e.Amount), "BTC"
+			r[13] = fiat(e.Amount, e.Rate)
+		case Rebalance, Transfer:
+			r[0], r[3], r[4] = "Other Fee", btc(e.Fee), "BTC"
+			r[13] = fiat(e.Fee, e.Rate)
+		}
+		if e.Fee > 0 && e.Kind != Rebalance && e.Kind != Transfer {
+			r[5], r[6] = btc(e.Fee), "BTC"
+		}
+		r[7] = a.Wallet
+		r[8] = "lightning"
+		if e.Kind == Deposit || e.Kind == Withdrawal || e.Kind == Transfer {
+			r[8] = "on-chain"
+		}
+		r[9] = description(e)
+		r[10] = e.Time.In(a.Location).Format("2006-01-02 15:04:05")
+		r[11] = e.ID
+		return r
+	}
+}
+
+func (a Accounting) currency(e *Entry) string {
+	if e.Rate == 0 {
+		return ""
+	}
+	return a.Currency
+}
+
+func description(e *Entry) string {
+	if e.Description == "" {
+		return e.Kind
+	}
+	return e.Kind + ": " + e.Description
+}
+
+// btc formats the msat as BTC, without the trailing zeros.
+func btc(msat int64) string {
+	return strconv.FormatFloat(float64(msat)/1e11, 'f', -1, 64)
+}
+
+// fiat returns the value of the msat at the rate, empty without rate.
+func fiat(msat int64, rate float64) string {
+	if rate == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(msat)/1e11*rate, 'f', 2, 64)
+}
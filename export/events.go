@@ -0,0 +1,233 @@
+package export
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/alerts"
+	"github.com/edouardparis/lntop/control"
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// Event is an event of the pubsub, its data is omitted if the event has
+// none.
+type Event struct {
+	Time time.Time   `json:"time"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+type Invoice struct {
+	Index          uint64 `json:"index"`
+	Hash           string `json:"hash"`
+	State          string `json:"state"`
+	Amount         int64  `json:"amount"`
+	AmountPaid     int64  `json:"amount_paid"`
+	Memo           string `json:"memo,omitempty"`
+	PaymentRequest string `json:"payment_request"`
+	CreationDate   int64  `json:"creation_date,omitempty"`
+	SettleDate     int64  `json:"settle_date,omitempty"`
+}
+
+type Payment struct {
+	Hash        string `json:"hash"`
+	Destination string `json:"destination,omitempty"`
+	Status      string `json:"status"`
+	Amount      int64  `json:"amount"`
+	Fee         int64  `json:"fee"`
+	Error       string `json:"error,omitempty"`
+}
+
+type ChannelUpdate struct {
+	ChannelPoint string `json:"channel_point"`
+	RemotePubKey string `json:"remote_pubkey,omitempty"`
+	CloseType    string `json:"close_type,omitempty"`
+}
+
+type Connection struct {
+	State    string `json:"state"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+type Alert struct {
+	Rule    string    `json:"rule"`
+	Key     string    `json:"key"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+type ChannelBackup struct {
+	ChannelPoints []string `json:"channel_points"`
+	Size          int      `json:"size"`
+}
+
+type InterceptedHTLC struct {
+	Key                string `json:"key"`
+	IncomingChannelId  uint64 `json:"incoming_channel_id"`
+	OutgoingChannelId  uint64 `json:"outgoing_channel_id"`
+	IncomingPeer       string `json:"incoming_peer,omitempty"`
+	OutgoingPeer       string `json:"outgoing_peer,omitempty"`
+	PaymentHash        string `json:"payment_hash"`
+	IncomingAmountMsat uint64 `json:"incoming_amount_msat"`
+	OutgoingAmountMsat uint64 `json:"outgoing_amount_msat"`
+	Action             string `json:"action"`
+	Reason             string `json:"reason,omitempty"`
+	Resolved           int64  `json:"resolved,omitempty"`
+}
+
+// NewEvent returns the event with its data, the event happened at t.
+func NewEvent(e *events.Event, t time.Time) Event {
+	out := Event{Time: t, Type: e.Type}
+	switch data := e.Data.(type) {
+	case *models.RoutingEvent:
+		out.Data = NewRoutingEvent(data)
+	case *models.Invoice:
+		out.Data = Invoice{
+			Index:          data.Index,
+			Hash:           data.GetRHash(),
+			State:          data.StateName(),
+			Amount:         data.Amount,
+			AmountPaid:     data.AmountPaid,
+			Memo:           data.Description,
+			PaymentRequest: data.PaymentRequest,
+			CreationDate:   data.CreationDate,
+			SettleDate:     data.SettleDate,
+		}
+	case *models.Payment:
+		out.Data = Payment{
+			Hash:        data.Hash,
+			Destination: data.Destination(),
+			Status:      data.StatusName(),
+			Amount:      data.Amount,
+			Fee:         data.Fee,
+			Error:       data.PaymentError,
+		}
+	case *models.ChannelUpdate:
+		u := ChannelUpdate{ChannelPoint: data.ChannelPoint, RemotePubKey: data.RemotePubKey}
+		if data.CloseType != 0 {
+			u.CloseType = models.CloseTypeName(data.CloseType)
+		}
+		out.Data = u
+	case *models.ChannelEdgeUpdate:
+		out.Data = map[string][]string{"channel_points": data.ChanPoints}
+	case *models.ChannelBackup:
+		out.Data = ChannelBackup{ChannelPoints: data.ChanPoints, Size: len(data.Multi)}
+	case *alerts.Alert:
+		out.Data = Alert{
+			Rule:    data.Rule,
+			Key:     data.Key,
+			Level:   data.Level.String(),
+			Message: data.Message,
+			Since:   data.Since,
+		}
+	case models.NodeState:
+		out.Data = map[string]string{"state": data.String()}
+	case models.Connection:
+		out.Data = Connection{State: data.State.String(), Attempts: data.Attempts}
+	case *models.InterceptedHTLC:
+		htlc := InterceptedHTLC{
+			Key:                data.Key(),
+			IncomingChannelId:  data.IncomingChannelId,
+			OutgoingChannelId:  data.OutgoingChannelId,
+			IncomingPeer:       data.IncomingPeer,
+			OutgoingPeer:       data.OutgoingPeer,
+			PaymentHash:        hex.EncodeToString(data.PaymentHash),
+			IncomingAmountMsat: data.IncomingAmountMsat,
+			OutgoingAmountMsat: data.OutgoingAmountMsat,
+			Action:             data.ActionName(),
+			Reason:             data.Reason,
+		}
+		if !data.Resolved.IsZero() {
+			htlc.Resolved = data.Resolved.Unix()
+		}
+		out.Data = htlc
+	}
+	return out
+}
+
+// Stream writes the events as JSON lines to a writer, or to every client
+// connected to its unix socket.
+type Stream struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	path     string
+	listener net.Listener
+	clients  map[net.Conn]*json.Encoder
+}
+
+func NewStream(w io.Writer) *Stream {
+	return &Stream{enc: json.NewEncoder(w)}
+}
+
+// ListenStream listens on the unix socket of the path, the clients
+// receive the events written after they connected.
+func ListenStream(path string) (*Stream, error) {
+	l, err := control.Listen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stream{path: path, listener: l, clients: make(map[net.Conn]*json.Encoder)}
+	go s.accept()
+	return s, nil
+}
+
+func (s *Stream) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.clients == nil {
+			conn.Close()
+		} else {
+			s.clients[conn] = json.NewEncoder(conn)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// WriteEvent writes the event as a single line, the clients failing to
+// read it within a second are disconnected.
+func (s *Stream) WriteEvent(e *events.Event) error {
+	v := NewEvent(e, time.Now())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return errors.WithStack(s.enc.Encode(v))
+	}
+	for conn, enc := range s.clients {
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		err := enc.Encode(v)
+		if err != nil {
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+	return nil
+}
+
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.clients = nil
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return errors.WithStack(err)
+}
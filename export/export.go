@@ -0,0 +1,58 @@
+// Package export serializes the node data for scripts and external tools.
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type Channel struct {
+	ID            uint64 `json:"id"`
+	SCID          string `json:"scid,omitempty"`
+	Status        string `json:"status"`
+	Alias         string `json:"alias"`
+	RemotePubKey  string `json:"remote_pubkey"`
+	ChannelPoint  string `json:"channel_point"`
+	Capacity      int64  `json:"capacity"`
+	LocalBalance  int64  `json:"local_balance"`
+	RemoteBalance int64  `json:"remote_balance"`
+	PendingHTLC   int    `json:"pending_htlc"`
+	Private       bool   `json:"private"`
+}
+
+func NewChannel(ch *models.Channel) Channel {
+	alias, _ := ch.ShortAlias()
+	c := Channel{
+		ID:            ch.ID,
+		Status:        ch.StatusName(),
+		Alias:         alias,
+		RemotePubKey:  ch.RemotePubKey,
+		ChannelPoint:  ch.ChannelPoint,
+		Capacity:      ch.Capacity,
+		LocalBalance:  ch.LocalBalance,
+		RemoteBalance: ch.RemoteBalance,
+		PendingHTLC:   len(ch.PendingHTLC),
+		Private:       ch.Private,
+	}
+	if ch.ID != 0 {
+		c.SCID = models.ToScid(ch.ID)
+	}
+	return c
+}
+
+// Channels writes the channels as an indented JSON array.
+func Channels(w io.Writer, channels []*models.Channel) error {
+	out := make([]Channel, len(channels))
+	for i := range channels {
+		out[i] = NewChannel(channels[i])
+	}
+	return JSON(w, out)
+}
+
+func JSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// MissionControl is the state of mission control in the JSON of lncli
+// querymc, the integers are strings.
+type MissionControl struct {
+	Pairs []MissionPair `json:"pairs"`
+}
+
+type MissionPair struct {
+	NodeFrom string      `json:"node_from"`
+	NodeTo   string      `json:"node_to"`
+	History  PairHistory `json:"history"`
+}
+
+type PairHistory struct {
+	FailTime       mcInt `json:"fail_time"`
+	FailAmtSat     mcInt `json:"fail_amt_sat"`
+	FailAmtMsat    mcInt `json:"fail_amt_msat"`
+	SuccessTime    mcInt `json:"success_time"`
+	SuccessAmtSat  mcInt `json:"success_amt_sat"`
+	SuccessAmtMsat mcInt `json:"success_amt_msat"`
+}
+
+// mcInt is an integer written as a string, read from a string or a
+// number.
+type mcInt int64
+
+func (i mcInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+func (i *mcInt) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) != nil {
+		s = string(data)
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.Errorf("not an integer: %s", data)
+	}
+	*i = mcInt(n)
+	return nil
+}
+
+// WriteMissionControl writes the pairs as the output of lncli querymc.
+func WriteMissionControl(w io.Writer, pairs []*models.MissionPair) error {
+	mc := MissionControl{Pairs: make([]MissionPair, len(pairs))}
+	for i, p := range pairs {
+		h := PairHistory{
+			FailAmtSat:     mcInt(p.FailAmtMsat / 1000),
+			FailAmtMsat:    mcInt(p.FailAmtMsat),
+			SuccessAmtSat:  mcInt(p.SuccessAmtMsat / 1000),
+			SuccessAmtMsat: mcInt(p.SuccessAmtMsat),
+		}
+		if !p.FailTime.IsZero() {
+			h.FailTime = mcInt(p.FailTime.Unix())
+		}
+		if !p.SuccessTime.IsZero() {
+			h.SuccessTime = mcInt(p.SuccessTime.Unix())
+		}
+		mc.Pairs[i] = MissionPair{NodeFrom: p.NodeFrom, NodeTo: p.NodeTo, History: h}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return errors.WithStack(enc.Encode(mc))
+}
+
+// ReadMissionControl reads the pairs written by WriteMissionControl or by
+// lncli querymc, the amounts in sat are used if there are none in msat.
+func ReadMissionControl(r io.Reader) ([]*models.MissionPair, error) {
+	var mc MissionControl
+	err := json.NewDecoder(r).Decode(&mc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pairs := make([]*models.MissionPair, len(mc.Pairs))
+	for i, p := range mc.Pairs {
+		if p.NodeFrom == "" || p.NodeTo == "" {
+			return nil, errors.Errorf("pair %d: missing node", i)
+		}
+		h := p.History
+		pair := &models.MissionPair{
+			NodeFrom:       p.NodeFrom,
+			NodeTo:         p.NodeTo,
+			FailAmtMsat:    int64(h.FailAmtMsat),
+			SuccessAmtMsat: int64(h.SuccessAmtMsat),
+		}
+		if pair.FailAmtMsat == 0 {
+			pair.FailAmtMsat = int64(h.FailAmtSat) * 1000
+		}
+		if pair.SuccessAmtMsat == 0 {
+			pair.SuccessAmtMsat = int64(h.SuccessAmtSat) * 1000
+		}
+		if h.FailTime != 0 {
+			pair.FailTime = time.Unix(int64(h.FailTime), 0)
+		}
+		if h.SuccessTime != 0 {
+			pair.SuccessTime = time.Unix(int64(h.SuccessTime), 0)
+		}
+		pairs[i] = pair
+	}
+	return pairs, nil
+}
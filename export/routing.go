@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type RoutingEvent struct {
+	Time              time.Time `json:"time"`
+	Direction         string    `json:"direction"`
+	Status            string    `json:"status"`
+	IncomingChannelID uint64    `json:"incoming_channel_id,omitempty"`
+	OutgoingChannelID uint64    `json:"outgoing_channel_id,omitempty"`
+	IncomingHtlcID    uint64    `json:"incoming_htlc_id"`
+	OutgoingHtlcID    uint64    `json:"outgoing_htlc_id"`
+	IncomingTimelock  uint32    `json:"incoming_timelock,omitempty"`
+	OutgoingTimelock  uint32    `json:"outgoing_timelock,omitempty"`
+	AmountMsat        uint64    `json:"amount_msat"`
+	FeeMsat           uint64    `json:"fee_msat"`
+	FailureCode       int32     `json:"failure_code,omitempty"`
+	FailureDetail     string    `json:"failure_detail,omitempty"`
+}
+
+func NewRoutingEvent(e *models.RoutingEvent) RoutingEvent {
+	r := RoutingEvent{
+		Time:              e.LastUpdate,
+		Direction:         "unknown",
+		Status:            "unknown",
+		IncomingChannelID: e.IncomingChannelId,
+		OutgoingChannelID: e.OutgoingChannelId,
+		IncomingHtlcID:    e.IncomingHtlcId,
+		OutgoingHtlcID:    e.OutgoingHtlcId,
+		IncomingTimelock:  e.IncomingTimelock,
+		OutgoingTimelock:  e.OutgoingTimelock,
+		AmountMsat:        e.AmountMsat,
+		FeeMsat:           e.FeeMsat,
+		FailureCode:       e.FailureCode,
+		FailureDetail:     e.FailureDetail,
+	}
+	switch e.Direction {
+	case models.RoutingSend:
+		r.Direction = "send"
+	case models.RoutingReceive:
+		r.Direction = "receive"
+	case models.RoutingForward:
+		r.Direction = "forward"
+	}
+	switch e.Status {
+	case models.RoutingStatusActive:
+		r.Status = "active"
+	case models.RoutingStatusSettled:
+		r.Status = "settled"
+	case models.RoutingStatusFailed:
+		r.Status = "failed"
+	case models.RoutingStatusLinkFailed:
+		r.Status = "linkfail"
+	}
+	return r
+}
+
+// Feed appends values as JSON lines to a file, which can be followed
+// like a log by other programs.
+type Feed struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// OpenFeed opens the file of the feed, created if it does not exist.
+func OpenFeed(path string) (*Feed, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Feed{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Write appends the value as a single line.
+func (f *Feed) Write(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return errors.WithStack(f.enc.Encode(v))
+}
+
+// WriteRoutingEvent appends the routing event.
+func (f *Feed) WriteRoutingEvent(e *models.RoutingEvent) error {
+	return f.Write(NewRoutingEvent(e))
+}
+
+func (f *Feed) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return errors.WithStack(f.file.Close())
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Table writes the rows of a view with the names of its columns, as CSV
+// with a header line or as a JSON array of objects keyed by column. The
+// thousands separators of the numbers are removed, the numbers are JSON
+// numbers.
+func Table(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case "csv":
+		return tableCSV(w, header, rows)
+	case "json":
+		return tableJSON(w, header, rows)
+	}
+	return errors.Errorf("unknown format %q, expected csv or json", format)
+}
+
+func tableCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i := range row {
+			record[i] = row[i]
+			if n, ok := number(row[i]); ok {
+				record[i] = n
+			}
+		}
+		err = cw.Write(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+// tableJSON writes the objects with their keys in the order of the
+// columns, a map would sort them.
+func tableJSON(w io.Writer, header []string, rows [][]string) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(header[j])
+			buf.Write(key)
+			buf.WriteString(": ")
+			if n, ok := number(row[j]); ok {
+				buf.WriteString(n)
+				continue
+			}
+			value, _ := json.Marshal(row[j])
+			buf.Write(value)
+		}
+		buf.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	_, err := w.Write(buf.Bytes())
+	return errors.WithStack(err)
+}
+
+// number returns the cell without its thousands separators if it is a
+// number in the JSON syntax.
+func number(cell string) (string, bool) {
+	n := strings.ReplaceAll(cell, ",", "")
+	_, err := strconv.ParseFloat(n, 64)
+	if err != nil || !json.Valid([]byte(n)) {
+		return "", false
+	}
+	return n, true
+}
@@ -0,0 +1,673 @@
+// Package expr evaluates the small expressions of the config, e.g. the
+// computed columns:
+//
+//	local_balance / capacity * 100
+//	my_ppm - peer_ppm
+//	active && local_balance < 0.2 * capacity ? "low" : "ok"
+//
+// Values are numbers (float64), strings and booleans. The operators are,
+// by increasing precedence, ?:, ||, &&, == != < <= > >=, + -, * / %, and
+// the unary ! and -. + also concatenates strings. A division or a modulo by
+// zero is ErrDivisionByZero. The functions are abs, min, max, round, floor,
+// ceil, contains, has, lower and upper, others can be given to ParseFuncs.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDivisionByZero is the error of a division or a modulo by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Env holds the values of the identifiers, float64, string or bool.
+type Env map[string]interface{}
+
+type Expr struct {
+	src  string
+	root node
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the values of env, an unknown
+// identifier is an error.
+func (e *Expr) Eval(env Env) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// Float evaluates the expression and converts the result to a number.
+func (e *Expr) Float(env Env) (float64, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	return toFloat(v)
+}
+
+// Bool evaluates the expression and converts the result to a boolean.
+func (e *Expr) Bool(env Env) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return toBool(v)
+}
+
+// Idents returns the identifiers of the expression once each, in their
+// order, to check them before it is evaluated.
+func (e *Expr) Idents() []string {
+	var idents []string
+	seen := map[string]bool{}
+	var walk func(node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case ident:
+			if !seen[string(n)] {
+				seen[string(n)] = true
+				idents = append(idents, string(n))
+			}
+		case *unaryOp:
+			walk(n.n)
+		case *ternary:
+			walk(n.cond)
+			walk(n.then)
+			walk(n.otherwise)
+		case *binary:
+			walk(n.left)
+			walk(n.right)
+		case *call:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return idents
+}
+
+// Func is a function of the expressions, called with the values of its
+// arguments.
+type Func func(args []interface{}) (interface{}, error)
+
+// Parse parses the expression.
+func Parse(src string) (*Expr, error) {
+	return ParseFuncs(src, nil)
+}
+
+// ParseFuncs parses the expression with the functions in addition to the
+// builtin ones.
+func ParseFuncs(src string, funcs map[string]Func) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, funcs: funcs}
+	root, err := p.parse(0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "expr %q", src)
+	}
+	if p.peek().kind != tokEOF {
+		return nil, errors.Errorf("expr %q: unexpected %q", src, p.peek().text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustParse is like Parse but panics on error.
+func MustParse(src string) *Expr {
+	e, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' || src[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:j], i})
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(src) && (isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, src[i:j], i})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, errors.Errorf("expr %q: unterminated string at %d", src, i)
+			}
+			tokens = append(tokens, token{tokString, b.String(), i})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "<=", ">="} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("+-*/%<>!?:(),", rune(c)) {
+				op = string(c)
+			}
+			if op == "" {
+				return nil, errors.Errorf("expr %q: unexpected %q at %d", src, c, i)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(src)}), nil
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+var precedence = map[string]int{
+	"?":  1,
+	"||": 2,
+	"&&": 3,
+	"==": 4, "!=": 4, "<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	funcs  map[string]Func
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokOp || t.text != op {
+		return errors.Errorf("expected %q at %d", op, t.pos)
+	}
+	return nil
+}
+
+// parse parses the binary operations of precedence greater than min.
+func (p *parser) parse(min int) (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokOp || !ok || prec <= min {
+			return left, nil
+		}
+		p.next()
+
+		if t.text == "?" {
+			then, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			err = p.expect(":")
+			if err != nil {
+				return nil, err
+			}
+			otherwise, err := p.parse(prec - 1)
+			if err != nil {
+				return nil, err
+			}
+			left = &ternary{left, then, otherwise}
+			continue
+		}
+
+		right, err := p.parse(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{t.text, left, right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(t.text, "_", ""), 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return literal{f}, nil
+	case tokString:
+		return literal{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		}
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			return p.call(t)
+		}
+		return ident(t.text), nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "-", "!":
+			n, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryOp{t.text, n}, nil
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, errors.New("unexpected end")
+	}
+	return nil, errors.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if f, custom := p.funcs[name.text]; custom {
+		fn, ok = f, true
+	}
+	if !ok {
+		return nil, errors.Errorf("unknown function %q at %d", name.text, name.pos)
+	}
+	p.next()
+
+	c := &call{name: name.text, fn: fn}
+	if p.peek().kind == tokOp && p.peek().text == ")" {
+		p.next()
+		return c, nil
+	}
+	for {
+		arg, err := p.parse(0)
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		t := p.next()
+		if t.kind == tokOp && t.text == ")" {
+			return c, nil
+		}
+		if t.kind != tokOp || t.text != "," {
+			return nil, errors.Errorf("expected \",\" or \")\" at %d", t.pos)
+		}
+	}
+}
+
+type node interface {
+	eval(Env) (interface{}, error)
+}
+
+type literal struct{ v interface{} }
+
+func (l literal) eval(Env) (interface{}, error) { return l.v, nil }
+
+type ident string
+
+func (i ident) eval(env Env) (interface{}, error) {
+	v, ok := env[string(i)]
+	if !ok {
+		return nil, errors.Errorf("unknown identifier %q", string(i))
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	}
+	return v, nil
+}
+
+type unaryOp struct {
+	op string
+	n  node
+}
+
+func (u *unaryOp) eval(env Env) (interface{}, error) {
+	v, err := u.n.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if u.op == "!" {
+		b, err := toBool(v)
+		return !b, err
+	}
+	f, err := toFloat(v)
+	return -f, err
+}
+
+type ternary struct {
+	cond, then, otherwise node
+}
+
+func (t *ternary) eval(env Env) (interface{}, error) {
+	v, err := t.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBool(v)
+	if err != nil {
+		return nil, err
+	}
+	if b {
+		return t.then.eval(env)
+	}
+	return t.otherwise.eval(env)
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (b *binary) eval(env Env) (interface{}, error) {
+	l, err := b.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || do not evaluate the right side if not needed.
+	if b.op == "&&" || b.op == "||" {
+		lb, err := toBool(l)
+		if err != nil {
+			return nil, err
+		}
+		if (b.op == "&&") != lb {
+			return lb, nil
+		}
+		r, err := b.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return toBool(r)
+	}
+
+	r, err := b.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	ls, lstr := l.(string)
+	rs, rstr := r.(string)
+	if lstr || rstr {
+		switch b.op {
+		case "+":
+			return toString(l) + toString(r), nil
+		case "==":
+			return lstr && rstr && ls == rs, nil
+		case "!=":
+			return !(lstr && rstr && ls == rs), nil
+		case "<":
+			return toString(l) < toString(r), nil
+		case "<=":
+			return toString(l) <= toString(r), nil
+		case ">":
+			return toString(l) > toString(r), nil
+		case ">=":
+			return toString(l) >= toString(r), nil
+		}
+		return nil, errors.Errorf("invalid operation %q on strings", b.op)
+	}
+
+	if lb, ok := l.(bool); ok {
+		if rb, ok := r.(bool); ok {
+			switch b.op {
+			case "==":
+				return lb == rb, nil
+			case "!=":
+				return lb != rb, nil
+			}
+		}
+	}
+
+	lf, err := toFloat(l)
+	if err != nil {
+		return nil, err
+	}
+	rf, err := toFloat(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return math.Mod(lf, rf), nil
+	case "==":
+		return lf == rf, nil
+	case "!=":
+		return lf != rf, nil
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	}
+	return nil, errors.Errorf("unknown operator %q", b.op)
+}
+
+type call struct {
+	name string
+	fn   func([]interface{}) (interface{}, error)
+	args []node
+}
+
+func (c *call) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, len(c.args))
+	for i := range c.args {
+		v, err := c.args[i].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := c.fn(args)
+	return v, errors.Wrap(err, c.name)
+}
+
+func floatFunc(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("expects one argument")
+		}
+		x, err := toFloat(args[0])
+		return f(x), err
+	}
+}
+
+func reduceFunc(f func(float64, float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errors.New("expects arguments")
+		}
+		result, err := toFloat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range args[1:] {
+			x, err := toFloat(a)
+			if err != nil {
+				return nil, err
+			}
+			result = f(result, x)
+		}
+		return result, nil
+	}
+}
+
+func stringFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("expects one argument")
+		}
+		return f(toString(args[0])), nil
+	}
+}
+
+var functions map[string]func([]interface{}) (interface{}, error)
+
+func init() {
+	functions = map[string]func([]interface{}) (interface{}, error){
+		"abs":   floatFunc(math.Abs),
+		"round": floatFunc(math.Round),
+		"floor": floatFunc(math.Floor),
+		"ceil":  floatFunc(math.Ceil),
+		"min":   reduceFunc(math.Min),
+		"max":   reduceFunc(math.Max),
+		"lower": stringFunc(strings.ToLower),
+		"upper": stringFunc(strings.ToUpper),
+		"contains": func(args []interface{}) (interface{}, error) {
+			if len(args) != 2 {
+				return nil, errors.New("expects two arguments")
+			}
+			return strings.Contains(
+				strings.ToLower(toString(args[0])),
+				strings.ToLower(toString(args[1]))), nil
+		},
+		// has is true if the second argument is one of the items of the
+		// comma separated list of the first one, e.g. has(tags, "sink").
+		"has": func(args []interface{}) (interface{}, error) {
+			if len(args) != 2 {
+				return nil, errors.New("expects two arguments")
+			}
+			item := strings.TrimSpace(toString(args[1]))
+			for _, s := range strings.Split(toString(args[0]), ",") {
+				if strings.EqualFold(strings.TrimSpace(s), item) {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return 0, errors.Errorf("%q is not a number", x)
+		}
+		return f, nil
+	}
+	return 0, errors.Errorf("%v is not a number", v)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case float64:
+		return x != 0, nil
+	case string:
+		return x != "", nil
+	}
+	return false, errors.Errorf("%v is not a boolean", v)
+}
+
+func toString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Format formats the value with the fmt verb of format, numbers are
+// printed with %.0f if format is empty.
+func Format(v interface{}, format string) string {
+	if format == "" {
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'f', 0, 64)
+		}
+		return toString(v)
+	}
+	return fmt.Sprintf(format, v)
+}
@@ -0,0 +1,147 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{src: "local_balance / capacity * 100"},
+		{src: `active && local_balance < 0.2 * capacity ? "low" : "ok"`},
+		{src: "max(1, min(2, 3), abs(-4))"},
+		{src: `"unterminated`, err: "unterminated string"},
+		{src: "1 +", err: "unexpected end"},
+		{src: "1 2", err: `unexpected "2"`},
+		{src: "(1 + 2", err: `expected ")"`},
+		{src: "1 # 2", err: "unexpected '#'"},
+		{src: "unknown(1)", err: `unknown function "unknown"`},
+		{src: "min(1 2)", err: `expected "," or ")"`},
+		{src: "a ? b", err: `expected ":"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	env := Env{
+		"local_balance": int64(250),
+		"capacity":      int64(1000),
+		"zero":          0,
+		"alias":         "ACINQ",
+		"tags":          "sink, exchange",
+		"active":        true,
+	}
+	tests := []struct {
+		src  string
+		want interface{}
+		err  error
+	}{
+		{src: "1 + 2 * 3", want: 7.0},
+		{src: "(1 + 2) * 3", want: 9.0},
+		{src: "7 % 4 - -1", want: 4.0},
+		{src: "local_balance / capacity * 100", want: 25.0},
+		{src: `"a" + 1`, want: "a1"},
+		{src: `alias == "ACINQ"`, want: true},
+		{src: `alias != 1`, want: true},
+		{src: `"a" < "b"`, want: true},
+		{src: "!active || capacity >= 1000", want: true},
+		{src: "active == true", want: true},
+		{src: `local_balance < 0.2 * capacity ? "low" : "ok"`, want: "ok"},
+		{src: `false ? 1 : true ? 2 : 3`, want: 2.0},
+		{src: "false && unknown", want: false},
+		{src: "true || unknown", want: true},
+		{src: "round(2.5) + floor(1.7) + ceil(0.2)", want: 5.0},
+		{src: "max(1, 3, 2) - min(4, -1)", want: 4.0},
+		{src: `upper(lower("AbC"))`, want: "ABC"},
+		{src: `contains(alias, "cin")`, want: true},
+		{src: `has(tags, "Exchange")`, want: true},
+		{src: `has(tags, "source")`, want: false},
+		{src: "capacity / zero", err: ErrDivisionByZero},
+		{src: "capacity % zero", err: ErrDivisionByZero},
+		{src: "round(1 / zero)", err: ErrDivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := MustParse(tt.src).Eval(env)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got error %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{src: "unknown + 1", err: `unknown identifier "unknown"`},
+		{src: `"a" - "b"`, err: `invalid operation "-" on strings`},
+		{src: `-"a"`, err: `"a" is not a number`},
+		{src: "abs(1, 2)", err: "abs: expects one argument"},
+		{src: `has("a")`, err: "has: expects two arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := MustParse(tt.src).Eval(nil)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIdents(t *testing.T) {
+	e := MustParse("a + max(b, a) > 0 ? -c : !d")
+	want := []string{"a", "b", "c", "d"}
+	if got := e.Idents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		v      interface{}
+		format string
+		want   string
+	}{
+		{v: 12.6, want: "13"},
+		{v: "ok", want: "ok"},
+		{v: true, want: "true"},
+		{v: 12.345, format: "%.1f", want: "12.3"},
+		{v: 0.5, format: "%.0f%%", want: "0%"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.v, tt.format); got != tt.want {
+			t.Errorf("Format(%v, %q) = %q, want %q", tt.v, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package fee estimates the on-chain fee rates in sat/vbyte, with the node
+// or a mempool.space compatible api, for the header, the dashboard and the
+// fee rates of the dialogs.
+package fee
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	Node    = "node"
+	Mempool = "mempool"
+
+	defaultURL      = "https://mempool.space"
+	defaultInterval = time.Minute
+	maxResponse     = 1 << 20
+)
+
+// Rates are the fee rates in sat/vbyte to confirm in the next blocks, in
+// half an hour, in an hour and in a day.
+type Rates struct {
+	Fastest  uint64
+	HalfHour uint64
+	Hour     uint64
+	Economy  uint64
+	// Source is node or the host of the api of the estimate.
+	Source string
+}
+
+// Estimate returns the fee rate in sat/vbyte estimated by the node to
+// confirm within the number of blocks.
+type Estimate func(ctx context.Context, target uint32) (uint64, error)
+
+// Provider returns the fee rates of its source.
+type Provider interface {
+	Rates(ctx context.Context) (*Rates, error)
+	// Interval is the duration between two estimates.
+	Interval() time.Duration
+}
+
+// NewNode returns the provider of the estimates of the node.
+func NewNode(estimate Estimate) Provider {
+	return &node{estimate: estimate, interval: defaultInterval}
+}
+
+// New returns the provider of the config, the node if the source is empty.
+// The requests to the api go through the proxy of the http config.
+func New(cfg config.Fees, httpCfg config.HTTP, estimate Estimate) (Provider, error) {
+	interval := defaultInterval
+	if cfg.Interval > 0 {
+		interval = time.Duration(cfg.Interval) * time.Second
+	}
+	n := &node{estimate: estimate, interval: interval}
+
+	switch cfg.Source {
+	case "", Node:
+		return n, nil
+	case Mempool:
+		u := strings.TrimRight(cfg.URL, "/")
+		if u == "" {
+			u = defaultURL
+		}
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" {
+			return nil, errors.Errorf("invalid url %q of the fee api", cfg.URL)
+		}
+		client, err := httpCfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		return &mempool{http: client, url: u, host: parsed.Host, node: n}, nil
+	}
+	return nil, errors.Errorf("unknown fee source %q", cfg.Source)
+}
+
+// node estimates the tiers with the targets of the next two blocks, three
+// blocks, six blocks and a day.
+type node struct {
+	estimate Estimate
+	interval time.Duration
+}
+
+func (n *node) Rates(ctx context.Context) (*Rates, error) {
+	targets := []uint32{2, 3, 6, 144}
+	rates := make([]uint64, len(targets))
+	for i := range targets {
+		rate, err := n.estimate(ctx, targets[i])
+		if err != nil {
+			return nil, err
+		}
+		rates[i] = rate
+	}
+	return &Rates{
+		Fastest:  rates[0],
+		HalfHour: rates[1],
+		Hour:     rates[2],
+		Economy:  rates[3],
+		Source:   Node,
+	}, nil
+}
+
+func (n *node) Interval() time.Duration { return n.interval }
+
+// mempool reads the recommended fees of the api, the estimates of the node
+// are used if it fails.
+type mempool struct {
+	http *http.Client
+	url  string
+	host string
+	node *node
+}
+
+func (m *mempool) Rates(ctx context.Context) (*Rates, error) {
+	rates, err := m.recommended(ctx)
+	if err == nil {
+		return rates, nil
+	}
+	rates, nerr := m.node.Rates(ctx)
+	if nerr != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (m *mempool) Interval() time.Duration { return m.node.interval }
+
+func (m *mempool) recommended(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url+"/api/v1/fees/recommended", nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, errors.Errorf("%s: %s", m.host, resp.Status)
+	}
+	var fees struct {
+		FastestFee  float64 `json:"fastestFee"`
+		HalfHourFee float64 `json:"halfHourFee"`
+		HourFee     float64 `json:"hourFee"`
+		EconomyFee  float64 `json:"economyFee"`
+	}
+	err = json.Unmarshal(body, &fees)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if fees.FastestFee <= 0 {
+		return nil, errors.Errorf("%s: no recommended fees", m.host)
+	}
+	return &Rates{
+		Fastest:  satPerVbyte(fees.FastestFee),
+		HalfHour: satPerVbyte(fees.HalfHourFee),
+		Hour:     satPerVbyte(fees.HourFee),
+		Economy:  satPerVbyte(fees.EconomyFee),
+		Source:   m.host,
+	}, nil
+}
+
+// satPerVbyte rounds up the rate, the api may return fractions of sat/vbyte.
+func satPerVbyte(rate float64) uint64 {
+	n := uint64(rate)
+	if float64(n) < rate {
+		n++
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,595 @@
+// Package firewall intercepts the HTLCs forwarded through the node with
+// the rules of the [firewall] config. An HTLC breaking a rule is failed,
+// the other ones are resumed or held until they are resolved from the ui
+// or their hold times out. Each HTLC is sent as an events.HTLCIntercepted
+// event when it is intercepted and when it is resolved.
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+const (
+	defaultHoldTimeout = 60 * time.Second
+	// retryDelay is the delay before the interceptor is started again
+	// after it stopped.
+	retryDelay = 10 * time.Second
+	// historySize is the number of resolved HTLCs kept.
+	historySize = 100
+	// resolutionsBuffer is the number of resolutions waiting for the
+	// interceptor.
+	resolutionsBuffer = 64
+	// reconcileInterval is the interval between two checks of the HTLCs
+	// in flight with the pending HTLCs of the channels.
+	reconcileInterval = time.Minute
+	// unknownPeer is the reason of the HTLCs held until the peers of
+	// their channels are known.
+	unknownPeer = "peer not known yet"
+)
+
+// Firewall holds the HTLCs forwarded through the node and resolves them
+// with its rules.
+type Firewall struct {
+	cfg         config.Firewall
+	holdTimeout time.Duration
+	logger      logging.Logger
+	network     *network.Network
+	resolutions chan *models.HTLCResolution
+
+	mu sync.Mutex
+	// htlcs are the HTLCs held and the last ones resolved, in the order
+	// of their interception.
+	htlcs []*models.InterceptedHTLC
+	// inflight are the HTLCs resumed until their forward is settled or
+	// failed, by circuit key.
+	inflight map[string]*flight
+	// running is true while the interceptor reads the resolutions.
+	running bool
+	// pubkeys are the pubkeys of the peers by channel id, aliases their
+	// aliases by pubkey, filled in the background. lookup asks for them
+	// again once an HTLC of an unknown channel or peer is intercepted.
+	pubkeys map[uint64]string
+	aliases map[string]string
+	lookup  chan struct{}
+}
+
+// flight is an HTLC forwarded by the node, its amount is exposed to the
+// peers of its channels. It is dropped if its payment hash is not pending
+// in the channels anymore.
+type flight struct {
+	amount  int64
+	peers   [2]string
+	hash    string
+	resumed time.Time
+}
+
+func newFlight(htlc *models.InterceptedHTLC, in, out string) *flight {
+	return &flight{
+		amount:  int64(htlc.OutgoingAmountMsat / 1000),
+		peers:   [2]string{in, out},
+		hash:    string(htlc.PaymentHash),
+		resumed: time.Now(),
+	}
+}
+
+// New returns the firewall of the config, nil if it is not enabled.
+func New(cfg config.Firewall, logger logging.Logger, n *network.Network) (*Firewall, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	for _, p := range cfg.Peers {
+		if p.Peer == "" {
+			return nil, errors.New("firewall: the peer of a rule is missing")
+		}
+		// the alias of a node is chosen by the node, the rules only
+		// name pubkeys.
+		if b, err := hex.DecodeString(p.Peer); err != nil || len(b) != 33 {
+			return nil, errors.Errorf("firewall: peer %s is not a pubkey", p.Peer)
+		}
+		switch p.Action {
+		case "", "resume", "hold", "fail":
+		default:
+			return nil, errors.Errorf("firewall: unknown action %q of peer %s", p.Action, p.Peer)
+		}
+	}
+	timeout := defaultHoldTimeout
+	if cfg.HoldTimeout > 0 {
+		timeout = time.Duration(cfg.HoldTimeout) * time.Second
+	}
+	return &Firewall{
+		cfg:         cfg,
+		holdTimeout: timeout,
+		logger:      logger.With(logging.String("logger", "firewall")),
+		network:     n,
+		resolutions: make(chan *models.HTLCResolution, resolutionsBuffer),
+		inflight:    make(map[string]*flight),
+		pubkeys:     make(map[uint64]string),
+		aliases:     make(map[string]string),
+		lookup:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Run intercepts the HTLCs until the context is done, the interceptor is
+// started again if it stops, e.g. while the node restarts.
+func (f *Firewall) Run(ctx context.Context, sub chan *events.Event) {
+	htlcs := make(chan *models.InterceptedHTLC)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			// the forwards settled or failed while the stream was
+			// down were missed.
+			f.reconcile(ctx)
+			f.setRunning(true)
+			err := f.network.InterceptHTLCs(ctx, htlcs, f.resolutions)
+			f.setRunning(false)
+			if err != nil {
+				f.logger.Error("InterceptHTLCs returned an error", logging.Error(err))
+			}
+			f.release(ctx, sub)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(reconcileInterval)
+		defer ticker.Stop()
+		for {
+			f.reconcile(ctx)
+			f.findAliases(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-f.lookup:
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			<-stopped
+			return
+		case htlc := <-htlcs:
+			f.intercept(ctx, htlc, sub)
+		case now := <-ticker.C:
+			f.decideKnown(ctx, now, sub)
+			f.expire(ctx, now, sub)
+		}
+	}
+}
+
+func (f *Firewall) setRunning(running bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = running
+}
+
+// reconcile keeps the pubkeys of the peers of the channels and drops the
+// HTLCs in flight whose payment hash is not pending in the channels
+// anymore, their settle or fail was missed. The ones resumed after the
+// channels were listed are kept.
+func (f *Firewall) reconcile(ctx context.Context) {
+	listed := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	channels, err := f.network.ListChannels(ctx)
+	if err != nil {
+		f.logger.Debug("cannot list the pending htlcs", logging.Error(err))
+		return
+	}
+	pending := map[string]bool{}
+	for _, ch := range channels {
+		for _, htlc := range ch.PendingHTLC {
+			pending[string(htlc.Hashlock)] = true
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range channels {
+		f.pubkeys[ch.ID] = ch.RemotePubKey
+	}
+	for key, fl := range f.inflight {
+		if fl.resumed.Before(listed) && !pending[fl.hash] {
+			f.logger.Debug("htlc not in flight anymore", logging.String("key", key))
+			delete(f.inflight, key)
+		}
+	}
+}
+
+// intercept resolves the HTLC with the rules, or holds it. The peers are
+// the ones already known, the node is not asked for them while the HTLCs
+// wait, the HTLC is held until the ones not known yet are found.
+func (f *Firewall) intercept(ctx context.Context, htlc *models.InterceptedHTLC, sub chan *events.Event) {
+	f.mu.Lock()
+	in := f.pubkey(htlc.IncomingChannelId)
+	out := f.pubkey(htlc.OutgoingChannelId)
+	htlc.IncomingPeer = f.alias(in)
+	htlc.OutgoingPeer = f.alias(out)
+	action, reason := f.decide(htlc, in, out)
+	htlc.Action = models.InterceptHold
+	htlc.Reason = reason
+	if action != models.InterceptHold {
+		f.resolve(htlc, action, nil, reason, htlc.Intercepted)
+		if htlc.Action == models.InterceptResume {
+			f.inflight[htlc.Key()] = newFlight(htlc, in, out)
+		}
+	}
+	f.htlcs = append(f.htlcs, htlc)
+	f.trim()
+	e := *htlc
+	f.mu.Unlock()
+
+	f.logger.Debug("htlc intercepted", logging.String("key", htlc.Key()),
+		logging.String("action", htlc.ActionName()), logging.String("reason", reason))
+	send(ctx, sub, &e)
+}
+
+// decide returns the action of the rules for the HTLC between the peers
+// of the pubkeys and the rule deciding it.
+func (f *Firewall) decide(htlc *models.InterceptedHTLC, in, out string) (int, string) {
+	amount := int64(htlc.OutgoingAmountMsat / 1000)
+	if f.cfg.MinFeePPM > 0 && htlc.FeePPM() < f.cfg.MinFeePPM {
+		return models.InterceptFail, fmt.Sprintf("fee rate %d ppm under %d ppm", htlc.FeePPM(), f.cfg.MinFeePPM)
+	}
+	if f.cfg.MaxHTLC > 0 && amount > f.cfg.MaxHTLC {
+		return models.InterceptFail, fmt.Sprintf("amount above %d sat", f.cfg.MaxHTLC)
+	}
+	if reason := f.exposed(htlc, in, out); reason != "" {
+		return models.InterceptFail, reason
+	}
+	// the rules of the peers cannot be applied yet, the HTLC is decided
+	// again once the lookup found them.
+	if !f.known(htlc) {
+		return models.InterceptHold, unknownPeer
+	}
+
+	hold, resume := "", false
+	for _, p := range f.cfg.Peers {
+		pubkey := f.match(p.Peer, in, out)
+		if pubkey == "" {
+			continue
+		}
+		switch {
+		case p.Action == "fail":
+			return models.InterceptFail, fmt.Sprintf("peer %s blocked", p.Peer)
+		case p.MaxHTLC > 0 && amount > p.MaxHTLC:
+			return models.InterceptFail, fmt.Sprintf("amount above %d sat for %s", p.MaxHTLC, p.Peer)
+		case p.Action == "hold":
+			hold = p.Peer
+		case p.Action == "resume":
+			resume = true
+		}
+	}
+	switch {
+	case hold != "":
+		return models.InterceptHold, fmt.Sprintf("held for %s", hold)
+	case resume || !f.cfg.Hold:
+		return models.InterceptResume, ""
+	}
+	return models.InterceptHold, "held by default"
+}
+
+// known returns true if the peers of the channels of the HTLC are known.
+func (f *Firewall) known(htlc *models.InterceptedHTLC) bool {
+	for _, id := range []uint64{htlc.IncomingChannelId, htlc.OutgoingChannelId} {
+		if _, ok := f.pubkeys[id]; !ok && id != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// decideKnown decides again the HTLCs held until the peers of their
+// channels are known, once they are.
+func (f *Firewall) decideKnown(ctx context.Context, now time.Time, sub chan *events.Event) {
+	var decided []models.InterceptedHTLC
+	f.mu.Lock()
+	for _, htlc := range f.htlcs {
+		if !htlc.Resolved.IsZero() || htlc.Reason != unknownPeer || !f.known(htlc) {
+			continue
+		}
+		in, out := f.pubkeys[htlc.IncomingChannelId], f.pubkeys[htlc.OutgoingChannelId]
+		htlc.IncomingPeer = f.alias(in)
+		htlc.OutgoingPeer = f.alias(out)
+		action, reason := f.decide(htlc, in, out)
+		htlc.Reason = reason
+		if action != models.InterceptHold {
+			f.resolve(htlc, action, nil, reason, now)
+			if htlc.Action == models.InterceptResume {
+				f.inflight[htlc.Key()] = newFlight(htlc, in, out)
+			}
+		}
+		decided = append(decided, *htlc)
+	}
+	f.trim()
+	f.mu.Unlock()
+	for i := range decided {
+		send(ctx, sub, &decided[i])
+	}
+}
+
+// match returns the pubkey of the peer of the HTLC named by the rule,
+// empty if it is neither.
+func (f *Firewall) match(peer, in, out string) string {
+	for _, pubkey := range []string{in, out} {
+		if pubkey != "" && peer == pubkey {
+			return pubkey
+		}
+	}
+	return ""
+}
+
+// exposed returns the limit of exposure broken by the HTLC between the
+// peers of the pubkeys once resumed, empty if none is.
+func (f *Firewall) exposed(htlc *models.InterceptedHTLC, in, out string) string {
+	amount := int64(htlc.OutgoingAmountMsat / 1000)
+	if f.cfg.MaxExposure > 0 && f.exposure("", htlc.Key())+amount > f.cfg.MaxExposure {
+		return fmt.Sprintf("exposure above %d sat", f.cfg.MaxExposure)
+	}
+	for _, p := range f.cfg.Peers {
+		pubkey := f.match(p.Peer, in, out)
+		if pubkey != "" && p.MaxExposure > 0 && f.exposure(pubkey, htlc.Key())+amount > p.MaxExposure {
+			return fmt.Sprintf("exposure above %d sat for %s", p.MaxExposure, p.Peer)
+		}
+	}
+	return ""
+}
+
+// exposure returns the amount in sat of the HTLCs in flight and of the
+// ones held through the peer of the pubkey, of all the HTLCs if it is
+// empty, but the HTLC of the circuit key.
+func (f *Firewall) exposure(pubkey, except string) int64 {
+	var total int64
+	for key, fl := range f.inflight {
+		if key != except && (pubkey == "" || fl.peers[0] == pubkey || fl.peers[1] == pubkey) {
+			total += fl.amount
+		}
+	}
+	for _, htlc := range f.htlcs {
+		if !htlc.Resolved.IsZero() || htlc.Key() == except {
+			continue
+		}
+		in, out := f.pubkeys[htlc.IncomingChannelId], f.pubkeys[htlc.OutgoingChannelId]
+		if pubkey == "" || in == pubkey || out == pubkey {
+			total += int64(htlc.OutgoingAmountMsat / 1000)
+		}
+	}
+	return total
+}
+
+// resolve sends the resolution of the HTLC to the interceptor.
+func (f *Firewall) resolve(htlc *models.InterceptedHTLC, action int, preimage []byte, reason string, now time.Time) {
+	select {
+	case f.resolutions <- &models.HTLCResolution{
+		IncomingChannelId: htlc.IncomingChannelId,
+		IncomingHtlcId:    htlc.IncomingHtlcId,
+		Action:            action,
+		Preimage:          preimage,
+	}:
+	default:
+		// the node resumes the HTLC once the interceptor stops.
+		cause := "interceptor not running"
+		if f.running {
+			cause = "resolutions of the interceptor full"
+		}
+		f.logger.Error(cause+", the htlc is resumed by the node",
+			logging.String("key", htlc.Key()))
+		action, reason = models.InterceptResume, cause
+	}
+	htlc.Action = action
+	htlc.Reason = reason
+	htlc.Resolved = now
+}
+
+// Resolve resumes, fails or settles with the preimage the held HTLC of
+// the circuit key, it is not resumed above the limits of exposure.
+func (f *Firewall) Resolve(key string, action int, preimage []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, htlc := range f.htlcs {
+		if htlc.Key() != key {
+			continue
+		}
+		if !htlc.Resolved.IsZero() {
+			return errors.Errorf("the htlc is already %s", htlc.ActionName())
+		}
+		if action == models.InterceptSettle {
+			hash := sha256.Sum256(preimage)
+			if !bytes.Equal(hash[:], htlc.PaymentHash) {
+				return errors.New("the preimage does not match the payment hash")
+			}
+		}
+		in, out := f.pubkeys[htlc.IncomingChannelId], f.pubkeys[htlc.OutgoingChannelId]
+		if action == models.InterceptResume {
+			if reason := f.exposed(htlc, in, out); reason != "" {
+				return errors.New(reason)
+			}
+		}
+		f.resolve(htlc, action, preimage, "resolved in the ui", time.Now())
+		if htlc.Action == models.InterceptResume {
+			f.inflight[key] = newFlight(htlc, in, out)
+		}
+		f.trim()
+		return nil
+	}
+	return errors.Errorf("unknown htlc %s", key)
+}
+
+// expire fails the HTLCs held longer than the hold timeout.
+func (f *Firewall) expire(ctx context.Context, now time.Time, sub chan *events.Event) {
+	var expired []models.InterceptedHTLC
+	f.mu.Lock()
+	for _, htlc := range f.htlcs {
+		if htlc.Resolved.IsZero() && now.Sub(htlc.Intercepted) >= f.holdTimeout {
+			f.resolve(htlc, models.InterceptFail, nil, "hold timed out", now)
+			expired = append(expired, *htlc)
+		}
+	}
+	f.trim()
+	f.mu.Unlock()
+	for i := range expired {
+		send(ctx, sub, &expired[i])
+	}
+}
+
+// release marks the HTLCs held as resumed once the interceptor stopped,
+// the node resumes them.
+func (f *Firewall) release(ctx context.Context, sub chan *events.Event) {
+	var released []models.InterceptedHTLC
+	f.mu.Lock()
+	for len(f.resolutions) > 0 {
+		<-f.resolutions
+	}
+	now := time.Now()
+	for _, htlc := range f.htlcs {
+		if htlc.Resolved.IsZero() {
+			htlc.Action = models.InterceptResume
+			htlc.Reason = "interceptor stopped"
+			htlc.Resolved = now
+			released = append(released, *htlc)
+		}
+	}
+	f.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	for i := range released {
+		send(ctx, sub, &released[i])
+	}
+}
+
+// trim drops the oldest resolved HTLCs above the history size.
+func (f *Firewall) trim() {
+	resolved := 0
+	for _, htlc := range f.htlcs {
+		if !htlc.Resolved.IsZero() {
+			resolved++
+		}
+	}
+	htlcs := f.htlcs[:0]
+	for _, htlc := range f.htlcs {
+		if resolved > historySize && !htlc.Resolved.IsZero() {
+			resolved--
+			continue
+		}
+		htlcs = append(htlcs, htlc)
+	}
+	f.htlcs = htlcs
+}
+
+// RoutingEvent removes the HTLC of the settled or failed forward from the
+// HTLCs in flight.
+func (f *Firewall) RoutingEvent(e *models.RoutingEvent) {
+	if e.Direction != models.RoutingForward || e.Status == models.RoutingStatusActive {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.inflight, models.CircuitKey(e.IncomingChannelId, e.IncomingHtlcId))
+}
+
+// List returns the HTLCs held and the last ones resolved, in the order of
+// their interception.
+func (f *Firewall) List() []*models.InterceptedHTLC {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	htlcs := make([]*models.InterceptedHTLC, len(f.htlcs))
+	for i := range f.htlcs {
+		htlc := *f.htlcs[i]
+		htlcs[i] = &htlc
+	}
+	return htlcs
+}
+
+// pubkey returns the pubkey of the peer of the channel, empty if it is not
+// known yet, the lookup of the peers is then started.
+func (f *Firewall) pubkey(id uint64) string {
+	pubkey, ok := f.pubkeys[id]
+	if !ok && id != 0 {
+		f.find()
+	}
+	return pubkey
+}
+
+// alias returns the alias of the node of the pubkey, the pubkey if it
+// has none or if it is not known yet.
+func (f *Firewall) alias(pubkey string) string {
+	if pubkey == "" {
+		return ""
+	}
+	alias, ok := f.aliases[pubkey]
+	if !ok {
+		f.find()
+	}
+	if alias == "" {
+		return pubkey
+	}
+	return alias
+}
+
+// find wakes up the lookup of the peers.
+func (f *Firewall) find() {
+	select {
+	case f.lookup <- struct{}{}:
+	default:
+	}
+}
+
+// findAliases asks the node for the aliases of the peers not known yet.
+func (f *Firewall) findAliases(ctx context.Context) {
+	f.mu.Lock()
+	var pubkeys []string
+	seen := map[string]bool{}
+	for _, pubkey := range f.pubkeys {
+		if _, ok := f.aliases[pubkey]; !ok && pubkey != "" && !seen[pubkey] {
+			seen[pubkey] = true
+			pubkeys = append(pubkeys, pubkey)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, pubkey := range pubkeys {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		alias := ""
+		node, err := f.network.GetNode(ctx, pubkey, false)
+		cancel()
+		if err != nil {
+			f.logger.Debug("cannot find node", logging.String("pubkey", pubkey), logging.Error(err))
+		} else {
+			alias = node.Alias
+		}
+		f.mu.Lock()
+		f.aliases[pubkey] = alias
+		f.mu.Unlock()
+	}
+}
+
+func send(ctx context.Context, sub chan *events.Event, htlc *models.InterceptedHTLC) {
+	select {
+	case sub <- events.NewWithData(events.HTLCIntercepted, htlc):
+	case <-ctx.Done():
+	}
+}
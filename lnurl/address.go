@@ -0,0 +1,31 @@
+package lnurl
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IsAddress returns true if s looks like a lightning address,
+// user@domain.
+func IsAddress(s string) bool {
+	user, domain, ok := strings.Cut(s, "@")
+	return ok && user != "" && strings.Contains(domain, ".") && !strings.ContainsAny(s, "/?: ")
+}
+
+// AddressURL returns the LNURL-pay url behind a lightning address
+// (LUD-16), https://domain/.well-known/lnurlp/user.
+func AddressURL(address string) (*url.URL, error) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	user, domain, ok := strings.Cut(address, "@")
+	if !ok || user == "" || domain == "" {
+		return nil, errors.Errorf("invalid lightning address %q", address)
+	}
+
+	u := &url.URL{Scheme: "https", Host: domain, Path: "/.well-known/lnurlp/" + user}
+	if strings.HasSuffix(domain, ".onion") {
+		u.Scheme = "http"
+	}
+	return u, nil
+}
@@ -0,0 +1,191 @@
+// Package lnurl decodes LNURL strings and runs the LNURL-pay (LUD-06)
+// and LNURL-withdraw (LUD-03) flows against the service, the invoices
+// being paid or created by the node.
+package lnurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	TagPay      = "payRequest"
+	TagWithdraw = "withdrawRequest"
+
+	maxResponse = 1 << 20
+)
+
+// Decode returns the url of a bech32 LNURL, of a LUD-17 url, lnurlp://
+// or lnurlw://, or of a lightning address, with or without the
+// lightning: prefix.
+func Decode(s string) (*url.URL, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 10 && strings.EqualFold(s[:10], "lightning:") {
+		s = s[10:]
+	}
+
+	if IsAddress(s) {
+		return AddressURL(s)
+	}
+
+	for _, scheme := range []string{"lnurlp://", "lnurlw://", "keyauth://"} {
+		if len(s) > len(scheme) && strings.EqualFold(s[:len(scheme)], scheme) {
+			u, err := url.Parse("https://" + s[len(scheme):])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if strings.HasSuffix(u.Hostname(), ".onion") {
+				u.Scheme = "http"
+			}
+			return u, nil
+		}
+	}
+
+	hrp, data, err := bech32.DecodeNoLimit(strings.ToLower(s))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if hrp != "lnurl" {
+		return nil, errors.Errorf("unexpected prefix %q", hrp)
+	}
+	raw, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	u, err := url.Parse(string(raw))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && strings.HasSuffix(u.Hostname(), ".onion")) {
+		return nil, errors.Errorf("unsupported url %s", u)
+	}
+	return u, nil
+}
+
+// Params are the parameters of the first request, Pay or Withdraw is
+// set depending on the tag.
+type Params struct {
+	Tag      string
+	URL      *url.URL
+	Pay      *PayParams
+	Withdraw *WithdrawParams
+}
+
+// Domain is the host displayed to confirm the flow.
+func (p *Params) Domain() string {
+	return p.URL.Hostname()
+}
+
+type response struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	Tag    string `json:"tag"`
+}
+
+type Client struct {
+	http *http.Client
+}
+
+// Fetch returns the parameters of the LNURL.
+func (c *Client) Fetch(ctx context.Context, u *url.URL) (*Params, error) {
+	body, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp response
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	params := &Params{Tag: resp.Tag, URL: u}
+	switch resp.Tag {
+	case TagPay:
+		params.Pay = &PayParams{}
+		err = json.Unmarshal(body, params.Pay)
+	case TagWithdraw:
+		params.Withdraw = &WithdrawParams{}
+		err = json.Unmarshal(body, params.Withdraw)
+	default:
+		return nil, errors.Errorf("unsupported lnurl tag %q", resp.Tag)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return params, nil
+}
+
+// get returns the body of the answer to a GET of the url, an error if
+// the service answers with the ERROR status.
+func (c *Client) get(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var status response
+	if json.Unmarshal(body, &status) == nil && strings.EqualFold(status.Status, "ERROR") {
+		return nil, errors.Errorf("%s: %s", u.Hostname(), status.Reason)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, errors.Errorf("%s: %s", u.Hostname(), resp.Status)
+	}
+	return body, nil
+}
+
+// callback returns the callback url with the query values added, the
+// callback may already have a query.
+func callback(raw string, values url.Values) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && strings.HasSuffix(u.Hostname(), ".onion")) {
+		return nil, errors.Errorf("unsupported callback %s", u)
+	}
+	query := u.Query()
+	for k := range values {
+		query.Set(k, values.Get(k))
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// NewClient returns a client using the proxy of the config.
+func NewClient(cfg config.HTTP) (*Client, error) {
+	client, err := cfg.Client()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{http: client}, nil
+}
+
+func formatMsat(msat int64) string {
+	if msat%1000 == 0 {
+		return fmt.Sprintf("%d sat", msat/1000)
+	}
+	return fmt.Sprintf("%.3f sat", float64(msat)/1000)
+}
@@ -0,0 +1,191 @@
+package lnurl
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// Node is the part of the network used by the flows.
+type Node interface {
+	DecodePayReq(context.Context, string) (*models.PayReq, error)
+	SendPayment(context.Context, *models.PayReq) (*models.Payment, error)
+	CreateInvoice(context.Context, int64, string) (*models.Invoice, error)
+}
+
+// PayParams are the parameters of a LNURL-pay, amounts in millisatoshis.
+type PayParams struct {
+	Callback       string `json:"callback"`
+	MinSendable    int64  `json:"minSendable"`
+	MaxSendable    int64  `json:"maxSendable"`
+	Metadata       string `json:"metadata"`
+	CommentAllowed int    `json:"commentAllowed"`
+}
+
+// metadata returns the content of the entry of the metadata with the
+// given type, e.g. text/plain.
+func (p *PayParams) metadata(kind string) string {
+	var entries [][]interface{}
+	if json.Unmarshal([]byte(p.Metadata), &entries) != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if len(e) == 2 && e[0] == kind {
+			s, _ := e[1].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+func (p *PayParams) Description() string {
+	return p.metadata("text/plain")
+}
+
+// Identifier returns the lightning address of the metadata, if any.
+func (p *PayParams) Identifier() string {
+	if id := p.metadata("text/identifier"); id != "" {
+		return id
+	}
+	return p.metadata("text/email")
+}
+
+// Range returns the amounts accepted by the service.
+func (p *PayParams) Range() string {
+	if p.MinSendable == p.MaxSendable {
+		return formatMsat(p.MinSendable)
+	}
+	return fmt.Sprintf("%s - %s", formatMsat(p.MinSendable), formatMsat(p.MaxSendable))
+}
+
+type SuccessAction struct {
+	Tag         string `json:"tag"`
+	Message     string `json:"message"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Ciphertext  string `json:"ciphertext"`
+	IV          string `json:"iv"`
+}
+
+// Text returns the message of the action to display once paid, the aes
+// message is decrypted with the preimage of the payment.
+func (a *SuccessAction) Text(preimage []byte) (string, error) {
+	switch a.Tag {
+	case "message":
+		return a.Message, nil
+	case "url":
+		return fmt.Sprintf("%s %s", a.Description, a.URL), nil
+	case "aes":
+		plain, err := decryptAES(preimage, a.Ciphertext, a.IV)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s", a.Description, plain), nil
+	}
+	return "", nil
+}
+
+func decryptAES(key []byte, ciphertext, iv string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	vector, err := base64.StdEncoding.DecodeString(iv)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(vector) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return "", errors.New("invalid aes success action")
+	}
+	cipher.NewCBCDecrypter(block, vector).CryptBlocks(data, data)
+
+	// PKCS#7 padding
+	pad := int(data[len(data)-1])
+	if pad == 0 || pad > len(data) {
+		return "", errors.New("invalid aes padding")
+	}
+	return string(data[:len(data)-pad]), nil
+}
+
+type payResponse struct {
+	PR            string         `json:"pr"`
+	SuccessAction *SuccessAction `json:"successAction"`
+}
+
+// Invoice is the invoice returned by the service for a payment, checked
+// against the amount and the metadata.
+type Invoice struct {
+	PayReq        *models.PayReq
+	SuccessAction *SuccessAction
+}
+
+// RequestInvoice requests an invoice of the amount to the service and
+// decodes it with the node, to confirm its destination before paying.
+func (c *Client) RequestInvoice(ctx context.Context, node Node, p *PayParams, msat int64, comment string) (*Invoice, error) {
+	if msat < p.MinSendable || msat > p.MaxSendable {
+		return nil, errors.Errorf("amount %s out of %s", formatMsat(msat), p.Range())
+	}
+
+	values := url.Values{"amount": {strconv.FormatInt(msat, 10)}}
+	if comment != "" && p.CommentAllowed > 0 {
+		if len(comment) > p.CommentAllowed {
+			comment = comment[:p.CommentAllowed]
+		}
+		values.Set("comment", comment)
+	}
+	u, err := callback(p.Callback, values)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var resp payResponse
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payreq, err := node.DecodePayReq(ctx, resp.PR)
+	if err != nil {
+		return nil, err
+	}
+	if payreq.Amount != msat/1000 {
+		return nil, errors.Errorf("invoice of %d sat instead of %s", payreq.Amount, formatMsat(msat))
+	}
+	hash := sha256.Sum256([]byte(p.Metadata))
+	if payreq.DescriptionHash != hex.EncodeToString(hash[:]) {
+		return nil, errors.New("invoice description hash does not match the metadata")
+	}
+
+	return &Invoice{PayReq: payreq, SuccessAction: resp.SuccessAction}, nil
+}
+
+// Pay pays the invoice with the node.
+func Pay(ctx context.Context, node Node, invoice *Invoice) (*models.Payment, error) {
+	payment, err := node.SendPayment(ctx, invoice.PayReq)
+	if err != nil {
+		return nil, err
+	}
+	if payment.PaymentError != "" {
+		return payment, errors.New(payment.PaymentError)
+	}
+	return payment, nil
+}
@@ -0,0 +1,51 @@
+package lnurl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// WithdrawParams are the parameters of a LNURL-withdraw, amounts in
+// millisatoshis.
+type WithdrawParams struct {
+	Callback           string `json:"callback"`
+	K1                 string `json:"k1"`
+	MinWithdrawable    int64  `json:"minWithdrawable"`
+	MaxWithdrawable    int64  `json:"maxWithdrawable"`
+	DefaultDescription string `json:"defaultDescription"`
+}
+
+// Range returns the amounts the service accepts to send.
+func (p *WithdrawParams) Range() string {
+	if p.MinWithdrawable == p.MaxWithdrawable {
+		return formatMsat(p.MinWithdrawable)
+	}
+	return fmt.Sprintf("%s - %s", formatMsat(p.MinWithdrawable), formatMsat(p.MaxWithdrawable))
+}
+
+// Withdraw creates an invoice of the amount with the node and sends it
+// to the service, which pays it asynchronously.
+func (c *Client) Withdraw(ctx context.Context, node Node, p *WithdrawParams, msat int64) error {
+	if msat < p.MinWithdrawable || msat > p.MaxWithdrawable {
+		return errors.Errorf("amount %s out of %s", formatMsat(msat), p.Range())
+	}
+
+	invoice, err := node.CreateInvoice(ctx, msat/1000, p.DefaultDescription)
+	if err != nil {
+		return err
+	}
+
+	u, err := callback(p.Callback, url.Values{
+		"k1": {p.K1},
+		"pr": {invoice.PaymentRequest},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.get(ctx, u)
+	return err
+}
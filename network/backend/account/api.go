@@ -0,0 +1,174 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	// defaultTimeout is the number of seconds to wait for the api.
+	defaultTimeout = 15
+	maxResponse    = 4 << 20
+	// historyLimit is the number of the last invoices and payments asked
+	// to the wallet.
+	historyLimit = 100
+)
+
+// api is the API of the custodial wallet of the account.
+type api interface {
+	// implementation is the name of the API displayed in the header.
+	implementation() string
+	wallet(context.Context) (*wallet, error)
+	// history returns the invoices and the payments of the account.
+	history(context.Context) ([]*entry, error)
+}
+
+type wallet struct {
+	Name        string
+	BalanceMsat int64
+}
+
+// entry is an invoice or a payment of the account, or an on-chain deposit
+// of LNDHub.
+type entry struct {
+	Hash string
+	// Invoice is true for an invoice, false for a payment.
+	Invoice bool
+	// AmountMsat is received if positive, sent if negative.
+	AmountMsat int64
+	FeeMsat    int64
+	Memo       string
+	Bolt11     string
+	Pending    bool
+	Date       time.Time
+	// Expiry is the number of seconds the invoice is valid for.
+	Expiry int64
+	// OnChain is true for a deposit, Hash is its txid.
+	OnChain       bool
+	Confirmations int32
+}
+
+// client sends the requests of the API through the proxy of the network,
+// if any.
+type client struct {
+	http *http.Client
+	base *url.URL
+}
+
+func newClient(cfg *config.Network, base string) (*client, error) {
+	u, err := url.Parse(strings.TrimSuffix(base, "/"))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New("address of the account is not an http(s) url")
+	}
+
+	httpClient, err := config.HTTP{Proxy: cfg.Proxy, Timeout: defaultTimeout}.Client()
+	if err != nil {
+		return nil, err
+	}
+	return &client{http: httpClient, base: u}, nil
+}
+
+// do sends the request of the path with the JSON of in, if not nil, and
+// decodes the JSON answer to out.
+func (c *client) do(ctx context.Context, method, path string, header http.Header, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// the error quotes the url, not the credentials of the headers.
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("%s: %s", req.URL.Hostname(), resp.Status)
+	}
+	return errors.WithStack(json.Unmarshal(data, out))
+}
+
+// apiTime is a time of the APIs, in unix seconds as a number or a string,
+// or in ISO 8601 as with LNbits 1.0.
+type apiTime struct {
+	time.Time
+}
+
+func (t *apiTime) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		t.Time = time.Unix(int64(sec), 0)
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05.999999"} {
+		parsed, err := time.Parse(layout, s)
+		if err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return errors.Errorf("invalid time %q", s)
+}
+
+// apiHash is a payment hash in hex, or the Node.js buffer of LNDHub.
+type apiHash string
+
+func (h *apiHash) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte("{")) {
+		// {"type": "Buffer", "data": [1, 2, ...]}
+		var buf struct {
+			Data []int `json:"data"`
+		}
+		err := json.Unmarshal(data, &buf)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b := make([]byte, len(buf.Data))
+		for i := range buf.Data {
+			b[i] = byte(buf.Data[i])
+		}
+		*h = apiHash(hex.EncodeToString(b))
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	*h = apiHash(s)
+	return nil
+}
@@ -0,0 +1,95 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+// lnbits is the API of an LNbits wallet, read with its invoice/read key.
+type lnbits struct {
+	client *client
+	header http.Header
+}
+
+func newLNbits(cfg *config.Network) (*lnbits, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("lnbits: api_key of the wallet is missing")
+	}
+	c, err := newClient(cfg, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("X-Api-Key", cfg.APIKey)
+	return &lnbits{client: c, header: header}, nil
+}
+
+func (l *lnbits) implementation() string {
+	return "lnbits"
+}
+
+func (l *lnbits) wallet(ctx context.Context) (*wallet, error) {
+	var resp struct {
+		Name    string `json:"name"`
+		Balance int64  `json:"balance"`
+	}
+	err := l.client.do(ctx, http.MethodGet, "/api/v1/wallet", l.header, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet{Name: resp.Name, BalanceMsat: resp.Balance}, nil
+}
+
+type lnbitsPayment struct {
+	PaymentHash string `json:"payment_hash"`
+	// Amount and Fee are in msat, negative for a payment.
+	Amount  int64   `json:"amount"`
+	Fee     int64   `json:"fee"`
+	Memo    string  `json:"memo"`
+	Bolt11  string  `json:"bolt11"`
+	Pending bool    `json:"pending"`
+	Status  string  `json:"status"`
+	Time    apiTime `json:"time"`
+	Expiry  apiTime `json:"expiry"`
+}
+
+func (l *lnbits) history(ctx context.Context) ([]*entry, error) {
+	var payments []lnbitsPayment
+	path := "/api/v1/payments?limit=" + strconv.Itoa(historyLimit)
+	err := l.client.do(ctx, http.MethodGet, path, l.header, nil, &payments)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*entry, 0, len(payments))
+	for _, p := range payments {
+		// the status is the one of LNbits 1.0, pending the one before.
+		if p.Status == "failed" {
+			continue
+		}
+		fee := p.Fee
+		if fee < 0 {
+			fee = -fee
+		}
+		e := &entry{
+			Hash:       p.PaymentHash,
+			Invoice:    p.Amount > 0,
+			AmountMsat: p.Amount,
+			FeeMsat:    fee,
+			Memo:       p.Memo,
+			Bolt11:     p.Bolt11,
+			Pending:    p.Pending || p.Status == "pending",
+			Date:       p.Time.Time,
+		}
+		if !p.Expiry.IsZero() && !p.Time.IsZero() {
+			e.Expiry = int64(p.Expiry.Sub(p.Time.Time).Seconds())
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
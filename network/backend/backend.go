@@ -1,3 +1,7 @@
+// Package backend defines the interface implemented by the lightning
+// node backends. The lnd package talks to a lnd node over gRPC, the cln
+// package to a Core Lightning node over its JSON-RPC socket, the mock
+// package keeps an in-memory state for development and tests.
 package backend
 
 import (
@@ -7,6 +11,9 @@ import (
 	"github.com/edouardparis/lntop/network/options"
 )
 
+// Backend is the set of node calls used by the ui, the pubsub and the
+// commands. The Subscribe methods block until the context is done and
+// send the updates to the channel.
 type Backend interface {
 	Ping() error
 
@@ -20,6 +27,10 @@ type Backend interface {
 
 	GetNode(context.Context, string, bool) (*models.Node, error)
 
+	// ListNodes returns the nodes of the graph known by the node, with
+	// their number of channels and total capacity.
+	ListNodes(context.Context) ([]*models.Node, error)
+
 	GetWalletBalance(context.Context) (*models.WalletBalance, error)
 
 	GetChannelsBalance(context.Context) (*models.ChannelsBalance, error)
@@ -32,10 +43,53 @@ type Backend interface {
 
 	GetInvoice(context.Context, string) (*models.Invoice, error)
 
+	// ListInvoices returns the last invoices of the node, the most recent
+	// last.
+	ListInvoices(context.Context) ([]*models.Invoice, error)
+
+	NewAddress(context.Context) (string, error)
+
 	DecodePayReq(context.Context, string) (*models.PayReq, error)
 
+	// ListOffers returns the BOLT12 offers of the node with the invoices
+	// created for their invoice requests.
+	ListOffers(context.Context) ([]*models.Offer, error)
+
+	// CreateOffer creates a BOLT12 offer of the amount in sat, chosen by
+	// the payer if zero, with the description.
+	CreateOffer(context.Context, int64, string) (*models.Offer, error)
+
+	// DisableOffer disables the offer of the id, its invoice requests are
+	// no longer answered.
+	DisableOffer(context.Context, string) error
+
+	// SignMessage signs the message with the key of the node and returns
+	// the zbase32 signature.
+	SignMessage(context.Context, string) (string, error)
+
+	// VerifyMessage verifies the zbase32 signature of the message.
+	VerifyMessage(context.Context, string, string) (*models.VerifiedMessage, error)
+
+	// SendPayment pays the payment request and returns once the payment
+	// succeeded or failed.
 	SendPayment(context.Context, *models.PayReq) (*models.Payment, error)
 
+	// Rebalance moves the amount in sat from the local balance of the
+	// first channel to the one of the second with a circular payment, for
+	// a fee of at most the max fee in sat. It sends each attempt to the
+	// channel and returns once the payment succeeded or failed.
+	Rebalance(context.Context, *models.Channel, *models.Channel, int64, int64, chan *models.RebalanceAttempt) (*models.Payment, error)
+
+	// SendKeysend pays the keysend or the AMP payment without invoice. It
+	// sends the payment in flight to the channel at each update and
+	// returns once it succeeded or failed.
+	SendKeysend(context.Context, *models.Keysend, chan *models.Payment) (*models.Payment, error)
+
+	// ListPayments returns the outgoing payments, the most recent last.
+	ListPayments(context.Context) ([]*models.Payment, error)
+
+	SubscribePayments(context.Context, chan *models.Payment) error
+
 	GetTransactions(context.Context) ([]*models.Transaction, error)
 
 	SubscribeTransactions(context.Context, chan *models.Transaction) error
@@ -45,4 +99,106 @@ type Backend interface {
 	SubscribeGraphEvents(context.Context, chan *models.ChannelEdgeUpdate) error
 
 	GetForwardingHistory(context.Context, string, uint32) ([]*models.ForwardingEvent, error)
+
+	ListPeers(context.Context) ([]*models.Peer, error)
+
+	// ConnectPeer connects to the node of the pubkey at the host:port.
+	ConnectPeer(context.Context, string, string) error
+
+	DisconnectPeer(context.Context, string) error
+
+	ClosedChannels(context.Context) ([]*models.ClosedChannel, error)
+
+	PendingSweeps(context.Context) ([]*models.PendingSweep, error)
+
+	BumpFee(context.Context, string, uint64) error
+
+	ListUnspent(context.Context) ([]*models.UTXO, error)
+
+	Consolidate(context.Context, []*models.UTXO, uint64) (*models.Consolidation, error)
+
+	LabelTransaction(context.Context, string, string) error
+
+	SendOnChain(context.Context, string, int64, uint64, []string) (string, error)
+
+	OpenChannel(context.Context, string, int64, uint64, bool, []string) (string, error)
+
+	// EstimateBatchOpen returns the on-chain fee of a transaction funding
+	// the channels at the fee rate in sat/vbyte, estimated by the wallet
+	// if zero.
+	EstimateBatchOpen(context.Context, []*models.BatchChannel, uint64) (*models.FeeEstimate, error)
+
+	// EstimateFeeRate returns the fee rate in sat/vbyte estimated to
+	// confirm a transaction within the number of blocks.
+	EstimateFeeRate(context.Context, uint32) (uint64, error)
+
+	// BatchOpenChannel opens the channels with a single funding
+	// transaction and returns its txid.
+	BatchOpenChannel(context.Context, []*models.BatchChannel, uint64) (string, error)
+
+	// UpdateChannelPolicy sets the routing policy of the node for the
+	// channel.
+	UpdateChannelPolicy(context.Context, *models.Channel, *models.RoutingPolicy) error
+
+	// CloseChannel closes the channel cooperatively at the fee rate in
+	// sat/vbyte, estimated if zero, or by publishing the commitment if
+	// forced, and returns the txid of the closing transaction.
+	CloseChannel(context.Context, *models.Channel, bool, uint64) (string, error)
+
+	// Watchtower returns the state of the watchtower client of the node.
+	Watchtower(context.Context) (*models.Watchtower, error)
+
+	// AddTower registers the tower of the pubkey at the host:port.
+	AddTower(context.Context, string, string) error
+
+	RemoveTower(context.Context, string) error
+
+	// MissionControl returns the history of the payment attempts between
+	// the pairs of nodes of the pathfinding of the node.
+	MissionControl(context.Context) ([]*models.MissionPair, error)
+
+	// PairProbability returns the success probability estimated by the
+	// pathfinding of the amount in msat from the first node to the
+	// second one.
+	PairProbability(context.Context, string, string, int64) (float64, error)
+
+	// ResetMissionControl clears the history of the payment attempts.
+	ResetMissionControl(context.Context) error
+
+	// ImportMissionControl adds the pairs to the history of the payment
+	// attempts, the more recent results are replaced if forced.
+	ImportMissionControl(context.Context, []*models.MissionPair, bool) error
+
+	SubscribeChannelBackups(context.Context, chan *models.ChannelBackup) error
+
+	VerifyChannelBackup(context.Context, *models.ChannelBackup) error
+
+	// ExportChannelBackup returns the current static channel backup of
+	// all the channels.
+	ExportChannelBackup(context.Context) (*models.ChannelBackup, error)
+
+	// InterceptHTLCs holds the HTLCs forwarded through the node and sends
+	// them to the first channel, they are resolved by the resolutions
+	// received on the second one.
+	InterceptHTLCs(context.Context, chan *models.InterceptedHTLC, chan *models.HTLCResolution) error
+
+	// GetState returns the state of the node, it answers while the
+	// wallet is locked.
+	GetState(context.Context) (models.NodeState, error)
+
+	// BakeMacaroon returns a new macaroon with only the permissions.
+	BakeMacaroon(context.Context, []models.Permission) ([]byte, error)
+}
+
+// History is implemented by the backends whose lists of invoices and of
+// payments are capped to the last ones for the ui, to read all of them for
+// the exports.
+type History interface {
+	// AllInvoices returns all the invoices of the node, the most recent
+	// last.
+	AllInvoices(context.Context) ([]*models.Invoice, error)
+
+	// AllPayments returns all the outgoing payments, the most recent
+	// last.
+	AllPayments(context.Context) ([]*models.Payment, error)
 }
@@ -0,0 +1,1143 @@
+// Package cln talks to a Core Lightning node over the JSON-RPC interface
+// of lightningd, on the unix socket given as address of the config:
+//
+//	[network]
+//	type = "cln"
+//	address = "/home/user/.lightning/bitcoin/lightning-rpc"
+//
+// lightningd has no sweeper, no labels of the transactions and no static
+// channel backups like lnd: these calls are not supported. The updates of
+// the channels, the transactions and the graph are not streamed, they are
+// polled by the ticker of the pubsub.
+package cln
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+const (
+	clnDefaultInvoiceExpiry = 3600
+	clnDialTimeout          = 5 * time.Second
+	// clnMessageRecord is the TLV record of the message of a keysend.
+	clnMessageRecord = "34349334"
+)
+
+var errNotSupported = errors.New("not supported by the cln backend")
+
+var _ backend.Backend = (*Backend)(nil)
+
+type Backend struct {
+	cfg    *config.Network
+	logger logging.Logger
+	rpc    *rpc
+}
+
+func (b *Backend) NodeName() string {
+	return b.cfg.Name
+}
+
+func (b *Backend) Ping() error {
+	_, err := b.getInfo(context.Background())
+	return err
+}
+
+func (b *Backend) getInfo(ctx context.Context) (*getInfo, error) {
+	var info getInfo
+	err := b.rpc.call(ctx, "getinfo", nil, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (b *Backend) Info(ctx context.Context) (*models.Info, error) {
+	b.logger.Debug("Retrieve info")
+	info, err := b.getInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.toInfo(), nil
+}
+
+// GetState returns the server as active once lightningd answers, it has
+// no locked wallet.
+func (b *Backend) GetState(ctx context.Context) (models.NodeState, error) {
+	_, err := b.getInfo(ctx)
+	if err != nil {
+		return models.NodeStateUnknown, err
+	}
+	return models.NodeStateServerActive, nil
+}
+
+func (b *Backend) GetNode(ctx context.Context, pubkey string, includeChannels bool) (*models.Node, error) {
+	b.logger.Debug("GetNode")
+
+	var resp struct {
+		Nodes []node `json:"nodes"`
+	}
+	err := b.rpc.call(ctx, "listnodes", map[string]interface{}{"id": pubkey}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.Node{PubKey: pubkey}
+	if len(resp.Nodes) > 0 {
+		result = resp.Nodes[0].toNode()
+	}
+
+	var channels struct {
+		Channels []gossipChannel `json:"channels"`
+	}
+	err = b.rpc.call(ctx, "listchannels", map[string]interface{}{"source": pubkey}, &channels)
+	if err != nil {
+		return nil, err
+	}
+	result.NumChannels = uint32(len(channels.Channels))
+	for i := range channels.Channels {
+		c := &channels.Channels[i]
+		result.TotalCapacity += c.AmountMsat.sat()
+		if includeChannels {
+			result.Channels = append(result.Channels, &models.Channel{
+				ID:           scidToID(c.ShortChannelID),
+				Capacity:     c.AmountMsat.sat(),
+				RemotePubKey: c.Destination,
+				LocalPolicy:  c.toRoutingPolicy(),
+			})
+		}
+	}
+
+	if forcedAlias, ok := b.cfg.Aliases[result.PubKey]; ok {
+		result.ForcedAlias = forcedAlias
+	}
+	return result, nil
+}
+
+// ListNodes returns the nodes of listnodes, their channels are counted
+// from the directions of listchannels of which they are the source.
+func (b *Backend) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	b.logger.Debug("ListNodes")
+
+	var resp struct {
+		Nodes []node `json:"nodes"`
+	}
+	err := b.rpc.call(ctx, "listnodes", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels struct {
+		Channels []gossipChannel `json:"channels"`
+	}
+	err = b.rpc.call(ctx, "listchannels", nil, &channels)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*models.Node, len(resp.Nodes))
+	index := make(map[string]*models.Node, len(resp.Nodes))
+	for i := range resp.Nodes {
+		nodes[i] = resp.Nodes[i].toNode()
+		if forcedAlias, ok := b.cfg.Aliases[nodes[i].PubKey]; ok {
+			nodes[i].ForcedAlias = forcedAlias
+		}
+		index[nodes[i].PubKey] = nodes[i]
+	}
+	for i := range channels.Channels {
+		if n, ok := index[channels.Channels[i].Source]; ok {
+			n.NumChannels++
+			n.TotalCapacity += channels.Channels[i].AmountMsat.sat()
+		}
+	}
+	return nodes, nil
+}
+
+func (b *Backend) GetWalletBalance(ctx context.Context) (*models.WalletBalance, error) {
+	b.logger.Debug("Retrieve wallet balance")
+
+	funds, err := b.listFunds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance := &models.WalletBalance{}
+	for _, o := range funds.Outputs {
+		switch o.Status {
+		case "confirmed":
+			balance.ConfirmedBalance += o.AmountMsat.sat()
+		case "unconfirmed":
+			balance.UnconfirmedBalance += o.AmountMsat.sat()
+		}
+	}
+	balance.TotalBalance = balance.ConfirmedBalance + balance.UnconfirmedBalance
+	return balance, nil
+}
+
+func (b *Backend) GetChannelsBalance(ctx context.Context) (*models.ChannelsBalance, error) {
+	b.logger.Debug("Retrieve channel balance")
+
+	funds, err := b.listFunds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance := &models.ChannelsBalance{}
+	for _, c := range funds.Channels {
+		switch c.State {
+		case "CHANNELD_NORMAL":
+			balance.Balance += c.OurAmountMsat.sat()
+		case "OPENINGD", "CHANNELD_AWAITING_LOCKIN", "DUALOPEND_AWAITING_LOCKIN":
+			balance.PendingOpenBalance += c.OurAmountMsat.sat()
+		}
+	}
+	return balance, nil
+}
+
+func (b *Backend) listFunds(ctx context.Context) (*listFunds, error) {
+	var funds listFunds
+	err := b.rpc.call(ctx, "listfunds", nil, &funds)
+	if err != nil {
+		return nil, err
+	}
+	return &funds, nil
+}
+
+func (b *Backend) ListChannels(ctx context.Context, opt ...options.Channel) ([]*models.Channel, error) {
+	b.logger.Debug("List channels")
+
+	info, err := b.getInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Channels []peerChannel `json:"channels"`
+	}
+	err = b.rpc.call(ctx, "listpeerchannels", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.NewChannelOptions(opt...)
+	channels := []*models.Channel{}
+	for i := range resp.Channels {
+		ch := resp.Channels[i].toChannel(info.BlockHeight)
+		switch {
+		case ch.Status == 0:
+			continue
+		case ch.Status != models.ChannelActive && ch.Status != models.ChannelInactive:
+			if !opts.Pending {
+				continue
+			}
+		case opts.Active && ch.Status != models.ChannelActive,
+			opts.Inactive && ch.Status != models.ChannelInactive,
+			opts.Public && ch.Private,
+			opts.Private && !ch.Private:
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
+
+func (b *Backend) GetChannelInfo(ctx context.Context, channel *models.Channel) error {
+	b.logger.Debug("GetChannelInfo")
+
+	if channel.ID == 0 {
+		return nil
+	}
+
+	info, err := b.getInfo(ctx)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Channels []gossipChannel `json:"channels"`
+	}
+	err = b.rpc.call(ctx, "listchannels",
+		map[string]interface{}{"short_channel_id": models.ToScid(channel.ID)}, &resp)
+	if err != nil {
+		return err
+	}
+	for i := range resp.Channels {
+		c := &resp.Channels[i]
+		if c.Source == info.ID {
+			channel.LocalPolicy = c.toRoutingPolicy()
+		} else {
+			channel.RemotePolicy = c.toRoutingPolicy()
+		}
+		t := time.Unix(c.LastUpdate, 0)
+		if channel.LastUpdate == nil || t.After(*channel.LastUpdate) {
+			channel.LastUpdate = &t
+		}
+	}
+	return nil
+}
+
+func (b *Backend) CreateInvoice(ctx context.Context, amount int64, desc string) (*models.Invoice, error) {
+	b.logger.Debug("Create invoice...",
+		logging.Int64("amount", amount),
+		logging.String("desc", desc))
+
+	// the labels of the invoices are unique.
+	label := fmt.Sprintf("lntop-%d", time.Now().UnixNano())
+	var resp struct {
+		PaymentHash string `json:"payment_hash"`
+		Bolt11      string `json:"bolt11"`
+		ExpiresAt   int64  `json:"expires_at"`
+	}
+	err := b.rpc.call(ctx, "invoice", map[string]interface{}{
+		"amount_msat": amount * 1000,
+		"label":       label,
+		"description": desc,
+		"expiry":      clnDefaultInvoiceExpiry,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	i := &invoice{
+		Label:       label,
+		Bolt11:      resp.Bolt11,
+		PaymentHash: resp.PaymentHash,
+		AmountMsat:  msat(amount * 1000),
+		Status:      "unpaid",
+		Description: desc,
+		ExpiresAt:   resp.ExpiresAt,
+	}
+	result := i.toInvoice()
+	if result.CreationDate == 0 {
+		result.CreationDate = time.Now().Unix()
+		result.Expiry = clnDefaultInvoiceExpiry
+	}
+	return result, nil
+}
+
+// ListInvoices returns the invoices of the node in the order of their
+// creation.
+func (b *Backend) ListInvoices(ctx context.Context) ([]*models.Invoice, error) {
+	b.logger.Debug("List invoices")
+
+	var resp struct {
+		Invoices []invoice `json:"invoices"`
+	}
+	err := b.rpc.call(ctx, "listinvoices", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]*models.Invoice, len(resp.Invoices))
+	for i := range resp.Invoices {
+		invoices[i] = resp.Invoices[i].toInvoice()
+	}
+	sort.SliceStable(invoices, func(i, j int) bool {
+		return invoices[i].CreationDate < invoices[j].CreationDate
+	})
+	return invoices, nil
+}
+
+// ListOffers returns the offers of listoffers decoded one by one, the
+// invoices of their invoice requests are the ones of listinvoices with
+// their offer id.
+func (b *Backend) ListOffers(ctx context.Context) ([]*models.Offer, error) {
+	b.logger.Debug("List offers")
+
+	var resp struct {
+		Offers []offer `json:"offers"`
+	}
+	err := b.rpc.call(ctx, "listoffers", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	var invoices struct {
+		Invoices []invoice `json:"invoices"`
+	}
+	err = b.rpc.call(ctx, "listinvoices", nil, &invoices)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]*models.Offer, len(resp.Offers))
+	byID := make(map[string]*models.Offer, len(resp.Offers))
+	for i := range resp.Offers {
+		offers[i] = resp.Offers[i].toOffer()
+		byID[offers[i].ID] = offers[i]
+		var decoded decodedOffer
+		err = b.rpc.call(ctx, "decode", map[string]interface{}{"string": offers[i].Bolt12}, &decoded)
+		if err != nil {
+			return nil, err
+		}
+		offers[i].Description = decoded.Description
+		offers[i].Amount = decoded.AmountMsat.sat()
+	}
+	for i := range invoices.Invoices {
+		o, ok := byID[invoices.Invoices[i].LocalOfferID]
+		if ok {
+			o.Requests = append(o.Requests, invoices.Invoices[i].toOfferRequest())
+		}
+	}
+	return offers, nil
+}
+
+func (b *Backend) CreateOffer(ctx context.Context, amount int64, description string) (*models.Offer, error) {
+	b.logger.Debug("Create offer...", logging.Int64("amount", amount))
+
+	params := map[string]interface{}{
+		"amount":      "any",
+		"description": description,
+	}
+	if amount > 0 {
+		params["amount"] = fmt.Sprintf("%dmsat", amount*1000)
+	}
+	var resp offer
+	err := b.rpc.call(ctx, "offer", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	o := resp.toOffer()
+	o.Description = description
+	o.Amount = amount
+	return o, nil
+}
+
+func (b *Backend) DisableOffer(ctx context.Context, id string) error {
+	b.logger.Debug("Disable offer...", logging.String("offer_id", id))
+
+	return b.rpc.call(ctx, "disableoffer", map[string]interface{}{"offer_id": id}, nil)
+}
+
+func (b *Backend) GetInvoice(ctx context.Context, rhash string) (*models.Invoice, error) {
+	b.logger.Debug("Retrieve invoice...", logging.String("r_hash", rhash))
+
+	var resp struct {
+		Invoices []invoice `json:"invoices"`
+	}
+	err := b.rpc.call(ctx, "listinvoices", map[string]interface{}{"payment_hash": rhash}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Invoices) == 0 {
+		return nil, errors.Errorf("invoice %s not found", rhash)
+	}
+	return resp.Invoices[0].toInvoice(), nil
+}
+
+// SubscribeInvoice sends the invoices as they are paid, lightningd does not
+// notify the created ones.
+func (b *Backend) SubscribeInvoice(ctx context.Context, channelInvoice chan *models.Invoice) error {
+	var index uint64
+	var resp struct {
+		Invoices []invoice `json:"invoices"`
+	}
+	err := b.rpc.call(ctx, "listinvoices", nil, &resp)
+	if err != nil {
+		return err
+	}
+	for _, i := range resp.Invoices {
+		if i.PayIndex > index {
+			index = i.PayIndex
+		}
+	}
+
+	for {
+		var paid invoice
+		err := b.rpc.call(ctx, "waitanyinvoice", map[string]interface{}{"lastpay_index": index}, &paid)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		index = paid.PayIndex
+		select {
+		case channelInvoice <- paid.toInvoice():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SubscribeRoutingEvents sends the forwards as they are created or
+// updated, it needs lightningd 23.11 or later.
+func (b *Backend) SubscribeRoutingEvents(ctx context.Context, channelEvents chan *models.RoutingEvent) error {
+	type wait struct {
+		Created  uint64   `json:"created"`
+		Updated  uint64   `json:"updated"`
+		Forwards *forward `json:"forwards"`
+		Details  *forward `json:"details"`
+	}
+
+	var next wait
+	err := b.rpc.call(ctx, "wait", map[string]interface{}{
+		"subsystem": "forwards", "indexname": "updated", "nextvalue": 0,
+	}, &next)
+	if err != nil {
+		return err
+	}
+	index := next.Updated
+
+	for {
+		var resp wait
+		err := b.rpc.call(ctx, "wait", map[string]interface{}{
+			"subsystem": "forwards", "indexname": "updated", "nextvalue": index + 1,
+		}, &resp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		index = resp.Updated
+		f := resp.Forwards
+		if f == nil {
+			f = resp.Details
+		}
+		if f == nil {
+			continue
+		}
+		select {
+		case channelEvents <- f.toRoutingEvent():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SubscribePayments waits for the updates of the parts of the payments,
+// the payment sent has the status of the part.
+func (b *Backend) SubscribePayments(ctx context.Context, channelPayments chan *models.Payment) error {
+	type wait struct {
+		Updated  uint64   `json:"updated"`
+		Sendpays *sendpay `json:"sendpays"`
+		Details  *sendpay `json:"details"`
+	}
+
+	var next wait
+	err := b.rpc.call(ctx, "wait", map[string]interface{}{
+		"subsystem": "sendpays", "indexname": "updated", "nextvalue": 0,
+	}, &next)
+	if err != nil {
+		return err
+	}
+	index := next.Updated
+
+	for {
+		var resp wait
+		err := b.rpc.call(ctx, "wait", map[string]interface{}{
+			"subsystem": "sendpays", "indexname": "updated", "nextvalue": index + 1,
+		}, &resp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		index = resp.Updated
+		s := resp.Sendpays
+		if s == nil {
+			s = resp.Details
+		}
+		if s == nil {
+			continue
+		}
+		select {
+		case channelPayments <- &models.Payment{Hash: s.PaymentHash, Status: payStatus(s.Status)}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SubscribeChannels blocks until the context is done, the channels are
+// polled.
+func (b *Backend) SubscribeChannels(ctx context.Context, _ chan *models.ChannelUpdate) error {
+	<-ctx.Done()
+	return nil
+}
+
+// SubscribeTransactions blocks until the context is done, the
+// transactions are polled.
+func (b *Backend) SubscribeTransactions(ctx context.Context, _ chan *models.Transaction) error {
+	<-ctx.Done()
+	return nil
+}
+
+// SubscribeGraphEvents blocks until the context is done, the policies of
+// the peers are read with the channels.
+func (b *Backend) SubscribeGraphEvents(ctx context.Context, _ chan *models.ChannelEdgeUpdate) error {
+	<-ctx.Done()
+	return nil
+}
+
+// SubscribeChannelBackups blocks until the context is done, lightningd
+// has no static channel backup to send.
+func (b *Backend) SubscribeChannelBackups(ctx context.Context, _ chan *models.ChannelBackup) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (b *Backend) VerifyChannelBackup(context.Context, *models.ChannelBackup) error {
+	return errNotSupported
+}
+
+func (b *Backend) ExportChannelBackup(context.Context) (*models.ChannelBackup, error) {
+	return nil, errNotSupported
+}
+
+// BakeMacaroon is not supported, lightningd has runes instead of
+// macaroons.
+func (b *Backend) BakeMacaroon(context.Context, []models.Permission) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// InterceptHTLCs is not supported, lightningd intercepts the HTLCs with
+// the htlc_accepted hook of a plugin.
+func (b *Backend) InterceptHTLCs(context.Context, chan *models.InterceptedHTLC, chan *models.HTLCResolution) error {
+	return errNotSupported
+}
+
+func (b *Backend) NewAddress(ctx context.Context) (string, error) {
+	b.logger.Debug("Create address...")
+
+	var resp struct {
+		Bech32 string `json:"bech32"`
+	}
+	err := b.rpc.call(ctx, "newaddr", map[string]interface{}{"addresstype": "bech32"}, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.Bech32, nil
+}
+
+func (b *Backend) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
+	b.logger.Info("decode payreq", logging.String("payreq", payreq))
+
+	var resp decodedInvoice
+	err := b.rpc.call(ctx, "decode", map[string]interface{}{"string": payreq}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toPayReq(payreq), nil
+}
+
+func (b *Backend) SignMessage(ctx context.Context, message string) (string, error) {
+	b.logger.Debug("Sign message...")
+	var resp struct {
+		Zbase string `json:"zbase"`
+	}
+	err := b.rpc.call(ctx, "signmessage", map[string]interface{}{"message": message}, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.Zbase, nil
+}
+
+// VerifyMessage verifies the signature with checkmessage, which fails if
+// the pubkey recovered is not a node of the graph.
+func (b *Backend) VerifyMessage(ctx context.Context, message, signature string) (*models.VerifiedMessage, error) {
+	b.logger.Debug("Verify message...")
+	var resp struct {
+		Verified bool   `json:"verified"`
+		PubKey   string `json:"pubkey"`
+	}
+	err := b.rpc.call(ctx, "checkmessage", map[string]interface{}{
+		"message": message,
+		"zbase":   signature,
+	}, &resp)
+	var failure *rpcError
+	if errors.As(err, &failure) {
+		return &models.VerifiedMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.VerifiedMessage{Valid: resp.Verified, PubKey: resp.PubKey}, nil
+}
+
+func (b *Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
+	b.logger.Debug("Send payment...",
+		logging.String("destination", payreq.Destination),
+		logging.Int64("amount", payreq.Amount),
+	)
+
+	var resp struct {
+		PaymentPreimage string `json:"payment_preimage"`
+		AmountMsat      msat   `json:"amount_msat"`
+		AmountSentMsat  msat   `json:"amount_sent_msat"`
+		Status          string `json:"status"`
+	}
+	err := b.rpc.call(ctx, "pay", map[string]interface{}{"bolt11": payreq.String}, &resp)
+	var failure *rpcError
+	if errors.As(err, &failure) {
+		return &models.Payment{
+			Hash:         payreq.PaymentHash,
+			Status:       models.PaymentFailed,
+			Amount:       payreq.Amount,
+			CreationDate: time.Now(),
+			PayReq:       payreq,
+			PaymentError: failure.Message,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	preimage, _ := hex.DecodeString(resp.PaymentPreimage)
+	return &models.Payment{
+		Hash:            payreq.PaymentHash,
+		Status:          payStatus(resp.Status),
+		Amount:          resp.AmountMsat.sat(),
+		Fee:             (resp.AmountSentMsat - resp.AmountMsat).sat(),
+		CreationDate:    time.Now(),
+		PayReq:          payreq,
+		PaymentPreimage: preimage,
+		Route: &models.Route{
+			Fee:    (resp.AmountSentMsat - resp.AmountMsat).sat(),
+			Amount: resp.AmountSentMsat.sat(),
+		},
+	}, nil
+}
+
+func (b *Backend) Rebalance(context.Context, *models.Channel, *models.Channel, int64, int64, chan *models.RebalanceAttempt) (*models.Payment, error) {
+	return nil, errNotSupported
+}
+
+// SendKeysend pays with the keysend command, the message is an extra TLV
+// record. lightningd does not send AMP payments.
+func (b *Backend) SendKeysend(ctx context.Context, k *models.Keysend, _ chan *models.Payment) (*models.Payment, error) {
+	if k.AMP {
+		return nil, errNotSupported
+	}
+	b.logger.Debug("Send keysend...",
+		logging.String("destination", k.Destination),
+		logging.Int64("amount", k.Amount),
+	)
+
+	params := map[string]interface{}{
+		"destination": k.Destination,
+		"amount_msat": k.Amount * 1000,
+	}
+	if k.Message != "" {
+		params["extratlvs"] = map[string]string{
+			clnMessageRecord: hex.EncodeToString([]byte(k.Message)),
+		}
+	}
+	var resp struct {
+		PaymentHash     string `json:"payment_hash"`
+		PaymentPreimage string `json:"payment_preimage"`
+		AmountMsat      msat   `json:"amount_msat"`
+		AmountSentMsat  msat   `json:"amount_sent_msat"`
+		Status          string `json:"status"`
+	}
+	err := b.rpc.call(ctx, "keysend", params, &resp)
+	var failure *rpcError
+	if errors.As(err, &failure) {
+		return &models.Payment{
+			Status:       models.PaymentFailed,
+			Amount:       k.Amount,
+			CreationDate: time.Now(),
+			PaymentError: failure.Message,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	preimage, _ := hex.DecodeString(resp.PaymentPreimage)
+	fee := (resp.AmountSentMsat - resp.AmountMsat).sat()
+	return &models.Payment{
+		Hash:            resp.PaymentHash,
+		Status:          payStatus(resp.Status),
+		Amount:          resp.AmountMsat.sat(),
+		Fee:             fee,
+		CreationDate:    time.Now(),
+		PaymentPreimage: preimage,
+		Route:           &models.Route{Fee: fee, Amount: resp.AmountSentMsat.sat()},
+	}, nil
+}
+
+// ListPayments returns the payments of listpays, lightningd keeps
+// neither their route nor their failure reason.
+func (b *Backend) ListPayments(ctx context.Context) ([]*models.Payment, error) {
+	b.logger.Debug("List payments")
+
+	var resp struct {
+		Pays []pay `json:"pays"`
+	}
+	err := b.rpc.call(ctx, "listpays", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]*models.Payment, len(resp.Pays))
+	for i := range resp.Pays {
+		payments[i] = resp.Pays[i].toPayment()
+	}
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].CreationDate.Before(payments[j].CreationDate)
+	})
+	return payments, nil
+}
+
+func (b *Backend) GetTransactions(ctx context.Context) ([]*models.Transaction, error) {
+	b.logger.Debug("Get transactions...")
+
+	info, err := b.getInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Transactions []transaction `json:"transactions"`
+	}
+	err = b.rpc.call(ctx, "listtransactions", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*models.Transaction, len(resp.Transactions))
+	for i := range resp.Transactions {
+		txs[i] = resp.Transactions[i].toTransaction(info.BlockHeight)
+	}
+	return txs, nil
+}
+
+func (b *Backend) GetForwardingHistory(ctx context.Context, startTime string, maxNumEvents uint32) ([]*models.ForwardingEvent, error) {
+	b.logger.Debug("GetForwardingHistory")
+
+	t, err := backend.ParseTime(startTime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Forwards []forward `json:"forwards"`
+	}
+	err = b.rpc.call(ctx, "listforwards", map[string]interface{}{"status": "settled"}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*models.ForwardingEvent{}
+	for i := range resp.Forwards {
+		e := resp.Forwards[i].toForwardingEvent()
+		if e.EventTime.Unix() < int64(t) {
+			continue
+		}
+		events = append(events, e)
+	}
+	if maxNumEvents > 0 && len(events) > int(maxNumEvents) {
+		events = events[len(events)-int(maxNumEvents):]
+	}
+	return events, nil
+}
+
+func (b *Backend) ListPeers(ctx context.Context) ([]*models.Peer, error) {
+	b.logger.Debug("List peers")
+
+	var resp struct {
+		Peers []peer `json:"peers"`
+	}
+	err := b.rpc.call(ctx, "listpeers", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	peers := []*models.Peer{}
+	for i := range resp.Peers {
+		if resp.Peers[i].Connected {
+			peers = append(peers, resp.Peers[i].toPeer())
+		}
+	}
+	return peers, nil
+}
+
+func (b *Backend) ConnectPeer(ctx context.Context, pubkey, host string) error {
+	b.logger.Debug("Connect peer", logging.String("pubkey", pubkey), logging.String("host", host))
+
+	return b.rpc.call(ctx, "connect", map[string]interface{}{"id": pubkey + "@" + host}, nil)
+}
+
+// DisconnectPeer fails if the peer has an active channel, lightningd
+// only disconnects it by force.
+func (b *Backend) DisconnectPeer(ctx context.Context, pubkey string) error {
+	b.logger.Debug("Disconnect peer", logging.String("pubkey", pubkey))
+
+	return b.rpc.call(ctx, "disconnect", map[string]interface{}{"id": pubkey}, nil)
+}
+
+// ClosedChannels needs lightningd 23.05 or later.
+func (b *Backend) ClosedChannels(ctx context.Context) ([]*models.ClosedChannel, error) {
+	b.logger.Debug("List closed channels")
+
+	var resp struct {
+		ClosedChannels []closedChannel `json:"closedchannels"`
+	}
+	err := b.rpc.call(ctx, "listclosedchannels", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]*models.ClosedChannel, len(resp.ClosedChannels))
+	for i := range resp.ClosedChannels {
+		channels[i] = resp.ClosedChannels[i].toClosedChannel()
+	}
+	return channels, nil
+}
+
+// PendingSweeps returns no sweep, lightningd has no sweeper.
+func (b *Backend) PendingSweeps(context.Context) ([]*models.PendingSweep, error) {
+	return []*models.PendingSweep{}, nil
+}
+
+func (b *Backend) BumpFee(context.Context, string, uint64) error {
+	return errNotSupported
+}
+
+// Watchtower is not supported, the watchtower client of lightningd is a
+// plugin.
+func (b *Backend) Watchtower(context.Context) (*models.Watchtower, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) AddTower(context.Context, string, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) RemoveTower(context.Context, string) error {
+	return errNotSupported
+}
+
+// MissionControl is not supported, the pay plugin of lightningd keeps no
+// history of the payment attempts between the payments.
+func (b *Backend) MissionControl(context.Context) ([]*models.MissionPair, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) PairProbability(context.Context, string, string, int64) (float64, error) {
+	return 0, errNotSupported
+}
+
+func (b *Backend) ResetMissionControl(context.Context) error {
+	return errNotSupported
+}
+
+func (b *Backend) ImportMissionControl(context.Context, []*models.MissionPair, bool) error {
+	return errNotSupported
+}
+
+func (b *Backend) ListUnspent(ctx context.Context) ([]*models.UTXO, error) {
+	b.logger.Debug("List unspent")
+
+	info, err := b.getInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	funds, err := b.listFunds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	utxos := []*models.UTXO{}
+	for i := range funds.Outputs {
+		o := &funds.Outputs[i]
+		if o.Status == "spent" {
+			continue
+		}
+		utxos = append(utxos, o.toUTXO(info.BlockHeight))
+	}
+	return utxos, nil
+}
+
+// UpdateChannelPolicy sets the fees and the htlc limits of the channel,
+// the cltv delta of lightningd is the same for every channel and is not
+// changed.
+func (b *Backend) UpdateChannelPolicy(ctx context.Context, channel *models.Channel, policy *models.RoutingPolicy) error {
+	b.logger.Debug("Update channel policy", logging.String("channel_point", channel.ChannelPoint))
+
+	if channel.ID == 0 {
+		return errors.New("channel without short channel id")
+	}
+	return b.rpc.call(ctx, "setchannel", map[string]interface{}{
+		"id":      models.ToScid(channel.ID),
+		"feebase": policy.FeeBaseMsat,
+		"feeppm":  policy.FeeRateMilliMsat,
+		"htlcmin": policy.MinHtlc,
+		"htlcmax": policy.MaxHtlc,
+	}, nil)
+}
+
+// CloseChannel negotiates the close with the peer, a forced close
+// publishes the commitment without waiting for it.
+func (b *Backend) CloseChannel(ctx context.Context, channel *models.Channel, force bool, satPerVbyte uint64) (string, error) {
+	b.logger.Debug("Close channel", logging.String("channel_point", channel.ChannelPoint),
+		logging.Bool("force", force))
+
+	if channel.ID == 0 {
+		return "", errors.New("channel without short channel id")
+	}
+	params := map[string]interface{}{"id": models.ToScid(channel.ID)}
+	if force {
+		params["unilateraltimeout"] = 1
+	} else if satPerVbyte > 0 {
+		params["feerange"] = []string{feerate(satPerVbyte), feerate(satPerVbyte)}
+	}
+	var resp struct {
+		TxID string `json:"txid"`
+	}
+	err := b.rpc.call(ctx, "close", params, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.TxID, nil
+}
+
+func (b *Backend) LabelTransaction(context.Context, string, string) error {
+	return errNotSupported
+}
+
+// Consolidate withdraws all the outputs to a new address of the wallet.
+func (b *Backend) Consolidate(ctx context.Context, utxos []*models.UTXO, satPerVbyte uint64) (*models.Consolidation, error) {
+	b.logger.Debug("Consolidate", logging.Int("inputs", len(utxos)))
+
+	fee, amount, err := models.ConsolidationFee(utxos, satPerVbyte)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := b.NewAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	outpoints := make([]string, len(utxos))
+	for i := range utxos {
+		outpoints[i] = utxos[i].Outpoint
+	}
+	txid, err := b.withdraw(ctx, addr, "all", satPerVbyte, outpoints)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Consolidation{
+		TxID:    txid,
+		Address: addr,
+		Inputs:  len(utxos),
+		Amount:  amount,
+		Fee:     fee,
+	}, nil
+}
+
+func (b *Backend) SendOnChain(ctx context.Context, address string, amount int64, satPerVbyte uint64, outpoints []string) (string, error) {
+	b.logger.Debug("Send on chain", logging.Int64("amount", amount),
+		logging.Int("inputs", len(outpoints)))
+	return b.withdraw(ctx, address, amount, satPerVbyte, outpoints)
+}
+
+func (b *Backend) withdraw(ctx context.Context, address string, amount interface{}, satPerVbyte uint64, outpoints []string) (string, error) {
+	params := map[string]interface{}{"destination": address, "satoshi": amount}
+	if satPerVbyte > 0 {
+		params["feerate"] = feerate(satPerVbyte)
+	}
+	if len(outpoints) > 0 {
+		params["utxos"] = outpoints
+	}
+	var resp struct {
+		TxID string `json:"txid"`
+	}
+	err := b.rpc.call(ctx, "withdraw", params, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.TxID, nil
+}
+
+// OpenChannel opens a channel with the connected peer funded by the
+// given outputs of the wallet, or by outputs selected by the wallet if
+// there are none, and returns the channel point.
+func (b *Backend) OpenChannel(ctx context.Context, pubkey string, amount int64, satPerVbyte uint64,
+	private bool, outpoints []string) (string, error) {
+	b.logger.Debug("Open channel", logging.Int64("amount", amount),
+		logging.Int("inputs", len(outpoints)))
+
+	params := map[string]interface{}{"id": pubkey, "amount": amount, "announce": !private}
+	if satPerVbyte > 0 {
+		params["feerate"] = feerate(satPerVbyte)
+	}
+	if len(outpoints) > 0 {
+		params["utxos"] = outpoints
+	}
+	var resp struct {
+		TxID   string `json:"txid"`
+		Outnum uint32 `json:"outnum"`
+	}
+	err := b.rpc.call(ctx, "fundchannel", params, &resp)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d", resp.TxID, resp.Outnum), nil
+}
+
+func (b *Backend) EstimateBatchOpen(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (*models.FeeEstimate, error) {
+	return nil, errNotSupported
+}
+
+// EstimateFeeRate returns the rate of the last feerates estimate within the
+// target, the fastest one if none is.
+func (b *Backend) EstimateFeeRate(ctx context.Context, target uint32) (uint64, error) {
+	var rates feerates
+	err := b.rpc.call(ctx, "feerates", map[string]interface{}{"style": "perkb"}, &rates)
+	if err != nil {
+		return 0, err
+	}
+	if len(rates.Perkb.Estimates) == 0 {
+		return 0, errors.New("no fee estimate")
+	}
+	// the estimates are by increasing block count.
+	estimate := rates.Perkb.Estimates[0]
+	for _, e := range rates.Perkb.Estimates[1:] {
+		if e.BlockCount <= target {
+			estimate = e
+		}
+	}
+	return max(estimate.FeeRate/1000, 1), nil
+}
+
+// BatchOpenChannel opens the channels with a single funding transaction
+// with multifundchannel and returns its txid.
+func (b *Backend) BatchOpenChannel(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (string, error) {
+	b.logger.Debug("Batch open channels", logging.Int("channels", len(channels)))
+
+	destinations := make([]map[string]interface{}, len(channels))
+	for i, ch := range channels {
+		destinations[i] = map[string]interface{}{
+			"id": ch.PubKey, "amount": ch.Amount, "announce": !ch.Private,
+		}
+	}
+	params := map[string]interface{}{"destinations": destinations}
+	if satPerVbyte > 0 {
+		params["feerate"] = feerate(satPerVbyte)
+	}
+	var resp struct {
+		TxID string `json:"txid"`
+	}
+	err := b.rpc.call(ctx, "multifundchannel", params, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.TxID, nil
+}
+
+// feerate returns the fee rate in sat/vbyte in the unit of lightningd.
+func feerate(satPerVbyte uint64) string {
+	return fmt.Sprintf("%dperkb", satPerVbyte*1000)
+}
+
+func New(c *config.Network, logger logging.Logger) (*Backend, error) {
+	if c.Address == "" {
+		return nil, errors.New("cln: address of the lightning-rpc socket is missing")
+	}
+	return &Backend{
+		cfg:    c,
+		logger: logger,
+		rpc: &rpc{
+			socket:  c.Address,
+			timeout: clnDialTimeout,
+		},
+	}, nil
+}
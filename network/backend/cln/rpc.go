@@ -0,0 +1,114 @@
+package cln
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("cln: %s (%d)", e.Message, e.Code)
+}
+
+// rpc calls the JSON-RPC interface of lightningd on its unix socket, a
+// connection is opened for each call.
+type rpc struct {
+	socket  string
+	timeout time.Duration
+	id      atomic.Uint64
+}
+
+// call sends the method with the params, an object or nil, and decodes
+// the result in out unless nil. The call is canceled with the context, it
+// has no timeout unless the context has a deadline.
+func (r *rpc) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	d := net.Dialer{Timeout: r.timeout}
+	conn, err := d.DialContext(ctx, "unix", r.socket)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// unblocks the read of a waiting call.
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	req := request{JSONRPC: "2.0", ID: r.id.Add(1), Method: method, Params: params}
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var resp response
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.WithStack(err)
+	}
+	if resp.Error != nil {
+		return errors.WithStack(resp.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.WithStack(json.Unmarshal(resp.Result, out))
+}
+
+// msat is an amount in millisatoshis, a number or a string like "1000msat"
+// depending on the version of lightningd.
+type msat uint64
+
+func (m *msat) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSuffix(strings.Trim(string(data), `"`), "msat")
+	if s == "" || s == "null" || s == "any" {
+		*m = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return errors.Errorf("invalid msat amount %s", data)
+	}
+	*m = msat(v)
+	return nil
+}
+
+func (m msat) sat() int64 {
+	return int64(m / 1000)
+}
@@ -0,0 +1,654 @@
+package cln
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type address struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+func (a address) String() string {
+	return fmt.Sprintf("%s:%d", a.Address, a.Port)
+}
+
+type getInfo struct {
+	ID                  string    `json:"id"`
+	Alias               string    `json:"alias"`
+	NumPeers            uint32    `json:"num_peers"`
+	NumPendingChannels  uint32    `json:"num_pending_channels"`
+	NumActiveChannels   uint32    `json:"num_active_channels"`
+	NumInactiveChannels uint32    `json:"num_inactive_channels"`
+	BlockHeight         uint32    `json:"blockheight"`
+	Version             string    `json:"version"`
+	Network             string    `json:"network"`
+	WarningBitcoindSync string    `json:"warning_bitcoind_sync"`
+	WarningLightningd   string    `json:"warning_lightningd_sync"`
+	Address             []address `json:"address"`
+}
+
+func (i *getInfo) toInfo() *models.Info {
+	network := i.Network
+	if network == "bitcoin" {
+		network = "mainnet"
+	}
+	return &models.Info{
+		PubKey:              i.ID,
+		Alias:               i.Alias,
+		NumPendingChannels:  i.NumPendingChannels,
+		NumActiveChannels:   i.NumActiveChannels,
+		NumInactiveChannels: i.NumInactiveChannels,
+		NumPeers:            i.NumPeers,
+		BlockHeight:         i.BlockHeight,
+		Synced:              i.WarningBitcoindSync == "" && i.WarningLightningd == "",
+		Version:             i.Version,
+		Implementation:      "cln",
+		Chains:              []string{"bitcoin"},
+		Testnet:             network == "testnet",
+		Network:             network,
+	}
+}
+
+type policy struct {
+	FeeBaseMsat     msat   `json:"fee_base_msat"`
+	FeeProportional int64  `json:"fee_proportional_millionths"`
+	CltvExpiryDelta uint32 `json:"cltv_expiry_delta"`
+	HtlcMinimumMsat msat   `json:"htlc_minimum_msat"`
+	HtlcMaximumMsat msat   `json:"htlc_maximum_msat"`
+}
+
+func (p *policy) toRoutingPolicy() *models.RoutingPolicy {
+	return &models.RoutingPolicy{
+		TimeLockDelta:    p.CltvExpiryDelta,
+		MinHtlc:          int64(p.HtlcMinimumMsat),
+		MaxHtlc:          uint64(p.HtlcMaximumMsat),
+		FeeBaseMsat:      int64(p.FeeBaseMsat),
+		FeeRateMilliMsat: p.FeeProportional,
+	}
+}
+
+type htlc struct {
+	Direction   string `json:"direction"`
+	AmountMsat  msat   `json:"amount_msat"`
+	Expiry      uint32 `json:"expiry"`
+	PaymentHash string `json:"payment_hash"`
+}
+
+type peerChannel struct {
+	PeerID           string `json:"peer_id"`
+	PeerConnected    bool   `json:"peer_connected"`
+	State            string `json:"state"`
+	ShortChannelID   string `json:"short_channel_id"`
+	FundingTxID      string `json:"funding_txid"`
+	FundingOutnum    uint32 `json:"funding_outnum"`
+	Private          bool   `json:"private"`
+	Opener           string `json:"opener"`
+	TotalMsat        msat   `json:"total_msat"`
+	ToUsMsat         msat   `json:"to_us_msat"`
+	InFulfilledMsat  msat   `json:"in_fulfilled_msat"`
+	OutFulfilledMsat msat   `json:"out_fulfilled_msat"`
+	LastTxFeeMsat    msat   `json:"last_tx_fee_msat"`
+	TheirToSelfDelay uint32 `json:"their_to_self_delay"`
+	Feerate          struct {
+		PerKw int64 `json:"perkw"`
+	} `json:"feerate"`
+	ChannelType struct {
+		Names []string `json:"names"`
+	} `json:"channel_type"`
+	Updates struct {
+		Local  *policy `json:"local"`
+		Remote *policy `json:"remote"`
+	} `json:"updates"`
+	Htlcs []htlc `json:"htlcs"`
+}
+
+// status returns the status of the channel of its state, zero for the
+// closed channels.
+func (c *peerChannel) status() int {
+	switch c.State {
+	case "CHANNELD_NORMAL":
+		if c.PeerConnected {
+			return models.ChannelActive
+		}
+		return models.ChannelInactive
+	case "OPENINGD", "CHANNELD_AWAITING_LOCKIN", "DUALOPEND_OPEN_INIT",
+		"DUALOPEND_OPEN_COMMITTED", "DUALOPEND_AWAITING_LOCKIN", "CHANNELD_AWAITING_SPLICE":
+		return models.ChannelOpening
+	case "CHANNELD_SHUTTING_DOWN", "CLOSINGD_SIGEXCHANGE":
+		return models.ChannelClosing
+	case "CLOSINGD_COMPLETE":
+		return models.ChannelWaitingClose
+	case "AWAITING_UNILATERAL", "FUNDING_SPEND_SEEN", "ONCHAIN":
+		return models.ChannelForceClosing
+	}
+	return 0
+}
+
+func (c *peerChannel) toChannel(height uint32) *models.Channel {
+	ch := &models.Channel{
+		ID:               scidToID(c.ShortChannelID),
+		Status:           c.status(),
+		RemotePubKey:     c.PeerID,
+		ChannelPoint:     fmt.Sprintf("%s:%d", c.FundingTxID, c.FundingOutnum),
+		Capacity:         c.TotalMsat.sat(),
+		LocalBalance:     c.ToUsMsat.sat(),
+		RemoteBalance:    (c.TotalMsat - c.ToUsMsat).sat(),
+		CommitFee:        c.LastTxFeeMsat.sat(),
+		FeePerKiloWeight: c.Feerate.PerKw,
+		// the amounts fulfilled are the ones routed or paid through
+		// the channel.
+		TotalAmountSent:     c.OutFulfilledMsat.sat(),
+		TotalAmountReceived: c.InFulfilledMsat.sat(),
+		CSVDelay:            c.TheirToSelfDelay,
+		Private:             c.Private,
+		PendingHTLC:         make([]*models.HTLC, len(c.Htlcs)),
+	}
+	ch.Age = ch.AgeAt(height)
+	for i, h := range c.Htlcs {
+		hash, _ := hex.DecodeString(h.PaymentHash)
+		ch.PendingHTLC[i] = &models.HTLC{
+			Incoming:         h.Direction == "in" || h.Direction == "incoming",
+			Amount:           h.AmountMsat.sat(),
+			Hashlock:         hash,
+			ExpirationHeight: h.Expiry,
+		}
+		ch.UnsettledBalance += h.AmountMsat.sat()
+	}
+	for _, name := range c.ChannelType.Names {
+		if strings.HasPrefix(name, "anchors") {
+			ch.Anchors = true
+		}
+	}
+	if c.Updates.Local != nil {
+		ch.LocalPolicy = c.Updates.Local.toRoutingPolicy()
+	}
+	if c.Updates.Remote != nil {
+		ch.RemotePolicy = c.Updates.Remote.toRoutingPolicy()
+	}
+	return ch
+}
+
+// gossipChannel is a direction of a channel of the gossip.
+type gossipChannel struct {
+	Source          string `json:"source"`
+	Destination     string `json:"destination"`
+	ShortChannelID  string `json:"short_channel_id"`
+	AmountMsat      msat   `json:"amount_msat"`
+	Active          bool   `json:"active"`
+	LastUpdate      int64  `json:"last_update"`
+	BaseFeeMsat     int64  `json:"base_fee_millisatoshi"`
+	FeePerMillionth int64  `json:"fee_per_millionth"`
+	Delay           uint32 `json:"delay"`
+	HtlcMinimumMsat msat   `json:"htlc_minimum_msat"`
+	HtlcMaximumMsat msat   `json:"htlc_maximum_msat"`
+}
+
+func (c *gossipChannel) toRoutingPolicy() *models.RoutingPolicy {
+	policy := &models.RoutingPolicy{
+		TimeLockDelta:    c.Delay,
+		MinHtlc:          int64(c.HtlcMinimumMsat),
+		MaxHtlc:          uint64(c.HtlcMaximumMsat),
+		FeeBaseMsat:      c.BaseFeeMsat,
+		FeeRateMilliMsat: c.FeePerMillionth,
+		Disabled:         !c.Active,
+	}
+	if c.LastUpdate > 0 {
+		policy.LastUpdate = time.Unix(c.LastUpdate, 0)
+	}
+	return policy
+}
+
+type node struct {
+	NodeID        string    `json:"nodeid"`
+	Alias         string    `json:"alias"`
+	LastTimestamp int64     `json:"last_timestamp"`
+	Addresses     []address `json:"addresses"`
+	// Features is the hex of the feature bits, the last byte has the
+	// bits 0 to 7.
+	Features string `json:"features"`
+}
+
+func (n *node) toNode() *models.Node {
+	result := &models.Node{
+		PubKey:     n.NodeID,
+		Alias:      n.Alias,
+		LastUpdate: time.Unix(n.LastTimestamp, 0),
+		Addresses:  make([]*models.NodeAddress, len(n.Addresses)),
+	}
+	for i, a := range n.Addresses {
+		result.Addresses[i] = &models.NodeAddress{Network: "tcp", Addr: a.String()}
+	}
+	if b, err := hex.DecodeString(n.Features); err == nil {
+		for i := len(b) - 1; i >= 0; i-- {
+			for j := 0; j < 8; j++ {
+				if b[i]&(1<<j) != 0 {
+					bit := uint32(8*(len(b)-1-i) + j)
+					result.Features = append(result.Features, models.NewNodeFeature(bit))
+				}
+			}
+		}
+	}
+	return result
+}
+
+type output struct {
+	TxID        string `json:"txid"`
+	Output      uint32 `json:"output"`
+	AmountMsat  msat   `json:"amount_msat"`
+	Address     string `json:"address"`
+	Status      string `json:"status"`
+	BlockHeight uint32 `json:"blockheight"`
+	Reserved    bool   `json:"reserved"`
+	// ReservedToBlock is the height at which the reservation of the
+	// output expires.
+	ReservedToBlock uint32 `json:"reserved_to_block"`
+}
+
+func (o *output) toUTXO(height uint32) *models.UTXO {
+	u := &models.UTXO{
+		Outpoint:    fmt.Sprintf("%s:%d", o.TxID, o.Output),
+		Address:     o.Address,
+		AddressType: models.AddressP2WKH,
+		Amount:      o.AmountMsat.sat(),
+	}
+	if strings.HasPrefix(o.Address, "bc1p") || strings.HasPrefix(o.Address, "tb1p") || strings.HasPrefix(o.Address, "bcrt1p") {
+		u.AddressType = models.AddressP2TR
+	}
+	if o.Status == "confirmed" && o.BlockHeight > 0 && height >= o.BlockHeight {
+		u.Confirmations = int64(height - o.BlockHeight + 1)
+	}
+	// the reservation is a lease until a block, its expiration is
+	// estimated with blocks of ten minutes.
+	if o.Reserved && o.ReservedToBlock > height {
+		u.LeaseExpiration = time.Now().Add(time.Duration(o.ReservedToBlock-height) * 10 * time.Minute)
+	}
+	return u
+}
+
+type fundsChannel struct {
+	OurAmountMsat msat   `json:"our_amount_msat"`
+	State         string `json:"state"`
+}
+
+type listFunds struct {
+	Outputs  []output       `json:"outputs"`
+	Channels []fundsChannel `json:"channels"`
+}
+
+type invoice struct {
+	Label              string `json:"label"`
+	Bolt11             string `json:"bolt11"`
+	PaymentHash        string `json:"payment_hash"`
+	PaymentPreimage    string `json:"payment_preimage"`
+	AmountMsat         msat   `json:"amount_msat"`
+	AmountReceivedMsat msat   `json:"amount_received_msat"`
+	Status             string `json:"status"`
+	Description        string `json:"description"`
+	PayIndex           uint64 `json:"pay_index"`
+	PaidAt             int64  `json:"paid_at"`
+	ExpiresAt          int64  `json:"expires_at"`
+	// LocalOfferID is the offer of the invoice created for an invoice
+	// request.
+	LocalOfferID    string `json:"local_offer_id"`
+	InvreqPayerNote string `json:"invreq_payer_note"`
+}
+
+// toInvoice returns the invoice, lightningd reports its expiry date but
+// not its creation date, read from the payment request.
+func (i *invoice) toInvoice() *models.Invoice {
+	hash, _ := hex.DecodeString(i.PaymentHash)
+	preimage, _ := hex.DecodeString(i.PaymentPreimage)
+	created := bolt11Timestamp(i.Bolt11)
+	expiry := int64(0)
+	if created > 0 {
+		expiry = i.ExpiresAt - created
+	}
+	state := models.InvoiceOpen
+	if i.Status == "paid" {
+		state = models.InvoiceSettled
+	}
+	return &models.Invoice{
+		Index:            i.PayIndex,
+		Amount:           i.AmountMsat.sat(),
+		AmountPaid:       i.AmountReceivedMsat.sat(),
+		AmountPaidInMSat: int64(i.AmountReceivedMsat),
+		Description:      i.Description,
+		RPreImage:        preimage,
+		RHash:            hash,
+		PaymentRequest:   i.Bolt11,
+		Settled:          i.Status == "paid",
+		State:            state,
+		CreationDate:     created,
+		SettleDate:       i.PaidAt,
+		Expiry:           expiry,
+	}
+}
+
+func (i *invoice) toOfferRequest() *models.OfferRequest {
+	r := &models.OfferRequest{
+		PaymentHash: i.PaymentHash,
+		Amount:      i.AmountMsat.sat(),
+		AmountPaid:  i.AmountReceivedMsat.sat(),
+		Paid:        i.Status == "paid",
+		ExpiresAt:   time.Unix(i.ExpiresAt, 0),
+		PayerNote:   i.InvreqPayerNote,
+	}
+	if i.PaidAt > 0 {
+		r.PaidAt = time.Unix(i.PaidAt, 0)
+	}
+	return r
+}
+
+type offer struct {
+	OfferID   string `json:"offer_id"`
+	Active    bool   `json:"active"`
+	SingleUse bool   `json:"single_use"`
+	Bolt12    string `json:"bolt12"`
+	Used      bool   `json:"used"`
+	Label     string `json:"label"`
+}
+
+// toOffer returns the offer, lightningd does not list its description and
+// amount, set from its decoding.
+func (o *offer) toOffer() *models.Offer {
+	status := models.OfferActive
+	switch {
+	case o.SingleUse && o.Used:
+		status = models.OfferUsed
+	case !o.Active:
+		status = models.OfferDisabled
+	}
+	return &models.Offer{
+		ID:        o.OfferID,
+		Bolt12:    o.Bolt12,
+		Status:    status,
+		SingleUse: o.SingleUse,
+		Label:     o.Label,
+	}
+}
+
+type decodedOffer struct {
+	Description string `json:"offer_description"`
+	AmountMsat  msat   `json:"offer_amount_msat"`
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bolt11Timestamp returns the creation date of the payment request, the
+// first 35 bits of its data, zero if it cannot be read.
+func bolt11Timestamp(bolt11 string) int64 {
+	s := strings.ToLower(bolt11)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 0 || len(s) < sep+8 {
+		return 0
+	}
+	var ts int64
+	for _, c := range s[sep+1 : sep+8] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return 0
+		}
+		ts = ts<<5 | int64(v)
+	}
+	return ts
+}
+
+type routeHop struct {
+	PubKey          string `json:"pubkey"`
+	ShortChannelID  string `json:"short_channel_id"`
+	FeeBaseMsat     msat   `json:"fee_base_msat"`
+	FeeProportional uint32 `json:"fee_proportional_millionths"`
+	CltvExpiryDelta uint32 `json:"cltv_expiry_delta"`
+}
+
+type decodedInvoice struct {
+	Payee           string `json:"payee"`
+	PaymentHash     string `json:"payment_hash"`
+	AmountMsat      msat   `json:"amount_msat"`
+	CreatedAt       int64  `json:"created_at"`
+	Expiry          int64  `json:"expiry"`
+	Description     string `json:"description"`
+	DescriptionHash string `json:"description_hash"`
+	MinFinalCltv    int64  `json:"min_final_cltv_expiry"`
+	PaymentSecret   string `json:"payment_secret"`
+	Fallbacks       []struct {
+		Addr string `json:"addr"`
+	} `json:"fallbacks"`
+	Routes [][]routeHop `json:"routes"`
+}
+
+func (d *decodedInvoice) toPayReq(s string) *models.PayReq {
+	secret, _ := hex.DecodeString(d.PaymentSecret)
+	p := &models.PayReq{
+		Destination:     d.Payee,
+		PaymentHash:     d.PaymentHash,
+		Amount:          d.AmountMsat.sat(),
+		AmountMsat:      int64(d.AmountMsat),
+		Timestamp:       d.CreatedAt,
+		Expiry:          d.Expiry,
+		Description:     d.Description,
+		DescriptionHash: d.DescriptionHash,
+		CltvExpiry:      d.MinFinalCltv,
+		PaymentAddr:     secret,
+		String:          s,
+	}
+	if len(d.Fallbacks) > 0 {
+		p.FallbackAddr = d.Fallbacks[0].Addr
+	}
+	for _, route := range d.Routes {
+		hint := &models.RouteHint{}
+		for _, hop := range route {
+			hint.Hops = append(hint.Hops, &models.HopHint{
+				NodeID:                    hop.PubKey,
+				ChanID:                    scidToID(hop.ShortChannelID),
+				FeeBaseMsat:               uint32(hop.FeeBaseMsat),
+				FeeProportionalMillionths: hop.FeeProportional,
+				CltvExpiryDelta:           hop.CltvExpiryDelta,
+			})
+		}
+		p.RouteHints = append(p.RouteHints, hint)
+	}
+	return p
+}
+
+type pay struct {
+	Bolt11         string `json:"bolt11"`
+	Destination    string `json:"destination"`
+	PaymentHash    string `json:"payment_hash"`
+	Status         string `json:"status"`
+	CreatedAt      int64  `json:"created_at"`
+	AmountMsat     msat   `json:"amount_msat"`
+	AmountSentMsat msat   `json:"amount_sent_msat"`
+	Preimage       string `json:"preimage"`
+}
+
+func (p *pay) toPayment() *models.Payment {
+	payment := &models.Payment{
+		Hash:         p.PaymentHash,
+		Status:       payStatus(p.Status),
+		Amount:       p.AmountMsat.sat(),
+		CreationDate: time.Unix(p.CreatedAt, 0),
+		PayReq:       &models.PayReq{Destination: p.Destination, String: p.Bolt11},
+	}
+	if payment.Status == models.PaymentSucceeded {
+		payment.Fee = (p.AmountSentMsat - p.AmountMsat).sat()
+		payment.PaymentPreimage, _ = hex.DecodeString(p.Preimage)
+	}
+	return payment
+}
+
+// sendpay is a part of a payment.
+type sendpay struct {
+	PaymentHash string `json:"payment_hash"`
+	Status      string `json:"status"`
+}
+
+func payStatus(s string) int {
+	switch s {
+	case "complete":
+		return models.PaymentSucceeded
+	case "failed":
+		return models.PaymentFailed
+	}
+	return models.PaymentInFlight
+}
+
+type forward struct {
+	InChannel    string  `json:"in_channel"`
+	OutChannel   string  `json:"out_channel"`
+	InHtlcID     uint64  `json:"in_htlc_id"`
+	OutHtlcID    uint64  `json:"out_htlc_id"`
+	InMsat       msat    `json:"in_msat"`
+	OutMsat      msat    `json:"out_msat"`
+	FeeMsat      msat    `json:"fee_msat"`
+	Status       string  `json:"status"`
+	ReceivedTime float64 `json:"received_time"`
+	ResolvedTime float64 `json:"resolved_time"`
+}
+
+func (f *forward) toForwardingEvent() *models.ForwardingEvent {
+	return &models.ForwardingEvent{
+		ChanIdIn:   scidToID(f.InChannel),
+		ChanIdOut:  scidToID(f.OutChannel),
+		AmtIn:      uint64(f.InMsat.sat()),
+		AmtOut:     uint64(f.OutMsat.sat()),
+		Fee:        uint64(f.FeeMsat.sat()),
+		FeeMsat:    uint64(f.FeeMsat),
+		AmtInMsat:  uint64(f.InMsat),
+		AmtOutMsat: uint64(f.OutMsat),
+		EventTime:  unixFloat(f.ResolvedTime),
+	}
+}
+
+func (f *forward) toRoutingEvent() *models.RoutingEvent {
+	e := &models.RoutingEvent{
+		IncomingChannelId: scidToID(f.InChannel),
+		OutgoingChannelId: scidToID(f.OutChannel),
+		IncomingHtlcId:    f.InHtlcID,
+		OutgoingHtlcId:    f.OutHtlcID,
+		LastUpdate:        time.Now(),
+		Direction:         models.RoutingForward,
+		AmountMsat:        uint64(f.OutMsat),
+		FeeMsat:           uint64(f.FeeMsat),
+	}
+	switch f.Status {
+	case "offered":
+		e.Status = models.RoutingStatusActive
+	case "settled":
+		e.Status = models.RoutingStatusSettled
+	case "failed":
+		e.Status = models.RoutingStatusFailed
+	case "local_failed":
+		e.Status = models.RoutingStatusLinkFailed
+	}
+	return e
+}
+
+type peer struct {
+	ID        string   `json:"id"`
+	Connected bool     `json:"connected"`
+	Netaddr   []string `json:"netaddr"`
+}
+
+func (p *peer) toPeer() *models.Peer {
+	result := &models.Peer{PubKey: p.ID}
+	if len(p.Netaddr) > 0 {
+		result.Address = p.Netaddr[0]
+	}
+	return result
+}
+
+type closedChannel struct {
+	PeerID         string `json:"peer_id"`
+	ShortChannelID string `json:"short_channel_id"`
+	FundingTxID    string `json:"funding_txid"`
+	FundingOutnum  uint32 `json:"funding_outnum"`
+	Opener         string `json:"opener"`
+	Closer         string `json:"closer"`
+	TotalMsat      msat   `json:"total_msat"`
+	FinalToUsMsat  msat   `json:"final_to_us_msat"`
+	CloseCause     string `json:"close_cause"`
+}
+
+func (c *closedChannel) toClosedChannel() *models.ClosedChannel {
+	return &models.ClosedChannel{
+		ID:             scidToID(c.ShortChannelID),
+		ChannelPoint:   fmt.Sprintf("%s:%d", c.FundingTxID, c.FundingOutnum),
+		RemotePubKey:   c.PeerID,
+		Capacity:       c.TotalMsat.sat(),
+		SettledBalance: c.FinalToUsMsat.sat(),
+		OpenInitiator:  initiator(c.Opener),
+		CloseInitiator: initiator(c.Closer),
+	}
+}
+
+func initiator(side string) int {
+	switch side {
+	case "local":
+		return models.InitiatorLocal
+	case "remote":
+		return models.InitiatorRemote
+	}
+	return 0
+}
+
+type transaction struct {
+	Hash        string `json:"hash"`
+	BlockHeight uint32 `json:"blockheight"`
+	Outputs     []struct {
+		AmountMsat msat `json:"amount_msat"`
+	} `json:"outputs"`
+}
+
+func (t *transaction) toTransaction(height uint32) *models.Transaction {
+	tx := &models.Transaction{
+		TxHash:      t.Hash,
+		BlockHeight: int32(t.BlockHeight),
+	}
+	if t.BlockHeight > 0 && height >= t.BlockHeight {
+		tx.NumConfirmations = int32(height - t.BlockHeight + 1)
+	}
+	return tx
+}
+
+// scidToID converts a short channel id BxTxO to the id of lnd, zero if it
+// is not one.
+func scidToID(scid string) uint64 {
+	parts := strings.Split(scid, "x")
+	if len(parts) != 3 {
+		return 0
+	}
+	var v [3]uint64
+	for i := range parts {
+		n, err := strconv.ParseUint(parts[i], 10, 32)
+		if err != nil {
+			return 0
+		}
+		v[i] = n
+	}
+	return v[0]<<40 | v[1]<<16 | v[2]
+}
+
+func unixFloat(t float64) time.Time {
+	sec := int64(t)
+	return time.Unix(sec, int64((t-float64(sec))*1e9))
+}
+
+// feerates are the estimates of the fee rate in sat per 1000 vbytes by
+// number of blocks.
+type feerates struct {
+	Perkb struct {
+		Estimates []struct {
+			BlockCount uint32 `json:"blockcount"`
+			FeeRate    uint64 `json:"feerate"`
+		} `json:"estimates"`
+	} `json:"perkb"`
+}
@@ -0,0 +1,677 @@
+// Package demo implements a backend.Backend generating a plausible node,
+// so the ui can be evaluated, themed and captured without a running
+// node. The mock backend is seeded with channels, peers, forwards and
+// on-chain state drawn from a fixed seed, and forwards, invoices, pings
+// and blocks are generated while the routing events are subscribed: two
+// runs show the same node and the same sequence of events.
+package demo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/backend/mock"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+const (
+	// seed is the seed of the generator of all the demo data.
+	seed = 21000000
+
+	// tick is the interval between two steps of the generator.
+	tick = 2 * time.Second
+	// blockTicks is the number of ticks between two blocks.
+	blockTicks = 30
+	// fundingConfs is the number of confirmations of the funding
+	// transaction before an opening channel is active.
+	fundingConfs = 3
+	// fundingExpiry is the number of blocks before the funding of an
+	// unconfirmed channel expires.
+	fundingExpiry = 2016
+)
+
+var _ backend.Backend = (*Backend)(nil)
+
+type Backend struct {
+	*mock.Backend
+
+	rand     *rand.Rand
+	info     models.Info
+	wallet   models.WalletBalance
+	channels []*models.Channel
+	peers    map[string]*models.Peer
+	htlcID   uint64
+	ticks    int
+	// intercepting is true while the HTLCs are intercepted, the forwards
+	// are then sent to the interceptor.
+	intercepting bool
+	// funded are the heights of the blocks of the funding transactions
+	// of the opening channels, by channel point.
+	funded map[string]uint32
+
+	mu sync.Mutex
+}
+
+// SubscribeRoutingEvents generates the events of the node for the
+// lifetime of the subscription.
+func (b *Backend) SubscribeRoutingEvents(ctx context.Context, channel chan *models.RoutingEvent) error {
+	go b.run(ctx)
+	return b.Backend.SubscribeRoutingEvents(ctx, channel)
+}
+
+func (b *Backend) run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			b.step(ctx, t)
+		}
+	}
+}
+
+// step generates the events of a tick: mostly forwards, sometimes a
+// failed forward, a paid invoice, a flapping peer or a new block.
+func (b *Backend) step(ctx context.Context, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ticks++
+
+	switch n := b.rand.Intn(20); {
+	case n < 12:
+		if event := b.forward(now); event != nil {
+			if b.intercepting {
+				b.intercept(event, now)
+			}
+			b.AddForwardingEvent(event)
+			b.update(event.ChanIdIn, event.ChanIdOut)
+			b.PublishRoutingEvent(&models.RoutingEvent{
+				IncomingChannelId: event.ChanIdIn,
+				OutgoingChannelId: event.ChanIdOut,
+				IncomingHtlcId:    b.htlcID,
+				OutgoingHtlcId:    b.htlcID,
+				LastUpdate:        now,
+				Direction:         models.RoutingForward,
+				Status:            models.RoutingStatusSettled,
+				IncomingTimelock:  b.info.BlockHeight + 184,
+				OutgoingTimelock:  b.info.BlockHeight + 144,
+				AmountMsat:        event.AmtOutMsat,
+				FeeMsat:           event.FeeMsat,
+			})
+		}
+	case n < 15:
+		b.failedForward(now)
+	case n < 16:
+		b.invoice(ctx)
+	case n < 17:
+		b.flap(now)
+	default:
+		b.ping()
+	}
+
+	if b.ticks%blockTicks == 0 {
+		b.block()
+	}
+}
+
+// InterceptHTLCs intercepts the forwards generated while the routing
+// events are subscribed, they are settled whatever their resolution.
+func (b *Backend) InterceptHTLCs(ctx context.Context, htlcs chan *models.InterceptedHTLC,
+	resolutions chan *models.HTLCResolution) error {
+	b.mu.Lock()
+	b.intercepting = true
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.intercepting = false
+		b.mu.Unlock()
+	}()
+	return b.Backend.InterceptHTLCs(ctx, htlcs, resolutions)
+}
+
+// intercept sends the HTLC of the forward to the interceptor.
+func (b *Backend) intercept(event *models.ForwardingEvent, now time.Time) {
+	hash := sha256.Sum256([]byte(strconv.FormatUint(b.htlcID, 10)))
+	b.PublishInterceptedHTLC(&models.InterceptedHTLC{
+		IncomingChannelId:  event.ChanIdIn,
+		IncomingHtlcId:     b.htlcID,
+		OutgoingChannelId:  event.ChanIdOut,
+		PaymentHash:        hash[:],
+		IncomingAmountMsat: event.AmtInMsat,
+		OutgoingAmountMsat: event.AmtOutMsat,
+		IncomingExpiry:     b.info.BlockHeight + 184,
+		OutgoingExpiry:     b.info.BlockHeight + 144,
+		Intercepted:        now,
+	})
+}
+
+// active returns the indexes of the active channels.
+func (b *Backend) active() []int {
+	indexes := []int{}
+	for i := range b.channels {
+		if b.channels[i].Status == models.ChannelActive {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// forward moves an amount between two active channels and returns the
+// forwarding event, nil if no channel has the liquidity.
+func (b *Backend) forward(t time.Time) *models.ForwardingEvent {
+	active := b.active()
+	if len(active) < 2 {
+		return nil
+	}
+	in := b.channels[active[b.rand.Intn(len(active))]]
+	out := b.channels[active[b.rand.Intn(len(active))]]
+	if in == out || out.LocalPolicy == nil {
+		return nil
+	}
+
+	amount := int64(1000 + b.rand.Intn(500000))
+	fee := out.LocalPolicy.FeeBaseMsat + amount*out.LocalPolicy.FeeRateMilliMsat/1000
+	if out.LocalBalance < amount || in.RemoteBalance < amount+fee/1000 {
+		return nil
+	}
+	in.LocalBalance += amount + fee/1000
+	in.RemoteBalance -= amount + fee/1000
+	in.TotalAmountReceived += amount + fee/1000
+	in.UpdatesCount++
+	out.LocalBalance -= amount
+	out.RemoteBalance += amount
+	out.TotalAmountSent += amount
+	out.UpdatesCount++
+	b.htlcID++
+
+	return &models.ForwardingEvent{
+		PeerAliasIn:  in.Node.Alias,
+		PeerAliasOut: out.Node.Alias,
+		ChanIdIn:     in.ID,
+		ChanIdOut:    out.ID,
+		AmtIn:        uint64(amount + fee/1000),
+		AmtOut:       uint64(amount),
+		Fee:          uint64(fee / 1000),
+		FeeMsat:      uint64(fee),
+		AmtInMsat:    uint64(amount*1000 + fee),
+		AmtOutMsat:   uint64(amount * 1000),
+		EventTime:    t,
+	}
+}
+
+// update publishes the channels of the ids.
+func (b *Backend) update(ids ...uint64) {
+	for _, ch := range b.channels {
+		for _, id := range ids {
+			if ch.ID == id {
+				b.SetChannel(copyChannel(ch))
+				break
+			}
+		}
+	}
+}
+
+// failedForward publishes a forward failing on the outgoing link.
+func (b *Backend) failedForward(now time.Time) {
+	active := b.active()
+	if len(active) < 2 {
+		return
+	}
+	b.htlcID++
+	b.PublishRoutingEvent(&models.RoutingEvent{
+		IncomingChannelId: b.channels[active[b.rand.Intn(len(active))]].ID,
+		OutgoingChannelId: b.channels[active[b.rand.Intn(len(active))]].ID,
+		IncomingHtlcId:    b.htlcID,
+		OutgoingHtlcId:    b.htlcID,
+		LastUpdate:        now,
+		Direction:         models.RoutingForward,
+		Status:            models.RoutingStatusLinkFailed,
+		AmountMsat:        uint64(1000+b.rand.Intn(2000000)) * 1000,
+		FailureCode:       15,
+		FailureDetail:     "TEMPORARY_CHANNEL_FAILURE INSUFFICIENT_BALANCE",
+	})
+}
+
+// invoice creates an invoice paid through the channel with the most
+// inbound liquidity.
+func (b *Backend) invoice(ctx context.Context) {
+	var in *models.Channel
+	for _, i := range b.active() {
+		if in == nil || b.channels[i].RemoteBalance > in.RemoteBalance {
+			in = b.channels[i]
+		}
+	}
+	amount := int64(1000 + b.rand.Intn(100000))
+	if in == nil || in.RemoteBalance < amount {
+		return
+	}
+	invoice, err := b.CreateInvoice(ctx, amount, "lntop demo")
+	if err != nil {
+		return
+	}
+	in.LocalBalance += amount
+	in.RemoteBalance -= amount
+	in.TotalAmountReceived += amount
+	in.UpdatesCount++
+	b.SetChannel(copyChannel(in))
+	_ = b.SettleInvoice(hex.EncodeToString(invoice.RHash))
+	// the mock credits settled invoices to the wallet.
+	b.SetWalletBalance(b.wallet)
+}
+
+// flap toggles a channel between active and inactive, with its peer.
+func (b *Backend) flap(now time.Time) {
+	ch := b.channels[b.rand.Intn(len(b.channels))]
+	switch ch.Status {
+	case models.ChannelActive:
+		ch.Status = models.ChannelInactive
+		b.RemovePeer(ch.RemotePubKey)
+	case models.ChannelInactive:
+		ch.Status = models.ChannelActive
+		peer := b.peers[ch.RemotePubKey]
+		peer.FlapCount++
+		peer.LastFlap = now
+		b.connect(ch.RemotePubKey)
+	default:
+		return
+	}
+	ch.LastUpdate = &now
+	// the peer disables its policy toward the node while the channel is
+	// inactive.
+	if ch.RemotePolicy != nil {
+		ch.RemotePolicy.Disabled = ch.Status == models.ChannelInactive
+		ch.RemotePolicy.LastUpdate = now
+	}
+	b.SetChannel(copyChannel(ch))
+	b.announce(ch)
+}
+
+// announce publishes the policy of the peer toward the node.
+func (b *Backend) announce(ch *models.Channel) {
+	if ch.RemotePolicy == nil {
+		return
+	}
+	policy := *ch.RemotePolicy
+	b.PublishGraphUpdate(&models.ChannelEdgeUpdate{
+		ChanPoints: []string{ch.ChannelPoint},
+		Policies: []*models.EdgePolicy{{
+			ChanPoint:       ch.ChannelPoint,
+			AdvertisingNode: ch.RemotePubKey,
+			ConnectingNode:  b.info.PubKey,
+			Policy:          &policy,
+		}},
+	})
+}
+
+// ping changes the round trip time of a peer, with the gossip exchanged
+// since the last ping.
+func (b *Backend) ping() {
+	ch := b.channels[b.rand.Intn(len(b.channels))]
+	if ch.Status != models.ChannelActive {
+		return
+	}
+	ch.PingTime = time.Duration(20+b.rand.Intn(300)) * time.Millisecond
+	peer := b.peers[ch.RemotePubKey]
+	peer.PingTime = ch.PingTime
+	peer.BytesSent += uint64(b.rand.Intn(1 << 16))
+	peer.BytesRecv += uint64(b.rand.Intn(1 << 16))
+	b.connect(ch.RemotePubKey)
+}
+
+// connect publishes the peer of the pubkey.
+func (b *Backend) connect(pubkey string) {
+	peer := *b.peers[pubkey]
+	b.SetPeer(&peer)
+}
+
+// ConnectPeer reconnects a peer of the demo and activates its channels,
+// any other node is connected by the mock.
+func (b *Backend) ConnectPeer(ctx context.Context, pubkey, host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.peers[pubkey]; !ok {
+		return b.Backend.ConnectPeer(ctx, pubkey, host)
+	}
+	peers, _ := b.ListPeers(ctx)
+	for _, p := range peers {
+		if p.PubKey == pubkey {
+			return errors.Errorf("already connected to peer: %s", pubkey)
+		}
+	}
+	b.connect(pubkey)
+	for _, ch := range b.channels {
+		if ch.RemotePubKey == pubkey && ch.Status == models.ChannelInactive {
+			ch.Status = models.ChannelActive
+			b.SetChannel(copyChannel(ch))
+		}
+	}
+	return nil
+}
+
+// DisconnectPeer disconnects the peer, the channels of a peer of the demo
+// are inactive until it flaps back.
+func (b *Backend) DisconnectPeer(ctx context.Context, pubkey string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.Backend.DisconnectPeer(ctx, pubkey)
+	if err != nil {
+		return err
+	}
+	for _, ch := range b.channels {
+		if ch.RemotePubKey == pubkey && ch.Status == models.ChannelActive {
+			ch.Status = models.ChannelInactive
+			b.SetChannel(copyChannel(ch))
+		}
+	}
+	return nil
+}
+
+// SendPayment pays after a few seconds, the time of a payment across the
+// network.
+func (b *Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(3 * time.Second):
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	payment, err := b.Backend.SendPayment(ctx, payreq)
+	if err != nil || payment.Route == nil {
+		return payment, err
+	}
+	for _, ch := range b.channels {
+		if ch.ID == payment.Route.Hops[0].ChanID {
+			ch.LocalBalance -= payment.Route.Amount
+			ch.RemoteBalance += payment.Route.Amount
+		}
+	}
+	return payment, nil
+}
+
+// SendKeysend pays after a few seconds like SendPayment.
+func (b *Backend) SendKeysend(ctx context.Context, k *models.Keysend, updates chan *models.Payment) (*models.Payment, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(3 * time.Second):
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	payment, err := b.Backend.SendKeysend(ctx, k, updates)
+	if err != nil || payment.Route == nil {
+		return payment, err
+	}
+	for _, ch := range b.channels {
+		if ch.ID == payment.Route.Hops[0].ChanID {
+			ch.LocalBalance -= payment.Route.Amount
+			ch.RemoteBalance += payment.Route.Amount
+		}
+	}
+	return payment, nil
+}
+
+// Rebalance fails a first attempt through another peer of the demo
+// before the one of the mock.
+func (b *Backend) Rebalance(ctx context.Context, out, in *models.Channel, amount, maxFee int64, attempts chan *models.RebalanceAttempt) (*models.Payment, error) {
+	b.mu.Lock()
+	peer := b.channels[b.rand.Intn(len(b.channels))].RemotePubKey
+	b.mu.Unlock()
+	failed := &models.RebalanceAttempt{
+		Number: 1,
+		Status: models.PaymentInFlight,
+		Route: &models.Route{Amount: amount, Hops: []*models.Hop{
+			{ChanID: out.ID, PubKey: out.RemotePubKey, Amount: amount},
+			{PubKey: peer, Amount: amount},
+			{PubKey: in.RemotePubKey, Amount: amount},
+			{ChanID: in.ID, PubKey: b.info.PubKey, Amount: amount},
+		}},
+	}
+
+	// an attempt takes a few seconds across the network.
+	send := func(a *models.RebalanceAttempt) {
+		if a.Status != models.PaymentInFlight {
+			select {
+			case <-ctx.Done():
+			case <-time.After(2 * time.Second):
+			}
+		}
+		select {
+		case attempts <- a:
+		case <-ctx.Done():
+		}
+	}
+	send(failed)
+	r := *failed
+	r.Status = models.PaymentFailed
+	r.Error = "temporary channel failure"
+	send(&r)
+
+	relay := make(chan *models.RebalanceAttempt)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for a := range relay {
+			a.Number += failed.Number
+			send(a)
+		}
+	}()
+	payment, err := b.Backend.Rebalance(ctx, out, in, amount, maxFee, relay)
+	close(relay)
+	<-done
+	if err != nil || payment.Status != models.PaymentSucceeded {
+		return payment, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.channels {
+		switch ch.ID {
+		case out.ID:
+			ch.LocalBalance -= payment.Route.Amount
+			ch.RemoteBalance += payment.Route.Amount
+		case in.ID:
+			ch.LocalBalance += amount
+			ch.RemoteBalance -= amount
+		}
+	}
+	// a step may have published the channels before they were rebalanced.
+	b.update(out.ID, in.ID)
+	return payment, nil
+}
+
+// DecodePayReq decodes the invoices of the node, any other invoice pays a
+// payee of the demo the amount of its prefix.
+func (b *Backend) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
+	req, err := b.Backend.DecodePayReq(ctx, payreq)
+	if err == nil {
+		return req, nil
+	}
+	s := strings.TrimPrefix(strings.ToLower(payreq), "lightning:")
+	sep := strings.LastIndexByte(s, '1')
+	if !strings.HasPrefix(s, "ln") || sep < 0 {
+		return nil, err
+	}
+	h := sha256.Sum256([]byte(s))
+	amount := prefixAmount(s[:sep])
+	return &models.PayReq{
+		Destination: pubkey(payees[int(h[0])%len(payees)]),
+		PaymentHash: hex.EncodeToString(h[:]),
+		Amount:      amount,
+		AmountMsat:  amount * 1000,
+		Timestamp:   time.Now().Unix(),
+		Expiry:      3600,
+		String:      payreq,
+	}, nil
+}
+
+// prefixAmount returns the amount in sat of the human readable prefix of
+// an invoice, zero if it has none.
+func prefixAmount(prefix string) int64 {
+	i := strings.IndexAny(prefix, "0123456789")
+	if i < 0 {
+		return 0
+	}
+	digits, multiplier := prefix[i:], byte(0)
+	if last := digits[len(digits)-1]; last < '0' || last > '9' {
+		digits, multiplier = digits[:len(digits)-1], last
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0
+	}
+	switch multiplier {
+	case 'm':
+		return n * 100000
+	case 'u':
+		return n * 100
+	case 'n':
+		return n / 10
+	case 'p':
+		return n / 10000
+	}
+	return n * 100000000
+}
+
+// CloseChannel closes the channel of the demo, it is closed at the next
+// block.
+func (b *Backend) CloseChannel(ctx context.Context, channel *models.Channel, force bool, satPerVbyte uint64) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	txid, err := b.Backend.CloseChannel(ctx, channel, force, satPerVbyte)
+	if err != nil {
+		return "", err
+	}
+	for _, ch := range b.channels {
+		if ch.ChannelPoint == channel.ChannelPoint {
+			ch.Status = models.ChannelWaitingClose
+			ch.CloseType = models.CloseCooperative
+			if force {
+				ch.CloseType = models.CloseLocalForce
+			}
+			ch.Closing = &models.Closing{ClosingTxID: txid, LimboBalance: ch.LocalBalance}
+		}
+	}
+	return txid, nil
+}
+
+// block mines a block: the funding of the opening channel confirms and
+// the channel is active after fundingConfs blocks, the closing channel is
+// closed and the outputs of the force closed channel mature.
+func (b *Backend) block() {
+	b.info.BlockHeight++
+	b.info.BlockHash = hash("demo block %d", b.info.BlockHeight)
+	b.SetInfo(b.info)
+
+	// the node writes a new channel backup at each open and close.
+	changed := false
+	channels := b.channels[:0]
+	for _, ch := range b.channels {
+		if ch.Status == models.ChannelWaitingClose {
+			_ = b.RemoveChannel(ch.ChannelPoint, ch.CloseType)
+			changed = true
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	b.channels = channels
+
+	for _, ch := range b.channels {
+		switch ch.Status {
+		case models.ChannelOpening:
+			ch.FundingExpiryBlocks--
+			funded, ok := b.funded[ch.ChannelPoint]
+			if !ok {
+				funded = b.info.BlockHeight
+				b.funded[ch.ChannelPoint] = funded
+				txid, _, _ := strings.Cut(ch.ChannelPoint, ":")
+				b.ConfirmTransaction(txid, b.info.BlockHash, funded)
+			}
+			if b.info.BlockHeight-funded+1 < fundingConfs {
+				break
+			}
+			ch.Status = models.ChannelActive
+			ch.ID = chanID(b.info.BlockHeight, uint64(b.rand.Intn(3000)), 0)
+			ch.LocalPolicy = b.policy(ch.Capacity, time.Now())
+			ch.RemotePolicy = b.policy(ch.Capacity, time.Now())
+			changed = true
+		case models.ChannelForceClosing:
+			if ch.BlocksTilMaturity <= 0 {
+				continue
+			}
+			ch.BlocksTilMaturity--
+			ch.Closing.BlocksTilMaturity--
+			for _, h := range ch.Closing.PendingHTLCs {
+				if h.BlocksTilMaturity > 0 {
+					h.BlocksTilMaturity--
+				}
+			}
+		default:
+			continue
+		}
+		b.SetChannel(copyChannel(ch))
+	}
+	if changed {
+		b.publishBackup()
+	}
+}
+
+// publishBackup sends a channel backup of the open channels, its size
+// grows with their number like the one of lnd.
+func (b *Backend) publishBackup() {
+	backup := &models.ChannelBackup{}
+	for _, ch := range b.channels {
+		if ch.Status == models.ChannelActive || ch.Status == models.ChannelInactive {
+			backup.ChanPoints = append(backup.ChanPoints, ch.ChannelPoint)
+		}
+	}
+	backup.Multi = make([]byte, 64+len(backup.ChanPoints)*128)
+	b.rand.Read(backup.Multi)
+	b.PublishChannelBackup(backup, nil)
+}
+
+func copyChannel(ch *models.Channel) *models.Channel {
+	channel := *ch
+	if ch.LocalPolicy != nil {
+		policy := *ch.LocalPolicy
+		channel.LocalPolicy = &policy
+	}
+	if ch.RemotePolicy != nil {
+		policy := *ch.RemotePolicy
+		channel.RemotePolicy = &policy
+	}
+	if ch.Closing != nil {
+		closing := *ch.Closing
+		closing.PendingHTLCs = make([]*models.PendingHTLC, len(ch.Closing.PendingHTLCs))
+		for i := range ch.Closing.PendingHTLCs {
+			h := *ch.Closing.PendingHTLCs[i]
+			closing.PendingHTLCs[i] = &h
+		}
+		channel.Closing = &closing
+	}
+	return &channel
+}
+
+// New returns the demo backend seeded with the node of the demo.
+func New(c *config.Network) *Backend {
+	b := &Backend{
+		Backend: mock.New(c),
+		rand:    rand.New(rand.NewSource(seed)),
+		peers:   make(map[string]*models.Peer),
+		funded:  make(map[string]uint32),
+	}
+	b.seed(time.Now())
+	return b
+}
@@ -0,0 +1,516 @@
+package demo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/edouardparis/lntop/network/backend/mock"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// height is the block height of the node when the demo starts.
+const height = 850000
+
+// peers are the nodes of the channels of the demo node, in the order of
+// their channels.
+var peers = []struct {
+	alias   string
+	status  int
+	private bool
+}{
+	{"bluewhale", models.ChannelActive, false},
+	{"lightning-bay", models.ChannelActive, false},
+	{"orange-pill", models.ChannelActive, false},
+	{"hodlhub", models.ChannelActive, false},
+	{"zapzap", models.ChannelActive, false},
+	{"sat-stacker", models.ChannelActive, true},
+	{"routing-ronin", models.ChannelActive, false},
+	{"moonrock", models.ChannelActive, false},
+	{"lnbits-cafe", models.ChannelActive, true},
+	{"nakamoto-node", models.ChannelActive, false},
+	{"block-party", models.ChannelInactive, false},
+	{"coldcard-carl", models.ChannelActive, false},
+	{"fiat-exit", models.ChannelOpening, false},
+	{"tor-tortoise", models.ChannelForceClosing, false},
+}
+
+// closedPeers are the nodes of the channels closed before the demo.
+var closedPeers = []struct {
+	alias     string
+	closeType int
+	days      int
+}{
+	{"dusty-node", models.CloseCooperative, 3},
+	{"sleepy-sats", models.CloseRemoteForce, 12},
+	{"fee-sniper", models.CloseLocalForce, 40},
+	{"never-funded", models.CloseFundingCanceled, 75},
+	{"old-friend", models.CloseCooperative, 200},
+}
+
+// payees are the destinations of the payments of the demo, beyond the
+// peers.
+var payees = []string{"coffee-corner", "podcast-sats", "nostr-zaps", "vpn-provider"}
+
+// paymentFailures are the failure reasons of the failed payments.
+var paymentFailures = []string{"no route", "timeout", "incorrect payment details", "insufficient balance"}
+
+// invoiceMemos are the descriptions of the invoices of the demo.
+var invoiceMemos = []string{"lntop demo", "coffee", "podcast boost", "zap", "donation", ""}
+
+var capacities = []int64{1000000, 2000000, 3000000, 5000000, 10000000, 16777215}
+
+// Plausible fee rates, in part per million, of the policies.
+var feeRates = []int64{1, 10, 50, 100, 250, 500, 1000, 2000}
+
+// hash returns a hex hash unique to the name, used as transaction id.
+func hash(format string, a ...interface{}) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf(format, a...)))
+	return hex.EncodeToString(h[:])
+}
+
+// pubkey returns a compressed public key unique to the alias.
+func pubkey(alias string) string {
+	return "02" + hash("demo node %s", alias)
+}
+
+// chanID returns the short channel id of the output of the transaction
+// at the height.
+func chanID(blockHeight uint32, txIndex, output uint64) uint64 {
+	return uint64(blockHeight)<<40 | txIndex<<16 | output
+}
+
+// features returns the feature bits of a node of the demo, the usual ones
+// of a recent node with some optional ones at random.
+func (b *Backend) features() []*models.NodeFeature {
+	bits := []uint32{1, 5, 7, 8, 11, 12, 14, 17}
+	for _, bit := range []uint32{19, 23, 27, 39, 45, 47, 51, 55} {
+		if b.rand.Intn(3) > 0 {
+			bits = append(bits, bit)
+		}
+	}
+	features := make([]*models.NodeFeature, len(bits))
+	for i := range bits {
+		features[i] = models.NewNodeFeature(bits[i])
+	}
+	return features
+}
+
+func (b *Backend) policy(capacity int64, lastUpdate time.Time) *models.RoutingPolicy {
+	return &models.RoutingPolicy{
+		TimeLockDelta:    []uint32{40, 80, 144}[b.rand.Intn(3)],
+		MinHtlc:          1000,
+		LastUpdate:       lastUpdate,
+		MaxHtlc:          uint64(capacity) * 990,
+		FeeBaseMsat:      []int64{0, 0, 1000}[b.rand.Intn(3)],
+		FeeRateMilliMsat: feeRates[b.rand.Intn(len(feeRates))],
+	}
+}
+
+// seed fills the mock backend with the node, its peers, channels, closed
+// channels, forwards and on-chain state.
+func (b *Backend) seed(now time.Time) {
+	b.info = models.Info{
+		PubKey:      pubkey("lntop-demo"),
+		Alias:       "lntop-demo",
+		BlockHeight: height,
+		BlockHash:   hash("demo block %d", height),
+		Synced:      true,
+		Version:     "0.18.0-beta demo",
+		Chains:      []string{"bitcoin"},
+		Network:     "mainnet",
+	}
+	b.Backend.SetInfo(b.info)
+
+	for i, p := range peers {
+		key := pubkey(p.alias)
+		capacity := capacities[b.rand.Intn(len(capacities))]
+		opened := uint32(height - 1000 - b.rand.Intn(60000))
+		lifetime := time.Duration(height-opened) * 10 * time.Minute
+		lastUpdate := now.Add(-time.Duration(b.rand.Intn(3600)) * time.Second)
+
+		node := &models.Node{
+			NumChannels:   uint32(10 + b.rand.Intn(400)),
+			TotalCapacity: int64(50+b.rand.Intn(5000)) * 1000000,
+			LastUpdate:    lastUpdate,
+			PubKey:        key,
+			Alias:         p.alias,
+			Addresses: []*models.NodeAddress{{
+				Network: "tcp",
+				Addr:    fmt.Sprintf("203.0.113.%d:9735", 10+i),
+			}},
+			Features: b.features(),
+		}
+		b.AddNode(node)
+
+		ch := &models.Channel{
+			ID:               chanID(opened, uint64(b.rand.Intn(3000)), 0),
+			Status:           p.status,
+			RemotePubKey:     key,
+			ChannelPoint:     hash("demo channel %d", i) + ":0",
+			Capacity:         capacity,
+			CommitFee:        int64(2000 + b.rand.Intn(2000)),
+			CommitWeight:     1116,
+			FeePerKiloWeight: 253,
+			CSVDelay:         144,
+			Private:          p.private,
+			PendingHTLC:      []*models.HTLC{},
+			LastUpdate:       &lastUpdate,
+			Node:             node,
+			LocalPolicy:      b.policy(capacity, lastUpdate),
+			RemotePolicy:     b.policy(capacity, now.Add(-time.Duration(b.rand.Intn(72))*time.Hour)),
+			Lifetime:         lifetime,
+			Uptime:           lifetime * time.Duration(80+b.rand.Intn(21)) / 100,
+			PingTime:         time.Duration(20+b.rand.Intn(300)) * time.Millisecond,
+			Anchors:          true,
+		}
+		ch.LocalBalance = capacity * int64(b.rand.Intn(101)) / 100
+		ch.RemoteBalance = capacity - ch.LocalBalance - ch.CommitFee
+		if ch.RemoteBalance < 0 {
+			ch.RemoteBalance = 0
+		}
+		ch.TotalAmountSent = int64(b.rand.Intn(int(capacity) * 4))
+		ch.TotalAmountReceived = int64(b.rand.Intn(int(capacity) * 4))
+		ch.UpdatesCount = uint64(b.rand.Intn(50000))
+		if p.status == models.ChannelActive && i%4 == 1 {
+			amount := int64(10000 + b.rand.Intn(200000))
+			ch.PendingHTLC = append(ch.PendingHTLC, &models.HTLC{
+				Incoming:         b.rand.Intn(2) == 0,
+				Amount:           amount,
+				Hashlock:         []byte(hash("demo htlc %d", i)[:32]),
+				ExpirationHeight: height + 40 + uint32(b.rand.Intn(100)),
+			})
+			ch.UnsettledBalance = amount
+		}
+
+		switch p.status {
+		case models.ChannelOpening:
+			ch.ID = 0
+			ch.LocalBalance = capacity - ch.CommitFee
+			ch.RemoteBalance = 0
+			ch.TotalAmountSent, ch.TotalAmountReceived, ch.UpdatesCount = 0, 0, 0
+			ch.Uptime, ch.Lifetime = 0, 0
+			ch.LocalPolicy, ch.RemotePolicy = nil, nil
+			ch.FundingExpiryBlocks = fundingExpiry
+			b.AddTransaction(&models.Transaction{
+				TxHash:        hash("demo channel %d", i),
+				Amount:        -capacity,
+				Date:          now.Add(-5 * time.Minute),
+				TotalFees:     1540,
+				DestAddresses: []string{"bc1qdemo" + hash("address %d", i)[:32]},
+			})
+		case models.ChannelInactive:
+			ch.RemotePolicy.Disabled = true
+		case models.ChannelForceClosing:
+			ch.BlocksTilMaturity = 96
+			ch.CloseType = models.CloseLocalForce
+			ch.Closing = &models.Closing{
+				ClosingTxID:       hash("demo close %d", i),
+				LimboBalance:      ch.LocalBalance,
+				MaturityHeight:    height + 96,
+				BlocksTilMaturity: 96,
+				Anchor:            models.AnchorLimbo,
+				PendingHTLCs: []*models.PendingHTLC{{
+					Amount:            25000,
+					Outpoint:          hash("demo close %d", i) + ":2",
+					MaturityHeight:    height + 40,
+					BlocksTilMaturity: 40,
+					Stage:             1,
+				}},
+			}
+			b.SetPendingSweeps([]*models.PendingSweep{{
+				Outpoint:             hash("demo close %d", i) + ":0",
+				WitnessType:          "COMMITMENT_ANCHOR",
+				Amount:               330,
+				SatPerVbyte:          4,
+				RequestedSatPerVbyte: 4,
+				BroadcastAttempts:    2,
+				NextBroadcastHeight:  height + 1,
+				DeadlineHeight:       height + 18,
+				Budget:               uint64(ch.LocalBalance / 2),
+			}})
+		}
+		b.channels = append(b.channels, ch)
+		b.SetChannel(copyChannel(ch))
+
+		b.peers[key] = &models.Peer{
+			PubKey:    key,
+			Address:   node.Addresses[0].Addr,
+			Inbound:   b.rand.Intn(2) == 0,
+			BytesSent: uint64(b.rand.Intn(1 << 30)),
+			BytesRecv: uint64(b.rand.Intn(1 << 30)),
+			SatSent:   ch.TotalAmountSent,
+			SatRecv:   ch.TotalAmountReceived,
+			PingTime:  ch.PingTime,
+			FlapCount: int32(b.rand.Intn(4)),
+		}
+		if b.peers[key].FlapCount > 0 {
+			b.peers[key].LastFlap = now.Add(-time.Duration(1+b.rand.Intn(72)) * time.Hour)
+		}
+		if p.status != models.ChannelInactive && p.status != models.ChannelForceClosing {
+			b.connect(key)
+		}
+	}
+
+	closed := []*models.ClosedChannel{}
+	for i, p := range closedPeers {
+		key := pubkey(p.alias)
+		b.AddNode(&models.Node{PubKey: key, Alias: p.alias, LastUpdate: now, Features: b.features()})
+		capacity := capacities[b.rand.Intn(len(capacities))]
+		closedAt := now.Add(-time.Duration(p.days) * 24 * time.Hour)
+		closeHeight := uint32(height - p.days*144)
+		initiator := models.InitiatorLocal
+		if p.closeType == models.CloseRemoteForce {
+			initiator = models.InitiatorRemote
+		}
+		settled := capacity * int64(b.rand.Intn(101)) / 100
+		if p.closeType == models.CloseFundingCanceled {
+			settled = 0
+		}
+		closed = append(closed, &models.ClosedChannel{
+			ID:             chanID(closeHeight-10000, uint64(b.rand.Intn(3000)), 1),
+			ChannelPoint:   hash("demo closed channel %d", i) + ":1",
+			RemotePubKey:   key,
+			Capacity:       capacity,
+			SettledBalance: settled,
+			CloseHeight:    closeHeight,
+			ClosingTxHash:  hash("demo closing %d", i),
+			CloseType:      p.closeType,
+			OpenInitiator:  models.InitiatorLocal,
+			CloseInitiator: initiator,
+			CloseTime:      closedAt,
+			ClosingFee:     int64(1000 + b.rand.Intn(5000)),
+		})
+	}
+	b.SetClosedChannels(closed)
+
+	start := now.Add(-30 * 24 * time.Hour)
+	for t := start; t.Before(now); t = t.Add(time.Duration(10+b.rand.Intn(110)) * time.Minute) {
+		if event := b.forward(t); event != nil {
+			b.AddForwardingEvent(event)
+		}
+	}
+	for _, ch := range b.channels {
+		b.SetChannel(copyChannel(ch))
+	}
+
+	var confirmed int64
+	utxos := []*models.UTXO{}
+	for i := 0; i < 7; i++ {
+		amount := int64(20000 + b.rand.Intn(3000000))
+		confs := int64(1 + b.rand.Intn(20000))
+		addressType := []string{models.AddressP2WKH, models.AddressP2WKH, models.AddressP2TR, models.AddressNP2WKH}[b.rand.Intn(4)]
+		txid := hash("demo deposit %d", i)
+		utxos = append(utxos, &models.UTXO{
+			Outpoint:      txid + ":0",
+			Address:       "bc1qdemo" + hash("deposit address %d", i)[:32],
+			AddressType:   addressType,
+			Amount:        amount,
+			Confirmations: confs,
+			Label:         []string{"", "", "exchange withdrawal", "change", ""}[b.rand.Intn(5)],
+		})
+		b.AddTransaction(&models.Transaction{
+			TxHash:           txid,
+			Amount:           amount,
+			NumConfirmations: int32(confs),
+			BlockHash:        hash("demo block %d", height-confs+1),
+			BlockHeight:      int32(height - confs + 1),
+			Date:             now.Add(-time.Duration(confs) * 10 * time.Minute),
+			DestAddresses:    []string{utxos[i].Address},
+		})
+		confirmed += amount
+	}
+	// an output is leased by a channel funding in progress.
+	utxos[3].LeaseID = hash("demo lease %d", 3)
+	utxos[3].LeaseExpiration = now.Add(45 * time.Minute)
+	b.SetUTXOs(utxos)
+
+	b.wallet = models.WalletBalance{
+		TotalBalance:     confirmed,
+		ConfirmedBalance: confirmed,
+		AnchorReserve:    models.RequiredAnchorReserve(b.channels),
+	}
+	b.SetWalletBalance(b.wallet)
+
+	for _, alias := range payees {
+		b.AddNode(&models.Node{PubKey: pubkey(alias), Alias: alias, LastUpdate: now, Features: b.features()})
+	}
+	payments := []*models.Payment{}
+	for i, t := 0, start; t.Before(now); i, t = i+1, t.Add(time.Duration(6+b.rand.Intn(48))*time.Hour) {
+		payments = append(payments, b.payment(i, t))
+	}
+	b.SetPayments(payments)
+
+	invoices := []*models.Invoice{}
+	for i, t := 1, start; t.Before(now); i, t = i+1, t.Add(time.Duration(4+b.rand.Intn(36))*time.Hour) {
+		// two in three invoices are settled and the others expire.
+		invoices = append(invoices, b.seedInvoice(uint64(i), t, b.rand.Intn(3) > 0))
+	}
+	invoices = append(invoices, b.seedInvoice(uint64(len(invoices)+1), now.Add(-10*time.Minute), false))
+	b.SetInvoices(invoices)
+
+	b.SetWatchtower(models.Watchtower{
+		Towers: []*models.Tower{{
+			PubKey:    pubkey("demo tower"),
+			Addresses: []string{"tower.lntop.dev:9911"},
+			Active:    true,
+			Sessions: []*models.TowerSession{
+				{Policy: "anchor", NumBackups: 1024, MaxBackups: 1024, SweepSatPerVbyte: 10},
+				{Policy: "anchor", NumBackups: 337, NumPendingBackups: 2, MaxBackups: 1024, SweepSatPerVbyte: 10},
+			},
+		}, {
+			PubKey:    pubkey("backup tower"),
+			Addresses: []string{"vd3kostnkdhqqfpvmdpxiuzu4gmvsffzlqtokoqhrd3ylkukbqk7gmqd.onion:9911"},
+			Sessions: []*models.TowerSession{
+				{Policy: "legacy", NumBackups: 12, MaxBackups: 1024, SweepSatPerVbyte: 10},
+			},
+		}},
+		Stats: models.TowerStats{
+			NumBackups:           1373,
+			NumPendingBackups:    2,
+			NumSessionsAcquired:  3,
+			NumSessionsExhausted: 1,
+		},
+		Policies: []*models.TowerPolicy{
+			{Type: "legacy", MaxUpdates: 1024, SweepSatPerVbyte: 10},
+			{Type: "anchor", MaxUpdates: 1024, SweepSatPerVbyte: 10},
+		},
+	})
+
+	b.SetMissionControl(b.seedMissionControl(now))
+
+	b.SetOffers(b.seedOffers(start, now))
+}
+
+// offerNotes are the payer notes of the invoice requests of the offers.
+var offerNotes = []string{"great podcast", "thanks!", "for the coffee", "", ""}
+
+// seedOffers returns the offers of the node, paid a few times since start
+// except the disabled one.
+func (b *Backend) seedOffers(start, now time.Time) []*models.Offer {
+	offers := []*models.Offer{
+		{Description: "lntop demo tips", Status: models.OfferActive},
+		{Description: "podcast subscription", Amount: 21000, Status: models.OfferActive},
+		{Description: "sticker pack", Amount: 5000, Status: models.OfferUsed, SingleUse: true},
+		{Description: "old donations", Status: models.OfferDisabled},
+	}
+	for i, o := range offers {
+		id, _ := hex.DecodeString(hash("demo offer %d", i))
+		o.ID = hex.EncodeToString(id)
+		o.Bolt12 = mock.Offer(id)
+		n := 1 + b.rand.Intn(6)
+		if o.SingleUse {
+			n = 1
+		}
+		for j := 0; j < n; j++ {
+			t := start.Add(time.Duration(b.rand.Int63n(int64(now.Sub(start)))))
+			amount := o.Amount
+			if amount == 0 {
+				amount = int64(1000 * (1 + b.rand.Intn(50)))
+			}
+			r := &models.OfferRequest{
+				PaymentHash: hash("demo offer %d request %d", i, j),
+				Amount:      amount,
+				ExpiresAt:   t.Add(2 * time.Hour),
+				PayerNote:   offerNotes[b.rand.Intn(len(offerNotes))],
+			}
+			// one request in four is not paid.
+			if o.SingleUse || b.rand.Intn(4) > 0 {
+				r.Paid = true
+				r.AmountPaid = amount
+				r.PaidAt = t.Add(time.Duration(1+b.rand.Intn(60)) * time.Second)
+			}
+			o.Requests = append(o.Requests, r)
+		}
+		sort.Slice(o.Requests, func(a, c int) bool { return o.Requests[a].ExpiresAt.Before(o.Requests[c].ExpiresAt) })
+	}
+	return offers
+}
+
+// seedMissionControl returns the pairs of the payments of the demo, from
+// the node to the active peers and from the peers to the payees, a pair
+// out of three last failed.
+func (b *Backend) seedMissionControl(now time.Time) []*models.MissionPair {
+	var pairs []*models.MissionPair
+	add := func(from, to string) {
+		pair := &models.MissionPair{NodeFrom: pubkey(from), NodeTo: pubkey(to)}
+		amount := int64(1000 * (10000 + b.rand.Intn(500000)))
+		pair.SuccessTime = now.Add(-time.Duration(10+b.rand.Intn(2000)) * time.Minute)
+		pair.SuccessAmtMsat = amount
+		if b.rand.Intn(3) == 0 {
+			pair.FailTime = now.Add(-time.Duration(1+b.rand.Intn(180)) * time.Minute)
+			pair.FailAmtMsat = amount + int64(1000*b.rand.Intn(200000))
+		}
+		pairs = append(pairs, pair)
+	}
+	for _, p := range peers {
+		if p.status != models.ChannelActive {
+			continue
+		}
+		add("lntop-demo", p.alias)
+		add(p.alias, payees[b.rand.Intn(len(payees))])
+	}
+	return pairs
+}
+
+// seedInvoice returns an invoice created at t, settled a few minutes
+// later if settle is true.
+func (b *Backend) seedInvoice(i uint64, t time.Time, settle bool) *models.Invoice {
+	amount := int64(1000 * (1 + b.rand.Intn(250)))
+	if b.rand.Intn(8) == 0 {
+		amount = 0
+	}
+	rhash, _ := hex.DecodeString(hash("demo invoice %d", i))
+	invoice := &models.Invoice{
+		Index:          i,
+		Amount:         amount,
+		Description:    invoiceMemos[b.rand.Intn(len(invoiceMemos))],
+		RHash:          rhash,
+		PaymentRequest: mock.PayReq(amount, rhash),
+		State:          models.InvoiceOpen,
+		CreationDate:   t.Unix(),
+		Expiry:         3600,
+	}
+	if settle {
+		paid := amount
+		if paid == 0 {
+			paid = int64(1000 * (1 + b.rand.Intn(50)))
+		}
+		invoice.Settled = true
+		invoice.State = models.InvoiceSettled
+		invoice.AmountPaid = paid
+		invoice.AmountPaidInMSat = paid * 1000
+		invoice.SettleDate = t.Add(time.Duration(1+b.rand.Intn(30)) * time.Minute).Unix()
+	}
+	return invoice
+}
+
+// payment returns a payment to a payee through a channel of the node,
+// one in five fails.
+func (b *Backend) payment(i int, t time.Time) *models.Payment {
+	out := b.channels[b.rand.Intn(len(b.channels)-2)]
+	amount := int64(100 + b.rand.Intn(200000))
+	payee := payees[b.rand.Intn(len(payees))]
+	payment := &models.Payment{
+		Hash:         hash("demo payment %d", i),
+		Status:       models.PaymentSucceeded,
+		Amount:       amount,
+		Fee:          amount/2000 + int64(b.rand.Intn(3)),
+		CreationDate: t,
+	}
+	if b.rand.Intn(5) == 0 {
+		payment.Status = models.PaymentFailed
+		payment.PaymentError = paymentFailures[b.rand.Intn(len(paymentFailures))]
+		payment.Fee = 0
+	}
+	hops := []*models.Hop{{ChanID: out.ID, PubKey: out.RemotePubKey, Amount: amount + payment.Fee, Fee: payment.Fee}}
+	if b.rand.Intn(2) == 0 {
+		mid := b.channels[b.rand.Intn(len(b.channels)-2)]
+		hops = append(hops, &models.Hop{PubKey: mid.RemotePubKey, Amount: amount})
+	}
+	hops = append(hops, &models.Hop{PubKey: pubkey(payee), Amount: amount})
+	payment.Route = &models.Route{Fee: payment.Fee, Amount: amount + payment.Fee, Hops: hops}
+	return payment
+}
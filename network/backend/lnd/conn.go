@@ -1,13 +1,18 @@
 package lnd
 
 import (
+	"context"
 	"crypto/tls"
-	"io/ioutil"
+	"crypto/x509"
+	"net"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	macaroon "gopkg.in/macaroon.v2"
 
 	"github.com/lightningnetwork/lnd/lncfg"
@@ -16,8 +21,10 @@ import (
 	"github.com/edouardparis/lntop/config"
 )
 
-func newClientConn(c *config.Network) (*grpc.ClientConn, error) {
-	macaroonBytes, err := ioutil.ReadFile(c.Macaroon)
+// newClientConn returns a connection to lnd, pinged at the interval of
+// keepaliveTime while idle if it is not zero.
+func newClientConn(c *config.Network, keepaliveTime time.Duration) (*grpc.ClientConn, error) {
+	macaroonBytes, err := c.MacaroonBytes()
 	if err != nil {
 		return nil, err
 	}
@@ -38,15 +45,20 @@ func newClientConn(c *config.Network) (*grpc.ClientConn, error) {
 		return nil, errors.WithStack(err)
 	}
 
-	var cred credentials.TransportCredentials
-	if c.Cert != "" {
-		cred, err = credentials.NewClientTLSFromFile(c.Cert, "")
+	der, err := c.CertDER()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{}
+	if der != nil {
+		cert, err := x509.ParseCertificate(der)
 		if err != nil {
-			return nil, err
+			return nil, errors.WithStack(err)
 		}
-	} else {
-		cred = credentials.NewTLS(&tls.Config{})
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AddCert(cert)
 	}
+	cred := credentials.NewTLS(tlsConfig)
 
 	u, err := url.Parse(c.Address)
 	if err != nil {
@@ -58,12 +70,29 @@ func newClientConn(c *config.Network) (*grpc.ClientConn, error) {
 		return nil, err
 	}
 
+	dialer := lncfg.ClientAddressDialer(u.Port())
+	if c.Proxy != "" {
+		dialer, err = proxyDialer(c.Proxy, u.Port())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(cred),
 		grpc.WithPerRPCCredentials(macaroon),
-		grpc.WithContextDialer(lncfg.ClientAddressDialer(u.Port())),
+		grpc.WithContextDialer(dialer),
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(c.MaxMsgRecvSize)),
 	}
+	if keepaliveTime > 0 {
+		// lnd closes the connections pinged without a stream unless
+		// permitted, which it is by default.
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTime,
+			PermitWithoutStream: true,
+		}))
+	}
 
 	conn, err := grpc.Dial(u.Hostname(), opts...)
 	if err != nil {
@@ -72,3 +101,27 @@ func newClientConn(c *config.Network) (*grpc.ClientConn, error) {
 
 	return conn, nil
 }
+
+// proxyDialer returns the dialer of the addresses through the SOCKS5 proxy
+// of the url, e.g. socks5://127.0.0.1:9050 for Tor. The proxy resolves the
+// hostnames, the .onion ones included.
+func proxyDialer(proxyURL, defaultPort string) (func(context.Context, string) (net.Conn, error), error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d, err := proxy.FromURL(u, proxy.Direct)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid proxy")
+	}
+	dialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, errors.Errorf("proxy %s cannot dial with a context", u.Redacted())
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, defaultPort)
+		}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}, nil
+}
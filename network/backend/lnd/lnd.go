@@ -1,15 +1,21 @@
 package lnd
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"regexp"
-	"strconv"
+	"math"
 	"time"
 
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/wtclientrpc"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -17,6 +23,7 @@ import (
 
 	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/backend"
 	"github.com/edouardparis/lntop/network/backend/pool"
 	"github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/network/options"
@@ -25,6 +32,22 @@ import (
 const (
 	lndDefaultInvoiceExpiry = 3600
 	lndMinPoolCapacity      = 6
+	lndFwdingHistPageSize   = 10000
+	lndPaymentTimeout       = 60
+	lndMaxPayments          = 1000
+	lndPaymentsPageSize     = 1000
+	lndInvoicesPageSize     = 1000
+	lndMaxInvoices          = 10000
+	lndConnectTimeout       = 30
+	lndRebalanceAttempts    = 10
+	lndRebalanceCltvDelta   = 40
+	// lndBatchConfTarget is the confirmation target of a batch funding
+	// transaction without fee rate.
+	lndBatchConfTarget = 6
+	// lndKeysendRecord is the TLV record of the preimage of a keysend,
+	// lndMessageRecord the one of the message read by the wallets.
+	lndKeysendRecord = 5482373484
+	lndMessageRecord = 34349334
 )
 
 type Client struct {
@@ -45,10 +68,44 @@ func (c *RouterClient) Close() error {
 	return c.conn.Close()
 }
 
+type WalletKitClient struct {
+	walletrpc.WalletKitClient
+	conn *pool.Conn
+}
+
+func (c *WalletKitClient) Close() error {
+	return c.conn.Close()
+}
+
+type WatchtowerClient struct {
+	wtclientrpc.WatchtowerClientClient
+	conn *pool.Conn
+}
+
+func (c *WatchtowerClient) Close() error {
+	return c.conn.Close()
+}
+
+// errNoOffers is the error of the BOLT12 offers, lnd does not support
+// them.
+var errNoOffers = errors.New("BOLT12 offers are not supported by lnd")
+
+var _ backend.Backend = (*Backend)(nil)
+
 type Backend struct {
-	cfg    *config.Network
-	logger logging.Logger
-	pool   *pool.Pool
+	cfg      *config.Network
+	timeouts config.Timeouts
+	logger   logging.Logger
+	pool     *pool.Pool
+}
+
+// withTimeout returns the context of a call with the deadline d, the
+// context itself if d is zero.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d == 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
 }
 
 func (l Backend) NodeName() string {
@@ -65,6 +122,9 @@ func (l Backend) Ping() error {
 }
 
 func (l Backend) Info(ctx context.Context) (*models.Info, error) {
+	ctx, cancel := withTimeout(ctx, l.timeouts.Info)
+	defer cancel()
+
 	clt, err := l.Client(ctx)
 	if err != nil {
 		return nil, err
@@ -169,8 +229,34 @@ func (l Backend) SubscribeChannels(ctx context.Context, events chan *models.Chan
 				}
 				return err
 			}
-			if event.Type == lnrpc.ChannelEventUpdate_FULLY_RESOLVED_CHANNEL {
+			switch event.Type {
+			case lnrpc.ChannelEventUpdate_FULLY_RESOLVED_CHANNEL:
 				events <- &models.ChannelUpdate{}
+			case lnrpc.ChannelEventUpdate_OPEN_CHANNEL:
+				c := channelProtoToChannel(event.GetOpenChannel())
+				events <- &models.ChannelUpdate{
+					ChannelPoint: c.ChannelPoint,
+					RemotePubKey: c.RemotePubKey,
+					Status:       c.Status,
+					Channel:      c,
+				}
+			case lnrpc.ChannelEventUpdate_ACTIVE_CHANNEL:
+				events <- &models.ChannelUpdate{
+					ChannelPoint: chanpointToString(event.GetActiveChannel()),
+					Status:       models.ChannelActive,
+				}
+			case lnrpc.ChannelEventUpdate_INACTIVE_CHANNEL:
+				events <- &models.ChannelUpdate{
+					ChannelPoint: chanpointToString(event.GetInactiveChannel()),
+					Status:       models.ChannelInactive,
+				}
+			case lnrpc.ChannelEventUpdate_CLOSED_CHANNEL:
+				c := event.GetClosedChannel()
+				events <- &models.ChannelUpdate{
+					ChannelPoint: c.GetChannelPoint(),
+					RemotePubKey: c.GetRemotePubkey(),
+					CloseType:    closeTypeProtoToCloseType(c.GetCloseType()),
+				}
 			}
 
 		}
@@ -213,17 +299,129 @@ func (l Backend) SubscribeGraphEvents(ctx context.Context, events chan *models.C
 				}
 				return err
 			}
-			chanPoints := []string{}
+			update := &models.ChannelEdgeUpdate{ChanPoints: []string{}}
 			for _, c := range event.ChannelUpdates {
-				chanPoints = append(chanPoints, chanpointToString(c.ChanPoint))
+				chanPoint := chanpointToString(c.ChanPoint)
+				update.ChanPoints = append(update.ChanPoints, chanPoint)
+				update.Policies = append(update.Policies, &models.EdgePolicy{
+					ChanPoint:       chanPoint,
+					AdvertisingNode: c.AdvertisingNode,
+					ConnectingNode:  c.ConnectingNode,
+					Policy:          protoToRoutingPolicy(c.RoutingPolicy),
+				})
+			}
+			if len(update.ChanPoints) > 0 {
+				events <- update
+			}
+		}
+	}
+}
+
+func (l Backend) SubscribeChannelBackups(ctx context.Context, events chan *models.ChannelBackup) error {
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	backupEvents, err := clt.SubscribeChannelBackups(ctx, &lnrpc.ChannelBackupSubscription{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			event, err := backupEvents.Recv()
+			if err != nil {
+				st, ok := status.FromError(err)
+				if ok && st.Code() == codes.Canceled {
+					l.logger.Debug("stopping subscribe channel backups: context canceled")
+					return nil
+				}
+				return err
 			}
-			if len(chanPoints) > 0 {
-				events <- &models.ChannelEdgeUpdate{ChanPoints: chanPoints}
+			if event.MultiChanBackup != nil {
+				events <- multiChanBackupProtoToChannelBackup(event.MultiChanBackup)
 			}
 		}
 	}
 }
 
+func (l Backend) GetState(ctx context.Context) (models.NodeState, error) {
+	ctx, cancel := withTimeout(ctx, l.timeouts.Info)
+	defer cancel()
+
+	conn, err := l.pool.Get(ctx)
+	if err != nil {
+		return models.NodeStateUnavailable, err
+	}
+	defer conn.Close()
+
+	resp, err := lnrpc.NewStateClient(conn.ClientConn).GetState(ctx, &lnrpc.GetStateRequest{})
+	if err != nil {
+		return models.NodeStateUnavailable, errors.WithStack(err)
+	}
+	return protoToNodeState(resp.State), nil
+}
+
+func (l Backend) BakeMacaroon(ctx context.Context, permissions []models.Permission) ([]byte, error) {
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	req := &lnrpc.BakeMacaroonRequest{}
+	for _, p := range permissions {
+		req.Permissions = append(req.Permissions, &lnrpc.MacaroonPermission{
+			Entity: p.Entity,
+			Action: p.Action,
+		})
+	}
+	resp, err := clt.BakeMacaroon(ctx, req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mac, err := hex.DecodeString(resp.Macaroon)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return mac, nil
+}
+
+func (l Backend) VerifyChannelBackup(ctx context.Context, backup *models.ChannelBackup) error {
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.VerifyChanBackup(ctx, &lnrpc.ChanBackupSnapshot{
+		MultiChanBackup: &lnrpc.MultiChanBackup{MultiChanBackup: backup.Multi},
+	})
+	return errors.WithStack(err)
+}
+
+func (l Backend) ExportChannelBackup(ctx context.Context) (*models.ChannelBackup, error) {
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.ExportAllChannelBackups(ctx, &lnrpc.ChanBackupExportRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.MultiChanBackup == nil {
+		return nil, errors.New("no channel backup")
+	}
+	return multiChanBackupProtoToChannelBackup(resp.MultiChanBackup), nil
+}
+
 func (l Backend) SubscribeRoutingEvents(ctx context.Context, channelEvents chan *models.RoutingEvent) error {
 	clt, err := l.RouterClient(ctx)
 	if err != nil {
@@ -256,6 +454,60 @@ func (l Backend) SubscribeRoutingEvents(ctx context.Context, channelEvents chan
 	}
 }
 
+// InterceptHTLCs runs the HtlcInterceptor of the router, lnd resumes the
+// HTLCs still held once the stream is closed, unless it is started with
+// requireinterceptor.
+func (l Backend) InterceptHTLCs(ctx context.Context, htlcs chan *models.InterceptedHTLC,
+	resolutions chan *models.HTLCResolution) error {
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := clt.HtlcInterceptor(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case htlcs <- interceptProtoToHTLC(req):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			st, ok := status.FromError(err)
+			if ok && st.Code() == codes.Canceled {
+				l.logger.Debug("stopping htlc interceptor: context canceled")
+				return nil
+			}
+			return errors.WithStack(err)
+		case r := <-resolutions:
+			err := stream.Send(resolutionToProto(r))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+}
+
 func (l Backend) Client(ctx context.Context) (*Client, error) {
 	conn, err := l.pool.Get(ctx)
 	if err != nil {
@@ -280,8 +532,32 @@ func (l Backend) RouterClient(ctx context.Context) (*RouterClient, error) {
 	}, nil
 }
 
+func (l Backend) WalletKitClient(ctx context.Context) (*WalletKitClient, error) {
+	conn, err := l.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletKitClient{
+		WalletKitClient: walletrpc.NewWalletKitClient(conn.ClientConn),
+		conn:            conn,
+	}, nil
+}
+
+func (l Backend) WatchtowerClient(ctx context.Context) (*WatchtowerClient, error) {
+	conn, err := l.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WatchtowerClient{
+		WatchtowerClientClient: wtclientrpc.NewWatchtowerClientClient(conn.ClientConn),
+		conn:                   conn,
+	}, nil
+}
+
 func (l Backend) NewClientConn() (*grpc.ClientConn, error) {
-	return newClientConn(l.cfg)
+	return newClientConn(l.cfg, l.timeouts.Keepalive)
 }
 
 func (l Backend) GetTransactions(ctx context.Context) ([]*models.Transaction, error) {
@@ -303,6 +579,8 @@ func (l Backend) GetTransactions(ctx context.Context) ([]*models.Transaction, er
 
 func (l Backend) GetWalletBalance(ctx context.Context) (*models.WalletBalance, error) {
 	l.logger.Debug("Retrieve wallet balance...")
+	ctx, cancel := withTimeout(ctx, l.timeouts.Info)
+	defer cancel()
 
 	clt, err := l.Client(ctx)
 	if err != nil {
@@ -325,6 +603,8 @@ func (l Backend) GetWalletBalance(ctx context.Context) (*models.WalletBalance, e
 
 func (l Backend) GetChannelsBalance(ctx context.Context) (*models.ChannelsBalance, error) {
 	l.logger.Debug("Retrieve channel balance...")
+	ctx, cancel := withTimeout(ctx, l.timeouts.Info)
+	defer cancel()
 
 	clt, err := l.Client(ctx)
 	if err != nil {
@@ -347,6 +627,8 @@ func (l Backend) GetChannelsBalance(ctx context.Context) (*models.ChannelsBalanc
 
 func (l Backend) ListChannels(ctx context.Context, opt ...options.Channel) ([]*models.Channel, error) {
 	l.logger.Debug("List channels")
+	ctx, cancel := withTimeout(ctx, l.timeouts.ListChannels)
+	defer cancel()
 
 	clt, err := l.Client(ctx)
 	if err != nil {
@@ -449,6 +731,28 @@ func (l Backend) GetNode(ctx context.Context, pubkey string, includeChannels boo
 	return result, nil
 }
 
+func (l Backend) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	l.logger.Debug("ListNodes")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.DescribeGraph(ctx, &lnrpc.ChannelGraphRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	nodes := graphProtoToNodes(resp)
+	for i := range nodes {
+		if forcedAlias, ok := l.cfg.Aliases[nodes[i].PubKey]; ok {
+			nodes[i].ForcedAlias = forcedAlias
+		}
+	}
+	return nodes, nil
+}
+
 func (l Backend) GetForwardingHistory(ctx context.Context, startTime string, maxNumEvents uint32) ([]*models.ForwardingEvent, error) {
 	l.logger.Debug("GetForwardingHistory")
 
@@ -457,16 +761,29 @@ func (l Backend) GetForwardingHistory(ctx context.Context, startTime string, max
 		return nil, err
 	}
 	defer clt.Close()
-	t, err := parseTime(startTime, time.Now())
+	t, err := backend.ParseTime(startTime, time.Now())
+
+	// the history is read by pages from the start time, the last
+	// maxNumEvents are kept.
+	result := []*models.ForwardingEvent{}
 	req := &lnrpc.ForwardingHistoryRequest{
 		StartTime:    t,
-		NumMaxEvents: maxNumEvents,
+		NumMaxEvents: lndFwdingHistPageSize,
 	}
-	resp, err := clt.ForwardingHistory(ctx, req)
-	if err != nil {
-		return nil, errors.WithStack(err)
+	for {
+		resp, err := clt.ForwardingHistory(ctx, req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result = append(result, protoToForwardingHistory(resp)...)
+		if len(resp.ForwardingEvents) < lndFwdingHistPageSize {
+			break
+		}
+		req.IndexOffset = resp.LastOffsetIndex
+	}
+	if maxNumEvents > 0 && len(result) > int(maxNumEvents) {
+		result = result[len(result)-int(maxNumEvents):]
 	}
-	result := protoToForwardingHistory(resp)
 
 	// Enrich peer alias names.
 	// This can be removed once the ForwardingHistory
@@ -510,7 +827,7 @@ func (l Backend) GetForwardingHistory(ctx context.Context, startTime string, max
 				events[i].PeerAliasIn = val
 			} else {
 				events[i].PeerAliasIn, err = getPeerAlias(event.ChanIdIn)
-				if err != nil {
+				if err == nil {
 					cache[event.ChanIdIn] = events[i].PeerAliasIn
 				}
 			}
@@ -519,7 +836,7 @@ func (l Backend) GetForwardingHistory(ctx context.Context, startTime string, max
 				events[i].PeerAliasOut = val
 			} else {
 				events[i].PeerAliasOut, err = getPeerAlias(event.ChanIdOut)
-				if err != nil {
+				if err == nil {
 					cache[event.ChanIdOut] = events[i].PeerAliasOut
 				}
 			}
@@ -536,10 +853,12 @@ func (l Backend) GetForwardingHistory(ctx context.Context, startTime string, max
 	return result, nil
 }
 
-func (l Backend) CreateInvoice(ctx context.Context, amount int64, desc string) (*models.Invoice, error) {
-	l.logger.Debug("Create invoice...",
-		logging.Int64("amount", amount),
-		logging.String("desc", desc))
+// ClosedChannels returns the closed channels, the closing transactions
+// of the wallet give their date and their fee.
+func (l Backend) ClosedChannels(ctx context.Context) ([]*models.ClosedChannel, error) {
+	l.logger.Debug("List closed channels")
+	ctx, cancel := withTimeout(ctx, l.timeouts.ListChannels)
+	defer cancel()
 
 	clt, err := l.Client(ctx)
 	if err != nil {
@@ -547,141 +866,1342 @@ func (l Backend) CreateInvoice(ctx context.Context, amount int64, desc string) (
 	}
 	defer clt.Close()
 
-	req := &lnrpc.Invoice{
-		Value:        amount,
-		Memo:         desc,
-		CreationDate: time.Now().Unix(),
-		Expiry:       lndDefaultInvoiceExpiry,
+	resp, err := clt.ClosedChannels(ctx, &lnrpc.ClosedChannelsRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	resp, err := clt.AddInvoice(ctx, req)
+	txs, err := clt.GetTransactions(ctx, &lnrpc.GetTransactionsRequest{})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	index := make(map[string]*lnrpc.Transaction, len(txs.Transactions))
+	for _, tx := range txs.Transactions {
+		index[tx.TxHash] = tx
+	}
 
-	invoice := addInvoiceProtoToInvoice(req, resp)
-
-	l.logger.Debug("Invoice retrieved", logging.Object("invoice", invoice))
-
-	return invoice, nil
+	channels := make([]*models.ClosedChannel, len(resp.Channels))
+	for i, c := range resp.Channels {
+		channels[i] = closedChannelProtoToClosedChannel(c)
+		tx, ok := index[c.ClosingTxHash]
+		if !ok {
+			continue
+		}
+		channels[i].CloseTime = time.Unix(tx.TimeStamp, 0)
+		if channels[i].OpenInitiator == models.InitiatorLocal {
+			channels[i].ClosingFee = closingFee(c, tx.RawTxHex)
+		}
+	}
+	return channels, nil
 }
 
-func (l Backend) GetInvoice(ctx context.Context, RHash string) (*models.Invoice, error) {
-	l.logger.Debug("Retrieve invoice...", logging.String("r_hash", RHash))
+// PendingSweeps returns the outputs the sweeper of the wallet is trying
+// to sweep, e.g. the anchors and the time locked outputs of force closed
+// channels.
+func (l Backend) PendingSweeps(ctx context.Context) ([]*models.PendingSweep, error) {
+	l.logger.Debug("List pending sweeps")
 
-	clt, err := l.Client(ctx)
+	clt, err := l.WalletKitClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer clt.Close()
 
-	req := &lnrpc.PaymentHash{
-		RHashStr: RHash,
-	}
-
-	resp, err := clt.LookupInvoice(ctx, req)
+	resp, err := clt.PendingSweeps(ctx, &walletrpc.PendingSweepsRequest{})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	invoice := lookupInvoiceProtoToInvoice(resp)
+	sweeps := make([]*models.PendingSweep, len(resp.PendingSweeps))
+	for i := range resp.PendingSweeps {
+		sweeps[i] = pendingSweepProtoToPendingSweep(resp.PendingSweeps[i])
+	}
+	return sweeps, nil
+}
 
-	l.logger.Debug("Invoice retrieved", logging.Object("invoice", invoice))
+// BumpFee asks the sweeper to sweep the outpoint, "txid:index", at the
+// fee rate, without waiting for the next block.
+func (l Backend) BumpFee(ctx context.Context, outpoint string, satPerVbyte uint64) error {
+	l.logger.Debug("Bump fee", logging.String("outpoint", outpoint))
 
-	return invoice, nil
+	op, err := outpointToProto(outpoint)
+	if err != nil {
+		return err
+	}
+
+	clt, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.BumpFee(ctx, &walletrpc.BumpFeeRequest{
+		Outpoint:    op,
+		SatPerVbyte: satPerVbyte,
+		Immediate:   true,
+	})
+	return errors.WithStack(err)
 }
 
-func (l Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
-	l.logger.Debug("Send payment...",
-		logging.String("destination", payreq.Destination),
-		logging.Int64("amount", payreq.Amount),
-	)
+// Watchtower returns the towers of the watchtower client with their
+// sessions, the backup counts and the policies of the new sessions. The
+// client must be enabled with wtclient.active.
+func (l Backend) Watchtower(ctx context.Context) (*models.Watchtower, error) {
+	l.logger.Debug("Get watchtower client")
 
-	clt, err := l.Client(ctx)
+	clt, err := l.WatchtowerClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer clt.Close()
 
-	req := &lnrpc.SendRequest{PaymentRequest: payreq.String}
-
-	resp, err := clt.SendPaymentSync(ctx, req)
+	resp, err := clt.ListTowers(ctx, &wtclientrpc.ListTowersRequest{IncludeSessions: true})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	payment := sendPaymentProtoToPayment(payreq, resp)
+	stats, err := clt.Stats(ctx, &wtclientrpc.StatsRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-	l.logger.Debug("Payment paid", logging.Object("payment", payment))
+	wt := &models.Watchtower{
+		Towers: make([]*models.Tower, len(resp.Towers)),
+		Stats: models.TowerStats{
+			NumBackups:           stats.NumBackups,
+			NumPendingBackups:    stats.NumPendingBackups,
+			NumFailedBackups:     stats.NumFailedBackups,
+			NumSessionsAcquired:  stats.NumSessionsAcquired,
+			NumSessionsExhausted: stats.NumSessionsExhausted,
+		},
+	}
+	for i := range resp.Towers {
+		wt.Towers[i] = towerProtoToTower(resp.Towers[i])
+	}
 
-	return payment, nil
+	for _, t := range []wtclientrpc.PolicyType{wtclientrpc.PolicyType_LEGACY, wtclientrpc.PolicyType_ANCHOR} {
+		policy, err := clt.Policy(ctx, &wtclientrpc.PolicyRequest{PolicyType: t})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		wt.Policies = append(wt.Policies, &models.TowerPolicy{
+			Type:             towerPolicyName(t),
+			MaxUpdates:       policy.MaxUpdates,
+			SweepSatPerVbyte: policy.SweepSatPerVbyte,
+		})
+	}
+	return wt, nil
 }
 
-func (l Backend) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
-	l.logger.Info("decode payreq", logging.String("payreq", payreq))
-	clt, err := l.Client(ctx)
+// AddTower registers the tower of the pubkey at the host:port, or adds
+// the address to a registered tower.
+func (l Backend) AddTower(ctx context.Context, pubkey, address string) error {
+	l.logger.Debug("Add tower", logging.String("pubkey", pubkey))
+
+	key, err := hex.DecodeString(pubkey)
 	if err != nil {
-		return nil, err
+		return errors.WithStack(err)
 	}
-	defer clt.Close()
 
-	resp, err := clt.DecodePayReq(ctx, &lnrpc.PayReqString{PayReq: payreq})
+	clt, err := l.WatchtowerClient(ctx)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return err
 	}
+	defer clt.Close()
 
-	return payreqProtoToPayReq(resp, payreq), nil
+	_, err = clt.AddTower(ctx, &wtclientrpc.AddTowerRequest{Pubkey: key, Address: address})
+	return errors.WithStack(err)
 }
 
-func New(c *config.Network, logger logging.Logger) (*Backend, error) {
-	var err error
+// RemoveTower removes the tower of the pubkey, its sessions are not
+// used for new backups.
+func (l Backend) RemoveTower(ctx context.Context, pubkey string) error {
+	l.logger.Debug("Remove tower", logging.String("pubkey", pubkey))
 
-	backend := &Backend{
-		cfg:    c,
-		logger: logger.With(logging.String("name", c.Name)),
+	key, err := hex.DecodeString(pubkey)
+	if err != nil {
+		return errors.WithStack(err)
 	}
 
-	if c.PoolCapacity < lndMinPoolCapacity {
-		c.PoolCapacity = lndMinPoolCapacity
-		logger.Info("pool_capacity too small, ignoring")
-	}
-	backend.pool, err = pool.New(backend.NewClientConn, c.PoolCapacity, time.Duration(c.ConnTimeout))
+	clt, err := l.WatchtowerClient(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer clt.Close()
 
-	return backend, nil
-}
-
-// reTimeRange matches systemd.time-like short negative timeranges, e.g. "-200s".
-var reTimeRange = regexp.MustCompile(`^-\d{1,18}[s|m|h|d|w|M|y]$`)
-
-// secondsPer allows translating s(seconds), m(minutes), h(ours), d(ays),
-// w(eeks), M(onths) and y(ears) into corresponding seconds.
-var secondsPer = map[string]int64{
-	"s": 1,
-	"m": 60,
-	"h": 3600,
-	"d": 86400,
-	"w": 604800,
-	"M": 2630016,  // 30.44 days
-	"y": 31557600, // 365.25 days
+	_, err = clt.RemoveTower(ctx, &wtclientrpc.RemoveTowerRequest{Pubkey: key})
+	return errors.WithStack(err)
 }
 
-// parseTime parses UNIX timestamps or short timeranges inspired by systemd
-// (when starting with "-"), e.g. "-1M" for one month (30.44 days) ago.
-func parseTime(s string, base time.Time) (uint64, error) {
-	if reTimeRange.MatchString(s) {
-		last := len(s) - 1
-
-		d, err := strconv.ParseInt(s[1:last], 10, 64)
-		if err != nil {
-			return uint64(0), err
-		}
+// MissionControl returns the pairs of the mission control of the router.
+func (l Backend) MissionControl(ctx context.Context) ([]*models.MissionPair, error) {
+	l.logger.Debug("Query mission control")
 
-		mul := secondsPer[string(s[last])]
-		return uint64(base.Unix() - d*mul), nil
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer clt.Close()
 
-	return strconv.ParseUint(s, 10, 64)
+	resp, err := clt.QueryMissionControl(ctx, &routerrpc.QueryMissionControlRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pairs := make([]*models.MissionPair, len(resp.Pairs))
+	for i := range resp.Pairs {
+		pairs[i] = pairProtoToMissionPair(resp.Pairs[i])
+	}
+	return pairs, nil
+}
+
+// PairProbability returns the probability of the router to forward the
+// amount in msat from the first node to the second one.
+func (l Backend) PairProbability(ctx context.Context, from, to string, amtMsat int64) (float64, error) {
+	fromNode, err := hex.DecodeString(from)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	toNode, err := hex.DecodeString(to)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.QueryProbability(ctx, &routerrpc.QueryProbabilityRequest{
+		FromNode: fromNode,
+		ToNode:   toNode,
+		AmtMsat:  amtMsat,
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return resp.Probability, nil
+}
+
+// ResetMissionControl clears the mission control of the router, the
+// routes are estimated with the apriori probability again.
+func (l Backend) ResetMissionControl(ctx context.Context) error {
+	l.logger.Debug("Reset mission control")
+
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.ResetMissionControl(ctx, &routerrpc.ResetMissionControlRequest{})
+	return errors.WithStack(err)
+}
+
+// ImportMissionControl imports the pairs in the mission control of the
+// router with XImportMissionControl.
+func (l Backend) ImportMissionControl(ctx context.Context, pairs []*models.MissionPair, force bool) error {
+	l.logger.Debug("Import mission control", logging.Int("pairs", len(pairs)))
+
+	req := &routerrpc.XImportMissionControlRequest{
+		Pairs: make([]*routerrpc.PairHistory, len(pairs)),
+		Force: force,
+	}
+	for i := range pairs {
+		pair, err := missionPairToProto(pairs[i])
+		if err != nil {
+			return err
+		}
+		req.Pairs[i] = pair
+	}
+
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.XImportMissionControl(ctx, req)
+	return errors.WithStack(err)
+}
+
+func (l Backend) ListUnspent(ctx context.Context) ([]*models.UTXO, error) {
+	l.logger.Debug("List unspent")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.ListUnspent(ctx, &lnrpc.ListUnspentRequest{MaxConfs: math.MaxInt32})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// lnd labels transactions, the label of an output is the label of
+	// the transaction that created it.
+	txs, err := clt.GetTransactions(ctx, &lnrpc.GetTransactionsRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	index := make(map[string]*lnrpc.Transaction, len(txs.Transactions))
+	for _, tx := range txs.Transactions {
+		index[tx.TxHash] = tx
+	}
+
+	utxos := make([]*models.UTXO, len(resp.Utxos))
+	for i := range resp.Utxos {
+		utxos[i] = utxoProtoToUTXO(resp.Utxos[i])
+		if tx, ok := index[utxos[i].TxID()]; ok {
+			utxos[i].Label = tx.Label
+		}
+	}
+
+	// the leased outputs are not listed as unspent, they are added from
+	// the outputs of their transactions.
+	wk, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer wk.Close()
+
+	leases, err := wk.ListLeases(ctx, &walletrpc.ListLeasesRequest{})
+	if err != nil {
+		l.logger.Debug("cannot list leases", logging.Error(err))
+		return utxos, nil
+	}
+	for _, lease := range leases.LockedUtxos {
+		if lease.Outpoint == nil {
+			continue
+		}
+		utxo := leaseProtoToUTXO(lease, index[lease.Outpoint.TxidStr])
+		found := false
+		for i := range utxos {
+			if utxos[i].Outpoint == utxo.Outpoint {
+				utxos[i].LeaseID = utxo.LeaseID
+				utxos[i].LeaseExpiration = utxo.LeaseExpiration
+				found = true
+			}
+		}
+		if !found {
+			utxos = append(utxos, utxo)
+		}
+	}
+	return utxos, nil
+}
+
+// LabelTransaction sets the label of a transaction of the wallet, and so
+// of its outputs.
+func (l Backend) LabelTransaction(ctx context.Context, txid, label string) error {
+	l.logger.Debug("Label transaction", logging.String("txid", txid))
+
+	hash, err := txidToBytes(txid)
+	if err != nil {
+		return err
+	}
+
+	clt, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.LabelTransaction(ctx, &walletrpc.LabelTransactionRequest{
+		Txid:      hash,
+		Label:     label,
+		Overwrite: true,
+	})
+	return errors.WithStack(err)
+}
+
+// UpdateChannelPolicy sets the routing policy of the node for the channel,
+// lnd announces it to the network.
+func (l Backend) UpdateChannelPolicy(ctx context.Context, channel *models.Channel, policy *models.RoutingPolicy) error {
+	l.logger.Debug("Update channel policy", logging.String("channel_point", channel.ChannelPoint))
+
+	point, err := channelPointToProto(channel.ChannelPoint)
+	if err != nil {
+		return err
+	}
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	resp, err := clt.UpdateChannelPolicy(ctx, &lnrpc.PolicyUpdateRequest{
+		Scope:                &lnrpc.PolicyUpdateRequest_ChanPoint{ChanPoint: point},
+		BaseFeeMsat:          policy.FeeBaseMsat,
+		FeeRatePpm:           uint32(policy.FeeRateMilliMsat),
+		TimeLockDelta:        policy.TimeLockDelta,
+		MinHtlcMsat:          uint64(policy.MinHtlc),
+		MinHtlcMsatSpecified: true,
+		MaxHtlcMsat:          policy.MaxHtlc,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(resp.FailedUpdates) > 0 {
+		return errors.Errorf("policy update failed: %s", resp.FailedUpdates[0].UpdateError)
+	}
+	return nil
+}
+
+// CloseChannel asks lnd to close the channel and returns once the closing
+// transaction is published, lnd keeps the close going.
+func (l Backend) CloseChannel(ctx context.Context, channel *models.Channel, force bool, satPerVbyte uint64) (string, error) {
+	l.logger.Debug("Close channel", logging.String("channel_point", channel.ChannelPoint),
+		logging.Bool("force", force))
+
+	point, err := channelPointToProto(channel.ChannelPoint)
+	if err != nil {
+		return "", err
+	}
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	req := &lnrpc.CloseChannelRequest{ChannelPoint: point, Force: force}
+	if !force {
+		req.SatPerVbyte = satPerVbyte
+	}
+	stream, err := clt.CloseChannel(ctx, req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for {
+		update, err := stream.Recv()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if pending := update.GetClosePending(); pending != nil {
+			return txidProtoToString(pending.Txid), nil
+		}
+		if closed := update.GetChanClose(); closed != nil {
+			return txidProtoToString(closed.ClosingTxid), nil
+		}
+	}
+}
+
+// fundAndPublish funds a PSBT spending exactly the inputs to the
+// outputs, a change output is added by the wallet if needed, then signs
+// and publishes it. The inputs are released if it fails.
+func (l Backend) fundAndPublish(ctx context.Context, clt *WalletKitClient, inputs []*lnrpc.OutPoint,
+	outputs map[string]uint64, satPerVbyte uint64, label string) (*wire.MsgTx, error) {
+	funded, err := clt.FundPsbt(ctx, &walletrpc.FundPsbtRequest{
+		Template: &walletrpc.FundPsbtRequest_Raw{Raw: &walletrpc.TxTemplate{
+			Inputs:  inputs,
+			Outputs: outputs,
+		}},
+		Fees:             &walletrpc.FundPsbtRequest_SatPerVbyte{SatPerVbyte: satPerVbyte},
+		SpendUnconfirmed: true,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	release := func() {
+		for _, lease := range funded.LockedUtxos {
+			_, err := clt.ReleaseOutput(ctx, &walletrpc.ReleaseOutputRequest{
+				Id:       lease.Id,
+				Outpoint: lease.Outpoint,
+			})
+			if err != nil {
+				l.logger.Debug("cannot release output", logging.Error(err))
+			}
+		}
+	}
+
+	final, err := clt.FinalizePsbt(ctx, &walletrpc.FinalizePsbtRequest{FundedPsbt: funded.FundedPsbt})
+	if err != nil {
+		release()
+		return nil, errors.WithStack(err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	err = tx.Deserialize(bytes.NewReader(final.RawFinalTx))
+	if err != nil {
+		release()
+		return nil, errors.WithStack(err)
+	}
+
+	_, err = clt.PublishTransaction(ctx, &walletrpc.Transaction{
+		TxHex: final.RawFinalTx,
+		Label: label,
+	})
+	if err != nil {
+		release()
+		return nil, errors.WithStack(err)
+	}
+	return tx, nil
+}
+
+// Consolidate spends the outputs to a new change address of the wallet,
+// the output pays the estimated fee so the wallet adds no change.
+func (l Backend) Consolidate(ctx context.Context, utxos []*models.UTXO, satPerVbyte uint64) (*models.Consolidation, error) {
+	l.logger.Debug("Consolidate", logging.Int("inputs", len(utxos)))
+
+	fee, amount, err := models.ConsolidationFee(utxos, satPerVbyte)
+	if err != nil {
+		return nil, err
+	}
+
+	outpoints := make([]string, len(utxos))
+	for i := range utxos {
+		outpoints[i] = utxos[i].Outpoint
+	}
+	inputs, err := outpointsToProto(outpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	clt, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	addr, err := clt.NextAddr(ctx, &walletrpc.AddrRequest{
+		Type:   walletrpc.AddressType_WITNESS_PUBKEY_HASH,
+		Change: true,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tx, err := l.fundAndPublish(ctx, clt, inputs,
+		map[string]uint64{addr.Addr: uint64(amount)}, satPerVbyte, "lntop: consolidation")
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Consolidation{
+		TxID:    tx.TxHash().String(),
+		Address: addr.Addr,
+		Inputs:  len(utxos),
+		Amount:  amount,
+		Fee:     fee,
+	}, nil
+}
+
+// SendOnChain sends the amount to the address spending only the given
+// outputs of the wallet, or selecting them if there are none, and
+// returns the txid.
+func (l Backend) SendOnChain(ctx context.Context, address string, amount int64, satPerVbyte uint64, outpoints []string) (string, error) {
+	l.logger.Debug("Send on chain", logging.String("address", address),
+		logging.Int("inputs", len(outpoints)))
+
+	inputs, err := outpointsToProto(outpoints)
+	if err != nil {
+		return "", err
+	}
+
+	clt, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	tx, err := l.fundAndPublish(ctx, clt, inputs,
+		map[string]uint64{address: uint64(amount)}, satPerVbyte, "")
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+// OpenChannel opens a channel with the connected peer funded by the
+// given outputs of the wallet, or by outputs selected by the wallet if
+// there are none, and returns the channel point.
+func (l Backend) OpenChannel(ctx context.Context, pubkey string, amount int64, satPerVbyte uint64,
+	private bool, outpoints []string) (string, error) {
+	l.logger.Debug("Open channel", logging.String("pubkey", pubkey),
+		logging.Int("inputs", len(outpoints)))
+
+	inputs, err := outpointsToProto(outpoints)
+	if err != nil {
+		return "", err
+	}
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	point, err := clt.OpenChannelSync(ctx, &lnrpc.OpenChannelRequest{
+		NodePubkeyString:   pubkey,
+		LocalFundingAmount: amount,
+		SatPerVbyte:        satPerVbyte,
+		Private:            private,
+		Outpoints:          inputs,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return channelPointProtoToString(point)
+}
+
+// EstimateFeeRate returns the fee rate estimated by the wallet, converted
+// from sat/kw.
+func (l Backend) EstimateFeeRate(ctx context.Context, target uint32) (uint64, error) {
+	clt, err := l.WalletKitClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.EstimateFee(ctx, &walletrpc.EstimateFeeRequest{ConfTarget: int32(target)})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return uint64(resp.SatPerKw) * 4 / 1000, nil
+}
+
+// EstimateBatchOpen estimates the fee of the funding transaction with the
+// coin selection of the wallet, the funding outputs are estimated as
+// p2wsh outputs of the same size.
+func (l Backend) EstimateBatchOpen(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (*models.FeeEstimate, error) {
+	l.logger.Debug("Estimate batch open", logging.Int("channels", len(channels)))
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	info, err := clt.GetInfo(ctx, &lnrpc.GetInfoRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	params, err := chainParams(infoProtoToInfo(info).Network)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]int64, len(channels))
+	for i, ch := range channels {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("lntop batch funding %d", i)))
+		addr, err := btcutil.NewAddressWitnessScriptHash(hash[:], params)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		outputs[addr.EncodeAddress()] = ch.Amount
+	}
+
+	resp, err := clt.EstimateFee(ctx, &lnrpc.EstimateFeeRequest{
+		AddrToAmount: outputs,
+		TargetConf:   lndBatchConfTarget,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	estimate := &models.FeeEstimate{Fee: resp.FeeSat, SatPerVbyte: resp.SatPerVbyte}
+	if satPerVbyte > 0 && resp.SatPerVbyte > 0 {
+		vsize := (resp.FeeSat + int64(resp.SatPerVbyte) - 1) / int64(resp.SatPerVbyte)
+		estimate = &models.FeeEstimate{Fee: vsize * int64(satPerVbyte), SatPerVbyte: satPerVbyte}
+	}
+	return estimate, nil
+}
+
+// BatchOpenChannel opens the channels with BatchOpenChannel, the peers
+// must be connected.
+func (l Backend) BatchOpenChannel(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (string, error) {
+	l.logger.Debug("Batch open channels", logging.Int("channels", len(channels)))
+
+	req := &lnrpc.BatchOpenChannelRequest{
+		Channels:    make([]*lnrpc.BatchOpenChannel, len(channels)),
+		SatPerVbyte: int64(satPerVbyte),
+	}
+	if satPerVbyte == 0 {
+		req.TargetConf = lndBatchConfTarget
+	}
+	for i, ch := range channels {
+		pubkey, err := hex.DecodeString(ch.PubKey)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		req.Channels[i] = &lnrpc.BatchOpenChannel{
+			NodePubkey:         pubkey,
+			LocalFundingAmount: ch.Amount,
+			Private:            ch.Private,
+		}
+	}
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	resp, err := clt.BatchOpenChannel(ctx, req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(resp.PendingChannels) == 0 {
+		return "", errors.New("batch without pending channel")
+	}
+	return txidProtoToString(resp.PendingChannels[0].Txid), nil
+}
+
+func (l Backend) ListPeers(ctx context.Context) ([]*models.Peer, error) {
+	l.logger.Debug("List peers")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.ListPeers(ctx, &lnrpc.ListPeersRequest{LatestError: true})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return listPeersProtoToPeers(resp), nil
+}
+
+func (l Backend) ConnectPeer(ctx context.Context, pubkey, host string) error {
+	l.logger.Debug("Connect peer", logging.String("pubkey", pubkey), logging.String("host", host))
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.ConnectPeer(ctx, &lnrpc.ConnectPeerRequest{
+		Addr:    &lnrpc.LightningAddress{Pubkey: pubkey, Host: host},
+		Timeout: lndConnectTimeout,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (l Backend) DisconnectPeer(ctx context.Context, pubkey string) error {
+	l.logger.Debug("Disconnect peer", logging.String("pubkey", pubkey))
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	_, err = clt.DisconnectPeer(ctx, &lnrpc.DisconnectPeerRequest{PubKey: pubkey})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (l Backend) CreateInvoice(ctx context.Context, amount int64, desc string) (*models.Invoice, error) {
+	l.logger.Debug("Create invoice...",
+		logging.Int64("amount", amount),
+		logging.String("desc", desc))
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	req := &lnrpc.Invoice{
+		Value:        amount,
+		Memo:         desc,
+		CreationDate: time.Now().Unix(),
+		Expiry:       lndDefaultInvoiceExpiry,
+	}
+
+	resp, err := clt.AddInvoice(ctx, req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	invoice := addInvoiceProtoToInvoice(req, resp)
+
+	l.logger.Debug("Invoice retrieved", logging.Object("invoice", invoice))
+
+	return invoice, nil
+}
+
+func (l Backend) NewAddress(ctx context.Context) (string, error) {
+	l.logger.Debug("New address...")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	resp, err := clt.NewAddress(ctx, &lnrpc.NewAddressRequest{
+		Type: lnrpc.AddressType_WITNESS_PUBKEY_HASH,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return resp.Address, nil
+}
+
+func (l Backend) GetInvoice(ctx context.Context, RHash string) (*models.Invoice, error) {
+	l.logger.Debug("Retrieve invoice...", logging.String("r_hash", RHash))
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	req := &lnrpc.PaymentHash{
+		RHashStr: RHash,
+	}
+
+	resp, err := clt.LookupInvoice(ctx, req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	invoice := lookupInvoiceProtoToInvoice(resp)
+
+	l.logger.Debug("Invoice retrieved", logging.Object("invoice", invoice))
+
+	return invoice, nil
+}
+
+// ListInvoices reads the invoices by pages from the last one, up to
+// lndMaxInvoices.
+func (l Backend) ListInvoices(ctx context.Context) ([]*models.Invoice, error) {
+	l.logger.Debug("List invoices")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	invoices := []*models.Invoice{}
+	req := &lnrpc.ListInvoiceRequest{
+		NumMaxInvoices: lndInvoicesPageSize,
+		Reversed:       true,
+	}
+	for {
+		resp, err := clt.ListInvoices(ctx, req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		page := make([]*models.Invoice, len(resp.Invoices))
+		for i := range resp.Invoices {
+			page[i] = lookupInvoiceProtoToInvoice(resp.Invoices[i])
+		}
+		invoices = append(page, invoices...)
+		if len(resp.Invoices) < lndInvoicesPageSize || len(invoices) >= lndMaxInvoices {
+			break
+		}
+		req.IndexOffset = resp.FirstIndexOffset
+	}
+	return invoices, nil
+}
+
+// AllInvoices reads all the invoices by pages from the first one, for the
+// exports reading the whole history of the node.
+func (l Backend) AllInvoices(ctx context.Context) ([]*models.Invoice, error) {
+	l.logger.Debug("List all invoices")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	invoices := []*models.Invoice{}
+	req := &lnrpc.ListInvoiceRequest{NumMaxInvoices: lndInvoicesPageSize}
+	for {
+		resp, err := clt.ListInvoices(ctx, req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := range resp.Invoices {
+			invoices = append(invoices, lookupInvoiceProtoToInvoice(resp.Invoices[i]))
+		}
+		if len(resp.Invoices) < lndInvoicesPageSize {
+			break
+		}
+		req.IndexOffset = resp.LastIndexOffset
+	}
+	return invoices, nil
+}
+
+// SendPayment pays the payment request with the router, at the fee limit
+// of lncli, and waits for the payment to succeed or fail.
+func (l Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
+	l.logger.Debug("Send payment...",
+		logging.String("destination", payreq.Destination),
+		logging.Int64("amount", payreq.Amount),
+	)
+
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	stream, err := clt.SendPaymentV2(ctx, &routerrpc.SendPaymentRequest{
+		PaymentRequest: payreq.String,
+		TimeoutSeconds: lndPaymentTimeout,
+		FeeLimitSat:    paymentFeeLimit(payreq.Amount),
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if resp.Status != lnrpc.Payment_SUCCEEDED && resp.Status != lnrpc.Payment_FAILED {
+			continue
+		}
+		payment := paymentProtoToPayment(resp)
+		payment.PayReq = payreq
+
+		l.logger.Debug("Payment done", logging.Object("payment", payment))
+
+		return payment, nil
+	}
+}
+
+// SendKeysend pays the destination without invoice at the fee limit of
+// lncli. A keysend carries the preimage of a random hash to the
+// destination, an AMP payment lets the router derive them.
+func (l Backend) SendKeysend(ctx context.Context, k *models.Keysend, updates chan *models.Payment) (*models.Payment, error) {
+	l.logger.Debug("Send keysend...",
+		logging.String("destination", k.Destination),
+		logging.Int64("amount", k.Amount),
+		logging.Bool("amp", k.AMP),
+	)
+
+	dest, err := hex.DecodeString(k.Destination)
+	if err != nil || len(dest) != 33 {
+		return nil, errors.Errorf("invalid pubkey %q", k.Destination)
+	}
+
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	req := &routerrpc.SendPaymentRequest{
+		Dest:              dest,
+		Amt:               k.Amount,
+		TimeoutSeconds:    lndPaymentTimeout,
+		FeeLimitSat:       paymentFeeLimit(k.Amount),
+		DestCustomRecords: map[uint64][]byte{},
+		Amp:               k.AMP,
+	}
+	if k.Message != "" {
+		req.DestCustomRecords[lndMessageRecord] = []byte(k.Message)
+	}
+	if !k.AMP {
+		preimage := make([]byte, 32)
+		_, err = rand.Read(preimage)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		hash := sha256.Sum256(preimage)
+		req.DestCustomRecords[lndKeysendRecord] = preimage
+		req.PaymentHash = hash[:]
+	}
+
+	stream, err := clt.SendPaymentV2(ctx, req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		payment := paymentProtoToPayment(resp)
+		if payment.Status == models.PaymentInFlight {
+			select {
+			case updates <- payment:
+			case <-ctx.Done():
+				return nil, errors.WithStack(ctx.Err())
+			}
+			continue
+		}
+
+		l.logger.Debug("Keysend done", logging.Object("payment", payment))
+
+		return payment, nil
+	}
+}
+
+// paymentFeeLimit is the default fee limit of lncli: the amount up to
+// 1000 sat, 5% of it above.
+func paymentFeeLimit(amount int64) int64 {
+	if amount <= 1000 {
+		return amount
+	}
+	return amount * 5 / 100
+}
+
+// Rebalance pays an invoice of the node over routes built from the
+// outgoing channel back through the incoming one, up to
+// lndRebalanceAttempts of them. Mission control learns from the failed
+// attempts and the channel failing one is ignored by the next queries.
+func (l Backend) Rebalance(ctx context.Context, out, in *models.Channel, amount, maxFee int64, attempts chan *models.RebalanceAttempt) (*models.Payment, error) {
+	l.logger.Debug("Rebalance...",
+		logging.Uint64("out", out.ID),
+		logging.Uint64("in", in.ID),
+		logging.Int64("amount", amount),
+		logging.Int64("max_fee", maxFee),
+	)
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	router, err := l.RouterClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer router.Close()
+
+	info, err := clt.GetInfo(ctx, &lnrpc.GetInfoRequest{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	self, err := hex.DecodeString(info.IdentityPubkey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	invoice, err := clt.AddInvoice(ctx, &lnrpc.Invoice{
+		Value:      amount,
+		Memo:       "lntop rebalance",
+		Expiry:     lndDefaultInvoiceExpiry,
+		CltvExpiry: lndRebalanceCltvDelta,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payment := &models.Payment{
+		Hash:         hex.EncodeToString(invoice.RHash),
+		Status:       models.PaymentFailed,
+		Amount:       amount,
+		CreationDate: time.Now(),
+	}
+
+	// the peer of the incoming channel takes a fee to forward the payment
+	// back, the routes queried to it must leave room for it.
+	lastFee := int64(0)
+	if in.RemotePolicy != nil {
+		lastFee = (in.RemotePolicy.FeeBaseMsat + amount*in.RemotePolicy.FeeRateMilliMsat/1000 + 999) / 1000
+	}
+	if lastFee > maxFee {
+		payment.PaymentError = fmt.Sprintf("the incoming channel takes a fee of %d sat", lastFee)
+		return payment, nil
+	}
+
+	ignored := []*lnrpc.NodePair{}
+	for i := 1; i <= lndRebalanceAttempts; i++ {
+		routes, err := clt.QueryRoutes(ctx, &lnrpc.QueryRoutesRequest{
+			PubKey:            in.RemotePubKey,
+			Amt:               amount,
+			OutgoingChanId:    out.ID,
+			FeeLimit:          &lnrpc.FeeLimit{Limit: &lnrpc.FeeLimit_Fixed{Fixed: maxFee - lastFee}},
+			UseMissionControl: true,
+			IgnoredPairs:      ignored,
+		})
+		if ctx.Err() != nil {
+			return nil, errors.WithStack(ctx.Err())
+		}
+		if err != nil || len(routes.Routes) == 0 {
+			payment.PaymentError = "no route"
+			return payment, nil
+		}
+
+		hops := routes.Routes[0].Hops
+		pubkeys := make([][]byte, 0, len(hops)+1)
+		for _, h := range hops {
+			pubkey, err := hex.DecodeString(h.PubKey)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			pubkeys = append(pubkeys, pubkey)
+		}
+		built, err := router.BuildRoute(ctx, &routerrpc.BuildRouteRequest{
+			AmtMsat:        amount * 1000,
+			FinalCltvDelta: lndRebalanceCltvDelta,
+			OutgoingChanId: out.ID,
+			HopPubkeys:     append(pubkeys, self),
+			PaymentAddr:    invoice.PaymentAddr,
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		route := built.Route
+		attempt := &models.RebalanceAttempt{
+			Number: i,
+			Status: models.PaymentInFlight,
+			Route:  routeProtoToRoute(route),
+		}
+		switch {
+		case route.Hops[len(route.Hops)-1].ChanId != in.ID:
+			// lnd picks the channel of the last hop among the ones
+			// with the peer.
+			attempt.Error = "the route goes through another channel of the peer"
+		case route.TotalFeesMsat > maxFee*1000:
+			attempt.Error = fmt.Sprintf("the route costs %d sat", route.TotalFees)
+		}
+		if attempt.Error != "" {
+			attempt.Status = models.PaymentFailed
+			sendRebalanceAttempt(ctx, attempts, attempt)
+			payment.PaymentError = attempt.Error
+			return payment, nil
+		}
+		sendRebalanceAttempt(ctx, attempts, attempt)
+
+		htlc, err := router.SendToRouteV2(ctx, &routerrpc.SendToRouteRequest{
+			PaymentHash: invoice.RHash,
+			Route:       route,
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if htlc.Status == lnrpc.HTLCAttempt_SUCCEEDED {
+			attempt.Status = models.PaymentSucceeded
+			sendRebalanceAttempt(ctx, attempts, attempt)
+			payment.Status = models.PaymentSucceeded
+			payment.Fee = route.TotalFees
+			payment.Route = attempt.Route
+			payment.PaymentPreimage = htlc.Preimage
+
+			l.logger.Debug("Rebalance done", logging.Object("payment", payment))
+
+			return payment, nil
+		}
+
+		attempt.Status = models.PaymentFailed
+		attempt.Error = htlcFailureReason(htlc.Failure)
+		sendRebalanceAttempt(ctx, attempts, attempt)
+		payment.PaymentError = attempt.Error
+
+		// the node at the index failed to forward over the hop of the
+		// index, the failures of the node, of the peer of the incoming
+		// channel and of the final hop are final.
+		index := int(htlc.Failure.GetFailureSourceIndex())
+		if htlc.Failure == nil || index == 0 || index >= len(route.Hops)-1 {
+			return payment, nil
+		}
+		from, _ := hex.DecodeString(route.Hops[index-1].PubKey)
+		to, _ := hex.DecodeString(route.Hops[index].PubKey)
+		ignored = append(ignored, &lnrpc.NodePair{From: from, To: to})
+	}
+	return payment, nil
+}
+
+// sendRebalanceAttempt sends a copy of the attempt, updated afterwards.
+func sendRebalanceAttempt(ctx context.Context, attempts chan *models.RebalanceAttempt, attempt *models.RebalanceAttempt) {
+	a := *attempt
+	select {
+	case attempts <- &a:
+	case <-ctx.Done():
+	}
+}
+
+// ListPayments returns the last lndMaxPayments payments, the incomplete
+// ones included.
+func (l Backend) ListPayments(ctx context.Context) ([]*models.Payment, error) {
+	l.logger.Debug("List payments")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.ListPayments(ctx, &lnrpc.ListPaymentsRequest{
+		IncludeIncomplete: true,
+		Reversed:          true,
+		MaxPayments:       lndMaxPayments,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payments := make([]*models.Payment, len(resp.Payments))
+	for i := range resp.Payments {
+		payments[i] = paymentProtoToPayment(resp.Payments[i])
+	}
+	return payments, nil
+}
+
+// AllPayments reads all the payments by pages from the first one, the
+// incomplete ones included.
+func (l Backend) AllPayments(ctx context.Context) ([]*models.Payment, error) {
+	l.logger.Debug("List all payments")
+
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	payments := []*models.Payment{}
+	req := &lnrpc.ListPaymentsRequest{
+		IncludeIncomplete: true,
+		MaxPayments:       lndPaymentsPageSize,
+	}
+	for {
+		resp, err := clt.ListPayments(ctx, req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := range resp.Payments {
+			payments = append(payments, paymentProtoToPayment(resp.Payments[i]))
+		}
+		if len(resp.Payments) < lndPaymentsPageSize {
+			break
+		}
+		req.IndexOffset = resp.LastIndexOffset
+	}
+	return payments, nil
+}
+
+// SubscribePayments sends the payments of the node at each of their
+// updates, the ones sent by other clients of lnd included.
+func (l Backend) SubscribePayments(ctx context.Context, channel chan *models.Payment) error {
+	clt, err := l.RouterClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer clt.Close()
+
+	stream, err := clt.TrackPayments(ctx, &routerrpc.TrackPaymentsRequest{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			payment, err := stream.Recv()
+			if err != nil {
+				st, ok := status.FromError(err)
+				if ok && st.Code() == codes.Canceled {
+					l.logger.Debug("stopping payments subscription: context canceled")
+					return nil
+				}
+				return err
+			}
+
+			channel <- paymentProtoToPayment(payment)
+		}
+	}
+}
+
+func (l Backend) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
+	l.logger.Info("decode payreq", logging.String("payreq", payreq))
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.DecodePayReq(ctx, &lnrpc.PayReqString{PayReq: payreq})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return payreqProtoToPayReq(resp, payreq), nil
+}
+
+func (l Backend) ListOffers(context.Context) ([]*models.Offer, error) {
+	return nil, errNoOffers
+}
+
+func (l Backend) CreateOffer(context.Context, int64, string) (*models.Offer, error) {
+	return nil, errNoOffers
+}
+
+func (l Backend) DisableOffer(context.Context, string) error {
+	return errNoOffers
+}
+
+func (l Backend) SignMessage(ctx context.Context, message string) (string, error) {
+	l.logger.Debug("Sign message...")
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer clt.Close()
+
+	resp, err := clt.SignMessage(ctx, &lnrpc.SignMessageRequest{Msg: []byte(message)})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return resp.Signature, nil
+}
+
+func (l Backend) VerifyMessage(ctx context.Context, message, signature string) (*models.VerifiedMessage, error) {
+	l.logger.Debug("Verify message...")
+	clt, err := l.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clt.Close()
+
+	resp, err := clt.VerifyMessage(ctx, &lnrpc.VerifyMessageRequest{
+		Msg:       []byte(message),
+		Signature: signature,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &models.VerifiedMessage{Valid: resp.Valid, PubKey: resp.Pubkey}, nil
+}
+
+func New(c *config.Network, logger logging.Logger) (*Backend, error) {
+	timeouts, err := c.Timeouts()
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &Backend{
+		cfg:      c,
+		timeouts: timeouts,
+		logger:   logger.With(logging.String("name", c.Name)),
+	}
+
+	if c.PoolCapacity < lndMinPoolCapacity {
+		c.PoolCapacity = lndMinPoolCapacity
+		logger.Info("pool_capacity too small, ignoring")
+	}
+	backend.pool, err = pool.New(backend.NewClientConn, c.PoolCapacity, time.Duration(c.ConnTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	return backend, nil
 }
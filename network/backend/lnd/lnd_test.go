@@ -0,0 +1,34 @@
+package lnd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edouardparis/lntop/network/backend/regtest"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+func TestListChannels(t *testing.T) {
+	h := regtest.Setup(t)
+	h.OpenChannel(t, h.Alice, h.Bob, 1000000)
+
+	for _, tt := range []struct {
+		node, peer *regtest.Node
+	}{
+		{h.Alice, h.Bob},
+		{h.Bob, h.Alice},
+	} {
+		t.Run(tt.node.Name, func(t *testing.T) {
+			channels, err := tt.node.Backend.ListChannels(context.Background(), options.WithChannelPending)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ch := range channels {
+				if ch.RemotePubKey == tt.peer.PubKey && ch.Capacity == 1000000 && ch.ID != 0 {
+					return
+				}
+			}
+			t.Errorf("no channel of 1000000 sat with %s in %d channels", tt.peer.Name, len(channels))
+		})
+	}
+}
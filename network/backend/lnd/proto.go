@@ -1,12 +1,21 @@
 package lnd
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/wtclientrpc"
+	"github.com/pkg/errors"
 
 	"github.com/edouardparis/lntop/network/models"
 )
@@ -16,9 +25,21 @@ func protoToWalletBalance(w *lnrpc.WalletBalanceResponse) *models.WalletBalance
 		TotalBalance:       w.GetTotalBalance(),
 		ConfirmedBalance:   w.GetConfirmedBalance(),
 		UnconfirmedBalance: w.GetUnconfirmedBalance(),
+		AnchorReserve:      w.GetReservedBalanceAnchorChan(),
 	}
 }
 
+// hasAnchors returns true if the commitment has anchor outputs, the fee
+// of the force close is then bumped from the wallet.
+func hasAnchors(t lnrpc.CommitmentType) bool {
+	switch t {
+	case lnrpc.CommitmentType_ANCHORS, lnrpc.CommitmentType_SCRIPT_ENFORCED_LEASE,
+		lnrpc.CommitmentType_SIMPLE_TAPROOT:
+		return true
+	}
+	return false
+}
+
 func protoToChannelsBalance(w *lnrpc.ChannelBalanceResponse) *models.ChannelsBalance {
 	return &models.ChannelsBalance{
 		PendingOpenBalance: w.GetPendingOpenBalance(),
@@ -38,6 +59,18 @@ func addInvoiceProtoToInvoice(req *lnrpc.Invoice, resp *lnrpc.AddInvoiceResponse
 	}
 }
 
+func invoiceStateProtoToInvoiceState(state lnrpc.Invoice_InvoiceState) int {
+	switch state {
+	case lnrpc.Invoice_SETTLED:
+		return models.InvoiceSettled
+	case lnrpc.Invoice_CANCELED:
+		return models.InvoiceCanceled
+	case lnrpc.Invoice_ACCEPTED:
+		return models.InvoiceAccepted
+	}
+	return models.InvoiceOpen
+}
+
 func lookupInvoiceProtoToInvoice(resp *lnrpc.Invoice) *models.Invoice {
 	return &models.Invoice{
 		Index:            resp.GetAddIndex(),
@@ -51,6 +84,7 @@ func lookupInvoiceProtoToInvoice(resp *lnrpc.Invoice) *models.Invoice {
 		DescriptionHash:  resp.GetDescriptionHash(),
 		FallBackAddress:  resp.GetFallbackAddr(),
 		Settled:          resp.GetSettled(),
+		State:            invoiceStateProtoToInvoiceState(resp.GetState()),
 		CreationDate:     resp.GetCreationDate(),
 		SettleDate:       resp.GetSettleDate(),
 		Expiry:           resp.GetExpiry(),
@@ -86,6 +120,7 @@ func channelProtoToChannel(c *lnrpc.Channel) *models.Channel {
 		Status:              status,
 		RemotePubKey:        c.GetRemotePubkey(),
 		ChannelPoint:        c.GetChannelPoint(),
+		Anchors:             hasAnchors(c.GetCommitmentType()),
 		Capacity:            c.GetCapacity(),
 		LocalBalance:        c.GetLocalBalance(),
 		RemoteBalance:       c.GetRemoteBalance(),
@@ -99,6 +134,8 @@ func channelProtoToChannel(c *lnrpc.Channel) *models.Channel {
 		CSVDelay:            c.GetCsvDelay(),
 		Private:             c.GetPrivate(),
 		PendingHTLC:         HTLCs,
+		Uptime:              time.Duration(c.GetUptime()) * time.Second,
+		Lifetime:            time.Duration(c.GetLifetime()) * time.Second,
 	}
 }
 
@@ -145,15 +182,17 @@ func pendingChannelsProtoToChannels(r *lnrpc.PendingChannelsResponse) []*models.
 
 func openingChannelProtoToChannel(c *lnrpc.PendingChannelsResponse_PendingOpenChannel) *models.Channel {
 	return &models.Channel{
-		Status:           models.ChannelOpening,
-		RemotePubKey:     c.Channel.RemoteNodePub,
-		Capacity:         c.Channel.Capacity,
-		LocalBalance:     c.Channel.LocalBalance,
-		RemoteBalance:    c.Channel.RemoteBalance,
-		ChannelPoint:     c.Channel.ChannelPoint,
-		CommitWeight:     c.CommitWeight,
-		CommitFee:        c.CommitFee,
-		FeePerKiloWeight: c.FeePerKw,
+		Status:              models.ChannelOpening,
+		RemotePubKey:        c.Channel.RemoteNodePub,
+		Capacity:            c.Channel.Capacity,
+		LocalBalance:        c.Channel.LocalBalance,
+		RemoteBalance:       c.Channel.RemoteBalance,
+		ChannelPoint:        c.Channel.ChannelPoint,
+		Anchors:             hasAnchors(c.Channel.CommitmentType),
+		CommitWeight:        c.CommitWeight,
+		CommitFee:           c.CommitFee,
+		FeePerKiloWeight:    c.FeePerKw,
+		FundingExpiryBlocks: c.FundingExpiryBlocks,
 	}
 }
 
@@ -165,6 +204,7 @@ func closingChannelProtoToChannel(c *lnrpc.PendingChannelsResponse_ClosedChannel
 		LocalBalance:  c.Channel.LocalBalance,
 		RemoteBalance: c.Channel.RemoteBalance,
 		ChannelPoint:  c.Channel.ChannelPoint,
+		Anchors:       hasAnchors(c.Channel.CommitmentType),
 	}
 }
 
@@ -176,10 +216,47 @@ func forceClosingChannelProtoToChannel(c *lnrpc.PendingChannelsResponse_ForceClo
 		LocalBalance:      c.Channel.LocalBalance,
 		RemoteBalance:     c.Channel.RemoteBalance,
 		ChannelPoint:      c.Channel.ChannelPoint,
+		Anchors:           hasAnchors(c.Channel.CommitmentType),
 		BlocksTilMaturity: c.BlocksTilMaturity,
+		Closing: &models.Closing{
+			ClosingTxID:       c.ClosingTxid,
+			LimboBalance:      c.LimboBalance,
+			RecoveredBalance:  c.RecoveredBalance,
+			MaturityHeight:    c.MaturityHeight,
+			BlocksTilMaturity: c.BlocksTilMaturity,
+			Anchor:            anchorStateProtoToAnchorState(c.Anchor),
+			PendingHTLCs:      pendingHTLCsProtoToPendingHTLCs(c.PendingHtlcs),
+		},
 	}
 }
 
+func anchorStateProtoToAnchorState(s lnrpc.PendingChannelsResponse_ForceClosedChannel_AnchorState) int {
+	switch s {
+	case lnrpc.PendingChannelsResponse_ForceClosedChannel_LIMBO:
+		return models.AnchorLimbo
+	case lnrpc.PendingChannelsResponse_ForceClosedChannel_RECOVERED:
+		return models.AnchorRecovered
+	case lnrpc.PendingChannelsResponse_ForceClosedChannel_LOST:
+		return models.AnchorLost
+	}
+	return 0
+}
+
+func pendingHTLCsProtoToPendingHTLCs(htlcs []*lnrpc.PendingHTLC) []*models.PendingHTLC {
+	result := make([]*models.PendingHTLC, len(htlcs))
+	for i, h := range htlcs {
+		result[i] = &models.PendingHTLC{
+			Incoming:          h.Incoming,
+			Amount:            h.Amount,
+			Outpoint:          h.Outpoint,
+			MaturityHeight:    h.MaturityHeight,
+			BlocksTilMaturity: h.BlocksTilMaturity,
+			Stage:             h.Stage,
+		}
+	}
+	return result
+}
+
 func waitingCloseChannelProtoToChannel(c *lnrpc.PendingChannelsResponse_WaitingCloseChannel) *models.Channel {
 	return &models.Channel{
 		Status:        models.ChannelWaitingClose,
@@ -188,57 +265,234 @@ func waitingCloseChannelProtoToChannel(c *lnrpc.PendingChannelsResponse_WaitingC
 		LocalBalance:  c.Channel.LocalBalance,
 		RemoteBalance: c.Channel.RemoteBalance,
 		ChannelPoint:  c.Channel.ChannelPoint,
+		Anchors:       hasAnchors(c.Channel.CommitmentType),
+		CloseType:     waitingCloseType(c),
+		Closing: &models.Closing{
+			ClosingTxID:  c.ClosingTxid,
+			LimboBalance: c.LimboBalance,
+		},
+	}
+}
+
+// waitingCloseType compares the closing tx to the commitments, a waiting
+// close channel is force closed if one of them is broadcast.
+func waitingCloseType(c *lnrpc.PendingChannelsResponse_WaitingCloseChannel) int {
+	if c.ClosingTxid == "" || c.Commitments == nil {
+		return 0
 	}
+	switch c.ClosingTxid {
+	case c.Commitments.LocalTxid:
+		return models.CloseLocalForce
+	case c.Commitments.RemoteTxid, c.Commitments.RemotePendingTxid:
+		return models.CloseRemoteForce
+	}
+	return models.CloseCooperative
+}
+
+func closeTypeProtoToCloseType(t lnrpc.ChannelCloseSummary_ClosureType) int {
+	switch t {
+	case lnrpc.ChannelCloseSummary_COOPERATIVE_CLOSE:
+		return models.CloseCooperative
+	case lnrpc.ChannelCloseSummary_LOCAL_FORCE_CLOSE:
+		return models.CloseLocalForce
+	case lnrpc.ChannelCloseSummary_REMOTE_FORCE_CLOSE:
+		return models.CloseRemoteForce
+	case lnrpc.ChannelCloseSummary_BREACH_CLOSE:
+		return models.CloseBreach
+	case lnrpc.ChannelCloseSummary_FUNDING_CANCELED:
+		return models.CloseFundingCanceled
+	case lnrpc.ChannelCloseSummary_ABANDONED:
+		return models.CloseAbandoned
+	}
+	return 0
+}
+
+func initiatorProtoToInitiator(i lnrpc.Initiator) int {
+	switch i {
+	case lnrpc.Initiator_INITIATOR_LOCAL:
+		return models.InitiatorLocal
+	case lnrpc.Initiator_INITIATOR_REMOTE:
+		return models.InitiatorRemote
+	case lnrpc.Initiator_INITIATOR_BOTH:
+		return models.InitiatorBoth
+	}
+	return 0
+}
+
+func closedChannelProtoToClosedChannel(c *lnrpc.ChannelCloseSummary) *models.ClosedChannel {
+	return &models.ClosedChannel{
+		ID:                c.ChanId,
+		ChannelPoint:      c.ChannelPoint,
+		RemotePubKey:      c.RemotePubkey,
+		Capacity:          c.Capacity,
+		SettledBalance:    c.SettledBalance,
+		TimeLockedBalance: c.TimeLockedBalance,
+		CloseHeight:       c.CloseHeight,
+		ClosingTxHash:     c.ClosingTxHash,
+		CloseType:         closeTypeProtoToCloseType(c.CloseType),
+		OpenInitiator:     initiatorProtoToInitiator(c.OpenInitiator),
+		CloseInitiator:    initiatorProtoToInitiator(c.CloseInitiator),
+	}
+}
+
+// closingFee returns the fee of the closing transaction, the capacity
+// of the funding output less the outputs, zero if the transaction does
+// not spend only the funding output.
+func closingFee(c *lnrpc.ChannelCloseSummary, rawTxHex string) int64 {
+	raw, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return 0
+	}
+	var tx wire.MsgTx
+	err = tx.Deserialize(bytes.NewReader(raw))
+	if err != nil || len(tx.TxIn) != 1 ||
+		tx.TxIn[0].PreviousOutPoint.String() != c.ChannelPoint {
+		return 0
+	}
+	fee := c.Capacity
+	for _, out := range tx.TxOut {
+		fee -= out.Value
+	}
+	if fee < 0 {
+		return 0
+	}
+	return fee
 }
 
 func payreqProtoToPayReq(h *lnrpc.PayReq, payreq string) *models.PayReq {
 	if h == nil {
 		return nil
 	}
-	return &models.PayReq{
+	p := &models.PayReq{
 		Destination:     h.Destination,
 		PaymentHash:     h.PaymentHash,
 		Amount:          h.NumSatoshis,
+		AmountMsat:      h.NumMsat,
 		Timestamp:       h.Timestamp,
 		Expiry:          h.Expiry,
 		Description:     h.Description,
 		DescriptionHash: h.DescriptionHash,
 		FallbackAddr:    h.FallbackAddr,
 		CltvExpiry:      h.CltvExpiry,
+		PaymentAddr:     h.PaymentAddr,
 		String:          payreq,
 	}
-}
 
-func sendPaymentProtoToPayment(payreq *models.PayReq, resp *lnrpc.SendResponse) *models.Payment {
-	if payreq == nil || resp == nil {
-		return nil
+	for _, r := range h.RouteHints {
+		hint := &models.RouteHint{}
+		for _, hop := range r.HopHints {
+			hint.Hops = append(hint.Hops, &models.HopHint{
+				NodeID:                    hop.NodeId,
+				ChanID:                    hop.ChanId,
+				FeeBaseMsat:               hop.FeeBaseMsat,
+				FeeProportionalMillionths: hop.FeeProportionalMillionths,
+				CltvExpiryDelta:           hop.CltvExpiryDelta,
+			})
+		}
+		p.RouteHints = append(p.RouteHints, hint)
+	}
+
+	for bit, f := range h.Features {
+		p.Features = append(p.Features, &models.Feature{
+			Bit:      bit,
+			Name:     f.Name,
+			Required: f.IsRequired,
+			Known:    f.IsKnown,
+		})
 	}
+	sort.Slice(p.Features, func(i, j int) bool {
+		return p.Features[i].Bit < p.Features[j].Bit
+	})
+
+	return p
+}
 
+func paymentProtoToPayment(p *lnrpc.Payment) *models.Payment {
 	payment := &models.Payment{
-		PaymentError:    resp.PaymentError,
-		PaymentPreimage: resp.PaymentPreimage,
-		PayReq:          payreq,
+		Hash:         p.PaymentHash,
+		Amount:       p.ValueSat,
+		Fee:          p.FeeSat,
+		CreationDate: time.Unix(0, p.CreationTimeNs),
+	}
+	switch p.Status {
+	case lnrpc.Payment_SUCCEEDED:
+		payment.Status = models.PaymentSucceeded
+		payment.PaymentPreimage, _ = hex.DecodeString(p.PaymentPreimage)
+	case lnrpc.Payment_FAILED:
+		payment.Status = models.PaymentFailed
+		payment.PaymentError = paymentFailureReason(p.FailureReason)
+	default:
+		payment.Status = models.PaymentInFlight
 	}
 
-	if resp.PaymentRoute != nil {
-		payment.Route = &models.Route{
-			TimeLock: resp.PaymentRoute.GetTotalTimeLock(),
-			Fee:      resp.PaymentRoute.GetTotalFees(),
-			Amount:   resp.PaymentRoute.GetTotalAmt(),
+	// the route of the settled htlc, or of the last attempt.
+	for _, htlc := range p.Htlcs {
+		if htlc.Route == nil {
+			continue
+		}
+		payment.Route = routeProtoToRoute(htlc.Route)
+		if htlc.Status == lnrpc.HTLCAttempt_SUCCEEDED {
+			break
 		}
 	}
-
 	return payment
 }
 
+// paymentFailureReason returns the failure reason in lower case, e.g.
+// "no route" for FAILURE_REASON_NO_ROUTE.
+func paymentFailureReason(r lnrpc.PaymentFailureReason) string {
+	if r == lnrpc.PaymentFailureReason_FAILURE_REASON_NONE {
+		return ""
+	}
+	name := strings.TrimPrefix(r.String(), "FAILURE_REASON_")
+	return strings.ToLower(strings.ReplaceAll(name, "_", " "))
+}
+
+func htlcFailureReason(f *lnrpc.Failure) string {
+	if f == nil {
+		return "unknown failure"
+	}
+	return strings.ToLower(strings.ReplaceAll(f.Code.String(), "_", " "))
+}
+
+func routeProtoToRoute(r *lnrpc.Route) *models.Route {
+	route := &models.Route{
+		TimeLock: r.TotalTimeLock,
+		Fee:      r.TotalFees,
+		Amount:   r.TotalAmt,
+		Hops:     make([]*models.Hop, len(r.Hops)),
+	}
+	for i, h := range r.Hops {
+		route.Hops[i] = &models.Hop{
+			ChanID:       h.ChanId,
+			PubKey:       h.PubKey,
+			ChanCapacity: h.ChanCapacity,
+			Amount:       h.AmtToForward,
+			Fee:          h.Fee,
+			Expiry:       h.Expiry,
+		}
+	}
+	return route
+}
+
 func infoProtoToInfo(resp *lnrpc.GetInfoResponse) *models.Info {
 	if resp == nil {
 		return nil
 	}
 
 	chains := []string{}
+	network := ""
 	for i := range resp.Chains {
 		chains = append(chains, resp.Chains[i].Chain)
+		if network == "" {
+			network = resp.Chains[i].Network
+		}
+	}
+	if network == "" {
+		network = "mainnet"
+		if resp.Testnet {
+			network = "testnet"
+		}
 	}
 
 	return &models.Info{
@@ -254,6 +508,7 @@ func infoProtoToInfo(resp *lnrpc.GetInfoResponse) *models.Info {
 		Version:             resp.Version,
 		Chains:              chains,
 		Testnet:             resp.Testnet,
+		Network:             network,
 	}
 }
 
@@ -262,13 +517,6 @@ func nodeProtoToNode(resp *lnrpc.NodeInfo) *models.Node {
 		return nil
 	}
 
-	addresses := make([]*models.NodeAddress, len(resp.Node.Addresses))
-	for i := range resp.Node.Addresses {
-		addresses[i] = &models.NodeAddress{
-			Network: resp.Node.Addresses[i].Network,
-			Addr:    resp.Node.Addresses[i].Addr,
-		}
-	}
 	channels := []*models.Channel{}
 	for _, c := range resp.Channels {
 		ch := &models.Channel{
@@ -278,28 +526,124 @@ func nodeProtoToNode(resp *lnrpc.NodeInfo) *models.Node {
 			LocalPolicy:  protoToRoutingPolicy(c.Node1Policy),
 			RemotePolicy: protoToRoutingPolicy(c.Node2Policy),
 		}
+		ch.RemotePubKey = c.Node2Pub
 		if c.Node1Pub != resp.Node.PubKey {
 			ch.LocalPolicy, ch.RemotePolicy = ch.RemotePolicy, ch.LocalPolicy
+			ch.RemotePubKey = c.Node1Pub
 		}
 		channels = append(channels, ch)
 	}
 
+	node := lightningNodeProtoToNode(resp.Node)
+	node.NumChannels = resp.NumChannels
+	node.TotalCapacity = resp.TotalCapacity
+	node.Channels = channels
+	return node
+}
+
+func lightningNodeProtoToNode(n *lnrpc.LightningNode) *models.Node {
+	addresses := make([]*models.NodeAddress, len(n.Addresses))
+	for i := range n.Addresses {
+		addresses[i] = &models.NodeAddress{
+			Network: n.Addresses[i].Network,
+			Addr:    n.Addresses[i].Addr,
+		}
+	}
+
+	features := make([]*models.NodeFeature, 0, len(n.Features))
+	for bit, f := range n.Features {
+		features = append(features, &models.NodeFeature{
+			Bit:      bit,
+			Name:     f.Name,
+			Required: f.IsRequired,
+			Known:    f.IsKnown,
+		})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Bit < features[j].Bit })
+
 	return &models.Node{
-		NumChannels:   resp.NumChannels,
-		TotalCapacity: resp.TotalCapacity,
-		LastUpdate:    time.Unix(int64(resp.Node.LastUpdate), 0),
-		PubKey:        resp.Node.PubKey,
-		Alias:         resp.Node.Alias,
-		Addresses:     addresses,
-		Channels:      channels,
+		LastUpdate: time.Unix(int64(n.LastUpdate), 0),
+		PubKey:     n.PubKey,
+		Alias:      n.Alias,
+		Addresses:  addresses,
+		Features:   features,
+	}
+}
+
+// graphProtoToNodes returns the nodes of the graph with the number of
+// their channels and total capacity counted from the edges.
+func graphProtoToNodes(resp *lnrpc.ChannelGraph) []*models.Node {
+	if resp == nil {
+		return nil
+	}
+
+	nodes := make([]*models.Node, len(resp.Nodes))
+	index := make(map[string]*models.Node, len(resp.Nodes))
+	for i := range resp.Nodes {
+		nodes[i] = lightningNodeProtoToNode(resp.Nodes[i])
+		index[nodes[i].PubKey] = nodes[i]
+	}
+	for _, e := range resp.Edges {
+		for _, pub := range []string{e.Node1Pub, e.Node2Pub} {
+			if n, ok := index[pub]; ok {
+				n.NumChannels++
+				n.TotalCapacity += e.Capacity
+			}
+		}
 	}
+	return nodes
+}
+
+func listPeersProtoToPeers(r *lnrpc.ListPeersResponse) []*models.Peer {
+	resp := r.GetPeers()
+	peers := make([]*models.Peer, len(resp))
+	for i := range resp {
+		peers[i] = &models.Peer{
+			PubKey:    resp[i].PubKey,
+			Address:   resp[i].Address,
+			Inbound:   resp[i].Inbound,
+			BytesSent: resp[i].BytesSent,
+			BytesRecv: resp[i].BytesRecv,
+			SatSent:   resp[i].SatSent,
+			SatRecv:   resp[i].SatRecv,
+			PingTime:  time.Duration(resp[i].PingTime) * time.Microsecond,
+			FlapCount: resp[i].FlapCount,
+		}
+		if resp[i].LastFlapNs > 0 {
+			peers[i].LastFlap = time.Unix(0, resp[i].LastFlapNs)
+		}
+		if n := len(resp[i].Errors); n > 0 {
+			peers[i].LastError = resp[i].Errors[n-1].Error
+			peers[i].LastErrorTime = time.Unix(int64(resp[i].Errors[n-1].Timestamp), 0)
+		}
+	}
+
+	return peers
+}
+
+func protoToNodeState(s lnrpc.WalletState) models.NodeState {
+	switch s {
+	case lnrpc.WalletState_NON_EXISTING:
+		return models.NodeStateNonExisting
+	case lnrpc.WalletState_LOCKED:
+		return models.NodeStateLocked
+	case lnrpc.WalletState_UNLOCKED:
+		return models.NodeStateUnlocked
+	case lnrpc.WalletState_RPC_ACTIVE:
+		return models.NodeStateRPCActive
+	case lnrpc.WalletState_SERVER_ACTIVE:
+		return models.NodeStateServerActive
+	case lnrpc.WalletState_WAITING_TO_START:
+		return models.NodeStateWaitingToStart
+	}
+	return models.NodeStateUnknown
 }
 
 func protoToRoutingPolicy(resp *lnrpc.RoutingPolicy) *models.RoutingPolicy {
 	if resp == nil {
 		return nil
 	}
-	return &models.RoutingPolicy{
+	policy := &models.RoutingPolicy{
 		TimeLockDelta:    resp.TimeLockDelta,
 		MinHtlc:          resp.MinHtlc,
 		MaxHtlc:          resp.MaxHtlcMsat,
@@ -307,6 +651,10 @@ func protoToRoutingPolicy(resp *lnrpc.RoutingPolicy) *models.RoutingPolicy {
 		FeeRateMilliMsat: resp.FeeRateMilliMsat,
 		Disabled:         resp.Disabled,
 	}
+	if resp.LastUpdate > 0 {
+		policy.LastUpdate = time.Unix(int64(resp.LastUpdate), 0)
+	}
+	return policy
 }
 
 func protoToTransactions(resp *lnrpc.TransactionDetails) []*models.Transaction {
@@ -331,7 +679,44 @@ func protoToTransaction(resp *lnrpc.Transaction) *models.Transaction {
 		Date:             time.Unix(int64(resp.TimeStamp), 0),
 		TotalFees:        resp.TotalFees,
 		DestAddresses:    resp.DestAddresses,
+		Label:            resp.Label,
+	}
+}
+
+func interceptProtoToHTLC(req *routerrpc.ForwardHtlcInterceptRequest) *models.InterceptedHTLC {
+	htlc := &models.InterceptedHTLC{
+		OutgoingChannelId:  req.OutgoingRequestedChanId,
+		PaymentHash:        req.PaymentHash,
+		IncomingAmountMsat: req.IncomingAmountMsat,
+		OutgoingAmountMsat: req.OutgoingAmountMsat,
+		IncomingExpiry:     req.IncomingExpiry,
+		OutgoingExpiry:     req.OutgoingExpiry,
+		Intercepted:        time.Now(),
 	}
+	if req.IncomingCircuitKey != nil {
+		htlc.IncomingChannelId = req.IncomingCircuitKey.ChanId
+		htlc.IncomingHtlcId = req.IncomingCircuitKey.HtlcId
+	}
+	return htlc
+}
+
+func resolutionToProto(r *models.HTLCResolution) *routerrpc.ForwardHtlcInterceptResponse {
+	resp := &routerrpc.ForwardHtlcInterceptResponse{
+		IncomingCircuitKey: &routerrpc.CircuitKey{
+			ChanId: r.IncomingChannelId,
+			HtlcId: r.IncomingHtlcId,
+		},
+		Action: routerrpc.ResolveHoldForwardAction_RESUME,
+	}
+	switch r.Action {
+	case models.InterceptFail:
+		resp.Action = routerrpc.ResolveHoldForwardAction_FAIL
+		resp.FailureCode = lnrpc.Failure_TEMPORARY_CHANNEL_FAILURE
+	case models.InterceptSettle:
+		resp.Action = routerrpc.ResolveHoldForwardAction_SETTLE
+		resp.Preimage = r.Preimage
+	}
+	return resp
 }
 
 func protoToRoutingEvent(resp *routerrpc.HtlcEvent) *models.RoutingEvent {
@@ -425,3 +810,238 @@ func protoToForwardingEvent(resp *lnrpc.ForwardingEvent) *models.ForwardingEvent
 		EventTime:  time.Unix(0, int64(resp.TimestampNs)),
 	}
 }
+
+func multiChanBackupProtoToChannelBackup(b *lnrpc.MultiChanBackup) *models.ChannelBackup {
+	backup := &models.ChannelBackup{Multi: b.MultiChanBackup}
+	for _, c := range b.ChanPoints {
+		backup.ChanPoints = append(backup.ChanPoints, chanpointToString(c))
+	}
+	return backup
+}
+
+func pendingSweepProtoToPendingSweep(s *walletrpc.PendingSweep) *models.PendingSweep {
+	sweep := &models.PendingSweep{
+		WitnessType:          s.WitnessType.String(),
+		Amount:               int64(s.AmountSat),
+		SatPerVbyte:          s.SatPerVbyte,
+		RequestedSatPerVbyte: s.RequestedSatPerVbyte,
+		BroadcastAttempts:    s.BroadcastAttempts,
+		NextBroadcastHeight:  s.NextBroadcastHeight,
+		DeadlineHeight:       s.DeadlineHeight,
+		Budget:               s.Budget,
+		Immediate:            s.Immediate,
+	}
+	if s.Outpoint != nil {
+		sweep.Outpoint = fmt.Sprintf("%s:%d", s.Outpoint.TxidStr, s.Outpoint.OutputIndex)
+	}
+	return sweep
+}
+
+func outpointsToProto(outpoints []string) ([]*lnrpc.OutPoint, error) {
+	if len(outpoints) == 0 {
+		return nil, nil
+	}
+	result := make([]*lnrpc.OutPoint, len(outpoints))
+	for i := range outpoints {
+		op, err := outpointToProto(outpoints[i])
+		if err != nil {
+			return nil, err
+		}
+		result[i] = op
+	}
+	return result, nil
+}
+
+// txidToBytes returns the bytes of the hash of the txid, in the reverse
+// order of its hex encoding.
+func txidToBytes(txid string) ([]byte, error) {
+	b, err := hex.DecodeString(txid)
+	if err != nil || len(b) != 32 {
+		return nil, errors.Errorf("invalid txid %s", txid)
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b, nil
+}
+
+func channelPointProtoToString(point *lnrpc.ChannelPoint) (string, error) {
+	switch txid := point.FundingTxid.(type) {
+	case *lnrpc.ChannelPoint_FundingTxidStr:
+		return fmt.Sprintf("%s:%d", txid.FundingTxidStr, point.OutputIndex), nil
+	case *lnrpc.ChannelPoint_FundingTxidBytes:
+		return fmt.Sprintf("%s:%d", txidProtoToString(txid.FundingTxidBytes), point.OutputIndex), nil
+	}
+	return "", errors.New("channel point without funding txid")
+}
+
+// txidProtoToString formats the txid bytes of lnd, in the byte order of
+// the transaction, as the usual reversed hex.
+func txidProtoToString(txid []byte) string {
+	b := make([]byte, len(txid))
+	for i := range b {
+		b[i] = txid[len(b)-1-i]
+	}
+	return hex.EncodeToString(b)
+}
+
+func outpointToProto(outpoint string) (*lnrpc.OutPoint, error) {
+	txid, index, ok := strings.Cut(outpoint, ":")
+	if !ok {
+		return nil, errors.Errorf("invalid outpoint %s", outpoint)
+	}
+	i, err := strconv.ParseUint(index, 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid outpoint %s", outpoint)
+	}
+	return &lnrpc.OutPoint{TxidStr: txid, OutputIndex: uint32(i)}, nil
+}
+
+func channelPointToProto(point string) (*lnrpc.ChannelPoint, error) {
+	op, err := outpointToProto(point)
+	if err != nil {
+		return nil, err
+	}
+	return &lnrpc.ChannelPoint{
+		FundingTxid: &lnrpc.ChannelPoint_FundingTxidStr{FundingTxidStr: op.TxidStr},
+		OutputIndex: op.OutputIndex,
+	}, nil
+}
+
+func utxoProtoToUTXO(u *lnrpc.Utxo) *models.UTXO {
+	utxo := &models.UTXO{
+		Address:       u.Address,
+		Amount:        u.AmountSat,
+		Confirmations: u.Confirmations,
+	}
+	if u.Outpoint != nil {
+		utxo.Outpoint = fmt.Sprintf("%s:%d", u.Outpoint.TxidStr, u.Outpoint.OutputIndex)
+	}
+	switch u.AddressType {
+	case lnrpc.AddressType_WITNESS_PUBKEY_HASH, lnrpc.AddressType_UNUSED_WITNESS_PUBKEY_HASH:
+		utxo.AddressType = models.AddressP2WKH
+	case lnrpc.AddressType_NESTED_PUBKEY_HASH, lnrpc.AddressType_UNUSED_NESTED_PUBKEY_HASH:
+		utxo.AddressType = models.AddressNP2WKH
+	case lnrpc.AddressType_TAPROOT_PUBKEY, lnrpc.AddressType_UNUSED_TAPROOT_PUBKEY:
+		utxo.AddressType = models.AddressP2TR
+	}
+	return utxo
+}
+
+// leaseProtoToUTXO returns the leased output, its address and its
+// confirmations are the ones of the output of the transaction if known.
+func leaseProtoToUTXO(lease *walletrpc.UtxoLease, tx *lnrpc.Transaction) *models.UTXO {
+	utxo := &models.UTXO{
+		Outpoint:        fmt.Sprintf("%s:%d", lease.Outpoint.TxidStr, lease.Outpoint.OutputIndex),
+		Amount:          int64(lease.Value),
+		LeaseID:         hex.EncodeToString(lease.Id),
+		LeaseExpiration: time.Unix(int64(lease.Expiration), 0),
+	}
+	if tx == nil {
+		return utxo
+	}
+	utxo.Confirmations = int64(tx.NumConfirmations)
+	utxo.Label = tx.Label
+	for _, o := range tx.OutputDetails {
+		if o.OutputIndex != int64(lease.Outpoint.OutputIndex) {
+			continue
+		}
+		utxo.Address = o.Address
+		switch o.OutputType {
+		case lnrpc.OutputScriptType_SCRIPT_TYPE_WITNESS_V0_PUBKEY_HASH:
+			utxo.AddressType = models.AddressP2WKH
+		case lnrpc.OutputScriptType_SCRIPT_TYPE_SCRIPT_HASH:
+			utxo.AddressType = models.AddressNP2WKH
+		case lnrpc.OutputScriptType_SCRIPT_TYPE_WITNESS_V1_TAPROOT:
+			utxo.AddressType = models.AddressP2TR
+		}
+	}
+	return utxo
+}
+
+func towerProtoToTower(t *wtclientrpc.Tower) *models.Tower {
+	tower := &models.Tower{
+		PubKey:    hex.EncodeToString(t.Pubkey),
+		Addresses: t.Addresses,
+	}
+	for _, info := range t.SessionInfo {
+		if info.ActiveSessionCandidate {
+			tower.Active = true
+		}
+		for _, s := range info.Sessions {
+			tower.Sessions = append(tower.Sessions, &models.TowerSession{
+				Policy:            towerPolicyName(info.PolicyType),
+				NumBackups:        s.NumBackups,
+				NumPendingBackups: s.NumPendingBackups,
+				MaxBackups:        s.MaxBackups,
+				SweepSatPerVbyte:  s.SweepSatPerVbyte,
+			})
+		}
+	}
+	return tower
+}
+
+// towerPolicyName returns the name of the policy type, e.g. "anchor".
+func towerPolicyName(t wtclientrpc.PolicyType) string {
+	return strings.ToLower(t.String())
+}
+
+// chainParams returns the parameters of the bitcoin network of the node.
+func chainParams(network string) (*chaincfg.Params, error) {
+	switch network {
+	case "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet":
+		return &chaincfg.TestNet3Params, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	case "simnet":
+		return &chaincfg.SimNetParams, nil
+	}
+	return nil, errors.Errorf("unknown network: %s", network)
+}
+
+func pairProtoToMissionPair(p *routerrpc.PairHistory) *models.MissionPair {
+	pair := &models.MissionPair{
+		NodeFrom: hex.EncodeToString(p.NodeFrom),
+		NodeTo:   hex.EncodeToString(p.NodeTo),
+	}
+	if p.History == nil {
+		return pair
+	}
+	if p.History.FailTime != 0 {
+		pair.FailTime = time.Unix(p.History.FailTime, 0)
+	}
+	pair.FailAmtMsat = p.History.FailAmtMsat
+	if p.History.SuccessTime != 0 {
+		pair.SuccessTime = time.Unix(p.History.SuccessTime, 0)
+	}
+	pair.SuccessAmtMsat = p.History.SuccessAmtMsat
+	return pair
+}
+
+func missionPairToProto(p *models.MissionPair) (*routerrpc.PairHistory, error) {
+	from, err := hex.DecodeString(p.NodeFrom)
+	if err != nil {
+		return nil, errors.Wrapf(err, "node %s", p.NodeFrom)
+	}
+	to, err := hex.DecodeString(p.NodeTo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "node %s", p.NodeTo)
+	}
+	data := &routerrpc.PairData{
+		FailAmtSat:     p.FailAmtMsat / 1000,
+		FailAmtMsat:    p.FailAmtMsat,
+		SuccessAmtSat:  p.SuccessAmtMsat / 1000,
+		SuccessAmtMsat: p.SuccessAmtMsat,
+	}
+	if !p.FailTime.IsZero() {
+		data.FailTime = p.FailTime.Unix()
+	}
+	if !p.SuccessTime.IsZero() {
+		data.SuccessTime = p.SuccessTime.Unix()
+	}
+	return &routerrpc.PairHistory{NodeFrom: from, NodeTo: to, History: data}, nil
+}
@@ -1,9 +1,17 @@
+// Package mock implements an in-memory backend.Backend. The state is
+// seeded and mutated with the exported setters, which also publish the
+// matching updates to the subscribers, so views and tools can be
+// developed without a running node.
 package mock
 
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,14 +19,54 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/backend"
 	"github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/network/options"
 )
 
+// updatesBuffer is the number of updates kept for a subscriber,
+// updates are dropped when nobody consumes them.
+const updatesBuffer = 64
+
+// mockApriori is the success probability of a pair without history.
+const mockApriori = 0.6
+
+var _ backend.Backend = (*Backend)(nil)
+
 type Backend struct {
-	invoices map[string]models.Invoice
-	count    uint64
-	cfg      *config.Network
+	cfg *config.Network
+
+	info            models.Info
+	walletBalance   models.WalletBalance
+	channelsBalance models.ChannelsBalance
+	nodes           map[string]*models.Node
+	channels        []*models.Channel
+	closed          []*models.ClosedChannel
+	sweeps          []*models.PendingSweep
+	utxos           []*models.UTXO
+	watchtower      models.Watchtower
+	mission         []*models.MissionPair
+	transactions    []*models.Transaction
+	forwards        []*models.ForwardingEvent
+	peers           []*models.Peer
+	payments        []*models.Payment
+	invoices        map[string]models.Invoice
+	offers          []*models.Offer
+	count           uint64
+
+	invoiceUpdates     chan *models.Invoice
+	channelUpdates     chan *models.ChannelUpdate
+	transactionUpdates chan *models.Transaction
+	routingUpdates     chan *models.RoutingEvent
+	graphUpdates       chan *models.ChannelEdgeUpdate
+	backupUpdates      chan *models.ChannelBackup
+	paymentUpdates     chan *models.Payment
+	intercepts         chan *models.InterceptedHTLC
+	resolutions        []*models.HTLCResolution
+	backupErr          error
+	backup             *models.ChannelBackup
+	state              models.NodeState
+
 	sync.RWMutex
 }
 
@@ -26,68 +74,1012 @@ func (b *Backend) Ping() error {
 	return nil
 }
 
+func (b *Backend) NodeName() string {
+	return b.cfg.Name
+}
+
 func (b *Backend) Info(ctx context.Context) (*models.Info, error) {
-	return nil, nil
+	b.RLock()
+	defer b.RUnlock()
+	info := b.info
+	for i := range b.channels {
+		switch b.channels[i].Status {
+		case models.ChannelActive:
+			info.NumActiveChannels++
+		case models.ChannelInactive:
+			info.NumInactiveChannels++
+		case models.ChannelOpening:
+			info.NumPendingChannels++
+		}
+	}
+	info.NumPeers = uint32(len(b.peers))
+	return &info, nil
 }
 
-func (l *Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
-	return nil, nil
+// SendPayment pays the payment request through the active channel with
+// the largest local balance, with a fee of 0.1% if the destination is not
+// its peer.
+func (b *Backend) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
+	b.Lock()
+	defer b.Unlock()
+	if payreq.Amount == 0 {
+		return nil, errors.New("invoice without amount")
+	}
+	payment := &models.Payment{
+		Hash:         payreq.PaymentHash,
+		Status:       models.PaymentInFlight,
+		Amount:       payreq.Amount,
+		CreationDate: time.Now(),
+		PayReq:       payreq,
+	}
+	return b.pay(payment, payreq.Destination), nil
 }
 
-func (b *Backend) NodeName() string {
-	return b.cfg.Name
+// SendKeysend pays the destination like SendPayment, the payment is in
+// flight once before.
+func (b *Backend) SendKeysend(ctx context.Context, k *models.Keysend, updates chan *models.Payment) (*models.Payment, error) {
+	b.Lock()
+	defer b.Unlock()
+	hash := sha256.Sum256([]byte(fmt.Sprintf("keysend %s %d %d", k.Destination, k.Amount, time.Now().UnixNano())))
+	payment := &models.Payment{
+		Hash:         hex.EncodeToString(hash[:]),
+		Status:       models.PaymentInFlight,
+		Amount:       k.Amount,
+		CreationDate: time.Now(),
+	}
+	p := *payment
+	select {
+	case updates <- &p:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return b.pay(payment, k.Destination), nil
 }
 
-func (b *Backend) SubscribeInvoice(ctx context.Context, ChannelInvoice chan *models.Invoice) error {
-	return nil
+// pay settles the payment in flight to the destination and returns a copy
+// of it.
+func (b *Backend) pay(payment *models.Payment, destination string) *models.Payment {
+	var out *models.Channel
+	for _, ch := range b.channels {
+		if ch.Status == models.ChannelActive && (out == nil || ch.LocalBalance > out.LocalBalance) {
+			out = ch
+		}
+	}
+	hops := []*models.Hop{}
+	if out != nil {
+		hops = append(hops, &models.Hop{ChanID: out.ID, PubKey: out.RemotePubKey, Amount: payment.Amount})
+		if out.RemotePubKey != destination {
+			payment.Fee = payment.Amount/1000 + 1
+			hops[0].Amount += payment.Fee
+			hops[0].Fee = payment.Fee
+			hops = append(hops, &models.Hop{PubKey: destination, Amount: payment.Amount})
+		}
+	}
+	switch {
+	case out == nil:
+		payment.Status = models.PaymentFailed
+		payment.PaymentError = "no route"
+	case out.LocalBalance < payment.Amount+payment.Fee:
+		payment.Status = models.PaymentFailed
+		payment.PaymentError = "insufficient balance"
+		payment.Fee = 0
+	default:
+		out.LocalBalance -= payment.Amount + payment.Fee
+		out.RemoteBalance += payment.Amount + payment.Fee
+		payment.Status = models.PaymentSucceeded
+		preimage := sha256.Sum256([]byte(payment.Hash))
+		payment.PaymentPreimage = preimage[:]
+		payment.Route = &models.Route{Fee: payment.Fee, Amount: payment.Amount + payment.Fee, Hops: hops}
+		publish(b.channelUpdates, &models.ChannelUpdate{})
+	}
+	b.payments = append(b.payments, payment)
+	publish(b.paymentUpdates, payment)
+	p := *payment
+	return &p
 }
 
-func (b *Backend) SubscribeChannels(context.Context, chan *models.ChannelUpdate) error {
-	return nil
+// Rebalance moves the amount between the two active channels in a single
+// attempt, from the peer of the outgoing channel to the one of the
+// incoming channel, with a fee of 0.1%.
+func (b *Backend) Rebalance(ctx context.Context, out, in *models.Channel, amount, maxFee int64, attempts chan *models.RebalanceAttempt) (*models.Payment, error) {
+	b.Lock()
+	defer b.Unlock()
+	var from, to *models.Channel
+	for _, ch := range b.channels {
+		switch ch.ID {
+		case out.ID:
+			from = ch
+		case in.ID:
+			to = ch
+		}
+	}
+	if from == nil || to == nil || from == to {
+		return nil, errors.New("unknown channel")
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("rebalance %d %d %d", from.ID, to.ID, time.Now().UnixNano())))
+	payment := &models.Payment{
+		Hash:         hex.EncodeToString(hash[:]),
+		Status:       models.PaymentFailed,
+		Amount:       amount,
+		CreationDate: time.Now(),
+	}
+	fee := amount/1000 + 1
+	if fee > maxFee {
+		payment.PaymentError = "no route"
+		return payment, nil
+	}
+	attempt := &models.RebalanceAttempt{
+		Number: 1,
+		Status: models.PaymentInFlight,
+		Route: &models.Route{Fee: fee, Amount: amount + fee, Hops: []*models.Hop{
+			{ChanID: from.ID, PubKey: from.RemotePubKey, Amount: amount + fee, Fee: fee},
+			{PubKey: to.RemotePubKey, Amount: amount},
+			{ChanID: to.ID, PubKey: b.info.PubKey, Amount: amount},
+		}},
+	}
+	send := func() {
+		a := *attempt
+		select {
+		case attempts <- &a:
+		case <-ctx.Done():
+		}
+	}
+	send()
+
+	switch {
+	case from.Status != models.ChannelActive || to.Status != models.ChannelActive:
+		attempt.Error = "unknown next peer"
+	case from.LocalBalance < amount+fee || to.RemoteBalance < amount:
+		attempt.Error = "temporary channel failure"
+	}
+	if attempt.Error != "" {
+		attempt.Status = models.PaymentFailed
+		payment.PaymentError = attempt.Error
+		send()
+		return payment, nil
+	}
+
+	from.LocalBalance -= amount + fee
+	from.RemoteBalance += amount + fee
+	to.LocalBalance += amount
+	to.RemoteBalance -= amount
+	attempt.Status = models.PaymentSucceeded
+	send()
+
+	payment.Status = models.PaymentSucceeded
+	payment.Fee = fee
+	payment.Route = attempt.Route
+	preimage := sha256.Sum256(hash[:])
+	payment.PaymentPreimage = preimage[:]
+	b.payments = append(b.payments, payment)
+	publish(b.channelUpdates, &models.ChannelUpdate{})
+	publish(b.paymentUpdates, payment)
+	p := *payment
+	return &p, nil
+}
+
+// ListPayments returns the payments sent and set.
+func (b *Backend) ListPayments(ctx context.Context) ([]*models.Payment, error) {
+	b.RLock()
+	defer b.RUnlock()
+	payments := make([]*models.Payment, len(b.payments))
+	for i := range b.payments {
+		p := *b.payments[i]
+		payments[i] = &p
+	}
+	return payments, nil
+}
+
+func (b *Backend) SubscribePayments(ctx context.Context, channel chan *models.Payment) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case payment := <-b.paymentUpdates:
+			channel <- payment
+		}
+	}
+}
+
+func (b *Backend) SubscribeInvoice(ctx context.Context, channel chan *models.Invoice) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case invoice := <-b.invoiceUpdates:
+			channel <- invoice
+		}
+	}
+}
+
+func (b *Backend) SubscribeChannels(ctx context.Context, channel chan *models.ChannelUpdate) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update := <-b.channelUpdates:
+			channel <- update
+		}
+	}
 }
 
 func (b *Backend) SubscribeTransactions(ctx context.Context, channel chan *models.Transaction) error {
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case tx := <-b.transactionUpdates:
+			channel <- tx
+		}
+	}
 }
 
 func (b *Backend) SubscribeRoutingEvents(ctx context.Context, channel chan *models.RoutingEvent) error {
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-b.routingUpdates:
+			channel <- event
+		}
+	}
 }
 
 func (b *Backend) SubscribeGraphEvents(ctx context.Context, channel chan *models.ChannelEdgeUpdate) error {
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update := <-b.graphUpdates:
+			channel <- update
+		}
+	}
 }
 
 func (b *Backend) GetNode(ctx context.Context, pubkey string, includeChannels bool) (*models.Node, error) {
-	return &models.Node{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	node, ok := b.nodes[pubkey]
+	if !ok {
+		return nil, errors.Errorf("unable to find node %s", pubkey)
+	}
+
+	result := *node
+	if forcedAlias, ok := b.cfg.Aliases[pubkey]; ok {
+		result.ForcedAlias = forcedAlias
+	}
+	if includeChannels {
+		result.Channels = []*models.Channel{}
+		for i := range b.channels {
+			if b.channels[i].RemotePubKey == pubkey {
+				// the channel is seen from the node, its remote is us.
+				ch := copyChannel(b.channels[i])
+				ch.RemotePubKey = b.info.PubKey
+				ch.LocalPolicy, ch.RemotePolicy = ch.RemotePolicy, ch.LocalPolicy
+				result.Channels = append(result.Channels, ch)
+			}
+		}
+	}
+	return &result, nil
+}
+
+func (b *Backend) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	b.RLock()
+	defer b.RUnlock()
+	nodes := make([]*models.Node, 0, len(b.nodes))
+	for pubkey := range b.nodes {
+		node := *b.nodes[pubkey]
+		if forcedAlias, ok := b.cfg.Aliases[pubkey]; ok {
+			node.ForcedAlias = forcedAlias
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes, nil
 }
 
 func (b *Backend) GetWalletBalance(ctx context.Context) (*models.WalletBalance, error) {
-	return &models.WalletBalance{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	balance := b.walletBalance
+	return &balance, nil
 }
 
 func (b *Backend) GetTransactions(ctx context.Context) ([]*models.Transaction, error) {
-	return []*models.Transaction{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	transactions := make([]*models.Transaction, len(b.transactions))
+	for i := range b.transactions {
+		tx := *b.transactions[i]
+		if tx.BlockHeight > 0 && uint32(tx.BlockHeight) <= b.info.BlockHeight {
+			tx.NumConfirmations = int32(b.info.BlockHeight) - tx.BlockHeight + 1
+		}
+		transactions[i] = &tx
+	}
+	return transactions, nil
 }
 
 func (b *Backend) GetChannelsBalance(ctx context.Context) (*models.ChannelsBalance, error) {
-	return &models.ChannelsBalance{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	balance := b.channelsBalance
+	for i := range b.channels {
+		switch b.channels[i].Status {
+		case models.ChannelActive, models.ChannelInactive:
+			balance.Balance += b.channels[i].LocalBalance
+		case models.ChannelOpening:
+			balance.PendingOpenBalance += b.channels[i].LocalBalance
+		}
+	}
+	return &balance, nil
 }
 
 func (b *Backend) ListChannels(ctx context.Context, opt ...options.Channel) ([]*models.Channel, error) {
-	return []*models.Channel{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	opts := options.NewChannelOptions(opt...)
+	channels := []*models.Channel{}
+	for _, ch := range b.channels {
+		pending := ch.Status != models.ChannelActive && ch.Status != models.ChannelInactive
+		switch {
+		case pending && !opts.Pending:
+			continue
+		case opts.Active && ch.Status != models.ChannelActive:
+			continue
+		case opts.Inactive && ch.Status != models.ChannelInactive:
+			continue
+		case opts.Public && ch.Private:
+			continue
+		case opts.Private && !ch.Private:
+			continue
+		}
+		channels = append(channels, copyChannel(ch))
+	}
+	return channels, nil
 }
 
 func (b *Backend) GetChannelInfo(ctx context.Context, channel *models.Channel) error {
-	return nil
+	if channel.ID == 0 {
+		return nil
+	}
+
+	b.RLock()
+	defer b.RUnlock()
+	for _, ch := range b.channels {
+		if ch.ID != channel.ID {
+			continue
+		}
+		lastUpdate := time.Now()
+		if ch.LastUpdate != nil {
+			lastUpdate = *ch.LastUpdate
+		}
+		channel.LastUpdate = &lastUpdate
+		channel.LocalPolicy = copyPolicy(ch.LocalPolicy)
+		channel.RemotePolicy = copyPolicy(ch.RemotePolicy)
+		return nil
+	}
+	return errors.Errorf("unable to find channel %d", channel.ID)
 }
 
+// DecodePayReq decodes the payment requests of the invoices created by
+// the backend.
 func (b *Backend) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
-	return &models.PayReq{}, nil
+	b.RLock()
+	defer b.RUnlock()
+	for _, invoice := range b.invoices {
+		if invoice.PaymentRequest == payreq {
+			return &models.PayReq{
+				Destination: b.info.PubKey,
+				PaymentHash: hex.EncodeToString(invoice.RHash),
+				Amount:      invoice.Amount,
+				AmountMsat:  invoice.Amount * 1000,
+				Timestamp:   invoice.CreationDate,
+				Expiry:      invoice.Expiry,
+				Description: invoice.Description,
+				String:      payreq,
+			}, nil
+		}
+	}
+	return nil, errors.New("invalid payment request")
+}
+
+// SignMessage returns a fake signature, the hash of the pubkey of the node
+// and of the message.
+func (b *Backend) SignMessage(ctx context.Context, message string) (string, error) {
+	b.RLock()
+	defer b.RUnlock()
+	return mockSignature(b.info.PubKey, message), nil
+}
+
+// VerifyMessage verifies the fake signatures of the node and of its peers.
+func (b *Backend) VerifyMessage(ctx context.Context, message, signature string) (*models.VerifiedMessage, error) {
+	b.RLock()
+	defer b.RUnlock()
+	pubkeys := []string{b.info.PubKey}
+	for _, peer := range b.peers {
+		pubkeys = append(pubkeys, peer.PubKey)
+	}
+	for _, pubkey := range pubkeys {
+		if mockSignature(pubkey, message) == signature {
+			return &models.VerifiedMessage{Valid: true, PubKey: pubkey}, nil
+		}
+	}
+	return &models.VerifiedMessage{}, nil
 }
 
+func mockSignature(pubkey, message string) string {
+	hash := sha256.Sum256([]byte(pubkey + message))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetForwardingHistory returns the last maxNumEvents forwarding events
+// since startTime.
 func (b *Backend) GetForwardingHistory(ctx context.Context, startTime string, maxNumEvents uint32) ([]*models.ForwardingEvent, error) {
-	return []*models.ForwardingEvent{}, nil
+	ts, err := backend.ParseTime(startTime, time.Now())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	start := time.Unix(int64(ts), 0)
+
+	b.RLock()
+	defer b.RUnlock()
+	events := []*models.ForwardingEvent{}
+	for _, e := range b.forwards {
+		if e.EventTime.Before(start) {
+			continue
+		}
+		event := *e
+		events = append(events, &event)
+	}
+	if maxNumEvents > 0 && len(events) > int(maxNumEvents) {
+		events = events[len(events)-int(maxNumEvents):]
+	}
+	return events, nil
+}
+
+func (b *Backend) ListPeers(ctx context.Context) ([]*models.Peer, error) {
+	b.RLock()
+	defer b.RUnlock()
+	peers := make([]*models.Peer, len(b.peers))
+	for i := range b.peers {
+		peer := *b.peers[i]
+		peers[i] = &peer
+	}
+	return peers, nil
+}
+
+// ConnectPeer connects to the node of the pubkey, known or not.
+func (b *Backend) ConnectPeer(ctx context.Context, pubkey, host string) error {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.peers {
+		if b.peers[i].PubKey == pubkey {
+			return errors.Errorf("already connected to peer: %s", pubkey)
+		}
+	}
+	b.peers = append(b.peers, &models.Peer{PubKey: pubkey, Address: host, Node: b.nodes[pubkey]})
+	return nil
+}
+
+// DisconnectPeer disconnects the peer, its channels are inactive.
+func (b *Backend) DisconnectPeer(ctx context.Context, pubkey string) error {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.peers {
+		if b.peers[i].PubKey != pubkey {
+			continue
+		}
+		b.peers = append(b.peers[:i], b.peers[i+1:]...)
+		for _, ch := range b.channels {
+			if ch.RemotePubKey == pubkey && ch.Status == models.ChannelActive {
+				ch.Status = models.ChannelInactive
+			}
+		}
+		publish(b.channelUpdates, &models.ChannelUpdate{})
+		return nil
+	}
+	return errors.New("peer is not connected")
+}
+
+func (b *Backend) ClosedChannels(ctx context.Context) ([]*models.ClosedChannel, error) {
+	b.RLock()
+	defer b.RUnlock()
+	closed := make([]*models.ClosedChannel, len(b.closed))
+	for i := range b.closed {
+		c := *b.closed[i]
+		closed[i] = &c
+	}
+	return closed, nil
+}
+
+func (b *Backend) PendingSweeps(ctx context.Context) ([]*models.PendingSweep, error) {
+	b.RLock()
+	defer b.RUnlock()
+	sweeps := make([]*models.PendingSweep, len(b.sweeps))
+	for i := range b.sweeps {
+		s := *b.sweeps[i]
+		sweeps[i] = &s
+	}
+	return sweeps, nil
+}
+
+func (b *Backend) Watchtower(ctx context.Context) (*models.Watchtower, error) {
+	b.RLock()
+	defer b.RUnlock()
+	wt := b.watchtower
+	wt.Towers = make([]*models.Tower, len(b.watchtower.Towers))
+	for i := range b.watchtower.Towers {
+		t := *b.watchtower.Towers[i]
+		t.Addresses = append([]string{}, t.Addresses...)
+		wt.Towers[i] = &t
+	}
+	return &wt, nil
+}
+
+// AddTower registers the tower, or adds the address to the registered
+// one.
+func (b *Backend) AddTower(ctx context.Context, pubkey, address string) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, t := range b.watchtower.Towers {
+		if t.PubKey != pubkey {
+			continue
+		}
+		for _, addr := range t.Addresses {
+			if addr == address {
+				return nil
+			}
+		}
+		t.Addresses = append(t.Addresses, address)
+		t.Active = true
+		return nil
+	}
+	b.watchtower.Towers = append(b.watchtower.Towers, &models.Tower{
+		PubKey:    pubkey,
+		Addresses: []string{address},
+		Active:    true,
+	})
+	return nil
+}
+
+// RemoveTower removes the tower, its sessions are lost.
+func (b *Backend) RemoveTower(ctx context.Context, pubkey string) error {
+	b.Lock()
+	defer b.Unlock()
+	for i, t := range b.watchtower.Towers {
+		if t.PubKey == pubkey {
+			b.watchtower.Towers = append(b.watchtower.Towers[:i], b.watchtower.Towers[i+1:]...)
+			return nil
+		}
+	}
+	return errors.Errorf("tower not found: %s", pubkey)
+}
+
+func (b *Backend) MissionControl(ctx context.Context) ([]*models.MissionPair, error) {
+	b.RLock()
+	defer b.RUnlock()
+	pairs := make([]*models.MissionPair, len(b.mission))
+	for i := range b.mission {
+		p := *b.mission[i]
+		pairs[i] = &p
+	}
+	return pairs, nil
+}
+
+// PairProbability returns 0.95 up to the amount of the last success, the
+// apriori 0.6 otherwise, recovering from the last failure of a lower
+// amount with a half-life of an hour.
+func (b *Backend) PairProbability(ctx context.Context, from, to string, amtMsat int64) (float64, error) {
+	b.RLock()
+	defer b.RUnlock()
+	for _, p := range b.mission {
+		if p.NodeFrom != from || p.NodeTo != to {
+			continue
+		}
+		if !p.SuccessTime.IsZero() && amtMsat <= p.SuccessAmtMsat {
+			return 0.95, nil
+		}
+		if !p.FailTime.IsZero() && amtMsat >= p.FailAmtMsat {
+			hours := time.Since(p.FailTime).Hours()
+			return mockApriori * (1 - math.Pow(0.5, hours)), nil
+		}
+		break
+	}
+	return mockApriori, nil
+}
+
+func (b *Backend) ResetMissionControl(ctx context.Context) error {
+	b.Lock()
+	defer b.Unlock()
+	b.mission = nil
+	return nil
+}
+
+// ImportMissionControl adds the pairs, the results of a known pair are
+// replaced by more recent ones, or by the imported ones if forced.
+func (b *Backend) ImportMissionControl(ctx context.Context, pairs []*models.MissionPair, force bool) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, imported := range pairs {
+		var pair *models.MissionPair
+		for _, p := range b.mission {
+			if p.NodeFrom == imported.NodeFrom && p.NodeTo == imported.NodeTo {
+				pair = p
+				break
+			}
+		}
+		if pair == nil {
+			p := *imported
+			b.mission = append(b.mission, &p)
+			continue
+		}
+		if !imported.FailTime.IsZero() && (force || imported.FailTime.After(pair.FailTime)) {
+			pair.FailTime, pair.FailAmtMsat = imported.FailTime, imported.FailAmtMsat
+		}
+		if !imported.SuccessTime.IsZero() && (force || imported.SuccessTime.After(pair.SuccessTime)) {
+			pair.SuccessTime, pair.SuccessAmtMsat = imported.SuccessTime, imported.SuccessAmtMsat
+		}
+	}
+	return nil
+}
+
+// BumpFee sets the fee rate of the pending sweep of the outpoint.
+func (b *Backend) BumpFee(ctx context.Context, outpoint string, satPerVbyte uint64) error {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.sweeps {
+		if b.sweeps[i].Outpoint == outpoint {
+			b.sweeps[i].RequestedSatPerVbyte = satPerVbyte
+			b.sweeps[i].SatPerVbyte = satPerVbyte
+			b.sweeps[i].BroadcastAttempts++
+			b.sweeps[i].NextBroadcastHeight = b.info.BlockHeight + 1
+			return nil
+		}
+	}
+	return errors.Errorf("unable to find pending sweep %s", outpoint)
+}
+
+func (b *Backend) ListUnspent(ctx context.Context) ([]*models.UTXO, error) {
+	b.RLock()
+	defer b.RUnlock()
+	utxos := make([]*models.UTXO, len(b.utxos))
+	for i := range b.utxos {
+		u := *b.utxos[i]
+		utxos[i] = &u
+	}
+	return utxos, nil
+}
+
+// Consolidate replaces the outputs by an unconfirmed output of their
+// amount minus the estimated fee.
+func (b *Backend) Consolidate(ctx context.Context, utxos []*models.UTXO, satPerVbyte uint64) (*models.Consolidation, error) {
+	fee, amount, err := models.ConsolidationFee(utxos, satPerVbyte)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	spent := make(map[string]bool, len(utxos))
+	for _, u := range utxos {
+		spent[u.Outpoint] = true
+	}
+	remaining := make([]*models.UTXO, 0, len(b.utxos))
+	for _, u := range b.utxos {
+		if spent[u.Outpoint] {
+			if u.Leased() {
+				return nil, errors.Errorf("output %s is leased", u.Outpoint)
+			}
+			delete(spent, u.Outpoint)
+			continue
+		}
+		remaining = append(remaining, u)
+	}
+	for outpoint := range spent {
+		return nil, errors.Errorf("unable to find output %s", outpoint)
+	}
+
+	b.count++
+	hash := sha256.Sum256([]byte(fmt.Sprintf("consolidation %d", b.count)))
+	c := &models.Consolidation{
+		TxID:    hex.EncodeToString(hash[:]),
+		Address: "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080",
+		Inputs:  len(utxos),
+		Amount:  amount,
+		Fee:     fee,
+	}
+	b.utxos = append(remaining, &models.UTXO{
+		Outpoint:    c.TxID + ":0",
+		Address:     c.Address,
+		AddressType: models.AddressP2WKH,
+		Amount:      amount,
+	})
+	return c, nil
+}
+
+// UpdateChannelPolicy sets the local policy of the channel.
+func (b *Backend) UpdateChannelPolicy(ctx context.Context, channel *models.Channel, policy *models.RoutingPolicy) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, ch := range b.channels {
+		if ch.ChannelPoint == channel.ChannelPoint {
+			ch.LocalPolicy = copyPolicy(policy)
+			ch.LocalPolicy.LastUpdate = time.Now()
+			return nil
+		}
+	}
+	return errors.Errorf("unable to find channel %s", channel.ChannelPoint)
+}
+
+// CloseChannel sets the channel waiting for the confirmation of its close,
+// RemoveChannel closes it.
+func (b *Backend) CloseChannel(ctx context.Context, channel *models.Channel, force bool, satPerVbyte uint64) (string, error) {
+	b.Lock()
+	defer b.Unlock()
+	for _, ch := range b.channels {
+		if ch.ChannelPoint != channel.ChannelPoint {
+			continue
+		}
+		if ch.Status != models.ChannelActive && ch.Status != models.ChannelInactive {
+			return "", errors.Errorf("channel %s is %s", ch.ChannelPoint, ch.StatusName())
+		}
+		b.count++
+		hash := sha256.Sum256([]byte(fmt.Sprintf("close %d", b.count)))
+		ch.Status = models.ChannelWaitingClose
+		ch.CloseType = models.CloseCooperative
+		if force {
+			ch.CloseType = models.CloseLocalForce
+		}
+		ch.Closing = &models.Closing{
+			ClosingTxID:  hex.EncodeToString(hash[:]),
+			LimboBalance: ch.LocalBalance,
+		}
+		publish(b.channelUpdates, &models.ChannelUpdate{})
+		return ch.Closing.ClosingTxID, nil
+	}
+	return "", errors.Errorf("unable to find channel %s", channel.ChannelPoint)
+}
+
+// LabelTransaction sets the label of the outputs of the transaction.
+func (b *Backend) LabelTransaction(ctx context.Context, txid, label string) error {
+	b.Lock()
+	defer b.Unlock()
+	found := false
+	for _, u := range b.utxos {
+		if u.TxID() == txid {
+			u.Label = label
+			found = true
+		}
+	}
+	if !found {
+		return errors.Errorf("unable to find transaction %s", txid)
+	}
+	return nil
+}
+
+// spend removes the outputs of the wallet and returns their amount, the
+// largest outputs are selected if none is given.
+func (b *Backend) spend(outpoints []string, amount int64) (int64, error) {
+	if len(outpoints) == 0 {
+		utxos := make([]*models.UTXO, len(b.utxos))
+		copy(utxos, b.utxos)
+		sort.Slice(utxos, func(i, j int) bool { return utxos[i].Amount > utxos[j].Amount })
+		var total int64
+		for _, u := range utxos {
+			if total >= amount {
+				break
+			}
+			outpoints = append(outpoints, u.Outpoint)
+			total += u.Amount
+		}
+	}
+
+	spent := make(map[string]bool, len(outpoints))
+	for _, outpoint := range outpoints {
+		spent[outpoint] = true
+	}
+	var total int64
+	remaining := make([]*models.UTXO, 0, len(b.utxos))
+	for _, u := range b.utxos {
+		if spent[u.Outpoint] {
+			delete(spent, u.Outpoint)
+			total += u.Amount
+			continue
+		}
+		remaining = append(remaining, u)
+	}
+	for outpoint := range spent {
+		return 0, errors.Errorf("unable to find output %s", outpoint)
+	}
+	if total < amount {
+		return 0, errors.Errorf("insufficient funds: %d sat available", total)
+	}
+	b.utxos = remaining
+	return total, nil
+}
+
+// SendOnChain spends the outputs and adds the change as an unconfirmed
+// output, the fee is the size of a one input two outputs transaction.
+func (b *Backend) SendOnChain(ctx context.Context, address string, amount int64, satPerVbyte uint64, outpoints []string) (string, error) {
+	b.Lock()
+	defer b.Unlock()
+	fee := int64(141 * satPerVbyte)
+	total, err := b.spend(outpoints, amount+fee)
+	if err != nil {
+		return "", err
+	}
+	b.count++
+	hash := sha256.Sum256([]byte(fmt.Sprintf("send %d", b.count)))
+	txid := hex.EncodeToString(hash[:])
+	if change := total - amount - fee; change > 0 {
+		b.utxos = append(b.utxos, &models.UTXO{
+			Outpoint:    txid + ":1",
+			Address:     "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080",
+			AddressType: models.AddressP2WKH,
+			Amount:      change,
+		})
+	}
+	return txid, nil
+}
+
+// OpenChannel spends the outputs like SendOnChain and adds an opening
+// channel with the peer.
+func (b *Backend) OpenChannel(ctx context.Context, pubkey string, amount int64, satPerVbyte uint64,
+	private bool, outpoints []string) (string, error) {
+	txid, err := b.SendOnChain(ctx, pubkey, amount, satPerVbyte, outpoints)
+	if err != nil {
+		return "", err
+	}
+	b.SetChannel(&models.Channel{
+		Status:       models.ChannelOpening,
+		RemotePubKey: pubkey,
+		ChannelPoint: txid + ":0",
+		Capacity:     amount,
+		LocalBalance: amount,
+		Private:      private,
+	})
+	return txid + ":0", nil
+}
+
+// batchVsize returns the size of a one input transaction funding the
+// channels with a change output.
+func batchVsize(channels []*models.BatchChannel) int64 {
+	return int64(141 + 43*(len(channels)-1))
+}
+
+// EstimateBatchOpen returns the fee of the batch at the fee rate, 2
+// sat/vbyte if zero.
+func (b *Backend) EstimateBatchOpen(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (*models.FeeEstimate, error) {
+	if satPerVbyte == 0 {
+		satPerVbyte = 2
+	}
+	return &models.FeeEstimate{
+		Fee:         batchVsize(channels) * int64(satPerVbyte),
+		SatPerVbyte: satPerVbyte,
+	}, nil
+}
+
+// BatchOpenChannel spends the outputs for the channels and their fee and
+// adds an opening channel for each of them.
+func (b *Backend) BatchOpenChannel(ctx context.Context, channels []*models.BatchChannel,
+	satPerVbyte uint64) (string, error) {
+	estimate, err := b.EstimateBatchOpen(ctx, channels, satPerVbyte)
+	if err != nil {
+		return "", err
+	}
+	amount := models.BatchAmount(channels)
+
+	b.Lock()
+	total, err := b.spend(nil, amount+estimate.Fee)
+	if err != nil {
+		b.Unlock()
+		return "", err
+	}
+	b.count++
+	hash := sha256.Sum256([]byte(fmt.Sprintf("batch %d", b.count)))
+	txid := hex.EncodeToString(hash[:])
+	if change := total - amount - estimate.Fee; change > 0 {
+		b.utxos = append(b.utxos, &models.UTXO{
+			Outpoint:    fmt.Sprintf("%s:%d", txid, len(channels)),
+			Address:     "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080",
+			AddressType: models.AddressP2WKH,
+			Amount:      change,
+		})
+	}
+	b.Unlock()
+
+	for i, ch := range channels {
+		b.SetChannel(&models.Channel{
+			Status:       models.ChannelOpening,
+			RemotePubKey: ch.PubKey,
+			ChannelPoint: fmt.Sprintf("%s:%d", txid, i),
+			Capacity:     ch.Amount,
+			LocalBalance: ch.Amount,
+			Private:      ch.Private,
+		})
+	}
+	return txid, nil
+}
+
+func (b *Backend) SubscribeChannelBackups(ctx context.Context, channel chan *models.ChannelBackup) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case backup := <-b.backupUpdates:
+			channel <- backup
+		}
+	}
+}
+
+func (b *Backend) VerifyChannelBackup(ctx context.Context, backup *models.ChannelBackup) error {
+	b.RLock()
+	defer b.RUnlock()
+	return b.backupErr
+}
+
+// EstimateFeeRate returns 20 sat/vbyte for the next block, falling to 1
+// sat/vbyte for a day.
+func (b *Backend) EstimateFeeRate(ctx context.Context, target uint32) (uint64, error) {
+	if target < 1 {
+		target = 1
+	}
+	return max(40/uint64(target+1), 1), nil
+}
+
+// ExportChannelBackup returns the last backup snapshot published, or a
+// snapshot of the open channels.
+func (b *Backend) ExportChannelBackup(ctx context.Context) (*models.ChannelBackup, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.backup != nil {
+		return b.backup, nil
+	}
+	backup := &models.ChannelBackup{}
+	for _, ch := range b.channels {
+		if ch.Status == models.ChannelActive || ch.Status == models.ChannelInactive {
+			backup.ChanPoints = append(backup.ChanPoints, ch.ChannelPoint)
+		}
+	}
+	backup.Multi = make([]byte, 64+len(backup.ChanPoints)*128)
+	return backup, nil
+}
+
+// InterceptHTLCs sends the HTLCs published with PublishInterceptedHTLC
+// and records the resolutions.
+func (b *Backend) InterceptHTLCs(ctx context.Context, htlcs chan *models.InterceptedHTLC,
+	resolutions chan *models.HTLCResolution) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case htlc := <-b.intercepts:
+			select {
+			case htlcs <- htlc:
+			case <-ctx.Done():
+				return nil
+			}
+		case r := <-resolutions:
+			b.Lock()
+			b.resolutions = append(b.resolutions, r)
+			b.Unlock()
+		}
+	}
+}
+
+func (b *Backend) GetState(ctx context.Context) (models.NodeState, error) {
+	b.RLock()
+	defer b.RUnlock()
+	return b.state, nil
+}
+
+func (b *Backend) BakeMacaroon(ctx context.Context, permissions []models.Permission) ([]byte, error) {
+	return []byte("mock macaroon"), nil
+}
+
+func (b *Backend) NewAddress(ctx context.Context) (string, error) {
+	return "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080", nil
 }
 
 func (b *Backend) CreateInvoice(ctx context.Context, amt int64, desc string) (*models.Invoice, error) {
@@ -108,15 +1100,105 @@ func (b *Backend) CreateInvoice(ctx context.Context, amt int64, desc string) (*m
 		Description:    desc,
 		CreationDate:   time.Now().Unix(),
 		Expiry:         3600,
-		PaymentRequest: "lnbc28600u1pw9n7g7pp5enjn8exsyymyl6mlxmcvy7fdcwuh04z96swfmtasznppglgdyvsqdqqcqzysc8rve6vdwuvketcn7yp8gu3ltvq29vj588erp3at9z2msqj0yhhjdwsf7qtfy5lwf8favm6u3wr5qklvprlhrz89pknpdfxnc55wy6sqnrxjh7",
+		PaymentRequest: PayReq(amt, hash[:]),
 	}
 
 	b.invoices[string(invoice.RHash)] = *invoice
+	publish(b.invoiceUpdates, invoice)
 
 	return invoice, nil
 }
 
+// PayReq returns a payment request of the amount unique to the hash, it
+// has the form of a BOLT11 invoice but cannot be paid.
+func PayReq(amount int64, hash []byte) string {
+	hrp := "lnbc"
+	if amount > 0 {
+		hrp += fmt.Sprintf("%dn", amount*10)
+	}
+	return bech32String(hrp, hash)
+}
+
+// Offer returns a BOLT12 offer unique to the hash, it cannot be paid.
+func Offer(hash []byte) string {
+	return bech32String("lno", hash)
+}
+
+// bech32String returns a string of the hrp followed by bech32 characters
+// derived from the hash.
+func bech32String(hrp string, hash []byte) string {
+	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	data := []byte{}
+	for h := sha256.Sum256(hash); len(data) < 120; h = sha256.Sum256(h[:]) {
+		data = append(data, h[:]...)
+	}
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for i := 0; i < len(data)*8/5; i++ {
+		bit := i * 5
+		v := (uint(data[bit/8])<<8 | uint(data[(bit/8+1)%len(data)])) >> (11 - bit%8) & 31
+		b.WriteByte(charset[v])
+	}
+	return b.String()
+}
+
+// ListOffers returns the offers in the order of their creation.
+func (b *Backend) ListOffers(ctx context.Context) ([]*models.Offer, error) {
+	b.RLock()
+	defer b.RUnlock()
+	offers := make([]*models.Offer, len(b.offers))
+	for i := range b.offers {
+		o := *b.offers[i]
+		o.Requests = append([]*models.OfferRequest{}, o.Requests...)
+		offers[i] = &o
+	}
+	return offers, nil
+}
+
+func (b *Backend) CreateOffer(ctx context.Context, amount int64, description string) (*models.Offer, error) {
+	b.Lock()
+	defer b.Unlock()
+	id := sha256.Sum256([]byte(uuid.Must(uuid.NewV4()).String()))
+	offer := &models.Offer{
+		ID:          hex.EncodeToString(id[:]),
+		Bolt12:      Offer(id[:]),
+		Description: description,
+		Amount:      amount,
+		Status:      models.OfferActive,
+	}
+	b.offers = append(b.offers, offer)
+	o := *offer
+	return &o, nil
+}
+
+func (b *Backend) DisableOffer(ctx context.Context, id string) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, o := range b.offers {
+		if o.ID == id {
+			o.Status = models.OfferDisabled
+			return nil
+		}
+	}
+	return errors.Errorf("unknown offer %s", id)
+}
+
+// ListInvoices returns the invoices in the order of their index.
+func (b *Backend) ListInvoices(ctx context.Context) ([]*models.Invoice, error) {
+	b.RLock()
+	defer b.RUnlock()
+	invoices := make([]*models.Invoice, 0, len(b.invoices))
+	for _, invoice := range b.invoices {
+		i := invoice
+		invoices = append(invoices, &i)
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].Index < invoices[j].Index })
+	return invoices, nil
+}
+
 func (b *Backend) GetInvoice(ctx context.Context, hash string) (*models.Invoice, error) {
+	b.RLock()
+	defer b.RUnlock()
 	invoice, ok := b.invoices[hash]
 	if !ok {
 		return nil, errors.New("unable to locate invoice")
@@ -125,9 +1207,321 @@ func (b *Backend) GetInvoice(ctx context.Context, hash string) (*models.Invoice,
 	return &invoice, nil
 }
 
+// SettleInvoice marks the invoice of the given hex payment hash as
+// settled and publishes it.
+func (b *Backend) SettleInvoice(rhash string) error {
+	hash, err := hex.DecodeString(rhash)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	invoice, ok := b.invoices[string(hash)]
+	if !ok {
+		return errors.New("unable to locate invoice")
+	}
+	invoice.Settled = true
+	invoice.State = models.InvoiceSettled
+	invoice.AmountPaid = invoice.Amount
+	invoice.SettleDate = time.Now().Unix()
+	b.invoices[string(hash)] = invoice
+	b.walletBalance.TotalBalance += invoice.Amount
+	b.walletBalance.ConfirmedBalance += invoice.Amount
+	publish(b.invoiceUpdates, &invoice)
+	return nil
+}
+
+// SetInfo replaces the node info, the channels and peers counters are
+// computed from the state.
+func (b *Backend) SetInfo(info models.Info) {
+	b.Lock()
+	defer b.Unlock()
+	b.info = info
+}
+
+func (b *Backend) SetWalletBalance(balance models.WalletBalance) {
+	b.Lock()
+	defer b.Unlock()
+	b.walletBalance = balance
+}
+
+func (b *Backend) AddNode(node *models.Node) {
+	b.Lock()
+	defer b.Unlock()
+	b.nodes[node.PubKey] = node
+}
+
+// SetChannel adds the channel or replaces the channel with the same
+// channel point, and publishes a channel update.
+func (b *Backend) SetChannel(channel *models.Channel) {
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.nodes[channel.RemotePubKey]; !ok && channel.Node != nil {
+		b.nodes[channel.RemotePubKey] = channel.Node
+	}
+
+	replaced := false
+	for i := range b.channels {
+		if b.channels[i].ChannelPoint == channel.ChannelPoint {
+			b.channels[i] = channel
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		b.channels = append(b.channels, channel)
+	}
+	publish(b.channelUpdates, &models.ChannelUpdate{
+		ChannelPoint: channel.ChannelPoint,
+		RemotePubKey: channel.RemotePubKey,
+		Status:       channel.Status,
+	})
+}
+
+// RemoveChannel removes the channel and publishes its close with the
+// close type, models.CloseCooperative, models.CloseLocalForce, ...
+func (b *Backend) RemoveChannel(chanPoint string, closeType int) error {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.channels {
+		if b.channels[i].ChannelPoint == chanPoint {
+			update := &models.ChannelUpdate{
+				ChannelPoint: chanPoint,
+				RemotePubKey: b.channels[i].RemotePubKey,
+				CloseType:    closeType,
+			}
+			closer := models.InitiatorLocal
+			if closeType == models.CloseRemoteForce || closeType == models.CloseBreach {
+				closer = models.InitiatorRemote
+			}
+			b.closed = append(b.closed, &models.ClosedChannel{
+				ID:             b.channels[i].ID,
+				ChannelPoint:   chanPoint,
+				RemotePubKey:   b.channels[i].RemotePubKey,
+				Capacity:       b.channels[i].Capacity,
+				SettledBalance: b.channels[i].LocalBalance,
+				CloseHeight:    b.info.BlockHeight,
+				CloseType:      closeType,
+				OpenInitiator:  models.InitiatorLocal,
+				CloseInitiator: closer,
+				CloseTime:      time.Now(),
+			})
+			b.channels = append(b.channels[:i], b.channels[i+1:]...)
+			publish(b.channelUpdates, update)
+			return nil
+		}
+	}
+	return errors.Errorf("unable to find channel %s", chanPoint)
+}
+
+func (b *Backend) AddTransaction(tx *models.Transaction) {
+	b.Lock()
+	defer b.Unlock()
+	b.transactions = append(b.transactions, tx)
+	sort.SliceStable(b.transactions, func(i, j int) bool {
+		return b.transactions[i].Date.Before(b.transactions[j].Date)
+	})
+	publish(b.transactionUpdates, tx)
+}
+
+// ConfirmTransaction includes the transaction in the block, its
+// confirmations follow the height of the info.
+func (b *Backend) ConfirmTransaction(txid, blockHash string, height uint32) {
+	b.Lock()
+	defer b.Unlock()
+	for _, tx := range b.transactions {
+		if tx.TxHash == txid {
+			tx.BlockHash = blockHash
+			tx.BlockHeight = int32(height)
+			publish(b.transactionUpdates, tx)
+			return
+		}
+	}
+}
+
+func (b *Backend) AddForwardingEvent(event *models.ForwardingEvent) {
+	b.Lock()
+	defer b.Unlock()
+	b.forwards = append(b.forwards, event)
+	sort.SliceStable(b.forwards, func(i, j int) bool {
+		return b.forwards[i].EventTime.Before(b.forwards[j].EventTime)
+	})
+}
+
+// SetPeer adds the peer or replaces the peer with the same pubkey.
+func (b *Backend) SetPeer(peer *models.Peer) {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.peers {
+		if b.peers[i].PubKey == peer.PubKey {
+			b.peers[i] = peer
+			return
+		}
+	}
+	b.peers = append(b.peers, peer)
+}
+
+func (b *Backend) RemovePeer(pubkey string) {
+	b.Lock()
+	defer b.Unlock()
+	for i := range b.peers {
+		if b.peers[i].PubKey == pubkey {
+			b.peers = append(b.peers[:i], b.peers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetClosedChannels replaces the channels closed on chain.
+func (b *Backend) SetClosedChannels(closed []*models.ClosedChannel) {
+	b.Lock()
+	defer b.Unlock()
+	b.closed = closed
+}
+
+// SetPayments replaces the payments of the node.
+func (b *Backend) SetPayments(payments []*models.Payment) {
+	b.Lock()
+	defer b.Unlock()
+	b.payments = payments
+}
+
+// SetInvoices replaces the invoices of the node.
+func (b *Backend) SetInvoices(invoices []*models.Invoice) {
+	b.Lock()
+	defer b.Unlock()
+	b.invoices = make(map[string]models.Invoice, len(invoices))
+	for _, invoice := range invoices {
+		b.invoices[string(invoice.RHash)] = *invoice
+		if invoice.Index > b.count {
+			b.count = invoice.Index
+		}
+	}
+}
+
+// SetOffers replaces the offers of the node.
+func (b *Backend) SetOffers(offers []*models.Offer) {
+	b.Lock()
+	defer b.Unlock()
+	b.offers = offers
+}
+
+// SetPendingSweeps replaces the outputs being swept by the wallet.
+func (b *Backend) SetPendingSweeps(sweeps []*models.PendingSweep) {
+	b.Lock()
+	defer b.Unlock()
+	b.sweeps = sweeps
+}
+
+// SetWatchtower replaces the state of the watchtower client.
+func (b *Backend) SetWatchtower(wt models.Watchtower) {
+	b.Lock()
+	defer b.Unlock()
+	b.watchtower = wt
+}
+
+// SetMissionControl replaces the pairs of mission control.
+func (b *Backend) SetMissionControl(pairs []*models.MissionPair) {
+	b.Lock()
+	defer b.Unlock()
+	b.mission = pairs
+}
+
+// SetUTXOs replaces the unspent outputs of the wallet.
+func (b *Backend) SetUTXOs(utxos []*models.UTXO) {
+	b.Lock()
+	defer b.Unlock()
+	b.utxos = utxos
+}
+
+// SetState sets the state of the node returned by GetState, the other
+// calls keep answering.
+func (b *Backend) SetState(state models.NodeState) {
+	b.Lock()
+	defer b.Unlock()
+	b.state = state
+}
+
+func (b *Backend) PublishRoutingEvent(event *models.RoutingEvent) {
+	publish(b.routingUpdates, event)
+}
+
+// PublishChannelBackup sends a backup snapshot of the channels, it fails
+// the verification when err is not nil.
+func (b *Backend) PublishChannelBackup(backup *models.ChannelBackup, err error) {
+	b.Lock()
+	b.backupErr = err
+	b.backup = backup
+	b.Unlock()
+	publish(b.backupUpdates, backup)
+}
+
+func (b *Backend) PublishGraphUpdate(update *models.ChannelEdgeUpdate) {
+	publish(b.graphUpdates, update)
+}
+
+// PublishInterceptedHTLC sends the HTLC to the interceptor.
+func (b *Backend) PublishInterceptedHTLC(htlc *models.InterceptedHTLC) {
+	publish(b.intercepts, htlc)
+}
+
+// Resolutions returns the resolutions of the intercepted HTLCs, in their
+// order.
+func (b *Backend) Resolutions() []*models.HTLCResolution {
+	b.RLock()
+	defer b.RUnlock()
+	resolutions := make([]*models.HTLCResolution, len(b.resolutions))
+	copy(resolutions, b.resolutions)
+	return resolutions
+}
+
+func publish[T any](updates chan T, update T) {
+	select {
+	case updates <- update:
+	default:
+	}
+}
+
+func copyChannel(ch *models.Channel) *models.Channel {
+	channel := *ch
+	channel.LocalPolicy = copyPolicy(ch.LocalPolicy)
+	channel.RemotePolicy = copyPolicy(ch.RemotePolicy)
+	return &channel
+}
+
+func copyPolicy(p *models.RoutingPolicy) *models.RoutingPolicy {
+	if p == nil {
+		return nil
+	}
+	policy := *p
+	return &policy
+}
+
 func New(c *config.Network) *Backend {
 	return &Backend{
-		invoices: make(map[string]models.Invoice),
-		cfg:      c,
+		cfg:   c,
+		state: models.NodeStateServerActive,
+		info: models.Info{
+			PubKey:      "02a3aa1e0bd0cb2d3d7a6ea0bd8bd62bbfc49b8fa1f5a1d8d9876d5f2e1a0b9c8d",
+			Alias:       c.Name,
+			BlockHeight: 1,
+			Synced:      true,
+			Version:     "mock",
+			Chains:      []string{"bitcoin"},
+			Testnet:     true,
+			Network:     "testnet",
+		},
+		nodes:              make(map[string]*models.Node),
+		channels:           []*models.Channel{},
+		invoices:           make(map[string]models.Invoice),
+		invoiceUpdates:     make(chan *models.Invoice, updatesBuffer),
+		channelUpdates:     make(chan *models.ChannelUpdate, updatesBuffer),
+		transactionUpdates: make(chan *models.Transaction, updatesBuffer),
+		routingUpdates:     make(chan *models.RoutingEvent, updatesBuffer),
+		graphUpdates:       make(chan *models.ChannelEdgeUpdate, updatesBuffer),
+		backupUpdates:      make(chan *models.ChannelBackup, updatesBuffer),
+		paymentUpdates:     make(chan *models.Payment, updatesBuffer),
+		intercepts:         make(chan *models.InterceptedHTLC, updatesBuffer),
 	}
 }
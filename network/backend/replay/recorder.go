@@ -0,0 +1,320 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+var _ backend.Backend = (*Recorder)(nil)
+
+// Recorder is a backend writing the answers of the reads and the updates
+// of the subscriptions of the backend it wraps to the recording. The
+// actions are passed to the backend and are not recorded.
+type Recorder struct {
+	backend.Backend
+	logger logging.Logger
+	start  time.Time
+
+	mu sync.Mutex
+	w  io.Writer
+	// last is the last answer recorded by call and key, an answer is
+	// recorded only if it changed.
+	last map[string]string
+	// err is the first error writing the recording, nothing is written
+	// after it.
+	err error
+}
+
+// NewRecorder writes the header of the recording to w and returns the
+// recorder of the backend.
+func NewRecorder(b backend.Backend, w io.Writer, logger logging.Logger) (*Recorder, error) {
+	r := &Recorder{
+		Backend: b,
+		logger:  logger.With(logging.String("logger", "recorder")),
+		start:   time.Now(),
+		w:       w,
+		last:    make(map[string]string),
+	}
+	data, err := json.Marshal(header{Version: version, Node: b.NodeName(), Start: r.start})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return r, nil
+}
+
+// record writes the answer of the call, or its error.
+func (r *Recorder) record(call, key string, data interface{}, err error) {
+	e := &entry{Call: call, Key: key}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	r.write(e, data, err == nil)
+}
+
+func (r *Recorder) recordUpdate(sub string, data interface{}) {
+	r.write(&entry{Sub: sub}, data, true)
+}
+
+func (r *Recorder) write(e *entry, data interface{}, withData bool) {
+	if withData {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			r.logger.Error("cannot record", logging.String("call", e.Call+e.Sub), logging.Error(err))
+			return
+		}
+		e.Data = raw
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if e.Call != "" {
+		id := e.Call + "\x00" + e.Key
+		answer := e.Error + string(e.Data)
+		if last, ok := r.last[id]; ok && last == answer {
+			return
+		}
+		r.last[id] = answer
+	}
+	e.At = time.Since(r.start).Milliseconds()
+	line, err := json.Marshal(e)
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = err
+		r.logger.Error("cannot write the recording, nothing more is recorded", logging.Error(err))
+	}
+}
+
+// recordSubscription runs the subscription of the backend and records its
+// updates before sending them to out.
+func recordSubscription[T any](ctx context.Context, r *Recorder, sub string, out chan T,
+	subscribe func(context.Context, chan T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	in := make(chan T)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		err = subscribe(ctx, in)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		// the backend may still be sending an update, it is dropped.
+		go func() {
+			for {
+				select {
+				case <-in:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-done:
+			return err
+		case update := <-in:
+			r.recordUpdate(sub, update)
+			select {
+			case out <- update:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func (r *Recorder) SubscribeInvoice(ctx context.Context, channel chan *models.Invoice) error {
+	return recordSubscription(ctx, r, "invoices", channel, r.Backend.SubscribeInvoice)
+}
+
+func (r *Recorder) SubscribeChannels(ctx context.Context, channel chan *models.ChannelUpdate) error {
+	return recordSubscription(ctx, r, "channels", channel, r.Backend.SubscribeChannels)
+}
+
+func (r *Recorder) SubscribePayments(ctx context.Context, channel chan *models.Payment) error {
+	return recordSubscription(ctx, r, "payments", channel, r.Backend.SubscribePayments)
+}
+
+func (r *Recorder) SubscribeTransactions(ctx context.Context, channel chan *models.Transaction) error {
+	return recordSubscription(ctx, r, "transactions", channel, r.Backend.SubscribeTransactions)
+}
+
+func (r *Recorder) SubscribeRoutingEvents(ctx context.Context, channel chan *models.RoutingEvent) error {
+	return recordSubscription(ctx, r, "routing", channel, r.Backend.SubscribeRoutingEvents)
+}
+
+func (r *Recorder) SubscribeGraphEvents(ctx context.Context, channel chan *models.ChannelEdgeUpdate) error {
+	return recordSubscription(ctx, r, "graph", channel, r.Backend.SubscribeGraphEvents)
+}
+
+func (r *Recorder) Info(ctx context.Context) (*models.Info, error) {
+	info, err := r.Backend.Info(ctx)
+	r.record("Info", "", info, err)
+	return info, err
+}
+
+func (r *Recorder) GetState(ctx context.Context) (models.NodeState, error) {
+	state, err := r.Backend.GetState(ctx)
+	r.record("GetState", "", state, err)
+	return state, err
+}
+
+func (r *Recorder) GetNode(ctx context.Context, pubkey string, includeChannels bool) (*models.Node, error) {
+	node, err := r.Backend.GetNode(ctx, pubkey, includeChannels)
+	r.record("GetNode", nodeKey(pubkey, includeChannels), node, err)
+	return node, err
+}
+
+func (r *Recorder) ListNodes(ctx context.Context) ([]*models.Node, error) {
+	nodes, err := r.Backend.ListNodes(ctx)
+	r.record("ListNodes", "", nodes, err)
+	return nodes, err
+}
+
+func (r *Recorder) GetWalletBalance(ctx context.Context) (*models.WalletBalance, error) {
+	balance, err := r.Backend.GetWalletBalance(ctx)
+	r.record("GetWalletBalance", "", balance, err)
+	return balance, err
+}
+
+func (r *Recorder) GetChannelsBalance(ctx context.Context) (*models.ChannelsBalance, error) {
+	balance, err := r.Backend.GetChannelsBalance(ctx)
+	r.record("GetChannelsBalance", "", balance, err)
+	return balance, err
+}
+
+func (r *Recorder) ListChannels(ctx context.Context, opts ...options.Channel) ([]*models.Channel, error) {
+	channels, err := r.Backend.ListChannels(ctx, opts...)
+	r.record("ListChannels", channelsKey(opts), channels, err)
+	return channels, err
+}
+
+// GetChannelInfo records the channel completed by the backend.
+func (r *Recorder) GetChannelInfo(ctx context.Context, channel *models.Channel) error {
+	err := r.Backend.GetChannelInfo(ctx, channel)
+	r.record("GetChannelInfo", strconv.FormatUint(channel.ID, 10), channel, err)
+	return err
+}
+
+func (r *Recorder) GetInvoice(ctx context.Context, hash string) (*models.Invoice, error) {
+	invoice, err := r.Backend.GetInvoice(ctx, hash)
+	r.record("GetInvoice", hash, invoice, err)
+	return invoice, err
+}
+
+func (r *Recorder) ListInvoices(ctx context.Context) ([]*models.Invoice, error) {
+	invoices, err := r.Backend.ListInvoices(ctx)
+	r.record("ListInvoices", "", invoices, err)
+	return invoices, err
+}
+
+func (r *Recorder) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, error) {
+	p, err := r.Backend.DecodePayReq(ctx, payreq)
+	r.record("DecodePayReq", payreq, p, err)
+	return p, err
+}
+
+func (r *Recorder) ListPayments(ctx context.Context) ([]*models.Payment, error) {
+	payments, err := r.Backend.ListPayments(ctx)
+	r.record("ListPayments", "", payments, err)
+	return payments, err
+}
+
+func (r *Recorder) GetTransactions(ctx context.Context) ([]*models.Transaction, error) {
+	txs, err := r.Backend.GetTransactions(ctx)
+	r.record("GetTransactions", "", txs, err)
+	return txs, err
+}
+
+// GetForwardingHistory records the history without its start time, it is
+// relative to the time of the call.
+func (r *Recorder) GetForwardingHistory(ctx context.Context, start string, max uint32) ([]*models.ForwardingEvent, error) {
+	forwards, err := r.Backend.GetForwardingHistory(ctx, start, max)
+	r.record("GetForwardingHistory", strconv.FormatUint(uint64(max), 10), forwards, err)
+	return forwards, err
+}
+
+func (r *Recorder) ListPeers(ctx context.Context) ([]*models.Peer, error) {
+	peers, err := r.Backend.ListPeers(ctx)
+	r.record("ListPeers", "", peers, err)
+	return peers, err
+}
+
+func (r *Recorder) ClosedChannels(ctx context.Context) ([]*models.ClosedChannel, error) {
+	closed, err := r.Backend.ClosedChannels(ctx)
+	r.record("ClosedChannels", "", closed, err)
+	return closed, err
+}
+
+func (r *Recorder) PendingSweeps(ctx context.Context) ([]*models.PendingSweep, error) {
+	sweeps, err := r.Backend.PendingSweeps(ctx)
+	r.record("PendingSweeps", "", sweeps, err)
+	return sweeps, err
+}
+
+func (r *Recorder) ListOffers(ctx context.Context) ([]*models.Offer, error) {
+	offers, err := r.Backend.ListOffers(ctx)
+	r.record("ListOffers", "", offers, err)
+	return offers, err
+}
+
+func (r *Recorder) ListUnspent(ctx context.Context) ([]*models.UTXO, error) {
+	utxos, err := r.Backend.ListUnspent(ctx)
+	r.record("ListUnspent", "", utxos, err)
+	return utxos, err
+}
+
+func (r *Recorder) Watchtower(ctx context.Context) (*models.Watchtower, error) {
+	tower, err := r.Backend.Watchtower(ctx)
+	r.record("Watchtower", "", tower, err)
+	return tower, err
+}
+
+func (r *Recorder) MissionControl(ctx context.Context) ([]*models.MissionPair, error) {
+	pairs, err := r.Backend.MissionControl(ctx)
+	r.record("MissionControl", "", pairs, err)
+	return pairs, err
+}
+
+func (r *Recorder) PairProbability(ctx context.Context, from, to string, amtMsat int64) (float64, error) {
+	p, err := r.Backend.PairProbability(ctx, from, to, amtMsat)
+	r.record("PairProbability", pairKey(from, to, amtMsat), p, err)
+	return p, err
+}
+
+func nodeKey(pubkey string, includeChannels bool) string {
+	return pubkey + " " + strconv.FormatBool(includeChannels)
+}
+
+func channelsKey(opts []options.Channel) string {
+	return fmt.Sprintf("%+v", options.NewChannelOptions(opts...))
+}
+
+func pairKey(from, to string, amtMsat int64) string {
+	return from + " " + to + " " + strconv.FormatInt(amtMsat, 10)
+}
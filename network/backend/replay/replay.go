@@ -0,0 +1,520 @@
+// Package replay records the node seen by lntop to a file and replays it
+// without a node, for the bug reports and the demos. The Recorder wraps
+// the backend of the node: the first line of the recording is its header,
+// each following line the answer of a read or an update of a subscription
+// with its time in milliseconds since the start:
+//
+//	{"version":1,"node":"alice","start":"2024-05-01T12:00:00Z"}
+//	{"at":12,"call":"ListChannels","key":"{Active:false ...}","data":[...]}
+//	{"at":5230,"sub":"routing","data":{...}}
+//
+// The Backend replays the recording of the address of the config: the
+// updates are sent to the subscriptions at their time and a read answers
+// the last answer recorded before, or the first one. The nodes, the
+// invoices and the payment requests are answered only if they were read
+// during the recording, the actions are not supported.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+// version is the version of the format of the recordings.
+const version = 1
+
+var (
+	errNotSupported = errors.New("not supported in a replay")
+	errNotRecorded  = errors.New("not in the recording")
+)
+
+type header struct {
+	Version int       `json:"version"`
+	Node    string    `json:"node"`
+	Start   time.Time `json:"start"`
+}
+
+// entry is the answer of a call, or an update of a subscription.
+type entry struct {
+	At    int64           `json:"at"`
+	Call  string          `json:"call,omitempty"`
+	Key   string          `json:"key,omitempty"`
+	Sub   string          `json:"sub,omitempty"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *entry) time() time.Duration {
+	return time.Duration(e.At) * time.Millisecond
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+type Backend struct {
+	cfg   *config.Network
+	start time.Time
+	// calls are the answers by call and key, and updates the updates by
+	// subscription, in their order.
+	calls   map[string][]*entry
+	updates map[string][]*entry
+
+	mu sync.Mutex
+	// sent is the number of updates sent by subscription, a subscription
+	// started again goes on from there.
+	sent map[string]int
+}
+
+// New reads the recording of the address of the config, it is replayed
+// from now.
+func New(cfg *config.Network) (*Backend, error) {
+	f, err := os.Open(cfg.Address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var h header
+	err = dec.Decode(&h)
+	if err != nil || h.Version == 0 {
+		return nil, errors.Errorf("%s is not a recording of lntop", cfg.Address)
+	}
+	if h.Version != version {
+		return nil, errors.Errorf("version %d of the recording is not supported", h.Version)
+	}
+
+	b := &Backend{
+		cfg:     cfg,
+		calls:   make(map[string][]*entry),
+		updates: make(map[string][]*entry),
+		sent:    make(map[string]int),
+	}
+	for {
+		e := &entry{}
+		err := dec.Decode(e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the last line of a recording interrupted may be cut.
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, errors.Wrapf(err, "invalid recording %s", cfg.Address)
+		}
+		if e.Sub != "" {
+			b.updates[e.Sub] = append(b.updates[e.Sub], e)
+		} else {
+			id := e.Call + "\x00" + e.Key
+			b.calls[id] = append(b.calls[id], e)
+		}
+	}
+	b.start = time.Now()
+	return b, nil
+}
+
+// answer decodes the answer of the call to out, it returns errNotRecorded
+// if the call was not recorded.
+func (b *Backend) answer(call, key string, out interface{}) error {
+	answers := b.calls[call+"\x00"+key]
+	if len(answers) == 0 {
+		return errNotRecorded
+	}
+	now := time.Since(b.start)
+	e := answers[0]
+	for _, a := range answers[1:] {
+		if a.time() > now {
+			break
+		}
+		e = a
+	}
+	if e.Error != "" {
+		return errors.New(e.Error)
+	}
+	return errors.WithStack(json.Unmarshal(e.Data, out))
+}
+
+// list decodes the answer of the call to out, left empty if the call was
+// not recorded.
+func (b *Backend) list(call string, out interface{}) error {
+	err := b.answer(call, "", out)
+	if err == errNotRecorded {
+		return nil
+	}
+	return err
+}
+
+// replaySubscription sends the updates of the subscription at their time,
+// until the context is done.
+func replaySubscription[T any](ctx context.Context, b *Backend, sub string, out chan T) error {
+	updates := b.updates[sub]
+	for {
+		b.mu.Lock()
+		i := b.sent[sub]
+		b.mu.Unlock()
+		if i >= len(updates) {
+			<-ctx.Done()
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(b.start.Add(updates[i].time())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		var update T
+		err := json.Unmarshal(updates[i].Data, &update)
+		if err == nil {
+			select {
+			case out <- update:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		b.mu.Lock()
+		b.sent[sub] = i + 1
+		b.mu.Unlock()
+	}
+}
+
+func (b *Backend) NodeName() string {
+	return b.cfg.Name
+}
+
+func (b *Backend) Ping() error {
+	return nil
+}
+
+func (b *Backend) SubscribeInvoice(ctx context.Context, channel chan *models.Invoice) error {
+	return replaySubscription(ctx, b, "invoices", channel)
+}
+
+func (b *Backend) SubscribeChannels(ctx context.Context, channel chan *models.ChannelUpdate) error {
+	return replaySubscription(ctx, b, "channels", channel)
+}
+
+func (b *Backend) SubscribePayments(ctx context.Context, channel chan *models.Payment) error {
+	return replaySubscription(ctx, b, "payments", channel)
+}
+
+func (b *Backend) SubscribeTransactions(ctx context.Context, channel chan *models.Transaction) error {
+	return replaySubscription(ctx, b, "transactions", channel)
+}
+
+func (b *Backend) SubscribeRoutingEvents(ctx context.Context, channel chan *models.RoutingEvent) error {
+	return replaySubscription(ctx, b, "routing", channel)
+}
+
+func (b *Backend) SubscribeGraphEvents(ctx context.Context, channel chan *models.ChannelEdgeUpdate) error {
+	return replaySubscription(ctx, b, "graph", channel)
+}
+
+func (b *Backend) SubscribeChannelBackups(ctx context.Context, _ chan *models.ChannelBackup) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (b *Backend) Info(context.Context) (*models.Info, error) {
+	info := &models.Info{}
+	err := b.answer("Info", "", info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetState returns the server as active if the state was not recorded,
+// the node answered the other calls.
+func (b *Backend) GetState(context.Context) (models.NodeState, error) {
+	var state models.NodeState
+	err := b.answer("GetState", "", &state)
+	if err == errNotRecorded {
+		return models.NodeStateServerActive, nil
+	}
+	if err != nil {
+		return models.NodeStateUnknown, err
+	}
+	return state, nil
+}
+
+func (b *Backend) GetNode(_ context.Context, pubkey string, includeChannels bool) (*models.Node, error) {
+	node := &models.Node{}
+	err := b.answer("GetNode", nodeKey(pubkey, includeChannels), node)
+	if err == errNotRecorded && includeChannels {
+		err = b.answer("GetNode", nodeKey(pubkey, false), node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (b *Backend) ListNodes(context.Context) ([]*models.Node, error) {
+	var nodes []*models.Node
+	err := b.list("ListNodes", &nodes)
+	return nodes, err
+}
+
+func (b *Backend) GetWalletBalance(context.Context) (*models.WalletBalance, error) {
+	balance := &models.WalletBalance{}
+	err := b.answer("GetWalletBalance", "", balance)
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func (b *Backend) GetChannelsBalance(context.Context) (*models.ChannelsBalance, error) {
+	balance := &models.ChannelsBalance{}
+	err := b.answer("GetChannelsBalance", "", balance)
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func (b *Backend) ListChannels(_ context.Context, opts ...options.Channel) ([]*models.Channel, error) {
+	var channels []*models.Channel
+	err := b.answer("ListChannels", channelsKey(opts), &channels)
+	if err == errNotRecorded {
+		return nil, nil
+	}
+	return channels, err
+}
+
+// GetChannelInfo sets the fields of the channel recorded for its id, it is
+// left as is if it was not recorded.
+func (b *Backend) GetChannelInfo(_ context.Context, channel *models.Channel) error {
+	err := b.answer("GetChannelInfo", strconv.FormatUint(channel.ID, 10), channel)
+	if err == errNotRecorded {
+		return nil
+	}
+	return err
+}
+
+func (b *Backend) GetInvoice(_ context.Context, hash string) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	err := b.answer("GetInvoice", hash, invoice)
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (b *Backend) ListInvoices(context.Context) ([]*models.Invoice, error) {
+	var invoices []*models.Invoice
+	err := b.list("ListInvoices", &invoices)
+	return invoices, err
+}
+
+func (b *Backend) DecodePayReq(_ context.Context, payreq string) (*models.PayReq, error) {
+	p := &models.PayReq{}
+	err := b.answer("DecodePayReq", payreq, p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (b *Backend) ListPayments(context.Context) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	err := b.list("ListPayments", &payments)
+	return payments, err
+}
+
+func (b *Backend) GetTransactions(context.Context) ([]*models.Transaction, error) {
+	var txs []*models.Transaction
+	err := b.list("GetTransactions", &txs)
+	return txs, err
+}
+
+func (b *Backend) GetForwardingHistory(_ context.Context, _ string, max uint32) ([]*models.ForwardingEvent, error) {
+	var forwards []*models.ForwardingEvent
+	err := b.answer("GetForwardingHistory", strconv.FormatUint(uint64(max), 10), &forwards)
+	if err == errNotRecorded {
+		return nil, nil
+	}
+	return forwards, err
+}
+
+func (b *Backend) ListPeers(context.Context) ([]*models.Peer, error) {
+	var peers []*models.Peer
+	err := b.list("ListPeers", &peers)
+	return peers, err
+}
+
+func (b *Backend) ClosedChannels(context.Context) ([]*models.ClosedChannel, error) {
+	var closed []*models.ClosedChannel
+	err := b.list("ClosedChannels", &closed)
+	return closed, err
+}
+
+func (b *Backend) PendingSweeps(context.Context) ([]*models.PendingSweep, error) {
+	var sweeps []*models.PendingSweep
+	err := b.list("PendingSweeps", &sweeps)
+	return sweeps, err
+}
+
+func (b *Backend) ListOffers(context.Context) ([]*models.Offer, error) {
+	var offers []*models.Offer
+	err := b.list("ListOffers", &offers)
+	return offers, err
+}
+
+func (b *Backend) ListUnspent(context.Context) ([]*models.UTXO, error) {
+	var utxos []*models.UTXO
+	err := b.list("ListUnspent", &utxos)
+	return utxos, err
+}
+
+func (b *Backend) Watchtower(context.Context) (*models.Watchtower, error) {
+	tower := &models.Watchtower{}
+	err := b.answer("Watchtower", "", tower)
+	if err != nil {
+		return nil, err
+	}
+	return tower, nil
+}
+
+func (b *Backend) MissionControl(context.Context) ([]*models.MissionPair, error) {
+	var pairs []*models.MissionPair
+	err := b.list("MissionControl", &pairs)
+	return pairs, err
+}
+
+func (b *Backend) PairProbability(_ context.Context, from, to string, amtMsat int64) (float64, error) {
+	var p float64
+	err := b.answer("PairProbability", pairKey(from, to, amtMsat), &p)
+	return p, err
+}
+
+func (b *Backend) InterceptHTLCs(context.Context, chan *models.InterceptedHTLC, chan *models.HTLCResolution) error {
+	return errNotSupported
+}
+
+func (b *Backend) CreateInvoice(context.Context, int64, string) (*models.Invoice, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) NewAddress(context.Context) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) CreateOffer(context.Context, int64, string) (*models.Offer, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) DisableOffer(context.Context, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) SignMessage(context.Context, string) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) VerifyMessage(context.Context, string, string) (*models.VerifiedMessage, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) SendPayment(context.Context, *models.PayReq) (*models.Payment, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) Rebalance(context.Context, *models.Channel, *models.Channel, int64, int64, chan *models.RebalanceAttempt) (*models.Payment, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) SendKeysend(context.Context, *models.Keysend, chan *models.Payment) (*models.Payment, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) ConnectPeer(context.Context, string, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) DisconnectPeer(context.Context, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) BumpFee(context.Context, string, uint64) error {
+	return errNotSupported
+}
+
+func (b *Backend) Consolidate(context.Context, []*models.UTXO, uint64) (*models.Consolidation, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) LabelTransaction(context.Context, string, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) SendOnChain(context.Context, string, int64, uint64, []string) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) OpenChannel(context.Context, string, int64, uint64, bool, []string) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) EstimateBatchOpen(context.Context, []*models.BatchChannel, uint64) (*models.FeeEstimate, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) EstimateFeeRate(context.Context, uint32) (uint64, error) {
+	return 0, errNotSupported
+}
+
+func (b *Backend) BatchOpenChannel(context.Context, []*models.BatchChannel, uint64) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) UpdateChannelPolicy(context.Context, *models.Channel, *models.RoutingPolicy) error {
+	return errNotSupported
+}
+
+func (b *Backend) CloseChannel(context.Context, *models.Channel, bool, uint64) (string, error) {
+	return "", errNotSupported
+}
+
+func (b *Backend) AddTower(context.Context, string, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) RemoveTower(context.Context, string) error {
+	return errNotSupported
+}
+
+func (b *Backend) ResetMissionControl(context.Context) error {
+	return errNotSupported
+}
+
+func (b *Backend) ImportMissionControl(context.Context, []*models.MissionPair, bool) error {
+	return errNotSupported
+}
+
+func (b *Backend) VerifyChannelBackup(context.Context, *models.ChannelBackup) error {
+	return errNotSupported
+}
+
+func (b *Backend) ExportChannelBackup(context.Context) (*models.ChannelBackup, error) {
+	return nil, errNotSupported
+}
+
+func (b *Backend) BakeMacaroon(context.Context, []models.Permission) ([]byte, error) {
+	return nil, errNotSupported
+}
@@ -0,0 +1,9 @@
+package models
+
+// ChannelBackup is a static channel backup (SCB) snapshot of all the
+// channels, as written to the channel.backup file by the node.
+type ChannelBackup struct {
+	ChanPoints []string
+	// Multi is the encrypted multi channel backup.
+	Multi []byte
+}
@@ -0,0 +1,24 @@
+package models
+
+// BatchChannel is a channel of a batch funded by a single transaction.
+type BatchChannel struct {
+	PubKey  string
+	Amount  int64
+	Private bool
+}
+
+// FeeEstimate is the on-chain fee in sat of a transaction at the fee
+// rate in sat/vbyte.
+type FeeEstimate struct {
+	Fee         int64
+	SatPerVbyte uint64
+}
+
+// BatchAmount returns the total local amount of the channels.
+func BatchAmount(channels []*BatchChannel) int64 {
+	var total int64
+	for _, ch := range channels {
+		total += ch.Amount
+	}
+	return total
+}
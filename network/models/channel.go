@@ -1,6 +1,8 @@
+// Package models defines the node types returned by the backends.
 package models
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -18,6 +20,34 @@ const (
 	ChannelClosed
 )
 
+// Close types of the closed and closing channels, zero if unknown.
+const (
+	CloseCooperative = iota + 1
+	CloseLocalForce
+	CloseRemoteForce
+	CloseBreach
+	CloseFundingCanceled
+	CloseAbandoned
+)
+
+func CloseTypeName(t int) string {
+	switch t {
+	case CloseCooperative:
+		return "cooperative"
+	case CloseLocalForce:
+		return "local force"
+	case CloseRemoteForce:
+		return "remote force"
+	case CloseBreach:
+		return "breach"
+	case CloseFundingCanceled:
+		return "funding canceled"
+	case CloseAbandoned:
+		return "abandoned"
+	}
+	return ""
+}
+
 type ChannelsBalance struct {
 	Balance            int64
 	PendingOpenBalance int64
@@ -54,6 +84,18 @@ type Channel struct {
 	LocalPolicy         *RoutingPolicy
 	RemotePolicy        *RoutingPolicy
 	BlocksTilMaturity   int32
+	// FundingExpiryBlocks is the number of blocks before the funding of
+	// an opening channel may be canceled by the peer.
+	FundingExpiryBlocks int32
+	CloseType           int
+	PingTime            time.Duration
+	Uptime              time.Duration
+	Lifetime            time.Duration
+	Closing             *Closing
+	Anchors             bool
+	// Tags and Note are the ones set in the ui, read from its store.
+	Tags []string
+	Note string
 }
 
 func (m Channel) MarshalLogObject(enc logging.ObjectEncoder) error {
@@ -75,6 +117,52 @@ func (m Channel) MarshalLogObject(enc logging.ObjectEncoder) error {
 	return nil
 }
 
+func (m Channel) StatusName() string {
+	switch m.Status {
+	case ChannelActive:
+		return "active"
+	case ChannelInactive:
+		return "inactive"
+	case ChannelOpening:
+		return "opening"
+	case ChannelClosing:
+		return "closing"
+	case ChannelForceClosing:
+		return "force closing"
+	case ChannelWaitingClose:
+		return "waiting close"
+	case ChannelClosed:
+		return "closed"
+	}
+	return ""
+}
+
+// FundingHeight returns the height of the block of the funding
+// transaction, encoded in the short channel id, zero without one.
+func (m Channel) FundingHeight() uint32 {
+	return uint32(m.ID >> 40)
+}
+
+// AgeAt returns the number of blocks since the funding of the channel at
+// the height, zero if the funding height is unknown or above the height,
+// like the one of an alias.
+func (m Channel) AgeAt(height uint32) uint32 {
+	funding := m.FundingHeight()
+	if funding == 0 || funding > height {
+		return 0
+	}
+	return height - funding
+}
+
+// ToScid formats a channel id as a short channel id, BxTxO.
+func ToScid(id uint64) string {
+	blocknum := id >> 40
+	txnum := (id >> 16) & 0x00FFFFFF
+	outnum := id & 0xFFFF
+
+	return fmt.Sprintf("%dx%dx%d", blocknum, txnum, outnum)
+}
+
 func (m Channel) ShortAlias() (alias string, forced bool) {
 	if m.Node != nil && m.Node.ForcedAlias != "" {
 		alias = m.Node.ForcedAlias
@@ -90,12 +178,53 @@ func (m Channel) ShortAlias() (alias string, forced bool) {
 	return
 }
 
+// ChannelUpdate is sent by the backends when a channel changes,
+// ChannelPoint and CloseType are set when it is closed.
 type ChannelUpdate struct {
+	ChannelPoint string
+	RemotePubKey string
+	CloseType    int
+	// Status is ChannelActive or ChannelInactive if the update is the
+	// one of the status of the channel.
+	Status int
+	// Channel is the channel opened if the update is the one of its
+	// open.
+	Channel *Channel
+}
+
+// IsForceClose returns true for the close types spending a commitment.
+func IsForceClose(t int) bool {
+	return t == CloseLocalForce || t == CloseRemoteForce || t == CloseBreach
 }
 
 type ChannelEdgeUpdate struct {
 	ChanPoints []string
+	// Policies are the policies announced, empty if the backend does not
+	// report them.
+	Policies []*EdgePolicy
 }
+
+// EdgePolicy is the policy of a channel announced by AdvertisingNode for
+// the HTLCs it forwards to ConnectingNode.
+type EdgePolicy struct {
+	ChanPoint       string
+	AdvertisingNode string
+	ConnectingNode  string
+	Policy          *RoutingPolicy
+}
+
+// Toward returns the policies of the update announced by the peers of the
+// node of the pubkey toward it.
+func (u *ChannelEdgeUpdate) Toward(pubkey string) []*EdgePolicy {
+	policies := []*EdgePolicy{}
+	for _, p := range u.Policies {
+		if p.ConnectingNode == pubkey && p.Policy != nil {
+			policies = append(policies, p)
+		}
+	}
+	return policies
+}
+
 type RoutingPolicy struct {
 	TimeLockDelta    uint32
 	MinHtlc          int64
@@ -103,4 +232,7 @@ type RoutingPolicy struct {
 	FeeBaseMsat      int64
 	FeeRateMilliMsat int64
 	Disabled         bool
+	// LastUpdate is the time of the announcement of the policy, zero if
+	// unknown.
+	LastUpdate time.Time
 }
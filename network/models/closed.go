@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Initiators of the opening and of the closing of a channel, zero if
+// unknown.
+const (
+	InitiatorLocal = iota + 1
+	InitiatorRemote
+	InitiatorBoth
+)
+
+func InitiatorName(i int) string {
+	switch i {
+	case InitiatorLocal:
+		return "local"
+	case InitiatorRemote:
+		return "remote"
+	case InitiatorBoth:
+		return "both"
+	}
+	return "unknown"
+}
+
+// ClosedChannel is the summary of a channel closed on chain.
+type ClosedChannel struct {
+	ID                uint64
+	ChannelPoint      string
+	RemotePubKey      string
+	Capacity          int64
+	SettledBalance    int64
+	TimeLockedBalance int64
+	CloseHeight       uint32
+	ClosingTxHash     string
+	CloseType         int
+	OpenInitiator     int
+	CloseInitiator    int
+	// CloseTime is the date of the closing transaction, zero if it is
+	// not a transaction of the wallet.
+	CloseTime time.Time
+	// ClosingFee is the fee of the closing transaction paid by the node,
+	// zero if the channel was opened by the peer.
+	ClosingFee int64
+	Node       *Node
+}
+
+// Alias returns the forced alias of the node, or its alias.
+func (c *ClosedChannel) Alias() string {
+	if c.Node == nil {
+		return ""
+	}
+	if c.Node.ForcedAlias != "" {
+		return c.Node.ForcedAlias
+	}
+	return c.Node.Alias
+}
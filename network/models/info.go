@@ -15,6 +15,11 @@ type Info struct {
 	Version             string
 	Chains              []string
 	Testnet             bool
+	// Network is the bitcoin network of the node: mainnet, testnet,
+	// signet or regtest.
+	Network string
+	// Implementation is the node software, lnd if empty.
+	Implementation string
 }
 
 func (i Info) MarshalLogObject(enc logging.ObjectEncoder) error {
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Actions resolving an intercepted HTLC, InterceptHold while it waits for
+// one.
+const (
+	InterceptHold = iota
+	InterceptResume
+	InterceptFail
+	InterceptSettle
+)
+
+// InterceptedHTLC is an HTLC forwarded through the node and held by the
+// interceptor until it is resumed, failed or settled.
+type InterceptedHTLC struct {
+	IncomingChannelId  uint64
+	IncomingHtlcId     uint64
+	OutgoingChannelId  uint64
+	PaymentHash        []byte
+	IncomingAmountMsat uint64
+	OutgoingAmountMsat uint64
+	IncomingExpiry     uint32
+	OutgoingExpiry     uint32
+	Intercepted        time.Time
+	// IncomingPeer and OutgoingPeer are the aliases of the peers of the
+	// channels, their pubkeys if they have none.
+	IncomingPeer string
+	OutgoingPeer string
+	// Action is the resolution of the HTLC and Reason the rule deciding
+	// it, Resolved is zero until it is resolved.
+	Action   int
+	Reason   string
+	Resolved time.Time
+}
+
+// Key returns the incoming circuit key of the HTLC, unique among the
+// HTLCs of the node.
+func (h *InterceptedHTLC) Key() string {
+	return CircuitKey(h.IncomingChannelId, h.IncomingHtlcId)
+}
+
+// CircuitKey returns the key of the HTLC of the id on the channel.
+func CircuitKey(chanID, htlcID uint64) string {
+	return fmt.Sprintf("%d:%d", chanID, htlcID)
+}
+
+// FeeMsat returns the fee of the forward, the incoming amount above the
+// outgoing one.
+func (h *InterceptedHTLC) FeeMsat() uint64 {
+	if h.IncomingAmountMsat < h.OutgoingAmountMsat {
+		return 0
+	}
+	return h.IncomingAmountMsat - h.OutgoingAmountMsat
+}
+
+// FeePPM returns the fee rate of the forward in parts per million of the
+// outgoing amount.
+func (h *InterceptedHTLC) FeePPM() uint64 {
+	if h.OutgoingAmountMsat == 0 {
+		return 0
+	}
+	return h.FeeMsat() * 1000000 / h.OutgoingAmountMsat
+}
+
+// ActionName returns the name of the action, e.g. "held" or "failed".
+func (h *InterceptedHTLC) ActionName() string {
+	switch h.Action {
+	case InterceptResume:
+		return "resumed"
+	case InterceptFail:
+		return "failed"
+	case InterceptSettle:
+		return "settled"
+	}
+	return "held"
+}
+
+// HTLCResolution resolves the intercepted HTLC of the incoming circuit
+// key, the preimage settles it.
+type HTLCResolution struct {
+	IncomingChannelId uint64
+	IncomingHtlcId    uint64
+	Action            int
+	Preimage          []byte
+}
@@ -2,10 +2,18 @@ package models
 
 import (
 	"encoding/hex"
+	"time"
 
 	"github.com/edouardparis/lntop/logging"
 )
 
+const (
+	InvoiceOpen = iota
+	InvoiceSettled
+	InvoiceCanceled
+	InvoiceAccepted
+)
+
 type Invoice struct {
 	// Index: index of this invoice.
 	// Each newly created invoice will increment
@@ -30,9 +38,12 @@ type Invoice struct {
 	// FallBackAddress: Fallback on-chain address.
 	FallBackAddress string
 	Settled         bool
-	CreationDate    int64
-	SettleDate      int64
-	Expiry          int64
+	// State: open, settled, canceled or accepted when the htlcs of a
+	// hold invoice are held.
+	State        int
+	CreationDate int64
+	SettleDate   int64
+	Expiry       int64
 	// CLTVExpiry: Delta to use for the time-lock of the CLTV extended to the final hop.
 	CLTVExpiry uint64
 	// Private: Whether this invoice should include routing hints for private channels.
@@ -43,6 +54,34 @@ func (m Invoice) GetRHash() string {
 	return hex.EncodeToString(m.RHash)
 }
 
+// ExpiryDate returns the unix time the invoice expires at, zero if its
+// creation date is unknown.
+func (m Invoice) ExpiryDate() int64 {
+	if m.CreationDate == 0 {
+		return 0
+	}
+	return m.CreationDate + m.Expiry
+}
+
+// StateName returns the name of the state, an open invoice past its
+// expiry is expired.
+func (m Invoice) StateName() string {
+	switch m.State {
+	case InvoiceOpen:
+		if d := m.ExpiryDate(); d > 0 && d < time.Now().Unix() {
+			return "expired"
+		}
+		return "open"
+	case InvoiceSettled:
+		return "settled"
+	case InvoiceCanceled:
+		return "canceled"
+	case InvoiceAccepted:
+		return "accepted"
+	}
+	return ""
+}
+
 func (m Invoice) MarshalLogObject(enc logging.ObjectEncoder) error {
 	enc.AddUint64("index", m.Index)
 	enc.AddBool("private", m.Private)
@@ -53,6 +92,7 @@ func (m Invoice) MarshalLogObject(enc logging.ObjectEncoder) error {
 	enc.AddString("r_pre_image", hex.EncodeToString(m.RPreImage))
 	enc.AddString("payment_request", m.PaymentRequest)
 	enc.AddBool("settled", m.Settled)
+	enc.AddString("state", m.StateName())
 	enc.AddInt64("expiry", m.Expiry)
 
 	return nil
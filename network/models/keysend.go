@@ -0,0 +1,13 @@
+package models
+
+// Keysend is a spontaneous payment to a node, without invoice.
+type Keysend struct {
+	Destination string
+	// Amount is in sat.
+	Amount int64
+	// Message is sent in a TLV record of the payment if not empty.
+	Message string
+	// AMP pays with an AMP payment split over several paths instead of a
+	// keysend.
+	AMP bool
+}
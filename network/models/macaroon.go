@@ -0,0 +1,20 @@
+package models
+
+// Permission is a permission of a macaroon, an action on an entity of the
+// node, e.g. read on offchain.
+type Permission struct {
+	Entity string
+	Action string
+}
+
+// MonitoringPermissions are the permissions lntop needs to display the
+// node, none of them is allowed to move funds or to change the node.
+var MonitoringPermissions = []Permission{
+	{Entity: "info", Action: "read"},
+	{Entity: "onchain", Action: "read"},
+	{Entity: "offchain", Action: "read"},
+	{Entity: "invoices", Action: "read"},
+	{Entity: "peers", Action: "read"},
+	// message:read verifies the signed messages.
+	{Entity: "message", Action: "read"},
+}
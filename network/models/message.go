@@ -0,0 +1,10 @@
+package models
+
+// VerifiedMessage is the verification of a signed message, the pubkey is
+// the one recovered from the signature.
+type VerifiedMessage struct {
+	// Valid is true if the signature is valid and its pubkey is a node
+	// of the graph.
+	Valid  bool
+	PubKey string
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// MissionPair is the history of the payment attempts between two nodes
+// kept by the mission control of the node, which estimates the success
+// probability of the routes.
+type MissionPair struct {
+	NodeFrom string
+	NodeTo   string
+	// FailTime is zero if no attempt failed, FailAmtMsat is the lowest
+	// amount failed at that time.
+	FailTime    time.Time
+	FailAmtMsat int64
+	// SuccessTime is zero if no attempt succeeded, SuccessAmtMsat is the
+	// highest amount forwarded at that time.
+	SuccessTime    time.Time
+	SuccessAmtMsat int64
+}
+
+// LastAttempt returns the time of the last failure or success.
+func (p *MissionPair) LastAttempt() time.Time {
+	if p.FailTime.After(p.SuccessTime) {
+		return p.FailTime
+	}
+	return p.SuccessTime
+}
+
+// Failed returns true if the last attempt of the pair failed.
+func (p *MissionPair) Failed() bool {
+	return !p.FailTime.IsZero() && p.FailTime.After(p.SuccessTime)
+}
@@ -11,9 +11,60 @@ type Node struct {
 	ForcedAlias   string
 	Addresses     []*NodeAddress
 	Channels      []*Channel
+	// Features are the feature bits announced by the node, in the order
+	// of the bits.
+	Features []*NodeFeature
+	// Cached is true if the node is only the alias recorded by lntop,
+	// until it is fetched.
+	Cached bool
 }
 
 type NodeAddress struct {
 	Network string
 	Addr    string
 }
+
+// NodeFeature is a feature bit announced by a node, Known is false if
+// lntop does not know the name of the bit.
+type NodeFeature struct {
+	Bit      uint32
+	Name     string
+	Required bool
+	Known    bool
+}
+
+// featureNames are the names of the feature bits of BOLT 9 by even bit,
+// as lnd names them.
+var featureNames = map[uint32]string{
+	0:    "data-loss-protect",
+	4:    "upfront-shutdown-script",
+	6:    "gossip-queries",
+	8:    "tlv-onion",
+	10:   "ext-gossip-queries",
+	12:   "static-remote-key",
+	14:   "payment-addr",
+	16:   "multi-path-payments",
+	18:   "wumbo-channels",
+	20:   "anchor-commitments",
+	22:   "anchors-zero-fee-htlc-tx",
+	24:   "route-blinding",
+	26:   "shutdown-any-segwit",
+	28:   "dual-funding",
+	38:   "onion-messages",
+	44:   "explicit-commitment-type",
+	46:   "scid-alias",
+	48:   "payment-metadata",
+	50:   "zero-conf",
+	54:   "keysend",
+	2022: "script-enforced-lease",
+}
+
+// NewNodeFeature returns the feature of the bit with its name, an even
+// bit is required and an odd one optional.
+func NewNodeFeature(bit uint32) *NodeFeature {
+	name, known := featureNames[bit&^1]
+	if !known {
+		name = "unknown"
+	}
+	return &NodeFeature{Bit: bit, Name: name, Required: bit%2 == 0, Known: known}
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	OfferActive = iota + 1
+	OfferDisabled
+	// OfferUsed is a single use offer already paid.
+	OfferUsed
+)
+
+// Offer is a BOLT12 offer of the node.
+type Offer struct {
+	ID          string
+	Bolt12      string
+	Description string
+	// Amount is in sat, zero if the payer chooses it.
+	Amount    int64
+	Status    int
+	SingleUse bool
+	Label     string
+	// Requests are the invoices created by the node for the invoice
+	// requests of the offer it received, the oldest first.
+	Requests []*OfferRequest
+}
+
+func (o Offer) StatusName() string {
+	switch o.Status {
+	case OfferActive:
+		return "active"
+	case OfferDisabled:
+		return "disabled"
+	case OfferUsed:
+		return "used"
+	}
+	return ""
+}
+
+// Paid returns the number of requests paid and the amount received in
+// sat, with the date of the last payment.
+func (o Offer) Paid() (int, int64, time.Time) {
+	n, amount, last := 0, int64(0), time.Time{}
+	for _, r := range o.Requests {
+		if !r.Paid {
+			continue
+		}
+		n++
+		amount += r.AmountPaid
+		if r.PaidAt.After(last) {
+			last = r.PaidAt
+		}
+	}
+	return n, amount, last
+}
+
+// OfferRequest is the invoice created for an invoice request of an offer.
+type OfferRequest struct {
+	PaymentHash string
+	// Amount and AmountPaid are in sat.
+	Amount     int64
+	AmountPaid int64
+	Paid       bool
+	PaidAt     time.Time
+	ExpiresAt  time.Time
+	// PayerNote is the note of the payer of the invoice request.
+	PayerNote string
+}
@@ -1,15 +1,61 @@
 package models
 
-import "github.com/edouardparis/lntop/logging"
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+)
+
+const (
+	PaymentInFlight = iota + 1
+	PaymentSucceeded
+	PaymentFailed
+)
 
 type Payment struct {
+	Hash   string
+	Status int
+	// Amount and Fee are in sat, the fee is the one of the route
+	// settled.
+	Amount       int64
+	Fee          int64
+	CreationDate time.Time
+	// PaymentError is the failure reason of a failed payment.
 	PaymentError    string
 	PaymentPreimage []byte
 	PayReq          *PayReq
 	Route           *Route
 }
 
+func (p Payment) StatusName() string {
+	switch p.Status {
+	case PaymentInFlight:
+		return "in flight"
+	case PaymentSucceeded:
+		return "succeeded"
+	case PaymentFailed:
+		return "failed"
+	}
+	return ""
+}
+
+// Destination returns the pubkey of the last hop of the route, or of the
+// payment request.
+func (p Payment) Destination() string {
+	if p.Route != nil && len(p.Route.Hops) > 0 {
+		return p.Route.Hops[len(p.Route.Hops)-1].PubKey
+	}
+	if p.PayReq != nil {
+		return p.PayReq.Destination
+	}
+	return ""
+}
+
 func (p Payment) MarshalLogObject(enc logging.ObjectEncoder) error {
+	enc.AddString("hash", p.Hash)
+	enc.AddString("status", p.StatusName())
+	enc.AddInt64("amount", p.Amount)
+	enc.AddInt64("fee", p.Fee)
 	enc.AddString("payment_error", p.PaymentError)
 
 	return nil
@@ -4,11 +4,37 @@ type PayReq struct {
 	Destination     string
 	PaymentHash     string
 	Amount          int64
+	AmountMsat      int64
 	Timestamp       int64
 	Expiry          int64
 	Description     string
 	DescriptionHash string
 	FallbackAddr    string
 	CltvExpiry      int64
+	PaymentAddr     []byte
+	RouteHints      []*RouteHint
+	Features        []*Feature
 	String          string
 }
+
+// RouteHint is a private route to the destination, the hops are in the
+// order of the payment.
+type RouteHint struct {
+	Hops []*HopHint
+}
+
+type HopHint struct {
+	NodeID                    string
+	ChanID                    uint64
+	FeeBaseMsat               uint32
+	FeeProportionalMillionths uint32
+	CltvExpiryDelta           uint32
+}
+
+// Feature is a feature bit set in the invoice.
+type Feature struct {
+	Bit      uint32
+	Name     string
+	Required bool
+	Known    bool
+}
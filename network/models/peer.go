@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+)
+
+type Peer struct {
+	PubKey    string
+	Address   string
+	Inbound   bool
+	BytesSent uint64
+	BytesRecv uint64
+	SatSent   int64
+	SatRecv   int64
+	// PingTime: The last measured round trip time to the peer.
+	PingTime time.Duration
+	// LastError is the most recent error of the connection, at
+	// LastErrorTime.
+	LastError     string
+	LastErrorTime time.Time
+	// FlapCount is the number of times the peer went offline and came
+	// back, the last one at LastFlap.
+	FlapCount int32
+	LastFlap  time.Time
+	Node      *Node
+}
+
+func (p Peer) MarshalLogObject(enc logging.ObjectEncoder) error {
+	enc.AddString("pubkey", p.PubKey)
+	enc.AddString("address", p.Address)
+	enc.AddBool("inbound", p.Inbound)
+	enc.AddDuration("ping_time", p.PingTime)
+	enc.AddInt32("flap_count", p.FlapCount)
+
+	return nil
+}
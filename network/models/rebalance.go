@@ -0,0 +1,11 @@
+package models
+
+// RebalanceAttempt is an attempt of a rebalance over a route, its status
+// is the one of a payment.
+type RebalanceAttempt struct {
+	Number int
+	Status int
+	Route  *Route
+	// Error is the failure reason of a failed attempt.
+	Error string
+}
@@ -36,7 +36,9 @@ type Hop struct {
 	// The first 3 bytes are the block height,
 	// the next 3 the index within the block,
 	// and the last 2 bytes are the output index for the channel.
-	ChanID       uint64
+	ChanID uint64
+	// PubKey: The public key of the node at the end of the hop.
+	PubKey       string
 	ChanCapacity int64
 	Amount       int64
 	Fee          int64
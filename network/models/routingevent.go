@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -52,3 +53,40 @@ func (u *RoutingEvent) IsEmpty() bool {
 		u.FeeMsat == 0 &&
 		u.AmountMsat == 0
 }
+
+// Resolved returns true if the HTLC is settled or failed.
+func (u *RoutingEvent) Resolved() bool {
+	return u.Status == RoutingStatusSettled || u.Status == RoutingStatusFailed ||
+		u.Status == RoutingStatusLinkFailed
+}
+
+// FailureReason returns the wire failure and the detail of lnd of a link
+// failure, e.g. "TEMPORARY_CHANNEL_FAILURE INSUFFICIENT_BALANCE", without
+// the message of the failure. An HTLC failed by the next nodes has the
+// reason "DOWNSTREAM", empty if the event is not a failure.
+func (u *RoutingEvent) FailureReason() string {
+	switch u.Status {
+	case RoutingStatusFailed:
+		return "DOWNSTREAM"
+	case RoutingStatusLinkFailed:
+		fields := strings.Fields(u.FailureDetail)
+		if len(fields) > 2 {
+			fields = fields[:2]
+		}
+		if len(fields) == 2 && (fields[1] == "UNKNOWN" || fields[1] == "NO_DETAIL") {
+			fields = fields[:1]
+		}
+		if len(fields) == 0 {
+			return "LINK_FAILURE"
+		}
+		return strings.Join(fields, " ")
+	}
+	return ""
+}
+
+// InsufficientBalance returns true if the HTLC failed on the outgoing
+// link for lack of local balance.
+func (u *RoutingEvent) InsufficientBalance() bool {
+	return u.Status == RoutingStatusLinkFailed &&
+		strings.Contains(u.FailureDetail, "INSUFFICIENT_BALANCE")
+}
@@ -0,0 +1,74 @@
+package models
+
+// NodeState is the state of the node and of its wallet.
+type NodeState int
+
+const (
+	NodeStateUnknown NodeState = iota
+	// NodeStateUnavailable is the state of a node not answering.
+	NodeStateUnavailable
+	NodeStateWaitingToStart
+	NodeStateNonExisting
+	NodeStateLocked
+	NodeStateUnlocked
+	NodeStateRPCActive
+	NodeStateServerActive
+)
+
+// Ready returns true if the node answers the calls of lntop.
+func (s NodeState) Ready() bool {
+	return s == NodeStateRPCActive || s == NodeStateServerActive
+}
+
+func (s NodeState) String() string {
+	switch s {
+	case NodeStateUnavailable:
+		return "unavailable"
+	case NodeStateWaitingToStart:
+		return "waiting to start"
+	case NodeStateNonExisting:
+		return "no wallet"
+	case NodeStateLocked:
+		return "wallet locked"
+	case NodeStateUnlocked:
+		return "wallet unlocked, starting"
+	case NodeStateRPCActive:
+		return "rpc active"
+	case NodeStateServerActive:
+		return "active"
+	}
+	return "unknown"
+}
+
+// ConnectionState is the state of the connection of lntop to the node.
+type ConnectionState int
+
+const (
+	// ConnectionConnected is the state of a node answering, ready or not.
+	ConnectionConnected ConnectionState = iota
+	// ConnectionReconnecting is the state of a node not answering anymore,
+	// retried with a backoff.
+	ConnectionReconnecting
+	// ConnectionDown is the state of a node still not answering after
+	// the first retries, retried at the longest backoff.
+	ConnectionDown
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionConnected:
+		return "connected"
+	case ConnectionReconnecting:
+		return "reconnecting"
+	case ConnectionDown:
+		return "down"
+	}
+	return "unknown"
+}
+
+// Connection is the state of the connection with the number of attempts
+// to reach the node since it stopped answering.
+type Connection struct {
+	State    ConnectionState
+	Attempts int
+}
@@ -0,0 +1,65 @@
+package models
+
+import "strings"
+
+// States of the anchor output of a force closed channel, zero if the
+// channel has no anchor.
+const (
+	AnchorLimbo = iota + 1
+	AnchorRecovered
+	AnchorLost
+)
+
+func AnchorStateName(s int) string {
+	switch s {
+	case AnchorLimbo:
+		return "limbo"
+	case AnchorRecovered:
+		return "recovered"
+	case AnchorLost:
+		return "lost"
+	}
+	return ""
+}
+
+// Closing is the on-chain state of a channel waiting for its closing
+// transaction to confirm or for its outputs to mature.
+type Closing struct {
+	ClosingTxID       string
+	LimboBalance      int64
+	RecoveredBalance  int64
+	MaturityHeight    uint32
+	BlocksTilMaturity int32
+	Anchor            int
+	PendingHTLCs      []*PendingHTLC
+}
+
+// PendingHTLC is an HTLC output of a force closed channel not yet swept.
+type PendingHTLC struct {
+	Incoming          bool
+	Amount            int64
+	Outpoint          string
+	MaturityHeight    uint32
+	BlocksTilMaturity int32
+	Stage             uint32
+}
+
+// PendingSweep is an output the wallet is trying to sweep.
+type PendingSweep struct {
+	Outpoint             string
+	WitnessType          string
+	Amount               int64
+	SatPerVbyte          uint64
+	RequestedSatPerVbyte uint64
+	BroadcastAttempts    uint32
+	NextBroadcastHeight  uint32
+	DeadlineHeight       uint32
+	Budget               uint64
+	Immediate            bool
+}
+
+// TxID returns the hash of the transaction of the swept output.
+func (s *PendingSweep) TxID() string {
+	txid, _, _ := strings.Cut(s.Outpoint, ":")
+	return txid
+}
@@ -0,0 +1,75 @@
+package models
+
+// Watchtower is the state of the watchtower client of the node: the
+// towers backing up the channel states, the counts of the backups and
+// the policies of the new sessions.
+type Watchtower struct {
+	Towers   []*Tower
+	Stats    TowerStats
+	Policies []*TowerPolicy
+}
+
+// Tower is a watchtower registered with the client.
+type Tower struct {
+	PubKey    string
+	Addresses []string
+	// Active is true if the tower is a candidate for new sessions.
+	Active   bool
+	Sessions []*TowerSession
+}
+
+// Backups returns the number of channel states backed up to the tower.
+func (t *Tower) Backups() uint32 {
+	var n uint32
+	for _, s := range t.Sessions {
+		n += s.NumBackups
+	}
+	return n
+}
+
+// PendingBackups returns the number of channel states queued for the
+// tower.
+func (t *Tower) PendingBackups() uint32 {
+	var n uint32
+	for _, s := range t.Sessions {
+		n += s.NumPendingBackups
+	}
+	return n
+}
+
+// MaxBackups returns the number of channel states the sessions with the
+// tower can back up.
+func (t *Tower) MaxBackups() uint32 {
+	var n uint32
+	for _, s := range t.Sessions {
+		n += s.MaxBackups
+	}
+	return n
+}
+
+// TowerSession is a session negotiated with a tower.
+type TowerSession struct {
+	// Policy is the channel type of the session, e.g. "anchor".
+	Policy            string
+	NumBackups        uint32
+	NumPendingBackups uint32
+	MaxBackups        uint32
+	SweepSatPerVbyte  uint32
+}
+
+// TowerStats are the counts of the backups of the client since the node
+// started.
+type TowerStats struct {
+	NumBackups           uint32
+	NumPendingBackups    uint32
+	NumFailedBackups     uint32
+	NumSessionsAcquired  uint32
+	NumSessionsExhausted uint32
+}
+
+// TowerPolicy is the policy of the new sessions of a channel type.
+type TowerPolicy struct {
+	Type             string
+	MaxUpdates       uint32
+	SweepSatPerVbyte uint32
+}
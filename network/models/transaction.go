@@ -19,4 +19,7 @@ type Transaction struct {
 	TotalFees int64
 	// / Addresses that received funds for this transaction
 	DestAddresses []string
+	// / Label of the transaction, e.g. the one of lnd for the fundings,
+	// / closings and sweeps of the channels
+	Label string
 }
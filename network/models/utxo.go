@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Address types of the outputs of the wallet.
+const (
+	AddressP2WKH  = "p2wkh"
+	AddressNP2WKH = "np2wkh"
+	AddressP2TR   = "p2tr"
+)
+
+// Weights of a consolidation transaction spending the outputs to a
+// p2wkh output of the wallet, rounded up so that the estimated fee is
+// never below the fee rate.
+const (
+	txOverheadWeight   = 44
+	p2wkhInputWeight   = 272
+	np2wkhInputWeight  = 364
+	p2trInputWeight    = 232
+	p2wkhOutputWeight  = 124
+	p2wkhDustThreshold = 294
+)
+
+// UTXO is an unspent output of the wallet.
+type UTXO struct {
+	Outpoint      string
+	Address       string
+	AddressType   string
+	Amount        int64
+	Confirmations int64
+	Label         string
+	// LeaseID is the id of the lease locking the output until
+	// LeaseExpiration, e.g. for a channel funding in progress. The
+	// expiration is zero if the output is not leased.
+	LeaseID         string
+	LeaseExpiration time.Time
+}
+
+// Leased returns true if the output is locked and cannot be spent until
+// its lease expires.
+func (u *UTXO) Leased() bool {
+	return !u.LeaseExpiration.IsZero()
+}
+
+// TxID returns the hash of the transaction of the output.
+func (u *UTXO) TxID() string {
+	txid, _, _ := strings.Cut(u.Outpoint, ":")
+	return txid
+}
+
+// Consolidation is a transaction spending outputs of the wallet to a
+// single new output of the wallet.
+type Consolidation struct {
+	TxID    string
+	Address string
+	Inputs  int
+	Amount  int64
+	Fee     int64
+}
+
+// ConsolidationVSize returns the estimated virtual size of the
+// transaction consolidating the outputs.
+func ConsolidationVSize(utxos []*UTXO) int64 {
+	weight := int64(txOverheadWeight + p2wkhOutputWeight)
+	for _, u := range utxos {
+		switch u.AddressType {
+		case AddressNP2WKH:
+			weight += np2wkhInputWeight
+		case AddressP2TR:
+			weight += p2trInputWeight
+		default:
+			weight += p2wkhInputWeight
+		}
+	}
+	return (weight + 3) / 4
+}
+
+// ConsolidationFee returns the fee of the transaction consolidating the
+// outputs at the fee rate in sat/vbyte, and the amount of its output.
+func ConsolidationFee(utxos []*UTXO, satPerVbyte uint64) (fee int64, amount int64, err error) {
+	if len(utxos) == 0 {
+		return 0, 0, errors.New("no output selected")
+	}
+	var total int64
+	for _, u := range utxos {
+		total += u.Amount
+	}
+	fee = ConsolidationVSize(utxos) * int64(satPerVbyte)
+	amount = total - fee
+	if amount < p2wkhDustThreshold {
+		return fee, amount, errors.Errorf("fee of %d sat leaves a dust output", fee)
+	}
+	return fee, amount, nil
+}
@@ -6,12 +6,37 @@ type WalletBalance struct {
 	TotalBalance       int64
 	ConfirmedBalance   int64
 	UnconfirmedBalance int64
+	// AnchorReserve is the confirmed balance the wallet must keep to
+	// bump the fee of the force closes of the anchor channels.
+	AnchorReserve int64
 }
 
 func (m WalletBalance) MarshalLogObject(enc logging.ObjectEncoder) error {
 	enc.AddInt64("total_balance", m.TotalBalance)
 	enc.AddInt64("confirmed_balance", m.ConfirmedBalance)
 	enc.AddInt64("unconfirmed_balance", m.UnconfirmedBalance)
+	enc.AddInt64("anchor_reserve", m.AnchorReserve)
 
 	return nil
 }
+
+// lnd reserves 10,000 sat per anchor channel, up to 100,000 sat.
+const (
+	anchorReservePerChannel = 10000
+	maxAnchorReserve        = 100000
+)
+
+// RequiredAnchorReserve returns the reserve of the anchor channels not
+// yet closed, for backends not reporting it.
+func RequiredAnchorReserve(channels []*Channel) int64 {
+	var reserve int64
+	for _, c := range channels {
+		if c.Anchors && c.Status != ChannelClosed {
+			reserve += anchorReservePerChannel
+		}
+	}
+	if reserve > maxAnchorReserve {
+		return maxAnchorReserve
+	}
+	return reserve
+}
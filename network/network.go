@@ -1,25 +1,54 @@
+// Package network wraps the backend of the configured node type.
 package network
 
 import (
 	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/logging"
 	"github.com/edouardparis/lntop/network/backend"
+	"github.com/edouardparis/lntop/network/backend/account"
+	"github.com/edouardparis/lntop/network/backend/cln"
+	"github.com/edouardparis/lntop/network/backend/demo"
 	"github.com/edouardparis/lntop/network/backend/lnd"
 	"github.com/edouardparis/lntop/network/backend/mock"
+	"github.com/edouardparis/lntop/network/backend/replay"
 )
 
+// Network is the backend of the node, the calls of backend.Backend
+// return the types of the network/models package.
 type Network struct {
 	backend.Backend
 }
 
+// New connects to the node of the config, type "cln" selects the Core
+// Lightning backend, "lndhub" and "lnbits" a custodial account, "mock" the
+// in-memory backend, "demo" the generated node of the demo mode and
+// "replay" the recording of the address.
 func New(c *config.Network, logger logging.Logger) (*Network, error) {
 	var (
 		err error
 		b   backend.Backend
 	)
-	if c.Type == "mock" {
+	switch c.Type {
+	case "mock":
 		b = mock.New(c)
-	} else {
+	case "demo":
+		b = demo.New(c)
+	case "replay":
+		b, err = replay.New(c)
+		if err != nil {
+			return nil, err
+		}
+	case "cln":
+		b, err = cln.New(c, logger.With(logging.String("network", "cln")))
+		if err != nil {
+			return nil, err
+		}
+	case "lndhub", "lnbits":
+		b, err = account.New(c, logger.With(logging.String("network", c.Type)))
+		if err != nil {
+			return nil, err
+		}
+	default:
 		b, err = lnd.New(c, logger.With(logging.String("network", "lnd")))
 		if err != nil {
 			return nil, err
@@ -33,3 +62,9 @@ func New(c *config.Network, logger logging.Logger) (*Network, error) {
 
 	return &Network{b}, nil
 }
+
+// NewWithBackend wraps an already created backend, e.g. a mock backend
+// seeded by a test.
+func NewWithBackend(b backend.Backend) *Network {
+	return &Network{b}
+}
@@ -0,0 +1,153 @@
+// Package peerdata reads the metadata of the peers published by other
+// services, e.g. the terminal web score, the Amboss tags and the 1ML rank
+// of the nodes, from the source of the config: the url of a JSON document
+// or a local file, keyed by pubkey:
+//
+//	{"<pubkey>": {"terminal_score": 97.5, "amboss_tags": ["Routing"], "oneml_rank": 42}}
+//
+// The document of a url is cached in a file, read instead of the url
+// while it is younger than the interval and if the url fails.
+package peerdata
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	defaultInterval = time.Hour
+	maxResponse     = 16 << 20
+)
+
+// Metadata is the metadata of a node, the zero values are unknown.
+type Metadata struct {
+	Score float64  `json:"terminal_score"`
+	Tags  []string `json:"amboss_tags"`
+	Rank  int      `json:"oneml_rank"`
+}
+
+// Source is the source of the metadata of the config.
+type Source struct {
+	location string
+	http     *http.Client
+	interval time.Duration
+	cache    string
+}
+
+// New returns the source of the config, the requests go through the proxy
+// of the http config.
+func New(cfg config.PeerData, httpCfg config.HTTP) (*Source, error) {
+	if cfg.Source == "" {
+		return nil, errors.New("the source of the peer data is missing")
+	}
+	s := &Source{location: cfg.Source, interval: defaultInterval, cache: cfg.Cache}
+	if cfg.Interval > 0 {
+		s.interval = time.Duration(cfg.Interval) * time.Second
+	}
+	if !s.remote() {
+		return s, nil
+	}
+
+	client, err := httpCfg.Client()
+	if err != nil {
+		return nil, err
+	}
+	s.http = client
+	return s, nil
+}
+
+// Interval is the duration between two fetches of the metadata.
+func (s *Source) Interval() time.Duration {
+	return s.interval
+}
+
+func (s *Source) remote() bool {
+	return strings.HasPrefix(s.location, "http://") || strings.HasPrefix(s.location, "https://")
+}
+
+// Fetch returns the metadata by pubkey. The one of a url is read from the
+// cache if it is fresh, and written to it once fetched: it is returned
+// with the error if the cache cannot be written.
+func (s *Source) Fetch(ctx context.Context) (map[string]Metadata, error) {
+	if !s.remote() {
+		return readFile(s.location)
+	}
+
+	if s.cache != "" {
+		info, err := os.Stat(s.cache)
+		if err == nil && time.Since(info.ModTime()) < s.interval {
+			data, err := readFile(s.cache)
+			if err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	body, err := s.get(ctx)
+	if err == nil {
+		var data map[string]Metadata
+		err = errors.WithStack(json.Unmarshal(body, &data))
+		if err == nil {
+			if s.cache != "" {
+				err := os.WriteFile(s.cache, body, 0600)
+				if err != nil {
+					return data, errors.Wrap(err, "cannot write the cache of the peer data")
+				}
+			}
+			return data, nil
+		}
+	}
+	if s.cache == "" {
+		return nil, err
+	}
+	// the last document fetched, however old.
+	data, cerr := readFile(s.cache)
+	if cerr != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *Source) get(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, errors.Errorf("%s: %s", req.URL.Hostname(), resp.Status)
+	}
+	return body, nil
+}
+
+func readFile(path string) (map[string]Metadata, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var data map[string]Metadata
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid peer data %s", path)
+	}
+	return data, nil
+}
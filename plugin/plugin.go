@@ -0,0 +1,204 @@
+// Package plugin runs the external programs declared in the [[plugins]]
+// sections of the config. A plugin reads requests and writes responses
+// on its stdin and stdout, one JSON object per line:
+//
+//	-> {"id":1,"method":"render","params":{"node":{...},"channels":[...]}}
+//	<- {"id":1,"result":{"title":"...","columns":["A","B"],"rows":[["1","2"]],"channels":{"<chan_point>":"value"}}}
+//
+// rows are displayed in the plugin view of the menu and the channels
+// values in the PLUGIN:<name> column of the channels view. A response
+// with an "error" string is logged and keeps the previous result.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+)
+
+const (
+	MethodRender = "render"
+
+	defaultInterval = 30 * time.Second
+	defaultTimeout  = 10 * time.Second
+)
+
+type Request struct {
+	ID     uint64      `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+type Response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Result is the result of the render method.
+type Result struct {
+	Title    string            `json:"title"`
+	Columns  []string          `json:"columns"`
+	Rows     [][]string        `json:"rows"`
+	Channels map[string]string `json:"channels"`
+}
+
+type Plugin struct {
+	cfg    config.Plugin
+	logger logging.Logger
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	id     uint64
+}
+
+func (p *Plugin) Name() string {
+	return p.cfg.Name
+}
+
+// Interval is the duration between two renders.
+func (p *Plugin) Interval() time.Duration {
+	if p.cfg.Interval <= 0 {
+		return defaultInterval
+	}
+	return time.Duration(p.cfg.Interval) * time.Second
+}
+
+func (p *Plugin) start() error {
+	if len(p.cfg.Command) == 0 {
+		return errors.Errorf("plugin %s: empty command", p.cfg.Name)
+	}
+
+	cmd := exec.Command(p.cfg.Command[0], p.cfg.Command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	p.cmd, p.stdin, p.stdout = cmd, stdin, scanner
+	p.logger.Debug("plugin started", logging.Int("pid", cmd.Process.Pid))
+	return nil
+}
+
+// Call sends the request to the plugin, starting it if needed, and
+// decodes the result. The plugin is killed if it does not answer in time.
+func (p *Plugin) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		err := p.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	p.id++
+	req, err := json.Marshal(Request{ID: p.id, Method: method, Params: params})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = p.stdin.Write(append(req, '\n'))
+	if err != nil {
+		p.stop()
+		return errors.WithStack(err)
+	}
+
+	timeout := defaultTimeout
+	if p.cfg.Timeout > 0 {
+		timeout = time.Duration(p.cfg.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lines := make(chan []byte, 1)
+	go func(s *bufio.Scanner) {
+		if s.Scan() {
+			lines <- append([]byte(nil), s.Bytes()...)
+		}
+		close(lines)
+	}(p.stdout)
+
+	var line []byte
+	select {
+	case <-ctx.Done():
+		p.stop()
+		return errors.Errorf("plugin %s: no response", p.cfg.Name)
+	case l, ok := <-lines:
+		if !ok {
+			p.stop()
+			return errors.Errorf("plugin %s exited", p.cfg.Name)
+		}
+		line = l
+	}
+
+	resp := Response{}
+	err = json.Unmarshal(line, &resp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.Error != "" {
+		return errors.Errorf("plugin %s: %s", p.cfg.Name, resp.Error)
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return errors.WithStack(json.Unmarshal(resp.Result, result))
+}
+
+// Render calls the render method.
+func (p *Plugin) Render(ctx context.Context, params interface{}) (*Result, error) {
+	result := &Result{}
+	err := p.Call(ctx, MethodRender, params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *Plugin) stop() {
+	if p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+	p.cmd = nil
+}
+
+// Close stops the plugin process.
+func (p *Plugin) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+func New(cfg config.Plugin, logger logging.Logger) *Plugin {
+	return &Plugin{
+		cfg:    cfg,
+		logger: logger.With(logging.String("plugin", cfg.Name)),
+	}
+}
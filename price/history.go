@@ -0,0 +1,126 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// History is implemented by the providers returning the price of a
+// bitcoin at a past day, for the fiat values of the accounting export.
+type History interface {
+	Provider
+	// RateAt returns the rate of the UTC day of the time.
+	RateAt(ctx context.Context, t time.Time) (float64, error)
+}
+
+func (s *static) RateAt(context.Context, time.Time) (float64, error) { return s.rate, nil }
+
+// ErrNoRate is the error of a day without price in the history of the
+// provider.
+var ErrNoRate = errors.New("no price of the day")
+
+// RateAt returns the rate of the day from the cache, the days fetched
+// are kept for the next entries of the export. A day without price is
+// kept with a zero rate, to not fetch it again.
+func (f *feed) RateAt(ctx context.Context, t time.Time) (float64, error) {
+	day := t.UTC().Truncate(24 * time.Hour)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rate, ok := f.days[day.Unix()]
+	if !ok {
+		rates, err := f.history(ctx, day)
+		if err != nil {
+			return 0, err
+		}
+		for d, rate := range rates {
+			f.days[d] = rate
+		}
+		rate = f.days[day.Unix()]
+		f.days[day.Unix()] = rate
+	}
+	if rate == 0 {
+		return 0, errors.Wrapf(ErrNoRate, "%s on %s", f.currency, day.Format("2006-01-02"))
+	}
+	return rate, nil
+}
+
+// krakenHistory reads the daily candles of the XBT pair from the day, the
+// rate of a day is its volume weighted average price. Kraken returns at
+// most the 720 last candles, the days before the first one have no price.
+func (f *feed) krakenHistory(ctx context.Context, day time.Time) (map[int64]float64, error) {
+	u := "https://api.kraken.com/0/public/OHLC?interval=1440&pair=XBT" + url.QueryEscape(f.currency) +
+		"&since=" + strconv.FormatInt(day.Unix()-1, 10)
+	var resp struct {
+		Error []string `json:"error"`
+		// Result has the candles of the pair and the last key, a number.
+		Result map[string]json.RawMessage `json:"result"`
+	}
+	err := f.get(ctx, u, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Error) > 0 {
+		return nil, errors.Errorf("kraken: %s", strings.Join(resp.Error, ", "))
+	}
+
+	rates := map[int64]float64{}
+	first := int64(math.MaxInt64)
+	for key, raw := range resp.Result {
+		if key == "last" {
+			continue
+		}
+		// a candle is the time, open, high, low, close, vwap, volume
+		// and count.
+		var candles [][]interface{}
+		err := json.Unmarshal(raw, &candles)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, c := range candles {
+			if len(c) < 6 {
+				continue
+			}
+			t, ok := c[0].(float64)
+			vwap, _ := c[5].(string)
+			if !ok {
+				continue
+			}
+			rate, err := strconv.ParseFloat(vwap, 64)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			rates[int64(t)] = rate
+			if int64(t) < first {
+				first = int64(t)
+			}
+		}
+	}
+	for d := day.Unix(); d < first && len(rates) > 0; d += 24 * 60 * 60 {
+		rates[d] = 0
+	}
+	return rates, nil
+}
+
+// coingeckoHistory reads the price of bitcoin in the currency at the
+// start of the day.
+func (f *feed) coingeckoHistory(ctx context.Context, day time.Time) (map[int64]float64, error) {
+	currency := strings.ToLower(f.currency)
+	u := "https://api.coingecko.com/api/v3/coins/bitcoin/history?localization=false&date=" + day.Format("02-01-2006")
+	var resp struct {
+		MarketData struct {
+			CurrentPrice map[string]float64 `json:"current_price"`
+		} `json:"market_data"`
+	}
+	err := f.get(ctx, u, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return map[int64]float64{day.Unix(): resp.MarketData.CurrentPrice[currency]}, nil
+}
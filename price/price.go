@@ -0,0 +1,166 @@
+// Package price fetches the price of a bitcoin in a fiat currency from
+// the provider of the config, for the fiat columns of the ui.
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+const (
+	Kraken    = "kraken"
+	CoinGecko = "coingecko"
+	Static    = "static"
+
+	defaultCurrency = "USD"
+	defaultInterval = 5 * time.Minute
+	maxResponse     = 1 << 20
+)
+
+// Provider returns the price of a bitcoin in its currency.
+type Provider interface {
+	Rate(ctx context.Context) (float64, error)
+	Currency() string
+	// Interval is the duration between two fetches of the rate.
+	Interval() time.Duration
+}
+
+// New returns the provider of the config, the requests go through the
+// proxy of the http config.
+func New(cfg config.Price, httpCfg config.HTTP) (Provider, error) {
+	currency := strings.ToUpper(cfg.Currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	interval := defaultInterval
+	if cfg.Interval > 0 {
+		interval = time.Duration(cfg.Interval) * time.Second
+	}
+
+	switch cfg.Provider {
+	case Static:
+		if cfg.Rate <= 0 {
+			return nil, errors.New("the static price provider requires a rate")
+		}
+		return &static{rate: cfg.Rate, currency: currency}, nil
+	case Kraken, CoinGecko:
+		client, err := httpCfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		f := &feed{http: client, currency: currency, interval: interval, days: map[int64]float64{}}
+		if cfg.Provider == Kraken {
+			f.rate = f.kraken
+			f.history = f.krakenHistory
+		} else {
+			f.rate = f.coingecko
+			f.history = f.coingeckoHistory
+		}
+		return f, nil
+	}
+	return nil, errors.Errorf("unknown price provider %q", cfg.Provider)
+}
+
+type static struct {
+	rate     float64
+	currency string
+}
+
+func (s *static) Rate(context.Context) (float64, error) { return s.rate, nil }
+func (s *static) Currency() string                      { return s.currency }
+
+// Interval is the default one, the rate does not change.
+func (s *static) Interval() time.Duration { return defaultInterval }
+
+type feed struct {
+	http     *http.Client
+	currency string
+	interval time.Duration
+	rate     func(ctx context.Context) (float64, error)
+	// history returns the daily rates fetched for the day, keyed by the
+	// unix time of their day.
+	history func(ctx context.Context, day time.Time) (map[int64]float64, error)
+
+	mu   sync.Mutex
+	days map[int64]float64
+}
+
+func (f *feed) Rate(ctx context.Context) (float64, error) { return f.rate(ctx) }
+func (f *feed) Currency() string                          { return f.currency }
+func (f *feed) Interval() time.Duration                   { return f.interval }
+
+// kraken reads the last trade price of the XBT pair of the currency.
+func (f *feed) kraken(ctx context.Context) (float64, error) {
+	u := "https://api.kraken.com/0/public/Ticker?pair=XBT" + url.QueryEscape(f.currency)
+	var resp struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			// C is the price and the volume of the last trade.
+			C []string `json:"c"`
+		} `json:"result"`
+	}
+	err := f.get(ctx, u, &resp)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Error) > 0 {
+		return 0, errors.Errorf("kraken: %s", strings.Join(resp.Error, ", "))
+	}
+	for _, pair := range resp.Result {
+		if len(pair.C) == 0 {
+			break
+		}
+		rate, err := strconv.ParseFloat(pair.C[0], 64)
+		return rate, errors.WithStack(err)
+	}
+	return 0, errors.Errorf("kraken: no price for XBT%s", f.currency)
+}
+
+// coingecko reads the simple price of bitcoin in the currency.
+func (f *feed) coingecko(ctx context.Context) (float64, error) {
+	currency := strings.ToLower(f.currency)
+	u := "https://api.coingecko.com/api/v3/simple/price?ids=bitcoin&vs_currencies=" + url.QueryEscape(currency)
+	var resp map[string]map[string]float64
+	err := f.get(ctx, u, &resp)
+	if err != nil {
+		return 0, err
+	}
+	rate, ok := resp["bitcoin"][currency]
+	if !ok {
+		return 0, errors.Errorf("coingecko: no price in %s", f.currency)
+	}
+	return rate, nil
+}
+
+func (f *feed) get(ctx context.Context, u string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("%s: %s", req.URL.Hostname(), resp.Status)
+	}
+	return errors.WithStack(json.Unmarshal(body, out))
+}
@@ -0,0 +1,122 @@
+package pubsub
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+)
+
+// configDelay is the time without change of the config file before it
+// is loaded again, editors write a file in several steps.
+const configDelay = 200 * time.Millisecond
+
+// WithConfig watches the config file of the path: it is loaded again
+// when it changes or at ReloadConfig, the ticker takes its refresh
+// intervals and a ConfigReloaded event carries it to the subscribers.
+func (p *PubSub) WithConfig(path string) *PubSub {
+	p.config = path
+	return p
+}
+
+// WithConfigReload calls reload with the config each time it is loaded
+// again, after the reloads added before.
+func (p *PubSub) WithConfigReload(reload func(*config.Config)) *PubSub {
+	p.reloads = append(p.reloads, reload)
+	return p
+}
+
+// ReloadConfig loads the config file again, e.g. at SIGHUP.
+func (p *PubSub) ReloadConfig() {
+	select {
+	case p.reload <- struct{}{}:
+	default:
+	}
+}
+
+func (p *PubSub) watchConfig(ctx context.Context, sub chan *events.Event) {
+	if p.config == "" {
+		return
+	}
+	path := filepath.Clean(p.config)
+
+	// the directory is watched, the file is replaced by a rename when
+	// most editors save it.
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(filepath.Dir(path))
+		if err != nil {
+			watcher.Close()
+		}
+	}
+	var changes chan fsnotify.Event
+	var errs chan error
+	if err != nil {
+		p.logger.Error("cannot watch the config file", logging.Error(err))
+	} else {
+		changes, errs = watcher.Events, watcher.Errors
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if changes != nil {
+			defer watcher.Close()
+		}
+
+		var delay <-chan time.Time
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ctx.Done():
+				return
+			case e, ok := <-changes:
+				if !ok {
+					changes = nil
+					continue
+				}
+				if filepath.Clean(e.Name) == path &&
+					e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					delay = time.After(configDelay)
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				p.logger.Error("config watcher returned an error", logging.Error(err))
+			case <-delay:
+				delay = nil
+				if !p.reloadConfig(sub) {
+					return
+				}
+			case <-p.reload:
+				if !p.reloadConfig(sub) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// reloadConfig loads the config file again, the current config is kept
+// if it is invalid. It returns false if the pubsub is stopped.
+func (p *PubSub) reloadConfig(sub chan *events.Event) bool {
+	cfg, err := config.Load(p.config)
+	if err != nil {
+		p.logger.Error("cannot reload the config", logging.Error(err))
+		return true
+	}
+	p.logger.Info("config reloaded", logging.String("path", p.config))
+	p.SetRefresh(cfg.Refresh)
+	for _, reload := range p.reloads {
+		reload(cfg)
+	}
+	return p.send(sub, events.NewWithData(events.ConfigReloaded, cfg))
+}
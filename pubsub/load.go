@@ -0,0 +1,183 @@
+package pubsub
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/network/options"
+)
+
+// loadStep is the interval between two sends of the load generator, the
+// events of the rates are spread over the steps of a second.
+const loadStep = 10 * time.Millisecond
+
+// reportInterval is the interval between two reports of a continuous
+// load.
+const reportInterval = 10 * time.Second
+
+// Load is the synthetic load injected with the events of the node, a
+// developer mode to benchmark the refresh of the ui on a busy node.
+type Load struct {
+	// RoutingRate and GraphRate are the events sent per second during
+	// a burst.
+	RoutingRate int
+	GraphRate   int
+	// Burst is the duration of a burst and Pause the duration between
+	// two bursts, the load is continuous if Burst is zero and reported
+	// every reportInterval.
+	Burst time.Duration
+	Pause time.Duration
+}
+
+func (l Load) enabled() bool {
+	return l.RoutingRate > 0 || l.GraphRate > 0
+}
+
+// WithLoad enables the load generator.
+func (p *PubSub) WithLoad(l Load) *PubSub {
+	p.load = l
+	return p
+}
+
+// generator builds the synthetic events on the channels of the node, so
+// they go through the same lookups as the real ones.
+type generator struct {
+	rand     *rand.Rand
+	channels []*models.Channel
+	htlcID   uint64
+	// active are the forwards waiting for their settle event.
+	active []*models.RoutingEvent
+}
+
+func (g *generator) routingEvent(now time.Time) *models.RoutingEvent {
+	if len(g.active) > 0 && g.rand.Intn(2) == 0 {
+		event := *g.active[0]
+		g.active = g.active[1:]
+		event.LastUpdate = now
+		event.Status = models.RoutingStatusSettled
+		if g.rand.Intn(5) == 0 {
+			event.Status = models.RoutingStatusLinkFailed
+			event.FailureCode = 15
+			event.FailureDetail = "TEMPORARY_CHANNEL_FAILURE INSUFFICIENT_BALANCE"
+		}
+		return &event
+	}
+
+	g.htlcID++
+	event := &models.RoutingEvent{
+		IncomingChannelId: g.channels[g.rand.Intn(len(g.channels))].ID,
+		OutgoingChannelId: g.channels[g.rand.Intn(len(g.channels))].ID,
+		IncomingHtlcId:    g.htlcID,
+		OutgoingHtlcId:    g.htlcID,
+		LastUpdate:        now,
+		Direction:         models.RoutingForward,
+		Status:            models.RoutingStatusActive,
+		AmountMsat:        uint64(1000+g.rand.Intn(1000000)) * 1000,
+		FeeMsat:           uint64(g.rand.Intn(1000000)),
+	}
+	active := *event
+	g.active = append(g.active, &active)
+	return event
+}
+
+func (g *generator) graphUpdate() *models.ChannelEdgeUpdate {
+	return &models.ChannelEdgeUpdate{
+		ChanPoints: []string{g.channels[g.rand.Intn(len(g.channels))].ChannelPoint},
+	}
+}
+
+// runLoad sends the synthetic events until Stop is called, and logs the
+// events sent and the rate reached by each burst, below the configured
+// rates when the consumer of the events cannot keep up.
+func (p *PubSub) runLoad(ctx context.Context, sub chan *events.Event) {
+	channels, err := p.network.ListChannels(ctx, options.WithChannelPending)
+	if err != nil {
+		p.logger.Error("load: list channels returned an error", logging.Error(err))
+		return
+	}
+	if len(channels) == 0 {
+		p.logger.Info("load: no channel to generate events on")
+		return
+	}
+	g := &generator{
+		rand:     rand.New(rand.NewSource(1)),
+		channels: channels,
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			if !p.burst(g, sub) {
+				return
+			}
+			if p.load.Burst == 0 {
+				continue
+			}
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(p.load.Pause):
+			}
+		}
+	}()
+}
+
+// burst sends the events of a burst, false if the pubsub was stopped.
+func (p *PubSub) burst(g *generator, sub chan *events.Event) bool {
+	ticker := time.NewTicker(loadStep)
+	defer ticker.Stop()
+
+	duration := p.load.Burst
+	if duration == 0 {
+		duration = reportInterval
+	}
+	start := time.Now()
+	var routing, graph, sentRouting, sentGraph float64
+	for {
+		select {
+		case <-p.stop:
+			return false
+		case now := <-ticker.C:
+			if now.Sub(start) >= duration {
+				elapsed := now.Sub(start).Seconds()
+				p.logger.Info("load: burst sent",
+					logging.Int("routing_events", int(sentRouting)),
+					logging.Int("graph_updates", int(sentGraph)),
+					logging.Int("routing_rate", int(sentRouting/elapsed)),
+					logging.Int("graph_rate", int(sentGraph/elapsed)),
+					logging.Duration("duration", now.Sub(start)))
+				return true
+			}
+			routing += float64(p.load.RoutingRate) * loadStep.Seconds()
+			graph += float64(p.load.GraphRate) * loadStep.Seconds()
+			for ; routing >= 1; routing-- {
+				if !p.send(sub, events.NewWithData(events.RoutingEventUpdated, g.routingEvent(now))) {
+					return false
+				}
+				sentRouting++
+			}
+			for ; graph >= 1; graph-- {
+				if !p.send(sub, events.NewWithData(events.GraphUpdated, g.graphUpdate())) {
+					return false
+				}
+				sentGraph++
+			}
+		}
+	}
+}
+
+// send sends the event unless the pubsub is stopped while the consumer
+// is not reading.
+func (p *PubSub) send(sub chan *events.Event, event *events.Event) bool {
+	select {
+	case <-p.stop:
+		return false
+	case sub <- event:
+		return true
+	}
+}
@@ -1,9 +1,14 @@
+// Package pubsub normalizes the subscriptions and the polling of the
+// network into a single stream of events.Event, see the events package
+// for the types and their data.
 package pubsub
 
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
+	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/events"
 	"github.com/edouardparis/lntop/logging"
 	"github.com/edouardparis/lntop/network"
@@ -12,9 +17,26 @@ import (
 
 type PubSub struct {
 	stop    chan bool
+	stopped sync.Once
 	logger  logging.Logger
 	network *network.Network
 	wg      *sync.WaitGroup
+	load    Load
+	// refresh is the config of the ticker, replaced when the config is
+	// reloaded.
+	refresh   config.Refresh
+	refreshMu sync.Mutex
+	// config is the path of the config file watched, reloads are called
+	// with the config each time it is loaded again.
+	config  string
+	reload  chan struct{}
+	reloads []func(*config.Config)
+	// lost is signaled when a subscription fails.
+	lost chan struct{}
+	// exports are called with the routing events of the node.
+	exports []func(*models.RoutingEvent)
+	// active is the unix time in nanoseconds of the last activity.
+	active atomic.Int64
 }
 
 func New(logger logging.Logger, network *network.Network) *PubSub {
@@ -23,181 +45,283 @@ func New(logger logging.Logger, network *network.Network) *PubSub {
 		network: network,
 		wg:      &sync.WaitGroup{},
 		stop:    make(chan bool),
+		reload:  make(chan struct{}, 1),
+		lost:    make(chan struct{}, 1),
 	}
 }
 
-func (p *PubSub) invoices(ctx context.Context, sub chan *events.Event) {
-	p.wg.Add(3)
+// WithRoutingExport calls export with every routing event of the node as
+// it arrives, after the exports added before. The synthetic load is not
+// exported.
+func (p *PubSub) WithRoutingExport(export func(*models.RoutingEvent)) *PubSub {
+	p.exports = append(p.exports, export)
+	return p
+}
+
+func (p *PubSub) invoices(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
 	invoices := make(chan *models.Invoice)
-	ctx, cancel := context.WithCancel(ctx)
 
 	go func() {
 		for invoice := range invoices {
 			p.logger.Debug("receive invoice", logging.Object("invoice", invoice))
 			if invoice.Settled {
-				sub <- events.New(events.InvoiceSettled)
+				sub <- events.NewWithData(events.InvoiceSettled, invoice)
 			} else {
-				sub <- events.New(events.InvoiceCreated)
+				sub <- events.NewWithData(events.InvoiceCreated, invoice)
 			}
 		}
-		p.wg.Done()
+		wg.Done()
+	}()
+
+	go func() {
+		p.retry(ctx, "SubscribeInvoice", func(ctx context.Context) error {
+			return p.network.SubscribeInvoice(ctx, invoices)
+		})
+		close(invoices)
+		wg.Done()
 	}()
+}
+
+func (p *PubSub) payments(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
+	payments := make(chan *models.Payment)
 
 	go func() {
-		err := p.network.SubscribeInvoice(ctx, invoices)
-		if err != nil {
-			p.logger.Error("SubscribeInvoice returned an error", logging.Error(err))
+		for payment := range payments {
+			p.logger.Debug("receive payment", logging.Object("payment", payment))
+			sub <- events.NewWithData(events.PaymentUpdated, payment)
 		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		<-p.stop
-		cancel()
-		close(invoices)
-		p.wg.Done()
+		p.retry(ctx, "SubscribePayments", func(ctx context.Context) error {
+			return p.network.SubscribePayments(ctx, payments)
+		})
+		close(payments)
+		wg.Done()
 	}()
 }
 
-func (p *PubSub) transactions(ctx context.Context, sub chan *events.Event) {
-	p.wg.Add(3)
+func (p *PubSub) transactions(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
 	transactions := make(chan *models.Transaction)
-	ctx, cancel := context.WithCancel(ctx)
 
 	go func() {
 		for tx := range transactions {
 			p.logger.Debug("receive transaction", logging.String("tx_hash", tx.TxHash))
 			sub <- events.New(events.TransactionCreated)
 		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		err := p.network.SubscribeTransactions(ctx, transactions)
-		if err != nil {
-			p.logger.Error("SubscribeTransactions returned an error", logging.Error(err))
-		}
-		p.wg.Done()
-	}()
-
-	go func() {
-		<-p.stop
-		cancel()
+		p.retry(ctx, "SubscribeTransactions", func(ctx context.Context) error {
+			return p.network.SubscribeTransactions(ctx, transactions)
+		})
 		close(transactions)
-		p.wg.Done()
+		wg.Done()
 	}()
 }
 
-func (p *PubSub) routingUpdates(ctx context.Context, sub chan *events.Event) {
-	p.wg.Add(3)
+func (p *PubSub) routingUpdates(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
 	routingUpdates := make(chan *models.RoutingEvent)
-	ctx, cancel := context.WithCancel(ctx)
 
 	go func() {
 		for hu := range routingUpdates {
 			p.logger.Debug("receive htlcUpdate")
 			if !hu.IsEmpty() {
+				for _, export := range p.exports {
+					export(hu)
+				}
 				sub <- events.NewWithData(events.RoutingEventUpdated, hu)
 			}
 		}
-		p.wg.Done()
-	}()
-
-	go func() {
-		err := p.network.SubscribeRoutingEvents(ctx, routingUpdates)
-		if err != nil {
-			p.logger.Error("SubscribeRoutingEvents returned an error", logging.Error(err))
-		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		<-p.stop
-		cancel()
+		p.retry(ctx, "SubscribeRoutingEvents", func(ctx context.Context) error {
+			return p.network.SubscribeRoutingEvents(ctx, routingUpdates)
+		})
 		close(routingUpdates)
-		p.wg.Done()
+		wg.Done()
 	}()
 }
 
-func (p *PubSub) graphUpdates(ctx context.Context, sub chan *events.Event) {
-	p.wg.Add(3)
+func (p *PubSub) graphUpdates(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
 	graphUpdates := make(chan *models.ChannelEdgeUpdate)
-	ctx, cancel := context.WithCancel(ctx)
 
 	go func() {
+		// disabled are the channels disabled by their peer, an event is
+		// sent when a peer disables one.
+		disabled := map[string]bool{}
+		pubkey := ""
 		for gu := range graphUpdates {
 			p.logger.Debug("receive graph update")
 			sub <- events.NewWithData(events.GraphUpdated, gu)
+			if len(gu.Policies) == 0 {
+				continue
+			}
+			if pubkey == "" {
+				info, err := p.network.Info(ctx)
+				if err != nil {
+					p.logger.Error("network info returned an error", logging.Error(err))
+					continue
+				}
+				pubkey = info.PubKey
+			}
+			for _, e := range gu.Toward(pubkey) {
+				if e.Policy.Disabled && !disabled[e.ChanPoint] {
+					sub <- events.NewWithData(events.ChannelDisabledByPeer, &models.ChannelUpdate{
+						ChannelPoint: e.ChanPoint,
+						RemotePubKey: e.AdvertisingNode,
+					})
+				}
+				disabled[e.ChanPoint] = e.Policy.Disabled
+			}
 		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		err := p.network.SubscribeGraphEvents(ctx, graphUpdates)
-		if err != nil {
-			p.logger.Error("SubscribeGraphEvents returned an error", logging.Error(err))
+		p.retry(ctx, "SubscribeGraphEvents", func(ctx context.Context) error {
+			return p.network.SubscribeGraphEvents(ctx, graphUpdates)
+		})
+		close(graphUpdates)
+		wg.Done()
+	}()
+}
+
+func (p *PubSub) channelBackups(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
+	backups := make(chan *models.ChannelBackup)
+
+	go func() {
+		for backup := range backups {
+			p.logger.Debug("receive channel backup", logging.Int("channels", len(backup.ChanPoints)))
+			sub <- events.NewWithData(events.ChannelBackupUpdated, backup)
 		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		<-p.stop
-		cancel()
-		close(graphUpdates)
-		p.wg.Done()
+		p.retry(ctx, "SubscribeChannelBackups", func(ctx context.Context) error {
+			return p.network.SubscribeChannelBackups(ctx, backups)
+		})
+		close(backups)
+		wg.Done()
 	}()
 }
 
-func (p *PubSub) channels(ctx context.Context, sub chan *events.Event) {
-	p.wg.Add(3)
+func (p *PubSub) channels(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event) {
+	wg.Add(2)
 	channels := make(chan *models.ChannelUpdate)
-	ctx, cancel := context.WithCancel(ctx)
 
 	go func() {
-		for range channels {
+		for update := range channels {
 			p.logger.Debug("channels updated")
-			sub <- events.New(events.ChannelActive)
-		}
-		p.wg.Done()
-	}()
-
-	go func() {
-		err := p.network.SubscribeChannels(ctx, channels)
-		if err != nil {
-			p.logger.Error("SubscribeChannels returned an error", logging.Error(err))
+			switch {
+			case update.CloseType != 0:
+				sub <- events.NewWithData(events.ChannelClosed, update)
+			case update.Status == models.ChannelInactive:
+				sub <- events.NewWithData(events.ChannelInactive, update)
+			case update.ChannelPoint != "":
+				sub <- events.NewWithData(events.ChannelActive, update)
+			default:
+				sub <- events.New(events.ChannelActive)
+			}
 		}
-		p.wg.Done()
+		wg.Done()
 	}()
 
 	go func() {
-		<-p.stop
-		cancel()
+		p.retry(ctx, "SubscribeChannels", func(ctx context.Context) error {
+			return p.network.SubscribeChannels(ctx, channels)
+		})
 		close(channels)
-		p.wg.Done()
+		wg.Done()
 	}()
 }
 
+// Stop stops the pubsub, the calls after the first one do nothing.
 func (p *PubSub) Stop() {
-	p.stop <- true
-	close(p.stop)
-	p.logger.Debug("Received signal, gracefully stopping")
+	p.stopped.Do(func() {
+		p.stop <- true
+		close(p.stop)
+		p.logger.Debug("Received signal, gracefully stopping")
+	})
 }
 
-func (p *PubSub) Run(ctx context.Context, sub chan *events.Event) {
-	p.logger.Debug("Starting...")
+// track returns the channels forwarding the events to sub: the events of
+// tracked touch the pubsub, the ones polled by the ticker do not, or the
+// node would never be idle. done is closed once both are closed and
+// drained.
+func (p *PubSub) track(sub chan *events.Event) (tracked, polled chan *events.Event, done chan struct{}) {
+	tracked = make(chan *events.Event)
+	polled = make(chan *events.Event)
+	done = make(chan struct{})
+	go func() {
+		in, quiet := tracked, polled
+		for in != nil || quiet != nil {
+			select {
+			case e, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				p.Touch()
+				sub <- e
+			case e, ok := <-quiet:
+				if !ok {
+					quiet = nil
+					continue
+				}
+				sub <- e
+			}
+		}
+		close(done)
+	}()
+	return tracked, polled, done
+}
 
-	p.invoices(ctx, sub)
-	p.transactions(ctx, sub)
-	p.routingUpdates(ctx, sub)
-	p.channels(ctx, sub)
-	p.graphUpdates(ctx, sub)
-	p.ticker(ctx, sub,
-		withTickerInfo(),
-		withTickerChannelsBalance(),
+// subscribe subscribes to the updates of the node and polls it until the
+// context is done, the channels of the subscriptions are closed once
+// their subscription returned. The events of the polls are sent to polled.
+func (p *PubSub) subscribe(ctx context.Context, wg *sync.WaitGroup, sub, polled chan *events.Event) {
+	p.invoices(ctx, wg, sub)
+	p.payments(ctx, wg, sub)
+	p.transactions(ctx, wg, sub)
+	p.routingUpdates(ctx, wg, sub)
+	p.channels(ctx, wg, sub)
+	p.graphUpdates(ctx, wg, sub)
+	p.channelBackups(ctx, wg, sub)
+	p.ticker(ctx, wg, polled,
+		every(func(r config.Refresh) int { return r.InfoInterval }, withTickerInfo()),
+		every(func(r config.Refresh) int { return r.BalanceInterval }, withTickerChannelsBalance()),
+		every(func(r config.Refresh) int { return r.PeersInterval }, withTickerPeers()),
 		// no need for ticker Wallet balance, transactions subscriber is enough
 		// withTickerWalletBalance(),
 	)
+}
+
+// Run sends the events to sub until Stop is called.
+func (p *PubSub) Run(ctx context.Context, sub chan *events.Event) {
+	p.logger.Debug("Starting...")
+
+	tracked, polled, done := p.track(sub)
+	p.watchState(ctx, tracked, polled)
+	p.watchConfig(ctx, tracked)
+	if p.load.enabled() {
+		p.runLoad(ctx, tracked)
+	}
 
 	<-p.stop
 	p.wg.Wait()
+	close(tracked)
+	close(polled)
+	<-done
 }
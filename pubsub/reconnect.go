@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/logging"
+)
+
+const (
+	// reconnectMin and reconnectMax bound the backoff between two
+	// attempts, doubled at each attempt.
+	reconnectMin = time.Second
+	reconnectMax = 30 * time.Second
+	// downAttempts is the number of failed attempts to reach the node
+	// after which it is down.
+	downAttempts = 5
+	// stableAfter is how long a subscription must run for its backoff to
+	// start again from reconnectMin.
+	stableAfter = time.Minute
+)
+
+// backoff returns the delay before the attempt, from zero.
+func backoff(attempt int) time.Duration {
+	d := reconnectMin
+	for i := 0; i < attempt && d < reconnectMax; i++ {
+		d *= 2
+	}
+	if d > reconnectMax {
+		d = reconnectMax
+	}
+	return d
+}
+
+// retry runs the subscription until the context is done. Each time it
+// fails, the state of the node is checked at once and it runs again
+// after the backoff, the streams of a node not answering are stopped by
+// watchState until it answers again.
+func (p *PubSub) retry(ctx context.Context, name string, subscribe func(context.Context) error) {
+	Retry(ctx, p.logger, name, subscribe, p.lose)
+}
+
+// Retry runs the subscription until the context is done, again after the
+// backoff each time it fails, e.g. when the node restarts. failed, if not
+// nil, is called at each failure.
+func Retry(ctx context.Context, logger logging.Logger, name string, subscribe func(context.Context) error,
+	failed func()) {
+	attempt := 0
+	for {
+		start := time.Now()
+		err := subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("subscription ended")
+		}
+		logger.Error(name+" returned an error", logging.Error(err))
+		if failed != nil {
+			failed()
+		}
+
+		if time.Since(start) > stableAfter {
+			attempt = 0
+		}
+		timer := time.NewTimer(backoff(attempt))
+		attempt++
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// lose wakes watchState up to check the state of the node after a
+// subscription failed.
+func (p *PubSub) lose() {
+	select {
+	case p.lost <- struct{}{}:
+	default:
+	}
+}
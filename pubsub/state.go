@@ -0,0 +1,113 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// stateInterval is the interval between two checks of the state of the
+// node.
+const stateInterval = 5 * time.Second
+
+// watchState subscribes to the node while it is ready. Its state is
+// checked every stateInterval: the subscriptions are stopped when it is
+// not ready anymore, e.g. its wallet is locked after a restart, and
+// started again once it is ready, with a NodeStateChanged event each
+// time. A node not answering is checked again with a backoff instead,
+// with a ConnectionChanged event at each attempt, and at once when a
+// subscription fails.
+func (p *PubSub) watchState(ctx context.Context, sub, polled chan *events.Event) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ready, first := false, true
+		connection := models.Connection{State: models.ConnectionConnected}
+		cancel := func() {}
+		defer func() { cancel() }()
+		for {
+			state := p.state(ctx)
+			if state.Ready() != ready {
+				ready = state.Ready()
+				if ready {
+					cancel = p.session(ctx, sub, polled)
+				} else {
+					cancel()
+				}
+				p.logger.Info("node state changed", logging.String("state", state.String()))
+				// the subscriptions of a node ready at start are not
+				// an event.
+				if !(first && ready) && !p.send(sub, events.NewWithData(events.NodeStateChanged, state)) {
+					return
+				}
+			}
+			first = false
+
+			wait := stateInterval
+			next := models.Connection{State: models.ConnectionConnected}
+			if state == models.NodeStateUnavailable {
+				next.State = models.ConnectionReconnecting
+				next.Attempts = connection.Attempts + 1
+				if next.Attempts > downAttempts {
+					next.State = models.ConnectionDown
+				}
+				wait = backoff(connection.Attempts)
+			}
+			if next != connection {
+				connection = next
+				p.logger.Info("connection changed",
+					logging.String("state", connection.State.String()),
+					logging.Int("attempts", connection.Attempts))
+				if !p.send(sub, events.NewWithData(events.ConnectionChanged, connection)) {
+					return
+				}
+			}
+
+			// the subscriptions failing with the connection do not
+			// shorten the backoff.
+			lost := p.lost
+			if connection.State != models.ConnectionConnected {
+				lost = nil
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-p.stop:
+				timer.Stop()
+				return
+			case <-lost:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// session subscribes to the node until the returned function is called,
+// it returns once the subscriptions of the session are stopped.
+func (p *PubSub) session(ctx context.Context, sub, polled chan *events.Event) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	wg := &sync.WaitGroup{}
+	p.subscribe(ctx, wg, sub, polled)
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// state returns the state of the node, unavailable if it does not
+// answer.
+func (p *PubSub) state(ctx context.Context) models.NodeState {
+	ctx, cancel := context.WithTimeout(ctx, stateInterval)
+	defer cancel()
+	state, err := p.network.GetState(ctx)
+	if err != nil {
+		p.logger.Debug("get state returned an error", logging.Error(err))
+		return models.NodeStateUnavailable
+	}
+	return state
+}
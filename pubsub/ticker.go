@@ -2,8 +2,10 @@ package pubsub
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/events"
 	"github.com/edouardparis/lntop/logging"
 	"github.com/edouardparis/lntop/network"
@@ -12,19 +14,141 @@ import (
 
 type tickerFunc func(context.Context, logging.Logger, *network.Network, chan *events.Event)
 
-func (p *PubSub) ticker(ctx context.Context, sub chan *events.Event, fn ...tickerFunc) {
-	p.wg.Add(1)
-	ticker := time.NewTicker(3 * time.Second)
+// tickerSource is a function of the ticker run every interval seconds of
+// the config, at each poll of the ticker if zero and never if negative.
+type tickerSource struct {
+	run      tickerFunc
+	interval func(config.Refresh) int
+	last     time.Time
+}
+
+func every(interval func(config.Refresh) int, run tickerFunc) *tickerSource {
+	return &tickerSource{run: run, interval: interval}
+}
+
+// due returns true if the source is to be run now, polled is true if the
+// ticker polls the node now. The ticker wakes up every min, a shorter
+// interval is min.
+func (s *tickerSource) due(cfg config.Refresh, now time.Time, polled bool, min time.Duration) bool {
+	n := s.interval(cfg)
+	switch {
+	case n < 0:
+		return false
+	case n == 0:
+		return polled
+	default:
+		return now.Sub(s.last) >= time.Duration(n)*time.Second-min/2
+	}
+}
+
+// Default intervals of the ticker, see config.Refresh.
+const (
+	defaultRefreshMin  = 3 * time.Second
+	defaultRefreshMax  = 30 * time.Second
+	defaultRefreshIdle = time.Minute
+)
+
+// WithRefresh sets the intervals of the ticker.
+func (p *PubSub) WithRefresh(cfg config.Refresh) *PubSub {
+	p.SetRefresh(cfg)
+	return p
+}
+
+// SetRefresh replaces the intervals of the ticker, they are used from
+// its next run.
+func (p *PubSub) SetRefresh(cfg config.Refresh) {
+	p.refreshMu.Lock()
+	p.refresh = cfg
+	p.refreshMu.Unlock()
+}
+
+func (p *PubSub) refreshConfig() config.Refresh {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	return p.refresh
+}
+
+// intervals returns the min, max and idle intervals of the ticker.
+func intervals(cfg config.Refresh) (time.Duration, time.Duration, time.Duration) {
+	min := seconds(cfg.Min, defaultRefreshMin)
+	max := seconds(cfg.Max, defaultRefreshMax)
+	idle := seconds(cfg.Idle, defaultRefreshIdle)
+	if max < min {
+		max = min
+	}
+	return min, max, idle
+}
+
+// Touch reports an activity, the ticker polls at the fast interval
+// until the node and the user are idle again.
+func (p *PubSub) Touch() {
+	p.active.Store(time.Now().UnixNano())
+}
+
+func seconds(n int, d time.Duration) time.Duration {
+	if n <= 0 {
+		return d
+	}
+	return time.Duration(n) * time.Second
+}
+
+// ticker polls the node at the min interval while the pubsub is
+// touched, and doubles the interval up to max at each poll once it was
+// not touched for idle. It wakes up every min interval to go back to it
+// as soon as it is touched. The intervals are read again at each wake up.
+// The sources with an interval of their own run at it instead of at the
+// polls.
+func (p *PubSub) ticker(ctx context.Context, wg *sync.WaitGroup, sub chan *events.Event, sources ...*tickerSource) {
+	min, _, _ := intervals(p.refreshConfig())
+
+	p.Touch()
+	wg.Add(1)
+	ticker := time.NewTicker(min)
 	go func() {
+		interval := min
+		last := time.Now()
 		for {
 			select {
 			case <-p.stop:
 				ticker.Stop()
-				p.wg.Done()
+				wg.Done()
+				return
+			case <-ctx.Done():
+				ticker.Stop()
+				wg.Done()
 				return
-			case <-ticker.C:
-				for i := range fn {
-					fn[i](ctx, p.logger, p.network, sub)
+			case now := <-ticker.C:
+				cfg := p.refreshConfig()
+				m, max, idle := intervals(cfg)
+				if m != min {
+					min = m
+					ticker.Reset(min)
+				}
+				if interval < min {
+					interval = min
+				} else if interval > max {
+					interval = max
+				}
+				polled := true
+				if now.Sub(time.Unix(0, p.active.Load())) < idle {
+					interval = min
+				} else if now.Sub(last) < interval-min/2 {
+					polled = false
+				} else if interval < max {
+					interval *= 2
+					if interval > max {
+						interval = max
+					}
+					p.logger.Debug("idle, refresh backed off", logging.Duration("interval", interval))
+				}
+				if polled {
+					last = now
+				}
+				for _, s := range sources {
+					if s.due(cfg, now, polled, min) {
+						s.last = now
+						s.run(ctx, p.logger, p.network, sub)
+					}
 				}
 			}
 		}
@@ -102,3 +226,35 @@ func withTickerWalletBalance() tickerFunc {
 		old = walletBalance
 	}
 }
+
+// withTickerPeers checks if the traffic, the ping time, the last error or
+// the flap count of the peers changed in the ticker interval.
+func withTickerPeers() tickerFunc {
+	var old map[string]models.Peer
+	return func(ctx context.Context, logger logging.Logger, net *network.Network, sub chan *events.Event) {
+		peers, err := net.ListPeers(ctx)
+		if err != nil {
+			logger.Error("network list peers returned an error", logging.Error(err))
+			return
+		}
+		current := make(map[string]models.Peer, len(peers))
+		changed := old != nil && len(old) != len(peers)
+		for _, p := range peers {
+			current[p.PubKey] = *p
+			if old == nil {
+				continue
+			}
+			o, ok := old[p.PubKey]
+			if !ok || o.BytesSent != p.BytesSent || o.BytesRecv != p.BytesRecv ||
+				o.SatSent != p.SatSent || o.SatRecv != p.SatRecv ||
+				o.PingTime != p.PingTime || !o.LastErrorTime.Equal(p.LastErrorTime) ||
+				o.FlapCount != p.FlapCount {
+				changed = true
+			}
+		}
+		if changed {
+			sub <- events.New(events.PeerTrafficUpdated)
+		}
+		old = current
+	}
+}
@@ -0,0 +1,65 @@
+// Package qr renders strings as QR codes drawn with unicode half blocks,
+// two modules per character, for terminals with a dark background.
+package qr
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+// Render returns the lines of the QR code of the content, with its quiet
+// zone.
+func Render(content string) (string, error) {
+	code, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return code.ToSmallString(false), nil
+}
+
+// Invoice returns the content of the QR code of a payment request, the
+// uppercase bech32 fits in a smaller code.
+func Invoice(payreq string) string {
+	return "LIGHTNING:" + strings.ToUpper(payreq)
+}
+
+// Address returns the content of the QR code of an on-chain address.
+func Address(address string) string {
+	return "bitcoin:" + address
+}
+
+// LNDConnect returns the lndconnect URI of the node of the config, with
+// its TLS certificate and macaroon, read by mobile wallets to connect to
+// the node.
+func LNDConnect(cfg *config.Network) (string, error) {
+	host := strings.TrimPrefix(cfg.Address, "//")
+	if host == "" {
+		return "", errors.New("missing network address")
+	}
+
+	values := []string{}
+	cert, err := cfg.CertDER()
+	if err != nil {
+		return "", err
+	}
+	if cert != nil {
+		values = append(values, "cert="+base64.RawURLEncoding.EncodeToString(cert))
+	}
+
+	if cfg.Macaroon != "" || cfg.MacaroonHex != "" {
+		data, err := cfg.MacaroonBytes()
+		if err != nil {
+			return "", err
+		}
+		values = append(values, "macaroon="+base64.RawURLEncoding.EncodeToString(data))
+	}
+
+	u := url.URL{Scheme: "lndconnect", Host: host, RawQuery: strings.Join(values, "&")}
+	return u.String(), nil
+}
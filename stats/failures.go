@@ -0,0 +1,200 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// FailureHours is the number of hours of the failure rates.
+const FailureHours = 24
+
+// Failures aggregates the forwards resolved by the routing events: their
+// failure reasons, the failures of the pairs of channels, the failure
+// rates by hour and the outgoing channels failing for lack of balance.
+type Failures struct {
+	Forwards int
+	Failed   int
+	reasons  map[string]int
+	pairs    map[Pair]*PairFailures
+	hours    map[int64]*Rate
+	hotspots map[uint64]*Hotspot
+}
+
+// Pair is the incoming and outgoing channel of a forward.
+type Pair struct {
+	In  uint64
+	Out uint64
+}
+
+// PairFailures are the forwards resolved through a pair.
+type PairFailures struct {
+	Pair
+	Forwards int
+	Failed   int
+	// Reason is the last failure reason of the pair.
+	Reason string
+}
+
+// Rate returns the part of the forwards failed, zero without forwards.
+func (p *PairFailures) Rate() float64 {
+	if p.Forwards == 0 {
+		return 0
+	}
+	return float64(p.Failed) / float64(p.Forwards)
+}
+
+// Rate is the forwards resolved within an hour starting at Time.
+type Rate struct {
+	Time     time.Time
+	Forwards int
+	Failed   int
+}
+
+// Hotspot is an outgoing channel failing forwards for lack of local
+// balance.
+type Hotspot struct {
+	ChannelID  uint64
+	Failures   int
+	AmountMsat uint64
+	Last       time.Time
+}
+
+// ReasonCount is the number of failures of a reason.
+type ReasonCount struct {
+	Reason string
+	Count  int
+}
+
+func NewFailures() *Failures {
+	return &Failures{
+		reasons:  make(map[string]int),
+		pairs:    make(map[Pair]*PairFailures),
+		hours:    make(map[int64]*Rate),
+		hotspots: make(map[uint64]*Hotspot),
+	}
+}
+
+// Add aggregates the event if it resolves a forward, the hours older
+// than FailureHours are dropped.
+func (f *Failures) Add(e *models.RoutingEvent) {
+	if e.Direction != models.RoutingForward || !e.Resolved() {
+		return
+	}
+	failed := e.Status != models.RoutingStatusSettled
+	f.Forwards++
+
+	pair := Pair{In: e.IncomingChannelId, Out: e.OutgoingChannelId}
+	p, ok := f.pairs[pair]
+	if !ok {
+		p = &PairFailures{Pair: pair}
+		f.pairs[pair] = p
+	}
+	p.Forwards++
+
+	hour := e.LastUpdate.Truncate(time.Hour)
+	r, ok := f.hours[hour.Unix()]
+	if !ok {
+		r = &Rate{Time: hour}
+		f.hours[hour.Unix()] = r
+	}
+	r.Forwards++
+	for t := range f.hours {
+		if hour.Unix()-t >= FailureHours*3600 {
+			delete(f.hours, t)
+		}
+	}
+
+	if !failed {
+		return
+	}
+	f.Failed++
+	reason := e.FailureReason()
+	f.reasons[reason]++
+	p.Failed++
+	p.Reason = reason
+	r.Failed++
+
+	if e.InsufficientBalance() {
+		h, ok := f.hotspots[e.OutgoingChannelId]
+		if !ok {
+			h = &Hotspot{ChannelID: e.OutgoingChannelId}
+			f.hotspots[e.OutgoingChannelId] = h
+		}
+		h.Failures++
+		h.AmountMsat += e.AmountMsat
+		if e.LastUpdate.After(h.Last) {
+			h.Last = e.LastUpdate
+		}
+	}
+}
+
+// Reasons returns the failure reasons, the most frequent first.
+func (f *Failures) Reasons() []ReasonCount {
+	list := make([]ReasonCount, 0, len(f.reasons))
+	for reason, n := range f.reasons {
+		list = append(list, ReasonCount{Reason: reason, Count: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Reason < list[j].Reason
+	})
+	return list
+}
+
+// TopPairs returns the n pairs with the most failures.
+func (f *Failures) TopPairs(n int) []PairFailures {
+	var list []PairFailures
+	for _, p := range f.pairs {
+		if p.Failed > 0 {
+			list = append(list, *p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Failed != list[j].Failed {
+			return list[i].Failed > list[j].Failed
+		}
+		return list[i].Rate() > list[j].Rate()
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// Rates returns the forwards of the last FailureHours hours ending at
+// now, the oldest first, the hours without forwards are zero.
+func (f *Failures) Rates(now time.Time) []Rate {
+	list := make([]Rate, FailureHours)
+	hour := now.Truncate(time.Hour)
+	for i := range list {
+		t := hour.Add(-time.Duration(FailureHours-1-i) * time.Hour)
+		list[i].Time = t
+		if r, ok := f.hours[t.Unix()]; ok {
+			list[i] = *r
+		}
+	}
+	return list
+}
+
+// Hotspots returns the n outgoing channels with the most failures for
+// lack of balance.
+func (f *Failures) Hotspots(n int) []Hotspot {
+	list := make([]Hotspot, 0, len(f.hotspots))
+	for _, h := range f.hotspots {
+		list = append(list, *h)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Failures != list[j].Failures {
+			return list[i].Failures > list[j].Failures
+		}
+		return list[i].AmountMsat > list[j].AmountMsat
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
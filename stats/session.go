@@ -0,0 +1,117 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// SessionWindow is the default duration of the rolling rates of the
+// session.
+const SessionWindow = 15 * time.Minute
+
+// Session keeps the forwards resolved by the routing events over a
+// rolling window, for the rates of the session.
+type Session struct {
+	Window time.Duration
+	// start is the time of the session or of its first forward if older.
+	start time.Time
+	// forwards are the forwards of the window, the oldest first.
+	forwards []sessionForward
+}
+
+type sessionForward struct {
+	at         time.Time
+	amountMsat uint64
+	feeMsat    uint64
+	settled    bool
+}
+
+// SessionRates are the rates of the forwards resolved over Period, the
+// window or the time since the start of the session if shorter.
+type SessionRates struct {
+	Period   time.Duration
+	Forwards int
+	Settled  int
+	// ForwardsPerMinute counts the settled and failed forwards.
+	ForwardsPerMinute float64
+	// SatsPerHour and FeesPerHour are the amounts forwarded and the fees
+	// earned by the settled forwards.
+	SatsPerHour float64
+	FeesPerHour float64
+}
+
+// Success returns the percentage of the forwards settled, false without
+// forwards.
+func (r SessionRates) Success() (float64, bool) {
+	if r.Forwards == 0 {
+		return 0, false
+	}
+	return float64(r.Settled) * 100 / float64(r.Forwards), true
+}
+
+// NewSession returns a session starting now, the default window if zero.
+func NewSession(window time.Duration, now time.Time) *Session {
+	if window <= 0 {
+		window = SessionWindow
+	}
+	return &Session{Window: window, start: now}
+}
+
+// Add keeps the event if it resolves a forward, at its last update. The
+// amount and the fee are the ones of the forward, a settle does not carry
+// them.
+func (s *Session) Add(e *models.RoutingEvent) {
+	if e.Direction != models.RoutingForward || !e.Resolved() {
+		return
+	}
+	f := sessionForward{
+		at:         e.LastUpdate,
+		amountMsat: e.AmountMsat,
+		feeMsat:    e.FeeMsat,
+		settled:    e.Status == models.RoutingStatusSettled,
+	}
+	if f.at.Before(s.start) {
+		s.start = f.at
+	}
+	i := sort.Search(len(s.forwards), func(i int) bool {
+		return s.forwards[i].at.After(f.at)
+	})
+	s.forwards = append(s.forwards, sessionForward{})
+	copy(s.forwards[i+1:], s.forwards[i:])
+	s.forwards[i] = f
+}
+
+// Rates returns the rates of the window ending at now, the forwards older
+// than the window are dropped.
+func (s *Session) Rates(now time.Time) SessionRates {
+	from := now.Add(-s.Window)
+	i := sort.Search(len(s.forwards), func(i int) bool {
+		return !s.forwards[i].at.Before(from)
+	})
+	s.forwards = s.forwards[i:]
+
+	r := SessionRates{Period: s.Window}
+	if elapsed := now.Sub(s.start); elapsed < r.Period {
+		r.Period = elapsed
+	}
+	// a session just started has no meaningful rate.
+	if r.Period < time.Minute {
+		r.Period = time.Minute
+	}
+	var amountMsat, feeMsat uint64
+	for _, f := range s.forwards {
+		r.Forwards++
+		if !f.settled {
+			continue
+		}
+		r.Settled++
+		amountMsat += f.amountMsat
+		feeMsat += f.feeMsat
+	}
+	r.ForwardsPerMinute = float64(r.Forwards) / r.Period.Minutes()
+	r.SatsPerHour = float64(amountMsat) / 1000 / r.Period.Hours()
+	r.FeesPerHour = float64(feeMsat) / 1000 / r.Period.Hours()
+	return r
+}
@@ -0,0 +1,95 @@
+// Package stats aggregates the forwarding history by channel over the
+// last day, week and month, for the fee report of the channels, the
+// failures of the forwards of the routing events and their rates over
+// the session.
+package stats
+
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// Windows are the durations of the aggregates, the forwarding history
+// is read over the longest one.
+var Windows = [...]time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// WindowNames are the labels of the windows, the suffixes of the columns.
+var WindowNames = [len(Windows)]string{"1D", "7D", "30D"}
+
+// Period is the duration of the forwarding history to aggregate.
+func Period() time.Duration {
+	return Windows[len(Windows)-1]
+}
+
+// Window is the aggregate of the forwards through a channel, the fees
+// are earned on the outgoing channel of a forward.
+type Window struct {
+	ForwardsIn    int
+	AmountInMsat  uint64
+	ForwardsOut   int
+	AmountOutMsat uint64
+	FeeMsat       uint64
+}
+
+// Volume returns the amount in sat forwarded through the channel in
+// both directions.
+func (w Window) Volume() int64 {
+	return int64((w.AmountInMsat + w.AmountOutMsat) / 1000)
+}
+
+// Fee returns the fees in sat earned by the channel.
+func (w Window) Fee() int64 {
+	return int64(w.FeeMsat / 1000)
+}
+
+// Channel holds the aggregates of a channel, in the order of Windows.
+type Channel [len(Windows)]Window
+
+// Report is the aggregates of the channels by channel id.
+type Report map[uint64]*Channel
+
+// NewReport aggregates the forwarding events, a forward is in every
+// window ending at now that contains it.
+func NewReport(forwards []*models.ForwardingEvent, now time.Time) Report {
+	r := make(Report)
+	for _, f := range forwards {
+		age := now.Sub(f.EventTime)
+		if age < 0 {
+			age = 0
+		}
+		in, out := r.channel(f.ChanIdIn), r.channel(f.ChanIdOut)
+		for i := range Windows {
+			if age >= Windows[i] {
+				continue
+			}
+			in[i].ForwardsIn++
+			in[i].AmountInMsat += f.AmtInMsat
+			out[i].ForwardsOut++
+			out[i].AmountOutMsat += f.AmtOutMsat
+			out[i].FeeMsat += f.FeeMsat
+		}
+	}
+	return r
+}
+
+// Channel returns the aggregates of the channel, zero without forwards.
+func (r Report) Channel(id uint64) Channel {
+	if c, ok := r[id]; ok {
+		return *c
+	}
+	return Channel{}
+}
+
+func (r Report) channel(id uint64) *Channel {
+	c, ok := r[id]
+	if !ok {
+		c = &Channel{}
+		r[id] = c
+	}
+	return c
+}
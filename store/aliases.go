@@ -0,0 +1,54 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// NodeAlias is the alias of a node and the time it was fetched.
+type NodeAlias struct {
+	Alias   string    `json:"alias"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// SetNodeAliases records the aliases of the nodes by pubkey.
+func (s *Store) SetNodeAliases(aliases map[string]*NodeAlias) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(aliasesBucket)
+		for pubkey, alias := range aliases {
+			data, err := json.Marshal(alias)
+			if err != nil {
+				return err
+			}
+			err = b.Put([]byte(pubkey), data)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+// NodeAliases returns the aliases of the nodes by pubkey.
+func (s *Store) NodeAliases() (map[string]*NodeAlias, error) {
+	aliases := map[string]*NodeAlias{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(aliasesBucket).ForEach(func(k, v []byte) error {
+			alias := &NodeAlias{}
+			err := json.Unmarshal(v, alias)
+			if err != nil {
+				return err
+			}
+			aliases[string(k)] = alias
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return aliases, nil
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// BalanceSample is the balance of a channel at a time.
+type BalanceSample struct {
+	Time     time.Time `json:"time"`
+	Local    int64     `json:"local"`
+	Remote   int64     `json:"remote"`
+	Capacity int64     `json:"capacity"`
+}
+
+// AddBalances records the balances of the channels at the time, each in
+// the bucket of its channel point.
+func (s *Store) AddBalances(t time.Time, channels []*models.Channel) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, ch := range channels {
+			if ch.ChannelPoint == "" {
+				continue
+			}
+			data, err := json.Marshal(&BalanceSample{
+				Time:     t,
+				Local:    ch.LocalBalance,
+				Remote:   ch.RemoteBalance,
+				Capacity: ch.Capacity,
+			})
+			if err != nil {
+				return err
+			}
+			b, err := tx.Bucket(balancesBucket).CreateBucketIfNotExists([]byte(ch.ChannelPoint))
+			if err != nil {
+				return err
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			err = b.Put(timeKey(t, seq), data)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+// Balances returns the samples of the channels since the time by channel
+// point, oldest first.
+func (s *Store) Balances(since time.Time) (map[string][]*BalanceSample, error) {
+	samples := map[string][]*BalanceSample{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		balances := tx.Bucket(balancesBucket)
+		return balances.ForEachBucket(func(name []byte) error {
+			c := balances.Bucket(name).Cursor()
+			list := []*BalanceSample{}
+			for k, v := c.Seek(timeKey(since, 0)); k != nil; k, v = c.Next() {
+				sample := &BalanceSample{}
+				err := json.Unmarshal(v, sample)
+				if err != nil {
+					return err
+				}
+				list = append(list, sample)
+			}
+			if len(list) > 0 {
+				samples[string(name)] = list
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return samples, nil
+}
+
+// PruneBalances deletes the samples older than the time, and the buckets
+// of the channels left without samples.
+func (s *Store) PruneBalances(before time.Time) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		balances := tx.Bucket(balancesBucket)
+		empty := [][]byte{}
+		err := balances.ForEachBucket(func(name []byte) error {
+			c := balances.Bucket(name).Cursor()
+			end := timeKey(before, 0)
+			for k, _ := c.First(); k != nil && string(k) < string(end); k, _ = c.First() {
+				err := c.Delete()
+				if err != nil {
+					return err
+				}
+			}
+			if k, _ := c.First(); k == nil {
+				empty = append(empty, append([]byte{}, name...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range empty {
+			err := balances.DeleteBucket(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.WithStack(err)
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ChannelNote is the tags and the note of a channel set in the ui.
+type ChannelNote struct {
+	Tags []string `json:"tags,omitempty"`
+	Note string   `json:"note,omitempty"`
+}
+
+// SetChannelNote records the tags and the note of the channel, deleted if
+// both are empty.
+func (s *Store) SetChannelNote(channelPoint string, note *ChannelNote) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(notesBucket)
+		if len(note.Tags) == 0 && note.Note == "" {
+			return b.Delete([]byte(channelPoint))
+		}
+		data, err := json.Marshal(note)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(channelPoint), data)
+	})
+	return errors.WithStack(err)
+}
+
+// ChannelNotes returns the tags and the notes of the channels by channel
+// point.
+func (s *Store) ChannelNotes() (map[string]*ChannelNote, error) {
+	notes := map[string]*ChannelNote{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(notesBucket).ForEach(func(k, v []byte) error {
+			note := &ChannelNote{}
+			err := json.Unmarshal(v, note)
+			if err != nil {
+				return err
+			}
+			notes[string(k)] = note
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return notes, nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// PolicyChange is a change of the routing policy of a channel, by the
+// node if Local or by the peer.
+type PolicyChange struct {
+	ChannelPoint string                `json:"channel_point"`
+	ChannelID    uint64                `json:"channel_id"`
+	PubKey       string                `json:"pubkey"`
+	Local        bool                  `json:"local"`
+	Time         time.Time             `json:"time"`
+	Old          *models.RoutingPolicy `json:"old"`
+	New          *models.RoutingPolicy `json:"new"`
+}
+
+// AddPolicyChange records the change in the bucket of its channel.
+func (s *Store) AddPolicyChange(c *PolicyChange) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(policiesBucket).CreateBucketIfNotExists([]byte(c.ChannelPoint))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(timeKey(c.Time, seq), data)
+	})
+	return errors.WithStack(err)
+}
+
+// PolicyChanges returns the changes of the channel, oldest first.
+func (s *Store) PolicyChanges(channelPoint string) ([]*PolicyChange, error) {
+	changes := []*PolicyChange{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(policiesBucket).Bucket([]byte(channelPoint))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			c := &PolicyChange{}
+			err := json.Unmarshal(v, c)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, c)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// AddRoutingEvent records the event, merged with the recorded one of the
+// same HTLC. The events are sorted by the time of their first record.
+func (s *Store) AddRoutingEvent(e *models.RoutingEvent) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(routingBucket)
+		index := tx.Bucket(routingIndexBucket)
+		id := routingID(e)
+		event := e
+		key := index.Get(id)
+		if key != nil {
+			recorded := &models.RoutingEvent{}
+			err := json.Unmarshal(b.Get(key), recorded)
+			if err != nil {
+				return err
+			}
+			recorded.Update(e)
+			event = recorded
+		} else {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			t := e.LastUpdate
+			if t.IsZero() {
+				t = time.Now()
+			}
+			key = timeKey(t, seq)
+			err = index.Put(id, key)
+			if err != nil {
+				return err
+			}
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, data)
+	})
+	return errors.WithStack(err)
+}
+
+// RoutingEvents returns the last n events recorded, oldest first.
+func (s *Store) RoutingEvents(n int) ([]*models.RoutingEvent, error) {
+	events := []*models.RoutingEvent{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(routingBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(events) < n; k, v = c.Prev() {
+			e := &models.RoutingEvent{}
+			err := json.Unmarshal(v, e)
+			if err != nil {
+				return err
+			}
+			events = append(events, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// RoutingEventsSince returns the events first recorded since the time,
+// oldest first.
+func (s *Store) RoutingEventsSince(since time.Time) ([]*models.RoutingEvent, error) {
+	events := []*models.RoutingEvent{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(routingBucket).Cursor()
+		for k, v := c.Seek(timeKey(since, 0)); k != nil; k, v = c.Next() {
+			e := &models.RoutingEvent{}
+			err := json.Unmarshal(v, e)
+			if err != nil {
+				return err
+			}
+			events = append(events, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return events, nil
+}
+
+// routingID is the key of the HTLC of the event, the one compared by
+// RoutingEvent.Equals.
+func routingID(e *models.RoutingEvent) []byte {
+	id := make([]byte, 32)
+	binary.BigEndian.PutUint64(id, e.IncomingChannelId)
+	binary.BigEndian.PutUint64(id[8:], e.IncomingHtlcId)
+	binary.BigEndian.PutUint64(id[16:], e.OutgoingChannelId)
+	binary.BigEndian.PutUint64(id[24:], e.OutgoingHtlcId)
+	return id
+}
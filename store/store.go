@@ -0,0 +1,72 @@
+// Package store keeps the data recorded by lntop across restarts in a
+// bbolt file, like the changes of the routing policies of the channels,
+// the routing events, the samples of the balances of the channels and
+// their tags and notes, the aliases of the nodes or the uptime of the
+// peers. The file is locked by the process having it open.
+package store
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout is how long Open waits for the lock of an other process.
+const openTimeout = time.Second
+
+var (
+	policiesBucket     = []byte("policies")
+	routingBucket      = []byte("routing")
+	routingIndexBucket = []byte("routing_index")
+	balancesBucket     = []byte("balances")
+	notesBucket        = []byte("notes")
+	aliasesBucket      = []byte("aliases")
+	uptimeBucket       = []byte("uptime")
+)
+
+type Store struct {
+	db *bolt.DB
+}
+
+func (s *Store) Close() error {
+	return errors.WithStack(s.db.Close())
+}
+
+// Open opens or creates the store of the path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return nil, errors.Errorf("store %s is used by an other process", path)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{policiesBucket, routingBucket, routingIndexBucket, balancesBucket,
+			notesBucket, aliasesBucket, uptimeBucket} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// timeKey returns a key sorting the records by time, the sequence of
+// the bucket keeps apart the records of the same time.
+func timeKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// PeerUptime is the time a peer was seen online and offline by lntop, from
+// the status of its channels, and the number of times it came back online.
+type PeerUptime struct {
+	Online  time.Duration `json:"online"`
+	Offline time.Duration `json:"offline"`
+	Flaps   int           `json:"flaps"`
+	// Active is the status of the peer at Seen.
+	Active bool      `json:"active"`
+	Seen   time.Time `json:"seen"`
+}
+
+// SetPeerUptimes records the uptime of the peers by pubkey.
+func (s *Store) SetPeerUptimes(uptimes map[string]*PeerUptime) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(uptimeBucket)
+		for pubkey, u := range uptimes {
+			data, err := json.Marshal(u)
+			if err != nil {
+				return err
+			}
+			err = b.Put([]byte(pubkey), data)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+// PeerUptimes returns the uptime of the peers by pubkey.
+func (s *Store) PeerUptimes() (map[string]*PeerUptime, error) {
+	uptimes := map[string]*PeerUptime{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(uptimeBucket).ForEach(func(k, v []byte) error {
+			u := &PeerUptime{}
+			err := json.Unmarshal(v, u)
+			if err != nil {
+				return err
+			}
+			uptimes[string(k)] = u
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return uptimes, nil
+}
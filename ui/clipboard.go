@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"encoding/base64"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// copyToClipboard sets the clipboard of the terminal with an OSC 52
+// sequence, ignored by the terminals without support for it.
+func copyToClipboard(s string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer tty.Close()
+	_, err = tty.WriteString("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a")
+	return errors.WithStack(err)
+}
@@ -4,24 +4,6 @@ import "github.com/gookit/color"
 
 type Color color.Color
 
-var (
-	yellow     = SprintFunc(color.New(color.FgYellow))
-	yellowBold = SprintFunc(color.New(color.FgYellow, color.Bold))
-	green      = SprintFunc(color.New(color.FgGreen))
-	greenBold  = SprintFunc(color.New(color.FgGreen, color.Bold))
-	greenBg    = SprintFunc(color.New(color.FgBlack, color.BgGreen))
-	magentaBg  = SprintFunc(color.New(color.FgBlack, color.BgMagenta))
-	red        = SprintFunc(color.New(color.FgRed))
-	redBold    = SprintFunc(color.New(color.FgRed, color.Bold))
-	cyan       = SprintFunc(color.New(color.FgCyan))
-	cyanBold   = SprintFunc(color.New(color.FgCyan, color.Bold))
-	cyanBg     = SprintFunc(color.New(color.BgCyan, color.FgBlack))
-	white      = SprintFunc(color.New())
-	whiteBold  = SprintFunc(color.New(color.Bold))
-	blackBg    = SprintFunc(color.New(color.BgBlack, color.FgWhite))
-	black      = SprintFunc(color.New(color.FgBlack))
-)
-
 func SprintFunc(c color.Style) func(args ...interface{}) string {
 	return func(args ...interface{}) string {
 		return c.Sprint(args...)
@@ -52,65 +34,70 @@ func Background(o *options) { o.bg = true }
 func Yellow(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bold {
-		return yellowBold
+		return current.Load().yellowBold
 	}
-	return yellow
+	return current.Load().yellow
 }
 
 func Green(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bold {
-		return greenBold
+		return current.Load().greenBold
 	}
 
 	if options.bg {
-		return greenBg
+		return current.Load().greenBg
 	}
 
-	return green
+	return current.Load().green
 }
 
 func Red(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bold {
-		return redBold
+		return current.Load().redBold
 	}
-	return red
+
+	if options.bg {
+		return current.Load().redBg
+	}
+
+	return current.Load().red
 }
 
 func White(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bold {
-		return whiteBold
+		return current.Load().whiteBold
 	}
-	return white
+	return current.Load().white
 }
 
 func Cyan(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bold {
-		return cyanBold
+		return current.Load().cyanBold
 	}
 	if options.bg {
-		return cyanBg
+		return current.Load().cyanBg
 	}
-	return cyan
+	return current.Load().cyan
 }
 
 func Black(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bg {
-		return blackBg
+		return current.Load().keyBg
 	}
-	return black
+	return current.Load().black
 }
 
 func Magenta(opts ...Option) func(a ...interface{}) string {
 	options := newOptions(opts)
 	if options.bg {
-		return magentaBg
+		return current.Load().magentaBg
 	}
-	return magentaBg
+	return current.Load().magentaBg
 }
 
 func HSL256(h, s, l float64, opts ...Option) func(a ...interface{}) string {
@@ -131,3 +118,37 @@ func HSL256(h, s, l float64, opts ...Option) func(a ...interface{}) string {
 		return c.Sprint(a...)
 	}
 }
+
+var names = map[string]color.Color{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// ByName returns the color of the given name, e.g. "red", as a
+// foreground or with the Background option as a background.
+func ByName(name string, opts ...Option) (func(a ...interface{}) string, bool) {
+	fg, ok := names[name]
+	if !ok {
+		return nil, false
+	}
+	options := newOptions(opts)
+	style := color.New(fg)
+	if options.bg {
+		style = color.New(color.FgBlack, fg.ToBg())
+	}
+	if options.bold {
+		style = append(style, color.Bold)
+	}
+	return SprintFunc(style), true
+}
+
+// Strip removes the color codes of s.
+func Strip(s string) string {
+	return color.ClearCode(s)
+}
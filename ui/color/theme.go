@@ -0,0 +1,411 @@
+package color
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/gookit/color"
+	"github.com/pkg/errors"
+)
+
+// Theme is the set of colors of the ui. A color is a name of the 8 colors
+// of the terminal, e.g. "green", a 256-color index, e.g. "208", or a
+// truecolor "#rrggbb", displayed as its nearest 256-color if truecolor is
+// off. An empty color is the default of the terminal.
+type Theme struct {
+	Name string
+	// Text is the color of the text which is not colored.
+	Text    string
+	Yellow  string
+	Green   string
+	Red     string
+	Cyan    string
+	Magenta string
+	// Contrast is the color of the text on the green, cyan and magenta
+	// backgrounds.
+	Contrast string
+	// KeyFg and KeyBg are the colors of the keys in the footers, KeyFg is
+	// also the text on the red background.
+	KeyFg string
+	KeyBg string
+	// Header are the column headers, Footer the footers and Selection
+	// the selected row.
+	HeaderFg    string
+	HeaderBg    string
+	FooterFg    string
+	FooterBg    string
+	SelectionFg string
+	SelectionBg string
+}
+
+// Themes are the built-in themes by name, dark is the default.
+var Themes = map[string]Theme{
+	"dark": {
+		Name:        "dark",
+		Yellow:      "yellow",
+		Green:       "green",
+		Red:         "red",
+		Cyan:        "cyan",
+		Magenta:     "magenta",
+		Contrast:    "black",
+		KeyFg:       "white",
+		KeyBg:       "black",
+		HeaderFg:    "black",
+		HeaderBg:    "green",
+		FooterFg:    "black",
+		FooterBg:    "cyan",
+		SelectionFg: "black",
+		SelectionBg: "cyan",
+	},
+	"light": {
+		Name:        "light",
+		Text:        "235",
+		Yellow:      "130",
+		Green:       "28",
+		Red:         "160",
+		Cyan:        "25",
+		Magenta:     "127",
+		Contrast:    "231",
+		KeyFg:       "231",
+		KeyBg:       "240",
+		HeaderFg:    "231",
+		HeaderBg:    "28",
+		FooterFg:    "235",
+		FooterBg:    "152",
+		SelectionFg: "235",
+		SelectionBg: "153",
+	},
+	"solarized": {
+		Name:        "solarized",
+		Text:        "#839496",
+		Yellow:      "#b58900",
+		Green:       "#859900",
+		Red:         "#dc322f",
+		Cyan:        "#2aa198",
+		Magenta:     "#d33682",
+		Contrast:    "#002b36",
+		KeyFg:       "#fdf6e3",
+		KeyBg:       "#073642",
+		HeaderFg:    "#002b36",
+		HeaderBg:    "#859900",
+		FooterFg:    "#002b36",
+		FooterBg:    "#268bd2",
+		SelectionFg: "#fdf6e3",
+		SelectionBg: "#586e75",
+	},
+	"high-contrast": {
+		Name:        "high-contrast",
+		Text:        "231",
+		Yellow:      "226",
+		Green:       "46",
+		Red:         "196",
+		Cyan:        "51",
+		Magenta:     "201",
+		Contrast:    "16",
+		KeyFg:       "16",
+		KeyBg:       "231",
+		HeaderFg:    "16",
+		HeaderBg:    "226",
+		FooterFg:    "16",
+		FooterBg:    "231",
+		SelectionFg: "16",
+		SelectionBg: "51",
+	},
+}
+
+// ThemeNames returns the names of the built-in themes, dark first.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		if name != "dark" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{"dark"}, names...)
+}
+
+// NewTheme returns the built-in theme of the name, dark if empty, with its
+// colors overridden by the ones of colors keyed by the snake case name of
+// the field, e.g. "header_bg".
+func NewTheme(name string, colors map[string]string) (Theme, error) {
+	if name == "" {
+		name = "dark"
+	}
+	t, ok := Themes[name]
+	if !ok {
+		return t, errors.Errorf("unknown theme %q", name)
+	}
+	slots := t.slots()
+	for key, value := range colors {
+		slot, ok := slots[key]
+		if !ok {
+			return t, errors.Errorf("unknown color %q of theme", key)
+		}
+		_, err := parseSpec(value)
+		if err != nil {
+			return t, err
+		}
+		*slot = value
+	}
+	return t, nil
+}
+
+func (t *Theme) slots() map[string]*string {
+	return map[string]*string{
+		"text":         &t.Text,
+		"yellow":       &t.Yellow,
+		"green":        &t.Green,
+		"red":          &t.Red,
+		"cyan":         &t.Cyan,
+		"magenta":      &t.Magenta,
+		"contrast":     &t.Contrast,
+		"key_fg":       &t.KeyFg,
+		"key_bg":       &t.KeyBg,
+		"header_fg":    &t.HeaderFg,
+		"header_bg":    &t.HeaderBg,
+		"footer_fg":    &t.FooterFg,
+		"footer_bg":    &t.FooterBg,
+		"selection_fg": &t.SelectionFg,
+		"selection_bg": &t.SelectionBg,
+	}
+}
+
+// Attributes are the colors of the theme set on the gocui views.
+type Attributes struct {
+	HeaderFg    gocui.Attribute
+	HeaderBg    gocui.Attribute
+	FooterFg    gocui.Attribute
+	FooterBg    gocui.Attribute
+	SelectionFg gocui.Attribute
+	SelectionBg gocui.Attribute
+	// Frame is the frame of an input, Valid the one of a valid value and
+	// Invalid the one of a value in error.
+	Frame   gocui.Attribute
+	Valid   gocui.Attribute
+	Invalid gocui.Attribute
+}
+
+// palette is the theme applied, its text styles and gocui attributes.
+type palette struct {
+	theme     Theme
+	truecolor bool
+	attrs     Attributes
+
+	yellow, yellowBold             func(a ...interface{}) string
+	green, greenBold, greenBg      func(a ...interface{}) string
+	magentaBg                      func(a ...interface{}) string
+	red, redBold, redBg            func(a ...interface{}) string
+	cyan, cyanBold, cyanBg         func(a ...interface{}) string
+	white, whiteBold, black, keyBg func(a ...interface{}) string
+}
+
+var current atomic.Pointer[palette]
+
+func init() {
+	SetTheme(Themes["dark"], false)
+}
+
+// SetTheme applies the theme to the text colored and to the views set from
+// then on. The truecolor colors are displayed as is if truecolor, the ui
+// must then use the OutputMode.
+func SetTheme(t Theme, truecolor bool) {
+	p := &palette{theme: t, truecolor: truecolor}
+	spec := func(s string) spec {
+		// the colors are checked by NewTheme, an invalid one is the
+		// default.
+		c, _ := parseSpec(s)
+		return c
+	}
+	style := func(fg, bg string, bold bool) func(a ...interface{}) string {
+		code := styleCode(spec(fg), spec(bg), bold, truecolor)
+		return func(a ...interface{}) string {
+			return color.RenderCode(code, a...)
+		}
+	}
+	p.yellow = style(t.Yellow, "", false)
+	p.yellowBold = style(t.Yellow, "", true)
+	p.green = style(t.Green, "", false)
+	p.greenBold = style(t.Green, "", true)
+	p.greenBg = style(t.Contrast, t.Green, false)
+	p.magentaBg = style(t.Contrast, t.Magenta, false)
+	p.red = style(t.Red, "", false)
+	p.redBold = style(t.Red, "", true)
+	p.redBg = style(t.KeyFg, t.Red, true)
+	p.cyan = style(t.Cyan, "", false)
+	p.cyanBold = style(t.Cyan, "", true)
+	p.cyanBg = style(t.Contrast, t.Cyan, false)
+	p.white = style(t.Text, "", false)
+	p.whiteBold = style(t.Text, "", true)
+	p.keyBg = style(t.KeyFg, t.KeyBg, false)
+	p.black = style(t.Contrast, "", false)
+
+	attr := func(s string) gocui.Attribute {
+		return spec(s).attribute(truecolor)
+	}
+	p.attrs = Attributes{
+		HeaderFg:    attr(t.HeaderFg),
+		HeaderBg:    attr(t.HeaderBg),
+		FooterFg:    attr(t.FooterFg),
+		FooterBg:    attr(t.FooterBg),
+		SelectionFg: attr(t.SelectionFg) | gocui.AttrDim,
+		SelectionBg: attr(t.SelectionBg),
+		Frame:       attr(t.Text),
+		Valid:       attr(t.Green),
+		Invalid:     attr(t.Red),
+	}
+	current.Store(p)
+}
+
+// CurrentTheme returns the theme applied.
+func CurrentTheme() Theme {
+	return current.Load().theme
+}
+
+// NextTheme applies the built-in theme following the current one and
+// returns it, the overridden colors are dropped.
+func NextTheme() Theme {
+	p := current.Load()
+	names := ThemeNames()
+	next := names[0]
+	for i, name := range names {
+		if name == p.theme.Name && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	SetTheme(Themes[next], p.truecolor)
+	return Themes[next]
+}
+
+// Attrs returns the gocui colors of the current theme.
+func Attrs() Attributes {
+	return current.Load().attrs
+}
+
+// OutputMode returns the gocui output mode displaying the colors of the
+// themes.
+func OutputMode() gocui.OutputMode {
+	if current.Load().truecolor {
+		return gocui.OutputTrue
+	}
+	return gocui.Output256
+}
+
+type specKind int
+
+const (
+	specDefault specKind = iota
+	specBasic
+	spec256
+	specRGB
+)
+
+// spec is a parsed color of a theme.
+type spec struct {
+	kind    specKind
+	index   uint8
+	r, g, b uint8
+}
+
+var basics = map[string]uint8{
+	"black":   0,
+	"red":     1,
+	"green":   2,
+	"yellow":  3,
+	"blue":    4,
+	"magenta": 5,
+	"cyan":    6,
+	"white":   7,
+}
+
+func parseSpec(s string) (spec, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "default" {
+		return spec{}, nil
+	}
+	if i, ok := basics[s]; ok {
+		return spec{kind: specBasic, index: i}, nil
+	}
+	if strings.HasPrefix(s, "#") {
+		if len(s) != 7 {
+			return spec{}, errors.Errorf("invalid color %q, expected #rrggbb", s)
+		}
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return spec{}, errors.Errorf("invalid color %q, expected #rrggbb", s)
+		}
+		return spec{kind: specRGB, r: uint8(v >> 16), g: uint8(v >> 8), b: uint8(v)}, nil
+	}
+	i, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return spec{}, errors.Errorf("invalid color %q, expected a name, 0-255 or #rrggbb", s)
+	}
+	return spec{kind: spec256, index: uint8(i)}, nil
+}
+
+// to256 returns the color as a 256-color index.
+func (s spec) to256() uint8 {
+	if s.kind == specRGB {
+		return color.RgbTo256(s.r, s.g, s.b)
+	}
+	return s.index
+}
+
+// rgb returns the color as red, green and blue.
+func (s spec) rgb() (uint8, uint8, uint8) {
+	if s.kind == specRGB {
+		return s.r, s.g, s.b
+	}
+	c := color.C256ToRgb(s.index)
+	return c[0], c[1], c[2]
+}
+
+func (s spec) attribute(truecolor bool) gocui.Attribute {
+	switch {
+	case s.kind == specDefault:
+		return gocui.ColorDefault
+	case s.kind == specRGB && truecolor:
+		return gocui.NewRGBColor(int32(s.r), int32(s.g), int32(s.b))
+	}
+	return gocui.Get256Color(int32(s.to256()))
+}
+
+// styleCode returns the escape code of the colors. gocui parses a code of
+// the 8 colors, or one of 256-colors or of truecolors starting with the
+// background, so the colors of a code are all of the same kind.
+func styleCode(fg, bg spec, bold, truecolor bool) string {
+	var codes []string
+	switch {
+	case truecolor && (fg.kind == specRGB || bg.kind == specRGB):
+		if bg.kind != specDefault {
+			r, g, b := bg.rgb()
+			codes = append(codes, fmt.Sprintf("48;2;%d;%d;%d", r, g, b))
+		}
+		if fg.kind != specDefault {
+			r, g, b := fg.rgb()
+			codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+		}
+	case fg.kind > specBasic || bg.kind > specBasic:
+		if bg.kind != specDefault {
+			codes = append(codes, fmt.Sprintf("48;5;%d", bg.to256()))
+		}
+		if fg.kind != specDefault {
+			codes = append(codes, fmt.Sprintf("38;5;%d", fg.to256()))
+		}
+	default:
+		if fg.kind != specDefault {
+			codes = append(codes, strconv.Itoa(30+int(fg.index)))
+		}
+		if bg.kind != specDefault {
+			codes = append(codes, strconv.Itoa(40+int(bg.index)))
+		}
+	}
+	if bold {
+		codes = append(codes, "1")
+	}
+	return strings.Join(codes, ";")
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/control"
+	"github.com/edouardparis/lntop/export"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/cursor"
+	"github.com/edouardparis/lntop/ui/models"
+	"github.com/edouardparis/lntop/ui/views"
+)
+
+const controlFilter = "control"
+
+type viewParams struct {
+	Name string `json:"name"`
+}
+
+type filterParams struct {
+	Query string `json:"query"`
+}
+
+type exportParams struct {
+	Path string `json:"path"`
+}
+
+// registerControl registers the handlers of the control server
+// driving the running ui.
+func (c *controller) registerControl(ctx context.Context, g *gocui.Gui, s *control.Server) {
+	s.Handle("view", func(raw json.RawMessage) (interface{}, error) {
+		params := viewParams{}
+		err := unmarshalParams(raw, &params)
+		if err != nil {
+			return nil, err
+		}
+		return nil, c.update(g, func(g *gocui.Gui) error {
+			return c.setMain(g, params.Name)
+		})
+	})
+
+	s.Handle("filter", func(raw json.RawMessage) (interface{}, error) {
+		params := filterParams{}
+		err := unmarshalParams(raw, &params)
+		if err != nil {
+			return nil, err
+		}
+		return nil, c.update(g, func(g *gocui.Gui) error {
+			c.models.Channels.SetFilter(controlFilter, channelsQuery(params.Query))
+			if c.views.Main.Name() == views.CHANNELS {
+				return cursor.Home(c.views.Channels)
+			}
+			return nil
+		})
+	})
+
+	s.Handle("export", func(raw json.RawMessage) (interface{}, error) {
+		params := exportParams{}
+		err := unmarshalParams(raw, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		channels := c.models.Channels.Filtered()
+		if params.Path == "" {
+			out := make([]export.Channel, len(channels))
+			for i := range channels {
+				out[i] = export.NewChannel(channels[i])
+			}
+			return out, nil
+		}
+
+		f, err := os.OpenFile(params.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer f.Close()
+		return nil, export.Channels(f, channels)
+	})
+
+	s.Handle("refresh", func(json.RawMessage) (interface{}, error) {
+		err := c.SetModels(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return nil, c.update(g, func(*gocui.Gui) error { return nil })
+	})
+}
+
+// update runs fn in the main loop of the ui and waits for its result.
+func (c *controller) update(g *gocui.Gui, fn func(*gocui.Gui) error) error {
+	done := make(chan error, 1)
+	g.Update(func(g *gocui.Gui) error {
+		done <- fn(g)
+		return nil
+	})
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		return errors.New("ui did not respond")
+	}
+}
+
+// setMain replaces the main view, the layout displays it
+// at the next redraw.
+func (c *controller) setMain(g *gocui.Gui, name string) error {
+	view := c.views.ByName(name)
+	if view == nil {
+		return errors.Errorf("unknown view %q", name)
+	}
+
+	if c.views.Main.Name() == name {
+		return nil
+	}
+
+	err := c.views.Main.Delete(g)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	c.views.Main = view
+	return nil
+}
+
+// channelsQuery returns a filter matching the channels whose alias or
+// remote pubkey contains the query, nil if the query is empty.
+func channelsQuery(query string) models.ChannelsFilter {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	return func(ch *netmodels.Channel) bool {
+		if strings.Contains(ch.RemotePubKey, query) {
+			return true
+		}
+		if ch.Node == nil {
+			return false
+		}
+		return strings.Contains(strings.ToLower(ch.Node.Alias), query) ||
+			strings.Contains(strings.ToLower(ch.Node.ForcedAlias), query)
+	}
+}
+
+func unmarshalParams(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return errors.WithStack(json.Unmarshal(raw, v))
+}
@@ -2,13 +2,22 @@ package ui
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
 
-	"github.com/edouardparis/lntop/app"
+	"github.com/edouardparis/lntop/config"
 	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/export"
 	"github.com/edouardparis/lntop/logging"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/plugin"
+	"github.com/edouardparis/lntop/qr"
+	"github.com/edouardparis/lntop/ui/color"
 	"github.com/edouardparis/lntop/ui/cursor"
 	"github.com/edouardparis/lntop/ui/models"
 	"github.com/edouardparis/lntop/ui/views"
@@ -16,8 +25,47 @@ import (
 
 type controller struct {
 	logger logging.Logger
-	models *models.Models
-	views  *views.Views
+	// network, models and views are the ones of the node displayed.
+	network *config.Network
+	models  *models.Models
+	views   *views.Views
+	nodes   []*node
+	current int
+	touch   func()
+	// export is the config of the views exported, the one of the first
+	// node.
+	export config.Export
+	// config is the path of the config file the columns are saved to.
+	config string
+	// filter is the expression of the channels of the config, applied
+	// again when it changes.
+	filter string
+	// missionExport is the file of the last export of mission control,
+	// the default file of the import.
+	missionExport string
+	// press is the left button pressed on the column headers.
+	press *mousePress
+	// sorted is the column of the last sort toggled.
+	sorted toggledSort
+	// keys is the keymap of the config of the first node.
+	keys config.Keys
+	// readOnly disables the actions changing the nodes.
+	readOnly bool
+}
+
+// toggledSort is the column of a view sorted by ToggleOrder with order.
+type toggledSort struct {
+	view  string
+	x     int
+	order models.Order
+}
+
+// node is the state of a node of the ui.
+type node struct {
+	name    string
+	network *config.Network
+	models  *models.Models
+	views   *views.Views
 }
 
 func (c *controller) layout(g *gocui.Gui) error {
@@ -90,36 +138,131 @@ func (c *controller) cursorPageUp(g *gocui.Gui, v *gocui.View) error {
 }
 
 func (c *controller) SetModels(ctx context.Context) error {
-	err := c.models.RefreshInfo(ctx)
+	return c.refreshModels(ctx, c.models)
+}
+
+func (c *controller) refreshModels(ctx context.Context, m *models.Models) error {
+	err := m.RefreshInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshWalletBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshChannelsBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshTransactions(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshForwardingHistory(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshChannelStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.RefreshClosedChannels(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = c.models.RefreshWalletBalance(ctx)
+	err = m.RefreshPayments(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = c.models.RefreshChannelsBalance(ctx)
+	err = m.RefreshInvoices(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = c.models.RefreshTransactions(ctx)
+	err = m.RefreshUTXOs(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = c.models.RefreshForwardingHistory(ctx)
+	// the sweeper is not queried by nodes built without walletrpc.
+	err = m.RefreshPendingSweeps(ctx)
+	if err != nil {
+		c.logger.Debug("cannot list pending sweeps", logging.Error(err))
+	}
+
+	// the watchtower client is not active on every node.
+	err = m.RefreshTowers(ctx)
+	if err != nil {
+		c.logger.Debug("cannot list towers", logging.Error(err))
+	}
+
+	// only lightningd supports the offers.
+	err = m.RefreshOffers(ctx)
+	if err != nil {
+		c.logger.Debug("cannot list offers", logging.Error(err))
+	}
+
+	// only lnd exports its channel backup.
+	err = m.RefreshChannelBackup(ctx)
+	if err != nil {
+		c.logger.Debug("cannot export the channel backup", logging.Error(err))
+	}
+
+	err = m.RefreshFirewall(ctx)
 	if err != nil {
 		return err
 	}
 
-	return c.models.RefreshChannels(ctx)
+	return m.RefreshChannels(ctx)
+}
+
+// refreshTowers refreshes the towers, the error is only logged as the
+// watchtower client is not active on every node.
+func (c *controller) refreshTowers(m *models.Models) func(context.Context) error {
+	return func(ctx context.Context) error {
+		err := m.RefreshTowers(ctx)
+		if err != nil {
+			c.logger.Debug("cannot list towers", logging.Error(err))
+		}
+		return nil
+	}
+}
+
+// refreshOffers refreshes the offers, the error is only logged as only
+// lightningd supports them.
+func (c *controller) refreshOffers(m *models.Models) func(context.Context) error {
+	return func(ctx context.Context) error {
+		err := m.RefreshOffers(ctx)
+		if err != nil {
+			c.logger.Debug("cannot list offers", logging.Error(err))
+		}
+		return nil
+	}
 }
 
-func (c *controller) Listen(ctx context.Context, g *gocui.Gui, sub chan *events.Event) {
+// Listen refreshes the models at the events of sub.
+func (c *controller) Listen(ctx context.Context, g *gocui.Gui, m *models.Models, sub chan *events.Event) {
 	c.logger.Debug("Listening...")
+	for event := range sub {
+		c.handle(ctx, g, m, event)
+	}
+}
+
+// handle refreshes the models at the event.
+func (c *controller) handle(ctx context.Context, g *gocui.Gui, m *models.Models, event *events.Event) {
 	refresh := func(fn ...func(context.Context) error) {
 		for i := range fn {
 			err := fn[i](ctx)
@@ -130,80 +273,445 @@ func (c *controller) Listen(ctx context.Context, g *gocui.Gui, sub chan *events.
 		g.Update(func(*gocui.Gui) error { return nil })
 	}
 
-	for event := range sub {
-		c.logger.Debug("event received", logging.String("type", event.Type))
-		switch event.Type {
-		case events.TransactionCreated:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshWalletBalance,
-				c.models.RefreshTransactions,
-			)
-		case events.BlockReceived:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshTransactions,
-			)
-		case events.WalletBalanceUpdated:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshWalletBalance,
-				c.models.RefreshTransactions,
-				c.models.RefreshForwardingHistory,
-			)
-		case events.ChannelBalanceUpdated:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshChannelsBalance,
-				c.models.RefreshChannels,
-				c.models.RefreshForwardingHistory,
-			)
-		case events.ChannelPending:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshChannelsBalance,
-				c.models.RefreshChannels,
-			)
-		case events.ChannelActive:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshChannelsBalance,
-				c.models.RefreshChannels,
-			)
-		case events.ChannelInactive:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshChannelsBalance,
-				c.models.RefreshChannels,
-			)
-		case events.InvoiceSettled:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshChannelsBalance,
-				c.models.RefreshChannels,
-				c.models.RefreshForwardingHistory,
-			)
-		case events.PeerUpdated:
-			refresh(
-				c.models.RefreshInfo,
-				c.models.RefreshForwardingHistory,
-			)
-
-		case events.RoutingEventUpdated:
-			refresh(
-				c.models.RefreshRouting(event.Data),
-			)
-		case events.GraphUpdated:
-			refresh(c.models.RefreshPolicies(event.Data))
+	c.logger.Debug("event received", logging.String("type", event.Type))
+	switch event.Type {
+	case events.TransactionCreated:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshWalletBalance,
+			m.RefreshTransactions,
+			m.RefreshPendingChannels,
+			m.RefreshUTXOs,
+		)
+	case events.BlockReceived:
+		// the countdowns of the pending channels are refreshed at each
+		// block.
+		refresh(
+			m.RefreshInfo,
+			m.RefreshTransactions,
+			m.RefreshChannels,
+			m.RefreshPendingSweeps,
+			m.RefreshUTXOs,
+			c.refreshTowers(m),
+			c.refreshOffers(m),
+		)
+	case events.WalletBalanceUpdated:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshWalletBalance,
+			m.RefreshTransactions,
+			m.RefreshForwardingHistory,
+			m.RefreshChannelStats,
+		)
+	case events.ChannelBalanceUpdated:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+			m.RefreshForwardingHistory,
+			m.RefreshChannelStats,
+			c.refreshTowers(m),
+		)
+	case events.ChannelPending:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+		)
+	case events.ChannelActive:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.PatchChannel(event.Data),
+		)
+	case events.ChannelInactive:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.PatchChannel(event.Data),
+		)
+	case events.ChannelClosing:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+			m.RefreshClosing(event.Data),
+		)
+	case events.ChannelClosed:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.PatchChannel(event.Data),
+			m.RefreshClosedChannels,
+		)
+	case events.ChannelDisabledByPeer:
+		refresh(m.NotifyChannelDisabled(event.Data))
+	case events.ChannelBackupUpdated:
+		refresh(m.SetChannelBackup(event.Data))
+	case events.InvoiceCreated:
+		refresh(m.RefreshInvoices)
+	case events.InvoiceSettled:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+			m.RefreshForwardingHistory,
+			m.RefreshChannelStats,
+			m.RefreshInvoices,
+			c.refreshOffers(m),
+			m.NotifyInvoice(event.Data),
+		)
+	case events.PaymentUpdated:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+			m.RefreshPayments,
+		)
+	case events.KeysendUpdated:
+		if p, ok := event.Payment(); ok && p.Status == netmodels.PaymentInFlight {
+			refresh(m.RefreshPayments)
+			break
+		}
+		refresh(
+			m.RefreshInfo,
+			m.RefreshChannelsBalance,
+			m.RefreshChannels,
+			m.RefreshPayments,
+			m.NotifyKeysend(event.Data),
+		)
+	case events.PeerUpdated:
+		refresh(
+			m.RefreshInfo,
+			m.RefreshForwardingHistory,
+			m.RefreshChannelStats,
+			m.RefreshPeers,
+		)
+	case events.PeerTrafficUpdated:
+		refresh(m.RefreshPeers)
+
+	case events.RoutingEventUpdated:
+		refresh(m.RefreshRouting(event.Data))
+		// a forward adds or removes the HTLCs in flight of its channels,
+		// the channels are listed once for the routing events of a burst
+		// of forwards.
+		m.ScheduleChannelHTLCs(ctx, htlcsDelay, func(err error) {
+			if err != nil {
+				c.logger.Error("failed", logging.Error(err))
+			}
+			g.Update(func(*gocui.Gui) error { return nil })
+		})
+	case events.GraphUpdated:
+		refresh(m.RefreshPolicies(event.Data))
+	case events.AlertRaised, events.AlertResolved:
+		refresh(m.RefreshAlerts(event.Data))
+	case events.HTLCIntercepted:
+		refresh(m.RefreshFirewall)
+	case events.NodeStateChanged:
+		state, _ := event.NodeState()
+		if state.Ready() {
+			// the models are stale, refreshed as at start.
+			refresh(func(ctx context.Context) error {
+				return c.refreshModels(ctx, m)
+			}, m.RefreshNodeState(state))
+		} else {
+			refresh(m.RefreshNodeState(state))
+		}
+	case events.ConnectionChanged:
+		connection, _ := event.Connection()
+		refresh(m.RefreshConnection(connection))
+	case events.ConfigReloaded:
+		cfg, ok := event.Config()
+		if ok {
+			g.Update(func(g *gocui.Gui) error {
+				return c.reloadConfig(g, cfg)
+			})
+		}
+	}
+}
+
+// reloadConfig rebuilds the views of the nodes with the views of the
+// config and sets their notifications, the data collected is kept. The filter of the channels is
+// replaced only if the one of the config changed.
+func (c *controller) reloadConfig(g *gocui.Gui, cfg *config.Config) error {
+	if filter := channelsFilter(cfg.Views); filter != c.filter {
+		c.filter = filter
+		for i := range c.nodes {
+			err := c.nodes[i].models.Channels.SetFilterExpr(filter)
+			if err != nil {
+				c.logger.Error("invalid filter of the channels", logging.Error(err))
+				break
+			}
+		}
+	}
+	for i := range c.nodes {
+		err := c.nodes[i].models.Notifications.SetConfig(cfg.Notifications)
+		if err != nil {
+			c.logger.Error("invalid notifications", logging.Error(err))
+		}
+		gui := g
+		if i != c.current {
+			gui = nil
+		}
+		err = c.nodes[i].views.SetConfig(gui, cfg.Views)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPlugins renders the plugins at their interval until the context
+// is done.
+func (c *controller) runPlugins(ctx context.Context, g *gocui.Gui) {
+	for _, p := range c.models.Plugins.List() {
+		go func(p *plugin.Plugin) {
+			ticker := time.NewTicker(p.Interval())
+			defer ticker.Stop()
+			for {
+				err := c.models.RefreshPlugin(p)(ctx)
+				if err != nil {
+					c.logger.Error("plugin failed",
+						logging.String("plugin", p.Name()), logging.Error(err))
+				} else {
+					g.Update(func(*gocui.Gui) error { return nil })
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(p)
+	}
+}
+
+// runPrice refreshes the price of the fiat columns at the interval of its
+// provider until the context is done.
+func (c *controller) runPrice(ctx context.Context, g *gocui.Gui) {
+	if !c.models.Price.Enabled() {
+		return
+	}
+	m := c.models
+	go func() {
+		ticker := time.NewTicker(m.Price.Interval())
+		defer ticker.Stop()
+		for {
+			err := m.RefreshPrice(ctx)
+			if err != nil {
+				c.logger.Error("cannot refresh price", logging.Error(err))
+			} else {
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runPeerData refreshes the metadata of the peers at the interval of its
+// source until the context is done.
+func (c *controller) runPeerData(ctx context.Context, g *gocui.Gui) {
+	if !c.models.PeerData.Enabled() {
+		return
+	}
+	m := c.models
+	go func() {
+		ticker := time.NewTicker(m.PeerData.Interval())
+		defer ticker.Stop()
+		for {
+			err := m.RefreshPeerData(ctx)
+			if err != nil {
+				c.logger.Error("cannot refresh peer data", logging.Error(err))
+			}
+			g.Update(func(*gocui.Gui) error { return nil })
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// routingChartRefresh is the interval between two reads of the routing
+// events of the chart from the store.
+const routingChartRefresh = 30 * time.Second
+
+// runRoutingChart reads the routing events of the chart from the store
+// until the context is done.
+func (c *controller) runRoutingChart(ctx context.Context, g *gocui.Gui) {
+	m := c.models
+	if !m.RoutingChart.Enabled() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(routingChartRefresh)
+		defer ticker.Stop()
+		for {
+			err := m.RefreshRoutingChart()
+			if err != nil {
+				c.logger.Error("cannot read routing chart", logging.Error(err))
+			} else {
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
 		}
+	}()
+}
+
+// runBalances reads the samples of the balances from the store at the
+// interval of the sampling until the context is done.
+func (c *controller) runBalances(ctx context.Context, g *gocui.Gui) {
+	m := c.models
+	interval := m.BalanceInterval()
+	if interval == 0 {
+		return
 	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			err := m.RefreshBalances()
+			if err != nil {
+				c.logger.Error("cannot read balances", logging.Error(err))
+			} else {
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// channelInfoRetry is the interval between two checks of the channels
+// whose info is to be fetched, once none is left or a fetch failed.
+const channelInfoRetry = time.Second
+
+// runChannelInfo fetches in the background the info and the nodes of the
+// channels of the nodes left by their refresh, a page at a time so the
+// rows fill in as it goes, until the context is done.
+func (c *controller) runChannelInfo(ctx context.Context, g *gocui.Gui) {
+	go func() {
+		ticker := time.NewTicker(channelInfoRetry)
+		defer ticker.Stop()
+		for {
+			left := false
+			for i := range c.nodes {
+				m := c.nodes[i].models
+				if m.Channels.StaleLen() == 0 {
+					continue
+				}
+				n, err := m.FetchChannelInfo(ctx)
+				if err != nil {
+					c.logger.Error("cannot fetch channel info", logging.Error(err))
+					continue
+				}
+				g.Update(func(*gocui.Gui) error { return nil })
+				left = left || n > 0
+			}
+			if left {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// htlcsDelay is the delay of the refresh of the HTLCs in flight after a
+// routing event.
+const htlcsDelay = time.Second
+
+// runFeeRates estimates the fee rates of the nodes at the interval of the
+// fee source until the context is done.
+func (c *controller) runFeeRates(ctx context.Context, g *gocui.Gui) {
+	go func() {
+		ticker := time.NewTicker(c.models.FeeRates.Interval())
+		defer ticker.Stop()
+		for {
+			for i := range c.nodes {
+				// only lnd, lightningd and the demo estimate the fees
+				// without an api.
+				err := c.nodes[i].models.RefreshFeeRates(ctx)
+				if err != nil {
+					c.logger.Debug("cannot estimate fee rates", logging.Error(err))
+					continue
+				}
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runNotifications redraws the ui every second while notifications are
+// displayed, so they disappear once they expire, until the context is
+// done.
+func (c *controller) runNotifications(ctx context.Context, g *gocui.Gui) {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		shown := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				active := false
+				for i := range c.nodes {
+					if len(c.nodes[i].models.Notifications.Active(now)) > 0 {
+						active = true
+					}
+				}
+				if active || shown {
+					g.Update(func(*gocui.Gui) error { return nil })
+				}
+				shown = active
+			}
+		}
+	}()
 }
 
 func (c *controller) Menu(g *gocui.Gui, v *gocui.View) error {
 	maxX, maxY := g.Size()
 
 	if v.Name() != c.views.Menu.Name() {
-		err := c.views.Menu.Set(g, 0, 6, 10, maxY)
+		c.views.HideDetails()
+		err := c.views.Channel.Delete(g)
+		if err != nil {
+			return err
+		}
+		err = c.views.Transaction.Delete(g)
+		if err != nil {
+			return err
+		}
+
+		err = c.views.Menu.Set(g, 0, 6, 10, maxY)
 		if err != nil {
 			return err
 		}
@@ -239,15 +747,56 @@ func (c *controller) Order(order models.Order) func(*gocui.Gui, *gocui.View) err
 		switch view.Name() {
 		case views.CHANNELS:
 			c.views.Channels.Sort("", order)
+		case views.PENDING:
+			c.views.Pending.Sort("", order)
 		case views.TRANSACTIONS:
 			c.views.Transactions.Sort("", order)
 		case views.FWDINGHIST:
 			c.views.FwdingHist.Sort("", order)
+		case views.PEERS:
+			c.views.Peers.Sort("", order)
+		case views.CLOSED:
+			c.views.Closed.Sort("", order)
+		case views.SWEEPS:
+			c.views.Sweeps.Sort("", order)
+		case views.PAYMENTS:
+			c.views.Payments.Sort("", order)
+		case views.INVOICES:
+			c.views.Invoices.Sort("", order)
+		case views.UTXOS:
+			c.views.UTXOs.Sort("", order)
+		case views.TOWERS:
+			c.views.Towers.Sort("", order)
+		case views.OFFERS:
+			c.views.Offers.Sort("", order)
+		case views.MISSIONCONTROL:
+			c.views.MissionControl.Sort("", order)
+		case views.GRAPH:
+			c.views.Graph.Sort("", order)
+		case views.FIREWALL:
+			c.views.Firewall.Sort("", order)
 		}
 		return nil
 	}
 }
 
+// ToggleOrder sorts the view by the current column, ascending then
+// reversed at each toggle of the same column.
+func (c *controller) ToggleOrder(g *gocui.Gui, v *gocui.View) error {
+	view := c.views.Get(v)
+	if view == nil {
+		return nil
+	}
+	ox, _ := view.Origin()
+	cx, _ := view.Cursor()
+	order := models.Asc
+	if c.sorted.view == view.Name() && c.sorted.x == ox+cx && c.sorted.order == models.Asc {
+		order = models.Desc
+	}
+	c.sorted = toggledSort{view: view.Name(), x: ox + cx, order: order}
+	return c.Order(order)(g, v)
+}
+
 func (c *controller) OnEnter(g *gocui.Gui, v *gocui.View) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
@@ -264,12 +813,22 @@ func (c *controller) OnEnter(g *gocui.Gui, v *gocui.View) error {
 		index := c.views.Channels.Index()
 		c.models.Channels.SetCurrent(index)
 		c.models.RefreshCurrentNode(ctx)
-		c.views.Main = c.views.Channel
-		return ToggleView(g, view, c.views.Channel)
+		c.views.Channel.Show()
+		return nil
 
 	case views.CHANNEL:
-		c.views.Main = c.views.Channels
-		return ToggleView(g, view, c.views.Channels)
+		c.views.Channel.Hide()
+		return nil
+
+	case views.PENDING:
+		pending := c.models.PendingChannels.Get(c.views.Pending.Index())
+		if pending == nil {
+			return nil
+		}
+		c.models.Channels.SetCurrentChannel(pending.Channel)
+		c.models.RefreshCurrentNode(ctx)
+		c.views.Channel.Show()
+		return nil
 
 	case views.MENU:
 		current := c.views.Menu.Current()
@@ -277,61 +836,76 @@ func (c *controller) OnEnter(g *gocui.Gui, v *gocui.View) error {
 			return nil
 		}
 
-		switch current {
-		case views.TRANSACTIONS:
-			err := c.views.Main.Delete(g)
-			if err != nil {
-				return err
-			}
-
-			c.views.Main = c.views.Transactions
-			err = c.views.Transactions.Set(g, 11, 6, maxX-1, maxY)
-			if err != nil {
-				return err
-			}
-		case views.CHANNELS:
-			err := c.views.Main.Delete(g)
-			if err != nil {
-				return err
-			}
+		main := c.views.ByName(current)
+		if main == nil {
+			return nil
+		}
 
-			c.views.Main = c.views.Channels
-			err = c.views.Channels.Set(g, 11, 6, maxX-1, maxY)
-			if err != nil {
-				return err
-			}
-		case views.ROUTING:
-			err := c.views.Main.Delete(g)
-			if err != nil {
-				return err
-			}
+		err := c.views.Main.Delete(g)
+		if err != nil {
+			return err
+		}
 
-			c.views.Main = c.views.Routing
-			err = c.views.Routing.Set(g, 11, 6, maxX-1, maxY)
-			if err != nil {
-				return err
-			}
-		case views.FWDINGHIST:
-			err := c.views.Main.Delete(g)
-			if err != nil {
-				return err
-			}
-			c.views.Main = c.views.FwdingHist
-			err = c.views.FwdingHist.Set(g, 11, 6, maxX-1, maxY)
-			if err != nil {
-				return err
-			}
+		c.views.Main = main
+		err = main.Set(g, 11, 6, maxX-1, maxY)
+		if err != nil {
+			return err
+		}
+		if current == views.MISSIONCONTROL {
+			c.refreshMissionControl(g)
+		}
+		if current == views.GRAPH {
+			c.refreshGraph(g)
 		}
 
 	case views.TRANSACTIONS:
 		index := c.views.Transactions.Index()
 		c.models.Transactions.SetCurrent(index)
-		c.views.Main = c.views.Transaction
-		return ToggleView(g, view, c.views.Transaction)
+		c.views.Transaction.Show()
+		return nil
 
 	case views.TRANSACTION:
-		c.views.Main = c.views.Transactions
-		return ToggleView(g, view, c.views.Transactions)
+		c.views.Transaction.Hide()
+		return nil
+
+	case views.GRAPH:
+		node := c.models.Graph.Get(c.views.Graph.Index())
+		if node == nil {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := c.models.RefreshGraphNode(ctx, node.PubKey)
+		if err != nil {
+			c.logger.Error("cannot get the node", logging.Error(err))
+			c.views.Graph.SetMessage("cannot get the node: " + err.Error())
+			return nil
+		}
+		c.views.Node.Show()
+		return nil
+
+	case views.NODE:
+		c.views.Node.Hide()
+		return nil
+
+	case views.INVOICES:
+		invoice := c.models.Invoices.Get(c.views.Invoices.Index())
+		if invoice == nil || invoice.PaymentRequest == "" {
+			return nil
+		}
+		return c.views.QRCode.Show("invoice", qr.Invoice(invoice.PaymentRequest))
+
+	case views.OFFERS:
+		offer := c.models.Offers.Get(c.views.Offers.Index())
+		if offer == nil {
+			return nil
+		}
+		c.views.Offer.Show(offer)
+		return nil
+
+	case views.OFFER:
+		c.views.Offer.Hide()
+		return nil
 	}
 	return nil
 }
@@ -346,27 +920,1757 @@ func (c *controller) NodeInfo(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
-func ToggleView(g *gocui.Gui, v1, v2 views.View) error {
-	maxX, maxY := g.Size()
-	err := v1.Delete(g)
+// TogglePrivate hides the private channels of the channels view, or
+// shows them again.
+func (c *controller) TogglePrivate(g *gocui.Gui, v *gocui.View) error {
+	c.models.Channels.TogglePrivate()
+	return cursor.Home(c.views.Channels)
+}
+
+// ExportChannels writes the channels view, with its columns and its sort
+// order, to a file of the export directory named after the time.
+func (c *controller) ExportChannels(g *gocui.Gui, v *gocui.View) error {
+	name := fmt.Sprintf("channels-%s.%s", time.Now().Format("20060102-150405"), c.export.Format)
+	path := filepath.Join(c.export.Dir, name)
+	header, rows := c.views.Channels.Table()
+	err := exportTable(path, c.export.Format, header, rows)
 	if err != nil {
-		return err
+		c.logger.Error("cannot export channels", logging.Error(err))
+		c.views.Channels.SetExported("cannot export: " + err.Error())
+		return nil
 	}
+	c.views.Channels.SetExported(fmt.Sprintf("%d channels exported to %s", len(rows), path))
+	return nil
+}
 
-	err = v2.Set(g, 0, 6, maxX-1, maxY)
+func exportTable(path, format string, header []string, rows [][]string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = export.Table(f, format, header, rows)
 	if err != nil {
+		f.Close()
 		return err
 	}
+	return errors.WithStack(f.Close())
+}
+
+// NextClosedRange selects the next time range of the closed channels.
+func (c *controller) NextClosedRange(g *gocui.Gui, v *gocui.View) error {
+	c.models.ClosedChannels.NextRange()
+	return nil
+}
+
+// NextFwdingHistRange selects the next time range of the forwarding
+// history, read again from the node in the background.
+func (c *controller) NextFwdingHistRange(g *gocui.Gui, v *gocui.View) error {
+	c.models.FwdingHist.NextRange()
+	m := c.models
+	go func() {
+		err := m.RefreshForwardingHistory(context.Background())
+		if err != nil {
+			c.logger.Error("cannot refresh forwarding history", logging.Error(err))
+		}
+		g.Update(func(*gocui.Gui) error { return nil })
+	}()
+	return cursor.Home(c.views.FwdingHist)
+}
+
+// Acknowledge removes the banner of the critical alerts.
+func (c *controller) Acknowledge(g *gocui.Gui, v *gocui.View) error {
+	c.models.Alerts.Acknowledge()
+	return nil
+}
+
+// ExportChannelBackup uploads the current channel backup of the node to the
+// export destination, the summary displays the result.
+func (c *controller) ExportChannelBackup(g *gocui.Gui, v *gocui.View) error {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := c.models.ExportChannelBackup(ctx)
+		if err != nil {
+			c.logger.Error("cannot export the channel backup", logging.Error(err))
+		}
+		g.Update(func(*gocui.Gui) error { return nil })
+	}()
+	return nil
+}
+
+// ShowInvoice creates an invoice without amount in the background and
+// displays its QR code.
+func (c *controller) ShowInvoice(g *gocui.Gui, v *gocui.View) error {
+	m, code := c.models, c.views.QRCode
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		invoice, err := m.CreateInvoice(ctx, 0, "lntop")
+		if err != nil {
+			c.logger.Error("cannot create invoice", logging.Error(err))
+			return
+		}
+		g.Update(func(*gocui.Gui) error {
+			return code.Show("invoice", qr.Invoice(invoice.PaymentRequest))
+		})
+	}()
+	return nil
+}
+
+// ShowAddress creates a new on-chain address in the background and
+// displays its QR code.
+func (c *controller) ShowAddress(g *gocui.Gui, v *gocui.View) error {
+	m, code := c.models, c.views.QRCode
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		address, err := m.NewAddress(ctx)
+		if err != nil {
+			c.logger.Error("cannot create address", logging.Error(err))
+			return
+		}
+		g.Update(func(*gocui.Gui) error {
+			return code.Show("address", qr.Address(address))
+		})
+	}()
+	return nil
+}
+
+// OpenLNDConnect asks to confirm the display of the lndconnect URI, it
+// holds the macaroon of the config.
+func (c *controller) OpenLNDConnect(g *gocui.Gui, v *gocui.View) error {
+	c.views.LNDConnect.Show()
+	return nil
+}
 
-	_, err = g.SetCurrentView(v2.Name())
-	return err
+func (c *controller) CloseLNDConnect(g *gocui.Gui, v *gocui.View) error {
+	c.views.LNDConnect.Hide()
+	return nil
 }
 
-func newController(app *app.App) *controller {
-	m := models.New(app)
-	return &controller{
-		logger: app.Logger.With(logging.String("logger", "controller")),
-		models: m,
-		views:  views.New(app.Config.Views, m),
+// ShowLNDConnect displays the QR code of the lndconnect URI of the node.
+func (c *controller) ShowLNDConnect(g *gocui.Gui, v *gocui.View) error {
+	c.views.LNDConnect.Hide()
+	uri, err := qr.LNDConnect(c.network)
+	if err != nil {
+		c.logger.Error("cannot build lndconnect uri", logging.Error(err))
+		return nil
 	}
+	return c.views.QRCode.Show("lndconnect", uri)
+}
+
+// CloseDetails closes the popups of the details.
+func (c *controller) CloseDetails(g *gocui.Gui, v *gocui.View) error {
+	c.views.HideDetails()
+	return nil
+}
+
+func (c *controller) CloseQRCode(g *gocui.Gui, v *gocui.View) error {
+	c.views.QRCode.Hide()
+	return nil
+}
+
+func (c *controller) OpenDecoder(g *gocui.Gui, v *gocui.View) error {
+	c.views.Decoder.Show()
+	return nil
+}
+
+func (c *controller) CloseDecoder(g *gocui.Gui, v *gocui.View) error {
+	c.views.Decoder.Hide()
+	return nil
+}
+
+// Decode decodes the payment request of the decoder input, an enter
+// received with a paste is ignored.
+func (c *controller) Decode(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Decoder.Pasting() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	payreq, alias, err := c.models.DecodePayReq(ctx, c.views.Decoder.Value())
+	c.views.Decoder.SetResult(payreq, alias, err)
+	return nil
+}
+
+func (c *controller) OpenMessage(g *gocui.Gui, v *gocui.View) error {
+	c.views.Message.Show()
+	return nil
+}
+
+func (c *controller) CloseMessage(g *gocui.Gui, v *gocui.View) error {
+	c.views.Message.Hide()
+	return nil
+}
+
+func (c *controller) NextMessageField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.Message.Next(g)
+}
+
+// SignOrVerifyMessage signs the message of the popup with the key of the
+// node in the background, or verifies its signature if one is given. Only
+// the signatures are verified in read-only mode.
+func (c *controller) SignOrVerifyMessage(g *gocui.Gui, v *gocui.View) error {
+	message := c.views.Message
+	if message.Pasting() || message.Busy() {
+		return nil
+	}
+	text, signature := message.Value()
+	if text == "" {
+		message.SetSignature("", errors.New("empty message"))
+		return nil
+	}
+	if signature == "" && c.readOnly {
+		message.SetSignature("", errors.New("signing is disabled in read-only mode"))
+		return nil
+	}
+	message.Start()
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if signature == "" {
+			signed, err := m.SignMessage(ctx, text)
+			g.Update(func(*gocui.Gui) error {
+				message.SetSignature(signed, err)
+				return nil
+			})
+			return
+		}
+		verified, alias, err := m.VerifyMessage(ctx, text, signature)
+		g.Update(func(*gocui.Gui) error {
+			message.SetVerified(verified, alias, err)
+			return nil
+		})
+	}()
+	return nil
+}
+
+// CopySignature copies the signature of the popup to the clipboard of the
+// terminal.
+func (c *controller) CopySignature(g *gocui.Gui, v *gocui.View) error {
+	_, signature := c.views.Message.Value()
+	if signature == "" {
+		return nil
+	}
+	c.views.Message.SetCopied(copyToClipboard(signature))
+	return nil
+}
+
+func (c *controller) OpenPay(g *gocui.Gui, v *gocui.View) error {
+	c.views.Pay.Show()
+	return nil
+}
+
+func (c *controller) ClosePay(g *gocui.Gui, v *gocui.View) error {
+	c.views.Pay.Hide()
+	return nil
+}
+
+// Pay decodes the payment request of the input and asks to confirm it,
+// the second enter pays it in the background while the popup spins.
+func (c *controller) Pay(g *gocui.Gui, v *gocui.View) error {
+	pay := c.views.Pay
+	if pay.Pasting() || pay.Sent() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	payreq, alias, err := c.models.DecodePayReq(ctx, pay.Value())
+	if err != nil {
+		pay.SetError(err)
+		return nil
+	}
+	if !pay.Confirm(payreq, alias) {
+		return nil
+	}
+	pay.Start()
+
+	m := c.models
+	done := make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*90)
+		defer cancel()
+		payment, err := m.SendPayment(ctx, payreq)
+		if err != nil {
+			c.logger.Error("cannot pay", logging.String("payment_hash", payreq.PaymentHash), logging.Error(err))
+		} else {
+			c.logger.Info("payment done", logging.Object("payment", payment))
+		}
+		close(done)
+		g.Update(func(*gocui.Gui) error {
+			pay.SetResult(payment, err)
+			return nil
+		})
+	}()
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *controller) OpenKeysend(g *gocui.Gui, v *gocui.View) error {
+	c.views.Keysend.Show()
+	return nil
+}
+
+func (c *controller) CloseKeysend(g *gocui.Gui, v *gocui.View) error {
+	c.views.Keysend.Hide()
+	return nil
+}
+
+func (c *controller) NextKeysendField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.Keysend.Next(g)
+}
+
+// Keysend asks to confirm the payment of the dialog and sends it at the
+// second enter, each update of the payment is handled as a KeysendUpdated
+// event.
+func (c *controller) Keysend(g *gocui.Gui, v *gocui.View) error {
+	keysend := c.views.Keysend
+	if keysend.Pasting() || keysend.Sent() {
+		return nil
+	}
+	req, err := keysend.Value()
+	if err != nil {
+		keysend.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if !keysend.Confirm(req, c.models.NodeAlias(ctx, req.Destination)) {
+		return nil
+	}
+	keysend.Start()
+
+	m := c.models
+	updates := make(chan *netmodels.Payment)
+	done := make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*90)
+		defer cancel()
+		payment, err := m.SendKeysend(ctx, req, updates)
+		if err != nil {
+			c.logger.Error("cannot send keysend", logging.String("destination", req.Destination), logging.Error(err))
+		} else {
+			c.logger.Info("keysend done", logging.Object("payment", payment))
+		}
+		close(done)
+		g.Update(func(*gocui.Gui) error {
+			keysend.SetResult(payment, err)
+			return nil
+		})
+		if payment != nil {
+			c.handle(context.Background(), g, m, events.NewWithData(events.KeysendUpdated, payment))
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case payment := <-updates:
+				g.Update(func(*gocui.Gui) error {
+					keysend.SetUpdate(payment)
+					return nil
+				})
+				c.handle(context.Background(), g, m, events.NewWithData(events.KeysendUpdated, payment))
+			case <-ticker.C:
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+		}
+	}()
+	return nil
+}
+
+// OpenBumpFee opens the fee bump of the sweep selected in the sweeps
+// view.
+func (c *controller) OpenBumpFee(g *gocui.Gui, v *gocui.View) error {
+	sweep := c.models.Sweeps.Get(c.views.Sweeps.Index())
+	if sweep == nil {
+		return nil
+	}
+	c.views.BumpFee.Show(sweep)
+	return nil
+}
+
+func (c *controller) CloseBumpFee(g *gocui.Gui, v *gocui.View) error {
+	c.views.BumpFee.Hide()
+	return nil
+}
+
+// BumpFee requests the fee rate of the input for the selected sweep, the
+// popup is closed once the sweeper accepted it.
+func (c *controller) BumpFee(g *gocui.Gui, v *gocui.View) error {
+	if c.views.BumpFee.Pasting() {
+		return nil
+	}
+	sweep := c.views.BumpFee.Sweep()
+	if sweep == nil {
+		return nil
+	}
+	rate, err := c.views.BumpFee.Value()
+	if err != nil {
+		c.views.BumpFee.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = c.models.BumpFee(ctx, sweep.Outpoint, rate)
+	if err != nil {
+		c.logger.Error("cannot bump fee", logging.String("outpoint", sweep.Outpoint), logging.Error(err))
+		c.views.BumpFee.SetError(err)
+		return nil
+	}
+	c.views.BumpFee.Hide()
+	return nil
+}
+
+// ToggleUTXO selects or unselects the output of the utxos view for a
+// consolidation, a leased output cannot be spent.
+func (c *controller) ToggleUTXO(g *gocui.Gui, v *gocui.View) error {
+	utxo := c.models.UTXOs.Get(c.views.UTXOs.Index())
+	if utxo == nil || utxo.Leased() {
+		return nil
+	}
+	c.models.UTXOs.Toggle(utxo.Outpoint)
+	return nil
+}
+
+func (c *controller) OpenConsolidate(g *gocui.Gui, v *gocui.View) error {
+	if len(c.models.UTXOs.Selected()) == 0 {
+		return nil
+	}
+	c.views.Consolidate.Show()
+	return nil
+}
+
+func (c *controller) CloseConsolidate(g *gocui.Gui, v *gocui.View) error {
+	c.views.Consolidate.Hide()
+	return nil
+}
+
+// Consolidate publishes the consolidation of the selected outputs at the
+// fee rate of the input, an enter once it is published closes it.
+func (c *controller) Consolidate(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Consolidate.Pasting() {
+		return nil
+	}
+	if c.views.Consolidate.Done() {
+		c.views.Consolidate.Hide()
+		return nil
+	}
+	rate, err := c.views.Consolidate.Value()
+	if err != nil {
+		c.views.Consolidate.SetResult(nil, err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	result, err := c.models.Consolidate(ctx, rate)
+	if err != nil {
+		c.logger.Error("cannot consolidate", logging.Error(err))
+	}
+	c.views.Consolidate.SetResult(result, err)
+	return nil
+}
+
+func (c *controller) OpenLabel(g *gocui.Gui, v *gocui.View) error {
+	utxo := c.models.UTXOs.Get(c.views.UTXOs.Index())
+	if utxo == nil {
+		return nil
+	}
+	c.views.Label.Show(utxo)
+	return nil
+}
+
+func (c *controller) CloseLabel(g *gocui.Gui, v *gocui.View) error {
+	c.views.Label.Hide()
+	return nil
+}
+
+// SetLabel labels the transaction of the output with the input.
+func (c *controller) SetLabel(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Label.Pasting() {
+		return nil
+	}
+	utxo := c.views.Label.UTXO()
+	if utxo == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := c.models.LabelUTXO(ctx, utxo, c.views.Label.Value())
+	if err != nil {
+		c.logger.Error("cannot label transaction", logging.String("outpoint", utxo.Outpoint), logging.Error(err))
+		c.views.Label.SetError(err)
+		return nil
+	}
+	c.views.Label.Hide()
+	return nil
+}
+
+// OpenPolicy opens the policy editor of the selected channel.
+func (c *controller) OpenPolicy(g *gocui.Gui, v *gocui.View) error {
+	channel := c.models.Channels.Get(c.views.Channels.Index())
+	if channel == nil {
+		return nil
+	}
+	c.views.Policy.Show(channel)
+	return nil
+}
+
+func (c *controller) ClosePolicy(g *gocui.Gui, v *gocui.View) error {
+	c.views.Policy.Hide()
+	return nil
+}
+
+func (c *controller) NextPolicyField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.Policy.Next(g)
+}
+
+// UpdatePolicy sets the policy of the editor for its channel.
+func (c *controller) UpdatePolicy(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Policy.Pasting() {
+		return nil
+	}
+	channel := c.views.Policy.Channel()
+	if channel == nil {
+		return nil
+	}
+	policy, err := c.views.Policy.Value()
+	if err != nil {
+		c.views.Policy.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = c.models.UpdateChannelPolicy(ctx, channel, policy)
+	if err != nil {
+		c.logger.Error("cannot update channel policy", logging.String("channel_point", channel.ChannelPoint), logging.Error(err))
+		c.views.Policy.SetError(err)
+		return nil
+	}
+	c.views.Policy.Hide()
+	return nil
+}
+
+// OpenNote opens the tags and the note of the selected channel, or of the
+// channel of the detail view.
+func (c *controller) OpenNote(g *gocui.Gui, v *gocui.View) error {
+	channel := c.models.Channels.Get(c.views.Channels.Index())
+	if v.Name() == views.CHANNEL {
+		channel = c.models.Channels.Current()
+	}
+	if channel == nil {
+		return nil
+	}
+	c.views.Note.Show(channel)
+	return nil
+}
+
+func (c *controller) CloseNote(g *gocui.Gui, v *gocui.View) error {
+	c.views.Note.Hide()
+	return nil
+}
+
+func (c *controller) NextNoteField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.Note.Next(g)
+}
+
+// UpdateNote records the tags and the note of the form for its channel.
+func (c *controller) UpdateNote(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Note.Pasting() {
+		return nil
+	}
+	channel := c.views.Note.Channel()
+	if channel == nil {
+		return nil
+	}
+	tags, note := c.views.Note.Value()
+	err := c.models.SetChannelNote(channel, tags, note)
+	if err != nil {
+		c.logger.Error("cannot record channel note", logging.String("channel_point", channel.ChannelPoint), logging.Error(err))
+		c.views.Note.SetError(err)
+		return nil
+	}
+	c.views.Note.Hide()
+	return nil
+}
+
+// OpenChannelDialog opens the dialog of a new channel, with the peer
+// selected in the peers view if it is displayed.
+func (c *controller) OpenChannelDialog(g *gocui.Gui, v *gocui.View) error {
+	pubkey := ""
+	switch {
+	case c.views.Node.Visible():
+		if node := c.models.Graph.Current; node != nil {
+			pubkey = node.PubKey
+		}
+	case c.views.Main.Name() == views.PEERS:
+		if peer := c.models.Peers.Get(c.views.Peers.Index()); peer != nil {
+			pubkey = peer.PubKey
+		}
+	case c.views.Main.Name() == views.GRAPH:
+		if node := c.models.Graph.Get(c.views.Graph.Index()); node != nil {
+			pubkey = node.PubKey
+		}
+	}
+	c.views.OpenChannel.Show(pubkey, c.models.FeeRates.HalfHour())
+	return nil
+}
+
+func (c *controller) CloseOpenChannel(g *gocui.Gui, v *gocui.View) error {
+	c.views.OpenChannel.Hide()
+	return nil
+}
+
+func (c *controller) NextOpenChannelField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.OpenChannel.Next(g)
+}
+
+// OpenChannel asks to confirm the channel of the dialog and opens it in
+// the background at the second enter, the channels view then displays it
+// as pending.
+func (c *controller) OpenChannel(g *gocui.Gui, v *gocui.View) error {
+	dialog := c.views.OpenChannel
+	if dialog.Pasting() || dialog.Opening() {
+		return nil
+	}
+	req, err := dialog.Value()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if !dialog.Confirm(req, c.models.NodeAlias(ctx, req.PubKey)) {
+		return nil
+	}
+	dialog.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+		point, err := m.OpenChannel(ctx, req.PubKey, req.Amount, req.SatPerVbyte, req.Private)
+		if err != nil {
+			c.logger.Error("cannot open channel", logging.String("pubkey", req.PubKey), logging.Error(err))
+		} else {
+			c.logger.Info("channel opened", logging.String("channel_point", point))
+		}
+		g.Update(func(g *gocui.Gui) error {
+			if !dialog.Opening() {
+				return nil
+			}
+			if err != nil {
+				dialog.SetError(err)
+				return nil
+			}
+			dialog.Hide()
+			return c.setMain(g, views.CHANNELS)
+		})
+	}()
+	return nil
+}
+
+// OpenBatchOpen opens the dialog of a batch of channels with the selected
+// peers, or the peer under the cursor if none is selected.
+func (c *controller) OpenBatchOpen(g *gocui.Gui, v *gocui.View) error {
+	var pubkeys []string
+	for _, peer := range c.models.Peers.Selected() {
+		pubkeys = append(pubkeys, peer.PubKey)
+	}
+	if len(pubkeys) == 0 && c.views.Main.Name() == views.PEERS {
+		if peer := c.models.Peers.Get(c.views.Peers.Index()); peer != nil {
+			pubkeys = append(pubkeys, peer.PubKey)
+		}
+	}
+	c.views.BatchOpen.Show(pubkeys)
+	return nil
+}
+
+func (c *controller) CloseBatchOpen(g *gocui.Gui, v *gocui.View) error {
+	c.views.BatchOpen.Hide()
+	return nil
+}
+
+func (c *controller) NextBatchOpenField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.BatchOpen.Next(g)
+}
+
+func (c *controller) RemoveBatchChannel(g *gocui.Gui, v *gocui.View) error {
+	if c.views.BatchOpen.Busy() {
+		return nil
+	}
+	c.views.BatchOpen.RemoveLast()
+	return nil
+}
+
+// BatchOpen adds the channel of the fields to the batch, or without pubkey
+// estimates the fee of the batch and opens its channels at the next enter,
+// both in the background.
+func (c *controller) BatchOpen(g *gocui.Gui, v *gocui.View) error {
+	dialog := c.views.BatchOpen
+	if dialog.Pasting() || dialog.Busy() {
+		return nil
+	}
+	channel, err := dialog.Value()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	if channel != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		err = dialog.Add(channel, c.models.NodeAlias(ctx, channel.PubKey))
+		if err != nil {
+			dialog.SetError(err)
+		}
+		return nil
+	}
+
+	channels := dialog.Channels()
+	if len(channels) == 0 {
+		dialog.SetError(errors.New("the batch has no channel"))
+		return nil
+	}
+	rate, err := dialog.FeeRate()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+
+	m := c.models
+	if !dialog.Confirmed(rate) {
+		dialog.Start("estimating the fee")
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+			estimate, err := m.EstimateBatchOpen(ctx, channels, rate)
+			if err != nil {
+				c.logger.Debug("cannot estimate batch fee", logging.Error(err))
+			}
+			g.Update(func(*gocui.Gui) error {
+				if dialog.Busy() {
+					dialog.SetEstimate(rate, estimate, err)
+				}
+				return nil
+			})
+		}()
+		return nil
+	}
+	dialog.Start("opening the channels")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+		txid, err := m.BatchOpenChannel(ctx, channels, rate)
+		if err != nil {
+			c.logger.Error("cannot open channels", logging.Int("channels", len(channels)), logging.Error(err))
+		} else {
+			c.logger.Info("channels opened", logging.String("txid", txid), logging.Int("channels", len(channels)))
+		}
+		g.Update(func(g *gocui.Gui) error {
+			if !dialog.Busy() {
+				return nil
+			}
+			if err != nil {
+				dialog.SetError(err)
+				return nil
+			}
+			dialog.Hide()
+			return c.setMain(g, views.CHANNELS)
+		})
+	}()
+	return nil
+}
+
+// TogglePeer selects or unselects the peer for a batch open.
+func (c *controller) TogglePeer(g *gocui.Gui, v *gocui.View) error {
+	peer := c.models.Peers.Get(c.views.Peers.Index())
+	if peer == nil {
+		return nil
+	}
+	c.models.Peers.Toggle(peer.PubKey)
+	return nil
+}
+
+// CloseChannelDialog opens the dialog closing the selected channel.
+func (c *controller) CloseChannelDialog(g *gocui.Gui, v *gocui.View) error {
+	channel := c.models.Channels.Get(c.views.Channels.Index())
+	if channel == nil {
+		return nil
+	}
+	c.views.CloseChannel.Show(channel, c.models.FeeRates.HalfHour())
+	return nil
+}
+
+func (c *controller) CancelCloseChannel(g *gocui.Gui, v *gocui.View) error {
+	c.views.CloseChannel.Hide()
+	return nil
+}
+
+func (c *controller) NextCloseChannelField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.CloseChannel.Next(g)
+}
+
+// CloseChannel asks to confirm the close of the dialog and closes the
+// channel in the background at the second enter, the ChannelClosing event
+// then displays it as closing.
+func (c *controller) CloseChannel(g *gocui.Gui, v *gocui.View) error {
+	dialog := c.views.CloseChannel
+	if dialog.Pasting() || dialog.Closing() {
+		return nil
+	}
+	channel := dialog.Channel()
+	if channel == nil {
+		return nil
+	}
+	req, err := dialog.Value()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	if !dialog.Confirm(req) {
+		return nil
+	}
+	dialog.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+		txid, err := m.CloseChannel(ctx, channel, req.Force, req.SatPerVbyte)
+		if err != nil {
+			c.logger.Error("cannot close channel", logging.String("channel_point", channel.ChannelPoint), logging.Error(err))
+			g.Update(func(*gocui.Gui) error {
+				if dialog.Closing() {
+					dialog.SetError(err)
+				}
+				return nil
+			})
+			return
+		}
+		c.logger.Info("channel closing", logging.String("channel_point", channel.ChannelPoint),
+			logging.String("txid", txid))
+		g.Update(func(*gocui.Gui) error {
+			if dialog.Closing() {
+				dialog.Hide()
+			}
+			return nil
+		})
+
+		update := &netmodels.ChannelUpdate{
+			ChannelPoint: channel.ChannelPoint,
+			RemotePubKey: channel.RemotePubKey,
+			CloseType:    netmodels.CloseCooperative,
+		}
+		if req.Force {
+			update.CloseType = netmodels.CloseLocalForce
+		}
+		c.handle(ctx, g, m, events.NewWithData(events.ChannelClosing, update))
+	}()
+	return nil
+}
+
+// OpenSearch opens the search of the channels view with its query.
+func (c *controller) OpenSearch(g *gocui.Gui, v *gocui.View) error {
+	c.views.Search.Show(c.models.Channels.Search(), c.search)
+	return nil
+}
+
+// search filters the channels with the query as it is typed.
+func (c *controller) search(query string) {
+	c.models.Channels.SetSearch(query)
+	_ = cursor.Home(c.views.Channels)
+}
+
+// CloseSearch closes the search, the channels stay filtered.
+func (c *controller) CloseSearch(g *gocui.Gui, v *gocui.View) error {
+	c.views.Search.Hide()
+	return nil
+}
+
+// ClearSearch closes the search and displays all the channels again.
+func (c *controller) ClearSearch(g *gocui.Gui, v *gocui.View) error {
+	c.views.Search.Hide()
+	if c.models.Channels.Search() == "" {
+		return nil
+	}
+	c.models.Channels.SetSearch("")
+	return cursor.Home(c.views.Channels)
+}
+
+// OpenLookup opens the lookup of the graph view with its query.
+func (c *controller) OpenLookup(g *gocui.Gui, v *gocui.View) error {
+	c.views.Lookup.Show(c.models.Graph.Query(), c.lookup)
+	return nil
+}
+
+// lookup filters the nodes of the graph with the query as it is typed.
+func (c *controller) lookup(query string) {
+	c.models.Graph.SetQuery(query)
+	_ = cursor.Home(c.views.Graph)
+}
+
+// CloseLookup closes the lookup, the nodes stay filtered.
+func (c *controller) CloseLookup(g *gocui.Gui, v *gocui.View) error {
+	c.views.Lookup.Hide()
+	return nil
+}
+
+// ClearLookup closes the lookup and displays all the nodes again.
+func (c *controller) ClearLookup(g *gocui.Gui, v *gocui.View) error {
+	c.views.Lookup.Hide()
+	if c.models.Graph.Query() == "" {
+		return nil
+	}
+	c.models.Graph.SetQuery("")
+	return cursor.Home(c.views.Graph)
+}
+
+// NextMatch moves to the next channel matching the search, back to the
+// first one after the last, or to the previous one if delta is negative.
+// Without a search the key does what the keymap binds it to in the other
+// views, if anything.
+func (c *controller) NextMatch(delta int, fallback func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if c.models.Channels.Search() == "" {
+			if fallback == nil {
+				return nil
+			}
+			return fallback(g, v)
+		}
+		view := c.views.Channels
+		if delta > 0 {
+			if view.Index() >= c.models.Channels.FilteredLen()-1 {
+				return cursor.Home(view)
+			}
+			return cursor.Down(view)
+		}
+		if view.Index() <= 0 {
+			return cursor.End(view)
+		}
+		return cursor.Up(view)
+	}
+}
+
+// channelsFilter returns the filter of the channels view of the config.
+func channelsFilter(cfg config.Views) string {
+	if cfg.Channels == nil {
+		return ""
+	}
+	return cfg.Channels.Filter
+}
+
+// OpenFilter opens the filter of the channels view with its expression.
+func (c *controller) OpenFilter(g *gocui.Gui, v *gocui.View) error {
+	c.views.Filter.Show(c.models.Channels.FilterExpr())
+	return nil
+}
+
+func (c *controller) CloseFilter(g *gocui.Gui, v *gocui.View) error {
+	c.views.Filter.Hide()
+	return nil
+}
+
+// ApplyFilter displays the channels of the expression of the field, all
+// of them if it is empty. The field stays open if it is invalid.
+func (c *controller) ApplyFilter(g *gocui.Gui, v *gocui.View) error {
+	if c.views.Filter.Pasting() {
+		return nil
+	}
+	err := c.models.Channels.SetFilterExpr(c.views.Filter.Value())
+	if err != nil {
+		c.views.Filter.SetError(err)
+		return nil
+	}
+	c.views.Filter.Hide()
+	return cursor.Home(c.views.Channels)
+}
+
+// OpenColumnChooser opens the column chooser of the main view.
+func (c *controller) OpenColumnChooser(g *gocui.Gui, v *gocui.View) error {
+	c.views.ShowColumnChooser()
+	return nil
+}
+
+func (c *controller) CloseColumnChooser(g *gocui.Gui, v *gocui.View) error {
+	c.views.ColumnChooser.Hide()
+	return nil
+}
+
+func (c *controller) ToggleColumn(g *gocui.Gui, v *gocui.View) error {
+	c.views.ColumnChooser.Toggle()
+	return nil
+}
+
+// MoveColumn moves the current column of the chooser down if delta is
+// positive, up otherwise.
+func (c *controller) MoveColumn(delta int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		return c.views.ColumnChooser.Move(delta)
+	}
+}
+
+// ResizeColumn widens or shrinks the current column of the chooser.
+func (c *controller) ResizeColumn(delta int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		c.views.ColumnChooser.Resize(delta)
+		return nil
+	}
+}
+
+// SaveColumns displays the columns of the chooser in the view of every
+// node and writes them to the config file.
+func (c *controller) SaveColumns(g *gocui.Gui, v *gocui.View) error {
+	chooser := c.views.ColumnChooser
+	name := chooser.Target()
+	columns, widths := chooser.Value()
+	if len(columns) == 0 {
+		chooser.SetError(errors.New("at least one column must be shown"))
+		return nil
+	}
+	for i := range c.nodes {
+		gui := g
+		if i != c.current {
+			gui = nil
+		}
+		err := c.nodes[i].views.SetColumns(gui, name, columns, widths)
+		if err != nil {
+			return err
+		}
+	}
+	err := config.SaveColumns(c.config, name, columns, widths)
+	if err != nil {
+		c.logger.Error("cannot save the columns", logging.Error(err))
+		chooser.SetError(errors.Errorf("shown, cannot save: %s", err))
+		return nil
+	}
+	chooser.Hide()
+	return nil
+}
+
+// OpenSortMenu opens the sort menu of the channels view.
+func (c *controller) OpenSortMenu(g *gocui.Gui, v *gocui.View) error {
+	c.views.ShowSortMenu()
+	return nil
+}
+
+func (c *controller) CloseSortMenu(g *gocui.Gui, v *gocui.View) error {
+	c.views.SortMenu.Hide()
+	return nil
+}
+
+func (c *controller) CycleSort(g *gocui.Gui, v *gocui.View) error {
+	c.views.SortMenu.Cycle()
+	return nil
+}
+
+// MoveSort lowers the priority of the current column of the sort menu if
+// delta is positive, raises it otherwise.
+func (c *controller) MoveSort(delta int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		c.views.SortMenu.Move(delta)
+		return nil
+	}
+}
+
+// SaveSort sorts the channels of every node by the keys of the sort menu
+// and writes them to the config file.
+func (c *controller) SaveSort(g *gocui.Gui, v *gocui.View) error {
+	menu := c.views.SortMenu
+	var values []string
+	for i := range c.nodes {
+		values = c.nodes[i].views.SetSort(menu.Value())
+	}
+	err := config.SaveSort(c.config, "channels", values)
+	if err != nil {
+		c.logger.Error("cannot save the sort", logging.Error(err))
+		menu.SetError(errors.Errorf("sorted, cannot save: %s", err))
+		return nil
+	}
+	menu.Hide()
+	return nil
+}
+
+// OpenRebalance opens the rebalance dialog with the selected channel as
+// the outgoing one.
+func (c *controller) OpenRebalance(g *gocui.Gui, v *gocui.View) error {
+	out := ""
+	if channel := c.models.Channels.Get(c.views.Channels.Index()); channel != nil {
+		out = netmodels.ToScid(channel.ID)
+	}
+	c.views.Rebalance.Show(out)
+	return nil
+}
+
+func (c *controller) CloseRebalance(g *gocui.Gui, v *gocui.View) error {
+	c.views.Rebalance.Hide()
+	return nil
+}
+
+func (c *controller) NextRebalanceField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.Rebalance.Next(g)
+}
+
+// Rebalance asks to confirm the rebalance of the dialog and starts it at
+// the second enter, the dialog displays the attempts as they progress.
+func (c *controller) Rebalance(g *gocui.Gui, v *gocui.View) error {
+	rebalance := c.views.Rebalance
+	if rebalance.Pasting() || rebalance.Sent() {
+		return nil
+	}
+	req, err := rebalance.Value()
+	if err != nil {
+		rebalance.SetError(err)
+		return nil
+	}
+	out := c.models.Channels.Find(req.Out)
+	if out == nil {
+		rebalance.SetError(errors.Errorf("no channel %q", req.Out))
+		return nil
+	}
+	in := c.models.Channels.Find(req.In)
+	if in == nil {
+		rebalance.SetError(errors.Errorf("no channel %q", req.In))
+		return nil
+	}
+	if out.RemotePubKey == in.RemotePubKey {
+		rebalance.SetError(errors.New("the channels are with the same peer"))
+		return nil
+	}
+	if !rebalance.Confirm(req, out, in) {
+		return nil
+	}
+	rebalance.Start()
+
+	m := c.models
+	attempts := make(chan *netmodels.RebalanceAttempt)
+	done := make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		payment, err := m.Rebalance(ctx, out, in, req.Amount, req.MaxFee, attempts)
+		if err != nil {
+			c.logger.Error("cannot rebalance", logging.Uint64("out", out.ID), logging.Uint64("in", in.ID), logging.Error(err))
+		} else {
+			c.logger.Info("rebalance done", logging.Object("payment", payment))
+		}
+		close(done)
+		g.Update(func(*gocui.Gui) error {
+			rebalance.SetResult(payment, err)
+			return nil
+		})
+	}()
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case attempt := <-attempts:
+				g.Update(func(*gocui.Gui) error {
+					rebalance.SetAttempt(attempt)
+					return nil
+				})
+			case <-ticker.C:
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *controller) OpenConnectPeer(g *gocui.Gui, v *gocui.View) error {
+	c.views.ConnectPeer.Show()
+	return nil
+}
+
+func (c *controller) CloseConnectPeer(g *gocui.Gui, v *gocui.View) error {
+	c.views.ConnectPeer.Hide()
+	return nil
+}
+
+// ConnectPeer connects to the uri of the popup in the background, the
+// popup is closed once the node is connected.
+func (c *controller) ConnectPeer(g *gocui.Gui, v *gocui.View) error {
+	popup := c.views.ConnectPeer
+	if popup.Pasting() || popup.Connecting() {
+		return nil
+	}
+	pubkey, host, err := popup.Value()
+	if err != nil {
+		popup.SetError(err)
+		return nil
+	}
+	popup.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*35)
+		defer cancel()
+		err := m.ConnectPeer(ctx, pubkey, host)
+		if err != nil {
+			c.logger.Error("cannot connect peer", logging.String("pubkey", pubkey), logging.Error(err))
+		} else {
+			c.logger.Info("peer connected", logging.String("pubkey", pubkey), logging.String("host", host))
+		}
+		g.Update(func(g *gocui.Gui) error {
+			if !popup.Connecting() {
+				return nil
+			}
+			if err != nil {
+				popup.SetError(err)
+				return nil
+			}
+			popup.Hide()
+			c.handle(context.Background(), g, m, events.New(events.PeerUpdated))
+			return nil
+		})
+	}()
+	return nil
+}
+
+func (c *controller) OpenDisconnectPeer(g *gocui.Gui, v *gocui.View) error {
+	peer := c.models.Peers.Get(c.views.Peers.Index())
+	if peer == nil {
+		return nil
+	}
+	c.views.DisconnectPeer.Show(peer)
+	return nil
+}
+
+func (c *controller) CloseDisconnectPeer(g *gocui.Gui, v *gocui.View) error {
+	c.views.DisconnectPeer.Hide()
+	return nil
+}
+
+func (c *controller) DisconnectPeer(g *gocui.Gui, v *gocui.View) error {
+	peer := c.views.DisconnectPeer.Peer()
+	if peer == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := c.models.DisconnectPeer(ctx, peer.PubKey)
+	if err != nil {
+		c.logger.Error("cannot disconnect peer", logging.String("pubkey", peer.PubKey), logging.Error(err))
+		c.views.DisconnectPeer.SetError(err)
+		return nil
+	}
+	c.logger.Info("peer disconnected", logging.String("pubkey", peer.PubKey))
+	c.views.DisconnectPeer.Hide()
+	c.handle(ctx, g, c.models, events.New(events.PeerUpdated))
+	return nil
+}
+
+func (c *controller) OpenAddTower(g *gocui.Gui, v *gocui.View) error {
+	c.views.AddTower.Show()
+	return nil
+}
+
+func (c *controller) CloseAddTower(g *gocui.Gui, v *gocui.View) error {
+	c.views.AddTower.Hide()
+	return nil
+}
+
+// AddTower registers the tower of the uri of the popup in the background,
+// the popup is closed once the tower is added.
+func (c *controller) AddTower(g *gocui.Gui, v *gocui.View) error {
+	popup := c.views.AddTower
+	if popup.Pasting() || popup.Adding() {
+		return nil
+	}
+	pubkey, host, err := popup.Value()
+	if err != nil {
+		popup.SetError(err)
+		return nil
+	}
+	popup.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*35)
+		defer cancel()
+		err := m.AddTower(ctx, pubkey, host)
+		if err != nil {
+			c.logger.Error("cannot add tower", logging.String("pubkey", pubkey), logging.Error(err))
+		} else {
+			c.logger.Info("tower added", logging.String("pubkey", pubkey), logging.String("host", host))
+		}
+		g.Update(func(g *gocui.Gui) error {
+			if !popup.Adding() {
+				return nil
+			}
+			if err != nil {
+				popup.SetError(err)
+				return nil
+			}
+			popup.Hide()
+			return nil
+		})
+	}()
+	return nil
+}
+
+func (c *controller) OpenRemoveTower(g *gocui.Gui, v *gocui.View) error {
+	tower := c.models.Towers.Get(c.views.Towers.Index())
+	if tower == nil {
+		return nil
+	}
+	c.views.RemoveTower.Show(tower)
+	return nil
+}
+
+func (c *controller) CloseRemoveTower(g *gocui.Gui, v *gocui.View) error {
+	c.views.RemoveTower.Hide()
+	return nil
+}
+
+func (c *controller) RemoveTower(g *gocui.Gui, v *gocui.View) error {
+	tower := c.views.RemoveTower.Tower()
+	if tower == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := c.models.RemoveTower(ctx, tower.PubKey)
+	if err != nil {
+		c.logger.Error("cannot remove tower", logging.String("pubkey", tower.PubKey), logging.Error(err))
+		c.views.RemoveTower.SetError(err)
+		return nil
+	}
+	c.logger.Info("tower removed", logging.String("pubkey", tower.PubKey))
+	c.views.RemoveTower.Hide()
+	return nil
+}
+
+// refreshMissionControl refreshes mission control in the background, the
+// probabilities of the pairs are queried one by one.
+func (c *controller) refreshMissionControl(g *gocui.Gui) {
+	m, view := c.models, c.views.MissionControl
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := m.RefreshMissionControl(ctx)
+		g.Update(func(*gocui.Gui) error {
+			if err != nil {
+				c.logger.Error("cannot query mission control", logging.Error(err))
+				view.SetMessage("cannot query mission control: " + err.Error())
+			}
+			return nil
+		})
+	}()
+}
+
+// refreshGraph loads the graph in the background, it takes a while on
+// mainnet.
+func (c *controller) refreshGraph(g *gocui.Gui) {
+	m, view := c.models, c.views.Graph
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := m.RefreshGraph(ctx)
+		g.Update(func(*gocui.Gui) error {
+			if err != nil {
+				c.logger.Error("cannot load the graph", logging.Error(err))
+			}
+			view.SetError(err)
+			return nil
+		})
+	}()
+}
+
+func (c *controller) OpenResetMission(g *gocui.Gui, v *gocui.View) error {
+	c.views.ResetMission.Show(c.models.MissionControl.Len())
+	return nil
+}
+
+func (c *controller) CloseResetMission(g *gocui.Gui, v *gocui.View) error {
+	c.views.ResetMission.Hide()
+	return nil
+}
+
+// ResetMission resets mission control in the background.
+func (c *controller) ResetMission(g *gocui.Gui, v *gocui.View) error {
+	popup, mission := c.views.ResetMission, c.views.MissionControl
+	if popup.Resetting() {
+		return nil
+	}
+	popup.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+		err := m.ResetMissionControl(ctx)
+		if err != nil {
+			c.logger.Error("cannot reset mission control", logging.Error(err))
+		} else {
+			c.logger.Info("mission control reset")
+		}
+		g.Update(func(*gocui.Gui) error {
+			if !popup.Resetting() {
+				return nil
+			}
+			if err != nil {
+				popup.SetError(err)
+				return nil
+			}
+			popup.Hide()
+			mission.SetMessage("mission control reset")
+			return nil
+		})
+	}()
+	return nil
+}
+
+// ExportMissionControl writes the pairs of mission control to a file of
+// the export directory named after the time, in the JSON of lncli querymc.
+func (c *controller) ExportMissionControl(g *gocui.Gui, v *gocui.View) error {
+	name := fmt.Sprintf("missioncontrol-%s.json", time.Now().Format("20060102-150405"))
+	path := filepath.Join(c.export.Dir, name)
+	pairs := c.models.MissionControl.Pairs()
+	err := writeMissionControl(path, pairs)
+	if err != nil {
+		c.logger.Error("cannot export mission control", logging.Error(err))
+		c.views.MissionControl.SetMessage("cannot export: " + err.Error())
+		return nil
+	}
+	c.missionExport = path
+	c.views.MissionControl.SetMessage(fmt.Sprintf("%d pairs exported to %s", len(pairs), path))
+	return nil
+}
+
+func writeMissionControl(path string, pairs []*netmodels.MissionPair) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = export.WriteMissionControl(f, pairs)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return errors.WithStack(f.Close())
+}
+
+func (c *controller) OpenImportMission(g *gocui.Gui, v *gocui.View) error {
+	c.views.ImportMission.Show(c.missionExport)
+	return nil
+}
+
+func (c *controller) CloseImportMission(g *gocui.Gui, v *gocui.View) error {
+	c.views.ImportMission.Hide()
+	return nil
+}
+
+func (c *controller) NextImportMissionField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.ImportMission.Next(g)
+}
+
+// ImportMission imports the pairs of the file of the dialog in mission
+// control in the background.
+func (c *controller) ImportMission(g *gocui.Gui, v *gocui.View) error {
+	dialog, mission := c.views.ImportMission, c.views.MissionControl
+	if dialog.Pasting() || dialog.Importing() {
+		return nil
+	}
+	path, force, err := dialog.Value()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	pairs, err := export.ReadMissionControl(f)
+	f.Close()
+	if err != nil {
+		dialog.SetError(err)
+		return nil
+	}
+	dialog.Start()
+
+	m := c.models
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+		err := m.ImportMissionControl(ctx, pairs, force)
+		if err != nil {
+			c.logger.Error("cannot import mission control", logging.String("path", path), logging.Error(err))
+		} else {
+			c.logger.Info("mission control imported", logging.String("path", path), logging.Int("pairs", len(pairs)))
+		}
+		g.Update(func(*gocui.Gui) error {
+			if !dialog.Importing() {
+				return nil
+			}
+			if err != nil {
+				dialog.SetError(err)
+				return nil
+			}
+			dialog.Hide()
+			mission.SetMessage(fmt.Sprintf("%d pairs imported from %s", len(pairs), path))
+			return nil
+		})
+	}()
+	return nil
+}
+
+// OpenResolveHTLC returns the handler confirming the action on the held
+// HTLC of the firewall view.
+func (c *controller) OpenResolveHTLC(action int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		htlc := c.models.Firewall.Get(c.views.Firewall.Index())
+		if htlc == nil || htlc.Action != netmodels.InterceptHold {
+			return nil
+		}
+		c.views.ResolveHTLC.Show(htlc, action)
+		return nil
+	}
+}
+
+func (c *controller) CloseResolveHTLC(g *gocui.Gui, v *gocui.View) error {
+	c.views.ResolveHTLC.Hide()
+	return nil
+}
+
+func (c *controller) ResolveHTLC(g *gocui.Gui, v *gocui.View) error {
+	popup := c.views.ResolveHTLC
+	htlc := popup.HTLC()
+	if htlc == nil || popup.Pasting() {
+		return nil
+	}
+	preimage, err := popup.Preimage()
+	if err != nil {
+		popup.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = c.models.ResolveHTLC(ctx, htlc.Key(), popup.Action(), preimage)
+	if err != nil {
+		c.logger.Error("cannot resolve htlc", logging.String("key", htlc.Key()), logging.Error(err))
+		popup.SetError(err)
+		return nil
+	}
+	c.logger.Info("htlc resolved", logging.String("key", htlc.Key()),
+		logging.Int("action", popup.Action()))
+	popup.Hide()
+	return nil
+}
+
+func (c *controller) OpenCreateInvoice(g *gocui.Gui, v *gocui.View) error {
+	c.views.CreateInvoice.Show()
+	return nil
+}
+
+func (c *controller) CloseCreateInvoice(g *gocui.Gui, v *gocui.View) error {
+	c.views.CreateInvoice.Hide()
+	return nil
+}
+
+func (c *controller) NextCreateInvoiceField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.CreateInvoice.Next(g)
+}
+
+// CreateInvoice creates the invoice of the dialog and displays its QR
+// code, lightningd does not notify the invoices created.
+func (c *controller) CreateInvoice(g *gocui.Gui, v *gocui.View) error {
+	if c.views.CreateInvoice.Pasting() {
+		return nil
+	}
+	amount, memo, err := c.views.CreateInvoice.Value()
+	if err != nil {
+		c.views.CreateInvoice.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	invoice, err := c.models.CreateInvoice(ctx, amount, memo)
+	if err != nil {
+		c.logger.Error("cannot create invoice", logging.Error(err))
+		c.views.CreateInvoice.SetError(err)
+		return nil
+	}
+	c.views.CreateInvoice.Hide()
+	c.handle(ctx, g, c.models, events.New(events.InvoiceCreated))
+	return c.views.QRCode.Show("invoice", qr.Invoice(invoice.PaymentRequest))
+}
+
+// CopyOffer copies the bolt12 string of the offer of the detail to the
+// clipboard.
+func (c *controller) CopyOffer(g *gocui.Gui, v *gocui.View) error {
+	offer := c.views.Offer.Current()
+	if offer == nil {
+		return nil
+	}
+	c.views.Offer.SetCopied(copyToClipboard(offer.Bolt12))
+	return nil
+}
+
+func (c *controller) OpenCreateOffer(g *gocui.Gui, v *gocui.View) error {
+	c.views.CreateOffer.Show()
+	return nil
+}
+
+func (c *controller) CloseCreateOffer(g *gocui.Gui, v *gocui.View) error {
+	c.views.CreateOffer.Hide()
+	return nil
+}
+
+func (c *controller) NextCreateOfferField(g *gocui.Gui, v *gocui.View) error {
+	return c.views.CreateOffer.Next(g)
+}
+
+// CreateOffer creates the offer of the dialog and displays its QR code.
+func (c *controller) CreateOffer(g *gocui.Gui, v *gocui.View) error {
+	if c.views.CreateOffer.Pasting() {
+		return nil
+	}
+	amount, description, err := c.views.CreateOffer.Value()
+	if err != nil {
+		c.views.CreateOffer.SetError(err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	offer, err := c.models.CreateOffer(ctx, amount, description)
+	if err != nil {
+		c.logger.Error("cannot create offer", logging.Error(err))
+		c.views.CreateOffer.SetError(err)
+		return nil
+	}
+	c.logger.Info("offer created", logging.String("id", offer.ID))
+	c.views.CreateOffer.Hide()
+	return c.views.QRCode.Show("offer", qr.Invoice(offer.Bolt12))
+}
+
+func (c *controller) OpenDisableOffer(g *gocui.Gui, v *gocui.View) error {
+	offer := c.models.Offers.Get(c.views.Offers.Index())
+	if offer == nil || offer.Status != netmodels.OfferActive {
+		return nil
+	}
+	c.views.DisableOffer.Show(offer)
+	return nil
+}
+
+func (c *controller) CloseDisableOffer(g *gocui.Gui, v *gocui.View) error {
+	c.views.DisableOffer.Hide()
+	return nil
+}
+
+func (c *controller) DisableOffer(g *gocui.Gui, v *gocui.View) error {
+	offer := c.views.DisableOffer.Offer()
+	if offer == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := c.models.DisableOffer(ctx, offer.ID)
+	if err != nil {
+		c.logger.Error("cannot disable offer", logging.String("id", offer.ID), logging.Error(err))
+		c.views.DisableOffer.SetError(err)
+		return nil
+	}
+	c.logger.Info("offer disabled", logging.String("id", offer.ID))
+	c.views.DisableOffer.Hide()
+	return nil
+}
+
+func newController(nodes []Node) *controller {
+	app := nodes[0].App
+	c := &controller{
+		logger:   app.Logger.With(logging.String("logger", "controller")),
+		nodes:    make([]*node, len(nodes)),
+		export:   app.Config.Export,
+		config:   app.Config.Path,
+		keys:     app.Config.Keys,
+		readOnly: app.Config.ReadOnly,
+	}
+	for i := range nodes {
+		cfg := nodes[i].App.Config
+		m := models.New(nodes[i].App)
+		if i > 0 {
+			// the plugins, the price and the peer data run once,
+			// displayed with every node.
+			m.Plugins = c.nodes[0].models.Plugins
+			m.Price = c.nodes[0].models.Price
+			m.PeerData = c.nodes[0].models.PeerData
+		}
+		c.filter = channelsFilter(cfg.Views)
+		err := m.Channels.SetFilterExpr(c.filter)
+		if err != nil {
+			c.logger.Error("invalid filter of the channels", logging.Error(err))
+		}
+		v := views.New(cfg.Views, m)
+		if len(nodes) > 1 {
+			v.Header.Node = fmt.Sprintf("%s %d/%d", cfg.Network.Name, i+1, len(nodes))
+		}
+		v.Header.ReadOnly = c.readOnly
+		v.Message.ReadOnly = c.readOnly
+		c.nodes[i] = &node{
+			name:    cfg.Network.Name,
+			network: &cfg.Network,
+			models:  m,
+			views:   v,
+		}
+	}
+	c.setNode(0)
+	return c
+}
+
+func (c *controller) setNode(i int) {
+	c.current = i
+	c.network = c.nodes[i].network
+	c.models = c.nodes[i].models
+	c.views = c.nodes[i].views
+}
+
+// NextTheme switches the colors of the ui to the next built-in theme, the
+// views take them at the next layout.
+func (c *controller) NextTheme(g *gocui.Gui, v *gocui.View) error {
+	theme := color.NextTheme()
+	c.logger.Info("theme switched", logging.String("theme", theme.Name))
+	return nil
+}
+
+// NextNode displays the next node, the views of the node displayed are
+// deleted and laid out again with the ones of the next node.
+func (c *controller) NextNode(g *gocui.Gui, v *gocui.View) error {
+	if len(c.nodes) < 2 {
+		return nil
+	}
+	err := c.views.Main.Delete(g)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	names := []string{}
+	for _, v := range g.Views() {
+		names = append(names, v.Name())
+	}
+	for _, name := range names {
+		err := g.DeleteView(name)
+		if err != nil {
+			return err
+		}
+	}
+	c.setNode((c.current + 1) % len(c.nodes))
+	return nil
 }
@@ -164,3 +164,48 @@ func PageUp(v View) error {
 	}
 	return nil
 }
+
+// Click moves the cursor to the row y and to the column at x of the page,
+// the column is reached by steps of the speed of the view. The row is kept
+// if the view is empty.
+func Click(v View, x, y int) error {
+	if v == nil {
+		return nil
+	}
+	_, fs := v.Limits()
+	ox, oy := v.Origin()
+	cx, _ := v.Cursor()
+	if oy+y >= fs {
+		y = fs - 1 - oy
+	}
+	if y >= 0 {
+		err := v.SetCursor(cx, y)
+		if err != nil {
+			return err
+		}
+	}
+	target := ox + x
+	var err error
+	for {
+		ox, _ := v.Origin()
+		cx, _ := v.Cursor()
+		right, left, _, _ := v.Speed()
+		switch {
+		case right > 0 && ox+cx+right <= target:
+			err = Right(v)
+		case left > 0 && ox+cx > target:
+			err = Left(v)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		nx, _ := v.Origin()
+		ncx, _ := v.Cursor()
+		// the cursor is against a side of the view.
+		if nx+ncx == ox+cx {
+			return nil
+		}
+	}
+}
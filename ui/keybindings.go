@@ -2,125 +2,566 @@ package ui
 
 import (
 	"github.com/awesome-gocui/gocui"
+	netmodels "github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/ui/models"
+	"github.com/edouardparis/lntop/ui/views"
 )
 
 func quit(g *gocui.Gui, v *gocui.View) error {
 	return gocui.ErrQuit
 }
 
+// setKeybinding sets the keybinding with a handler touching the pubsub
+// first, the user is active.
+func (c *controller) setKeybinding(g *gocui.Gui, view string, key interface{}, mod gocui.Modifier,
+	handler func(*gocui.Gui, *gocui.View) error) error {
+	return g.SetKeybinding(view, key, mod, func(g *gocui.Gui, v *gocui.View) error {
+		if c.touch != nil {
+			c.touch()
+		}
+		return handler(g, v)
+	})
+}
+
+// mutating returns the handler of an action changing the node, ignored in
+// read-only mode.
+func (c *controller) mutating(handler func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if c.readOnly {
+			c.logger.Info("action disabled in read-only mode")
+			return nil
+		}
+		return handler(g, v)
+	}
+}
+
 func setKeyBinding(c *controller, g *gocui.Gui) error {
-	err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit)
+	keys, err := newKeymap(c.keys)
+	if err != nil {
+		return err
+	}
+	keys.bind("quit", quit)
+	keys.bind("up", c.cursorUp)
+	keys.bind("down", c.cursorDown)
+	keys.bind("left", c.cursorLeft)
+	keys.bind("right", c.cursorRight)
+	keys.bind("home", c.cursorHome)
+	keys.bind("end", c.cursorEnd)
+	keys.bind("page_down", c.cursorPageDown)
+	keys.bind("page_up", c.cursorPageUp)
+	keys.bind("enter", c.OnEnter)
+	keys.bind("menu", c.Menu)
+	keys.bind("sort_asc", c.Order(models.Asc))
+	keys.bind("sort_desc", c.Order(models.Desc))
+	keys.bind("sort_toggle", c.ToggleOrder)
+	keys.bind("node_info", c.NodeInfo)
+	keys.bind("acknowledge", c.Acknowledge)
+	keys.bind("invoice", c.mutating(c.ShowInvoice))
+	keys.bind("address", c.mutating(c.ShowAddress))
+	keys.bind("lndconnect", c.mutating(c.OpenLNDConnect))
+	keys.bind("decoder", c.OpenDecoder)
+	keys.bind("sign_message", c.OpenMessage)
+	keys.bind("next_node", c.NextNode)
+	keys.bind("next_theme", c.NextTheme)
+	keys.bind("open_channel", c.mutating(c.OpenChannelDialog))
+	keys.bind("batch_open", c.mutating(c.OpenBatchOpen))
+	keys.bind("backup", c.ExportChannelBackup)
+
+	keys.bindView("toggle_private", views.CHANNELS, c.TogglePrivate)
+	keys.bindView("export_channels", views.CHANNELS, c.ExportChannels)
+	keys.bindView("search", views.CHANNELS, c.OpenSearch)
+	keys.bindViewOr("next_match", views.CHANNELS, func(global func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+		return c.NextMatch(1, global)
+	})
+	keys.bindViewOr("previous_match", views.CHANNELS, func(global func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+		return c.NextMatch(-1, global)
+	})
+	keys.bindView("filter", views.CHANNELS, c.OpenFilter)
+	keys.bindView("sort_menu", views.CHANNELS, c.OpenSortMenu)
+	keys.bindView("rebalance", views.CHANNELS, c.mutating(c.OpenRebalance))
+	for _, name := range []string{views.CHANNELS, views.CHANNEL} {
+		keys.bindView("policy", name, c.mutating(c.OpenPolicy))
+		keys.bindView("note", name, c.OpenNote)
+		keys.bindView("close_channel", name, c.mutating(c.CloseChannelDialog))
+	}
+	for _, name := range []string{views.CHANNELS, views.TRANSACTIONS, views.ROUTING} {
+		keys.bindView("columns", name, c.OpenColumnChooser)
+	}
+	keys.bindView("column_toggle", views.COLUMN_CHOOSER, c.ToggleColumn)
+	keys.bindView("column_up", views.COLUMN_CHOOSER, c.MoveColumn(-1))
+	keys.bindView("column_down", views.COLUMN_CHOOSER, c.MoveColumn(1))
+	keys.bindView("column_wider", views.COLUMN_CHOOSER, c.ResizeColumn(1))
+	keys.bindView("column_narrower", views.COLUMN_CHOOSER, c.ResizeColumn(-1))
+	keys.bindView("sort_cycle", views.SORT_MENU, c.CycleSort)
+	keys.bindView("sort_up", views.SORT_MENU, c.MoveSort(-1))
+	keys.bindView("sort_down", views.SORT_MENU, c.MoveSort(1))
+	keys.bindView("lookup", views.GRAPH, c.OpenLookup)
+	keys.bindView("range", views.CLOSED, c.NextClosedRange)
+	keys.bindView("range", views.FWDINGHIST, c.NextFwdingHistRange)
+	keys.bindView("select", views.PEERS, c.TogglePeer)
+	keys.bindView("connect_peer", views.PEERS, c.mutating(c.OpenConnectPeer))
+	keys.bindView("disconnect_peer", views.PEERS, c.mutating(c.OpenDisconnectPeer))
+	keys.bindView("add_tower", views.TOWERS, c.mutating(c.OpenAddTower))
+	keys.bindView("remove_tower", views.TOWERS, c.mutating(c.OpenRemoveTower))
+	keys.bindView("create_offer", views.OFFERS, c.mutating(c.OpenCreateOffer))
+	keys.bindView("disable_offer", views.OFFERS, c.mutating(c.OpenDisableOffer))
+	keys.bindView("copy_offer", views.OFFER, c.CopyOffer)
+	keys.bindView("reset_mission", views.MISSIONCONTROL, c.mutating(c.OpenResetMission))
+	keys.bindView("export_mission", views.MISSIONCONTROL, c.ExportMissionControl)
+	keys.bindView("import_mission", views.MISSIONCONTROL, c.mutating(c.OpenImportMission))
+	keys.bindView("resume_htlc", views.FIREWALL, c.mutating(c.OpenResolveHTLC(netmodels.InterceptResume)))
+	keys.bindView("fail_htlc", views.FIREWALL, c.mutating(c.OpenResolveHTLC(netmodels.InterceptFail)))
+	keys.bindView("settle_htlc", views.FIREWALL, c.mutating(c.OpenResolveHTLC(netmodels.InterceptSettle)))
+	keys.bindView("create_invoice", views.INVOICES, c.mutating(c.OpenCreateInvoice))
+	keys.bindView("pay", views.PAYMENTS, c.mutating(c.OpenPay))
+	keys.bindView("keysend", views.PAYMENTS, c.mutating(c.OpenKeysend))
+	keys.bindView("bump_fee", views.SWEEPS, c.mutating(c.OpenBumpFee))
+	keys.bindView("select", views.UTXOS, c.ToggleUTXO)
+	keys.bindView("consolidate", views.UTXOS, c.mutating(c.OpenConsolidate))
+	keys.bindView("label", views.UTXOS, c.mutating(c.OpenLabel))
+	err = keys.set(c, g)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, "", gocui.MouseLeft, gocui.ModNone, c.mouseDown)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, "", gocui.MouseRelease, gocui.ModNone, c.mouseUp)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, "", gocui.MouseWheelDown, gocui.ModNone, c.wheel(true))
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyF10, gocui.ModNone, quit)
+	err = c.setKeybinding(g, "", gocui.MouseWheelUp, gocui.ModNone, c.wheel(false))
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'q', gocui.ModNone, quit)
+	for _, name := range c.views.OpenChannel.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.OpenChannel)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseOpenChannel)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextOpenChannelField)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range c.views.BatchOpen.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.BatchOpen)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseBatchOpen)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextBatchOpenField)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyCtrlX, gocui.ModNone, c.RemoveBatchChannel)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.DECODER_INPUT, gocui.KeyEnter, gocui.ModNone, c.Decode)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyArrowUp, gocui.ModNone, c.cursorUp)
+	err = c.setKeybinding(g, views.DECODER_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseDecoder)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'k', gocui.ModNone, c.cursorUp)
+	for _, name := range c.views.Message.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.SignOrVerifyMessage)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseMessage)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextMessageField)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyCtrlY, gocui.ModNone, c.CopySignature)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.QR, gocui.KeyEnter, gocui.ModNone, c.CloseQRCode)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyArrowDown, gocui.ModNone, c.cursorDown)
+	err = c.setKeybinding(g, views.QR, gocui.KeyEsc, gocui.ModNone, c.CloseQRCode)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'j', gocui.ModNone, c.cursorDown)
+	err = c.setKeybinding(g, views.LNDCONNECT, gocui.KeyEnter, gocui.ModNone, c.ShowLNDConnect)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyArrowLeft, gocui.ModNone, c.cursorLeft)
+	err = c.setKeybinding(g, views.LNDCONNECT, gocui.KeyEsc, gocui.ModNone, c.CloseLNDConnect)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'h', gocui.ModNone, c.cursorLeft)
+	err = c.setKeybinding(g, views.CHANNEL, gocui.KeyEsc, gocui.ModNone, c.CloseDetails)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyArrowRight, gocui.ModNone, c.cursorRight)
+	err = c.setKeybinding(g, views.TRANSACTION, gocui.KeyEsc, gocui.ModNone, c.CloseDetails)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'l', gocui.ModNone, c.cursorRight)
+	err = c.setKeybinding(g, views.NODE, gocui.KeyEsc, gocui.ModNone, c.CloseDetails)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyHome, gocui.ModNone, c.cursorHome)
+	err = c.setKeybinding(g, views.OFFER, gocui.KeyEsc, gocui.ModNone, c.CloseDetails)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'g', gocui.ModNone, c.cursorHome)
+	err = c.setKeybinding(g, views.COLUMN_CHOOSER, gocui.KeyEnter, gocui.ModNone, c.SaveColumns)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyEnd, gocui.ModNone, c.cursorEnd)
+	err = c.setKeybinding(g, views.COLUMN_CHOOSER, gocui.KeyEsc, gocui.ModNone, c.CloseColumnChooser)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'G', gocui.ModNone, c.cursorEnd)
+	err = c.setKeybinding(g, views.SORT_MENU, gocui.KeyEnter, gocui.ModNone, c.SaveSort)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyPgdn, gocui.ModNone, c.cursorPageDown)
+	err = c.setKeybinding(g, views.SORT_MENU, gocui.KeyEsc, gocui.ModNone, c.CloseSortMenu)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyPgup, gocui.ModNone, c.cursorPageUp)
+	err = c.setKeybinding(g, views.CHANNELS, gocui.KeyEsc, gocui.ModNone, c.ClearSearch)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyEnter, gocui.ModNone, c.OnEnter)
+	err = c.setKeybinding(g, views.SEARCH, gocui.KeyEnter, gocui.ModNone, c.CloseSearch)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.SEARCH, gocui.KeyEsc, gocui.ModNone, c.ClearSearch)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.GRAPH_LOOKUP, gocui.KeyEnter, gocui.ModNone, c.CloseLookup)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.GRAPH_LOOKUP, gocui.KeyEsc, gocui.ModNone, c.ClearLookup)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.FILTER_INPUT, gocui.KeyEnter, gocui.ModNone, c.ApplyFilter)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.FILTER_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseFilter)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.views.Rebalance.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.Rebalance)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseRebalance)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextRebalanceField)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range c.views.CloseChannel.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.CloseChannel)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CancelCloseChannel)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextCloseChannelField)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range c.views.Policy.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.UpdatePolicy)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.ClosePolicy)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextPolicyField)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range c.views.Note.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.UpdateNote)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseNote)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextNoteField)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.CONNECT_PEER_INPUT, gocui.KeyEnter, gocui.ModNone, c.ConnectPeer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.CONNECT_PEER_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseConnectPeer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.DISCONNECT_PEER, gocui.KeyEnter, gocui.ModNone, c.DisconnectPeer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.DISCONNECT_PEER, gocui.KeyEsc, gocui.ModNone, c.CloseDisconnectPeer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.ADD_TOWER_INPUT, gocui.KeyEnter, gocui.ModNone, c.AddTower)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.ADD_TOWER_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseAddTower)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.REMOVE_TOWER, gocui.KeyEnter, gocui.ModNone, c.RemoveTower)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.REMOVE_TOWER, gocui.KeyEsc, gocui.ModNone, c.CloseRemoveTower)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.views.CreateOffer.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.CreateOffer)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseCreateOffer)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextCreateOfferField)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.DISABLE_OFFER, gocui.KeyEnter, gocui.ModNone, c.DisableOffer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.DISABLE_OFFER, gocui.KeyEsc, gocui.ModNone, c.CloseDisableOffer)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.RESET_MISSION, gocui.KeyEnter, gocui.ModNone, c.ResetMission)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.RESET_MISSION, gocui.KeyEsc, gocui.ModNone, c.CloseResetMission)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.views.ImportMission.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.ImportMission)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseImportMission)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextImportMissionField)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.RESOLVE_HTLC, gocui.KeyEnter, gocui.ModNone, c.ResolveHTLC)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.RESOLVE_HTLC, gocui.KeyEsc, gocui.ModNone, c.CloseResolveHTLC)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.RESOLVE_HTLC_PREIMAGE, gocui.KeyEnter, gocui.ModNone, c.ResolveHTLC)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.RESOLVE_HTLC_PREIMAGE, gocui.KeyEsc, gocui.ModNone, c.CloseResolveHTLC)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.views.CreateInvoice.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.CreateInvoice)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseCreateInvoice)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextCreateInvoiceField)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.PAY_INPUT, gocui.KeyEnter, gocui.ModNone, c.Pay)
+	if err != nil {
+		return err
+	}
+
+	err = c.setKeybinding(g, views.PAY_INPUT, gocui.KeyEsc, gocui.ModNone, c.ClosePay)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.views.Keysend.Names() {
+		err = c.setKeybinding(g, name, gocui.KeyEnter, gocui.ModNone, c.Keysend)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyEsc, gocui.ModNone, c.CloseKeysend)
+		if err != nil {
+			return err
+		}
+
+		err = c.setKeybinding(g, name, gocui.KeyTab, gocui.ModNone, c.NextKeysendField)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.setKeybinding(g, views.BUMPFEE_INPUT, gocui.KeyEnter, gocui.ModNone, c.BumpFee)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", gocui.KeyF2, gocui.ModNone, c.Menu)
+	err = c.setKeybinding(g, views.BUMPFEE_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseBumpFee)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'm', gocui.ModNone, c.Menu)
+	err = c.setKeybinding(g, views.CONSOLIDATE_INPUT, gocui.KeyEnter, gocui.ModNone, c.Consolidate)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'a', gocui.ModNone, c.Order(models.Asc))
+	err = c.setKeybinding(g, views.CONSOLIDATE_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseConsolidate)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'd', gocui.ModNone, c.Order(models.Desc))
+	err = c.setKeybinding(g, views.LABEL_INPUT, gocui.KeyEnter, gocui.ModNone, c.SetLabel)
 	if err != nil {
 		return err
 	}
 
-	err = g.SetKeybinding("", 'c', gocui.ModNone, c.NodeInfo)
+	err = c.setKeybinding(g, views.LABEL_INPUT, gocui.KeyEsc, gocui.ModNone, c.CloseLabel)
 	if err != nil {
 		return err
 	}
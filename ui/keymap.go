@@ -0,0 +1,334 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+)
+
+// sequenceDelay is the time within which the second key of a sequence,
+// e.g. gg, must be pressed.
+const sequenceDelay = time.Second
+
+// profiles are the keys of the actions by profile, the global ones and
+// the ones of the views. A key is a character, a name of gocui.Parse like
+// f2, pgdn or ctrl+d, or a sequence of two characters like gg for a global
+// action.
+var profiles = map[string]map[string][]string{
+	"default": {
+		"quit":         {"ctrl+c", "f10", "q"},
+		"up":           {"up", "k"},
+		"down":         {"down", "j"},
+		"left":         {"left", "h"},
+		"right":        {"right", "l"},
+		"home":         {"home", "g"},
+		"end":          {"end", "G"},
+		"page_down":    {"pgdn"},
+		"page_up":      {"pgup"},
+		"enter":        {"enter"},
+		"menu":         {"f2", "m"},
+		"sort_asc":     {"a"},
+		"sort_desc":    {"d"},
+		"sort_toggle":  {"s"},
+		"node_info":    {"c"},
+		"acknowledge":  {"A"},
+		"invoice":      {"I"},
+		"address":      {"N"},
+		"lndconnect":   {"C"},
+		"decoder":      {"D"},
+		"sign_message": {"M"},
+		"next_node":    {"n"},
+		"next_theme":   {"T"},
+		"open_channel": {"o"},
+		"batch_open":   {"O"},
+		"backup":       {"B"},
+
+		"toggle_private":  {"p"},
+		"export_channels": {"E"},
+		"policy":          {"f"},
+		"note":            {"t"},
+		"columns":         {"v"},
+		"column_toggle":   {"space"},
+		"column_up":       {"K"},
+		"column_down":     {"J"},
+		"column_wider":    {"+"},
+		"column_narrower": {"-"},
+		"sort_menu":       {"S"},
+		"sort_cycle":      {"space"},
+		"sort_up":         {"K"},
+		"sort_down":       {"J"},
+		"search":          {"/"},
+		"next_match":      {"n"},
+		"previous_match":  {"N"},
+		"filter":          {"F"},
+		"rebalance":       {"r"},
+		"close_channel":   {"x"},
+		"range":           {"t"},
+		"select":          {"space"},
+		"connect_peer":    {"c"},
+		"disconnect_peer": {"x"},
+		"lookup":          {"/"},
+		"add_tower":       {"c"},
+		"remove_tower":    {"x"},
+		"create_offer":    {"c"},
+		"disable_offer":   {"x"},
+		"copy_offer":      {"ctrl+y"},
+		"reset_mission":   {"X"},
+		"export_mission":  {"E"},
+		"import_mission":  {"L"},
+		"resume_htlc":     {"r"},
+		"fail_htlc":       {"x"},
+		"settle_htlc":     {"s"},
+		"create_invoice":  {"c"},
+		"pay":             {"p"},
+		"keysend":         {"k"},
+		"bump_fee":        {"b"},
+		"consolidate":     {"x"},
+		"label":           {"L"},
+	},
+}
+
+func init() {
+	vim := make(map[string][]string)
+	for action, keys := range profiles["default"] {
+		vim[action] = keys
+	}
+	vim["home"] = []string{"home", "gg"}
+	vim["page_down"] = []string{"pgdn", "ctrl+d", "ctrl+f"}
+	vim["page_up"] = []string{"pgup", "ctrl+u", "ctrl+b"}
+	profiles["vim"] = vim
+}
+
+// keyAliases are the names of the keys accepted besides the ones of
+// gocui.Parse.
+var keyAliases = map[string]string{
+	"up":     "arrow+up",
+	"down":   "arrow+down",
+	"left":   "arrow+left",
+	"right":  "arrow+right",
+	"escape": "esc",
+	"pgdown": "pgdn",
+}
+
+// viewAction is the handler of an action in a view, built with the
+// handler of the same key in the other views, nil if it has none.
+type viewAction struct {
+	action  string
+	view    string
+	handler func(global func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error
+}
+
+// keymap is the keys of the actions and their handlers.
+type keymap struct {
+	keys     map[string][]string
+	handlers map[string]func(*gocui.Gui, *gocui.View) error
+	// views are the handlers of the actions of the views.
+	views []viewAction
+	// bound are the handlers of the keys set, by key.
+	bound map[string]func(*gocui.Gui, *gocui.View) error
+	// pending is the first key of a sequence pressed at pendingAt.
+	pending   string
+	pendingAt time.Time
+}
+
+// newKeymap returns the keymap of the profile of the config, default if
+// empty, with the keys of the actions of the config replacing the ones of
+// the profile.
+func newKeymap(cfg config.Keys) (*keymap, error) {
+	name := cfg.Profile
+	if name == "" {
+		name = "default"
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, errors.Errorf("unknown keys profile %q", name)
+	}
+	k := &keymap{
+		keys:     make(map[string][]string),
+		handlers: make(map[string]func(*gocui.Gui, *gocui.View) error),
+		bound:    make(map[string]func(*gocui.Gui, *gocui.View) error),
+	}
+	for action, keys := range profile {
+		k.keys[action] = keys
+	}
+	for action, keys := range cfg.Actions {
+		if _, ok := profile[action]; !ok {
+			return nil, errors.Errorf("unknown action %q of keys", action)
+		}
+		k.keys[action] = keys
+	}
+	return k, nil
+}
+
+// bind sets the handler of the global action.
+func (k *keymap) bind(action string, handler func(*gocui.Gui, *gocui.View) error) {
+	k.handlers[action] = handler
+}
+
+// bindView sets the handler of the action in the view, its keys replace
+// the ones of the global actions there.
+func (k *keymap) bindView(action, view string, handler func(*gocui.Gui, *gocui.View) error) {
+	k.bindViewOr(action, view, func(func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+		return handler
+	})
+}
+
+// bindViewOr sets the handler of the action in the view built with the
+// handler of the global action of the same key, e.g. to fall back to it.
+func (k *keymap) bindViewOr(action, view string,
+	handler func(global func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error) {
+	k.views = append(k.views, viewAction{action: action, view: view, handler: handler})
+}
+
+// set sets the keybindings of the actions bound. A key starting a sequence
+// waits for the next one, even if it is also the key of an action. The
+// actions of the views are set after the global ones.
+func (k *keymap) set(c *controller, g *gocui.Gui) error {
+	singles := make(map[string]func(*gocui.Gui, *gocui.View) error)
+	// sequences are the handlers of the sequences by second and first
+	// key.
+	sequences := make(map[string]map[string]func(*gocui.Gui, *gocui.View) error)
+	prefixes := make(map[string]bool)
+
+	actions := make([]string, 0, len(k.handlers))
+	for action := range k.handlers {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	owners := make(map[string]string)
+	for _, action := range actions {
+		for _, key := range k.keys[action] {
+			if owner, ok := owners[key]; ok {
+				return errors.Errorf("key %q of %q and %q", key, owner, action)
+			}
+			owners[key] = action
+			if isSequence(key) {
+				runes := []rune(key)
+				first, second := string(runes[0]), string(runes[1])
+				if sequences[second] == nil {
+					sequences[second] = make(map[string]func(*gocui.Gui, *gocui.View) error)
+				}
+				sequences[second][first] = k.handlers[action]
+				prefixes[first] = true
+				continue
+			}
+			singles[key] = k.handlers[action]
+		}
+	}
+
+	keys := make(map[string]bool)
+	for key := range singles {
+		keys[key] = true
+	}
+	for key := range sequences {
+		keys[key] = true
+	}
+	for key := range prefixes {
+		keys[key] = true
+	}
+	for key := range keys {
+		gk, mod, err := parseKey(key)
+		if err != nil {
+			return err
+		}
+		handler := k.dispatch(key, singles[key], sequences[key], prefixes[key])
+		err = c.setKeybinding(g, "", gk, mod, handler)
+		if err != nil {
+			return err
+		}
+		k.bound[key] = handler
+	}
+	return k.setViews(c, g)
+}
+
+// setViews sets the keybindings of the actions of the views, a key is the
+// one of a single action in a view.
+func (k *keymap) setViews(c *controller, g *gocui.Gui) error {
+	owners := make(map[[2]string]string)
+	for _, a := range k.views {
+		for _, key := range k.keys[a.action] {
+			id := [2]string{a.view, key}
+			if owner, ok := owners[id]; ok && owner != a.action {
+				return errors.Errorf("key %q of %q and %q in the %s view", key, owner, a.action, a.view)
+			}
+			owners[id] = a.action
+			gk, mod, err := parseKey(key)
+			if err != nil {
+				return err
+			}
+			err = c.setKeybinding(g, a.view, gk, mod, a.handler(k.bound[key]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dispatch returns the handler of a key: it ends the sequence pending, or
+// starts one, or runs the action of the key alone.
+func (k *keymap) dispatch(key string, single func(*gocui.Gui, *gocui.View) error,
+	sequences map[string]func(*gocui.Gui, *gocui.View) error, prefix bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		pending := k.pending
+		k.pending = ""
+		if pending != "" && time.Since(k.pendingAt) < sequenceDelay {
+			if handler, ok := sequences[pending]; ok {
+				return handler(g, v)
+			}
+		}
+		if prefix {
+			k.pending = key
+			k.pendingAt = time.Now()
+			return nil
+		}
+		if single != nil {
+			return single(g, v)
+		}
+		return nil
+	}
+}
+
+// isSequence returns whether the key is a sequence of two characters
+// rather than the name of a key.
+func isSequence(key string) bool {
+	if utf8.RuneCountInString(key) != 2 {
+		return false
+	}
+	_, _, err := parseKey(key)
+	return err != nil
+}
+
+// parseKey returns the gocui key of a key of the keymap, the name of a
+// key may start with alt+.
+func parseKey(key string) (interface{}, gocui.Modifier, error) {
+	if utf8.RuneCountInString(key) == 1 {
+		r, _ := utf8.DecodeRuneInString(key)
+		return r, gocui.ModNone, nil
+	}
+	name := strings.ToLower(strings.Replace(key, "-", "+", -1))
+	mod := gocui.ModNone
+	if strings.HasPrefix(name, "alt+") && len(name) > len("alt+") {
+		mod = gocui.ModAlt
+		name = strings.TrimPrefix(name, "alt+")
+		if utf8.RuneCountInString(name) == 1 {
+			// the case of the character is kept.
+			r, _ := utf8.DecodeRuneInString(key[len(key)-len(name):])
+			return r, mod, nil
+		}
+	}
+	if alias, ok := keyAliases[name]; ok {
+		name = alias
+	}
+	gk, _, err := gocui.Parse(name)
+	if err != nil {
+		return nil, gocui.ModNone, errors.Errorf("unknown key %q", key)
+	}
+	return gk, mod, nil
+}
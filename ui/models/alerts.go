@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edouardparis/lntop/alerts"
+)
+
+// Alerts is the list of the raised alerts, not yet resolved, and of the
+// critical alerts pinned until acknowledged.
+type Alerts struct {
+	list   []*alerts.Alert
+	pinned []*alerts.Alert
+	mu     sync.RWMutex
+}
+
+func (a *Alerts) List() []*alerts.Alert {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]*alerts.Alert{}, a.list...)
+}
+
+func (a *Alerts) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.list)
+}
+
+// Rule returns the raised alerts of the rule.
+func (a *Alerts) Rule(name string) []*alerts.Alert {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	list := []*alerts.Alert{}
+	for i := range a.list {
+		if a.list[i].Rule == name {
+			list = append(list, a.list[i])
+		}
+	}
+	return list
+}
+
+// Pinned returns the critical alerts not yet acknowledged, resolved or
+// not.
+func (a *Alerts) Pinned() []*alerts.Alert {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]*alerts.Alert{}, a.pinned...)
+}
+
+func (a *Alerts) Acknowledge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pinned = nil
+}
+
+func (a *Alerts) pin(alert *alerts.Alert) {
+	for i := range a.pinned {
+		if a.pinned[i].Key == alert.Key {
+			return
+		}
+	}
+	a.pinned = append(a.pinned, alert)
+}
+
+func (a *Alerts) update(alert *alerts.Alert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if alert.Level == alerts.Critical && !alert.Resolved {
+		a.pin(alert)
+	}
+	for i := range a.list {
+		if a.list[i].Key == alert.Key {
+			if alert.Resolved {
+				a.list = append(a.list[:i], a.list[i+1:]...)
+			} else {
+				a.list[i] = alert
+			}
+			return
+		}
+	}
+	if !alert.Resolved {
+		a.list = append(a.list, alert)
+	}
+}
+
+func (m *Models) RefreshAlerts(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		alert, ok := update.(*alerts.Alert)
+		if !ok {
+			m.logger.Error("refreshAlerts: invalid event data")
+			return nil
+		}
+		m.Alerts.update(alert)
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/store"
+)
+
+// nodeAliases are the aliases of the nodes of the channels recorded in the
+// store, displayed until the nodes are fetched. The ones younger than ttl
+// are not fetched again.
+type nodeAliases struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	forced  config.Aliases
+	aliases map[string]*store.NodeAlias
+}
+
+// node returns the node of the alias recorded for the pubkey, nil if
+// there is none.
+func (a *nodeAliases) node(pubkey string) *models.Node {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alias, ok := a.aliases[pubkey]
+	if !ok {
+		return nil
+	}
+	return &models.Node{
+		PubKey:      pubkey,
+		Alias:       alias.Alias,
+		ForcedAlias: a.forced[pubkey],
+		Cached:      true,
+	}
+}
+
+// expired returns true if the alias recorded for the pubkey is to be
+// fetched again.
+func (a *nodeAliases) expired(pubkey string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alias, ok := a.aliases[pubkey]
+	return !ok || time.Since(alias.Fetched) > a.ttl
+}
+
+// fetched sets the aliases of the nodes fetched now and returns them.
+func (a *nodeAliases) fetched(nodes []*models.Node) map[string]*store.NodeAlias {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	aliases := map[string]*store.NodeAlias{}
+	now := time.Now()
+	for _, n := range nodes {
+		alias := &store.NodeAlias{Alias: n.Alias, Fetched: now}
+		a.aliases[n.PubKey] = alias
+		aliases[n.PubKey] = alias
+	}
+	return aliases
+}
+
+// LoadNodeAliases reads the aliases of the nodes of the channels recorded
+// in the store, if any.
+func (m *Models) LoadNodeAliases(cfg config.Store, forced config.Aliases) error {
+	ttl := cfg.AliasCache()
+	if m.store == nil || ttl == 0 {
+		return nil
+	}
+	aliases, err := m.store.NodeAliases()
+	if err != nil {
+		return err
+	}
+	m.aliases = &nodeAliases{ttl: ttl, forced: forced, aliases: aliases}
+	return nil
+}
+
+// cachedNode returns the node of the alias recorded for the pubkey, nil if
+// there is none or if the aliases are not recorded.
+func (m *Models) cachedNode(pubkey string) *models.Node {
+	if m.aliases == nil {
+		return nil
+	}
+	return m.aliases.node(pubkey)
+}
+
+// nodeExpired returns true if the node is missing or is the alias recorded
+// and the alias is to be fetched again.
+func (m *Models) nodeExpired(node *models.Node) bool {
+	return node == nil || node.Cached && m.aliases.expired(node.PubKey)
+}
+
+// recordAliases records the aliases of the nodes fetched in the store.
+func (m *Models) recordAliases(nodes []*models.Node) {
+	if m.aliases == nil || len(nodes) == 0 {
+		return
+	}
+	err := m.store.SetNodeAliases(m.aliases.fetched(nodes))
+	if err != nil {
+		m.logger.Error("cannot record the aliases of the nodes", logging.Error(err))
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/backup"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// ChannelBackup is the last static channel backup of all the channels
+// written by the node, and the result of its last export.
+type ChannelBackup struct {
+	backup *models.ChannelBackup
+	// updated is the time the backup was received, or exported from the
+	// node at start.
+	updated   time.Time
+	exported  time.Time
+	exportErr error
+	exporting bool
+	mu        sync.RWMutex
+}
+
+// Get returns the last backup and the time it was received, nil if
+// there is none yet.
+func (b *ChannelBackup) Get() (*models.ChannelBackup, time.Time) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.backup, b.updated
+}
+
+// Exported returns the time of the last export and its error, zero if
+// the backup was not exported. exporting is true while an export runs.
+func (b *ChannelBackup) Exported() (exported time.Time, exporting bool, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.exported, b.exporting, b.exportErr
+}
+
+func (b *ChannelBackup) set(backup *models.ChannelBackup) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.backup = backup
+	b.updated = time.Now()
+}
+
+// RefreshChannelBackup exports the current backup from the node, before
+// the first one is sent.
+func (m *Models) RefreshChannelBackup(ctx context.Context) error {
+	snapshot, err := m.network.ExportChannelBackup(ctx)
+	if err != nil {
+		return err
+	}
+	m.ChannelBackup.set(snapshot)
+	return nil
+}
+
+// SetChannelBackup sets the backup of a ChannelBackupUpdated event.
+func (m *Models) SetChannelBackup(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		snapshot, ok := update.(*models.ChannelBackup)
+		if !ok {
+			return nil
+		}
+		m.ChannelBackup.set(snapshot)
+		return nil
+	}
+}
+
+// ExportChannelBackup uploads the current backup of the node to the
+// export destination of the config.
+func (m *Models) ExportChannelBackup(ctx context.Context) error {
+	b := m.ChannelBackup
+	b.mu.Lock()
+	if b.exporting {
+		b.mu.Unlock()
+		return nil
+	}
+	b.exporting = true
+	b.mu.Unlock()
+
+	err := m.exportChannelBackup(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.exporting = false
+	b.exported = time.Now()
+	b.exportErr = err
+	return err
+}
+
+func (m *Models) exportChannelBackup(ctx context.Context) error {
+	if m.backupExport == nil {
+		return errors.New("no export destination in [backup]")
+	}
+	snapshot, err := m.network.ExportChannelBackup(ctx)
+	if err != nil {
+		return err
+	}
+	m.ChannelBackup.set(snapshot)
+	return m.backupExport.Upload(ctx, backup.FileName(time.Now()), snapshot.Multi)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/store"
+)
+
+// BalancePeriod is the period of the samples of the balances read from the
+// store.
+const BalancePeriod = 7 * 24 * time.Hour
+
+// Balances returns the samples of the balances of the channel of the
+// period, oldest first, none without a store.
+func (c *Channels) Balances(channelPoint string) []*store.BalanceSample {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+	return c.balances[channelPoint]
+}
+
+func (c *Channels) setBalances(balances map[string][]*store.BalanceSample) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.balances = balances
+}
+
+// BalanceInterval returns the interval between two samples of the balances,
+// zero if they are not sampled in the store.
+func (m *Models) BalanceInterval() time.Duration {
+	if m.store == nil {
+		return 0
+	}
+	return m.balanceInterval
+}
+
+// RefreshBalances reads the samples of the balances of the period from the
+// store, the last ones are kept if it fails.
+func (m *Models) RefreshBalances() error {
+	if m.store == nil {
+		return nil
+	}
+	balances, err := m.store.Balances(time.Now().Add(-BalancePeriod))
+	if err != nil {
+		return err
+	}
+	m.Channels.setBalances(balances)
+	return nil
+}
@@ -2,26 +2,129 @@ package models
 
 import (
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
+	"github.com/edouardparis/lntop/store"
 )
 
 type ChannelsSort func(*models.Channel, *models.Channel) bool
 
+// ChannelsFilter returns true if the channel must be displayed.
+type ChannelsFilter func(*models.Channel) bool
+
 type Channels struct {
 	current     *models.Channel
 	index       map[string]*models.Channel
 	list        []*models.Channel
 	sort        ChannelsSort
+	filters     map[string]ChannelsFilter
 	mu          sync.RWMutex
 	CurrentNode *models.Node
+	// CurrentHistory are the policy changes of the current channel,
+	// oldest first.
+	CurrentHistory []*PolicyPeriod
+	// health are the scores of the channels by channel point, with its
+	// own lock as the channels are sorted by score under mu.
+	health   map[string]int
+	healthMu sync.RWMutex
+	// stats are the fees and volumes of the channels aggregated from
+	// the forwarding history, under healthMu.
+	stats stats.Report
+	// balances are the samples of the balances of the channels by
+	// channel point, under healthMu.
+	balances map[string][]*store.BalanceSample
+	// uptimes are the uptimes of the peers by pubkey, recorded in the
+	// store at uptimeRecorded, under healthMu.
+	uptimes        map[string]*store.PeerUptime
+	uptimeRecorded time.Time
+	// notes are the tags and the notes of the channels by channel point,
+	// under mu.
+	notes map[string]*store.ChannelNote
+	// search is the query of the search filter and expr the expression
+	// of the expression filter, under mu.
+	search string
+	expr   string
+	// stale are the channel points of the channels whose info and node
+	// are to be fetched, visible the ones of the rows displayed, fetched
+	// first, under mu.
+	stale   map[string]bool
+	visible []string
 }
 
 func (c *Channels) List() []*models.Channel {
 	return c.list
 }
 
+// SetFilter registers the filter under the given key, a nil filter
+// removes it. A channel is displayed only if it passes every filter.
+func (c *Channels) SetFilter(key string, f ChannelsFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f == nil {
+		delete(c.filters, key)
+		return
+	}
+	c.filters[key] = f
+}
+
+// privateFilter is the key of the filter hiding the private channels.
+const privateFilter = "private"
+
+// TogglePrivate hides the private channels, or shows them again.
+func (c *Channels) TogglePrivate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.filters[privateFilter]; ok {
+		delete(c.filters, privateFilter)
+		return
+	}
+	c.filters[privateFilter] = func(ch *models.Channel) bool {
+		return !ch.Private
+	}
+}
+
+// PrivateHidden returns true if the private channels are hidden.
+func (c *Channels) PrivateHidden() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.filters[privateFilter]
+	return ok
+}
+
+func (c *Channels) match(channel *models.Channel) bool {
+	for _, f := range c.filters {
+		if !f(channel) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filtered returns the channels passing the filters, in sort order.
+func (c *Channels) Filtered() []*models.Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.filters) == 0 {
+		return c.list
+	}
+	list := make([]*models.Channel, 0, len(c.list))
+	for i := range c.list {
+		if c.match(c.list[i]) {
+			list = append(list, c.list[i])
+		}
+	}
+	return list
+}
+
+func (c *Channels) FilteredLen() int {
+	return len(c.Filtered())
+}
+
 func (c *Channels) Len() int {
 	return len(c.list)
 }
@@ -42,6 +145,40 @@ func (c *Channels) Sort(s ChannelsSort) {
 	sort.Sort(c)
 }
 
+// ClearSort keeps the current order of the channels, the new ones are
+// added at the end.
+func (c *Channels) ClearSort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sort = nil
+}
+
+// resort sorts the channels again with the last sort, once their values
+// are refreshed.
+func (c *Channels) resort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sort != nil {
+		sort.Sort(c)
+	}
+}
+
+// ChannelsSortBy sorts the channels by the first sort, then the channels
+// equal by it by the next one.
+func ChannelsSortBy(sorts ...ChannelsSort) ChannelsSort {
+	return func(c1, c2 *models.Channel) bool {
+		for _, s := range sorts {
+			if s(c1, c2) {
+				return true
+			}
+			if s(c2, c1) {
+				return false
+			}
+		}
+		return false
+	}
+}
+
 func (c *Channels) Current() *models.Channel {
 	return c.current
 }
@@ -50,12 +187,100 @@ func (c *Channels) SetCurrent(index int) {
 	c.current = c.Get(index)
 }
 
+// SetCurrentChannel sets the current channel, e.g. a channel of another
+// view.
+func (c *Channels) SetCurrentChannel(channel *models.Channel) {
+	c.current = channel
+}
+
+// Get returns the channel at the index of the filtered list.
 func (c *Channels) Get(index int) *models.Channel {
-	if index < 0 || index > len(c.list)-1 {
+	list := c.Filtered()
+	if index < 0 || index > len(list)-1 {
 		return nil
 	}
 
-	return c.list[index]
+	return list[index]
+}
+
+// Health returns the health score of the channel, false if it is not
+// computed yet.
+func (c *Channels) Health(chanPoint string) (int, bool) {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+	score, ok := c.health[chanPoint]
+	return score, ok
+}
+
+func (c *Channels) setHealth(health map[string]int) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.health = health
+}
+
+// Stats returns the fees and volumes of the channel over the windows of
+// the stats, zero without forwards.
+func (c *Channels) Stats(id uint64) stats.Channel {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+	return c.stats.Channel(id)
+}
+
+func (c *Channels) setStats(r stats.Report) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.stats = r
+}
+
+// setNote sets the tags and the note of the channel of the channel point.
+func (c *Channels) setNote(channelPoint string, note *store.ChannelNote) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notes[channelPoint] = note
+	if ch, ok := c.index[channelPoint]; ok {
+		ch.Tags, ch.Note = note.Tags, note.Note
+	}
+}
+
+// applyNote sets the recorded tags and note on the channel.
+func (c *Channels) applyNote(ch *models.Channel) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if n, ok := c.notes[ch.ChannelPoint]; ok {
+		ch.Tags, ch.Note = n.Tags, n.Note
+	}
+}
+
+// setAges sets the ages of the channels at the height of the last block.
+func (c *Channels) setAges(height uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ch := range c.list {
+		ch.Age = ch.AgeAt(height)
+	}
+}
+
+// Find returns the open channel of the short channel id, channel id,
+// channel point, remote pubkey or alias, nil if there is none.
+func (c *Channels) Find(key string) *models.Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+	for _, ch := range c.list {
+		if ch.Status != models.ChannelActive && ch.Status != models.ChannelInactive {
+			continue
+		}
+		alias, _ := ch.ShortAlias()
+		if key == models.ToScid(ch.ID) || key == strconv.FormatUint(ch.ID, 10) ||
+			key == ch.ChannelPoint || key == ch.RemotePubKey ||
+			strings.EqualFold(key, alias) {
+			return ch
+		}
+	}
+	return nil
 }
 
 func (c *Channels) GetByChanPoint(chanPoint string) *models.Channel {
@@ -77,6 +302,19 @@ func (c *Channels) Add(channel *models.Channel) {
 	c.list = append(c.list, channel)
 }
 
+// UpdateHTLCs replaces the HTLCs in flight of the channel, if it is
+// known.
+func (c *Channels) UpdateHTLCs(newChannel *models.Channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	oldChannel, ok := c.index[newChannel.ChannelPoint]
+	if !ok {
+		return
+	}
+	oldChannel.PendingHTLC = newChannel.PendingHTLC
+	oldChannel.UnsettledBalance = newChannel.UnsettledBalance
+}
+
 func (c *Channels) Update(newChannel *models.Channel) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -105,7 +343,16 @@ func (c *Channels) Update(newChannel *models.Channel) {
 	oldChannel.Private = newChannel.Private
 	oldChannel.PendingHTLC = newChannel.PendingHTLC
 	oldChannel.Age = newChannel.Age
+	oldChannel.PingTime = newChannel.PingTime
+	oldChannel.Uptime = newChannel.Uptime
+	oldChannel.Lifetime = newChannel.Lifetime
 	oldChannel.BlocksTilMaturity = newChannel.BlocksTilMaturity
+	oldChannel.FundingExpiryBlocks = newChannel.FundingExpiryBlocks
+	oldChannel.CloseType = newChannel.CloseType
+	oldChannel.Closing = newChannel.Closing
+	oldChannel.Anchors = newChannel.Anchors
+	oldChannel.Tags = newChannel.Tags
+	oldChannel.Note = newChannel.Note
 
 	if newChannel.LastUpdate != nil {
 		oldChannel.LastUpdate = newChannel.LastUpdate
@@ -120,9 +367,72 @@ func (c *Channels) Update(newChannel *models.Channel) {
 	}
 }
 
+// SetVisible records the channels of the rows displayed, their info is
+// fetched before the one of the others.
+func (c *Channels) SetVisible(channels []*models.Channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.visible = c.visible[:0]
+	for _, ch := range channels {
+		c.visible = append(c.visible, ch.ChannelPoint)
+	}
+}
+
+func (c *Channels) markStale(chanPoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stale[chanPoint] = true
+}
+
+// StaleLen returns the number of channels whose info is to be fetched.
+func (c *Channels) StaleLen() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.stale)
+}
+
+// takeStale returns up to n channels whose info is to be fetched, the
+// visible ones first, and no longer counts them as stale. Only the visible
+// ones are returned if visibleOnly is true, the first ones of the list if
+// no row is displayed yet.
+func (c *Channels) takeStale(n int, visibleOnly bool) []*models.Channel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channels := []*models.Channel{}
+	take := func(chanPoint string) {
+		ch, ok := c.index[chanPoint]
+		if !ok || !c.stale[chanPoint] || len(channels) == n {
+			return
+		}
+		delete(c.stale, chanPoint)
+		channels = append(channels, ch)
+	}
+	for _, chanPoint := range c.visible {
+		take(chanPoint)
+	}
+	if visibleOnly && len(c.visible) > 0 {
+		return channels
+	}
+	for _, ch := range c.list {
+		take(ch.ChannelPoint)
+	}
+	// the stale channels no longer listed are closed.
+	for chanPoint := range c.stale {
+		if _, ok := c.index[chanPoint]; !ok {
+			delete(c.stale, chanPoint)
+		}
+	}
+	return channels
+}
+
 func NewChannels() *Channels {
 	return &Channels{
-		list:  []*models.Channel{},
-		index: make(map[string]*models.Channel),
+		list:    []*models.Channel{},
+		index:   make(map[string]*models.Channel),
+		filters: make(map[string]ChannelsFilter),
+		health:  make(map[string]int),
+		notes:   make(map[string]*store.ChannelNote),
+		stale:   make(map[string]bool),
+		uptimes: make(map[string]*store.PeerUptime),
 	}
 }
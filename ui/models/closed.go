@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// ClosedRanges are the time ranges of the closed channels, zero for all
+// of them.
+var ClosedRanges = []time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+	0,
+}
+
+// defaultClosedRange is the index of 30 days.
+const defaultClosedRange = 2
+
+type ClosedChannelsSort func(*models.ClosedChannel, *models.ClosedChannel) bool
+
+type ClosedChannels struct {
+	list  []*models.ClosedChannel
+	sort  ClosedChannelsSort
+	rng   int
+	nodes map[string]*models.Node
+	mu    sync.RWMutex
+}
+
+// Range returns the time range of the closed channels listed.
+func (c *ClosedChannels) Range() time.Duration {
+	return ClosedRanges[c.rng]
+}
+
+// NextRange selects the next time range.
+func (c *ClosedChannels) NextRange() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rng = (c.rng + 1) % len(ClosedRanges)
+}
+
+// List returns the channels closed in the time range.
+func (c *ClosedChannels) List() []*models.ClosedChannel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return InRange(c.list, ClosedRanges[c.rng])
+}
+
+// All returns the channels closed, whatever the time range.
+func (c *ClosedChannels) All() []*models.ClosedChannel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list
+}
+
+func (c *ClosedChannels) Len() int {
+	return len(c.List())
+}
+
+func (c *ClosedChannels) Sort(s ClosedChannelsSort) {
+	if s == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sort = s
+	sort.SliceStable(c.list, func(i, j int) bool { return s(c.list[i], c.list[j]) })
+}
+
+func (c *ClosedChannels) Update(list []*models.ClosedChannel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.list = list
+	if c.sort != nil {
+		sort.SliceStable(c.list, func(i, j int) bool { return c.sort(c.list[i], c.list[j]) })
+	}
+}
+
+func NewClosedChannels() *ClosedChannels {
+	return &ClosedChannels{
+		list:  []*models.ClosedChannel{},
+		rng:   defaultClosedRange,
+		nodes: make(map[string]*models.Node),
+	}
+}
+
+// InRange returns the channels closed for less than d, all of them if
+// d is zero.
+func InRange(list []*models.ClosedChannel, d time.Duration) []*models.ClosedChannel {
+	if d == 0 {
+		return list
+	}
+	since := time.Now().Add(-d)
+	result := make([]*models.ClosedChannel, 0, len(list))
+	for _, c := range list {
+		if c.CloseTime.After(since) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// CloseBreakdown counts the closed channels by type and by initiator of
+// the close, with the closing fees paid by the node.
+type CloseBreakdown struct {
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"by_type"`
+	ByInitiator map[string]int `json:"by_initiator"`
+	ClosingFees int64          `json:"closing_fees"`
+}
+
+func NewCloseBreakdown(list []*models.ClosedChannel) *CloseBreakdown {
+	b := &CloseBreakdown{
+		ByType:      make(map[string]int),
+		ByInitiator: make(map[string]int),
+	}
+	for _, c := range list {
+		b.Total++
+		name := models.CloseTypeName(c.CloseType)
+		if name == "" {
+			name = "unknown"
+		}
+		b.ByType[name]++
+		b.ByInitiator[models.InitiatorName(c.CloseInitiator)]++
+		b.ClosingFees += c.ClosingFee
+	}
+	return b
+}
+
+// RefreshClosedChannels lists the closed channels with the aliases of
+// their nodes, the date of a close without a wallet transaction is
+// estimated from its height.
+func (m *Models) RefreshClosedChannels(ctx context.Context) error {
+	list, err := m.network.ClosedChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range list {
+		if c.CloseTime.IsZero() && m.Info.Info != nil && m.Info.BlockHeight >= c.CloseHeight {
+			blocks := m.Info.BlockHeight - c.CloseHeight
+			c.CloseTime = time.Now().Add(-time.Duration(blocks) * 10 * time.Minute)
+		}
+		node, ok := m.ClosedChannels.nodes[c.RemotePubKey]
+		if !ok {
+			node, err = m.network.GetNode(ctx, c.RemotePubKey, false)
+			if err != nil {
+				m.logger.Debug("refreshClosedChannels: cannot find Node",
+					logging.String("pubkey", c.RemotePubKey))
+			} else {
+				m.ClosedChannels.nodes[c.RemotePubKey] = node
+			}
+		}
+		c.Node = node
+	}
+
+	m.ClosedChannels.Update(list)
+	return nil
+}
+
+// RangeName returns the label of a time range.
+func RangeName(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "all"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
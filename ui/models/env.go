@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/edouardparis/lntop/expr"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// ChannelEnv returns the identifiers of the channel for the expressions
+// of the config.
+func ChannelEnv(ch *models.Channel) expr.Env {
+	alias, _ := ch.ShortAlias()
+	env := expr.Env{
+		"id":                    float64(ch.ID),
+		"status":                ch.StatusName(),
+		"active":                ch.Status == models.ChannelActive,
+		"private":               ch.Private,
+		"alias":                 alias,
+		"pubkey":                ch.RemotePubKey,
+		"channel_point":         ch.ChannelPoint,
+		"capacity":              float64(ch.Capacity),
+		"local_balance":         float64(ch.LocalBalance),
+		"remote_balance":        float64(ch.RemoteBalance),
+		"local":                 float64(ch.LocalBalance),
+		"remote":                float64(ch.RemoteBalance),
+		"commit_fee":            float64(ch.CommitFee),
+		"unsettled_balance":     float64(ch.UnsettledBalance),
+		"total_amount_sent":     float64(ch.TotalAmountSent),
+		"total_amount_received": float64(ch.TotalAmountReceived),
+		"updates_count":         float64(ch.UpdatesCount),
+		"csv_delay":             float64(ch.CSVDelay),
+		"age":                   float64(ch.Age),
+		"ping_ms":               float64(ch.PingTime.Milliseconds()),
+		"pending_htlc":          float64(len(ch.PendingHTLC)),
+		"pending_htlcs":         float64(len(ch.PendingHTLC)),
+		"my_base":               0.0,
+		"my_ppm":                0.0,
+		"peer_base":             0.0,
+		"peer_ppm":              0.0,
+		"my_disabled":           false,
+		"peer_disabled":         false,
+		"tags":                  strings.Join(ch.Tags, ","),
+		"note":                  ch.Note,
+	}
+	if ch.LocalPolicy != nil {
+		env["my_base"] = float64(ch.LocalPolicy.FeeBaseMsat)
+		env["my_ppm"] = float64(ch.LocalPolicy.FeeRateMilliMsat)
+		env["my_disabled"] = ch.LocalPolicy.Disabled
+	}
+	if ch.RemotePolicy != nil {
+		env["peer_base"] = float64(ch.RemotePolicy.FeeBaseMsat)
+		env["peer_ppm"] = float64(ch.RemotePolicy.FeeRateMilliMsat)
+		env["peer_disabled"] = ch.RemotePolicy.Disabled
+	}
+	return env
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
+)
+
+// RoutingFailures are the failures of the forwards of the routing events
+// since lntop started, with the events recorded in the store.
+type RoutingFailures struct {
+	failures *stats.Failures
+	mu       sync.RWMutex
+}
+
+func NewRoutingFailures() *RoutingFailures {
+	return &RoutingFailures{failures: stats.NewFailures()}
+}
+
+func (r *RoutingFailures) add(e *models.RoutingEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures.Add(e)
+}
+
+// Counts returns the number of forwards resolved and failed.
+func (r *RoutingFailures) Counts() (int, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failures.Forwards, r.failures.Failed
+}
+
+func (r *RoutingFailures) Reasons() []stats.ReasonCount {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failures.Reasons()
+}
+
+func (r *RoutingFailures) TopPairs(n int) []stats.PairFailures {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failures.TopPairs(n)
+}
+
+func (r *RoutingFailures) Rates(now time.Time) []stats.Rate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failures.Rates(now)
+}
+
+func (r *RoutingFailures) Hotspots(n int) []stats.Hotspot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.failures.Hotspots(n)
+}
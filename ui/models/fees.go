@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/fee"
+)
+
+// FeeRates are the last on-chain fee rates in sat/vbyte of the fee
+// provider of the config.
+type FeeRates struct {
+	provider fee.Provider
+	rates    *fee.Rates
+	updated  time.Time
+	mu       sync.RWMutex
+}
+
+// Get returns the rates and the time of their estimate, nil if they were
+// not estimated.
+func (f *FeeRates) Get() (*fee.Rates, time.Time) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.rates, f.updated
+}
+
+// HalfHour returns the rate to confirm in half an hour, the default fee
+// rate of the dialogs, zero if it was not estimated.
+func (f *FeeRates) HalfHour() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.rates == nil {
+		return 0
+	}
+	return f.rates.HalfHour
+}
+
+// Interval returns the duration between two estimates of the provider.
+func (f *FeeRates) Interval() time.Duration {
+	return f.provider.Interval()
+}
+
+// RefreshFeeRates estimates the fee rates with the provider, the previous
+// ones are kept if the estimate fails.
+func (m *Models) RefreshFeeRates(ctx context.Context) error {
+	rates, err := m.FeeRates.provider.Rates(ctx)
+	if err != nil {
+		return err
+	}
+	m.FeeRates.mu.Lock()
+	defer m.FeeRates.mu.Unlock()
+	m.FeeRates.rates = rates
+	m.FeeRates.updated = time.Now()
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/expr"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// exprFilter is the key of the filter of the expression of the channels.
+const exprFilter = "expr"
+
+// ParseChannelsExpr parses the expression of a filter of the channels,
+// evaluated with the identifiers of ChannelEnv to a boolean.
+func ParseChannelsExpr(src string) (*expr.Expr, error) {
+	e, err := expr.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	// the unknown identifiers and the results which are not a boolean
+	// are reported before any channel is filtered.
+	env := ChannelEnv(&models.Channel{RemotePubKey: strings.Repeat("0", 66)})
+	for _, id := range e.Idents() {
+		if _, ok := env[id]; !ok {
+			return nil, errors.Errorf("unknown identifier %q", id)
+		}
+	}
+	// the channel of zeros divides by zero, like an unused channel.
+	_, err = e.Bool(env)
+	if err != nil && !errors.Is(err, expr.ErrDivisionByZero) {
+		return nil, errors.Errorf("invalid filter: %s", err)
+	}
+	return e, nil
+}
+
+// SetFilterExpr displays only the channels for which the expression is
+// true, an empty expression displays them all again. The filter is not
+// changed if the expression is invalid.
+func (c *Channels) SetFilterExpr(src string) error {
+	src = strings.TrimSpace(src)
+	var f ChannelsFilter
+	if src != "" {
+		e, err := ParseChannelsExpr(src)
+		if err != nil {
+			return err
+		}
+		f = func(ch *models.Channel) bool {
+			ok, err := e.Bool(ChannelEnv(ch))
+			return err == nil && ok
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expr = src
+	if f == nil {
+		delete(c.filters, exprFilter)
+		return nil
+	}
+	c.filters[exprFilter] = f
+	return nil
+}
+
+// FilterExpr returns the expression of the filter, empty if none.
+func (c *Channels) FilterExpr() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.expr
+}
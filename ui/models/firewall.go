@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type FirewallSort func(*models.InterceptedHTLC, *models.InterceptedHTLC) bool
+
+// Firewall is the list of the HTLCs intercepted by the firewall, the held
+// ones and the last resolved ones.
+type Firewall struct {
+	list    []*models.InterceptedHTLC
+	sort    FirewallSort
+	enabled bool
+	mu      sync.RWMutex
+}
+
+// Enabled returns true if the HTLCs are intercepted by the firewall.
+func (f *Firewall) Enabled() bool {
+	return f.enabled
+}
+
+func (f *Firewall) List() []*models.InterceptedHTLC {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.list
+}
+
+func (f *Firewall) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.list)
+}
+
+func (f *Firewall) Get(index int) *models.InterceptedHTLC {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if index < 0 || index > len(f.list)-1 {
+		return nil
+	}
+	return f.list[index]
+}
+
+// Held returns the number of HTLCs waiting for a resolution.
+func (f *Firewall) Held() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	n := 0
+	for _, htlc := range f.list {
+		if htlc.Action == models.InterceptHold {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *Firewall) Sort(fn FirewallSort) {
+	if fn == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sort = fn
+	sort.SliceStable(f.list, func(i, j int) bool { return fn(f.list[i], f.list[j]) })
+}
+
+func (f *Firewall) Update(htlcs []*models.InterceptedHTLC) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.list = htlcs
+	if f.sort != nil {
+		sort.SliceStable(f.list, func(i, j int) bool { return f.sort(f.list[i], f.list[j]) })
+	}
+}
+
+// RefreshFirewall lists the HTLCs of the firewall, if it is enabled.
+func (m *Models) RefreshFirewall(ctx context.Context) error {
+	if m.firewall == nil {
+		return nil
+	}
+	m.Firewall.Update(m.firewall.List())
+	return nil
+}
+
+// ResolveHTLC resumes, fails or settles with the preimage the held HTLC
+// of the circuit key and refreshes the firewall.
+func (m *Models) ResolveHTLC(ctx context.Context, key string, action int, preimage []byte) error {
+	if m.firewall == nil {
+		return errors.New("the firewall is not enabled")
+	}
+	err := m.firewall.Resolve(key, action, preimage)
+	if err != nil {
+		return err
+	}
+	return m.RefreshFirewall(ctx)
+}
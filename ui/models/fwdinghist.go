@@ -1,21 +1,76 @@
 package models
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/edouardparis/lntop/network/models"
 )
 
+// FwdingHistRanges are the time ranges of the forwarding history selected
+// after the start time of the config, zero for all of it.
+var FwdingHistRanges = []time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+	0,
+}
+
 type FwdinghistSort func(*models.ForwardingEvent, *models.ForwardingEvent) bool
 
 type FwdingHist struct {
 	StartTime    string
 	MaxNumEvents uint32
-	current      *models.ForwardingEvent
-	list         []*models.ForwardingEvent
-	sort         FwdinghistSort
-	mu           sync.RWMutex
+	// rng is the index of the time range in FwdingHistRanges plus one,
+	// zero for the start time.
+	rng     int
+	current *models.ForwardingEvent
+	list    []*models.ForwardingEvent
+	sort    FwdinghistSort
+	mu      sync.RWMutex
+}
+
+// Start returns the start time of the forwarding history of the range.
+func (t *FwdingHist) Start() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.rng == 0 {
+		return t.StartTime
+	}
+	d := FwdingHistRanges[t.rng-1]
+	if d == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("-%ds", int64(d.Seconds()))
+}
+
+// RangeName returns the label of the time range.
+func (t *FwdingHist) RangeName() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.rng > 0 {
+		return RangeName(FwdingHistRanges[t.rng-1])
+	}
+	switch {
+	case t.StartTime == "" || t.StartTime == "0":
+		return "all"
+	case strings.HasPrefix(t.StartTime, "-"):
+		return t.StartTime[1:]
+	}
+	return "since " + t.StartTime
+}
+
+// NextRange selects the next time range, back to the start time after
+// the last one.
+func (t *FwdingHist) NextRange() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rng = (t.rng + 1) % (len(FwdingHistRanges) + 1)
 }
 
 func (t *FwdingHist) Current() *models.ForwardingEvent {
@@ -69,4 +124,8 @@ func (t *FwdingHist) Update(events []*models.ForwardingEvent) {
 	for _, event := range events {
 		t.list = append(t.list, event)
 	}
+	// the order of the column sorted is kept.
+	if t.sort != nil {
+		sort.Sort(t)
+	}
 }
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type GraphSort func(*models.Node, *models.Node) bool
+
+// Graph is the graph of the node, loaded once, and the nodes matching the
+// query of the lookup. Current is the node of the detail with its
+// channels.
+type Graph struct {
+	Current *models.Node
+	nodes   []*models.Node
+	index   map[string]*models.Node
+	list    []*models.Node
+	query   string
+	sort    GraphSort
+	loaded  time.Time
+	mu      sync.RWMutex
+}
+
+// List returns the nodes matching the query.
+func (g *Graph) List() []*models.Node {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.list
+}
+
+// Len returns the number of nodes matching the query.
+func (g *Graph) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.list)
+}
+
+// Total returns the number of nodes of the graph.
+func (g *Graph) Total() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.nodes)
+}
+
+func (g *Graph) Get(index int) *models.Node {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if index < 0 || index > len(g.list)-1 {
+		return nil
+	}
+	return g.list[index]
+}
+
+// Loaded returns the time the graph was loaded, zero before.
+func (g *Graph) Loaded() time.Time {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.loaded
+}
+
+// Query returns the query of the lookup, empty if none.
+func (g *Graph) Query() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.query
+}
+
+// SetQuery displays the nodes whose pubkey starts with the query or
+// whose alias contains it, an empty query displays them all again.
+func (g *Graph) SetQuery(query string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.query = strings.TrimSpace(query)
+	g.filter()
+}
+
+// alias returns the alias of the node of the graph, empty if unknown.
+func (g *Graph) alias(pubkey string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	node, ok := g.index[pubkey]
+	if !ok {
+		return ""
+	}
+	if node.ForcedAlias != "" {
+		return node.ForcedAlias
+	}
+	return node.Alias
+}
+
+func (g *Graph) Sort(fn GraphSort) {
+	if fn == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sort = fn
+	sort.SliceStable(g.list, func(i, j int) bool { return fn(g.list[i], g.list[j]) })
+}
+
+// Update replaces the nodes of the graph, the largest first unless
+// sorted.
+func (g *Graph) Update(nodes []*models.Node) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nodes = nodes
+	g.index = make(map[string]*models.Node, len(nodes))
+	for _, n := range nodes {
+		g.index[n.PubKey] = n
+	}
+	g.loaded = time.Now()
+	g.filter()
+}
+
+func (g *Graph) filter() {
+	query := strings.ToLower(g.query)
+	list := make([]*models.Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		if query == "" || strings.HasPrefix(n.PubKey, query) ||
+			strings.Contains(strings.ToLower(n.Alias), query) ||
+			strings.Contains(strings.ToLower(n.ForcedAlias), query) {
+			list = append(list, n)
+		}
+	}
+	fn := g.sort
+	if fn == nil {
+		fn = func(n1, n2 *models.Node) bool {
+			return n1.TotalCapacity > n2.TotalCapacity
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return fn(list[i], list[j]) })
+	g.list = list
+}
+
+// RefreshGraph loads the nodes of the graph, the lookup is done on them
+// until the next refresh.
+func (m *Models) RefreshGraph(ctx context.Context) error {
+	nodes, err := m.network.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	m.Graph.Update(nodes)
+	return nil
+}
+
+// RefreshGraphNode sets the node of the pubkey with its channels as the
+// current node of the graph, the peers of the channels have the aliases
+// of the graph.
+func (m *Models) RefreshGraphNode(ctx context.Context, pubkey string) error {
+	node, err := m.network.GetNode(ctx, pubkey, true)
+	if err != nil {
+		return err
+	}
+	for _, ch := range node.Channels {
+		if ch.Node == nil {
+			ch.Node = &models.Node{PubKey: ch.RemotePubKey, Alias: m.Graph.alias(ch.RemotePubKey)}
+		}
+	}
+	m.Graph.Current = node
+	return nil
+}
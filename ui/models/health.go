@@ -0,0 +1,91 @@
+package models
+
+import (
+	"math"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+const (
+	defaultForwardsTarget = 10
+	// maxHealthHTLCs is the number of pending HTLCs for which the htlcs
+	// component is zero.
+	maxHealthHTLCs = 10
+)
+
+var defaultHealth = config.Health{
+	Uptime:   30,
+	Balance:  20,
+	Forwards: 20,
+	Fees:     15,
+	HTLCs:    15,
+}
+
+// ChannelHealth returns the score from 0 to 100 of the channel, the
+// weighted mean of:
+//   - uptime: the part of its lifetime the peer was online,
+//   - balance: 1 for a channel balanced in the middle, 0 on one side,
+//   - forwards: the forwards of the history through the channel up to
+//     the target,
+//   - fees: 1 if the outgoing fee rate is not above the peer's one,
+//     decreasing with the ratio otherwise,
+//   - htlcs: decreasing with the pending HTLCs.
+func ChannelHealth(ch *models.Channel, forwards int, cfg config.Health) int {
+	weights := cfg
+	if cfg.Uptime == 0 && cfg.Balance == 0 && cfg.Forwards == 0 && cfg.Fees == 0 && cfg.HTLCs == 0 {
+		weights = defaultHealth
+	}
+	target := cfg.ForwardsTarget
+	if target <= 0 {
+		target = defaultForwardsTarget
+	}
+
+	uptime := 0.0
+	if ch.Lifetime > 0 {
+		uptime = math.Min(1, float64(ch.Uptime)/float64(ch.Lifetime))
+	} else if ch.Status == models.ChannelActive {
+		uptime = 1
+	}
+
+	balance := 0.0
+	if ch.Capacity > 0 {
+		balance = 1 - math.Abs(float64(ch.LocalBalance)/float64(ch.Capacity)-0.5)*2
+	}
+
+	flow := math.Min(1, float64(forwards)/float64(target))
+
+	fees := 1.0
+	if ch.LocalPolicy != nil && ch.RemotePolicy != nil &&
+		ch.LocalPolicy.FeeRateMilliMsat > ch.RemotePolicy.FeeRateMilliMsat {
+		fees = float64(ch.RemotePolicy.FeeRateMilliMsat+1) / float64(ch.LocalPolicy.FeeRateMilliMsat+1)
+	}
+
+	htlcs := math.Max(0, 1-float64(len(ch.PendingHTLC))/maxHealthHTLCs)
+
+	total := weights.Uptime + weights.Balance + weights.Forwards + weights.Fees + weights.HTLCs
+	if total <= 0 {
+		return 0
+	}
+	score := (weights.Uptime*uptime + weights.Balance*balance + weights.Forwards*flow +
+		weights.Fees*fees + weights.HTLCs*htlcs) / total
+	return int(math.Round(math.Max(0, math.Min(1, score)) * 100))
+}
+
+// refreshHealth computes the scores of the channels with the forwards
+// of the history.
+func (m *Models) refreshHealth() {
+	forwards := make(map[uint64]int)
+	for _, e := range m.FwdingHist.List() {
+		forwards[e.ChanIdIn]++
+		forwards[e.ChanIdOut]++
+	}
+
+	health := make(map[string]int)
+	for _, ch := range m.Channels.List() {
+		if ch.Status == models.ChannelActive || ch.Status == models.ChannelInactive {
+			health[ch.ChannelPoint] = ChannelHealth(ch, forwards[ch.ID], m.health)
+		}
+	}
+	m.Channels.setHealth(health)
+}
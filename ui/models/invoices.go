@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type InvoicesSort func(*models.Invoice, *models.Invoice) bool
+
+type Invoices struct {
+	list []*models.Invoice
+	sort InvoicesSort
+	mu   sync.RWMutex
+}
+
+func (i *Invoices) List() []*models.Invoice {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.list
+}
+
+func (i *Invoices) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.list)
+}
+
+func (i *Invoices) Get(index int) *models.Invoice {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if index < 0 || index > len(i.list)-1 {
+		return nil
+	}
+	return i.list[index]
+}
+
+func (i *Invoices) Sort(fn InvoicesSort) {
+	if fn == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.sort = fn
+	sort.SliceStable(i.list, func(a, b int) bool { return fn(i.list[a], i.list[b]) })
+}
+
+func (i *Invoices) Update(list []*models.Invoice) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.list = list
+	if i.sort != nil {
+		sort.SliceStable(i.list, func(a, b int) bool { return i.sort(i.list[a], i.list[b]) })
+	}
+}
+
+func NewInvoices() *Invoices {
+	return &Invoices{list: []*models.Invoice{}}
+}
+
+func (m *Models) RefreshInvoices(ctx context.Context) error {
+	list, err := m.network.ListInvoices(ctx)
+	if err != nil {
+		return err
+	}
+	m.Invoices.Update(list)
+	return nil
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// penaltyHalfLife is the time a failed pair penalizes its node, the
+// default half-life of the penalties of lnd.
+const penaltyHalfLife = time.Hour
+
+// DefaultProbabilityAmount is the amount in sat of the probabilities of
+// the pairs.
+const DefaultProbabilityAmount = 100000
+
+type MissionControlSort func(*MissionPair, *MissionPair) bool
+
+// MissionPair is a pair of mission control with the aliases of its nodes
+// and its success probability of the amount of the estimates, negative
+// if it is unknown.
+type MissionPair struct {
+	*models.MissionPair
+	FromAlias   string
+	ToAlias     string
+	Probability float64
+}
+
+// PenalizedNode is a node of the pairs failed within the penalty
+// half-life.
+type PenalizedNode struct {
+	PubKey   string
+	Alias    string
+	Failures int
+	LastFail time.Time
+}
+
+// MissionControl is the history of the payment attempts of the pathfinding
+// of the node, with the probabilities of the pairs for Amount sat.
+type MissionControl struct {
+	Amount    int64
+	list      []*MissionPair
+	aliases   map[string]string
+	refreshed time.Time
+	sort      MissionControlSort
+	mu        sync.RWMutex
+}
+
+func NewMissionControl() *MissionControl {
+	return &MissionControl{
+		Amount:  DefaultProbabilityAmount,
+		aliases: make(map[string]string),
+	}
+}
+
+func (m *MissionControl) List() []*MissionPair {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.list
+}
+
+func (m *MissionControl) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.list)
+}
+
+func (m *MissionControl) Get(index int) *MissionPair {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if index < 0 || index > len(m.list)-1 {
+		return nil
+	}
+	return m.list[index]
+}
+
+// Refreshed returns the time of the last refresh, zero before the first
+// one.
+func (m *MissionControl) Refreshed() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.refreshed
+}
+
+// Pairs returns the pairs without their aliases and probabilities.
+func (m *MissionControl) Pairs() []*models.MissionPair {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pairs := make([]*models.MissionPair, len(m.list))
+	for i := range m.list {
+		pairs[i] = m.list[i].MissionPair
+	}
+	return pairs
+}
+
+// Penalized returns the nodes of the pairs failed within the penalty
+// half-life, the most failed first.
+func (m *MissionControl) Penalized(now time.Time) []*PenalizedNode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	nodes := map[string]*PenalizedNode{}
+	var list []*PenalizedNode
+	for _, p := range m.list {
+		if !p.Failed() || now.Sub(p.FailTime) > penaltyHalfLife {
+			continue
+		}
+		node, ok := nodes[p.NodeTo]
+		if !ok {
+			node = &PenalizedNode{PubKey: p.NodeTo, Alias: p.ToAlias}
+			nodes[p.NodeTo] = node
+			list = append(list, node)
+		}
+		node.Failures++
+		if p.FailTime.After(node.LastFail) {
+			node.LastFail = p.FailTime
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Failures != list[j].Failures {
+			return list[i].Failures > list[j].Failures
+		}
+		return list[i].LastFail.After(list[j].LastFail)
+	})
+	return list
+}
+
+func (m *MissionControl) Sort(fn MissionControlSort) {
+	if fn == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sort = fn
+	sort.SliceStable(m.list, func(i, j int) bool { return fn(m.list[i], m.list[j]) })
+}
+
+// Update replaces the pairs, the last attempted first unless sorted.
+func (m *MissionControl) Update(pairs []*MissionPair) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.list = pairs
+	m.refreshed = time.Now()
+	fn := m.sort
+	if fn == nil {
+		fn = func(p1, p2 *MissionPair) bool {
+			return p1.LastAttempt().After(p2.LastAttempt())
+		}
+	}
+	sort.SliceStable(m.list, func(i, j int) bool { return fn(m.list[i], m.list[j]) })
+}
+
+func (m *MissionControl) alias(pubkey string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	alias, ok := m.aliases[pubkey]
+	return alias, ok
+}
+
+// RefreshMissionControl queries the pairs of mission control with their
+// probabilities, the aliases of the nodes are looked up once.
+func (m *Models) RefreshMissionControl(ctx context.Context) error {
+	pairs, err := m.network.MissionControl(ctx)
+	if err != nil {
+		return err
+	}
+	amtMsat := m.MissionControl.Amount * 1000
+	list := make([]*MissionPair, len(pairs))
+	for i := range pairs {
+		pair := &MissionPair{MissionPair: pairs[i], Probability: -1}
+		pair.FromAlias = m.missionAlias(ctx, pairs[i].NodeFrom)
+		pair.ToAlias = m.missionAlias(ctx, pairs[i].NodeTo)
+		pair.Probability, err = m.network.PairProbability(ctx, pairs[i].NodeFrom, pairs[i].NodeTo, amtMsat)
+		if err != nil {
+			m.logger.Debug("refreshMissionControl: cannot query the probability",
+				logging.String("from", pairs[i].NodeFrom), logging.String("to", pairs[i].NodeTo),
+				logging.Error(err))
+			pair.Probability = -1
+		}
+		list[i] = pair
+	}
+	m.MissionControl.Update(list)
+	return nil
+}
+
+func (m *Models) missionAlias(ctx context.Context, pubkey string) string {
+	alias, ok := m.MissionControl.alias(pubkey)
+	if ok {
+		return alias
+	}
+	// the node is not in its own graph.
+	if m.Info.Info != nil && m.Info.PubKey == pubkey {
+		return m.Info.Alias
+	}
+	alias = m.NodeAlias(ctx, pubkey)
+	m.MissionControl.mu.Lock()
+	m.MissionControl.aliases[pubkey] = alias
+	m.MissionControl.mu.Unlock()
+	return alias
+}
+
+// ResetMissionControl clears mission control and refreshes it.
+func (m *Models) ResetMissionControl(ctx context.Context) error {
+	err := m.network.ResetMissionControl(ctx)
+	if err != nil {
+		return err
+	}
+	return m.RefreshMissionControl(ctx)
+}
+
+// ImportMissionControl imports the pairs in mission control and refreshes
+// it.
+func (m *Models) ImportMissionControl(ctx context.Context, pairs []*models.MissionPair, force bool) error {
+	err := m.network.ImportMissionControl(ctx, pairs, force)
+	if err != nil {
+		return err
+	}
+	return m.RefreshMissionControl(ctx)
+}
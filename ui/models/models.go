@@ -1,35 +1,136 @@
+// Package models aggregates the data of the node for the views: the
+// channels with their node aliases and policies, the balances, the
+// transactions, the routing log and the forwarding history. It does not
+// depend on the terminal ui and can be used by other programs, the
+// Refresh methods are called on the events of the pubsub package.
 package models
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/edouardparis/lntop/app"
+	"github.com/edouardparis/lntop/backup"
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/fee"
+	"github.com/edouardparis/lntop/firewall"
 	"github.com/edouardparis/lntop/logging"
 	"github.com/edouardparis/lntop/network"
 	"github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/network/options"
+	"github.com/edouardparis/lntop/peerdata"
+	"github.com/edouardparis/lntop/plugin"
+	"github.com/edouardparis/lntop/price"
+	"github.com/edouardparis/lntop/store"
 )
 
 type Models struct {
 	logger          logging.Logger
 	network         *network.Network
+	health          config.Health
+	store           *store.Store
+	balanceInterval time.Duration
+	firewall        *firewall.Firewall
 	Info            *Info
 	Channels        *Channels
+	PendingChannels *PendingChannels
 	WalletBalance   *WalletBalance
 	ChannelsBalance *ChannelsBalance
 	Transactions    *Transactions
+	Payments        *Payments
+	Invoices        *Invoices
 	RoutingLog      *RoutingLog
+	RoutingFailures *RoutingFailures
+	Session         *Session
 	FwdingHist      *FwdingHist
+	Peers           *Peers
+	ClosedChannels  *ClosedChannels
+	Sweeps          *Sweeps
+	UTXOs           *UTXOs
+	Towers          *Towers
+	Offers          *Offers
+	MissionControl  *MissionControl
+	Graph           *Graph
+	Firewall        *Firewall
+	Plugins         *Plugins
+	Price           *Price
+	PeerData        *PeerData
+	Alerts          *Alerts
+	NodeState       *NodeState
+	Connection      *Connection
+	ChannelBackup   *ChannelBackup
+	FeeRates        *FeeRates
+	Notifications   *Notifications
+	RoutingChart    *RoutingChart
+	// channelsRefreshed is the unix time in nanoseconds of the last
+	// refresh of every channel.
+	channelsRefreshed atomic.Int64
+	// htlcsScheduled is true while a refresh of the HTLCs in flight is
+	// scheduled.
+	htlcsScheduled atomic.Bool
+	// aliases is nil if the aliases of the nodes are not recorded.
+	aliases *nodeAliases
+	// backupExport is the destination of the channel backup exported
+	// from the ui, nil if none.
+	backupExport backup.Destination
 }
 
 func New(app *app.App) *Models {
-	fwdingHist := FwdingHist{}
+	m := NewWithNetwork(app.Network, app.Logger)
+	m.health = app.Config.Health
+	m.store = app.Store
+	m.balanceInterval, _ = app.Config.Store.BalanceSampling()
+	if window := app.Config.Views.Session.Window; window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			app.Logger.Info("Couldn't parse the window of the session rates.")
+		} else {
+			m.Session = NewSession(d)
+		}
+	}
+	err := m.RoutingChart.setConfig(app.Config.Views.RoutingChart)
+	if err != nil {
+		app.Logger.Info("Couldn't parse the window and the interval of the routing chart.")
+	}
+	m.RoutingChart.enabled = app.Store != nil && !app.Config.Views.RoutingChart.Disabled
+	m.firewall = app.Firewall
+	m.Firewall.enabled = app.Firewall != nil
+	err = m.LoadRoutingLog()
+	if err != nil {
+		app.Logger.Error("cannot load the recorded routing events", logging.Error(err))
+	}
+	err = m.LoadChannelNotes()
+	if err != nil {
+		app.Logger.Error("cannot load the notes of the channels", logging.Error(err))
+	}
+	err = m.LoadNodeAliases(app.Config.Store, app.Config.Network.Aliases)
+	if err != nil {
+		app.Logger.Error("cannot load the aliases of the nodes", logging.Error(err))
+	}
+	err = m.LoadPeerUptimes()
+	if err != nil {
+		app.Logger.Error("cannot load the uptime of the peers", logging.Error(err))
+	}
+	m.backupExport, err = backup.NewExport(app.Config.Backup, app.Config.HTTP)
+	if err != nil {
+		app.Logger.Error("invalid export destination of the channel backup", logging.Error(err))
+	}
+	fees, err := fee.New(app.Config.Fees, app.Config.HTTP, m.estimateFeeRate)
+	if err != nil {
+		app.Logger.Error("invalid fee source, the fees are estimated by the node", logging.Error(err))
+	} else {
+		m.FeeRates.provider = fees
+	}
+	m.Info.explorer = app.Config.Explorer
 	startTime := app.Config.Views.FwdingHist.Options.GetOption("START_TIME", "start_time")
 	maxNumEvents := app.Config.Views.FwdingHist.Options.GetOption("MAX_NUM_EVENTS", "max_num_events")
 
 	if startTime != "" {
-		fwdingHist.StartTime = startTime
+		m.FwdingHist.StartTime = startTime
 	}
 
 	if maxNumEvents != "" {
@@ -37,25 +138,113 @@ func New(app *app.App) *Models {
 		if err != nil {
 			app.Logger.Info("Couldn't parse the maximum number of forwarding events.")
 		} else {
-			fwdingHist.MaxNumEvents = uint32(max)
+			m.FwdingHist.MaxNumEvents = uint32(max)
 		}
 	}
 
-	return &Models{
-		logger:          app.Logger.With(logging.String("logger", "models")),
-		network:         app.Network,
+	if cfg := app.Config.Views.MissionControl; cfg != nil {
+		amount := cfg.Options.GetOption("PROBABILITY", "amount")
+		if amount != "" {
+			n, err := strconv.ParseInt(amount, 10, 64)
+			if err != nil || n <= 0 {
+				app.Logger.Info("Couldn't parse the amount of the probabilities.")
+			} else {
+				m.MissionControl.Amount = n
+			}
+		}
+	}
+
+	for i := range app.Config.Plugins {
+		m.Plugins.Add(plugin.New(app.Config.Plugins[i], app.Logger))
+	}
+
+	if app.Config.Price.Provider != "" {
+		provider, err := price.New(app.Config.Price, app.Config.HTTP)
+		if err != nil {
+			app.Logger.Error("cannot create the price provider, the fiat columns are empty", logging.Error(err))
+		} else {
+			m.Price.provider = provider
+		}
+	}
+
+	err = m.Notifications.SetConfig(app.Config.Notifications)
+	if err != nil {
+		app.Logger.Info("Couldn't parse the duration of the notifications.")
+	}
+
+	if app.Config.PeerData.Source != "" {
+		source, err := peerdata.New(app.Config.PeerData, app.Config.HTTP)
+		if err != nil {
+			app.Logger.Error("cannot create the source of the peer data, the SCORE, TAGS and RANK columns are empty", logging.Error(err))
+		} else {
+			m.PeerData.source = source
+		}
+	}
+
+	return m
+}
+
+// NewWithNetwork returns empty models refreshed from the given network,
+// without reading the views config.
+func NewWithNetwork(network *network.Network, logger logging.Logger) *Models {
+	m := &Models{
+		logger:          logger.With(logging.String("logger", "models")),
+		network:         network,
 		Info:            &Info{},
 		Channels:        NewChannels(),
+		PendingChannels: &PendingChannels{},
 		WalletBalance:   &WalletBalance{},
 		ChannelsBalance: &ChannelsBalance{},
 		Transactions:    &Transactions{},
+		Payments:        NewPayments(),
+		Invoices:        NewInvoices(),
 		RoutingLog:      &RoutingLog{},
-		FwdingHist:      &fwdingHist,
+		RoutingFailures: NewRoutingFailures(),
+		Session:         NewSession(0),
+		FwdingHist:      &FwdingHist{},
+		Peers:           NewPeers(),
+		ClosedChannels:  NewClosedChannels(),
+		Sweeps:          &Sweeps{},
+		UTXOs:           NewUTXOs(),
+		Towers:          &Towers{},
+		Offers:          &Offers{},
+		MissionControl:  NewMissionControl(),
+		Graph:           &Graph{},
+		Firewall:        &Firewall{},
+		Plugins:         NewPlugins(),
+		Price:           &Price{},
+		PeerData:        &PeerData{},
+		Alerts:          &Alerts{},
+		NodeState:       &NodeState{state: models.NodeStateServerActive},
+		Connection:      &Connection{},
+		ChannelBackup:   &ChannelBackup{},
+		Notifications:   NewNotifications(),
+		RoutingChart:    NewRoutingChart(),
 	}
+	m.FeeRates = &FeeRates{provider: fee.NewNode(m.estimateFeeRate)}
+	return m
+}
+
+func (m *Models) estimateFeeRate(ctx context.Context, target uint32) (uint64, error) {
+	return m.network.EstimateFeeRate(ctx, target)
 }
 
 type Info struct {
 	*models.Info
+	explorer func(network string) string
+}
+
+// TxURL returns the url of the transaction on the explorer of the network
+// of the node, empty if none.
+func (i *Info) TxURL(txid string) string {
+	if i.Info == nil || i.explorer == nil {
+		return ""
+	}
+	explorer := i.explorer(i.Network)
+	if explorer == "" {
+		return ""
+	}
+	return fmt.Sprintf(explorer, txid)
 }
 
 func (m *Models) RefreshInfo(ctx context.Context) error {
@@ -63,17 +252,19 @@ func (m *Models) RefreshInfo(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	*m.Info = Info{info}
+	m.Info.Info = info
+	m.Channels.setAges(info.BlockHeight)
 	return nil
 }
 
 func (m *Models) RefreshForwardingHistory(ctx context.Context) error {
-	forwardingEvents, err := m.network.GetForwardingHistory(ctx, m.FwdingHist.StartTime, m.FwdingHist.MaxNumEvents)
+	forwardingEvents, err := m.network.GetForwardingHistory(ctx, m.FwdingHist.Start(), m.FwdingHist.MaxNumEvents)
 	if err != nil {
 		return err
 	}
 
 	m.FwdingHist.Update(forwardingEvents)
+	m.refreshHealth()
 
 	return nil
 }
@@ -83,32 +274,32 @@ func (m *Models) RefreshChannels(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	pings := map[string]time.Duration{}
+	peers, err := m.network.ListPeers(ctx)
+	if err != nil {
+		m.logger.Debug("refreshChannels: cannot list peers", logging.Error(err))
+	}
+	for _, p := range peers {
+		pings[p.PubKey] = p.PingTime
+	}
 	index := map[string]*models.Channel{}
 	for i := range channels {
 		index[channels[i].ChannelPoint] = channels[i]
-		if channels[i].ID > 0 {
-			channels[i].Age = m.Info.BlockHeight - uint32(channels[i].ID>>40)
-		}
+		channels[i].Age = channels[i].AgeAt(m.Info.BlockHeight)
+		channels[i].PingTime = pings[channels[i].RemotePubKey]
+		m.Channels.applyNote(channels[i])
 		if !m.Channels.Contains(channels[i]) {
 			m.Channels.Add(channels[i])
 		}
 		channel := m.Channels.GetByChanPoint(channels[i].ChannelPoint)
+		if channel != nil && channel.Node == nil {
+			channel.Node = m.cachedNode(channel.RemotePubKey)
+		}
 		if channel != nil &&
 			(channel.UpdatesCount < channels[i].UpdatesCount ||
-				channel.LastUpdate == nil || channel.LocalPolicy == nil || channel.RemotePolicy == nil) {
-			err := m.network.GetChannelInfo(ctx, channels[i])
-			if err != nil {
-				return err
-			}
-
-			if channels[i].Node == nil {
-				channels[i].Node, err = m.network.GetNode(ctx,
-					channels[i].RemotePubKey, false)
-				if err != nil {
-					m.logger.Debug("refreshChannels: cannot find Node",
-						logging.String("pubkey", channels[i].RemotePubKey))
-				}
-			}
+				channel.LastUpdate == nil || channel.LocalPolicy == nil || channel.RemotePolicy == nil ||
+				channel.Node != nil && m.nodeExpired(channel.Node)) {
+			m.Channels.markStale(channel.ChannelPoint)
 		}
 
 		m.Channels.Update(channels[i])
@@ -118,9 +309,140 @@ func (m *Models) RefreshChannels(ctx context.Context) error {
 			c.Status = models.ChannelClosed
 		}
 	}
+	m.Channels.resort()
+	// the info of the rows displayed is fetched now and the one of the
+	// others in the background, a node with thousands of channels would
+	// wait for thousands of calls.
+	err = m.fetchChannelInfo(ctx, m.Channels.takeStale(channelInfoPage, true))
+	if err != nil {
+		return err
+	}
+	m.channelsRefreshed.Store(time.Now().UnixNano())
+	m.refreshClosingSweeps(ctx)
+	m.refreshHealth()
+	m.refreshUptime()
+	return m.RefreshPendingChannels(ctx)
+}
+
+// channelsResync is the age of the last refresh of every channel after
+// which an update of a channel refreshes them all instead of patching it.
+const channelsResync = 5 * time.Minute
+
+// PatchChannel applies the update of a channel reported by the node, its
+// open, close or status, to the channel without listing the channels
+// again. They are all refreshed if the update has no channel, if the
+// channel is unknown or if their last refresh is older than
+// channelsResync.
+func (m *Models) PatchChannel(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		cu, ok := update.(*models.ChannelUpdate)
+		refreshed := time.Unix(0, m.channelsRefreshed.Load())
+		if !ok || cu.ChannelPoint == "" || time.Since(refreshed) > channelsResync {
+			return m.RefreshChannels(ctx)
+		}
+		channel := m.Channels.GetByChanPoint(cu.ChannelPoint)
+		switch {
+		case cu.Channel != nil:
+			cu.Channel.Age = cu.Channel.AgeAt(m.Info.BlockHeight)
+			m.Channels.applyNote(cu.Channel)
+			if channel == nil {
+				m.Channels.Add(cu.Channel)
+			} else {
+				cu.Channel.PingTime = channel.PingTime
+			}
+			m.Channels.Update(cu.Channel)
+			// the policies and the node are fetched in the background.
+			m.Channels.markStale(cu.ChannelPoint)
+		case channel == nil:
+			return m.RefreshChannels(ctx)
+		case cu.CloseType != 0:
+			channel.Status = models.ChannelClosed
+			channel.CloseType = cu.CloseType
+		case cu.Status != 0:
+			channel.Status = cu.Status
+		}
+		m.refreshHealth()
+		m.refreshUptime()
+		return m.RefreshPendingChannels(ctx)
+	}
+}
+
+// RefreshAllChannels refreshes the channels with the info and the node of
+// every one, for the programs without a ui fetching them in the
+// background.
+func (m *Models) RefreshAllChannels(ctx context.Context) error {
+	err := m.RefreshChannels(ctx)
+	for err == nil && m.Channels.StaleLen() > 0 {
+		_, err = m.FetchChannelInfo(ctx)
+	}
+	return err
+}
+
+// channelInfoPage is the number of channels whose info is fetched at once.
+const channelInfoPage = 50
+
+// FetchChannelInfo fetches the info and the node of the next page of the
+// channels whose info is stale since their refresh, the visible ones
+// first, and returns the number of channels left.
+func (m *Models) FetchChannelInfo(ctx context.Context) (int, error) {
+	err := m.fetchChannelInfo(ctx, m.Channels.takeStale(channelInfoPage, false))
+	return m.Channels.StaleLen(), err
+}
+
+func (m *Models) fetchChannelInfo(ctx context.Context, channels []*models.Channel) error {
+	nodes := []*models.Node{}
+	defer func() { m.recordAliases(nodes) }()
+	for i, ch := range channels {
+		err := m.network.GetChannelInfo(ctx, ch)
+		if err != nil {
+			// the channels left are fetched again with the next page.
+			for _, c := range channels[i:] {
+				m.Channels.markStale(c.ChannelPoint)
+			}
+			return err
+		}
+
+		if m.nodeExpired(ch.Node) {
+			node, err := m.network.GetNode(ctx, ch.RemotePubKey, false)
+			if err != nil {
+				m.logger.Debug("refreshChannels: cannot find Node",
+					logging.String("pubkey", ch.RemotePubKey))
+				continue
+			}
+			ch.Node = node
+			nodes = append(nodes, node)
+		}
+	}
 	return nil
 }
 
+// RefreshChannelHTLCs updates the HTLCs in flight of the open channels,
+// without the pending channels and the peers of RefreshChannels.
+func (m *Models) RefreshChannelHTLCs(ctx context.Context) error {
+	channels, err := m.network.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range channels {
+		m.Channels.UpdateHTLCs(channels[i])
+	}
+	return nil
+}
+
+// ScheduleChannelHTLCs refreshes the HTLCs in flight of the channels once
+// after delay, the calls until then are coalesced in the same refresh.
+// done is called with the error of the refresh.
+func (m *Models) ScheduleChannelHTLCs(ctx context.Context, delay time.Duration, done func(error)) {
+	if !m.htlcsScheduled.CompareAndSwap(false, true) {
+		return
+	}
+	time.AfterFunc(delay, func() {
+		// a call during the refresh schedules the next one.
+		m.htlcsScheduled.Store(false)
+		done(m.RefreshChannelHTLCs(ctx))
+	})
+}
+
 type WalletBalance struct {
 	*models.WalletBalance
 }
@@ -153,18 +475,49 @@ type RoutingLog struct {
 
 const MaxRoutingEvents = 512 // 8K monitor @ 8px per line = 540
 
+// LoadRoutingLog fills the routing log with the last events recorded in
+// the store, if any.
+func (m *Models) LoadRoutingLog() error {
+	if m.store == nil {
+		return nil
+	}
+	events, err := m.store.RoutingEvents(MaxRoutingEvents)
+	if err != nil {
+		return err
+	}
+	m.RoutingLog.Log = events
+	for _, e := range events {
+		m.RoutingFailures.add(e)
+		m.Session.add(e)
+	}
+	return nil
+}
+
 func (m *Models) RefreshRouting(update interface{}) func(context.Context) error {
 	return (func(ctx context.Context) error {
 		hu, ok := update.(*models.RoutingEvent)
 		if ok {
 			found := false
+			resolved := false
+			// forward is the event logged with the update, a settle
+			// carries no amount.
+			forward := hu
 			for _, hlu := range m.RoutingLog.Log {
 				if hlu.Equals(hu) {
+					resolved = hlu.Resolved()
 					hlu.Update(hu)
+					forward = hlu
 					found = true
 					break
 				}
 			}
+			// a forward is aggregated once, at its first resolution.
+			if !resolved {
+				m.RoutingFailures.add(hu)
+				m.Session.add(forward)
+				m.Notifications.forward(forward)
+				m.RoutingChart.add(forward)
+			}
 			if !found {
 				if len(m.RoutingLog.Log) == MaxRoutingEvents {
 					m.RoutingLog.Log = m.RoutingLog.Log[1:]
@@ -194,10 +547,127 @@ func (m *Models) RefreshPolicies(update interface{}) func(context.Context) error
 	}
 }
 
+// UpdateChannelPolicy sets the policy of the node for the channel and
+// refreshes its info.
+func (m *Models) UpdateChannelPolicy(ctx context.Context, channel *models.Channel, policy *models.RoutingPolicy) error {
+	err := m.network.UpdateChannelPolicy(ctx, channel, policy)
+	if err != nil {
+		return err
+	}
+	return m.network.GetChannelInfo(ctx, channel)
+}
+
+// OpenChannel opens a channel with the peer and refreshes the channels,
+// the channel is listed as pending until it is confirmed.
+func (m *Models) OpenChannel(ctx context.Context, pubkey string, amount int64, satPerVbyte uint64, private bool) (string, error) {
+	point, err := m.network.OpenChannel(ctx, pubkey, amount, satPerVbyte, private, nil)
+	if err != nil {
+		return "", err
+	}
+	return point, m.RefreshChannels(ctx)
+}
+
+// EstimateBatchOpen returns the on-chain fee of the batch at the fee rate.
+func (m *Models) EstimateBatchOpen(ctx context.Context, channels []*models.BatchChannel, satPerVbyte uint64) (*models.FeeEstimate, error) {
+	return m.network.EstimateBatchOpen(ctx, channels, satPerVbyte)
+}
+
+// BatchOpenChannel opens the channels with a single funding transaction,
+// the selection is cleared and the channels are refreshed.
+func (m *Models) BatchOpenChannel(ctx context.Context, channels []*models.BatchChannel, satPerVbyte uint64) (string, error) {
+	txid, err := m.network.BatchOpenChannel(ctx, channels, satPerVbyte)
+	if err != nil {
+		return "", err
+	}
+	m.Peers.Unselect()
+	return txid, m.RefreshChannels(ctx)
+}
+
+// CloseChannel closes the channel and returns the txid of the close, the
+// ChannelClosing event displays it as closing.
+func (m *Models) CloseChannel(ctx context.Context, channel *models.Channel, force bool, satPerVbyte uint64) (string, error) {
+	return m.network.CloseChannel(ctx, channel, force, satPerVbyte)
+}
+
+// RefreshClosing marks the channel of the close as closing while the node
+// still reports it open.
+func (m *Models) RefreshClosing(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		cu, ok := update.(*models.ChannelUpdate)
+		if !ok {
+			m.logger.Error("refreshClosing: invalid event data")
+			return nil
+		}
+		channel := m.Channels.GetByChanPoint(cu.ChannelPoint)
+		if channel == nil ||
+			(channel.Status != models.ChannelActive && channel.Status != models.ChannelInactive) {
+			return nil
+		}
+		channel.Status = models.ChannelClosing
+		if models.IsForceClose(cu.CloseType) {
+			channel.Status = models.ChannelForceClosing
+		}
+		channel.CloseType = cu.CloseType
+		return nil
+	}
+}
+
+// NodeAlias returns the alias of the node, empty if it is unknown.
+func (m *Models) NodeAlias(ctx context.Context, pubkey string) string {
+	node, err := m.network.GetNode(ctx, pubkey, false)
+	if err != nil || node == nil {
+		return ""
+	}
+	if node.ForcedAlias != "" {
+		return node.ForcedAlias
+	}
+	return node.Alias
+}
+
+// RefreshCurrentNode refreshes the node of the current channel and its
+// policy history.
 func (m *Models) RefreshCurrentNode(ctx context.Context) (err error) {
+	m.RefreshPolicyHistory()
 	cur := m.Channels.Current()
 	if cur != nil {
 		m.Channels.CurrentNode, err = m.network.GetNode(ctx, cur.RemotePubKey, true)
 	}
 	return
 }
+
+func (m *Models) CreateInvoice(ctx context.Context, amount int64, desc string) (*models.Invoice, error) {
+	return m.network.CreateInvoice(ctx, amount, desc)
+}
+
+func (m *Models) NewAddress(ctx context.Context) (string, error) {
+	return m.network.NewAddress(ctx)
+}
+
+// DecodePayReq decodes a payment request and returns the alias of its
+// destination, empty if the node is unknown.
+func (m *Models) DecodePayReq(ctx context.Context, payreq string) (*models.PayReq, string, error) {
+	p, err := m.network.DecodePayReq(ctx, strings.TrimPrefix(strings.ToLower(payreq), "lightning:"))
+	if err != nil {
+		return nil, "", err
+	}
+	return p, m.NodeAlias(ctx, p.Destination), nil
+}
+
+// SignMessage signs the message with the key of the node.
+func (m *Models) SignMessage(ctx context.Context, message string) (string, error) {
+	return m.network.SignMessage(ctx, message)
+}
+
+// VerifyMessage verifies the signature of the message and returns the
+// alias of its signer, empty if the node is unknown.
+func (m *Models) VerifyMessage(ctx context.Context, message, signature string) (*models.VerifiedMessage, string, error) {
+	v, err := m.network.VerifyMessage(ctx, message, strings.TrimSpace(signature))
+	if err != nil {
+		return nil, "", err
+	}
+	alias := ""
+	if v.PubKey != "" {
+		alias = m.NodeAlias(ctx, v.PubKey)
+	}
+	return v, alias, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/store"
+)
+
+// LoadChannelNotes reads the tags and the notes of the channels from the
+// store, if any.
+func (m *Models) LoadChannelNotes() error {
+	if m.store == nil {
+		return nil
+	}
+	notes, err := m.store.ChannelNotes()
+	if err != nil {
+		return err
+	}
+	for channelPoint, n := range notes {
+		m.Channels.setNote(channelPoint, n)
+	}
+	return nil
+}
+
+// ParseTags returns the tags of the comma or space separated list,
+// lowercased and without duplicates.
+func ParseTags(s string) []string {
+	tags := []string{}
+	for _, t := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// SetChannelNote records the tags and the note of the channel in the store
+// and sets them on the channel.
+func (m *Models) SetChannelNote(ch *models.Channel, tags []string, note string) error {
+	if m.store == nil {
+		return errors.New("no store, the notes are recorded in the file of path in [store]")
+	}
+	n := &store.ChannelNote{Tags: tags, Note: strings.TrimSpace(note)}
+	err := m.store.SetChannelNote(ch.ChannelPoint, n)
+	if err != nil {
+		return err
+	}
+	m.Channels.setNote(ch.ChannelPoint, n)
+	return nil
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+const (
+	// MaxNotifications is the number of notifications kept.
+	MaxNotifications = 200
+
+	defaultNotificationDuration = 5 * time.Second
+	defaultForwardMin           = 1000000
+)
+
+const (
+	NotificationForward = iota + 1
+	NotificationInvoice
+	NotificationPayment
+	NotificationDisabled
+)
+
+type Notification struct {
+	Time    time.Time
+	Kind    int
+	Message string
+}
+
+// Notifications are the notifications of the forwards and the invoices of
+// the thresholds of the config, the most recent last.
+type Notifications struct {
+	cfg      config.Notifications
+	duration time.Duration
+	list     []*Notification
+	mu       sync.RWMutex
+}
+
+// NewNotifications returns the notifications of the default config.
+func NewNotifications() *Notifications {
+	return &Notifications{duration: defaultNotificationDuration}
+}
+
+// SetConfig replaces the thresholds and the duration of the notifications,
+// the duration is kept if it is invalid.
+func (n *Notifications) SetConfig(cfg config.Notifications) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cfg = cfg
+	if cfg.Duration == "" {
+		n.duration = defaultNotificationDuration
+		return nil
+	}
+	d, err := time.ParseDuration(cfg.Duration)
+	if err != nil || d <= 0 {
+		return errors.Errorf("invalid duration %q of the notifications", cfg.Duration)
+	}
+	n.duration = d
+	return nil
+}
+
+func (n *Notifications) List() []*Notification {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]*Notification{}, n.list...)
+}
+
+// Active returns the notifications younger than the duration, displayed
+// until they expire.
+func (n *Notifications) Active(now time.Time) []*Notification {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	i := len(n.list)
+	for i > 0 && now.Sub(n.list[i-1].Time) < n.duration {
+		i--
+	}
+	return append([]*Notification{}, n.list[i:]...)
+}
+
+func (n *Notifications) add(kind int, message string) {
+	if len(n.list) == MaxNotifications {
+		n.list = n.list[1:]
+	}
+	n.list = append(n.list, &Notification{Time: time.Now(), Kind: kind, Message: message})
+}
+
+// forward notifies the forward once it is settled, if it is large enough.
+func (n *Notifications) forward(e *models.RoutingEvent) {
+	if e.Direction != models.RoutingForward || e.Status != models.RoutingStatusSettled {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	rule := n.cfg.Forwards
+	min := rule.MinAmount
+	if min == 0 {
+		min = defaultForwardMin
+	}
+	if rule.Disabled || int64(e.AmountMsat/1000) < min {
+		return
+	}
+	p := message.NewPrinter(language.English)
+	n.add(NotificationForward, p.Sprintf("Forwarded %d sat, earned %d sat",
+		e.AmountMsat/1000, e.FeeMsat/1000))
+}
+
+func (n *Notifications) invoice(i *models.Invoice) {
+	if !i.Settled {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	rule := n.cfg.Invoices
+	if rule.Disabled || i.AmountPaid < rule.MinAmount {
+		return
+	}
+	p := message.NewPrinter(language.English)
+	if i.Description == "" {
+		n.add(NotificationInvoice, p.Sprintf("Invoice settled, received %d sat", i.AmountPaid))
+		return
+	}
+	n.add(NotificationInvoice, p.Sprintf("Invoice '%s' settled, received %d sat",
+		i.Description, i.AmountPaid))
+}
+
+// payment notifies the result of a payment sent from the ui, whatever its
+// amount.
+func (n *Notifications) payment(p *models.Payment) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	pr := message.NewPrinter(language.English)
+	switch p.Status {
+	case models.PaymentSucceeded:
+		n.add(NotificationPayment, pr.Sprintf("Payment of %d sat sent, fee %d sat", p.Amount, p.Fee))
+	case models.PaymentFailed:
+		n.add(NotificationPayment, pr.Sprintf("Payment of %d sat failed: %s", p.Amount, p.PaymentError))
+	}
+}
+
+// NotifyChannelDisabled notifies the channel of a ChannelDisabledByPeer
+// event and refreshes its policies.
+func (m *Models) NotifyChannelDisabled(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		cu, ok := update.(*models.ChannelUpdate)
+		if !ok {
+			m.logger.Error("notifyChannelDisabled: invalid event data")
+			return nil
+		}
+		channel := m.Channels.GetByChanPoint(cu.ChannelPoint)
+		if channel == nil {
+			return nil
+		}
+		alias, _ := channel.ShortAlias()
+		m.Notifications.mu.Lock()
+		m.Notifications.add(NotificationDisabled, fmt.Sprintf("%s disabled channel %s toward the node",
+			alias, models.ToScid(channel.ID)))
+		m.Notifications.mu.Unlock()
+		return m.RefreshPolicies(&models.ChannelEdgeUpdate{ChanPoints: []string{cu.ChannelPoint}})(ctx)
+	}
+}
+
+// NotifyKeysend notifies the result of a KeysendUpdated event.
+func (m *Models) NotifyKeysend(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		payment, ok := update.(*models.Payment)
+		if !ok {
+			m.logger.Error("notifyKeysend: invalid event data")
+			return nil
+		}
+		m.Notifications.payment(payment)
+		return nil
+	}
+}
+
+// NotifyInvoice notifies the invoice of an InvoiceSettled event.
+func (m *Models) NotifyInvoice(update interface{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		invoice, ok := update.(*models.Invoice)
+		if !ok {
+			m.logger.Error("notifyInvoice: invalid event data")
+			return nil
+		}
+		m.Notifications.invoice(invoice)
+		return nil
+	}
+}
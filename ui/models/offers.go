@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type OffersSort func(*models.Offer, *models.Offer) bool
+
+// Offers are the BOLT12 offers of the node, with the error of their last
+// refresh as not every backend supports them.
+type Offers struct {
+	list []*models.Offer
+	sort OffersSort
+	err  error
+	mu   sync.RWMutex
+}
+
+func (o *Offers) List() []*models.Offer {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.list
+}
+
+func (o *Offers) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.list)
+}
+
+func (o *Offers) Get(index int) *models.Offer {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if index < 0 || index > len(o.list)-1 {
+		return nil
+	}
+	return o.list[index]
+}
+
+// Err returns the error of the last refresh, nil if it succeeded.
+func (o *Offers) Err() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.err
+}
+
+func (o *Offers) Sort(fn OffersSort) {
+	if fn == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sort = fn
+	sort.SliceStable(o.list, func(i, j int) bool { return fn(o.list[i], o.list[j]) })
+}
+
+func (o *Offers) Update(list []*models.Offer, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.err = err
+	if err != nil {
+		return
+	}
+	o.list = list
+	if o.sort != nil {
+		sort.SliceStable(o.list, func(i, j int) bool { return o.sort(o.list[i], o.list[j]) })
+	}
+}
+
+func (m *Models) RefreshOffers(ctx context.Context) error {
+	offers, err := m.network.ListOffers(ctx)
+	m.Offers.Update(offers, err)
+	return err
+}
+
+// CreateOffer creates an offer of the amount in sat, zero for any amount,
+// and refreshes the offers.
+func (m *Models) CreateOffer(ctx context.Context, amount int64, description string) (*models.Offer, error) {
+	offer, err := m.network.CreateOffer(ctx, amount, description)
+	if err != nil {
+		return nil, err
+	}
+	return offer, m.RefreshOffers(ctx)
+}
+
+// DisableOffer disables the offer, it no longer accepts invoice requests,
+// and refreshes the offers.
+func (m *Models) DisableOffer(ctx context.Context, id string) error {
+	err := m.network.DisableOffer(ctx, id)
+	if err != nil {
+		return err
+	}
+	return m.RefreshOffers(ctx)
+}
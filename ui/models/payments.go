@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type PaymentsSort func(*models.Payment, *models.Payment) bool
+
+type Payments struct {
+	list []*models.Payment
+	sort PaymentsSort
+	// nodes are the nodes of the routes, nil if they are unknown.
+	nodes map[string]*models.Node
+	mu    sync.RWMutex
+}
+
+func (p *Payments) List() []*models.Payment {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.list
+}
+
+func (p *Payments) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.list)
+}
+
+func (p *Payments) Get(index int) *models.Payment {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if index < 0 || index > len(p.list)-1 {
+		return nil
+	}
+	return p.list[index]
+}
+
+// Alias returns the alias of the node of a route, empty if it is
+// unknown.
+func (p *Payments) Alias(pubkey string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if node := p.nodes[pubkey]; node != nil {
+		return node.Alias
+	}
+	return ""
+}
+
+func (p *Payments) Sort(fn PaymentsSort) {
+	if fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sort = fn
+	sort.SliceStable(p.list, func(i, j int) bool { return fn(p.list[i], p.list[j]) })
+}
+
+func (p *Payments) Update(list []*models.Payment) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.list = list
+	if p.sort != nil {
+		sort.SliceStable(p.list, func(i, j int) bool { return p.sort(p.list[i], p.list[j]) })
+	}
+}
+
+func NewPayments() *Payments {
+	return &Payments{
+		list:  []*models.Payment{},
+		nodes: make(map[string]*models.Node),
+	}
+}
+
+// RefreshPayments lists the payments with the nodes of their routes,
+// a node is looked up once.
+func (m *Models) RefreshPayments(ctx context.Context) error {
+	list, err := m.network.ListPayments(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range list {
+		pubkeys := []string{}
+		if p.Route != nil {
+			for _, hop := range p.Route.Hops {
+				pubkeys = append(pubkeys, hop.PubKey)
+			}
+		}
+		pubkeys = append(pubkeys, p.Destination())
+		for _, pubkey := range pubkeys {
+			m.Payments.mu.RLock()
+			_, ok := m.Payments.nodes[pubkey]
+			m.Payments.mu.RUnlock()
+			if ok || pubkey == "" {
+				continue
+			}
+			node, err := m.network.GetNode(ctx, pubkey, false)
+			if err != nil {
+				m.logger.Debug("refreshPayments: cannot find Node",
+					logging.String("pubkey", pubkey))
+				node = nil
+			}
+			m.Payments.mu.Lock()
+			m.Payments.nodes[pubkey] = node
+			m.Payments.mu.Unlock()
+		}
+	}
+
+	m.Payments.Update(list)
+	return nil
+}
+
+// SendPayment pays the payment request and returns once it succeeded or
+// failed, the PaymentUpdated events refresh the payments.
+func (m *Models) SendPayment(ctx context.Context, payreq *models.PayReq) (*models.Payment, error) {
+	return m.network.SendPayment(ctx, payreq)
+}
+
+// SendKeysend pays the keysend or the AMP payment, the updates of the
+// payment in flight are sent to the channel.
+func (m *Models) SendKeysend(ctx context.Context, k *models.Keysend, updates chan *models.Payment) (*models.Payment, error) {
+	return m.network.SendKeysend(ctx, k, updates)
+}
+
+// Rebalance moves the amount from the outgoing channel to the incoming
+// one with a circular payment, the attempts are sent to the channel.
+func (m *Models) Rebalance(ctx context.Context, out, in *models.Channel, amount, maxFee int64, attempts chan *models.RebalanceAttempt) (*models.Payment, error) {
+	return m.network.Rebalance(ctx, out, in, amount, maxFee, attempts)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/peerdata"
+)
+
+// PeerData is the metadata of the peers from the source of the config,
+// empty until it is fetched or if no source is configured.
+type PeerData struct {
+	source *peerdata.Source
+	mu     sync.RWMutex
+	peers  map[string]peerdata.Metadata
+}
+
+// Enabled returns true if a source is configured.
+func (p *PeerData) Enabled() bool {
+	return p.source != nil
+}
+
+// Interval returns the duration between two refreshes of the metadata.
+func (p *PeerData) Interval() time.Duration {
+	return p.source.Interval()
+}
+
+// Get returns the metadata of the node of the pubkey, false if it has
+// none.
+func (p *PeerData) Get(pubkey string) (peerdata.Metadata, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	md, ok := p.peers[pubkey]
+	return md, ok
+}
+
+// RefreshPeerData fetches the metadata from the source, the last one is
+// kept if it fails.
+func (m *Models) RefreshPeerData(ctx context.Context) error {
+	if m.PeerData.source == nil {
+		return nil
+	}
+	peers, err := m.PeerData.source.Fetch(ctx)
+	if peers != nil {
+		m.PeerData.mu.Lock()
+		m.PeerData.peers = peers
+		m.PeerData.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type PeersSort func(*models.Peer, *models.Peer) bool
+
+type Peers struct {
+	list []*models.Peer
+	sort PeersSort
+	// nodes are the nodes of the peers already fetched, the aliases are
+	// not fetched again at each refresh.
+	nodes map[string]*models.Node
+	// selected are the pubkeys of the peers selected for a batch open.
+	selected map[string]bool
+	mu       sync.RWMutex
+}
+
+func (p *Peers) List() []*models.Peer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.list
+}
+
+func (p *Peers) Len() int {
+	return len(p.list)
+}
+
+func (p *Peers) Swap(i, j int) {
+	p.list[i], p.list[j] = p.list[j], p.list[i]
+}
+
+func (p *Peers) Less(i, j int) bool {
+	return p.sort(p.list[i], p.list[j])
+}
+
+func (p *Peers) Sort(s PeersSort) {
+	if s == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sort = s
+	sort.Sort(p)
+}
+
+func (p *Peers) Get(index int) *models.Peer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if index < 0 || index > len(p.list)-1 {
+		return nil
+	}
+	return p.list[index]
+}
+
+// Update replaces the peers, the disconnected ones are removed and
+// unselected.
+func (p *Peers) Update(peers []*models.Peer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.list = peers
+	if p.sort != nil {
+		sort.Sort(p)
+	}
+	selected := make(map[string]bool, len(p.selected))
+	for _, peer := range peers {
+		if p.selected[peer.PubKey] {
+			selected[peer.PubKey] = true
+		}
+	}
+	p.selected = selected
+}
+
+// Toggle selects or unselects the peer.
+func (p *Peers) Toggle(pubkey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.selected[pubkey] {
+		delete(p.selected, pubkey)
+		return
+	}
+	p.selected[pubkey] = true
+}
+
+func (p *Peers) IsSelected(pubkey string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.selected[pubkey]
+}
+
+// Selected returns the selected peers in the order of the list.
+func (p *Peers) Selected() []*models.Peer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var selected []*models.Peer
+	for _, peer := range p.list {
+		if p.selected[peer.PubKey] {
+			selected = append(selected, peer)
+		}
+	}
+	return selected
+}
+
+// Unselect clears the selection.
+func (p *Peers) Unselect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.selected = make(map[string]bool)
+}
+
+// node returns the node of the peer already fetched, the refreshes of the
+// ui and of the control socket run at once.
+func (p *Peers) node(pubkey string) (*models.Node, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	node, ok := p.nodes[pubkey]
+	return node, ok
+}
+
+func (p *Peers) setNode(pubkey string, node *models.Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes[pubkey] = node
+}
+
+func NewPeers() *Peers {
+	return &Peers{
+		list:     []*models.Peer{},
+		nodes:    make(map[string]*models.Node),
+		selected: make(map[string]bool),
+	}
+}
+
+func (m *Models) RefreshPeers(ctx context.Context) error {
+	peers, err := m.network.ListPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range peers {
+		node, ok := m.Peers.node(peers[i].PubKey)
+		if !ok {
+			node, err = m.network.GetNode(ctx, peers[i].PubKey, false)
+			if err != nil {
+				m.logger.Debug("refreshPeers: cannot find Node",
+					logging.String("pubkey", peers[i].PubKey))
+			} else {
+				m.Peers.setNode(peers[i].PubKey, node)
+			}
+		}
+		peers[i].Node = node
+	}
+
+	m.Peers.Update(peers)
+	return nil
+}
+
+func (m *Models) ConnectPeer(ctx context.Context, pubkey, host string) error {
+	return m.network.ConnectPeer(ctx, pubkey, host)
+}
+
+func (m *Models) DisconnectPeer(ctx context.Context, pubkey string) error {
+	return m.network.DisconnectPeer(ctx, pubkey)
+}
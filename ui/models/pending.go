@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// PendingChannel is a channel opening or closing, Confirmations are the
+// ones of its funding transaction, -1 if it is not a transaction of the
+// wallet.
+type PendingChannel struct {
+	*models.Channel
+	Confirmations int32
+}
+
+type PendingChannelsSort func(*PendingChannel, *PendingChannel) bool
+
+// PendingChannels are the channels opening, closing, waiting for their
+// close to confirm and force closing.
+type PendingChannels struct {
+	list []*PendingChannel
+	sort PendingChannelsSort
+	mu   sync.RWMutex
+}
+
+func (p *PendingChannels) List() []*PendingChannel {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.list
+}
+
+func (p *PendingChannels) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.list)
+}
+
+func (p *PendingChannels) Get(index int) *PendingChannel {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if index < 0 || index > len(p.list)-1 {
+		return nil
+	}
+	return p.list[index]
+}
+
+// Limbo returns the balance of the pending channels locked until they
+// are resolved.
+func (p *PendingChannels) Limbo() int64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var limbo int64
+	for _, c := range p.list {
+		if c.Closing != nil {
+			limbo += c.Closing.LimboBalance
+		}
+	}
+	return limbo
+}
+
+func (p *PendingChannels) Sort(fn PendingChannelsSort) {
+	if fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sort = fn
+	sort.SliceStable(p.list, func(i, j int) bool { return fn(p.list[i], p.list[j]) })
+}
+
+func (p *PendingChannels) Update(list []*PendingChannel) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.list = list
+	if p.sort != nil {
+		sort.SliceStable(p.list, func(i, j int) bool { return p.sort(p.list[i], p.list[j]) })
+	}
+}
+
+// RefreshPendingChannels lists the pending channels of the channels with
+// the confirmations of their funding transaction in the wallet, it is
+// called at each refresh of the channels.
+func (m *Models) RefreshPendingChannels(ctx context.Context) error {
+	confs := map[string]int32{}
+	for _, tx := range m.Transactions.List() {
+		confs[tx.TxHash] = tx.NumConfirmations
+	}
+
+	var list []*PendingChannel
+	for _, c := range m.Channels.List() {
+		switch c.Status {
+		case models.ChannelOpening, models.ChannelClosing,
+			models.ChannelForceClosing, models.ChannelWaitingClose:
+		default:
+			continue
+		}
+		txid, _, _ := strings.Cut(c.ChannelPoint, ":")
+		n, ok := confs[txid]
+		if !ok {
+			n = -1
+		}
+		list = append(list, &PendingChannel{Channel: c, Confirmations: n})
+	}
+	m.PendingChannels.Update(list)
+	return nil
+}
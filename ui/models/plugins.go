@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edouardparis/lntop/export"
+	"github.com/edouardparis/lntop/plugin"
+)
+
+type Plugins struct {
+	list    []*plugin.Plugin
+	results map[string]*plugin.Result
+	mu      sync.RWMutex
+}
+
+func (p *Plugins) Add(pl *plugin.Plugin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.list = append(p.list, pl)
+}
+
+func (p *Plugins) List() []*plugin.Plugin {
+	return p.list
+}
+
+// Result returns the last result of the plugin, nil if it never answered.
+func (p *Plugins) Result(name string) *plugin.Result {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.results[name]
+}
+
+// ChannelValue returns the value of the plugin column for the channel.
+func (p *Plugins) ChannelValue(name, chanPoint string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	r, ok := p.results[name]
+	if !ok {
+		return ""
+	}
+	return r.Channels[chanPoint]
+}
+
+func (p *Plugins) set(name string, r *plugin.Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[name] = r
+}
+
+func (p *Plugins) Close() {
+	for i := range p.list {
+		p.list[i].Close()
+	}
+}
+
+func NewPlugins() *Plugins {
+	return &Plugins{results: make(map[string]*plugin.Result)}
+}
+
+type pluginNode struct {
+	PubKey      string `json:"pubkey"`
+	Alias       string `json:"alias"`
+	BlockHeight uint32 `json:"block_height"`
+}
+
+type renderParams struct {
+	Node     pluginNode       `json:"node"`
+	Channels []export.Channel `json:"channels"`
+}
+
+// RefreshPlugin sends the node and the channels to the plugin and keeps
+// its result.
+func (m *Models) RefreshPlugin(pl *plugin.Plugin) func(context.Context) error {
+	return func(ctx context.Context) error {
+		params := renderParams{Channels: []export.Channel{}}
+		if m.Info.Info != nil {
+			params.Node = pluginNode{
+				PubKey:      m.Info.PubKey,
+				Alias:       m.Info.Alias,
+				BlockHeight: m.Info.BlockHeight,
+			}
+		}
+		for _, ch := range m.Channels.List() {
+			params.Channels = append(params.Channels, export.NewChannel(ch))
+		}
+
+		result, err := pl.Render(ctx, params)
+		if err != nil {
+			return err
+		}
+		m.Plugins.set(pl.Name(), result)
+		return nil
+	}
+}
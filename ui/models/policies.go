@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/store"
+)
+
+// PolicyPeriod is a recorded change of a policy of the current channel
+// with the forwards of the forwarding history through the channel until
+// the next change.
+type PolicyPeriod struct {
+	*store.PolicyChange
+	ForwardsIn  int
+	AmountIn    uint64
+	ForwardsOut int
+	AmountOut   uint64
+	FeeMsat     uint64
+}
+
+// RefreshPolicyHistory reads the policy changes of the current channel
+// from the store, the history is empty without a store.
+func (m *Models) RefreshPolicyHistory() {
+	cur := m.Channels.Current()
+	if m.store == nil || cur == nil {
+		m.Channels.CurrentHistory = nil
+		return
+	}
+	changes, err := m.store.PolicyChanges(cur.ChannelPoint)
+	if err != nil {
+		m.logger.Error("cannot read policy history", logging.Error(err))
+		return
+	}
+	m.Channels.CurrentHistory = policyPeriods(cur, changes, m.FwdingHist.List(), time.Now())
+}
+
+func policyPeriods(ch *models.Channel, changes []*store.PolicyChange, forwards []*models.ForwardingEvent, now time.Time) []*PolicyPeriod {
+	periods := make([]*PolicyPeriod, len(changes))
+	for i := range changes {
+		end := now
+		if i+1 < len(changes) {
+			end = changes[i+1].Time
+		}
+		period := &PolicyPeriod{PolicyChange: changes[i]}
+		for _, f := range forwards {
+			if f.EventTime.Before(changes[i].Time) || !f.EventTime.Before(end) {
+				continue
+			}
+			if f.ChanIdIn == ch.ID {
+				period.ForwardsIn++
+				period.AmountIn += f.AmtIn
+			}
+			if f.ChanIdOut == ch.ID {
+				period.ForwardsOut++
+				period.AmountOut += f.AmtOut
+				period.FeeMsat += f.FeeMsat
+			}
+		}
+		periods[i] = period
+	}
+	return periods
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/price"
+)
+
+// Price is the price of a bitcoin in the fiat currency of the config,
+// unknown until it is fetched or if no provider is configured.
+type Price struct {
+	provider price.Provider
+	mu       sync.RWMutex
+	rate     float64
+	updated  time.Time
+}
+
+// Enabled returns true if a provider is configured.
+func (p *Price) Enabled() bool {
+	return p.provider != nil
+}
+
+// Currency returns the fiat currency, empty if no provider is configured.
+func (p *Price) Currency() string {
+	if p.provider == nil {
+		return ""
+	}
+	return p.provider.Currency()
+}
+
+// Interval returns the duration between two refreshes of the rate.
+func (p *Price) Interval() time.Duration {
+	return p.provider.Interval()
+}
+
+// Rate returns the price of a bitcoin and the time it was fetched, zero
+// if it is unknown.
+func (p *Price) Rate() (float64, time.Time) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rate, p.updated
+}
+
+// Fiat returns the value of the amount in sat, false if the rate is
+// unknown.
+func (p *Price) Fiat(sat float64) (float64, bool) {
+	rate, _ := p.Rate()
+	if rate == 0 {
+		return 0, false
+	}
+	return sat * rate / 1e8, true
+}
+
+func (p *Price) set(rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rate = rate
+	p.updated = time.Now()
+}
+
+// RefreshPrice fetches the rate from the provider, the last rate is kept
+// if it fails.
+func (m *Models) RefreshPrice(ctx context.Context) error {
+	if m.Price.provider == nil {
+		return nil
+	}
+	rate, err := m.Price.provider.Rate(ctx)
+	if err != nil {
+		return err
+	}
+	m.Price.set(rate)
+	return nil
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+const (
+	defaultChartWindow   = 24 * time.Hour
+	defaultChartInterval = time.Hour
+)
+
+// RoutingBucket is the forwards settled during an interval of the chart.
+type RoutingBucket struct {
+	Time       time.Time
+	Forwards   int
+	AmountMsat uint64
+	FeeMsat    uint64
+}
+
+// RoutingChart is the forwards settled by interval over the window of the
+// chart, read from the store.
+type RoutingChart struct {
+	enabled  bool
+	window   time.Duration
+	interval time.Duration
+	mu       sync.RWMutex
+	buckets  []*RoutingBucket
+}
+
+func NewRoutingChart() *RoutingChart {
+	return &RoutingChart{window: defaultChartWindow, interval: defaultChartInterval}
+}
+
+// setConfig sets the window and the interval of the config, the default
+// ones are kept if they are invalid.
+func (c *RoutingChart) setConfig(cfg config.RoutingChart) error {
+	window, interval := defaultChartWindow, defaultChartInterval
+	var err error
+	if cfg.Window != "" {
+		window, err = time.ParseDuration(cfg.Window)
+		if err != nil || window <= 0 {
+			return errors.Errorf("invalid window %q of the routing chart", cfg.Window)
+		}
+	}
+	if cfg.Interval != "" {
+		interval, err = time.ParseDuration(cfg.Interval)
+		if err != nil || interval <= 0 || interval > window {
+			return errors.Errorf("invalid interval %q of the routing chart", cfg.Interval)
+		}
+	}
+	c.window, c.interval = window, interval
+	return nil
+}
+
+// Enabled returns true if the chart is not disabled and a store is open.
+func (c *RoutingChart) Enabled() bool {
+	return c.enabled
+}
+
+func (c *RoutingChart) Window() time.Duration {
+	return c.window
+}
+
+func (c *RoutingChart) Interval() time.Duration {
+	return c.interval
+}
+
+// Buckets returns the buckets of the window, oldest first.
+func (c *RoutingChart) Buckets() []*RoutingBucket {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	buckets := make([]*RoutingBucket, len(c.buckets))
+	for i := range c.buckets {
+		b := *c.buckets[i]
+		buckets[i] = &b
+	}
+	return buckets
+}
+
+// routingBuckets counts the forwards settled of the events in the buckets
+// of the window ending in the interval of now.
+func routingBuckets(events []*models.RoutingEvent, window, interval time.Duration, now time.Time) []*RoutingBucket {
+	n := int((window + interval - 1) / interval)
+	start := now.Truncate(interval).Add(-time.Duration(n-1) * interval)
+	buckets := make([]*RoutingBucket, n)
+	for i := range buckets {
+		buckets[i] = &RoutingBucket{Time: start.Add(time.Duration(i) * interval)}
+	}
+	for _, e := range events {
+		if e.Direction != models.RoutingForward || e.Status != models.RoutingStatusSettled ||
+			e.LastUpdate.Before(start) {
+			continue
+		}
+		i := min(int(e.LastUpdate.Sub(start)/interval), n-1)
+		buckets[i].Forwards++
+		buckets[i].AmountMsat += e.AmountMsat
+		buckets[i].FeeMsat += e.FeeMsat
+	}
+	return buckets
+}
+
+// add counts the forward settled in the last bucket until the next read of
+// the store, the buckets of the window roll forward.
+func (c *RoutingChart) add(e *models.RoutingEvent) {
+	if !c.enabled || e.Direction != models.RoutingForward || e.Status != models.RoutingStatusSettled {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.buckets) == 0 {
+		return
+	}
+	buckets := c.buckets
+	for !e.LastUpdate.Before(buckets[len(buckets)-1].Time.Add(c.interval)) {
+		next := &RoutingBucket{Time: buckets[len(buckets)-1].Time.Add(c.interval)}
+		buckets = append(buckets[1:], next)
+	}
+	last := buckets[len(buckets)-1]
+	last.Forwards++
+	last.AmountMsat += e.AmountMsat
+	last.FeeMsat += e.FeeMsat
+	c.buckets = buckets
+}
+
+// RefreshRoutingChart reads the routing events of the window from the
+// store.
+func (m *Models) RefreshRoutingChart() error {
+	c := m.RoutingChart
+	if !c.enabled {
+		return nil
+	}
+	now := time.Now()
+	events, err := m.store.RoutingEventsSince(now.Add(-c.window - c.interval))
+	if err != nil {
+		return err
+	}
+	buckets := routingBuckets(events, c.window, c.interval, now)
+	c.mu.Lock()
+	c.buckets = buckets
+	c.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// searchFilter is the key of the filter of the search of the channels.
+const searchFilter = "search"
+
+// SetSearch displays only the channels matching the query, an empty
+// query displays them all again. The alias matches if it contains the
+// characters of the query in order, the pubkey, the channel id, the
+// short channel id, the channel point and the addresses of the peer if
+// they contain the query. A query with the special characters of a
+// regular expression is one if it compiles, matched with every field.
+func (c *Channels) SetSearch(query string) {
+	query = strings.TrimSpace(query)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.search = query
+	if query == "" {
+		delete(c.filters, searchFilter)
+		return
+	}
+	c.filters[searchFilter] = searchQuery(query)
+}
+
+// Search returns the query of the search, empty if none.
+func (c *Channels) Search() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.search
+}
+
+func searchQuery(query string) ChannelsFilter {
+	if regexp.QuoteMeta(query) != query {
+		re, err := regexp.Compile("(?i)" + query)
+		if err == nil {
+			return func(ch *models.Channel) bool {
+				aliases, fields := searchFields(ch)
+				for _, f := range append(aliases, fields...) {
+					if re.MatchString(f) {
+						return true
+					}
+				}
+				return false
+			}
+		}
+	}
+
+	query = strings.ToLower(query)
+	return func(ch *models.Channel) bool {
+		aliases, fields := searchFields(ch)
+		for _, alias := range aliases {
+			if fuzzyMatch(strings.ToLower(alias), query) {
+				return true
+			}
+		}
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), query) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// searchFields returns the aliases of the channel and its other fields
+// searched.
+func searchFields(ch *models.Channel) ([]string, []string) {
+	var aliases []string
+	fields := []string{
+		ch.RemotePubKey,
+		strconv.FormatUint(ch.ID, 10),
+		models.ToScid(ch.ID),
+		ch.ChannelPoint,
+	}
+	if ch.Node != nil {
+		aliases = append(aliases, ch.Node.Alias, ch.Node.ForcedAlias)
+		for _, addr := range ch.Node.Addresses {
+			if addr != nil {
+				fields = append(fields, addr.Addr)
+			}
+		}
+	}
+	return aliases, fields
+}
+
+// fuzzyMatch returns true if s contains the characters of query in
+// their order, not necessarily next to each other.
+func fuzzyMatch(s, query string) bool {
+	for _, r := range query {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
+)
+
+// Session is the rolling rates of the forwards of the routing events
+// since lntop started.
+type Session struct {
+	session *stats.Session
+	mu      sync.Mutex
+}
+
+func NewSession(window time.Duration) *Session {
+	return &Session{session: stats.NewSession(window, time.Now())}
+}
+
+func (s *Session) add(e *models.RoutingEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session.Add(e)
+}
+
+// Rates returns the rates of the window ending now.
+func (s *Session) Rates() stats.SessionRates {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.session.Rates(time.Now())
+}
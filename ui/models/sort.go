@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"strings"
 	"time"
 )
@@ -78,6 +79,20 @@ func StringSort(a, b string, o Order) bool {
 	return result > 0
 }
 
+// ValueSort compares the values as numbers if both are numbers, as
+// strings otherwise.
+func ValueSort(a, b string, o Order) bool {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		if o == Asc {
+			return fa < fb
+		}
+		return fa > fb
+	}
+	return StringSort(a, b, o)
+}
+
 func BoolSort(a, b bool, o Order) bool {
 	if o == Asc {
 		return !a && b
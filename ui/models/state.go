@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+// NodeState is the last state of the node sent by the pubsub, ready
+// until it says otherwise.
+type NodeState struct {
+	state models.NodeState
+	since time.Time
+	mu    sync.RWMutex
+}
+
+// Get returns the state and the time it was received.
+func (s *NodeState) Get() (models.NodeState, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state, s.since
+}
+
+func (s *NodeState) Ready() bool {
+	state, _ := s.Get()
+	return state.Ready()
+}
+
+func (m *Models) RefreshNodeState(state models.NodeState) func(context.Context) error {
+	return func(ctx context.Context) error {
+		m.NodeState.mu.Lock()
+		m.NodeState.state = state
+		m.NodeState.since = time.Now()
+		m.NodeState.mu.Unlock()
+		return nil
+	}
+}
+
+// Connection is the last state of the connection to the node sent by the
+// pubsub, connected until it says otherwise.
+type Connection struct {
+	connection models.Connection
+	mu         sync.RWMutex
+}
+
+func (c *Connection) Get() models.Connection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.connection
+}
+
+func (m *Models) RefreshConnection(connection models.Connection) func(context.Context) error {
+	return func(ctx context.Context) error {
+		m.Connection.mu.Lock()
+		m.Connection.connection = connection
+		m.Connection.mu.Unlock()
+		return nil
+	}
+}
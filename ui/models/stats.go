@@ -0,0 +1,22 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edouardparis/lntop/stats"
+)
+
+// RefreshChannelStats aggregates the forwarding history of the longest
+// window of the stats, whatever the range of the forwarding history
+// view.
+func (m *Models) RefreshChannelStats(ctx context.Context) error {
+	start := fmt.Sprintf("-%ds", int64(stats.Period().Seconds()))
+	forwards, err := m.network.GetForwardingHistory(ctx, start, 0)
+	if err != nil {
+		return err
+	}
+	m.Channels.setStats(stats.NewReport(forwards, time.Now()))
+	return nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type SweepsSort func(*models.PendingSweep, *models.PendingSweep) bool
+
+type Sweeps struct {
+	list []*models.PendingSweep
+	sort SweepsSort
+	mu   sync.RWMutex
+}
+
+func (s *Sweeps) List() []*models.PendingSweep {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list
+}
+
+func (s *Sweeps) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.list)
+}
+
+func (s *Sweeps) Get(index int) *models.PendingSweep {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if index < 0 || index > len(s.list)-1 {
+		return nil
+	}
+	return s.list[index]
+}
+
+func (s *Sweeps) Sort(fn SweepsSort) {
+	if fn == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sort = fn
+	sort.SliceStable(s.list, func(i, j int) bool { return fn(s.list[i], s.list[j]) })
+}
+
+// ForTx returns the pending sweeps of the outputs of the transaction,
+// e.g. the anchor and the outputs of a closing transaction.
+func (s *Sweeps) ForTx(txid string) []*models.PendingSweep {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var sweeps []*models.PendingSweep
+	for _, sweep := range s.list {
+		if sweep.TxID() == txid {
+			sweeps = append(sweeps, sweep)
+		}
+	}
+	return sweeps
+}
+
+func (s *Sweeps) Update(list []*models.PendingSweep) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.list = list
+	if s.sort != nil {
+		sort.SliceStable(s.list, func(i, j int) bool { return s.sort(s.list[i], s.list[j]) })
+	}
+}
+
+func (m *Models) RefreshPendingSweeps(ctx context.Context) error {
+	sweeps, err := m.network.PendingSweeps(ctx)
+	if err != nil {
+		return err
+	}
+	m.Sweeps.Update(sweeps)
+	return nil
+}
+
+// BumpFee requests a new fee rate for the sweep of the outpoint and
+// refreshes the pending sweeps.
+func (m *Models) BumpFee(ctx context.Context, outpoint string, satPerVbyte uint64) error {
+	err := m.network.BumpFee(ctx, outpoint, satPerVbyte)
+	if err != nil {
+		return err
+	}
+	return m.RefreshPendingSweeps(ctx)
+}
+
+// refreshClosingSweeps refreshes the pending sweeps while channels are
+// closing, the sweeps of the closing transactions change at each block.
+func (m *Models) refreshClosingSweeps(ctx context.Context) {
+	for _, c := range m.Channels.List() {
+		if c.Closing != nil && c.Status != models.ChannelClosed {
+			err := m.RefreshPendingSweeps(ctx)
+			if err != nil {
+				m.logger.Debug("refreshChannels: cannot list pending sweeps", logging.Error(err))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type TowersSort func(*models.Tower, *models.Tower) bool
+
+// Towers are the towers of the watchtower client with its backup counts
+// and the policies of its new sessions.
+type Towers struct {
+	list     []*models.Tower
+	stats    models.TowerStats
+	policies []*models.TowerPolicy
+	sort     TowersSort
+	mu       sync.RWMutex
+}
+
+func (t *Towers) List() []*models.Tower {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.list
+}
+
+func (t *Towers) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.list)
+}
+
+func (t *Towers) Get(index int) *models.Tower {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if index < 0 || index > len(t.list)-1 {
+		return nil
+	}
+	return t.list[index]
+}
+
+// Stats returns the backup counts of the client.
+func (t *Towers) Stats() models.TowerStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.stats
+}
+
+// Policies returns the policies of the new sessions by channel type.
+func (t *Towers) Policies() []*models.TowerPolicy {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.policies
+}
+
+func (t *Towers) Sort(fn TowersSort) {
+	if fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sort = fn
+	sort.SliceStable(t.list, func(i, j int) bool { return fn(t.list[i], t.list[j]) })
+}
+
+func (t *Towers) Update(wt *models.Watchtower) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.list = wt.Towers
+	t.stats = wt.Stats
+	t.policies = wt.Policies
+	if t.sort != nil {
+		sort.SliceStable(t.list, func(i, j int) bool { return t.sort(t.list[i], t.list[j]) })
+	}
+}
+
+func (m *Models) RefreshTowers(ctx context.Context) error {
+	wt, err := m.network.Watchtower(ctx)
+	if err != nil {
+		return err
+	}
+	m.Towers.Update(wt)
+	return nil
+}
+
+// AddTower registers the tower and refreshes the towers.
+func (m *Models) AddTower(ctx context.Context, pubkey, address string) error {
+	err := m.network.AddTower(ctx, pubkey, address)
+	if err != nil {
+		return err
+	}
+	return m.RefreshTowers(ctx)
+}
+
+// RemoveTower removes the tower and refreshes the towers.
+func (m *Models) RemoveTower(ctx context.Context, pubkey string) error {
+	err := m.network.RemoveTower(ctx, pubkey)
+	if err != nil {
+		return err
+	}
+	return m.RefreshTowers(ctx)
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/store"
+)
+
+// uptimeRecord is the interval between two records of the uptimes of all
+// the peers, the ones whose status changed are recorded at once.
+const uptimeRecord = 5 * time.Minute
+
+// PeerUptime returns the uptime of the peer seen by lntop, false if it was
+// never seen.
+func (c *Channels) PeerUptime(pubkey string) (store.PeerUptime, bool) {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+	u, ok := c.uptimes[pubkey]
+	if !ok {
+		return store.PeerUptime{}, false
+	}
+	return *u, true
+}
+
+// observeUptime counts the time since the last observation of the peers as
+// online or offline and the peers coming back online. It returns copies of
+// the uptimes to record, the ones of the peers whose status changed or all
+// of them every uptimeRecord.
+func (c *Channels) observeUptime(active map[string]bool, now time.Time) map[string]*store.PeerUptime {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	all := now.Sub(c.uptimeRecorded) >= uptimeRecord
+	if all {
+		c.uptimeRecorded = now
+	}
+	record := map[string]*store.PeerUptime{}
+	for pubkey, a := range active {
+		u, ok := c.uptimes[pubkey]
+		if !ok {
+			u = &store.PeerUptime{}
+			c.uptimes[pubkey] = u
+		}
+		changed := u.Seen.IsZero() || u.Active != a
+		if !u.Seen.IsZero() {
+			if u.Active {
+				u.Online += now.Sub(u.Seen)
+			} else {
+				u.Offline += now.Sub(u.Seen)
+			}
+			if !u.Active && a {
+				u.Flaps++
+			}
+		}
+		u.Active = a
+		u.Seen = now
+		if changed || all {
+			r := *u
+			record[pubkey] = &r
+		}
+	}
+	return record
+}
+
+// LoadPeerUptimes reads the uptimes of the peers recorded in the store, if
+// any. The time lntop was not running is not counted.
+func (m *Models) LoadPeerUptimes() error {
+	if m.store == nil {
+		return nil
+	}
+	uptimes, err := m.store.PeerUptimes()
+	if err != nil {
+		return err
+	}
+	m.Channels.healthMu.Lock()
+	defer m.Channels.healthMu.Unlock()
+	for pubkey, u := range uptimes {
+		u.Seen = time.Time{}
+		m.Channels.uptimes[pubkey] = u
+	}
+	return nil
+}
+
+// refreshUptime observes the peers of the open channels, a peer is online
+// if one of its channels is active, and records their uptimes.
+func (m *Models) refreshUptime() {
+	active := map[string]bool{}
+	for _, ch := range m.Channels.List() {
+		switch ch.Status {
+		case models.ChannelActive:
+			active[ch.RemotePubKey] = true
+		case models.ChannelInactive:
+			if _, ok := active[ch.RemotePubKey]; !ok {
+				active[ch.RemotePubKey] = false
+			}
+		}
+	}
+	record := m.Channels.observeUptime(active, time.Now())
+	if m.store == nil || len(record) == 0 {
+		return
+	}
+	err := m.store.SetPeerUptimes(record)
+	if err != nil {
+		m.logger.Error("cannot record the uptime of the peers", logging.Error(err))
+	}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/edouardparis/lntop/network/models"
+)
+
+type UTXOsSort func(*models.UTXO, *models.UTXO) bool
+
+type UTXOs struct {
+	list []*models.UTXO
+	sort UTXOsSort
+	// selected are the outpoints of the outputs selected for a
+	// consolidation.
+	selected map[string]bool
+	mu       sync.RWMutex
+}
+
+func (u *UTXOs) List() []*models.UTXO {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.list
+}
+
+func (u *UTXOs) Len() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return len(u.list)
+}
+
+func (u *UTXOs) Get(index int) *models.UTXO {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if index < 0 || index > len(u.list)-1 {
+		return nil
+	}
+	return u.list[index]
+}
+
+func (u *UTXOs) Sort(s UTXOsSort) {
+	if s == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sort = s
+	sort.SliceStable(u.list, func(i, j int) bool { return s(u.list[i], u.list[j]) })
+}
+
+// Update replaces the outputs, the spent outputs are unselected.
+func (u *UTXOs) Update(list []*models.UTXO) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.list = list
+	if u.sort != nil {
+		sort.SliceStable(u.list, func(i, j int) bool { return u.sort(u.list[i], u.list[j]) })
+	}
+	selected := make(map[string]bool, len(u.selected))
+	for _, utxo := range list {
+		if u.selected[utxo.Outpoint] {
+			selected[utxo.Outpoint] = true
+		}
+	}
+	u.selected = selected
+}
+
+// Toggle selects or unselects the output.
+func (u *UTXOs) Toggle(outpoint string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.selected[outpoint] {
+		delete(u.selected, outpoint)
+		return
+	}
+	u.selected[outpoint] = true
+}
+
+func (u *UTXOs) IsSelected(outpoint string) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.selected[outpoint]
+}
+
+// Selected returns the selected outputs in the order of the list.
+func (u *UTXOs) Selected() []*models.UTXO {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	var selected []*models.UTXO
+	for _, utxo := range u.list {
+		if u.selected[utxo.Outpoint] {
+			selected = append(selected, utxo)
+		}
+	}
+	return selected
+}
+
+func NewUTXOs() *UTXOs {
+	return &UTXOs{
+		list:     []*models.UTXO{},
+		selected: make(map[string]bool),
+	}
+}
+
+func (m *Models) RefreshUTXOs(ctx context.Context) error {
+	utxos, err := m.network.ListUnspent(ctx)
+	if err != nil {
+		return err
+	}
+	m.UTXOs.Update(utxos)
+	return nil
+}
+
+// Consolidate spends the selected outputs to a new output of the wallet
+// at the fee rate, the outputs and the balance are then refreshed.
+func (m *Models) Consolidate(ctx context.Context, satPerVbyte uint64) (*models.Consolidation, error) {
+	c, err := m.network.Consolidate(ctx, m.UTXOs.Selected(), satPerVbyte)
+	if err != nil {
+		return nil, err
+	}
+	err = m.RefreshUTXOs(ctx)
+	if err != nil {
+		return c, err
+	}
+	return c, m.RefreshWalletBalance(ctx)
+}
+
+// LabelUTXO sets the label of the transaction of the output.
+func (m *Models) LabelUTXO(ctx context.Context, utxo *models.UTXO, label string) error {
+	err := m.network.LabelTransaction(ctx, utxo.TxID(), label)
+	if err != nil {
+		return err
+	}
+	return m.RefreshUTXOs(ctx)
+}
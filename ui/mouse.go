@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/cursor"
+)
+
+// wheelRows is the number of rows scrolled by a step of the wheel.
+const wheelRows = 3
+
+// mousePress is the left button pressed on the column headers of the main
+// view, released on them it sorts the column, elsewhere it moves the
+// split between the summary and the main view.
+type mousePress struct {
+	x, y int
+}
+
+// trackDrags stops the tracking of the moves of the mouse enabled by
+// gocui, which moves the cursor of the view under the mouse, only the
+// moves with a button pressed are reported. It must run in the main loop,
+// once the mouse is enabled.
+func trackDrags(g *gocui.Gui) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return nil
+	}
+	defer tty.Close()
+	_, _ = tty.WriteString("\x1b[?1003l\x1b[?1002h\x1b[?1006h")
+	return nil
+}
+
+// mainAt returns the position in the page of the main view of the point of
+// the screen, the row of the column headers is -1. ok is false if the
+// point is out of the main view.
+func (c *controller) mainAt(g *gocui.Gui, mx, my int) (x, row int, ok bool) {
+	if c.views.Main == nil {
+		return 0, 0, false
+	}
+	v, err := g.View(c.views.Main.Name())
+	if err != nil {
+		return 0, 0, false
+	}
+	x0, y0, x1, y1 := v.Dimensions()
+	if mx <= x0 || mx >= x1 || my < y0 || my >= y1 {
+		return 0, 0, false
+	}
+	return mx - x0 - 1, my - y0 - 1, true
+}
+
+// mouseDown selects the row clicked of the main view or opens the view
+// clicked in the menu. A click out of the menu closes it.
+func (c *controller) mouseDown(g *gocui.Gui, v *gocui.View) error {
+	mx, my := g.MousePosition()
+	current := g.CurrentView()
+	if current == nil || c.views.Main == nil {
+		return nil
+	}
+	if current.Name() == c.views.Menu.Name() {
+		if v != nil && v.Name() == c.views.Menu.Name() {
+			_, y0, _, _ := v.Dimensions()
+			err := cursor.Click(c.views.Get(v), 0, my-y0-1)
+			if err != nil {
+				return err
+			}
+			return c.OnEnter(g, v)
+		}
+		_, _, ok := c.mainAt(g, mx, my)
+		if !ok {
+			return nil
+		}
+		err := c.Menu(g, current)
+		if err != nil {
+			return err
+		}
+	} else if current.Name() != c.views.Main.Name() {
+		// a popup has the focus.
+		return nil
+	}
+
+	x, row, ok := c.mainAt(g, mx, my)
+	if !ok {
+		return nil
+	}
+	if row < 0 {
+		c.press = &mousePress{x: x, y: my}
+		return nil
+	}
+	return cursor.Click(c.views.Main, x, row)
+}
+
+// mouseUp sorts the column of the headers clicked, or moves the split if
+// the headers were dragged.
+func (c *controller) mouseUp(g *gocui.Gui, v *gocui.View) error {
+	c.resetCursors(g)
+	press := c.press
+	c.press = nil
+	if press == nil {
+		return nil
+	}
+	_, my := g.MousePosition()
+	if my != press.y {
+		_, maxY := g.Size()
+		c.views.SetSplit(c.views.Split()+my-press.y, maxY)
+		return nil
+	}
+	return c.sortAt(g, press.x)
+}
+
+// sortAt sorts the main view by the column at x, ascending then reversed
+// at each click.
+func (c *controller) sortAt(g *gocui.Gui, x int) error {
+	main := c.views.Main
+	_, cy := main.Cursor()
+	err := cursor.Click(main, x, cy)
+	if err != nil {
+		return err
+	}
+	v, err := g.View(main.Name())
+	if err != nil {
+		return nil
+	}
+	return c.ToggleOrder(g, v)
+}
+
+// wheel scrolls the view with the focus, or the main view under the mouse
+// if the menu has the focus.
+func (c *controller) wheel(down bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		c.resetCursors(g)
+		current := g.CurrentView()
+		if current == nil {
+			return nil
+		}
+		if current.Name() == c.views.Menu.Name() {
+			mx, my := g.MousePosition()
+			if _, _, ok := c.mainAt(g, mx, my); ok {
+				current, _ = g.View(c.views.Main.Name())
+			}
+		}
+		view := c.views.Get(current)
+		if view == nil {
+			return nil
+		}
+		move := cursor.Up
+		if down {
+			move = cursor.Down
+		}
+		for i := 0; i < wheelRows; i++ {
+			err := move(view)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// resetCursors sets again the cursors of the main view and of the menu,
+// gocui moves the cursor of the view under the mouse at each event.
+func (c *controller) resetCursors(g *gocui.Gui) {
+	names := []string{c.views.Menu.Name()}
+	if c.views.Main != nil {
+		names = append(names, c.views.Main.Name())
+	}
+	for _, name := range names {
+		v, err := g.View(name)
+		if err != nil {
+			continue
+		}
+		if view := c.views.Get(v); view != nil {
+			view.SetCursor(view.Cursor())
+		}
+	}
+}
@@ -7,22 +7,64 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/edouardparis/lntop/app"
+	"github.com/edouardparis/lntop/control"
 	"github.com/edouardparis/lntop/events"
+	"github.com/edouardparis/lntop/logging"
+	"github.com/edouardparis/lntop/ui/color"
 )
 
-func Run(ctx context.Context, app *app.App, sub chan *events.Event) error {
-	g, err := gocui.NewGui(gocui.Output256, false)
+// Node is a node displayed by the ui, its models are refreshed at the
+// events of Events.
+type Node struct {
+	App    *app.App
+	Events chan *events.Event
+}
+
+// Run runs the ui until it is quit, touch is called at each key pressed
+// if not nil.
+func Run(ctx context.Context, app *app.App, sub chan *events.Event, touch func()) error {
+	return RunNodes(ctx, []Node{{App: app, Events: sub}}, touch)
+}
+
+// RunNodes runs the ui displaying the first node, n switches to the next
+// one. The plugins and the control server are the ones of the config of
+// the first node.
+func RunNodes(ctx context.Context, nodes []Node, touch func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cfg := nodes[0].App.Config.Theme
+	theme, err := color.NewTheme(cfg.Name, cfg.Colors)
 	if err != nil {
 		return err
 	}
-	defer g.Close()
+	color.SetTheme(theme, cfg.TrueColor)
 
-	g.Cursor = false
-	ctrl := newController(app)
-	err = ctrl.SetModels(ctx)
+	g, err := gocui.NewGui(color.OutputMode(), false)
 	if err != nil {
 		return err
 	}
+	defer g.Close()
+
+	g.Cursor = false
+	app := nodes[0].App
+	g.Mouse = !app.Config.Mouse.Disabled
+	if g.Mouse {
+		g.Update(trackDrags)
+	}
+	ctrl := newController(nodes)
+	ctrl.touch = touch
+	for i, n := range ctrl.nodes {
+		err = ctrl.refreshModels(ctx, n.models)
+		if err == nil {
+			continue
+		}
+		if i == 0 {
+			return err
+		}
+		// the node is refreshed once its pubsub sees it ready.
+		ctrl.logger.Error("cannot refresh node", logging.String("node", n.name), logging.Error(err))
+	}
 
 	g.SetManagerFunc(ctrl.layout)
 
@@ -31,7 +73,31 @@ func Run(ctx context.Context, app *app.App, sub chan *events.Event) error {
 		return err
 	}
 
-	go ctrl.Listen(ctx, g, sub)
+	for i := range nodes {
+		go ctrl.Listen(ctx, g, ctrl.nodes[i].models, nodes[i].Events)
+	}
+
+	ctrl.runPlugins(ctx, g)
+	ctrl.runPrice(ctx, g)
+	ctrl.runPeerData(ctx, g)
+	ctrl.runBalances(ctx, g)
+	ctrl.runRoutingChart(ctx, g)
+	ctrl.runNotifications(ctx, g)
+	ctrl.runChannelInfo(ctx, g)
+	ctrl.runFeeRates(ctx, g)
+	defer ctrl.models.Plugins.Close()
+
+	if app.Config.Control.Socket != "" {
+		srv := control.New(app.Config.Control.Socket, app.Logger)
+		ctrl.registerControl(ctx, g, srv)
+		go func() {
+			err := srv.ListenAndServe(ctx)
+			if err != nil {
+				app.Logger.Error("control server failed", logging.Error(err))
+			}
+		}()
+		defer srv.Close()
+	}
 
 	err = g.MainLoop()
 
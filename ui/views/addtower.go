@@ -0,0 +1,125 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	ADD_TOWER       = "add_tower"
+	ADD_TOWER_INPUT = "add_tower_input"
+)
+
+// AddTower is the popup registering the tower of the pubkey@host:port
+// uri typed in its input with the watchtower client, the tower is
+// reached in the background.
+type AddTower struct {
+	input   *Input
+	visible bool
+	adding  bool
+	err     error
+}
+
+func (a *AddTower) Visible() bool {
+	return a.visible
+}
+
+func (a *AddTower) Show() {
+	a.input.SetValue("")
+	a.adding = false
+	a.err = nil
+	a.visible = true
+}
+
+func (a *AddTower) Hide() {
+	a.visible = false
+	a.adding = false
+	a.err = nil
+}
+
+// Value returns the pubkey and the host of the uri of the input.
+func (a *AddTower) Value() (string, string, error) {
+	return parseNodeURI(a.input.Value())
+}
+
+// Pasting returns true while an uri is pasted in the input.
+func (a *AddTower) Pasting() bool {
+	return a.input.Pasting()
+}
+
+// Adding returns true until the tower is added or failed.
+func (a *AddTower) Adding() bool {
+	return a.adding
+}
+
+func (a *AddTower) Start() {
+	a.adding = true
+	a.err = nil
+}
+
+// SetError sets the error of the uri or of the tower, the popup stays
+// open.
+func (a *AddTower) SetError(err error) {
+	a.adding = false
+	a.err = err
+}
+
+func (a *AddTower) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 110
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+7 > maxY {
+		y0 = 0
+	}
+
+	err := a.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(ADD_TOWER, x0, y0+3, x0+width, y0+6, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " add tower "
+	a.display(v)
+	return nil
+}
+
+func (a *AddTower) display(v *gocui.View) {
+	v.Clear()
+	switch {
+	case a.err != nil:
+		fmt.Fprintln(v, color.Red()(a.err.Error()))
+	case a.adding:
+		fmt.Fprintln(v, color.Yellow()("adding..."))
+	default:
+		fmt.Fprintln(v, "type the pubkey@host:port of the tower and press enter, esc to close")
+	}
+}
+
+func (a *AddTower) Delete(g *gocui.Gui) error {
+	err := a.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(ADD_TOWER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewAddTower() *AddTower {
+	return &AddTower{input: NewInput(ADD_TOWER_INPUT, " tower uri ", validateNodeURI)}
+}
@@ -0,0 +1,136 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/store"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+// balanceWindow is a window of the balance columns, split in bars.
+type balanceWindow struct {
+	name   string
+	period time.Duration
+	bars   int
+}
+
+var balanceWindows = []balanceWindow{
+	{name: "1D", period: 24 * time.Hour, bars: 24},
+	{name: "7D", period: 7 * 24 * time.Hour, bars: 28},
+}
+
+func findBalanceWindow(column string) balanceWindow {
+	_, name, _ := strings.Cut(column, "_")
+	for _, w := range balanceWindows {
+		if w.name == name {
+			return w
+		}
+	}
+	return balanceWindows[0]
+}
+
+// balanceSparkline returns a bar of the local part of the capacity for
+// each of the n periods from since to now, at the last sample of the
+// period, blank without sample.
+func balanceSparkline(samples []*store.BalanceSample, since, now time.Time, n int) string {
+	bars := make([]rune, n)
+	for i := range bars {
+		bars[i] = ' '
+	}
+	step := now.Sub(since) / time.Duration(n)
+	for _, s := range samples {
+		if s.Time.Before(since) || s.Capacity <= 0 {
+			continue
+		}
+		i := min(int(s.Time.Sub(since)/step), n-1)
+		ratio := min(max(float64(s.Local)/float64(s.Capacity), 0), 1)
+		bars[i] = sparks[int(ratio*float64(len(sparks)-1)+0.5)]
+	}
+	return string(bars)
+}
+
+// balanceDrift returns the change of the local balance from the first
+// sample since the time to the last one, false without sample.
+func balanceDrift(samples []*store.BalanceSample, since time.Time) (int64, bool) {
+	for _, s := range samples {
+		if !s.Time.Before(since) {
+			return samples[len(samples)-1].Local - s.Local, true
+		}
+	}
+	return 0, false
+}
+
+// formatDrift returns the signed drift padded to the width, in green if
+// the local balance filled and in red if it drained.
+func formatDrift(drift int64, width int, opts ...color.Option) string {
+	switch {
+	case drift > 0:
+		return color.Green(opts...)(fmt.Sprintf("%*s", width, "+"+formatAmount(drift)))
+	case drift < 0:
+		return color.Red(opts...)(fmt.Sprintf("%*s", width, "-"+formatAmount(-drift)))
+	}
+	return color.White(opts...)(fmt.Sprintf("%*s", width, "0"))
+}
+
+// balanceColumn returns the column of the samples of the balances of the
+// BALANCE_ and DRIFT_ columns, sorted by the drift of their window.
+func balanceColumn(chans *models.Channels, column string) channelsColumn {
+	w := findBalanceWindow(column)
+	drift := func(c *netmodels.Channel) int64 {
+		d, _ := balanceDrift(chans.Balances(c.ChannelPoint), time.Now().Add(-w.period))
+		return d
+	}
+	sort := func(order models.Order) models.ChannelsSort {
+		return func(c1, c2 *netmodels.Channel) bool {
+			return models.Int64Sort(drift(c1), drift(c2), order)
+		}
+	}
+
+	if strings.HasPrefix(column, "DRIFT_") {
+		return channelsColumn{
+			width: 13,
+			name:  fmt.Sprintf("%13s", column),
+			sort:  sort,
+			display: func(c *netmodels.Channel, opts ...color.Option) string {
+				d, ok := balanceDrift(chans.Balances(c.ChannelPoint), time.Now().Add(-w.period))
+				if !ok {
+					return fmt.Sprintf("%13s", "")
+				}
+				return formatDrift(d, 13, opts...)
+			},
+		}
+	}
+	return channelsColumn{
+		width: w.bars,
+		name:  fmt.Sprintf("%-*s", w.bars, column),
+		sort:  sort,
+		display: func(c *netmodels.Channel, opts ...color.Option) string {
+			now := time.Now()
+			return color.Cyan(opts...)(balanceSparkline(chans.Balances(c.ChannelPoint),
+				now.Add(-w.period), now, w.bars))
+		},
+	}
+}
+
+// printBalances displays the local part of the capacity and the drift of
+// the local balance over the windows of the balance columns.
+func printBalances(v *gocui.View, samples []*store.BalanceSample) {
+	green := color.Green()
+	cyan := color.Cyan()
+	now := time.Now()
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Balance ]"))
+	for _, w := range balanceWindows {
+		since := now.Add(-w.period)
+		d, _ := balanceDrift(samples, since)
+		fmt.Fprintf(v, "%s %s drift %s sat\n",
+			cyan(fmt.Sprintf("%21s", strings.ToLower(w.name)+":")),
+			cyan(balanceSparkline(samples, since, now, w.bars)), formatDrift(d, 0))
+	}
+}
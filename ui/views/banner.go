@@ -0,0 +1,60 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	BANNER = "banner"
+)
+
+// Banner displays the critical alerts, like force-closes, until they are
+// acknowledged.
+type Banner struct {
+	alerts *models.Alerts
+}
+
+// Visible returns true if there are alerts to acknowledge.
+func (b *Banner) Visible() bool {
+	return len(b.alerts.Pinned()) > 0
+}
+
+func (b *Banner) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(BANNER, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = false
+
+	pinned := b.alerts.Pinned()
+	message := pinned[len(pinned)-1].Message
+	if len(pinned) > 1 {
+		message = fmt.Sprintf("%s (+%d)", message, len(pinned)-1)
+	}
+
+	v.Clear()
+	red := color.Red(color.Background, color.Bold)
+	fmt.Fprintf(v, "%s %s\n",
+		red(fmt.Sprintf(" %s ", message)),
+		color.Cyan()("press A to acknowledge"),
+	)
+	return nil
+}
+
+func (b *Banner) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(BANNER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewBanner(alerts *models.Alerts) *Banner {
+	return &Banner{alerts: alerts}
+}
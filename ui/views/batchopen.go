@@ -0,0 +1,295 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	BATCH_OPEN          = "batch_open"
+	BATCH_OPEN_PUBKEY   = "batch_open_pubkey"
+	BATCH_OPEN_AMOUNT   = "batch_open_amount"
+	BATCH_OPEN_PRIVATE  = "batch_open_private"
+	BATCH_OPEN_FEE_RATE = "batch_open_fee_rate"
+
+	// batchOpenLines is the number of channels displayed, the last ones.
+	batchOpenLines = 6
+)
+
+// batchChannel is a channel of the batch with the alias of the peer.
+type batchChannel struct {
+	*netmodels.BatchChannel
+	alias string
+}
+
+// BatchOpen is the dialog opening channels with several peers in a single
+// funding transaction. An enter with a pubkey adds the channel of the
+// fields to the batch, an enter without shows the fee of the transaction
+// and the next one opens the channels.
+type BatchOpen struct {
+	form
+	visible  bool
+	channels []*batchChannel
+	// pending are the pubkeys of the selected peers to fill in after the
+	// channel added.
+	pending []string
+	// estimated is true once the fee is estimated at the rate for the
+	// channels of the batch.
+	estimated bool
+	rate      uint64
+	estimate  *netmodels.FeeEstimate
+	errFee    error
+	// busy is the action in progress, the estimate of the fee or the
+	// opening of the channels, empty if none.
+	busy string
+	err  error
+}
+
+func (b *BatchOpen) Visible() bool {
+	return b.visible
+}
+
+// Show opens the dialog with the pubkeys of the selected peers, filled in
+// one after the other.
+func (b *BatchOpen) Show(pubkeys []string) {
+	b.channels = nil
+	b.pending = pubkeys
+	b.reset("", "", "", "")
+	b.fill()
+	b.resetEstimate()
+	b.busy = ""
+	b.err = nil
+	b.visible = true
+}
+
+func (b *BatchOpen) Hide() {
+	b.visible = false
+	b.channels = nil
+	b.pending = nil
+	b.resetEstimate()
+	b.busy = ""
+}
+
+// Start displays the action in progress until its result is set.
+func (b *BatchOpen) Start(action string) {
+	b.busy = action
+	b.err = nil
+}
+
+// Busy returns true while the fee is estimated or the channels opened.
+func (b *BatchOpen) Busy() bool {
+	return b.busy != ""
+}
+
+// fill clears the fields of the channel with the next pending pubkey,
+// the amount is focused if there is one.
+func (b *BatchOpen) fill() {
+	pubkey := ""
+	if len(b.pending) > 0 {
+		pubkey = b.pending[0]
+		b.pending = b.pending[1:]
+	}
+	b.inputs[0].SetValue(pubkey)
+	b.inputs[1].SetValue("")
+	b.inputs[2].SetValue("no")
+	b.focus = 0
+	if pubkey != "" {
+		b.focus = 1
+	}
+}
+
+func (b *BatchOpen) resetEstimate() {
+	b.estimated = false
+	b.estimate = nil
+	b.errFee = nil
+}
+
+// Value returns the channel of the fields, nil if the pubkey is empty.
+func (b *BatchOpen) Value() (*netmodels.BatchChannel, error) {
+	pubkey := b.inputs[0].Value()
+	if pubkey == "" {
+		return nil, nil
+	}
+	err := validatePubKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(b.inputs[1].Value())
+	if err != nil {
+		return nil, err
+	}
+	private, err := parseYesNo(b.inputs[2].Value())
+	if err != nil {
+		return nil, errors.Errorf("private: %s", err)
+	}
+	return &netmodels.BatchChannel{PubKey: pubkey, Amount: amount, Private: private}, nil
+}
+
+// FeeRate returns the fee rate of the field, zero if it is empty.
+func (b *BatchOpen) FeeRate() (uint64, error) {
+	s := b.inputs[3].Value()
+	if s == "" {
+		return 0, nil
+	}
+	return parseFeeRate(s)
+}
+
+// Add adds the channel to the batch, a peer has a single channel in it.
+// The fields of the channel are cleared, the pubkey is the next selected
+// peer.
+func (b *BatchOpen) Add(channel *netmodels.BatchChannel, alias string) error {
+	for _, ch := range b.channels {
+		if ch.PubKey == channel.PubKey {
+			return errors.New("the peer is already in the batch")
+		}
+	}
+	b.channels = append(b.channels, &batchChannel{BatchChannel: channel, alias: alias})
+	b.fill()
+	b.resetEstimate()
+	b.err = nil
+	return nil
+}
+
+// RemoveLast removes the last channel added to the batch.
+func (b *BatchOpen) RemoveLast() {
+	if len(b.channels) == 0 {
+		return
+	}
+	b.channels = b.channels[:len(b.channels)-1]
+	b.resetEstimate()
+	b.err = nil
+}
+
+// Channels returns the channels of the batch, in their order.
+func (b *BatchOpen) Channels() []*netmodels.BatchChannel {
+	channels := make([]*netmodels.BatchChannel, len(b.channels))
+	for i := range b.channels {
+		channels[i] = b.channels[i].BatchChannel
+	}
+	return channels
+}
+
+// Confirmed returns true if the fee of the batch was shown at the rate by
+// the previous enter.
+func (b *BatchOpen) Confirmed(rate uint64) bool {
+	return b.estimated && b.rate == rate
+}
+
+// SetEstimate shows the fee of the batch at the rate to confirm it, the
+// batch can be opened even if the fee cannot be estimated.
+func (b *BatchOpen) SetEstimate(rate uint64, estimate *netmodels.FeeEstimate, err error) {
+	b.estimated = true
+	b.rate = rate
+	b.estimate = estimate
+	b.errFee = err
+	b.busy = ""
+	b.err = nil
+}
+
+// SetError sets the error of the fields or of the opening, the dialog
+// stays open.
+func (b *BatchOpen) SetError(err error) {
+	b.resetEstimate()
+	b.busy = ""
+	b.err = err
+}
+
+func (b *BatchOpen) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 4
+	if y0+3*len(b.inputs)+batchOpenLines+5 > maxY {
+		y0 = 0
+	}
+
+	y, err := b.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(BATCH_OPEN, x0, y, x0+width, y+batchOpenLines+4, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = fmt.Sprintf(" batch open: %d channels ", len(b.channels))
+	b.display(v)
+	return nil
+}
+
+func (b *BatchOpen) display(v *gocui.View) {
+	v.Clear()
+	channels := b.channels
+	if len(channels) > batchOpenLines {
+		fmt.Fprintf(v, "%d more channels\n", len(channels)-batchOpenLines+1)
+		channels = channels[len(channels)-batchOpenLines+1:]
+	}
+	for _, ch := range channels {
+		peer := ch.PubKey
+		if ch.alias != "" {
+			peer = ch.alias
+		}
+		kind := "public"
+		if ch.Private {
+			kind = "private"
+		}
+		fmt.Fprintf(v, "%s %s %s\n", color.Yellow()(fmt.Sprintf("%15s", formatAmount(ch.Amount)+" sat")),
+			color.Cyan()(peer), kind)
+	}
+
+	if b.err != nil {
+		fmt.Fprintln(v, color.Red()(b.err.Error()))
+		return
+	}
+	if b.busy != "" {
+		fmt.Fprintln(v, color.Yellow()(b.busy+"..."))
+		return
+	}
+	if !b.estimated {
+		fmt.Fprintln(v, "enter adds the channel of the fields, with an empty pubkey it opens the batch")
+		fmt.Fprintln(v, "tab moves to the next field, ctrl-x removes the last channel, esc to close")
+		return
+	}
+
+	total := color.Yellow(color.Bold)(formatAmount(netmodels.BatchAmount(b.Channels())) + " sat")
+	switch {
+	case b.errFee != nil:
+		fmt.Fprintf(v, "fund %s, the fee cannot be estimated: %s\n", total, color.Red()(b.errFee.Error()))
+	case b.estimate != nil:
+		fmt.Fprintf(v, "fund %s for an on-chain fee of %s at %d sat/vbyte?\n", total,
+			color.Yellow(color.Bold)(formatAmount(b.estimate.Fee)+" sat"), b.estimate.SatPerVbyte)
+	}
+	fmt.Fprintf(v, "press enter again to open the %d channels, esc to close\n", len(b.channels))
+}
+
+func (b *BatchOpen) Delete(g *gocui.Gui) error {
+	err := b.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(BATCH_OPEN)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewBatchOpen() *BatchOpen {
+	return &BatchOpen{form: form{inputs: []*Input{
+		NewInput(BATCH_OPEN_PUBKEY, " node pubkey ", validatePubKey),
+		NewInput(BATCH_OPEN_AMOUNT, " local amount (sat) ", validateAmount),
+		NewInput(BATCH_OPEN_PRIVATE, " private (yes/no) ", validateYesNo),
+		NewInput(BATCH_OPEN_FEE_RATE, " fee rate (sat/vbyte) ", validateFeeRate),
+	}}}
+}
@@ -0,0 +1,149 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	BUMPFEE       = "bumpfee"
+	BUMPFEE_INPUT = "bumpfee_input"
+)
+
+// BumpFee is the popup requesting a new fee rate for the pending sweep
+// selected in the sweeps view.
+type BumpFee struct {
+	input   *Input
+	visible bool
+	sweep   *netmodels.PendingSweep
+	err     error
+}
+
+func (b *BumpFee) Visible() bool {
+	return b.visible
+}
+
+func (b *BumpFee) Show(sweep *netmodels.PendingSweep) {
+	b.sweep = sweep
+	b.err = nil
+	b.visible = true
+}
+
+func (b *BumpFee) Hide() {
+	b.visible = false
+	b.sweep = nil
+	b.err = nil
+}
+
+// Sweep returns the pending sweep to bump.
+func (b *BumpFee) Sweep() *netmodels.PendingSweep {
+	return b.sweep
+}
+
+// Value returns the fee rate of the input in sat/vbyte.
+func (b *BumpFee) Value() (uint64, error) {
+	return parseFeeRate(b.input.Value())
+}
+
+// Pasting returns true while a fee rate is pasted in the input.
+func (b *BumpFee) Pasting() bool {
+	return b.input.Pasting()
+}
+
+// SetError sets the error returned by the fee bump, the popup stays
+// open to try another fee rate.
+func (b *BumpFee) SetError(err error) {
+	b.err = err
+}
+
+func (b *BumpFee) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+8 > maxY {
+		y0 = 0
+	}
+
+	err := b.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(BUMPFEE, x0, y0+3, x0+width, y0+9, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " bump fee "
+	b.display(v)
+	return nil
+}
+
+func (b *BumpFee) display(v *gocui.View) {
+	v.Clear()
+	if b.sweep == nil {
+		fmt.Fprintln(v, "no sweep selected, esc to close")
+		return
+	}
+	cyan := color.Cyan()
+	fmt.Fprintf(v, "%s %s\n", cyan("outpoint"), b.sweep.Outpoint)
+	fmt.Fprintf(v, "%s %d sat/vbyte, %d attempts\n", cyan("fee rate"),
+		b.sweep.SatPerVbyte, b.sweep.BroadcastAttempts)
+	if b.err != nil {
+		fmt.Fprintln(v, color.Red()(b.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "type the new fee rate and press enter, esc to close")
+}
+
+func (b *BumpFee) Delete(g *gocui.Gui) error {
+	err := b.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(BUMPFEE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func parseFeeRate(s string) (uint64, error) {
+	rate, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errors.New("not a fee rate in sat/vbyte")
+	}
+	if rate == 0 {
+		return 0, errors.New("fee rate must be above 0")
+	}
+	return rate, nil
+}
+
+// formatFeeRate returns the rate of a field, empty if it is zero.
+func formatFeeRate(rate uint64) string {
+	if rate == 0 {
+		return ""
+	}
+	return strconv.FormatUint(rate, 10)
+}
+
+func validateFeeRate(s string) error {
+	_, err := parseFeeRate(s)
+	return err
+}
+
+func NewBumpFee() *BumpFee {
+	return &BumpFee{input: NewInput(BUMPFEE_INPUT, " fee rate (sat/vbyte) ", validateFeeRate)}
+}
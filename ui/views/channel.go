@@ -1,26 +1,55 @@
 package views
 
 import (
+	"cmp"
+	"encoding/hex"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/awesome-gocui/gocui"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 
 	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
 	"github.com/edouardparis/lntop/ui/color"
 	"github.com/edouardparis/lntop/ui/models"
 )
 
 const (
-	CHANNEL        = "channel"
-	CHANNEL_HEADER = "channel_header"
-	CHANNEL_FOOTER = "channel_footer"
+	CHANNEL = "channel"
 )
 
+// maxPolicyHistory is the number of policy changes displayed, the most
+// recent ones.
+const maxPolicyHistory = 20
+
+// htlcExpiryClose is the number of blocks before its expiry an HTLC in
+// flight is highlighted, lnd goes on chain 10 blocks before the expiry of
+// an incoming one.
+const htlcExpiryClose = 40
+
+// Channel is the popup of the detail of the current channel, above the
+// channels view.
 type Channel struct {
 	view     *gocui.View
 	channels *models.Channels
+	sweeps   *models.Sweeps
+	info     *models.Info
+	visible  bool
+}
+
+func (c *Channel) Visible() bool {
+	return c.visible
+}
+
+// Show displays the current channel from the next layout.
+func (c *Channel) Show() {
+	c.visible = true
+}
+
+func (c *Channel) Hide() {
+	c.visible = false
 }
 
 func (c Channel) Name() string {
@@ -63,85 +92,181 @@ func (c *Channel) SetOrigin(x, y int) error {
 }
 
 func (c *Channel) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
-	header, err := g.SetView(CHANNEL_HEADER, x0-1, y0, x1+2, y0+2, 0)
-	if err != nil {
-		if err != gocui.ErrUnknownView {
-			return err
-		}
-	}
-	header.Frame = false
-	header.BgColor = gocui.ColorGreen
-	header.FgColor = gocui.ColorBlack | gocui.AttrBold
-	header.Rewind()
-	fmt.Fprintln(header, "Channel")
-
-	v, err := g.SetView(CHANNEL, x0-1, y0+1, x1+2, y1-1, 0)
+	v, err := g.SetView(CHANNEL, x0, y0, x1, y1, 0)
 	if err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 	}
-	v.Frame = false
+	v.Frame = true
+	v.Title = " Channel - esc to close, c to count the disabled channels of the node, t to tag "
 	c.view = v
 	c.display()
 
-	footer, err := g.SetView(CHANNEL_FOOTER, x0-1, y1-2, x1, y1, 0)
-	if err != nil {
-		if err != gocui.ErrUnknownView {
-			return err
-		}
-	}
-	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
-	footer.Rewind()
-	blackBg := color.Black(color.Background)
-	fmt.Fprintf(footer, "%s%s %s%s %s%s %s%s\n",
-		blackBg("F2"), "Menu",
-		blackBg("Enter"), "Channels",
-		blackBg("C"), "Get disabled",
-		blackBg("F10"), "Quit",
-	)
-	return nil
+	_, err = g.SetCurrentView(CHANNEL)
+	return err
 }
 
 func (c Channel) Delete(g *gocui.Gui) error {
-	err := g.DeleteView(CHANNEL_HEADER)
-	if err != nil {
+	err := g.DeleteView(CHANNEL)
+	if err != nil && err != gocui.ErrUnknownView {
 		return err
 	}
+	return nil
+}
 
-	err = g.DeleteView(CHANNEL)
-	if err != nil {
-		return err
+// policyRows are the fields of the policies compared side by side.
+var policyRows = []struct {
+	name  string
+	value func(*netmodels.RoutingPolicy) string
+}{
+	{"     Time lock delta:", func(p *netmodels.RoutingPolicy) string { return fmt.Sprintf("%d", p.TimeLockDelta) }},
+	{"     Min htlc (msat):", func(p *netmodels.RoutingPolicy) string { return formatAmount(p.MinHtlc) }},
+	{"      Max htlc (sat):", func(p *netmodels.RoutingPolicy) string { return formatAmount(int64(p.MaxHtlc / 1000)) }},
+	{"       Fee base msat:", func(p *netmodels.RoutingPolicy) string { return formatAmount(p.FeeBaseMsat) }},
+	{" Fee rate milli msat:", func(p *netmodels.RoutingPolicy) string { return fmt.Sprintf("%d", p.FeeRateMilliMsat) }},
+	{"              Status:", func(p *netmodels.RoutingPolicy) string {
+		if p.Disabled {
+			return "disabled"
+		}
+		return "enabled"
+	}},
+}
+
+// recentPolicyChange is the age under which the last change of the policy
+// of the peer is highlighted.
+const recentPolicyChange = 24 * time.Hour
+
+// printPolicies displays the outgoing policy of the node and the incoming
+// one of the peer side by side. The fields of the last change of the peer
+// in the history are highlighted if it is younger than recentPolicyChange.
+func printPolicies(v *gocui.View, local, remote *netmodels.RoutingPolicy, history []*models.PolicyPeriod) {
+	green := color.Green()
+	cyan := color.Cyan()
+	var change *models.PolicyPeriod
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].Local {
+			if time.Since(history[i].Time) < recentPolicyChange {
+				change = history[i]
+			}
+			break
+		}
+	}
+
+	cell := func(p *netmodels.RoutingPolicy, value func(*netmodels.RoutingPolicy) string, changed bool) string {
+		if p == nil {
+			return fmt.Sprintf("%18s", "-")
+		}
+		s := fmt.Sprintf("%18s", value(p))
+		switch {
+		case changed:
+			return color.Yellow(color.Bold)(s)
+		case p.Disabled && value(p) == "disabled":
+			return color.Red()(s)
+		}
+		return s
 	}
 
-	return g.DeleteView(CHANNEL_FOOTER)
+	fmt.Fprintln(v)
+	fmt.Fprintf(v, "%s %18s %18s\n", green(" [ Routing Policies ]"), "outgoing (ours)", "incoming (theirs)")
+	for _, row := range policyRows {
+		changed := change != nil && change.Old != nil && change.New != nil &&
+			row.value(change.Old) != row.value(change.New)
+		fmt.Fprintf(v, "%s %s %s\n", cyan(row.name),
+			cell(local, row.value, false), cell(remote, row.value, changed))
+	}
+	lastUpdate := func(p *netmodels.RoutingPolicy) string {
+		if p.LastUpdate.IsZero() {
+			return "-"
+		}
+		return p.LastUpdate.Format("15:04:05 Jan _2")
+	}
+	fmt.Fprintf(v, "%s %s %s\n", cyan("         Last update:"),
+		cell(local, lastUpdate, false), cell(remote, lastUpdate, change != nil))
+	if change != nil {
+		fmt.Fprintf(v, " %s %s ago: %s\n", color.Yellow(color.Bold)("peer changed its policy"),
+			formatPeriod(time.Since(change.Time)), policyDiff(change.Old, change.New))
+	}
 }
 
-func printPolicy(v *gocui.View, p *message.Printer, policy *netmodels.RoutingPolicy, outgoing bool) {
+// printPolicyHistory displays the policy changes of the channel, most
+// recent first, with the forwards of the forwarding history through the
+// channel until the next change.
+func printPolicyHistory(v *gocui.View, history []*models.PolicyPeriod) {
 	green := color.Green()
 	cyan := color.Cyan()
-	red := color.Red()
-	fmt.Fprintln(v, "")
-	direction := "Outgoing"
-	if !outgoing {
-		direction = "Incoming"
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Policy History ]"))
+	for i := len(history) - 1; i >= 0 && i >= len(history)-maxPolicyHistory; i-- {
+		period := history[i]
+		by := "peer "
+		if period.Local {
+			by = "local"
+		}
+		fmt.Fprintf(v, " %s %s %s\n",
+			cyan(period.Time.Format("15:04:05 Jan _2")), by, policyDiff(period.Old, period.New))
+		fmt.Fprintf(v, "%s in %d (%s sat), out %d (%s sat), fee %s msat\n",
+			cyan("                 forwards:"),
+			period.ForwardsIn, formatAmount(int64(period.AmountIn)),
+			period.ForwardsOut, formatAmount(int64(period.AmountOut)),
+			formatAmount(int64(period.FeeMsat)))
 	}
-	fmt.Fprintf(v, green(" [ %s Policy ]\n"), direction)
-	if policy.Disabled {
-		fmt.Fprintln(v, red("disabled"))
+}
+
+// printStats displays the forwards through the channel and the fees it
+// earned over the windows of the stats.
+func printStats(v *gocui.View, s stats.Channel) {
+	green := color.Green()
+	cyan := color.Cyan()
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Forwards ]"))
+	for i := range s {
+		fmt.Fprintf(v, "%s in %d (%s sat), out %d (%s sat), fee %s msat\n",
+			cyan(fmt.Sprintf("%21s", stats.WindowNames[i]+":")),
+			s[i].ForwardsIn, formatAmount(int64(s[i].AmountInMsat/1000)),
+			s[i].ForwardsOut, formatAmount(int64(s[i].AmountOutMsat/1000)),
+			formatAmount(int64(s[i].FeeMsat)))
 	}
-	fmt.Fprintf(v, "%s %d\n",
-		cyan("     Time lock delta:"), policy.TimeLockDelta)
-	fmt.Fprintf(v, "%s %s\n",
-		cyan("     Min htlc (msat):"), formatAmount(policy.MinHtlc))
-	fmt.Fprintf(v, "%s %s\n",
-		cyan("      Max htlc (sat):"), formatAmount(int64(policy.MaxHtlc/1000)))
-	fmt.Fprintf(v, "%s %s\n",
-		cyan("       Fee base msat:"), formatAmount(policy.FeeBaseMsat))
-	fmt.Fprintf(v, "%s %d\n",
-		cyan(" Fee rate milli msat:"), policy.FeeRateMilliMsat)
+}
+
+// policyDiff describes the fields changed between the two policies.
+func policyDiff(old, new *netmodels.RoutingPolicy) string {
+	if new == nil {
+		return "policy removed"
+	}
+	if old == nil {
+		return fmt.Sprintf("fee rate %d ppm, base fee %s msat",
+			new.FeeRateMilliMsat, formatAmount(new.FeeBaseMsat))
+	}
+	changes := []string{}
+	if old.FeeRateMilliMsat != new.FeeRateMilliMsat {
+		changes = append(changes, fmt.Sprintf("fee rate %d -> %d ppm",
+			old.FeeRateMilliMsat, new.FeeRateMilliMsat))
+	}
+	if old.FeeBaseMsat != new.FeeBaseMsat {
+		changes = append(changes, fmt.Sprintf("base fee %s -> %s msat",
+			formatAmount(old.FeeBaseMsat), formatAmount(new.FeeBaseMsat)))
+	}
+	if old.TimeLockDelta != new.TimeLockDelta {
+		changes = append(changes, fmt.Sprintf("time lock delta %d -> %d",
+			old.TimeLockDelta, new.TimeLockDelta))
+	}
+	if old.MinHtlc != new.MinHtlc {
+		changes = append(changes, fmt.Sprintf("min htlc %s -> %s msat",
+			formatAmount(old.MinHtlc), formatAmount(new.MinHtlc)))
+	}
+	if old.MaxHtlc != new.MaxHtlc {
+		changes = append(changes, fmt.Sprintf("max htlc %s -> %s sat",
+			formatAmount(int64(old.MaxHtlc/1000)), formatAmount(int64(new.MaxHtlc/1000))))
+	}
+	if old.Disabled != new.Disabled {
+		if new.Disabled {
+			changes = append(changes, color.Red()("disabled"))
+		} else {
+			changes = append(changes, "enabled")
+		}
+	}
+	return strings.Join(changes, ", ")
 }
 
 func formatAmount(amt int64) string {
@@ -178,7 +303,6 @@ func formatDisabledCount(cnt int, total uint32) string {
 }
 
 func (c *Channel) display() {
-	p := message.NewPrinter(language.English)
 	v := c.view
 	v.Clear()
 	channel := c.channels.Current()
@@ -187,12 +311,18 @@ func (c *Channel) display() {
 	fmt.Fprintln(v, green(" [ Channel ]"))
 	fmt.Fprintf(v, "%s %s\n",
 		cyan("             Status:"), status(channel))
+	visibility := color.Green()("public")
+	if channel.Private {
+		visibility = color.Red()("private")
+	}
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("         Visibility:"), visibility)
 	if channel.Status == netmodels.ChannelForceClosing {
 		fmt.Fprintf(v, "%s %d blocks\n",
 			cyan("         Matured in:"), channel.BlocksTilMaturity)
 	}
 	fmt.Fprintf(v, "%s %d (%s)\n",
-		cyan("                 ID:"), channel.ID, ToScid(channel.ID))
+		cyan("                 ID:"), channel.ID, netmodels.ToScid(channel.ID))
 	fmt.Fprintf(v, "%s %s\n",
 		cyan("           Capacity:"), formatAmount(channel.Capacity))
 	fmt.Fprintf(v, "%s %s\n",
@@ -201,6 +331,18 @@ func (c *Channel) display() {
 		cyan("     Remote Balance:"), formatAmount(channel.RemoteBalance))
 	fmt.Fprintf(v, "%s %s\n",
 		cyan("      Channel Point:"), channel.ChannelPoint)
+	if url := c.info.TxURL(strings.Split(channel.ChannelPoint, ":")[0]); url != "" {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("           Explorer:"), url)
+	}
+	if len(channel.Tags) > 0 {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("               Tags:"), color.Yellow()(strings.Join(channel.Tags, ", ")))
+	}
+	if channel.Note != "" {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("               Note:"), channel.Note)
+	}
 	fmt.Fprintln(v, "")
 
 	fmt.Fprintln(v, green(" [ Node ]"))
@@ -213,12 +355,18 @@ func (c *Channel) display() {
 		}
 		fmt.Fprintf(v, "%s %s\n",
 			cyan("          Alias:"), alias)
+		node := channel.Node
+		current := c.channels.CurrentNode != nil && c.channels.CurrentNode.PubKey == channel.RemotePubKey
+		// the node of the channel is only its alias until it is fetched.
+		if node.Cached && current {
+			node = c.channels.CurrentNode
+		}
 		fmt.Fprintf(v, "%s %s\n",
-			cyan(" Total Capacity:"), formatAmount(channel.Node.TotalCapacity))
+			cyan(" Total Capacity:"), formatAmount(node.TotalCapacity))
 		fmt.Fprintf(v, "%s %d\n",
-			cyan(" Total Channels:"), channel.Node.NumChannels)
+			cyan(" Total Channels:"), node.NumChannels)
 
-		if c.channels.CurrentNode != nil && c.channels.CurrentNode.PubKey == channel.RemotePubKey {
+		if current {
 			disabledOut := 0
 			disabledIn := 0
 			for _, ch := range c.channels.CurrentNode.Channels {
@@ -229,35 +377,150 @@ func (c *Channel) display() {
 					disabledIn++
 				}
 			}
-			fmt.Fprintf(v, "\n %s %s\n", cyan("Disabled from node:"), formatDisabledCount(disabledOut, channel.Node.NumChannels))
-			fmt.Fprintf(v, " %s %s\n", cyan("Disabled to node:  "), formatDisabledCount(disabledIn, channel.Node.NumChannels))
+			fmt.Fprintf(v, "\n %s %s\n", cyan("Disabled from node:"), formatDisabledCount(disabledOut, node.NumChannels))
+			fmt.Fprintf(v, " %s %s\n", cyan("Disabled to node:  "), formatDisabledCount(disabledIn, node.NumChannels))
 		}
 	}
 
-	if channel.LocalPolicy != nil {
-		printPolicy(v, p, channel.LocalPolicy, true)
+	if channel.LocalPolicy != nil || channel.RemotePolicy != nil {
+		printPolicies(v, channel.LocalPolicy, channel.RemotePolicy, c.channels.CurrentHistory)
 	}
 
-	if channel.RemotePolicy != nil {
-		printPolicy(v, p, channel.RemotePolicy, false)
+	printStats(v, c.channels.Stats(channel.ID))
+
+	if samples := c.channels.Balances(channel.ChannelPoint); len(samples) > 0 {
+		printBalances(v, samples)
+	}
+
+	if len(c.channels.CurrentHistory) > 0 {
+		printPolicyHistory(v, c.channels.CurrentHistory)
 	}
 
 	if len(channel.PendingHTLC) > 0 {
-		fmt.Fprintln(v)
-		fmt.Fprintln(v, green(" [ Pending HTLCs ]"))
-		for _, htlc := range channel.PendingHTLC {
-			fmt.Fprintf(v, "%s %t\n",
-				cyan("   Incoming:"), htlc.Incoming)
+		c.printHTLCs(v, channel.PendingHTLC)
+	}
+
+	if channel.Closing != nil {
+		c.printClosing(v, channel.Closing)
+	}
+}
+
+// printHTLCs displays the HTLCs in flight in the channel, the first to
+// expire first. The ones expiring within htlcExpiryClose blocks are in
+// red.
+func (c *Channel) printHTLCs(v *gocui.View, htlcs []*netmodels.HTLC) {
+	htlcs = slices.Clone(htlcs)
+	slices.SortStableFunc(htlcs, func(h1, h2 *netmodels.HTLC) int {
+		return cmp.Compare(h1.ExpirationHeight, h2.ExpirationHeight)
+	})
+	var total int64
+	for _, htlc := range htlcs {
+		total += htlc.Amount
+	}
+	fmt.Fprintln(v)
+	fmt.Fprintf(v, "%s %d HTLCs, %s sat\n", color.Green()(" [ In-flight HTLCs ]"), len(htlcs), formatAmount(total))
+	for _, htlc := range htlcs {
+		direction := "out"
+		if htlc.Incoming {
+			direction = "in "
+		}
+		hash := hex.EncodeToString(htlc.Hashlock)
+		if len(hash) > 16 {
+			hash = hash[:16]
+		}
+		line := fmt.Sprintf("   %s %15s sat  expiry %s  hash %s", direction,
+			formatAmount(htlc.Amount), c.blocksFrom(htlc.ExpirationHeight), hash)
+		if c.info != nil && c.info.Info != nil && htlc.ExpirationHeight <= c.info.BlockHeight+htlcExpiryClose {
+			line = color.Red()(line)
+		}
+		fmt.Fprintln(v, line)
+	}
+}
+
+// printClosing displays the outputs of the closing transaction not yet
+// returned to the wallet and the sweeps trying to claim them.
+func (c *Channel) printClosing(v *gocui.View, closing *netmodels.Closing) {
+	green := color.Green()
+	cyan := color.Cyan()
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Closing ]"))
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("      Closing Tx:"), closing.ClosingTxID)
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("   Limbo Balance:"), formatAmount(closing.LimboBalance))
+	if closing.RecoveredBalance > 0 {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("       Recovered:"), formatAmount(closing.RecoveredBalance))
+	}
+	if closing.MaturityHeight > 0 {
+		fmt.Fprintf(v, "%s %d (%d blocks)\n",
+			cyan(" Maturity Height:"), closing.MaturityHeight, closing.BlocksTilMaturity)
+	}
+	if closing.Anchor != 0 {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("          Anchor:"), anchorState(closing.Anchor))
+	}
+	for _, htlc := range closing.PendingHTLCs {
+		direction := "outgoing"
+		if htlc.Incoming {
+			direction = "incoming"
+		}
+		fmt.Fprintf(v, "%s %s %s stage %d, matures at %d (%d blocks)\n",
+			cyan("            HTLC:"), formatAmount(htlc.Amount), direction,
+			htlc.Stage, htlc.MaturityHeight, htlc.BlocksTilMaturity)
+	}
+
+	if c.sweeps == nil || closing.ClosingTxID == "" {
+		return
+	}
+	sweeps := c.sweeps.ForTx(closing.ClosingTxID)
+	if len(sweeps) == 0 {
+		return
+	}
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Pending Sweeps ]"))
+	for _, sweep := range sweeps {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("        Outpoint:"), sweep.Outpoint)
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("         Witness:"), sweep.WitnessType)
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("          Amount:"), formatAmount(sweep.Amount))
+		fmt.Fprintf(v, "%s %d sat/vbyte\n",
+			cyan("        Fee Rate:"), sweep.SatPerVbyte)
+		fmt.Fprintf(v, "%s %d\n",
+			cyan("        Attempts:"), sweep.BroadcastAttempts)
+		fmt.Fprintf(v, "%s %s\n",
+			cyan("  Next Broadcast:"), c.blocksFrom(sweep.NextBroadcastHeight))
+		if sweep.DeadlineHeight > 0 {
 			fmt.Fprintf(v, "%s %s\n",
-				cyan("     Amount:"), formatAmount(htlc.Amount))
-			fmt.Fprintf(v, "%s %d\n",
-				cyan(" Expiration:"), htlc.ExpirationHeight)
-			fmt.Fprintln(v)
+				cyan("        Deadline:"), c.blocksFrom(sweep.DeadlineHeight))
 		}
+		fmt.Fprintln(v)
 	}
+}
 
+// blocksFrom returns the height with the number of blocks until it.
+func (c *Channel) blocksFrom(height uint32) string {
+	if height == 0 {
+		return "-"
+	}
+	if c.info == nil || c.info.Info == nil || height <= c.info.BlockHeight {
+		return fmt.Sprintf("%d", height)
+	}
+	return fmt.Sprintf("%d (in %d blocks)", height, height-c.info.BlockHeight)
+}
+
+func anchorState(s int) string {
+	switch s {
+	case netmodels.AnchorRecovered:
+		return color.Green()(netmodels.AnchorStateName(s))
+	case netmodels.AnchorLost:
+		return color.Red()(netmodels.AnchorStateName(s))
+	}
+	return color.Yellow()(netmodels.AnchorStateName(s))
 }
 
-func NewChannel(channels *models.Channels) *Channel {
-	return &Channel{channels: channels}
+func NewChannel(channels *models.Channels, sweeps *models.Sweeps, info *models.Info) *Channel {
+	return &Channel{channels: channels, sweeps: sweeps, info: info}
 }
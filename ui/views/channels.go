@@ -3,13 +3,22 @@ package views
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"github.com/pkg/errors"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
 	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/expr"
 	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
 	"github.com/edouardparis/lntop/ui/color"
 	"github.com/edouardparis/lntop/ui/models"
 )
@@ -36,10 +45,61 @@ var DefaultChannelsColumns = []string{
 	"ID",
 }
 
+// AllChannelsColumns are the columns of the view, in the order of the
+// column chooser.
+var AllChannelsColumns = []string{
+	"STATUS",
+	"ALIAS",
+	"GAUGE",
+	"LOCAL",
+	"REMOTE",
+	"CAP",
+	"FIAT_LOCAL",
+	"FIAT_REMOTE",
+	"FIAT_CAP",
+	"SENT",
+	"RECEIVED",
+	"HTLC",
+	"UNSETTLED",
+	"CFEE",
+	"LAST UPDATE",
+	"PRIVATE",
+	"DISABLED",
+	"ID",
+	"SCID",
+	"NUPD",
+	"BASE_OUT",
+	"RATE_OUT",
+	"BASE_IN",
+	"RATE_IN",
+	"AGE",
+	"HEALTH",
+	"FEE_RATIO",
+	"LATENCY",
+	"UPTIME%",
+	"FLAPS",
+	"FEES_1D",
+	"FEES_7D",
+	"FEES_30D",
+	"VOLUME_1D",
+	"VOLUME_7D",
+	"VOLUME_30D",
+	"SCORE",
+	"TAGS",
+	"RANK",
+	"BALANCE_1D",
+	"BALANCE_7D",
+	"DRIFT_1D",
+	"DRIFT_7D",
+}
+
 type Channels struct {
 	cfg *config.View
+	// names are the columns of the config, columns their display.
+	names []string
 
-	columns []channelsColumn
+	columns    []channelsColumn
+	highlights []highlight
 
 	columnHeadersView *gocui.View
 	columnViews       []*gocui.View
@@ -49,6 +109,12 @@ type Channels struct {
 
 	ox, oy int
 	cx, cy int
+	rows   int
+
+	exported   string
+	exportedAt time.Time
+
+	sortKeys []SortKey
 }
 
 type channelsColumn struct {
@@ -82,24 +148,69 @@ func (c Channels) currentColumnIndex() int {
 	return index
 }
 
-func (c Channels) Sort(column string, order models.Order) {
+func (c *Channels) Sort(column string, order models.Order) {
 	if column == "" {
 		index := c.currentColumnIndex()
 		if index >= len(c.columns) {
 			return
 		}
-		col := c.columns[index]
-		if col.sort == nil {
+		if c.columns[index].sort == nil {
 			return
 		}
+		c.SortBy([]SortKey{{Column: c.names[index], Order: order}})
+	}
+}
 
-		c.channels.Sort(col.sort(order))
+// SortBy sorts the channels by the keys of the columns displayed, the
+// others are ignored. The channels keep their order without keys.
+func (c *Channels) SortBy(keys []SortKey) {
+	if len(keys) == 0 {
+		c.channels.ClearSort()
+		c.sortKeys = nil
 		for i := range c.columns {
-			c.columns[i].sorted = (i == index)
+			c.columns[i].sorted = false
+		}
+		return
+	}
+	var sorts []models.ChannelsSort
+	var applied []SortKey
+	sorted := map[int]bool{}
+	for _, key := range keys {
+		i := slices.Index(c.names, key.Column)
+		if i < 0 || c.columns[i].sort == nil || sorted[i] {
+			continue
 		}
+		sorts = append(sorts, c.columns[i].sort(key.Order))
+		applied = append(applied, key)
+		sorted[i] = true
+	}
+	if len(sorts) == 0 {
+		return
+	}
+	c.channels.Sort(models.ChannelsSortBy(sorts...))
+	c.sortKeys = applied
+	for i := range c.columns {
+		c.columns[i].sorted = sorted[i]
 	}
 }
 
+// SortKeys returns the keys of the last sort.
+func (c *Channels) SortKeys() []SortKey {
+	return c.sortKeys
+}
+
+// SortableColumns returns the columns displayed the channels can be
+// sorted by.
+func (c *Channels) SortableColumns() []string {
+	var columns []string
+	for i := range c.columns {
+		if c.columns[i].sort != nil {
+			columns = append(columns, c.names[i])
+		}
+	}
+	return columns
+}
+
 func (c Channels) Origin() (int, int) {
 	return c.ox, c.oy
 }
@@ -159,7 +270,7 @@ func (c *Channels) Speed() (int, int, int, int) {
 	if c.Index() > 0 {
 		up = 1
 	}
-	if c.Index() < c.channels.Len()-1 {
+	if c.Index() < c.channels.FilteredLen()-1 {
 		down = 1
 	}
 	if current > len(c.columns)-1 {
@@ -175,7 +286,7 @@ func (c *Channels) Speed() (int, int, int, int) {
 
 func (c *Channels) Limits() (pageSize int, fullSize int) {
 	_, pageSize = c.view.Size()
-	fullSize = c.channels.Len()
+	fullSize = c.channels.FilteredLen()
 	return
 }
 
@@ -185,6 +296,31 @@ func (c Channels) Index() int {
 	return cy + oy
 }
 
+// Table returns the names of the columns of the view and the cells of the
+// channels displayed, in their sort order, without colors and padding.
+func (c *Channels) Table() ([]string, [][]string) {
+	header := make([]string, len(c.columns))
+	for i := range c.columns {
+		header[i] = strings.TrimSpace(c.columns[i].name)
+	}
+	list := c.channels.Filtered()
+	rows := make([][]string, len(list))
+	for i := range list {
+		rows[i] = make([]string, len(c.columns))
+		for j := range c.columns {
+			rows[i][j] = strings.TrimSpace(color.Strip(c.columns[j].display(list[i])))
+		}
+	}
+	return header, rows
+}
+
+// SetExported displays the message of an export in the footer for a few
+// seconds.
+func (c *Channels) SetExported(msg string) {
+	c.exported = msg
+	c.exportedAt = time.Now()
+}
+
 func (c *Channels) Delete(g *gocui.Gui) error {
 	err := g.DeleteView(CHANNELS_COLUMNS)
 	if err != nil {
@@ -203,6 +339,7 @@ func (c *Channels) Delete(g *gocui.Gui) error {
 		}
 	}
 	c.columnViews = c.columnViews[:0]
+	c.rows = 0
 	return g.DeleteView(CHANNELS_FOOTER)
 }
 
@@ -217,8 +354,8 @@ func (c *Channels) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		setCursor = true
 	}
 	c.columnHeadersView.Frame = false
-	c.columnHeadersView.BgColor = gocui.ColorGreen
-	c.columnHeadersView.FgColor = gocui.ColorBlack
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
 
 	c.view, err = g.SetView(CHANNELS, x0-1, y0+1, x1+2, y1-1, 0)
 	if err != nil {
@@ -229,8 +366,8 @@ func (c *Channels) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	}
 	c.view.Frame = false
 	c.view.Autoscroll = false
-	c.view.SelBgColor = gocui.ColorCyan
-	c.view.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
 	c.view.Highlight = false
 	c.display(g)
 
@@ -255,15 +392,42 @@ func (c *Channels) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		}
 	}
 	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
-	footer.Rewind()
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	// the message of an export is longer than the keys, Rewind would
+	// leave it once it expired.
+	footer.Clear()
 	blackBg := color.Black(color.Background)
-	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
+	private := "Hide private"
+	if c.channels.PrivateHidden() {
+		private = "Show private"
+	}
+	search := "Search"
+	if query := c.channels.Search(); query != "" {
+		search = fmt.Sprintf("%q %s%s %s%s", query, blackBg("n N"), "Match", blackBg("Esc"), "Clear")
+	}
+	filter := "Filter"
+	if expr := c.channels.FilterExpr(); expr != "" {
+		filter = fmt.Sprintf("%q", expr)
+	}
+	keys := fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s%s %s%s %s%s %s%s %s%s %s%s %s%s",
 		blackBg("F2"), "Menu",
 		blackBg("Enter"), "Channel",
+		blackBg("/"), search,
+		blackBg("F"), filter,
+		blackBg("f"), "Policy",
+		blackBg("r"), "Rebalance",
+		blackBg("x"), "Close",
+		blackBg("p"), private,
+		blackBg("E"), "Export",
+		blackBg("v"), "Columns",
+		blackBg("S"), "Sort",
 		blackBg("F10"), "Quit",
-	))
+	)
+	if c.exported != "" && time.Since(c.exportedAt) < 5*time.Second {
+		keys += " " + c.exported
+	}
+	fmt.Fprintln(footer, keys)
 	return nil
 }
 
@@ -294,15 +458,32 @@ func (c *Channels) display(g *gocui.Gui) {
 			cc, _ := g.SetView("channel_content_"+c.columns[i].name, x0, y0, x0+width+2, y1, 0)
 			cc.Frame = false
 			cc.Autoscroll = false
-			cc.SelBgColor = gocui.ColorCyan
-			cc.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+			cc.SelBgColor = color.Attrs().SelectionBg
+			cc.SelFgColor = color.Attrs().SelectionFg
 			cc.Highlight = true
 			c.columnViews[i] = cc
 		}
 	}
-	for ci, item := range c.channels.List() {
+	list := c.channels.Filtered()
+	// Rewind does not drop the lines of the previous display,
+	// the column views must be cleared if the list shrank.
+	shrank := len(list) < c.rows
+	if shrank {
+		for _, cc := range c.columnViews {
+			cc.Clear()
+		}
+	}
+	c.rows = len(list)
+	// the info of the channels of the rows displayed is fetched first.
+	_, height := c.view.Size()
+	from := min(c.oy, len(list))
+	c.channels.SetVisible(list[from:min(from+height, len(list))])
+	for ci, item := range list {
 		x0, y0, _, y1 := c.view.Dimensions()
 		x0 -= c.ox
+		h := matchHighlight(c.highlights, func() expr.Env {
+			return models.ChannelEnv(item)
+		})
 		for i := range c.columns {
 			var opt color.Option
 			if currentColumnIndex == i {
@@ -314,16 +495,33 @@ func (c *Channels) display(g *gocui.Gui) {
 			if ci == 0 {
 				cc.Rewind()
 			}
-			fmt.Fprintln(cc, c.columns[i].display(item, opt), " ")
+			fmt.Fprintln(cc, h.apply(c.columns[i].name, c.columns[i].display(item, opt)), " ")
 			x0 += width + 1
 		}
 	}
+	if shrank {
+		for _, cc := range c.columnViews {
+			cc.SetOrigin(0, c.oy)
+			cc.SetCursor(c.cx, c.cy)
+		}
+	}
 }
 
-func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
+// Columns returns the columns of the view and their widths.
+func (c *Channels) Columns() ([]string, []int) {
+	widths := make([]int, len(c.columns))
+	for i := range c.columns {
+		widths[i] = c.columns[i].width
+	}
+	return c.names, widths
+}
+
+func NewChannels(cfg *config.View, chans *models.Channels, plugins *models.Plugins, price *models.Price,
+	peers *models.PeerData) *Channels {
 	channels := &Channels{
-		cfg:      cfg,
-		channels: chans,
+		cfg:        cfg,
+		channels:   chans,
+		highlights: newHighlights(cfg),
 	}
 
 	printer := message.NewPrinter(language.English)
@@ -364,6 +562,12 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 					if forced {
 						aliasColor = color.Cyan(opts...)
 					}
+					if c.Private {
+						// the private channels are marked even without
+						// the PRIVATE column.
+						return aliasColor(runewidth.FillRight(runewidth.Truncate(alias, 23, ""), 23)) +
+							color.Red(opts...)(" P")
+					}
 					return aliasColor(fmt.Sprintf("%-25s", alias))
 				},
 			}
@@ -436,6 +640,24 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 					return color.White(opts...)(printer.Sprintf("%12d", c.Capacity))
 				},
 			}
+		case "FIAT_LOCAL", "FIAT_REMOTE", "FIAT_CAP":
+			amount := map[string]func(*netmodels.Channel) int64{
+				"FIAT_LOCAL":  func(c *netmodels.Channel) int64 { return c.LocalBalance },
+				"FIAT_REMOTE": func(c *netmodels.Channel) int64 { return c.RemoteBalance },
+				"FIAT_CAP":    func(c *netmodels.Channel) int64 { return c.Capacity },
+			}[columns[i]]
+			channels.columns[i] = channelsColumn{
+				width: 12,
+				name:  fmt.Sprintf("%12s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						return models.Int64Sort(amount(c1), amount(c2), order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					return color.Green(opts...)(fiat(price, float64(amount(c)), 12, 2))
+				},
+			}
 		case "SENT":
 			channels.columns[i] = channelsColumn{
 				width: 12,
@@ -536,6 +758,27 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 					return color.Green(opts...)("public ")
 				},
 			}
+		case "DISABLED":
+			channels.columns[i] = channelsColumn{
+				width: 8,
+				name:  fmt.Sprintf("%-8s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						return models.IntSort(disabledRank(c1), disabledRank(c2), order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					switch disabledRank(c) {
+					case 3:
+						return color.Red(opts...)("both    ")
+					case 2:
+						return color.Red(opts...)("peer    ")
+					case 1:
+						return color.Yellow(opts...)("ours    ")
+					}
+					return fmt.Sprintf("%-8s", "")
+				},
+			}
 		case "ID":
 			channels.columns[i] = channelsColumn{
 				width: 19,
@@ -565,7 +808,7 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 					if c.ID == 0 {
 						return fmt.Sprintf("%-14s", "")
 					}
-					return color.White(opts...)(fmt.Sprintf("%-14s", ToScid(c.ID)))
+					return color.White(opts...)(fmt.Sprintf("%-14s", netmodels.ToScid(c.ID)))
 				},
 			}
 		case "NUPD":
@@ -702,8 +945,209 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 					}
 				},
 			}
+		case "HEALTH":
+			channels.columns[i] = channelsColumn{
+				width: 6,
+				name:  fmt.Sprintf("%6s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						h1, _ := chans.Health(c1.ChannelPoint)
+						h2, _ := chans.Health(c2.ChannelPoint)
+						return models.IntSort(h1, h2, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					score, ok := chans.Health(c.ChannelPoint)
+					if !ok {
+						return fmt.Sprintf("%6s", "")
+					}
+					result := fmt.Sprintf("%6d", score)
+					switch {
+					case score < 40:
+						return color.Red(opts...)(result)
+					case score < 70:
+						return color.Yellow(opts...)(result)
+					}
+					return color.Green(opts...)(result)
+				},
+			}
+		case "FEE_RATIO":
+			ratio := feeRatioColumn(cfg, "FEE_RATIO")
+			channels.columns[i] = channelsColumn{
+				width: 9,
+				name:  fmt.Sprintf("%9s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						r1, _ := feeRatio(c1)
+						r2, _ := feeRatio(c2)
+						return models.Float64Sort(r1, r2, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					return ratio(c, opts...)
+				},
+			}
+		case "LATENCY":
+			latency := latencyColumn(cfg, "LATENCY")
+			channels.columns[i] = channelsColumn{
+				width: 8,
+				name:  fmt.Sprintf("%8s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						return models.Int64Sort(int64(c1.PingTime), int64(c2.PingTime), order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					return latency(c.PingTime, opts...)
+				},
+			}
+		case "UPTIME%":
+			channels.columns[i] = channelsColumn{
+				width: 7,
+				name:  fmt.Sprintf("%7s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						u1, _ := peerUptime(chans, c1.RemotePubKey)
+						u2, _ := peerUptime(chans, c2.RemotePubKey)
+						return models.Float64Sort(u1, u2, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					uptime, ok := peerUptime(chans, c.RemotePubKey)
+					if !ok {
+						return fmt.Sprintf("%7s", "")
+					}
+					result := fmt.Sprintf("%6.1f%%", uptime)
+					switch {
+					case uptime < 90:
+						return color.Red(opts...)(result)
+					case uptime < 99:
+						return color.Yellow(opts...)(result)
+					}
+					return color.Green(opts...)(result)
+				},
+			}
+		case "FLAPS":
+			channels.columns[i] = channelsColumn{
+				width: 5,
+				name:  fmt.Sprintf("%5s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						u1, _ := chans.PeerUptime(c1.RemotePubKey)
+						u2, _ := chans.PeerUptime(c2.RemotePubKey)
+						return models.IntSort(u1.Flaps, u2.Flaps, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					u, ok := chans.PeerUptime(c.RemotePubKey)
+					if !ok {
+						return fmt.Sprintf("%5s", "")
+					}
+					text := fmt.Sprintf("%5d", u.Flaps)
+					if u.Flaps > 0 {
+						return color.Yellow(opts...)(text)
+					}
+					return color.White(opts...)(text)
+				},
+			}
+		case "FEES_1D", "FEES_7D", "FEES_30D", "VOLUME_1D", "VOLUME_7D", "VOLUME_30D":
+			value := statsColumn(chans, columns[i])
+			channels.columns[i] = channelsColumn{
+				width: 12,
+				name:  fmt.Sprintf("%12s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						return models.Int64Sort(value(c1), value(c2), order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					return color.Cyan(opts...)(printer.Sprintf("%12d", value(c)))
+				},
+			}
+		case "SCORE":
+			channels.columns[i] = channelsColumn{
+				width: 6,
+				name:  fmt.Sprintf("%6s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						md1, _ := peers.Get(c1.RemotePubKey)
+						md2, _ := peers.Get(c2.RemotePubKey)
+						return models.Float64Sort(md1.Score, md2.Score, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					md, ok := peers.Get(c.RemotePubKey)
+					if !ok || md.Score == 0 {
+						return fmt.Sprintf("%6s", "")
+					}
+					return color.Cyan(opts...)(fmt.Sprintf("%6.1f", md.Score))
+				},
+			}
+		case "TAGS":
+			channels.columns[i] = channelsColumn{
+				width: 20,
+				name:  fmt.Sprintf("%-20s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						return models.StringSort(channelTags(c1, peers), channelTags(c2, peers), order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					// the tags of the channel first, then the ones of
+					// the peer data.
+					tags := runewidth.FillRight(runewidth.Truncate(channelTags(c, peers), 20, ""), 20)
+					local := runewidth.Truncate(strings.Join(c.Tags, ","), 20, "")
+					return color.Yellow(opts...)(local) + color.White(opts...)(tags[len(local):])
+				},
+			}
+		case "BALANCE_1D", "BALANCE_7D", "DRIFT_1D", "DRIFT_7D":
+			channels.columns[i] = balanceColumn(chans, columns[i])
+		case "RANK":
+			channels.columns[i] = channelsColumn{
+				width: 7,
+				name:  fmt.Sprintf("%7s", columns[i]),
+				sort: func(order models.Order) models.ChannelsSort {
+					return func(c1, c2 *netmodels.Channel) bool {
+						md1, _ := peers.Get(c1.RemotePubKey)
+						md2, _ := peers.Get(c2.RemotePubKey)
+						return models.IntSort(md1.Rank, md2.Rank, order)
+					}
+				},
+				display: func(c *netmodels.Channel, opts ...color.Option) string {
+					md, ok := peers.Get(c.RemotePubKey)
+					if !ok || md.Rank == 0 {
+						return fmt.Sprintf("%7s", "")
+					}
+					return color.White(opts...)(printer.Sprintf("%7d", md.Rank))
+				},
+			}
 
 		default:
+			if cfg != nil {
+				if cc, ok := cfg.Computed[columns[i]]; ok {
+					channels.columns[i] = computedChannelsColumn(columns[i], cc)
+					continue
+				}
+			}
+			if name, ok := strings.CutPrefix(columns[i], "PLUGIN:"); ok {
+				channels.columns[i] = channelsColumn{
+					width: 12,
+					name:  fmt.Sprintf("%12s", runewidth.Truncate(name, 12, "")),
+					sort: func(order models.Order) models.ChannelsSort {
+						return func(c1, c2 *netmodels.Channel) bool {
+							return models.ValueSort(
+								plugins.ChannelValue(name, c1.ChannelPoint),
+								plugins.ChannelValue(name, c2.ChannelPoint),
+								order)
+						}
+					},
+					display: func(c *netmodels.Channel, opts ...color.Option) string {
+						value := runewidth.Truncate(plugins.ChannelValue(name, c.ChannelPoint), 12, "")
+						return color.White(opts...)(runewidth.FillLeft(value, 12))
+					},
+				}
+				continue
+			}
 			channels.columns[i] = channelsColumn{
 				width: 21,
 				name:  fmt.Sprintf("%-21s", columns[i]),
@@ -714,6 +1158,23 @@ func NewChannels(cfg *config.View, chans *models.Channels) *Channels {
 		}
 	}
 
+	channels.names = columns
+	for i := range channels.columns {
+		w, ok := columnWidth(cfg, columns[i])
+		if !ok {
+			continue
+		}
+		col := &channels.columns[i]
+		display, right := col.display, rightAligned(col.name)
+		col.name, col.width = fitCell(col.name, w, right), w
+		col.display = func(item *netmodels.Channel, opts ...color.Option) string {
+			return fitCell(display(item, opts...), w, right)
+		}
+	}
+	if cfg != nil && len(cfg.Sort) > 0 {
+		channels.SortBy(ParseSortKeys(cfg.Sort))
+	}
+
 	return channels
 }
 
@@ -764,3 +1225,157 @@ func status(c *netmodels.Channel, opts ...color.Option) string {
 	}
 	return ""
 }
+
+// feeRatio returns the outgoing fee rate of the channel divided by the
+// fee rate of the peer toward the node, false if it cannot be computed.
+func feeRatio(c *netmodels.Channel) (float64, bool) {
+	if c.LocalPolicy == nil || c.RemotePolicy == nil {
+		return 0, false
+	}
+	out, in := c.LocalPolicy.FeeRateMilliMsat, c.RemotePolicy.FeeRateMilliMsat
+	switch {
+	case in == 0 && out == 0:
+		return 1, true
+	case in == 0:
+		return math.Inf(1), true
+	}
+	return float64(out) / float64(in), true
+}
+
+// feeRatioColumn returns the display of the fee ratio, red at over or
+// above as the node prices the channel far above its peer, yellow at
+// under or below as it prices it far below.
+func feeRatioColumn(cfg *config.View, column string) func(*netmodels.Channel, ...color.Option) string {
+	over, under := 4.0, 0.25
+	if cfg != nil {
+		over = parseRatio(cfg.Options.GetOption(column, "over"), over)
+		under = parseRatio(cfg.Options.GetOption(column, "under"), under)
+	}
+	return func(c *netmodels.Channel, opts ...color.Option) string {
+		r, ok := feeRatio(c)
+		if !ok {
+			return fmt.Sprintf("%9s", "")
+		}
+		result := fmt.Sprintf("%8.2fx", r)
+		if math.IsInf(r, 1) {
+			result = fmt.Sprintf("%9s", "inf")
+		}
+		switch {
+		case r >= over:
+			return color.Red(opts...)(result)
+		case r <= under:
+			return color.Yellow(opts...)(result)
+		}
+		return color.White(opts...)(result)
+	}
+}
+
+func parseRatio(s string, def float64) float64 {
+	r, err := strconv.ParseFloat(s, 64)
+	if err != nil || r <= 0 {
+		return def
+	}
+	return r
+}
+
+// statsColumn returns the fees or the volume in sat of the window of the
+// column, FEES_7D is the fees earned over the last 7 days.
+// channelTags returns the tags of the channel followed by the ones of the
+// peer in the peer data.
+func channelTags(c *netmodels.Channel, peers *models.PeerData) string {
+	tags := strings.Join(c.Tags, ",")
+	md, _ := peers.Get(c.RemotePubKey)
+	if len(md.Tags) == 0 {
+		return tags
+	}
+	if tags != "" {
+		tags += " "
+	}
+	return tags + strings.Join(md.Tags, ",")
+}
+
+func statsColumn(chans *models.Channels, column string) func(*netmodels.Channel) int64 {
+	kind, window, _ := strings.Cut(column, "_")
+	w := 0
+	for i := range stats.WindowNames {
+		if stats.WindowNames[i] == window {
+			w = i
+		}
+	}
+	return func(c *netmodels.Channel) int64 {
+		s := chans.Stats(c.ID)[w]
+		if kind == "FEES" {
+			return s.Fee()
+		}
+		return s.Volume()
+	}
+}
+
+// computedChannelsColumn returns the column displaying the result of
+// the expression of the config, the expression is parsed when the config
+// loads.
+func computedChannelsColumn(name string, cc config.ComputedColumn) channelsColumn {
+	width := cc.Width
+	if width <= 0 {
+		width = 10
+	}
+	e, err := expr.Parse(cc.Expr)
+	if err != nil {
+		return channelsColumn{
+			width: 21,
+			name:  fmt.Sprintf("%-21s", name),
+			display: func(c *netmodels.Channel, opts ...color.Option) string {
+				return fmt.Sprintf("%-21s", "invalid expression")
+			},
+		}
+	}
+
+	value := func(c *netmodels.Channel) string {
+		v, err := e.Eval(models.ChannelEnv(c))
+		if errors.Is(err, expr.ErrDivisionByZero) {
+			return "-"
+		}
+		if err != nil {
+			return "error"
+		}
+		return expr.Format(v, cc.Format)
+	}
+
+	return channelsColumn{
+		width: width,
+		name:  fmt.Sprintf("%*s", width, runewidth.Truncate(name, width, "")),
+		sort: func(order models.Order) models.ChannelsSort {
+			return func(c1, c2 *netmodels.Channel) bool {
+				return models.ValueSort(value(c1), value(c2), order)
+			}
+		},
+		display: func(c *netmodels.Channel, opts ...color.Option) string {
+			v := runewidth.Truncate(value(c), width, "")
+			return color.White(opts...)(runewidth.FillLeft(v, width))
+		},
+	}
+}
+
+// disabledRank ranks the channels by the side which disabled its policy:
+// 1 the node, 2 the peer and 3 both, 0 if none did.
+func disabledRank(c *netmodels.Channel) int {
+	rank := 0
+	if c.LocalPolicy != nil && c.LocalPolicy.Disabled {
+		rank++
+	}
+	if c.RemotePolicy != nil && c.RemotePolicy.Disabled {
+		rank += 2
+	}
+	return rank
+}
+
+// peerUptime returns the percentage of the time the peer was seen online,
+// false until it was seen for some time.
+func peerUptime(chans *models.Channels, pubkey string) (float64, bool) {
+	u, ok := chans.PeerUptime(pubkey)
+	total := u.Online + u.Offline
+	if !ok || total == 0 {
+		return 0, false
+	}
+	return 100 * float64(u.Online) / float64(total), true
+}
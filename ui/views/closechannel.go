@@ -0,0 +1,202 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	CLOSE_CHANNEL          = "close_channel"
+	CLOSE_CHANNEL_FEE_RATE = "close_channel_fee_rate"
+	CLOSE_CHANNEL_FORCE    = "close_channel_force"
+)
+
+// CloseRequest is the close of the dialog, the wallet estimates the fee
+// rate of a cooperative close if it is zero.
+type CloseRequest struct {
+	Force       bool
+	SatPerVbyte uint64
+}
+
+// CloseChannel is the dialog closing the channel selected in the channels
+// view, the first enter asks for a confirmation and the second one
+// closes it.
+type CloseChannel struct {
+	form
+	visible   bool
+	channel   *netmodels.Channel
+	confirmed *CloseRequest
+	// rate is the fee rate the field was filled with, ignored by a force
+	// close.
+	rate string
+	// closing is true while the channel is closed.
+	closing bool
+	err     error
+}
+
+func (c *CloseChannel) Visible() bool {
+	return c.visible
+}
+
+// Show opens the dialog for the channel, a cooperative close at the fee
+// rate by default, the one estimated by the wallet if it is zero.
+func (c *CloseChannel) Show(channel *netmodels.Channel, rate uint64) {
+	c.rate = formatFeeRate(rate)
+	c.reset(c.rate, "no")
+	c.channel = channel
+	c.confirmed = nil
+	c.closing = false
+	c.err = nil
+	c.visible = true
+}
+
+func (c *CloseChannel) Hide() {
+	c.visible = false
+	c.channel = nil
+	c.confirmed = nil
+	c.closing = false
+	c.err = nil
+}
+
+// Start marks the confirmed close as sent until its result is set.
+func (c *CloseChannel) Start() {
+	c.closing = true
+}
+
+// Closing returns true while the channel is closed.
+func (c *CloseChannel) Closing() bool {
+	return c.closing
+}
+
+// Channel returns the channel to close.
+func (c *CloseChannel) Channel() *netmodels.Channel {
+	return c.channel
+}
+
+// Value returns the close of the fields.
+func (c *CloseChannel) Value() (*CloseRequest, error) {
+	if c.channel.Status != netmodels.ChannelActive && c.channel.Status != netmodels.ChannelInactive {
+		return nil, errors.Errorf("the channel is %s", c.channel.StatusName())
+	}
+	force, err := parseYesNo(c.inputs[1].Value())
+	if err != nil {
+		return nil, errors.Errorf("force: %s", err)
+	}
+	rate := uint64(0)
+	if s := c.inputs[0].Value(); s != "" && !(force && s == c.rate) {
+		if force {
+			return nil, errors.New("the fee rate of a force close is the one of the commitment")
+		}
+		rate, err = parseFeeRate(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &CloseRequest{Force: force, SatPerVbyte: rate}, nil
+}
+
+// Confirm returns true if the close was confirmed by the previous enter,
+// otherwise it asks to confirm it.
+func (c *CloseChannel) Confirm(req *CloseRequest) bool {
+	if c.confirmed != nil && *c.confirmed == *req {
+		return true
+	}
+	c.confirmed = req
+	c.err = nil
+	return false
+}
+
+// SetError sets the error of the fields or of the close, the dialog
+// stays open.
+func (c *CloseChannel) SetError(err error) {
+	c.confirmed = nil
+	c.closing = false
+	c.err = err
+}
+
+func (c *CloseChannel) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(c.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := c.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(CLOSE_CHANNEL, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " close channel "
+	c.display(v)
+	return nil
+}
+
+func (c *CloseChannel) display(v *gocui.View) {
+	v.Clear()
+	if c.channel == nil {
+		fmt.Fprintln(v, "no channel selected, esc to close")
+		return
+	}
+	cyan := color.Cyan()
+	alias, _ := c.channel.ShortAlias()
+	fmt.Fprintf(v, "%s %s %s\n", cyan("channel"), alias, c.channel.ChannelPoint)
+	if c.err != nil {
+		fmt.Fprintln(v, color.Red()(c.err.Error()))
+		return
+	}
+	if c.closing {
+		fmt.Fprintln(v, color.Yellow()("closing..."))
+		return
+	}
+	if c.confirmed != nil {
+		switch {
+		case c.confirmed.Force:
+			fmt.Fprintf(v, "%s, the balance is locked for %d blocks?\n",
+				color.Red(color.Bold)("force close the channel"), c.channel.CSVDelay)
+		case c.confirmed.SatPerVbyte > 0:
+			fmt.Fprintf(v, "close the channel with the peer at %d sat/vbyte?\n", c.confirmed.SatPerVbyte)
+		default:
+			fmt.Fprintln(v, "close the channel with the peer at the fee rate estimated by the wallet?")
+		}
+		fmt.Fprintln(v, "press enter again to close it, esc to cancel")
+		return
+	}
+	fmt.Fprintln(v, "the wallet estimates the fee rate if empty, a force close publishes the commitment")
+	fmt.Fprintln(v, "tab moves to the next field, enter closes the channel, esc to cancel")
+}
+
+func (c *CloseChannel) Delete(g *gocui.Gui) error {
+	err := c.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(CLOSE_CHANNEL)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewCloseChannel() *CloseChannel {
+	return &CloseChannel{form: form{inputs: []*Input{
+		NewInput(CLOSE_CHANNEL_FEE_RATE, " fee rate (sat/vbyte) ", validateFeeRate),
+		NewInput(CLOSE_CHANNEL_FORCE, " force (yes/no) ", validateYesNo),
+	}}}
+}
@@ -0,0 +1,520 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	CLOSED         = "closed"
+	CLOSED_SUMMARY = "closed_summary"
+	CLOSED_COLUMNS = "closed_columns"
+	CLOSED_FOOTER  = "closed_footer"
+)
+
+var DefaultClosedColumns = []string{
+	"DATE",
+	"ALIAS",
+	"TYPE",
+	"OPENER",
+	"CLOSER",
+	"CAP",
+	"SETTLED",
+	"FEE",
+	"HEIGHT",
+}
+
+type Closed struct {
+	cfg *config.View
+
+	columns           []closedColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	summaryView       *gocui.View
+	closed            *models.ClosedChannels
+
+	printer *message.Printer
+
+	ox, oy int
+	cx, cy int
+	// rows is the number of closed channels of the last display.
+	rows int
+}
+
+type closedColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.ClosedChannelsSort
+	display func(*netmodels.ClosedChannel, ...color.Option) string
+}
+
+func (c Closed) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Closed) Name() string {
+	return CLOSED
+}
+
+func (c *Closed) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Closed) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Closed) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Closed) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Closed) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Closed) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Closed) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.closed.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Closed) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.closed.Len()
+	return
+}
+
+func (c *Closed) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.closed.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Closed) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(CLOSED_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(CLOSED)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(CLOSED_SUMMARY)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(CLOSED_FOOTER)
+}
+
+func (c *Closed) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.summaryView, err = g.SetView(CLOSED_SUMMARY, x0-1, y0, x1+2, y0+3, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	c.summaryView.Frame = false
+	c.displaySummary()
+
+	y0 += 2
+	c.columnHeadersView, err = g.SetView(CLOSED_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(CLOSED, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(CLOSED_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("t"), "Range",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+// displaySummary writes the breakdown of the closes of the time range.
+func (c *Closed) displaySummary() {
+	c.summaryView.Clear()
+	rng := c.closed.Range()
+	b := models.NewCloseBreakdown(c.closed.List())
+	cyan := color.Cyan()
+
+	count := func(m map[string]int, names ...string) string {
+		var buffer bytes.Buffer
+		for _, name := range names {
+			if m[name] == 0 {
+				continue
+			}
+			if buffer.Len() > 0 {
+				buffer.WriteString(", ")
+			}
+			buffer.WriteString(fmt.Sprintf("%s %d", name, m[name]))
+		}
+		if buffer.Len() == 0 {
+			return "none"
+		}
+		return buffer.String()
+	}
+
+	fmt.Fprintf(c.summaryView, "%s %d closed in %s, %s %s\n",
+		cyan("closed"), b.Total, models.RangeName(rng),
+		cyan("closing fees"), c.printer.Sprintf("%d sat", b.ClosingFees))
+	fmt.Fprintf(c.summaryView, "%s %s  %s %s\n",
+		cyan("type"), count(b.ByType, "cooperative", "local force", "remote force",
+			"breach", "funding canceled", "abandoned", "unknown"),
+		cyan("closer"), count(b.ByInitiator, "local", "remote", "both", "unknown"))
+}
+
+func (c *Closed) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.closed.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once the range is shorter.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func NewClosed(cfg *config.View, closed *models.ClosedChannels) *Closed {
+	printer := message.NewPrinter(language.English)
+	view := &Closed{
+		cfg:     cfg,
+		closed:  closed,
+		printer: printer,
+	}
+
+	columns := DefaultClosedColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]closedColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "DATE":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.DateSort(&c1.CloseTime, &c2.CloseTime, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.Cyan(opts...)(fmt.Sprintf("%15s", c.CloseTime.Format("15:04:05 Jan _2")))
+				},
+			}
+		case "ALIAS":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-25s", columns[i]),
+				width: 25,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.StringSort(c1.Alias(), c2.Alias(), order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					alias := c.Alias()
+					if alias == "" {
+						alias = c.RemotePubKey
+					}
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(alias, 25, ""), 25))
+				},
+			}
+		case "TYPE":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-16s", columns[i]),
+				width: 16,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.IntSort(c1.CloseType, c2.CloseType, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					name := fmt.Sprintf("%-16s", netmodels.CloseTypeName(c.CloseType))
+					switch c.CloseType {
+					case netmodels.CloseCooperative:
+						return color.Green(opts...)(name)
+					case netmodels.CloseBreach:
+						return color.Red(opts...)(name)
+					case netmodels.CloseLocalForce, netmodels.CloseRemoteForce:
+						return color.Yellow(opts...)(name)
+					}
+					return color.White(opts...)(name)
+				},
+			}
+		case "OPENER", "CLOSER":
+			closer := columns[i] == "CLOSER"
+			initiator := func(c *netmodels.ClosedChannel) int {
+				if closer {
+					return c.CloseInitiator
+				}
+				return c.OpenInitiator
+			}
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-7s", columns[i]),
+				width: 7,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.IntSort(initiator(c1), initiator(c2), order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-7s", netmodels.InitiatorName(initiator(c))))
+				},
+			}
+		case "CAP":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.Int64Sort(c1.Capacity, c2.Capacity, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", c.Capacity))
+				},
+			}
+		case "SETTLED":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.Int64Sort(c1.SettledBalance, c2.SettledBalance, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", c.SettledBalance))
+				},
+			}
+		case "TIMELOCKED":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.Int64Sort(c1.TimeLockedBalance, c2.TimeLockedBalance, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", c.TimeLockedBalance))
+				},
+			}
+		case "FEE":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.Int64Sort(c1.ClosingFee, c2.ClosingFee, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%8d", c.ClosingFee))
+				},
+			}
+		case "HEIGHT":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.ClosedChannelsSort {
+					return func(c1, c2 *netmodels.ClosedChannel) bool {
+						return models.UInt32Sort(c1.CloseHeight, c2.CloseHeight, order)
+					}
+				},
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", c.CloseHeight))
+				},
+			}
+		case "CHANNEL_POINT":
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-68s", columns[i]),
+				width: 68,
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-68s", c.ChannelPoint))
+				},
+			}
+		default:
+			view.columns[i] = closedColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(c *netmodels.ClosedChannel, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
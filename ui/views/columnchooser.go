@@ -0,0 +1,348 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/cursor"
+)
+
+const (
+	COLUMN_CHOOSER      = "column_chooser"
+	COLUMN_CHOOSER_HELP = "column_chooser_help"
+
+	// minColumnWidth is the width below which a column is not shrunk.
+	minColumnWidth = 2
+)
+
+// chooserColumn is a column of the chooser, its width is the default
+// one of the column unless resized.
+type chooserColumn struct {
+	name    string
+	visible bool
+	width   int
+	def     int
+}
+
+// ColumnChooser is the popup showing, hiding, ordering and resizing the
+// columns of the main view, the visible columns first.
+type ColumnChooser struct {
+	view    *gocui.View
+	visible bool
+	target  string
+	columns []*chooserColumn
+	err     error
+
+	cy, oy int
+}
+
+func (c *ColumnChooser) Visible() bool {
+	return c.visible
+}
+
+// Show opens the chooser of the view with its columns and widths, then
+// the other columns of all hidden. defaults are the default widths by
+// column.
+func (c *ColumnChooser) Show(target string, columns []string, widths []int, all []string, defaults map[string]int) {
+	c.target = target
+	c.columns = nil
+	shown := map[string]bool{}
+	for i := range columns {
+		shown[columns[i]] = true
+		def, ok := defaults[columns[i]]
+		if !ok {
+			def = widths[i]
+		}
+		c.columns = append(c.columns, &chooserColumn{
+			name: columns[i], visible: true, width: widths[i], def: def,
+		})
+	}
+	for _, name := range all {
+		if !shown[name] {
+			c.columns = append(c.columns, &chooserColumn{
+				name: name, width: defaults[name], def: defaults[name],
+			})
+		}
+	}
+	c.cy, c.oy = 0, 0
+	c.err = nil
+	c.visible = true
+}
+
+func (c *ColumnChooser) Hide() {
+	c.visible = false
+}
+
+// Target returns the name of the view of the columns.
+func (c *ColumnChooser) Target() string {
+	return c.target
+}
+
+// Value returns the visible columns in their order and the widths of
+// the resized ones.
+func (c *ColumnChooser) Value() ([]string, map[string]int) {
+	var columns []string
+	widths := map[string]int{}
+	for _, col := range c.columns {
+		if !col.visible {
+			continue
+		}
+		columns = append(columns, col.name)
+		if col.width != col.def {
+			widths[col.name] = col.width
+		}
+	}
+	return columns, widths
+}
+
+// SetError displays the error, the chooser stays open.
+func (c *ColumnChooser) SetError(err error) {
+	c.err = err
+}
+
+func (c *ColumnChooser) current() *chooserColumn {
+	i := c.cy + c.oy
+	if i < 0 || i >= len(c.columns) {
+		return nil
+	}
+	return c.columns[i]
+}
+
+// Toggle shows or hides the current column.
+func (c *ColumnChooser) Toggle() {
+	if col := c.current(); col != nil {
+		col.visible = !col.visible
+		c.err = nil
+	}
+}
+
+// Move moves the current column one line down if delta is positive, up
+// otherwise, the cursor follows it.
+func (c *ColumnChooser) Move(delta int) error {
+	i := c.cy + c.oy
+	j := i - 1
+	if delta > 0 {
+		j = i + 1
+	}
+	if i < 0 || i >= len(c.columns) || j < 0 || j >= len(c.columns) {
+		return nil
+	}
+	c.columns[i], c.columns[j] = c.columns[j], c.columns[i]
+	if delta > 0 {
+		return cursor.Down(c)
+	}
+	return cursor.Up(c)
+}
+
+// Resize widens or shrinks the current column by delta.
+func (c *ColumnChooser) Resize(delta int) {
+	col := c.current()
+	if col == nil {
+		return
+	}
+	col.width += delta
+	if col.width < minColumnWidth {
+		col.width = minColumnWidth
+	}
+}
+
+func (c ColumnChooser) Name() string {
+	return COLUMN_CHOOSER
+}
+
+func (c *ColumnChooser) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c ColumnChooser) Origin() (int, int) {
+	return 0, c.oy
+}
+
+func (c ColumnChooser) Cursor() (int, int) {
+	return 0, c.cy
+}
+
+func (c ColumnChooser) Speed() (int, int, int, int) {
+	down := 0
+	if c.cy+c.oy < len(c.columns)-1 {
+		down = 1
+	}
+	return 0, 0, down, 1
+}
+
+func (c ColumnChooser) Limits() (pageSize int, fullSize int) {
+	if c.view != nil {
+		_, pageSize = c.view.Size()
+	}
+	return pageSize, len(c.columns)
+}
+
+func (c *ColumnChooser) SetCursor(x, y int) error {
+	err := c.view.SetCursor(x, y)
+	if err != nil {
+		return err
+	}
+	c.cy = y
+	return nil
+}
+
+func (c *ColumnChooser) SetOrigin(x, y int) error {
+	err := c.view.SetOrigin(x, y)
+	if err != nil {
+		return err
+	}
+	c.oy = y
+	return nil
+}
+
+func (c *ColumnChooser) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	width := 50
+	if width > x1-x0 {
+		width = x1 - x0
+	}
+	x0 = x0 + (x1-x0-width)/2
+	x1 = x0 + width
+	if y0+len(c.columns)+3 < y1 {
+		y1 = y0 + len(c.columns) + 3
+	}
+
+	setCursor := false
+	var err error
+	c.view, err = g.SetView(COLUMN_CHOOSER, x0, y0, x1, y1-2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = true
+	c.view.Title = fmt.Sprintf(" columns of %s ", c.target)
+	c.view.Highlight = true
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Clear()
+	for _, col := range c.columns {
+		check := "[ ]"
+		if col.visible {
+			check = color.Green()("[x]")
+		}
+		w := fmt.Sprintf("%3d", col.width)
+		if col.width != col.def {
+			w = color.Yellow()(w)
+		}
+		fmt.Fprintf(c.view, " %s %-*s %s\n", check, width-11, col.name, w)
+	}
+	if setCursor {
+		err = c.SetOrigin(0, c.oy)
+		if err != nil {
+			return err
+		}
+		err = c.SetCursor(0, c.cy)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = g.SetCurrentView(COLUMN_CHOOSER)
+	if err != nil {
+		return err
+	}
+
+	help, err := g.SetView(COLUMN_CHOOSER_HELP, x0, y1-2, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	help.Frame = true
+	help.Clear()
+	if c.err != nil {
+		fmt.Fprintln(help, color.Red()(c.err.Error()))
+	} else {
+		fmt.Fprintln(help, "space shows, K J move, + - resize, enter saves")
+	}
+	return nil
+}
+
+func (c *ColumnChooser) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(COLUMN_CHOOSER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	err = g.DeleteView(COLUMN_CHOOSER_HELP)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewColumnChooser() *ColumnChooser {
+	return &ColumnChooser{}
+}
+
+// columnWidth returns the width of the column in the config, false if it
+// is not resized.
+func columnWidth(cfg *config.View, name string) (int, bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	w, ok := cfg.Widths[name]
+	return w, ok && w >= minColumnWidth
+}
+
+// rightAligned returns true if the name of the column is padded on the
+// left, its cells are aligned on the right.
+func rightAligned(name string) bool {
+	return strings.HasPrefix(name, " ")
+}
+
+// fitCell pads or cuts the cell to w characters, on the left for a right
+// aligned column, the color codes of the cell are kept.
+func fitCell(cell string, w int, right bool) string {
+	n := runewidth.StringWidth(color.Strip(cell))
+	if n < w {
+		pad := strings.Repeat(" ", w-n)
+		if right {
+			return pad + cell
+		}
+		return cell + pad
+	}
+	if n == w {
+		return cell
+	}
+
+	// the characters from drop to keep are kept, counted in cells.
+	drop, keep := 0, w
+	if right {
+		drop, keep = n-w, n
+	}
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(cell); {
+		if strings.HasPrefix(cell[i:], "\x1b[") {
+			j := strings.IndexFunc(cell[i+2:], func(r rune) bool {
+				return r >= '@' && r <= '~'
+			})
+			if j < 0 {
+				break
+			}
+			b.WriteString(cell[i : i+j+3])
+			i += j + 3
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(cell[i:])
+		rw := runewidth.RuneWidth(r)
+		if pos >= drop && pos+rw <= keep {
+			b.WriteRune(r)
+		}
+		pos += rw
+		i += size
+	}
+	return b.String()
+}
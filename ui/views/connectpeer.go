@@ -0,0 +1,145 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	CONNECT_PEER       = "connect_peer"
+	CONNECT_PEER_INPUT = "connect_peer_input"
+)
+
+// ConnectPeer is the popup connecting to the node of the pubkey@host:port
+// uri typed in its input, the connection runs in the background.
+type ConnectPeer struct {
+	input      *Input
+	visible    bool
+	connecting bool
+	err        error
+}
+
+func (c *ConnectPeer) Visible() bool {
+	return c.visible
+}
+
+func (c *ConnectPeer) Show() {
+	c.input.SetValue("")
+	c.connecting = false
+	c.err = nil
+	c.visible = true
+}
+
+func (c *ConnectPeer) Hide() {
+	c.visible = false
+	c.connecting = false
+	c.err = nil
+}
+
+// Value returns the pubkey and the host of the uri of the input.
+func (c *ConnectPeer) Value() (string, string, error) {
+	return parseNodeURI(c.input.Value())
+}
+
+// Pasting returns true while an uri is pasted in the input.
+func (c *ConnectPeer) Pasting() bool {
+	return c.input.Pasting()
+}
+
+// Connecting returns true until the connection succeeded or failed.
+func (c *ConnectPeer) Connecting() bool {
+	return c.connecting
+}
+
+func (c *ConnectPeer) Start() {
+	c.connecting = true
+	c.err = nil
+}
+
+// SetError sets the error of the uri or of the connection, the popup stays
+// open.
+func (c *ConnectPeer) SetError(err error) {
+	c.connecting = false
+	c.err = err
+}
+
+func (c *ConnectPeer) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 110
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+7 > maxY {
+		y0 = 0
+	}
+
+	err := c.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(CONNECT_PEER, x0, y0+3, x0+width, y0+6, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " connect peer "
+	c.display(v)
+	return nil
+}
+
+func (c *ConnectPeer) display(v *gocui.View) {
+	v.Clear()
+	switch {
+	case c.err != nil:
+		fmt.Fprintln(v, color.Red()(c.err.Error()))
+	case c.connecting:
+		fmt.Fprintln(v, color.Yellow()("connecting..."))
+	default:
+		fmt.Fprintln(v, "type the pubkey@host:port of the node and press enter, esc to close")
+	}
+}
+
+func (c *ConnectPeer) Delete(g *gocui.Gui) error {
+	err := c.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(CONNECT_PEER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+// parseNodeURI returns the pubkey and the host of a pubkey@host:port uri,
+// the port is the default one of the backend if it is omitted.
+func parseNodeURI(s string) (string, string, error) {
+	pubkey, host, ok := strings.Cut(strings.TrimSpace(s), "@")
+	if !ok || host == "" {
+		return "", "", errors.New("not a pubkey@host:port uri")
+	}
+	err := validatePubKey(pubkey)
+	if err != nil {
+		return "", "", err
+	}
+	return pubkey, host, nil
+}
+
+func validateNodeURI(s string) error {
+	_, _, err := parseNodeURI(s)
+	return err
+}
+
+func NewConnectPeer() *ConnectPeer {
+	return &ConnectPeer{input: NewInput(CONNECT_PEER_INPUT, " node uri ", validateNodeURI)}
+}
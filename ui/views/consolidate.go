@@ -0,0 +1,157 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	CONSOLIDATE       = "consolidate"
+	CONSOLIDATE_INPUT = "consolidate_input"
+)
+
+// Consolidate is the popup spending the outputs selected in the utxos
+// view to a new output of the wallet, with a preview of the fee at the
+// fee rate of its input.
+type Consolidate struct {
+	input   *Input
+	utxos   *models.UTXOs
+	printer *message.Printer
+	visible bool
+	result  *netmodels.Consolidation
+	err     error
+}
+
+func (c *Consolidate) Visible() bool {
+	return c.visible
+}
+
+func (c *Consolidate) Show() {
+	c.result = nil
+	c.err = nil
+	c.visible = true
+}
+
+func (c *Consolidate) Hide() {
+	c.visible = false
+	c.result = nil
+	c.err = nil
+}
+
+// Done returns true once the consolidation is published.
+func (c *Consolidate) Done() bool {
+	return c.result != nil
+}
+
+// Value returns the fee rate of the input in sat/vbyte.
+func (c *Consolidate) Value() (uint64, error) {
+	return parseFeeRate(c.input.Value())
+}
+
+// Pasting returns true while a fee rate is pasted in the input.
+func (c *Consolidate) Pasting() bool {
+	return c.input.Pasting()
+}
+
+func (c *Consolidate) SetResult(result *netmodels.Consolidation, err error) {
+	c.result = result
+	c.err = err
+}
+
+func (c *Consolidate) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+10 > maxY {
+		y0 = 0
+	}
+
+	err := c.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(CONSOLIDATE, x0, y0+3, x0+width, y0+11, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " consolidate "
+	c.display(v)
+	return nil
+}
+
+func (c *Consolidate) display(v *gocui.View) {
+	v.Clear()
+	cyan := color.Cyan()
+	field := func(name string, value interface{}) {
+		fmt.Fprintf(v, "%s %v\n", cyan(fmt.Sprintf("%-8s", name)), value)
+	}
+
+	if c.result != nil {
+		field("txid", c.result.TxID)
+		field("address", c.result.Address)
+		field("inputs", c.result.Inputs)
+		field("amount", c.printer.Sprintf("%d sat", c.result.Amount))
+		field("fee", c.printer.Sprintf("%d sat", c.result.Fee))
+		fmt.Fprintln(v, color.Green()("published, enter or esc to close"))
+		return
+	}
+
+	utxos := c.utxos.Selected()
+	var total int64
+	for _, u := range utxos {
+		total += u.Amount
+	}
+	field("inputs", c.printer.Sprintf("%d outputs, %d sat", len(utxos), total))
+	field("size", fmt.Sprintf("%d vbytes", netmodels.ConsolidationVSize(utxos)))
+
+	err := c.err
+	rate, rateErr := c.Value()
+	if rateErr == nil {
+		fee, amount, feeErr := netmodels.ConsolidationFee(utxos, rate)
+		field("fee", c.printer.Sprintf("%d sat", fee))
+		field("output", c.printer.Sprintf("%d sat", amount))
+		if err == nil {
+			err = feeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(v, color.Red()(err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "type the fee rate and press enter to publish, esc to close")
+}
+
+func (c *Consolidate) Delete(g *gocui.Gui) error {
+	err := c.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(CONSOLIDATE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewConsolidate(utxos *models.UTXOs) *Consolidate {
+	return &Consolidate{
+		input:   NewInput(CONSOLIDATE_INPUT, " fee rate (sat/vbyte) ", validateFeeRate),
+		utxos:   utxos,
+		printer: message.NewPrinter(language.English),
+	}
+}
@@ -0,0 +1,116 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	CREATE_INVOICE        = "create_invoice"
+	CREATE_INVOICE_AMOUNT = "create_invoice_amount"
+	CREATE_INVOICE_MEMO   = "create_invoice_memo"
+)
+
+// CreateInvoice is the dialog creating an invoice, its QR code is
+// displayed once it is created.
+type CreateInvoice struct {
+	form
+	visible bool
+	err     error
+}
+
+func (c *CreateInvoice) Visible() bool {
+	return c.visible
+}
+
+func (c *CreateInvoice) Show() {
+	c.reset("", "")
+	c.err = nil
+	c.visible = true
+}
+
+func (c *CreateInvoice) Hide() {
+	c.visible = false
+	c.err = nil
+}
+
+// Value returns the amount in sat and the memo of the invoice, the amount
+// is zero if the payer chooses it.
+func (c *CreateInvoice) Value() (int64, string, error) {
+	amount := int64(0)
+	if s := c.inputs[0].Value(); s != "" {
+		var err error
+		amount, err = parseAmount(s)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+	return amount, c.inputs[1].Value(), nil
+}
+
+// SetError sets the error of the fields or of the creation, the dialog
+// stays open.
+func (c *CreateInvoice) SetError(err error) {
+	c.err = err
+}
+
+func (c *CreateInvoice) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(c.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := c.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(CREATE_INVOICE, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " create invoice "
+	c.display(v)
+	return nil
+}
+
+func (c *CreateInvoice) display(v *gocui.View) {
+	v.Clear()
+	if c.err != nil {
+		fmt.Fprintln(v, color.Red()(c.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "the payer chooses the amount if it is empty")
+	fmt.Fprintln(v, "tab moves to the next field, enter creates the invoice, esc to close")
+}
+
+func (c *CreateInvoice) Delete(g *gocui.Gui) error {
+	err := c.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(CREATE_INVOICE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewCreateInvoice() *CreateInvoice {
+	return &CreateInvoice{form: form{inputs: []*Input{
+		NewInput(CREATE_INVOICE_AMOUNT, " amount (sat) ", validateAmount),
+		NewInput(CREATE_INVOICE_MEMO, " memo ", nil),
+	}}}
+}
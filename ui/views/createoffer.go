@@ -0,0 +1,116 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	CREATE_OFFER             = "create_offer"
+	CREATE_OFFER_AMOUNT      = "create_offer_amount"
+	CREATE_OFFER_DESCRIPTION = "create_offer_description"
+)
+
+// CreateOffer is the dialog creating a BOLT12 offer, its QR code is
+// displayed once it is created.
+type CreateOffer struct {
+	form
+	visible bool
+	err     error
+}
+
+func (c *CreateOffer) Visible() bool {
+	return c.visible
+}
+
+func (c *CreateOffer) Show() {
+	c.reset("", "")
+	c.err = nil
+	c.visible = true
+}
+
+func (c *CreateOffer) Hide() {
+	c.visible = false
+	c.err = nil
+}
+
+// Value returns the amount in sat and the description of the offer, the
+// amount is zero if the payer chooses it.
+func (c *CreateOffer) Value() (int64, string, error) {
+	amount := int64(0)
+	if s := c.inputs[0].Value(); s != "" {
+		var err error
+		amount, err = parseAmount(s)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+	return amount, c.inputs[1].Value(), nil
+}
+
+// SetError sets the error of the fields or of the creation, the dialog
+// stays open.
+func (c *CreateOffer) SetError(err error) {
+	c.err = err
+}
+
+func (c *CreateOffer) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(c.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := c.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(CREATE_OFFER, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " create offer "
+	c.display(v)
+	return nil
+}
+
+func (c *CreateOffer) display(v *gocui.View) {
+	v.Clear()
+	if c.err != nil {
+		fmt.Fprintln(v, color.Red()(c.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "the offer is paid any number of times, the payer chooses the amount if it is empty")
+	fmt.Fprintln(v, "tab moves to the next field, enter creates the offer, esc to close")
+}
+
+func (c *CreateOffer) Delete(g *gocui.Gui) error {
+	err := c.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(CREATE_OFFER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewCreateOffer() *CreateOffer {
+	return &CreateOffer{form: form{inputs: []*Input{
+		NewInput(CREATE_OFFER_AMOUNT, " amount (sat) ", validateAmount),
+		NewTextInput(CREATE_OFFER_DESCRIPTION, " description "),
+	}}}
+}
@@ -0,0 +1,270 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/alerts"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	DASHBOARD        = "dashboard"
+	DASHBOARD_FOOTER = "dashboard_footer"
+
+	// dashboardEvents is the number of notifications displayed.
+	dashboardEvents = 5
+)
+
+// Dashboard is the overview of the node: its info, its liquidity, its
+// channels by state, the HTLCs in flight, the on-chain fee rates and the
+// last notifications.
+type Dashboard struct {
+	view            *gocui.View
+	info            *models.Info
+	channelsBalance *models.ChannelsBalance
+	walletBalance   *models.WalletBalance
+	channels        *models.Channels
+	feeRates        *models.FeeRates
+	notifications   *models.Notifications
+	alerts          *models.Alerts
+}
+
+func (d Dashboard) Name() string {
+	return DASHBOARD
+}
+
+func (d *Dashboard) Wrap(v *gocui.View) View {
+	d.view = v
+	return d
+}
+
+func (d Dashboard) Origin() (int, int) {
+	return d.view.Origin()
+}
+
+func (d Dashboard) Cursor() (int, int) {
+	return d.view.Cursor()
+}
+
+func (d Dashboard) Speed() (int, int, int, int) {
+	return 0, 0, 1, 1
+}
+
+func (d Dashboard) Limits() (pageSize int, fullSize int) {
+	_, pageSize = d.view.Size()
+	fullSize = len(d.view.BufferLines()) - 1
+	return
+}
+
+func (d *Dashboard) SetCursor(x, y int) error {
+	return d.view.SetCursor(x, y)
+}
+
+func (d *Dashboard) SetOrigin(x, y int) error {
+	return d.view.SetOrigin(x, y)
+}
+
+func (d Dashboard) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(DASHBOARD)
+	if err != nil {
+		return err
+	}
+	return g.DeleteView(DASHBOARD_FOOTER)
+}
+
+func (d *Dashboard) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	d.view, err = g.SetView(DASHBOARD, x0-1, y0, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	d.view.Frame = false
+	d.display()
+
+	footer, err := g.SetView(DASHBOARD_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+func (d *Dashboard) display() {
+	v := d.view
+	ox, oy := v.Origin()
+	v.Clear()
+	v.SetOrigin(ox, oy)
+
+	p := message.NewPrinter(language.English)
+	green := color.Green()
+	yellow := color.Yellow()
+	cyan := color.Cyan()
+	red := color.Red()
+
+	fmt.Fprintln(v, green(" [ Node ]"))
+	synced := green("synced")
+	if !d.info.Synced {
+		synced = red("not synced")
+	}
+	implementation := d.info.Implementation
+	if implementation == "" {
+		implementation = "lnd"
+	}
+	fmt.Fprintf(v, " %s %s %s-%s %s, %s at %d, %d peers\n",
+		cyan("node     :"), d.info.Alias, implementation, d.info.Version,
+		d.info.Network, synced, d.info.BlockHeight, d.info.NumPeers)
+	fmt.Fprintf(v, " %s %s\n", cyan("pubkey   :"), d.info.PubKey)
+
+	channels := d.channels.List()
+	var local, remote int64
+	var active, inactive, opening, closing int
+	var htlcs, incoming int
+	var inFlight int64
+	expiry := uint32(0)
+	for _, ch := range channels {
+		switch ch.Status {
+		case netmodels.ChannelActive:
+			active++
+			local += ch.LocalBalance
+			remote += ch.RemoteBalance
+		case netmodels.ChannelInactive:
+			inactive++
+			local += ch.LocalBalance
+			remote += ch.RemoteBalance
+		case netmodels.ChannelOpening:
+			opening++
+		case netmodels.ChannelClosing, netmodels.ChannelForceClosing, netmodels.ChannelWaitingClose:
+			closing++
+		}
+		for _, htlc := range ch.PendingHTLC {
+			htlcs++
+			if htlc.Incoming {
+				incoming++
+			}
+			inFlight += htlc.Amount
+			if expiry == 0 || htlc.ExpirationHeight < expiry {
+				expiry = htlc.ExpirationHeight
+			}
+		}
+	}
+
+	fmt.Fprintln(v, green(" [ Liquidity ]"))
+	fmt.Fprintf(v, " %s %s %s\n", cyan("outbound :"),
+		dashboardGauge(local, local+remote), formatAmount(local))
+	fmt.Fprintf(v, " %s %s %s\n", cyan("inbound  :"),
+		dashboardGauge(remote, local+remote), formatAmount(remote))
+	fmt.Fprintln(v, p.Sprintf(" %s %s (%s|%s)", cyan("on-chain :"),
+		formatAmount(d.walletBalance.TotalBalance),
+		green(formatAmount(d.walletBalance.ConfirmedBalance)),
+		yellow(formatAmount(d.walletBalance.UnconfirmedBalance))))
+	fmt.Fprintf(v, " %s %s\n", cyan("total    :"), formatAmount(
+		d.channelsBalance.Balance+d.channelsBalance.PendingOpenBalance+d.walletBalance.TotalBalance))
+
+	fmt.Fprintln(v, green(" [ Channels ]"))
+	fmt.Fprintf(v, " %d %s %d %s %d %s %d %s\n",
+		active, green("active"), inactive, red("inactive"),
+		opening, yellow("opening"), closing, yellow("closing"))
+
+	fmt.Fprintln(v, green(" [ HTLCs ]"))
+	if htlcs == 0 {
+		fmt.Fprintln(v, " none in flight")
+	} else {
+		line := p.Sprintf(" %d in flight (%d incoming, %d outgoing), %s sat",
+			htlcs, incoming, htlcs-incoming, formatAmount(inFlight))
+		if expiry > d.info.BlockHeight {
+			line += p.Sprintf(", first expiry in %d blocks", expiry-d.info.BlockHeight)
+		}
+		fmt.Fprintln(v, line)
+	}
+
+	fmt.Fprintln(v, green(" [ Fees ]"))
+	rates, updated := d.feeRates.Get()
+	if rates == nil {
+		fmt.Fprintln(v, " not estimated")
+	} else {
+		fmt.Fprintf(v, " %s %d %s %d %s %d %s %d sat/vB, %s at %s\n",
+			cyan("next blocks:"), rates.Fastest, cyan("30 min:"), rates.HalfHour,
+			cyan("1 hour:"), rates.Hour, cyan("1 day:"), rates.Economy,
+			rates.Source, updated.Format("15:04"))
+	}
+
+	fmt.Fprintln(v, green(" [ Events ]"))
+	shown := 0
+	for _, alert := range d.alerts.List() {
+		if alert.Resolved {
+			continue
+		}
+		level := yellow
+		if alert.Level == alerts.Critical {
+			level = red
+		}
+		fmt.Fprintf(v, " %s %s\n", cyan(alert.Since.Format("15:04:05 Jan _2")),
+			level(alert.Message))
+		shown++
+	}
+	list := d.notifications.List()
+	for i := len(list) - 1; i >= 0 && i >= len(list)-dashboardEvents; i-- {
+		fmt.Fprintf(v, " %s %s\n",
+			cyan(list[i].Time.Format("15:04:05 Jan _2")),
+			notificationColor(list[i])(list[i].Message))
+		shown++
+	}
+	if shown == 0 {
+		fmt.Fprintln(v, " none")
+	}
+}
+
+// dashboardGauge returns a bar of the part of the total and its percent.
+func dashboardGauge(part, total int64) string {
+	if total == 0 {
+		return fmt.Sprintf("[%20s]   0%%", "")
+	}
+	index := int(part * 20 / total)
+	var buffer bytes.Buffer
+	cyan := color.Cyan()
+	for i := 0; i < 20; i++ {
+		if i < index {
+			buffer.WriteString(cyan("|"))
+			continue
+		}
+		buffer.WriteString(" ")
+	}
+	return fmt.Sprintf("[%s] %3d%%", buffer.String(), part*100/total)
+}
+
+func NewDashboard(info *models.Info,
+	channelsBalance *models.ChannelsBalance,
+	walletBalance *models.WalletBalance,
+	channels *models.Channels,
+	feeRates *models.FeeRates,
+	notifications *models.Notifications,
+	alerts *models.Alerts) *Dashboard {
+	return &Dashboard{
+		info:            info,
+		channelsBalance: channelsBalance,
+		walletBalance:   walletBalance,
+		channels:        channels,
+		feeRates:        feeRates,
+		notifications:   notifications,
+		alerts:          alerts,
+	}
+}
@@ -0,0 +1,210 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	DECODER       = "decoder"
+	DECODER_INPUT = "decoder_input"
+)
+
+// Decoder is the popup decoding a BOLT11 payment request pasted in its
+// input, without paying it.
+type Decoder struct {
+	input   *Input
+	visible bool
+	payreq  *netmodels.PayReq
+	alias   string
+	err     error
+}
+
+func (d *Decoder) Visible() bool {
+	return d.visible
+}
+
+func (d *Decoder) Show() {
+	d.visible = true
+}
+
+func (d *Decoder) Hide() {
+	d.visible = false
+	d.payreq = nil
+	d.err = nil
+}
+
+// Value returns the payment request of the input.
+func (d *Decoder) Value() string {
+	return d.input.Value()
+}
+
+// Pasting returns true while a payment request is pasted in the input.
+func (d *Decoder) Pasting() bool {
+	return d.input.Pasting()
+}
+
+// SetResult sets the payment request decoded from the input, with the
+// alias of its destination.
+func (d *Decoder) SetResult(payreq *netmodels.PayReq, alias string, err error) {
+	d.payreq = payreq
+	d.alias = alias
+	d.err = err
+}
+
+func (d *Decoder) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 110
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+6 > maxY {
+		y0 = 0
+	}
+
+	err := d.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(DECODER, x0, y0+3, x0+width, maxY-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " decoded "
+	d.display(v)
+	return nil
+}
+
+func (d *Decoder) display(v *gocui.View) {
+	v.Clear()
+	if d.err != nil {
+		fmt.Fprintln(v, color.Red()(d.err.Error()))
+		return
+	}
+	if d.payreq == nil {
+		fmt.Fprintln(v, "paste or type a BOLT11 invoice and press enter, esc to close")
+		return
+	}
+
+	p := d.payreq
+	cyan := color.Cyan()
+	field := func(name string, value interface{}) {
+		fmt.Fprintf(v, "%s %v\n", cyan(fmt.Sprintf("%-16s", name)), value)
+	}
+
+	if p.AmountMsat == 0 && p.Amount == 0 {
+		field("amount", "any")
+	} else {
+		field("amount", fmt.Sprintf("%d sat (%d msat)", p.Amount, p.AmountMsat))
+	}
+	destination := p.Destination
+	if d.alias != "" {
+		destination = fmt.Sprintf("%s %s", color.Green()(d.alias), p.Destination)
+	}
+	field("destination", destination)
+	if p.Description != "" {
+		field("description", p.Description)
+	}
+	if p.DescriptionHash != "" {
+		field("description hash", p.DescriptionHash)
+	}
+	field("payment hash", p.PaymentHash)
+
+	created := time.Unix(p.Timestamp, 0)
+	expires := created.Add(time.Duration(p.Expiry) * time.Second)
+	field("created", created.Format("2006-01-02 15:04:05"))
+	expiry := expires.Format("2006-01-02 15:04:05")
+	if time.Now().After(expires) {
+		expiry = color.Red()(expiry + " (expired)")
+	}
+	field("expires", expiry)
+	field("cltv expiry", p.CltvExpiry)
+	if p.FallbackAddr != "" {
+		field("fallback", p.FallbackAddr)
+	}
+
+	for i, hint := range p.RouteHints {
+		for j, hop := range hint.Hops {
+			name := ""
+			if j == 0 {
+				name = fmt.Sprintf("route hint %d", i+1)
+			}
+			field(name, fmt.Sprintf("%s %s base %d msat %d ppm cltv %d",
+				hop.NodeID, netmodels.ToScid(hop.ChanID),
+				hop.FeeBaseMsat, hop.FeeProportionalMillionths, hop.CltvExpiryDelta))
+		}
+	}
+
+	for i, f := range p.Features {
+		name := ""
+		if i == 0 {
+			name = "features"
+		}
+		kind := "optional"
+		if f.Required {
+			kind = "required"
+		}
+		feature := fmt.Sprintf("%d %s %s", f.Bit, f.Name, kind)
+		if !f.Known {
+			feature = color.Yellow()(feature + " unknown")
+		}
+		field(name, feature)
+	}
+}
+
+func (d *Decoder) Delete(g *gocui.Gui) error {
+	err := d.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(DECODER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+// validatePayReq checks the prefix, the characters and the checksum of a
+// BOLT11 payment request, an error until it is complete.
+func validatePayReq(s string) error {
+	s = strings.ToLower(s)
+	s = strings.TrimPrefix(s, "lightning:")
+	if !strings.HasPrefix(s, "ln") {
+		if strings.HasPrefix("ln", s) {
+			return errors.New("incomplete invoice")
+		}
+		return errors.New("not a lightning invoice")
+	}
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 0 {
+		return errors.New("incomplete invoice")
+	}
+	for _, c := range s[sep+1:] {
+		if !strings.ContainsRune("qpzry9x8gf2tvdw0s3jn54khce6mua7l", c) {
+			return errors.Errorf("invalid character %q", c)
+		}
+	}
+	_, _, err := bech32.DecodeNoLimit(s)
+	if err != nil {
+		return errors.New("incomplete invoice or invalid checksum")
+	}
+	return nil
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{input: NewInput(DECODER_INPUT, " BOLT11 invoice ", validatePayReq)}
+}
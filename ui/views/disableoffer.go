@@ -0,0 +1,96 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	DISABLE_OFFER = "disable_offer"
+)
+
+// DisableOffer is the popup confirming the disabling of the offer selected
+// in the offers view.
+type DisableOffer struct {
+	offer *netmodels.Offer
+	err   error
+}
+
+func (r *DisableOffer) Visible() bool {
+	return r.offer != nil
+}
+
+func (r *DisableOffer) Show(offer *netmodels.Offer) {
+	r.offer = offer
+	r.err = nil
+}
+
+func (r *DisableOffer) Hide() {
+	r.offer = nil
+	r.err = nil
+}
+
+// Offer returns the offer to disable.
+func (r *DisableOffer) Offer() *netmodels.Offer {
+	return r.offer
+}
+
+// SetError sets the error of the disabling, the popup stays open.
+func (r *DisableOffer) SetError(err error) {
+	r.err = err
+}
+
+func (r *DisableOffer) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+6 > maxY {
+		y0 = 0
+	}
+
+	v, err := g.SetView(DISABLE_OFFER, x0, y0, x0+width, y0+6, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " disable offer "
+	r.display(v)
+
+	_, err = g.SetCurrentView(DISABLE_OFFER)
+	return err
+}
+
+func (r *DisableOffer) display(v *gocui.View) {
+	v.Clear()
+	cyan := color.Cyan()
+	fmt.Fprintf(v, "%s %s\n", cyan("offer      "), r.offer.ID)
+	fmt.Fprintf(v, "%s %s\n", cyan("description"), r.offer.Description)
+	if r.err != nil {
+		fmt.Fprintln(v, color.Red()(r.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "its invoice requests are no longer answered, it cannot be enabled again")
+	fmt.Fprintln(v, "press enter to disable it, esc to cancel")
+}
+
+func (r *DisableOffer) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(DISABLE_OFFER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewDisableOffer() *DisableOffer {
+	return &DisableOffer{}
+}
@@ -0,0 +1,100 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	DISCONNECT_PEER = "disconnect_peer"
+)
+
+// DisconnectPeer is the popup confirming the disconnection of the peer
+// selected in the peers view.
+type DisconnectPeer struct {
+	peer *netmodels.Peer
+	err  error
+}
+
+func (d *DisconnectPeer) Visible() bool {
+	return d.peer != nil
+}
+
+func (d *DisconnectPeer) Show(peer *netmodels.Peer) {
+	d.peer = peer
+	d.err = nil
+}
+
+func (d *DisconnectPeer) Hide() {
+	d.peer = nil
+	d.err = nil
+}
+
+// Peer returns the peer to disconnect.
+func (d *DisconnectPeer) Peer() *netmodels.Peer {
+	return d.peer
+}
+
+// SetError sets the error of the disconnection, the popup stays open.
+func (d *DisconnectPeer) SetError(err error) {
+	d.err = err
+}
+
+func (d *DisconnectPeer) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+6 > maxY {
+		y0 = 0
+	}
+
+	v, err := g.SetView(DISCONNECT_PEER, x0, y0, x0+width, y0+6, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " disconnect peer "
+	d.display(v)
+
+	_, err = g.SetCurrentView(DISCONNECT_PEER)
+	return err
+}
+
+func (d *DisconnectPeer) display(v *gocui.View) {
+	v.Clear()
+	cyan := color.Cyan()
+	alias := peerAlias(d.peer)
+	if alias == "" {
+		alias = d.peer.PubKey
+	}
+	fmt.Fprintf(v, "%s %s\n", cyan("peer   "), alias)
+	fmt.Fprintf(v, "%s %s\n", cyan("address"), d.peer.Address)
+	if d.err != nil {
+		fmt.Fprintln(v, color.Red()(d.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "its channels are inactive until it reconnects")
+	fmt.Fprintln(v, "press enter to disconnect it, esc to cancel")
+}
+
+func (d *DisconnectPeer) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(DISCONNECT_PEER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewDisconnectPeer() *DisconnectPeer {
+	return &DisconnectPeer{}
+}
@@ -0,0 +1,248 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/stats"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	FAILURES        = "failures"
+	FAILURES_FOOTER = "failures_footer"
+
+	// failuresTop is the number of pairs and hotspots displayed.
+	failuresTop = 10
+)
+
+// sparks are the heights of the bars of the failure rates by hour and of
+// the balances of the channels.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Failures is the dashboard of the failures of the forwards of the
+// routing events: their reasons, the failing pairs of channels, the rate
+// by hour and the channels failing for lack of balance.
+type Failures struct {
+	view     *gocui.View
+	failures *models.RoutingFailures
+	channels *models.Channels
+}
+
+func (c Failures) Name() string {
+	return FAILURES
+}
+
+func (c *Failures) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Failures) Origin() (int, int) {
+	return c.view.Origin()
+}
+
+func (c Failures) Cursor() (int, int) {
+	return c.view.Cursor()
+}
+
+func (c Failures) Speed() (int, int, int, int) {
+	return 0, 0, 1, 1
+}
+
+func (c Failures) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = len(c.view.BufferLines()) - 1
+	return
+}
+
+func (c *Failures) SetCursor(x, y int) error {
+	return c.view.SetCursor(x, y)
+}
+
+func (c *Failures) SetOrigin(x, y int) error {
+	return c.view.SetOrigin(x, y)
+}
+
+func (c Failures) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(FAILURES)
+	if err != nil {
+		return err
+	}
+	return g.DeleteView(FAILURES_FOOTER)
+}
+
+func (c *Failures) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	c.view, err = g.SetView(FAILURES, x0-1, y0, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	c.view.Frame = false
+	c.display()
+
+	footer, err := g.SetView(FAILURES_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	forwards, failed := c.failures.Counts()
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %d forwards resolved since start, %d failed",
+		blackBg("F2"), "Menu",
+		blackBg("F10"), "Quit",
+		forwards, failed,
+	))
+	return nil
+}
+
+func (c *Failures) display() {
+	p := message.NewPrinter(language.English)
+	v := c.view
+	// the sections grow with the failures, the scroll is kept.
+	ox, oy := v.Origin()
+	v.Clear()
+	v.SetOrigin(ox, oy)
+	green := color.Green()
+	cyan := color.Cyan()
+
+	forwards, failed := c.failures.Counts()
+	fmt.Fprintln(v, green(" [ Forwards ]"))
+	fmt.Fprintf(v, "%s %d\n", cyan("       Resolved:"), forwards)
+	fmt.Fprintf(v, "%s %d %s\n", cyan("         Failed:"), failed, formatFailureRate(failed, forwards))
+
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Failure Reasons ]"))
+	for _, r := range c.failures.Reasons() {
+		fmt.Fprintf(v, " %s %6d %s\n",
+			cyan(runewidth.FillRight(runewidth.Truncate(r.Reason, 48, "…"), 48)),
+			r.Count, formatFailureRate(r.Count, failed))
+	}
+
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Top Failing Pairs ]"))
+	pairs := c.failures.TopPairs(failuresTop)
+	if len(pairs) > 0 {
+		fmt.Fprintf(v, " %s %s %6s %8s %7s  %s\n",
+			runewidth.FillRight("IN", 20), runewidth.FillRight("OUT", 20),
+			"FAILED", "FORWARDS", "RATE", "LAST REASON")
+	}
+	for _, pair := range pairs {
+		fmt.Fprintf(v, " %s %s %6d %8d %s  %s\n",
+			cyan(failureAlias(c.channels, pair.In)), cyan(failureAlias(c.channels, pair.Out)),
+			pair.Failed, pair.Forwards, formatFailureRate(pair.Failed, pair.Forwards), pair.Reason)
+	}
+
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(fmt.Sprintf(" [ Failure Rate of the last %d hours ]", stats.FailureHours)))
+	c.printRates(v, c.failures.Rates(time.Now()))
+
+	fmt.Fprintln(v)
+	fmt.Fprintln(v, green(" [ Insufficient Balance Hotspots ]"))
+	hotspots := c.failures.Hotspots(failuresTop)
+	if len(hotspots) > 0 {
+		fmt.Fprintf(v, " %s %8s %15s %15s  %s\n",
+			runewidth.FillRight("OUT", 20), "FAILURES", "FAILED AMOUNT", "LOCAL BALANCE", "LAST")
+	}
+	for _, h := range hotspots {
+		local := ""
+		if ch := failureChannel(c.channels, h.ChannelID); ch != nil {
+			local = formatAmount(ch.LocalBalance)
+		}
+		fmt.Fprintf(v, " %s %8d %s %15s  %s\n",
+			cyan(failureAlias(c.channels, h.ChannelID)), h.Failures,
+			color.Yellow()(p.Sprintf("%15d", h.AmountMsat/1000)), local,
+			h.Last.Format("15:04:05 Jan _2"))
+	}
+}
+
+// printRates prints a bar by hour whose height and color are the failure
+// rate, followed by the rates of the hours with failures, the last first.
+func (c *Failures) printRates(v *gocui.View, rates []stats.Rate) {
+	var bars strings.Builder
+	for _, r := range rates {
+		if r.Forwards == 0 {
+			bars.WriteString("  ")
+			continue
+		}
+		rate := float64(r.Failed) / float64(r.Forwards)
+		bar := string(sparks[int(rate*float64(len(sparks)-1))]) + " "
+		switch {
+		case rate >= 0.5:
+			bars.WriteString(color.Red()(bar))
+		case rate >= 0.2:
+			bars.WriteString(color.Yellow()(bar))
+		default:
+			bars.WriteString(color.Green()(bar))
+		}
+	}
+	fmt.Fprintf(v, " %s\n", bars.String())
+	fmt.Fprintf(v, " %s%s\n", rates[0].Time.Format("15:04"),
+		fmt.Sprintf("%*s", 2*len(rates)-5, rates[len(rates)-1].Time.Format("15:04")))
+	for i := len(rates) - 1; i >= 0; i-- {
+		if rates[i].Failed == 0 {
+			continue
+		}
+		fmt.Fprintf(v, " %s %6d/%-6d %s\n", color.Cyan()(rates[i].Time.Format("15:04")),
+			rates[i].Failed, rates[i].Forwards, formatFailureRate(rates[i].Failed, rates[i].Forwards))
+	}
+}
+
+// formatFailureRate returns the part of the total failed in percent,
+// colored from green to red.
+func formatFailureRate(n, total int) string {
+	if total == 0 {
+		return fmt.Sprintf("%7s", "")
+	}
+	rate := float64(n) / float64(total)
+	text := fmt.Sprintf("%6.1f%%", rate*100)
+	switch {
+	case rate >= 0.5:
+		return color.Red()(text)
+	case rate >= 0.2:
+		return color.Yellow()(text)
+	}
+	return color.Green()(text)
+}
+
+// failureChannel returns the channel of the id, nil if it is not a
+// channel of the node anymore.
+func failureChannel(channels *models.Channels, id uint64) *netmodels.Channel {
+	for _, ch := range channels.List() {
+		if ch.ID == id {
+			return ch
+		}
+	}
+	return nil
+}
+
+// failureAlias returns the alias of the peer of the channel, its short
+// channel id if it is unknown.
+func failureAlias(channels *models.Channels, id uint64) string {
+	name := netmodels.ToScid(id)
+	if ch := failureChannel(channels, id); ch != nil {
+		if alias, _ := ch.ShortAlias(); alias != "" {
+			name = alias
+		}
+	}
+	return runewidth.FillRight(runewidth.Truncate(name, 20, "…"), 20)
+}
+
+func NewFailures(failures *models.RoutingFailures, channels *models.Channels) *Failures {
+	return &Failures{failures: failures, channels: channels}
+}
@@ -0,0 +1,22 @@
+package views
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+var fiatPrinter = message.NewPrinter(language.English)
+
+// fiat returns the value of the amount in sat with the decimals, right
+// aligned in width, blank until the rate is known.
+func fiat(p *models.Price, sat float64, width, decimals int) string {
+	value, ok := p.Fiat(sat)
+	if !ok {
+		return fmt.Sprintf("%*s", width, "")
+	}
+	return fiatPrinter.Sprintf("%*.*f", width, decimals, value)
+}
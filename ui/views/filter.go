@@ -0,0 +1,63 @@
+package views
+
+import (
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const FILTER_INPUT = "filter_input"
+
+// Filter is the field of the expression filtering the channels view,
+// above its footer. The expression is checked as it is typed.
+type Filter struct {
+	input   *Input
+	visible bool
+}
+
+func (f *Filter) Visible() bool {
+	return f.visible
+}
+
+// Show opens the field with the expression.
+func (f *Filter) Show(expr string) {
+	f.input.SetValue(expr)
+	f.visible = true
+}
+
+func (f *Filter) Hide() {
+	f.visible = false
+}
+
+// Value returns the expression of the field.
+func (f *Filter) Value() string {
+	return f.input.Value()
+}
+
+// SetError sets the error of the expression, the field stays open.
+func (f *Filter) SetError(err error) {
+	f.input.SetError(err)
+}
+
+// Pasting returns true while an expression is pasted in the field.
+func (f *Filter) Pasting() bool {
+	return f.input.Pasting()
+}
+
+func (f *Filter) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	return f.input.Set(g, x0, y0, x1, y1)
+}
+
+func (f *Filter) Delete(g *gocui.Gui) error {
+	return f.input.Delete(g)
+}
+
+func NewFilter() *Filter {
+	input := NewInput(FILTER_INPUT, " filter, e.g. local < 1000000 && active && !private ",
+		func(src string) error {
+			_, err := models.ParseChannelsExpr(src)
+			return err
+		})
+	input.text = true
+	return &Filter{input: input}
+}
@@ -0,0 +1,496 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	FIREWALL         = "firewall"
+	FIREWALL_COLUMNS = "firewall_columns"
+	FIREWALL_FOOTER  = "firewall_footer"
+)
+
+var DefaultFirewallColumns = []string{
+	"TIME",
+	"IN",
+	"OUT",
+	"AMOUNT",
+	"FEE",
+	"PPM",
+	"EXPIRY",
+	"STATUS",
+	"REASON",
+}
+
+// Firewall is the view of the HTLCs intercepted by the firewall, the held
+// ones are resumed, failed or settled from it.
+type Firewall struct {
+	cfg     *config.View
+	printer *message.Printer
+
+	columns           []firewallColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	firewall          *models.Firewall
+	// rows is the number of HTLCs of the last display.
+	rows int
+
+	ox, oy int
+	cx, cy int
+}
+
+type firewallColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.FirewallSort
+	display func(*netmodels.InterceptedHTLC, ...color.Option) string
+}
+
+func (c Firewall) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Firewall) Name() string {
+	return FIREWALL
+}
+
+func (c *Firewall) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Firewall) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Firewall) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Firewall) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Firewall) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Firewall) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Firewall) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.firewall.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Firewall) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.firewall.Len()
+	return
+}
+
+func (c *Firewall) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.firewall.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Firewall) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(FIREWALL_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(FIREWALL)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(FIREWALL_FOOTER)
+}
+
+func (c *Firewall) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(FIREWALL_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(FIREWALL, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(FIREWALL_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	summary := c.printer.Sprintf("%d held", c.firewall.Held())
+	if !c.firewall.Enabled() {
+		summary = "the firewall is not enabled"
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("r"), "Resume",
+		blackBg("x"), "Fail",
+		blackBg("s"), "Settle",
+		blackBg("F10"), "Quit",
+		summary,
+	))
+	return nil
+}
+
+func (c *Firewall) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.firewall.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once the old HTLCs are dropped.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// htlcStatus returns the name of the resolution of the HTLC in its color.
+func htlcStatus(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+	text := fmt.Sprintf("%-7s", h.ActionName())
+	switch h.Action {
+	case netmodels.InterceptHold:
+		return color.Yellow(opts...)(text)
+	case netmodels.InterceptResume:
+		return color.Green(opts...)(text)
+	case netmodels.InterceptFail:
+		return color.Red(opts...)(text)
+	}
+	return color.Cyan(opts...)(text)
+}
+
+func NewFirewall(cfg *config.View, htlcs *models.Firewall) *Firewall {
+	printer := message.NewPrinter(language.English)
+	view := &Firewall{
+		cfg:      cfg,
+		printer:  printer,
+		firewall: htlcs,
+	}
+
+	columns := DefaultFirewallColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]firewallColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "TIME":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.DateSort(&h1.Intercepted, &h2.Intercepted, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(h.Intercepted.Format("15:04:05"))
+				},
+			}
+		case "IN":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-20s", columns[i]),
+				width: 20,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.StringSort(h1.IncomingPeer, h2.IncomingPeer, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(h.IncomingPeer, 20, "…"), 20))
+				},
+			}
+		case "OUT":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-20s", columns[i]),
+				width: 20,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.StringSort(h1.OutgoingPeer, h2.OutgoingPeer, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(h.OutgoingPeer, 20, "…"), 20))
+				},
+			}
+		case "IN_CHAN":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-19s", columns[i]),
+				width: 19,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt64Sort(h1.IncomingChannelId, h2.IncomingChannelId, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-19d", h.IncomingChannelId))
+				},
+			}
+		case "OUT_CHAN":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-19s", columns[i]),
+				width: 19,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt64Sort(h1.OutgoingChannelId, h2.OutgoingChannelId, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-19d", h.OutgoingChannelId))
+				},
+			}
+		case "AMOUNT":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt64Sort(h1.OutgoingAmountMsat, h2.OutgoingAmountMsat, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.Yellow(opts...)(printer.Sprintf("%12d", h.OutgoingAmountMsat/1000))
+				},
+			}
+		case "FEE":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt64Sort(h1.FeeMsat(), h2.FeeMsat(), order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%10d", h.FeeMsat()))
+				},
+			}
+		case "PPM":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%7s", columns[i]),
+				width: 7,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt64Sort(h1.FeePPM(), h2.FeePPM(), order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%7d", h.FeePPM()))
+				},
+			}
+		case "EXPIRY":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.UInt32Sort(h1.OutgoingExpiry, h2.OutgoingExpiry, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", h.OutgoingExpiry))
+				},
+			}
+		case "STATUS":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-7s", columns[i]),
+				width: 7,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.IntSort(h1.Action, h2.Action, order)
+					}
+				},
+				display: htlcStatus,
+			}
+		case "REASON":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.FirewallSort {
+					return func(h1, h2 *netmodels.InterceptedHTLC) bool {
+						return models.StringSort(h1.Reason, h2.Reason, order)
+					}
+				},
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(h.Reason, 30, "…"), 30))
+				},
+			}
+		case "HASH":
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-64s", columns[i]),
+				width: 64,
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-64x", h.PaymentHash))
+				},
+			}
+		default:
+			view.columns[i] = firewallColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(h *netmodels.InterceptedHTLC, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
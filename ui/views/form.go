@@ -0,0 +1,69 @@
+package views
+
+import (
+	"github.com/awesome-gocui/gocui"
+)
+
+// form is the stack of the fields of a dialog, tab moves to the next one.
+type form struct {
+	inputs []*Input
+	focus  int
+}
+
+// Names returns the names of the fields, in their order.
+func (f *form) Names() []string {
+	names := make([]string, len(f.inputs))
+	for i := range f.inputs {
+		names[i] = f.inputs[i].Name()
+	}
+	return names
+}
+
+// reset fills the fields with the values and focuses the first one.
+func (f *form) reset(values ...string) {
+	f.focus = 0
+	for i := range f.inputs {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		f.inputs[i].SetValue(value)
+	}
+}
+
+// Next moves the focus to the next field, back to the first after the
+// last one.
+func (f *form) Next(g *gocui.Gui) error {
+	f.focus = (f.focus + 1) % len(f.inputs)
+	_, err := g.SetCurrentView(f.inputs[f.focus].Name())
+	return err
+}
+
+// Pasting returns true while a value is pasted in the field focused.
+func (f *form) Pasting() bool {
+	return f.inputs[f.focus].Pasting()
+}
+
+// set lays out the fields from y0 and returns the line below the last.
+func (f *form) set(g *gocui.Gui, x0, y0, x1 int) (int, error) {
+	y := y0
+	for i := range f.inputs {
+		err := f.inputs[i].Set(g, x0, y, x1, y+2)
+		if err != nil {
+			return 0, err
+		}
+		y += 3
+	}
+	_, err := g.SetCurrentView(f.inputs[f.focus].Name())
+	return y, err
+}
+
+func (f *form) delete(g *gocui.Gui) error {
+	for i := range f.inputs {
+		err := f.inputs[i].Delete(g)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -41,6 +41,8 @@ type FwdingHist struct {
 
 	ox, oy int
 	cx, cy int
+	// rows is the number of forwards of the last display.
+	rows int
 }
 
 type fwdinghistColumn struct {
@@ -193,8 +195,8 @@ func (c *FwdingHist) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		setCursor = true
 	}
 	c.columnHeadersView.Frame = false
-	c.columnHeadersView.BgColor = gocui.ColorGreen
-	c.columnHeadersView.FgColor = gocui.ColorBlack
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
 
 	c.view, err = g.SetView(FWDINGHIST, x0-1, y0+1, x1+2, y1-1, 0)
 	if err != nil {
@@ -205,8 +207,8 @@ func (c *FwdingHist) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	}
 	c.view.Frame = false
 	c.view.Autoscroll = false
-	c.view.SelBgColor = gocui.ColorCyan
-	c.view.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
 	c.view.Highlight = true
 	c.display()
 
@@ -231,14 +233,16 @@ func (c *FwdingHist) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		}
 	}
 	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
-	footer.Rewind()
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
 	blackBg := color.Black(color.Background)
-	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %d forwards in %s",
 		blackBg("F2"), "Menu",
 		blackBg("Enter"), "FwdingHist",
+		blackBg("t"), "Range",
 		blackBg("F10"), "Quit",
+		c.fwdinghist.Len(), c.fwdinghist.RangeName(),
 	))
 	return nil
 }
@@ -262,8 +266,19 @@ func (c *FwdingHist) display() {
 	}
 	fmt.Fprintln(c.columnHeadersView, buffer.String())
 
-	c.view.Rewind()
-	for _, item := range c.fwdinghist.List() {
+	list := c.fwdinghist.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once the range is shorter.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
 		var buffer bytes.Buffer
 		for i := range c.columns {
 			var opt color.Option
@@ -277,7 +292,7 @@ func (c *FwdingHist) display() {
 	}
 }
 
-func NewFwdingHist(cfg *config.View, hist *models.FwdingHist) *FwdingHist {
+func NewFwdingHist(cfg *config.View, hist *models.FwdingHist, price *models.Price) *FwdingHist {
 	fwdinghist := &FwdingHist{
 		cfg:        cfg,
 		fwdinghist: hist,
@@ -385,6 +400,20 @@ func NewFwdingHist(cfg *config.View, hist *models.FwdingHist) *FwdingHist {
 					return fee(e.Fee)
 				},
 			}
+		case "FIAT_FEE":
+			fwdinghist.columns[i] = fwdinghistColumn{
+				name:  fmt.Sprintf("%11s", "FIAT_EARNED"),
+				width: 11,
+				sort: func(order models.Order) models.FwdinghistSort {
+					return func(e1, e2 *netmodels.ForwardingEvent) bool {
+						return models.UInt64Sort(e1.FeeMsat, e2.FeeMsat, order)
+					}
+				},
+				display: func(e *netmodels.ForwardingEvent, opts ...color.Option) string {
+					// the fee of a forward is often below a cent.
+					return color.Green(opts...)(fiat(price, float64(e.FeeMsat)/1000, 11, 4))
+				},
+			}
 		case "TIMESTAMP_NS":
 			fwdinghist.columns[i] = fwdinghistColumn{
 				name:  fmt.Sprintf("%15s", "TIME"),
@@ -0,0 +1,465 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	GRAPH         = "graph"
+	GRAPH_COLUMNS = "graph_columns"
+	GRAPH_FOOTER  = "graph_footer"
+	GRAPH_LOOKUP  = "graph_lookup"
+)
+
+var DefaultGraphColumns = []string{
+	"ALIAS",
+	"CHANNELS",
+	"CAPACITY",
+	"LAST_UPDATE",
+	"PUBKEY",
+}
+
+// Graph is the view of the nodes of the graph matching the lookup, the
+// largest first.
+type Graph struct {
+	cfg *config.View
+
+	columns           []graphColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	graph             *models.Graph
+	// rows is the number of nodes of the last display.
+	rows int
+	// err is the error of the loading of the graph.
+	err error
+	// message is the result of the last action, displayed in the footer
+	// for a few seconds.
+	message   string
+	messageAt time.Time
+
+	ox, oy int
+	cx, cy int
+}
+
+type graphColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.GraphSort
+	display func(*netmodels.Node, ...color.Option) string
+}
+
+func (c Graph) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Graph) Name() string {
+	return GRAPH
+}
+
+func (c *Graph) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Graph) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Graph) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Graph) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Graph) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Graph) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Graph) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.graph.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Graph) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.graph.Len()
+	return
+}
+
+func (c *Graph) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.graph.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+// SetError displays the error of the loading of the graph in the footer,
+// nil once it is loaded.
+func (c *Graph) SetError(err error) {
+	c.err = err
+}
+
+// SetMessage displays the result of an action in the footer for a few
+// seconds.
+func (c *Graph) SetMessage(msg string) {
+	c.message = msg
+	c.messageAt = time.Now()
+}
+
+func (c Graph) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(GRAPH_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(GRAPH)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(GRAPH_FOOTER)
+}
+
+func (c *Graph) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(GRAPH_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(GRAPH, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(GRAPH_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	summary := c.summary()
+	if c.message != "" && time.Since(c.messageAt) < 5*time.Second {
+		summary = c.message
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("/"), "Lookup",
+		blackBg("Enter"), "Node",
+		blackBg("O"), "Open",
+		blackBg("F10"), "Quit",
+		summary,
+	))
+	return nil
+}
+
+func (c *Graph) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.graph.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once the lookup matches fewer nodes.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func (c *Graph) summary() string {
+	if c.err != nil {
+		return "cannot load the graph: " + c.err.Error()
+	}
+	if c.graph.Loaded().IsZero() {
+		return "loading..."
+	}
+	summary := fmt.Sprintf("%d/%d nodes", c.graph.Len(), c.graph.Total())
+	if query := c.graph.Query(); query != "" {
+		summary += fmt.Sprintf(" matching %q", query)
+	}
+	return summary
+}
+
+// graphAlias returns the forced alias of the node or its alias.
+func graphAlias(n *netmodels.Node) string {
+	if n.ForcedAlias != "" {
+		return n.ForcedAlias
+	}
+	return n.Alias
+}
+
+func NewGraph(cfg *config.View, graph *models.Graph) *Graph {
+	printer := message.NewPrinter(language.English)
+	view := &Graph{
+		cfg:   cfg,
+		graph: graph,
+	}
+
+	columns := DefaultGraphColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]graphColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "ALIAS":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-25s", columns[i]),
+				width: 25,
+				sort: func(order models.Order) models.GraphSort {
+					return func(n1, n2 *netmodels.Node) bool {
+						return models.StringSort(graphAlias(n1), graphAlias(n2), order)
+					}
+				},
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					alias := runewidth.FillRight(runewidth.Truncate(graphAlias(n), 25, "…"), 25)
+					if n.ForcedAlias != "" {
+						return color.Cyan(opts...)(alias)
+					}
+					return color.White(opts...)(alias)
+				},
+			}
+		case "PUBKEY":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-66s", columns[i]),
+				width: 66,
+				sort: func(order models.Order) models.GraphSort {
+					return func(n1, n2 *netmodels.Node) bool {
+						return models.StringSort(n1.PubKey, n2.PubKey, order)
+					}
+				},
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-66s", n.PubKey))
+				},
+			}
+		case "CHANNELS":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.GraphSort {
+					return func(n1, n2 *netmodels.Node) bool {
+						return models.UInt32Sort(n1.NumChannels, n2.NumChannels, order)
+					}
+				},
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%8d", n.NumChannels))
+				},
+			}
+		case "CAPACITY":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.GraphSort {
+					return func(n1, n2 *netmodels.Node) bool {
+						return models.Int64Sort(n1.TotalCapacity, n2.TotalCapacity, order)
+					}
+				},
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					return color.Yellow(opts...)(fmt.Sprintf("%15s", formatAmount(n.TotalCapacity)))
+				},
+			}
+		case "LAST_UPDATE":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.GraphSort {
+					return func(n1, n2 *netmodels.Node) bool {
+						return models.DateSort(&n1.LastUpdate, &n2.LastUpdate, order)
+					}
+				},
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					return color.White(opts...)(formatAttempt(n.LastUpdate))
+				},
+			}
+		case "ADDRESS":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					addr := ""
+					if len(n.Addresses) > 0 {
+						addr = n.Addresses[0].Addr
+					}
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(addr, 30, "…"), 30))
+				},
+			}
+		case "FEATURES":
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-40s", columns[i]),
+				width: 40,
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					bits := make([]string, len(n.Features))
+					for j, f := range n.Features {
+						bits[j] = fmt.Sprint(f.Bit)
+					}
+					features := strings.Join(bits, ",")
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(features, 40, "…"), 40))
+				},
+			}
+		default:
+			view.columns[i] = graphColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(n *netmodels.Node, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
@@ -3,8 +3,11 @@ package views
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/awesome-gocui/gocui"
+	"github.com/edouardparis/lntop/alerts"
+	netmodels "github.com/edouardparis/lntop/network/models"
 	"github.com/edouardparis/lntop/ui/color"
 	"github.com/edouardparis/lntop/ui/models"
 )
@@ -16,7 +19,19 @@ const (
 var versionReg = regexp.MustCompile(`(\d+\.)?(\d+\.)?(\*|\d+)`)
 
 type Header struct {
-	Info *models.Info
+	Info   *models.Info
+	Alerts *models.Alerts
+	Price  *models.Price
+	// FeeRates are the fee rates of the next blocks, half an hour, an
+	// hour and a day.
+	FeeRates *models.FeeRates
+	// Connection is the state of the connection to the node.
+	Connection *models.Connection
+	// Node is the name of the node displayed, empty if it is the only
+	// one.
+	Node string
+	// ReadOnly is true if the actions changing the node are disabled.
+	ReadOnly bool
 }
 
 func (h *Header) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
@@ -33,15 +48,26 @@ func (h *Header) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	if len(matches) > 0 {
 		version = matches[0]
 	}
+	if version != "" {
+		version = "-v" + version
+	}
+
+	implementation := h.Info.Implementation
+	if implementation == "" {
+		implementation = "lnd"
+	}
 
 	chain := ""
 	if len(h.Info.Chains) > 0 {
 		chain = h.Info.Chains[0]
 	}
 
-	network := "testnet"
-	if !h.Info.Testnet {
-		network = "mainnet"
+	// the coins of the other networks have no value, it must not be
+	// missed.
+	// a custodial account has no network.
+	network := h.Info.Network
+	if network != "mainnet" && network != "" {
+		network = color.Red(color.Background)(fmt.Sprintf(" %s ", strings.ToUpper(network)))
 	}
 
 	sync := color.Yellow()("[syncing]")
@@ -49,19 +75,62 @@ func (h *Header) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		sync = color.Green()("[synced]")
 	}
 
-	v.Clear()
+	connection := color.Green()("[CONNECTED]")
+	switch c := h.Connection.Get(); c.State {
+	case netmodels.ConnectionReconnecting:
+		connection = color.Yellow(color.Bold)(fmt.Sprintf("[RECONNECTING #%d]", c.Attempts))
+	case netmodels.ConnectionDown:
+		connection = color.Red(color.Background, color.Bold)(" DOWN ")
+	}
+	if h.ReadOnly {
+		connection += " " + color.Yellow(color.Background)(" READ-ONLY ")
+	}
+
+	status := ""
+	if list := h.Alerts.List(); len(list) > 0 {
+		status = color.Red(color.Bold)(fmt.Sprintf("[%d alerts]", len(list)))
+		// the wallet balance stays in the header until it is resolved,
+		// otherwise the last raised alert is displayed next to the count.
+		if wallet := h.Alerts.Rule(alerts.WalletBalanceName); len(wallet) > 0 {
+			status = fmt.Sprintf("%s %s", status, color.Yellow(color.Bold)(wallet[0].Message))
+		} else {
+			status = fmt.Sprintf("%s %s", status, color.Red()(list[len(list)-1].Message))
+		}
+	}
+
 	cyan := color.Cyan()
-	fmt.Fprintln(v, fmt.Sprintf("%s %s %s %s %s %s",
+	rate := ""
+	if r, _ := h.Price.Rate(); r > 0 {
+		rate = fiatPrinter.Sprintf("%s %.0f", color.Cyan()(fmt.Sprintf("btc/%s:", strings.ToLower(h.Price.Currency()))), r)
+	}
+
+	fees := ""
+	if r, _ := h.FeeRates.Get(); r != nil {
+		fees = fmt.Sprintf("%s %d|%d|%d|%d", cyan("sat/vB:"), r.Fastest, r.HalfHour, r.Hour, r.Economy)
+	}
+
+	node := ""
+	if h.Node != "" {
+		node = color.Magenta(color.Background)(fmt.Sprintf(" %s ", h.Node)) + " "
+	}
+
+	v.Clear()
+	fmt.Fprintln(v, fmt.Sprintf("%s%s %s %s %s %s %s %s %s %s %s",
+		node,
 		color.Cyan(color.Background)(h.Info.Alias),
-		cyan(fmt.Sprintf("%s-v%s", "lnd", version)),
+		cyan(implementation+version),
 		fmt.Sprintf("%s %s", chain, network),
 		sync,
+		connection,
 		fmt.Sprintf("%s %d", cyan("height:"), h.Info.BlockHeight),
 		fmt.Sprintf("%s %d", cyan("peers:"), h.Info.NumPeers),
+		rate,
+		fees,
+		status,
 	))
 	return nil
 }
 
-func NewHeader(info *models.Info) *Header {
-	return &Header{Info: info}
+func NewHeader(info *models.Info, alerts *models.Alerts, price *models.Price, feeRates *models.FeeRates, connection *models.Connection) *Header {
+	return &Header{Info: info, Alerts: alerts, Price: price, FeeRates: feeRates, Connection: connection}
 }
@@ -0,0 +1,70 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/expr"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+type highlight struct {
+	expr   *expr.Expr
+	column string
+	style  func(...interface{}) string
+}
+
+// newHighlights parses the highlights of the config, the invalid ones
+// are refused when the config loads.
+func newHighlights(cfg *config.View) []highlight {
+	if cfg == nil {
+		return nil
+	}
+	highlights := []highlight{}
+	for _, h := range cfg.Highlights {
+		e, err := expr.Parse(h.Expr)
+		if err != nil {
+			continue
+		}
+		var opt color.Option
+		if h.Background {
+			opt = color.Background
+		}
+		style, ok := color.ByName(strings.ToLower(h.Color), opt)
+		if !ok {
+			continue
+		}
+		highlights = append(highlights, highlight{
+			expr:   e,
+			column: strings.ToUpper(h.Column),
+			style:  style,
+		})
+	}
+	return highlights
+}
+
+// matchHighlight returns the first highlight true for env.
+func matchHighlight(highlights []highlight, env func() expr.Env) *highlight {
+	if len(highlights) == 0 {
+		return nil
+	}
+	e := env()
+	for i := range highlights {
+		ok, err := highlights[i].expr.Bool(e)
+		if err == nil && ok {
+			return &highlights[i]
+		}
+	}
+	return nil
+}
+
+// apply colors the cell of the column with the given name.
+func (h *highlight) apply(column, text string) string {
+	if h == nil {
+		return text
+	}
+	if h.column != "" && h.column != strings.TrimSpace(column) {
+		return text
+	}
+	return h.style(color.Strip(text))
+}
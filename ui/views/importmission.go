@@ -0,0 +1,134 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	IMPORT_MISSION       = "import_mission"
+	IMPORT_MISSION_PATH  = "import_mission_path"
+	IMPORT_MISSION_FORCE = "import_mission_force"
+)
+
+// ImportMission is the dialog importing in mission control the pairs of
+// a file written by an export or by lncli querymc.
+type ImportMission struct {
+	form
+	visible bool
+	// importing is true while the pairs are imported.
+	importing bool
+	err       error
+}
+
+func (i *ImportMission) Visible() bool {
+	return i.visible
+}
+
+// Show opens the dialog with the path of the file, e.g. the last export.
+func (i *ImportMission) Show(path string) {
+	i.reset(path, "no")
+	i.importing = false
+	i.err = nil
+	i.visible = true
+}
+
+func (i *ImportMission) Hide() {
+	i.visible = false
+	i.importing = false
+	i.err = nil
+}
+
+// Start marks the pairs as imported until the result is set.
+func (i *ImportMission) Start() {
+	i.importing = true
+	i.err = nil
+}
+
+// Importing returns true while the pairs are imported.
+func (i *ImportMission) Importing() bool {
+	return i.importing
+}
+
+// Value returns the path of the file and whether the pairs replace the
+// more recent results.
+func (i *ImportMission) Value() (string, bool, error) {
+	path := i.inputs[0].Value()
+	if path == "" {
+		return "", false, errors.New("the path of the file is empty")
+	}
+	force, err := parseYesNo(i.inputs[1].Value())
+	if err != nil {
+		return "", false, errors.Errorf("force: %s", err)
+	}
+	return path, force, nil
+}
+
+// SetError sets the error of the fields or of the import, the dialog
+// stays open.
+func (i *ImportMission) SetError(err error) {
+	i.importing = false
+	i.err = err
+}
+
+func (i *ImportMission) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(i.inputs)+4 > maxY {
+		y0 = 0
+	}
+
+	y, err := i.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(IMPORT_MISSION, x0, y, x0+width, y+4, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " import mission control "
+	v.Clear()
+	if i.err != nil {
+		fmt.Fprintln(v, color.Red()(i.err.Error()))
+		return nil
+	}
+	if i.importing {
+		fmt.Fprintln(v, color.Yellow()("importing..."))
+		return nil
+	}
+	fmt.Fprintln(v, "the pairs of the file, JSON of lncli querymc, are added to mission control,")
+	fmt.Fprintln(v, "forced they replace more recent results. tab, enter imports, esc to close")
+	return nil
+}
+
+func (i *ImportMission) Delete(g *gocui.Gui) error {
+	err := i.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(IMPORT_MISSION)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewImportMission() *ImportMission {
+	return &ImportMission{form: form{inputs: []*Input{
+		NewTextInput(IMPORT_MISSION_PATH, " file "),
+		NewInput(IMPORT_MISSION_FORCE, " force (yes/no) ", validateYesNo),
+	}}}
+}
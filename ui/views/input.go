@@ -0,0 +1,184 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+// pasteDelay is the delay under which keys are considered to come from
+// a paste rather than from typing: gocui does not report the bracketed
+// paste markers, the pasted text arrives as keys.
+const pasteDelay = 25 * time.Millisecond
+
+// Input is a one line editable field of a dialog. The fields hold
+// invoices, public keys or addresses: the spaces and line breaks of a
+// paste are dropped and the value is validated as it is typed. A text
+// field keeps the spaces.
+type Input struct {
+	name     string
+	title    string
+	view     *gocui.View
+	validate func(string) error
+	err      error
+	last     time.Time
+	text     bool
+	// value is written in the field when it is displayed.
+	value string
+}
+
+func (i *Input) Name() string {
+	return i.name
+}
+
+// Value returns the text of the field without the surrounding spaces.
+func (i *Input) Value() string {
+	if i.view == nil {
+		return ""
+	}
+	return strings.TrimSpace(i.view.Buffer())
+}
+
+// Valid returns the error of the validation of the value.
+func (i *Input) Valid() error {
+	return i.err
+}
+
+// SetError sets the error of the value, displayed under the field until
+// it is edited.
+func (i *Input) SetError(err error) {
+	i.err = err
+}
+
+// SetValue sets the value of the field, it is written when the field is
+// displayed next or replaces the text of the field displayed.
+func (i *Input) SetValue(value string) {
+	i.value = value
+	if i.view == nil {
+		return
+	}
+	i.view.Clear()
+	fmt.Fprint(i.view, value)
+	_ = i.view.SetOrigin(0, 0)
+	_ = i.view.SetCursor(len(value), 0)
+	i.check()
+}
+
+// Pasting returns true if the last key was received under the paste
+// delay, an enter is then part of the paste and does not submit.
+func (i *Input) Pasting() bool {
+	return time.Since(i.last) < pasteDelay
+}
+
+func (i *Input) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	i.last = time.Now()
+	x, _ := v.Cursor()
+
+	switch {
+	case ch != 0 && mod == gocui.ModNone:
+		if unicode.IsPrint(ch) && ((i.text && ch == ' ') || !unicode.IsSpace(ch)) {
+			v.EditWrite(ch)
+		}
+	case key == gocui.KeySpace:
+		if i.text {
+			v.EditWrite(' ')
+		}
+	case key == gocui.KeyBackspace || key == gocui.KeyBackspace2:
+		v.EditDelete(true)
+	case key == gocui.KeyDelete:
+		v.EditDelete(false)
+	case key == gocui.KeyArrowLeft:
+		v.MoveCursor(-1, 0)
+	case key == gocui.KeyArrowRight:
+		v.MoveCursor(1, 0)
+	case key == gocui.KeyHome || key == gocui.KeyCtrlA:
+		v.MoveCursor(-x, 0)
+	case key == gocui.KeyEnd || key == gocui.KeyCtrlE:
+		v.MoveCursor(len(v.Buffer()), 0)
+	case key == gocui.KeyCtrlU:
+		v.Clear()
+		_ = v.SetCursor(0, 0)
+		_ = v.SetOrigin(0, 0)
+	}
+
+	i.check()
+}
+
+func (i *Input) check() {
+	i.err = nil
+	value := i.Value()
+	if i.validate != nil && value != "" {
+		i.err = i.validate(value)
+	}
+}
+
+func (i *Input) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	i.view, err = g.SetView(i.name, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		if i.value != "" {
+			fmt.Fprint(i.view, i.value)
+			_ = i.view.SetCursor(len(i.value), 0)
+			i.check()
+		}
+	}
+	i.view.Frame = true
+	i.view.Title = i.title
+	i.view.Editable = true
+	i.view.Editor = i
+	i.view.Wrap = false
+	i.feedback()
+	g.Cursor = true
+
+	_, err = g.SetCurrentView(i.name)
+	return err
+}
+
+// feedback displays the result of the validation under the field.
+func (i *Input) feedback() {
+	i.view.Subtitle = ""
+	i.view.FrameColor = color.Attrs().Frame
+	i.view.TitleColor = color.Attrs().Frame
+	if i.validate == nil || i.Value() == "" {
+		return
+	}
+	if i.err != nil {
+		i.view.Subtitle = " " + i.err.Error() + " "
+		i.view.FrameColor = color.Attrs().Invalid
+		i.view.TitleColor = color.Attrs().Invalid
+		return
+	}
+	i.view.Subtitle = " valid "
+	i.view.FrameColor = color.Attrs().Valid
+	i.view.TitleColor = color.Attrs().Valid
+}
+
+func (i *Input) Delete(g *gocui.Gui) error {
+	g.Cursor = false
+	i.view = nil
+	i.err = nil
+	i.value = ""
+	err := g.DeleteView(i.name)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+// NewInput returns a field validated by validate, which may be nil.
+func NewInput(name, title string, validate func(string) error) *Input {
+	return &Input{name: name, title: title, validate: validate}
+}
+
+// NewTextInput returns a field of free text, e.g. a label.
+func NewTextInput(name, title string) *Input {
+	return &Input{name: name, title: title, text: true}
+}
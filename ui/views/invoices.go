@@ -0,0 +1,443 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	INVOICES         = "invoices"
+	INVOICES_COLUMNS = "invoices_columns"
+	INVOICES_FOOTER  = "invoices_footer"
+)
+
+var DefaultInvoicesColumns = []string{
+	"DATE",
+	"STATE",
+	"AMOUNT",
+	"PAID",
+	"MEMO",
+	"EXPIRY",
+}
+
+type Invoices struct {
+	cfg *config.View
+
+	columns           []invoicesColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	invoices          *models.Invoices
+
+	ox, oy int
+	cx, cy int
+}
+
+type invoicesColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.InvoicesSort
+	display func(*netmodels.Invoice, ...color.Option) string
+}
+
+func (c Invoices) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Invoices) Name() string {
+	return INVOICES
+}
+
+func (c *Invoices) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Invoices) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Invoices) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Invoices) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Invoices) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Invoices) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Invoices) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.invoices.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Invoices) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.invoices.Len()
+	return
+}
+
+func (c *Invoices) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.invoices.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Invoices) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(INVOICES_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(INVOICES)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(INVOICES_FOOTER)
+}
+
+func (c *Invoices) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(INVOICES_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(INVOICES, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(INVOICES_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("Enter"), "QR",
+		blackBg("c"), "Create",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+func (c *Invoices) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	c.view.Rewind()
+	for _, item := range c.invoices.List() {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func invoiceState(i *netmodels.Invoice, opts ...color.Option) string {
+	name := i.StateName()
+	text := fmt.Sprintf("%-8s", name)
+	switch {
+	case i.State == netmodels.InvoiceSettled:
+		return color.Green(opts...)(text)
+	case i.State == netmodels.InvoiceCanceled, name == "expired":
+		return color.Red(opts...)(text)
+	}
+	return color.Yellow(opts...)(text)
+}
+
+func NewInvoices(cfg *config.View, invoices *models.Invoices) *Invoices {
+	view := &Invoices{
+		cfg:      cfg,
+		invoices: invoices,
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	columns := DefaultInvoicesColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]invoicesColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "DATE":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.Int64Sort(i1.CreationDate, i2.CreationDate, order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.Cyan(opts...)(fmt.Sprintf("%15s", formatUnix(i.CreationDate)))
+				},
+			}
+		case "STATE":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.StringSort(i1.StateName(), i2.StateName(), order)
+					}
+				},
+				display: invoiceState,
+			}
+		case "AMOUNT":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.Int64Sort(i1.Amount, i2.Amount, order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					if i.Amount == 0 {
+						return color.White(opts...)(fmt.Sprintf("%12s", "any"))
+					}
+					return color.White(opts...)(printer.Sprintf("%12d", i.Amount))
+				},
+			}
+		case "PAID":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.Int64Sort(i1.AmountPaid, i2.AmountPaid, order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.Green(opts...)(printer.Sprintf("%12d", i.AmountPaid))
+				},
+			}
+		case "MEMO":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.StringSort(i1.Description, i2.Description, order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(i.Description, 30, "…"), 30))
+				},
+			}
+		case "EXPIRY":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.Int64Sort(i1.ExpiryDate(), i2.ExpiryDate(), order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%15s", formatUnix(i.ExpiryDate())))
+				},
+			}
+		case "SETTLED":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.Int64Sort(i1.SettleDate, i2.SettleDate, order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.Cyan(opts...)(fmt.Sprintf("%15s", formatUnix(i.SettleDate)))
+				},
+			}
+		case "HASH":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-64s", columns[i]),
+				width: 64,
+				sort: func(order models.Order) models.InvoicesSort {
+					return func(i1, i2 *netmodels.Invoice) bool {
+						return models.StringSort(i1.GetRHash(), i2.GetRHash(), order)
+					}
+				},
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-64s", i.GetRHash()))
+				},
+			}
+		case "PAYREQ":
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-40s", columns[i]),
+				width: 40,
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(i.PaymentRequest, 40, "…"), 40))
+				},
+			}
+		default:
+			view.columns[i] = invoicesColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(i *netmodels.Invoice, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+
+	return view
+}
+
+// formatUnix returns the date of the unix time, empty if it is zero.
+func formatUnix(t int64) string {
+	if t == 0 {
+		return ""
+	}
+	return time.Unix(t, 0).Format("15:04:05 Jan _2")
+}
@@ -0,0 +1,219 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	KEYSEND         = "keysend"
+	KEYSEND_PUBKEY  = "keysend_pubkey"
+	KEYSEND_AMOUNT  = "keysend_amount"
+	KEYSEND_MESSAGE = "keysend_message"
+	KEYSEND_AMP     = "keysend_amp"
+)
+
+// Keysend is the dialog paying a node without invoice, with a keysend or
+// an AMP payment and an optional message. The first enter asks for a
+// confirmation and the second one sends it.
+type Keysend struct {
+	form
+	visible   bool
+	confirmed *netmodels.Keysend
+	alias     string
+	// start is the start of the payment, zero until it is sent.
+	start   time.Time
+	payment *netmodels.Payment
+	err     error
+}
+
+func (k *Keysend) Visible() bool {
+	return k.visible
+}
+
+func (k *Keysend) Show() {
+	k.reset("", "", "", "no")
+	k.confirmed = nil
+	k.start = time.Time{}
+	k.payment = nil
+	k.err = nil
+	k.visible = true
+}
+
+func (k *Keysend) Hide() {
+	k.visible = false
+	k.confirmed = nil
+}
+
+// Sent returns true once the payment is sent.
+func (k *Keysend) Sent() bool {
+	return !k.start.IsZero()
+}
+
+// Value returns the payment of the fields.
+func (k *Keysend) Value() (*netmodels.Keysend, error) {
+	pubkey := k.inputs[0].Value()
+	err := validatePubKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(k.inputs[1].Value())
+	if err != nil {
+		return nil, err
+	}
+	amp, err := parseYesNo(k.inputs[3].Value())
+	if err != nil {
+		return nil, errors.Errorf("amp: %s", err)
+	}
+	return &netmodels.Keysend{
+		Destination: pubkey,
+		Amount:      amount,
+		Message:     k.inputs[2].Value(),
+		AMP:         amp,
+	}, nil
+}
+
+// Confirm returns true if the payment was confirmed by the previous
+// enter, otherwise it asks to confirm it, the alias is the one of the
+// destination.
+func (k *Keysend) Confirm(req *netmodels.Keysend, alias string) bool {
+	if k.confirmed != nil && *k.confirmed == *req {
+		return true
+	}
+	k.confirmed = req
+	k.alias = alias
+	k.err = nil
+	return false
+}
+
+// Start displays the payment in flight.
+func (k *Keysend) Start() {
+	k.start = time.Now()
+}
+
+// SetUpdate sets the payment at each update, until it succeeded or
+// failed.
+func (k *Keysend) SetUpdate(payment *netmodels.Payment) {
+	k.payment = payment
+}
+
+// SetResult sets the payment once it succeeded or failed.
+func (k *Keysend) SetResult(payment *netmodels.Payment, err error) {
+	if payment != nil {
+		k.payment = payment
+	}
+	k.err = err
+}
+
+// SetError sets the error of the fields, the dialog stays open.
+func (k *Keysend) SetError(err error) {
+	k.confirmed = nil
+	k.err = err
+}
+
+func (k *Keysend) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(k.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := k.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(KEYSEND, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " keysend "
+	k.display(v)
+	return nil
+}
+
+func (k *Keysend) display(v *gocui.View) {
+	v.Clear()
+	if k.err != nil {
+		fmt.Fprintln(v, color.Red()(k.err.Error()))
+		if k.Sent() {
+			fmt.Fprintln(v, "esc to close")
+		}
+		return
+	}
+	if k.confirmed == nil {
+		fmt.Fprintln(v, "the message is a TLV record read by the wallets, an AMP payment is split")
+		fmt.Fprintln(v, "tab moves to the next field, enter sends the payment, esc to close")
+		return
+	}
+
+	kind := "keysend"
+	if k.confirmed.AMP {
+		kind = "AMP payment"
+	}
+	dest := k.confirmed.Destination
+	if k.alias != "" {
+		dest = k.alias
+	}
+	amount := color.Yellow(color.Bold)(formatAmount(k.confirmed.Amount) + " sat")
+	if !k.Sent() {
+		fmt.Fprintf(v, "send a %s of %s to %s?\n", kind, amount, color.Cyan()(dest))
+		fmt.Fprintln(v, "press enter again to send it, esc to cancel")
+		return
+	}
+
+	switch {
+	case k.payment != nil && k.payment.Status == netmodels.PaymentSucceeded:
+		fmt.Fprintf(v, "%s %s to %s, fee %d sat\n", color.Green(color.Bold)("sent"),
+			amount, color.Cyan()(dest), k.payment.Fee)
+		fmt.Fprintf(v, "preimage %x\n", k.payment.PaymentPreimage)
+		fmt.Fprintln(v, "esc to close")
+	case k.payment != nil && k.payment.Status == netmodels.PaymentFailed:
+		fmt.Fprintf(v, "%s %s to %s: %s\n", color.Red(color.Bold)("failed to send"),
+			amount, color.Cyan()(dest), k.payment.PaymentError)
+		fmt.Fprintln(v, "esc to close")
+	default:
+		frame := spinner[int(time.Since(k.start)/(100*time.Millisecond))%len(spinner)]
+		fmt.Fprintf(v, "%s sending a %s of %s to %s, %ds\n", color.Yellow()(frame),
+			kind, amount, color.Cyan()(dest), int(time.Since(k.start).Seconds()))
+		if k.payment != nil && k.payment.Hash != "" {
+			fmt.Fprintf(v, "payment hash %s\n", k.payment.Hash)
+		}
+		fmt.Fprintln(v, "esc closes the dialog, the payment goes on")
+	}
+}
+
+func (k *Keysend) Delete(g *gocui.Gui) error {
+	err := k.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(KEYSEND)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewKeysend() *Keysend {
+	return &Keysend{form: form{inputs: []*Input{
+		NewInput(KEYSEND_PUBKEY, " node pubkey ", validatePubKey),
+		NewInput(KEYSEND_AMOUNT, " amount (sat) ", validateAmount),
+		NewTextInput(KEYSEND_MESSAGE, " message "),
+		NewInput(KEYSEND_AMP, " amp (yes/no) ", validateYesNo),
+	}}}
+}
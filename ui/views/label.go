@@ -0,0 +1,122 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	LABEL       = "label"
+	LABEL_INPUT = "label_input"
+)
+
+// Label is the popup setting the label of the output selected in the
+// utxos view, lnd labels the transaction of the output.
+type Label struct {
+	input   *Input
+	visible bool
+	utxo    *netmodels.UTXO
+	err     error
+}
+
+func (l *Label) Visible() bool {
+	return l.visible
+}
+
+func (l *Label) Show(utxo *netmodels.UTXO) {
+	l.utxo = utxo
+	l.err = nil
+	l.visible = true
+}
+
+func (l *Label) Hide() {
+	l.visible = false
+	l.utxo = nil
+	l.err = nil
+}
+
+// UTXO returns the output to label.
+func (l *Label) UTXO() *netmodels.UTXO {
+	return l.utxo
+}
+
+// Value returns the label of the input.
+func (l *Label) Value() string {
+	return l.input.Value()
+}
+
+// Pasting returns true while a label is pasted in the input.
+func (l *Label) Pasting() bool {
+	return l.input.Pasting()
+}
+
+func (l *Label) SetError(err error) {
+	l.err = err
+}
+
+func (l *Label) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+7 > maxY {
+		y0 = 0
+	}
+
+	err := l.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(LABEL, x0, y0+3, x0+width, y0+8, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " label "
+	l.display(v)
+	return nil
+}
+
+func (l *Label) display(v *gocui.View) {
+	v.Clear()
+	if l.utxo == nil {
+		fmt.Fprintln(v, "no output selected, esc to close")
+		return
+	}
+	cyan := color.Cyan()
+	fmt.Fprintf(v, "%s %s\n", cyan("outpoint"), l.utxo.Outpoint)
+	if l.utxo.Label != "" {
+		fmt.Fprintf(v, "%s %s\n", cyan("label   "), l.utxo.Label)
+	}
+	if l.err != nil {
+		fmt.Fprintln(v, color.Red()(l.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "type the label of the transaction and press enter, esc to close")
+}
+
+func (l *Label) Delete(g *gocui.Gui) error {
+	err := l.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(LABEL)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewLabel() *Label {
+	return &Label{input: NewTextInput(LABEL_INPUT, " label ")}
+}
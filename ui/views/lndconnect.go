@@ -0,0 +1,73 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	LNDCONNECT = "lndconnect"
+)
+
+// LNDConnect is the popup confirming the display of the QR code of the
+// lndconnect URI, it holds the macaroon of the config.
+type LNDConnect struct {
+	visible bool
+}
+
+func (l *LNDConnect) Visible() bool {
+	return l.visible
+}
+
+func (l *LNDConnect) Show() {
+	l.visible = true
+}
+
+func (l *LNDConnect) Hide() {
+	l.visible = false
+}
+
+func (l *LNDConnect) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+5 > maxY {
+		y0 = 0
+	}
+
+	v, err := g.SetView(LNDCONNECT, x0, y0, x0+width, y0+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " lndconnect "
+
+	v.Clear()
+	fmt.Fprintln(v, color.Red()("the QR code holds the macaroon of the config and the TLS certificate,"))
+	fmt.Fprintln(v, color.Red()("anyone scanning it gets the access of the macaroon to the node"))
+	fmt.Fprintln(v, "press enter to display it, esc to cancel")
+
+	_, err = g.SetCurrentView(LNDCONNECT)
+	return err
+}
+
+func (l *LNDConnect) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(LNDCONNECT)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewLNDConnect() *LNDConnect {
+	return &LNDConnect{}
+}
@@ -0,0 +1,64 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+// Lookup is the field of the lookup of the graph view, above its footer.
+// The nodes are filtered as the query is typed.
+type Lookup struct {
+	input   *Input
+	visible bool
+	graph   *models.Graph
+	changed func(string)
+}
+
+func (l *Lookup) Visible() bool {
+	return l.visible
+}
+
+// Show opens the field with the query, changed is called with the query
+// each time it is edited.
+func (l *Lookup) Show(query string, changed func(string)) {
+	l.input.SetValue(query)
+	l.changed = changed
+	l.visible = true
+}
+
+func (l *Lookup) Hide() {
+	l.visible = false
+	l.changed = nil
+}
+
+func (l *Lookup) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	l.input.Edit(v, key, ch, mod)
+	if l.changed != nil {
+		l.changed(l.input.Value())
+	}
+}
+
+func (l *Lookup) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	err := l.input.Set(g, x0, y0, x1, y1)
+	if err != nil {
+		return err
+	}
+	l.input.view.Editor = l
+	l.input.view.Subtitle = fmt.Sprintf(" %d/%d nodes, enter to keep, esc to clear ",
+		l.graph.Len(), l.graph.Total())
+	return nil
+}
+
+func (l *Lookup) Delete(g *gocui.Gui) error {
+	return l.input.Delete(g)
+}
+
+func NewLookup(graph *models.Graph) *Lookup {
+	return &Lookup{
+		input: NewTextInput(GRAPH_LOOKUP, " lookup pubkey or alias "),
+		graph: graph,
+	}
+}
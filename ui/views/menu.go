@@ -13,15 +13,35 @@ const (
 	MENU_FOOTER = "menu_footer"
 )
 
-var menu = []string{
-	"CHANNEL",
-	"TRANSAC",
-	"ROUTING",
-	"FWDHIST",
+type menuItem struct {
+	label string
+	view  string
+}
+
+var menu = []menuItem{
+	{"DASHBRD", DASHBOARD},
+	{"CHANNEL", CHANNELS},
+	{"PENDING", PENDING},
+	{"TRANSAC", TRANSACTIONS},
+	{"PAYMENT", PAYMENTS},
+	{"INVOICE", INVOICES},
+	{"OFFERS", OFFERS},
+	{"ROUTING", ROUTING},
+	{"FAILURE", FAILURES},
+	{"FWDHIST", FWDINGHIST},
+	{"PEERS", PEERS},
+	{"CLOSED", CLOSED},
+	{"SWEEPS", SWEEPS},
+	{"UTXOS", UTXOS},
+	{"TOWERS", TOWERS},
+	{"MISSION", MISSIONCONTROL},
+	{"GRAPH", GRAPH},
+	{"NOTIFY", NOTIFICATIONS},
 }
 
 type Menu struct {
-	view *gocui.View
+	view  *gocui.View
+	items []menuItem
 
 	cy, oy int
 }
@@ -45,15 +65,15 @@ func (h Menu) Cursor() (int, int) {
 
 func (h Menu) Speed() (int, int, int, int) {
 	down := 0
-	if h.cy+h.oy < len(menu)-1 {
+	if h.cy+h.oy < len(h.items)-1 {
 		down = 1
 	}
 	return 0, 0, down, 1
 }
 
 func (h Menu) Limits() (pageSize int, fullSize int) {
-	pageSize = len(menu)
-	fullSize = len(menu)
+	pageSize = len(h.items)
+	fullSize = len(h.items)
 	return
 }
 
@@ -78,17 +98,8 @@ func (h *Menu) SetOrigin(x, y int) error {
 
 func (h Menu) Current() string {
 	_, y := h.view.Cursor()
-	if y < len(menu) {
-		switch menu[y] {
-		case "CHANNEL":
-			return CHANNELS
-		case "TRANSAC":
-			return TRANSACTIONS
-		case "ROUTING":
-			return ROUTING
-		case "FWDHIST":
-			return FWDINGHIST
-		}
+	if y < len(h.items) {
+		return h.items[y].view
 	}
 	return ""
 }
@@ -117,8 +128,8 @@ func (h Menu) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		setCursor = true
 	}
 	header.Frame = false
-	header.BgColor = gocui.ColorGreen
-	header.FgColor = gocui.ColorBlack
+	header.BgColor = color.Attrs().HeaderBg
+	header.FgColor = color.Attrs().HeaderFg
 
 	header.Rewind()
 	fmt.Fprintln(header, " MENU")
@@ -133,12 +144,12 @@ func (h Menu) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 
 	h.view.Frame = false
 	h.view.Highlight = true
-	h.view.SelBgColor = gocui.ColorCyan
-	h.view.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+	h.view.SelBgColor = color.Attrs().SelectionBg
+	h.view.SelFgColor = color.Attrs().SelectionFg
 
 	h.view.Rewind()
-	for i := range menu {
-		fmt.Fprintln(h.view, fmt.Sprintf(" %-9s", menu[i]))
+	for i := range h.items {
+		fmt.Fprintln(h.view, fmt.Sprintf(" %-9s", h.items[i].label))
 	}
 	_, err = g.SetCurrentView(MENU)
 	if err != nil {
@@ -166,8 +177,8 @@ func (h Menu) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		}
 	}
 	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
 	footer.Rewind()
 	blackBg := color.Black(color.Background)
 	fmt.Fprintln(footer, fmt.Sprintf("%s%s",
@@ -176,4 +187,11 @@ func (h Menu) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	return nil
 }
 
-func NewMenu() *Menu { return &Menu{} }
+// Add appends an entry displaying the view of the given name.
+func (h *Menu) Add(label, view string) {
+	h.items = append(h.items, menuItem{label, view})
+}
+
+func NewMenu() *Menu {
+	return &Menu{items: append([]menuItem{}, menu...)}
+}
@@ -0,0 +1,179 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	MESSAGE           = "message"
+	MESSAGE_TEXT      = "message_text"
+	MESSAGE_SIGNATURE = "message_signature"
+)
+
+// Message is the popup signing a message with the key of the node, or
+// verifying the signature of the message if one is given.
+type Message struct {
+	form
+	// ReadOnly only allows to verify the signatures.
+	ReadOnly bool
+	visible  bool
+	// busy is true while the message is signed or verified.
+	busy     bool
+	signed   bool
+	verified *netmodels.VerifiedMessage
+	alias    string
+	copied   bool
+	err      error
+}
+
+func (m *Message) Visible() bool {
+	return m.visible
+}
+
+func (m *Message) Show() {
+	m.reset()
+	m.clear()
+	m.inputs[1].title = " signature, empty to sign the message "
+	if m.ReadOnly {
+		m.inputs[1].title = " signature "
+	}
+	m.visible = true
+}
+
+func (m *Message) Hide() {
+	m.visible = false
+}
+
+func (m *Message) clear() {
+	m.busy = false
+	m.signed = false
+	m.verified = nil
+	m.alias = ""
+	m.copied = false
+	m.err = nil
+}
+
+// Value returns the message and the signature of the fields.
+func (m *Message) Value() (string, string) {
+	return m.inputs[0].Value(), m.inputs[1].Value()
+}
+
+// Start marks the message as signed or verified until its result is set.
+func (m *Message) Start() {
+	m.clear()
+	m.busy = true
+}
+
+// Busy returns true while the message is signed or verified.
+func (m *Message) Busy() bool {
+	return m.busy
+}
+
+// SetSignature fills the signature field with the signature of the node.
+func (m *Message) SetSignature(signature string, err error) {
+	m.clear()
+	m.err = err
+	if err == nil {
+		m.inputs[1].SetValue(signature)
+		m.signed = true
+	}
+}
+
+// SetVerified sets the verification of the signature, the alias is the
+// one of its signer.
+func (m *Message) SetVerified(verified *netmodels.VerifiedMessage, alias string, err error) {
+	m.clear()
+	m.verified = verified
+	m.alias = alias
+	m.err = err
+}
+
+// SetCopied reports the copy of the signature to the clipboard.
+func (m *Message) SetCopied(err error) {
+	m.copied = err == nil
+	m.err = err
+}
+
+func (m *Message) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 110
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(m.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := m.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(MESSAGE, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " sign or verify a message "
+	m.display(v)
+	return nil
+}
+
+func (m *Message) display(v *gocui.View) {
+	v.Clear()
+	switch {
+	case m.err != nil:
+		fmt.Fprintln(v, color.Red()(m.err.Error()))
+	case m.busy:
+		fmt.Fprintln(v, color.Yellow()("asking the node..."))
+	case m.signed:
+		fmt.Fprintln(v, color.Green(color.Bold)("signed with the key of the node"))
+	case m.verified != nil && m.verified.Valid:
+		signer := m.verified.PubKey
+		if m.alias != "" {
+			signer = fmt.Sprintf("%s %s", color.Cyan()(m.alias), signer)
+		}
+		fmt.Fprintf(v, "%s of %s\n", color.Green(color.Bold)("valid signature"), signer)
+	case m.verified != nil && m.verified.PubKey != "":
+		fmt.Fprintf(v, "%s, %s is not a node of the graph\n", color.Red(color.Bold)("invalid signature"),
+			m.verified.PubKey)
+	case m.verified != nil:
+		fmt.Fprintln(v, color.Red(color.Bold)("invalid signature"))
+	case m.ReadOnly:
+		fmt.Fprintln(v, "enter verifies the signature of the message, signing is disabled in read-only mode")
+	default:
+		fmt.Fprintln(v, "enter signs the message with the key of the node, or verifies the signature if one is given")
+	}
+	if m.copied {
+		fmt.Fprintln(v, "signature copied to the clipboard")
+	}
+	fmt.Fprintln(v, "tab moves to the next field, ctrl+y copies the signature, esc to close")
+}
+
+func (m *Message) Delete(g *gocui.Gui) error {
+	err := m.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(MESSAGE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewMessage() *Message {
+	return &Message{form: form{inputs: []*Input{
+		NewTextInput(MESSAGE_TEXT, " message "),
+		NewTextInput(MESSAGE_SIGNATURE, " signature, empty to sign the message "),
+	}}}
+}
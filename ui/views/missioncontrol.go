@@ -0,0 +1,529 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	MISSIONCONTROL         = "missioncontrol"
+	MISSIONCONTROL_COLUMNS = "missioncontrol_columns"
+	MISSIONCONTROL_FOOTER  = "missioncontrol_footer"
+)
+
+var DefaultMissionControlColumns = []string{
+	"FROM",
+	"TO",
+	"SUCCESS",
+	"SUCCESS_AMT",
+	"FAILURE",
+	"FAIL_AMT",
+	"PROB",
+}
+
+// MissionControl is the view of the pairs of mission control, the footer
+// shows the nodes penalized by the recent failures.
+type MissionControl struct {
+	cfg     *config.View
+	printer *message.Printer
+
+	columns           []missionColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	mission           *models.MissionControl
+	// rows is the number of pairs of the last display.
+	rows int
+	// message is the result of the last action, displayed in the footer
+	// for a few seconds.
+	message   string
+	messageAt time.Time
+
+	ox, oy int
+	cx, cy int
+}
+
+type missionColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.MissionControlSort
+	display func(*models.MissionPair, ...color.Option) string
+}
+
+func (c MissionControl) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c MissionControl) Name() string {
+	return MISSIONCONTROL
+}
+
+func (c *MissionControl) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c MissionControl) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c MissionControl) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c MissionControl) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *MissionControl) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *MissionControl) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *MissionControl) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.mission.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *MissionControl) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.mission.Len()
+	return
+}
+
+func (c *MissionControl) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.mission.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+// SetMessage displays the result of an action in the footer for a few
+// seconds.
+func (c *MissionControl) SetMessage(msg string) {
+	c.message = msg
+	c.messageAt = time.Now()
+}
+
+func (c MissionControl) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(MISSIONCONTROL_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(MISSIONCONTROL)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(MISSIONCONTROL_FOOTER)
+}
+
+func (c *MissionControl) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(MISSIONCONTROL_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(MISSIONCONTROL, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(MISSIONCONTROL_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	summary := c.summary()
+	if c.message != "" && time.Since(c.messageAt) < 5*time.Second {
+		summary = c.message
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("X"), "Reset",
+		blackBg("E"), "Export",
+		blackBg("L"), "Import",
+		blackBg("F10"), "Quit",
+		summary,
+	))
+	return nil
+}
+
+func (c *MissionControl) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.mission.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once mission control is reset.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// summary returns the number of pairs and the nodes penalized by the
+// recent failures, the most failed first.
+func (c *MissionControl) summary() string {
+	if c.mission.Refreshed().IsZero() {
+		return "loading..."
+	}
+	summary := c.printer.Sprintf("%d pairs, probabilities of %d sat", c.mission.Len(), c.mission.Amount)
+	penalized := c.mission.Penalized(time.Now())
+	if len(penalized) == 0 {
+		return summary
+	}
+	var names []string
+	for i, node := range penalized {
+		if i == 3 {
+			names = append(names, fmt.Sprintf("+%d", len(penalized)-i))
+			break
+		}
+		names = append(names, fmt.Sprintf("%s (%d)", nodeName(node.Alias, node.PubKey), node.Failures))
+	}
+	return summary + ", penalized: " + strings.Join(names, " ")
+}
+
+// nodeName returns the alias of the node, the start of its pubkey if it
+// has none.
+func nodeName(alias, pubkey string) string {
+	if alias != "" {
+		return alias
+	}
+	if len(pubkey) > 16 {
+		return pubkey[:16]
+	}
+	return pubkey
+}
+
+// formatProbability returns the probability in percent, colored from red
+// to green, "-" if it is unknown.
+func formatProbability(p float64, width int, opts ...color.Option) string {
+	if p < 0 {
+		return color.White(opts...)(fmt.Sprintf("%*s", width, "-"))
+	}
+	text := fmt.Sprintf("%*.1f%%", width-1, p*100)
+	switch {
+	case p >= 0.6:
+		return color.Green(opts...)(text)
+	case p >= 0.2:
+		return color.Yellow(opts...)(text)
+	}
+	return color.Red(opts...)(text)
+}
+
+// formatAttempt returns the time of the attempt, empty if there was none.
+func formatAttempt(t time.Time) string {
+	if t.IsZero() {
+		return fmt.Sprintf("%-15s", "")
+	}
+	return t.Format("15:04:05 Jan _2")
+}
+
+func NewMissionControl(cfg *config.View, mission *models.MissionControl) *MissionControl {
+	printer := message.NewPrinter(language.English)
+	view := &MissionControl{
+		cfg:     cfg,
+		printer: printer,
+		mission: mission,
+	}
+
+	columns := DefaultMissionControlColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]missionColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "FROM":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-20s", columns[i]),
+				width: 20,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.StringSort(nodeName(p1.FromAlias, p1.NodeFrom), nodeName(p2.FromAlias, p2.NodeFrom), order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					name := nodeName(p.FromAlias, p.NodeFrom)
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(name, 20, "…"), 20))
+				},
+			}
+		case "TO":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-20s", columns[i]),
+				width: 20,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.StringSort(nodeName(p1.ToAlias, p1.NodeTo), nodeName(p2.ToAlias, p2.NodeTo), order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					name := nodeName(p.ToAlias, p.NodeTo)
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(name, 20, "…"), 20))
+				},
+			}
+		case "FROM_PUBKEY":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-66s", columns[i]),
+				width: 66,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.StringSort(p1.NodeFrom, p2.NodeFrom, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-66s", p.NodeFrom))
+				},
+			}
+		case "TO_PUBKEY":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-66s", columns[i]),
+				width: 66,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.StringSort(p1.NodeTo, p2.NodeTo, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-66s", p.NodeTo))
+				},
+			}
+		case "SUCCESS":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.DateSort(&p1.SuccessTime, &p2.SuccessTime, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					return color.Green(opts...)(formatAttempt(p.SuccessTime))
+				},
+			}
+		case "SUCCESS_AMT":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.Int64Sort(p1.SuccessAmtMsat, p2.SuccessAmtMsat, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					if p.SuccessTime.IsZero() {
+						return fmt.Sprintf("%12s", "")
+					}
+					return color.White(opts...)(printer.Sprintf("%12d", p.SuccessAmtMsat/1000))
+				},
+			}
+		case "FAILURE":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.DateSort(&p1.FailTime, &p2.FailTime, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					if p.Failed() {
+						return color.Red(opts...)(formatAttempt(p.FailTime))
+					}
+					return color.White(opts...)(formatAttempt(p.FailTime))
+				},
+			}
+		case "FAIL_AMT":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.Int64Sort(p1.FailAmtMsat, p2.FailAmtMsat, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					if p.FailTime.IsZero() {
+						return fmt.Sprintf("%12s", "")
+					}
+					return color.White(opts...)(printer.Sprintf("%12d", p.FailAmtMsat/1000))
+				},
+			}
+		case "PROB":
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%6s", columns[i]),
+				width: 6,
+				sort: func(order models.Order) models.MissionControlSort {
+					return func(p1, p2 *models.MissionPair) bool {
+						return models.Float64Sort(p1.Probability, p2.Probability, order)
+					}
+				},
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					return formatProbability(p.Probability, 6, opts...)
+				},
+			}
+		default:
+			view.columns[i] = missionColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(p *models.MissionPair, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
@@ -0,0 +1,188 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	NODE = "node"
+)
+
+// Node is the popup of the detail of the current node of the graph, with
+// its addresses, features and channels, above the graph view.
+type Node struct {
+	view    *gocui.View
+	graph   *models.Graph
+	info    *models.Info
+	visible bool
+}
+
+func (c *Node) Visible() bool {
+	return c.visible
+}
+
+// Show displays the current node of the graph from the next layout.
+func (c *Node) Show() {
+	c.visible = true
+}
+
+func (c *Node) Hide() {
+	c.visible = false
+}
+
+func (c Node) Name() string {
+	return NODE
+}
+
+func (c *Node) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Node) Origin() (int, int) {
+	return c.view.Origin()
+}
+
+func (c Node) Cursor() (int, int) {
+	return c.view.Cursor()
+}
+
+func (c Node) Speed() (int, int, int, int) {
+	return 1, 1, 1, 1
+}
+
+func (c Node) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = len(c.view.BufferLines()) - 1
+	return
+}
+
+func (c *Node) SetCursor(x, y int) error {
+	return c.view.SetCursor(x, y)
+}
+
+func (c *Node) SetOrigin(x, y int) error {
+	return c.view.SetOrigin(x, y)
+}
+
+func (c *Node) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(NODE, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Title = " Node - o to open a channel, esc to close "
+	c.view = v
+	c.display()
+
+	_, err = g.SetCurrentView(NODE)
+	return err
+}
+
+func (c Node) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(NODE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func (c *Node) display() {
+	p := message.NewPrinter(language.English)
+	v := c.view
+	// the previous node may have more lines, the scroll is kept.
+	ox, oy := v.Origin()
+	v.Clear()
+	v.SetOrigin(ox, oy)
+	node := c.graph.Current
+	if node == nil {
+		return
+	}
+	green := color.Green()
+	cyan := color.Cyan()
+	fmt.Fprintln(v, green(" [ Node ]"))
+	alias := node.Alias
+	if node.ForcedAlias != "" {
+		alias = cyan(node.ForcedAlias)
+	}
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("          Alias:"), alias)
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("         PubKey:"), node.PubKey)
+	fmt.Fprintf(v, "%s %s\n",
+		cyan("    Last Update:"), node.LastUpdate.Format("15:04:05 Jan _2"))
+	fmt.Fprintf(v, "%s %s\n",
+		cyan(" Total Capacity:"), formatAmount(node.TotalCapacity))
+	fmt.Fprintf(v, "%s %d\n",
+		cyan(" Total Channels:"), node.NumChannels)
+
+	fmt.Fprintln(v, "")
+	fmt.Fprintln(v, green(" [ Addresses ]"))
+	for _, a := range node.Addresses {
+		fmt.Fprintf(v, "%s %s\n",
+			cyan(fmt.Sprintf("%16s", a.Network)), a.Addr)
+	}
+
+	fmt.Fprintln(v, "")
+	fmt.Fprintln(v, green(" [ Features ]"))
+	for _, f := range node.Features {
+		kind := "optional"
+		if f.Required {
+			kind = "required"
+		}
+		name := runewidth.FillRight(f.Name, 28)
+		if !f.Known {
+			name = color.Yellow()(name)
+		}
+		fmt.Fprintf(v, "%s %s %s\n",
+			cyan(fmt.Sprintf("%16d", f.Bit)), name, kind)
+	}
+
+	channels := append([]*netmodels.Channel{}, node.Channels...)
+	sort.SliceStable(channels, func(i, j int) bool {
+		return channels[i].Capacity > channels[j].Capacity
+	})
+	fmt.Fprintln(v, "")
+	fmt.Fprintln(v, green(fmt.Sprintf(" [ Channels ] %d channels", len(channels))))
+	for _, ch := range channels {
+		peer := runewidth.FillRight(runewidth.Truncate(c.peerName(ch), 25, "…"), 25)
+		policy := ""
+		if ch.LocalPolicy != nil {
+			policy = p.Sprintf("base %6d msat  rate %6d ppm", ch.LocalPolicy.FeeBaseMsat, ch.LocalPolicy.FeeRateMilliMsat)
+			if ch.LocalPolicy.Disabled {
+				policy += color.Red()("  disabled")
+			}
+		}
+		fmt.Fprintf(v, " %s %s  %s\n",
+			cyan(peer), color.Yellow()(fmt.Sprintf("%15s", formatAmount(ch.Capacity))), policy)
+	}
+}
+
+// peerName returns the alias of the peer of the channel of the node, the
+// start of its pubkey if it has none.
+func (c *Node) peerName(ch *netmodels.Channel) string {
+	if c.info.Info != nil && ch.RemotePubKey == c.info.PubKey {
+		return c.info.Alias + " (you)"
+	}
+	alias := ""
+	if ch.Node != nil {
+		alias = ch.Node.Alias
+	}
+	return nodeName(alias, ch.RemotePubKey)
+}
+
+func NewNode(graph *models.Graph, info *models.Info) *Node {
+	return &Node{graph: graph, info: info}
+}
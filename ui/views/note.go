@@ -0,0 +1,124 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	NOTE      = "note"
+	NOTE_TAGS = "note_tags"
+	NOTE_TEXT = "note_text"
+)
+
+// Note is the form of the tags and the note of the channel selected in the
+// channels view, recorded in the store.
+type Note struct {
+	form
+	visible bool
+	channel *netmodels.Channel
+	err     error
+}
+
+func (n *Note) Visible() bool {
+	return n.visible
+}
+
+// Show opens the form filled with the tags and the note of the channel.
+func (n *Note) Show(channel *netmodels.Channel) {
+	n.channel = channel
+	n.err = nil
+	n.visible = true
+	n.reset(strings.Join(channel.Tags, ", "), channel.Note)
+}
+
+func (n *Note) Hide() {
+	n.visible = false
+	n.channel = nil
+	n.err = nil
+}
+
+// Channel returns the channel of the note.
+func (n *Note) Channel() *netmodels.Channel {
+	return n.channel
+}
+
+// Value returns the tags and the note of the fields.
+func (n *Note) Value() ([]string, string) {
+	return models.ParseTags(n.inputs[0].Value()), n.inputs[1].Value()
+}
+
+// SetError sets the error of the recording, the form stays open.
+func (n *Note) SetError(err error) {
+	n.err = err
+}
+
+func (n *Note) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(n.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := n.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(NOTE, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " channel tags "
+	n.display(v)
+	return nil
+}
+
+func (n *Note) display(v *gocui.View) {
+	v.Clear()
+	if n.channel == nil {
+		fmt.Fprintln(v, "no channel selected, esc to close")
+		return
+	}
+	cyan := color.Cyan()
+	alias, _ := n.channel.ShortAlias()
+	fmt.Fprintf(v, "%s %s %s\n", cyan("channel"), alias, n.channel.ChannelPoint)
+	if n.err != nil {
+		fmt.Fprintln(v, color.Red()(n.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "tags separated by commas, tab moves to the note, enter records them, esc to close")
+}
+
+func (n *Note) Delete(g *gocui.Gui) error {
+	err := n.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(NOTE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewNote() *Note {
+	return &Note{form: form{inputs: []*Input{
+		NewTextInput(NOTE_TAGS, " tags, e.g. drain, friend "),
+		NewTextInput(NOTE_TEXT, " note "),
+	}}}
+}
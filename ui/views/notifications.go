@@ -0,0 +1,184 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	NOTIFICATIONS        = "notifications"
+	NOTIFICATIONS_FOOTER = "notifications_footer"
+	TOAST                = "toast"
+
+	// toastMax is the number of notifications displayed at once.
+	toastMax = 3
+)
+
+// Notifications is the history of the notifications, the most recent
+// first.
+type Notifications struct {
+	view          *gocui.View
+	notifications *models.Notifications
+}
+
+func (c Notifications) Name() string {
+	return NOTIFICATIONS
+}
+
+func (c *Notifications) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Notifications) Origin() (int, int) {
+	return c.view.Origin()
+}
+
+func (c Notifications) Cursor() (int, int) {
+	return c.view.Cursor()
+}
+
+func (c Notifications) Speed() (int, int, int, int) {
+	return 0, 0, 1, 1
+}
+
+func (c Notifications) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = len(c.view.BufferLines()) - 1
+	return
+}
+
+func (c *Notifications) SetCursor(x, y int) error {
+	return c.view.SetCursor(x, y)
+}
+
+func (c *Notifications) SetOrigin(x, y int) error {
+	return c.view.SetOrigin(x, y)
+}
+
+func (c Notifications) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(NOTIFICATIONS)
+	if err != nil {
+		return err
+	}
+	return g.DeleteView(NOTIFICATIONS_FOOTER)
+}
+
+func (c *Notifications) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	c.view, err = g.SetView(NOTIFICATIONS, x0-1, y0, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	c.view.Frame = false
+	c.display()
+
+	footer, err := g.SetView(NOTIFICATIONS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %d notifications",
+		blackBg("F2"), "Menu",
+		blackBg("F10"), "Quit",
+		len(c.notifications.List()),
+	))
+	return nil
+}
+
+func (c *Notifications) display() {
+	v := c.view
+	// the notifications are added at the top, the scroll is kept.
+	ox, oy := v.Origin()
+	v.Clear()
+	v.SetOrigin(ox, oy)
+
+	list := c.notifications.List()
+	fmt.Fprintln(v, color.Green()(" [ Notifications ]"))
+	for i := len(list) - 1; i >= 0; i-- {
+		fmt.Fprintf(v, " %s %s\n",
+			color.Cyan()(list[i].Time.Format("15:04:05 Jan _2")),
+			notificationColor(list[i])(list[i].Message))
+	}
+}
+
+func notificationColor(n *models.Notification) func(a ...interface{}) string {
+	switch n.Kind {
+	case models.NotificationInvoice:
+		return color.Yellow()
+	case models.NotificationPayment:
+		return color.Magenta()
+	case models.NotificationDisabled:
+		return color.Red()
+	}
+	return color.Green()
+}
+
+func NewNotifications(notifications *models.Notifications) *Notifications {
+	return &Notifications{notifications: notifications}
+}
+
+// Toast displays the last notifications in the bottom right corner until
+// they expire.
+type Toast struct {
+	notifications *models.Notifications
+}
+
+// Visible returns true if a notification has not expired.
+func (t *Toast) Visible() bool {
+	return len(t.notifications.Active(time.Now())) > 0
+}
+
+func (t *Toast) Set(g *gocui.Gui, maxX, maxY int) error {
+	active := t.notifications.Active(time.Now())
+	if len(active) > toastMax {
+		active = active[len(active)-toastMax:]
+	}
+	width := 0
+	for _, n := range active {
+		width = max(width, runewidth.StringWidth(n.Message))
+	}
+	width = min(width+2, maxX-4)
+
+	// above the footer of the main view.
+	x1, y1 := maxX-2, maxY-2
+	v, err := g.SetView(TOAST, x1-width-1, y1-len(active)-1, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Clear()
+	for _, n := range active {
+		fmt.Fprintf(v, " %s\n", notificationColor(n)(runewidth.Truncate(n.Message, width-2, "…")))
+	}
+	_, err = g.SetViewOnTop(TOAST)
+	return err
+}
+
+func (t *Toast) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(TOAST)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewToast(notifications *models.Notifications) *Toast {
+	return &Toast{notifications: notifications}
+}
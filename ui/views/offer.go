@@ -0,0 +1,182 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	OFFER = "offer"
+)
+
+// Offer is the popup of the detail of the offer selected in the offers
+// view, with its bolt12 string and the invoice requests it received.
+type Offer struct {
+	view   *gocui.View
+	offers *models.Offers
+	// id is the id of the offer, it is found again in the offers at each
+	// display to show the requests received since.
+	id     string
+	copied bool
+	err    error
+}
+
+func (c *Offer) Visible() bool {
+	return c.id != ""
+}
+
+func (c *Offer) Show(offer *netmodels.Offer) {
+	c.id = offer.ID
+	c.copied = false
+	c.err = nil
+}
+
+func (c *Offer) Hide() {
+	c.id = ""
+}
+
+// Current returns the offer of the popup, nil if it no longer exists.
+func (c *Offer) Current() *netmodels.Offer {
+	for _, o := range c.offers.List() {
+		if o.ID == c.id {
+			return o
+		}
+	}
+	return nil
+}
+
+// SetCopied reports the copy of the bolt12 string to the clipboard.
+func (c *Offer) SetCopied(err error) {
+	c.copied = err == nil
+	c.err = err
+}
+
+func (c Offer) Name() string {
+	return OFFER
+}
+
+func (c *Offer) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Offer) Origin() (int, int) {
+	return c.view.Origin()
+}
+
+func (c Offer) Cursor() (int, int) {
+	return c.view.Cursor()
+}
+
+func (c Offer) Speed() (int, int, int, int) {
+	return 1, 1, 1, 1
+}
+
+func (c Offer) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = len(c.view.BufferLines()) - 1
+	return
+}
+
+func (c *Offer) SetCursor(x, y int) error {
+	return c.view.SetCursor(x, y)
+}
+
+func (c *Offer) SetOrigin(x, y int) error {
+	return c.view.SetOrigin(x, y)
+}
+
+func (c *Offer) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(OFFER, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " Offer - ctrl+y to copy it, esc to close "
+	c.view = v
+	c.display()
+
+	_, err = g.SetCurrentView(OFFER)
+	return err
+}
+
+func (c Offer) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(OFFER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func (c *Offer) display() {
+	p := message.NewPrinter(language.English)
+	v := c.view
+	ox, oy := v.Origin()
+	v.Clear()
+	v.SetOrigin(ox, oy)
+	offer := c.Current()
+	if offer == nil {
+		return
+	}
+	green := color.Green()
+	cyan := color.Cyan()
+	fmt.Fprintln(v, green(" [ Offer ]"))
+	fmt.Fprintf(v, "%s %s\n", cyan("          ID:"), offer.ID)
+	fmt.Fprintf(v, "%s %s\n", cyan("      Status:"), offer.StatusName())
+	amount := "any"
+	if offer.Amount > 0 {
+		amount = p.Sprintf("%d sat", offer.Amount)
+	}
+	fmt.Fprintf(v, "%s %s\n", cyan("      Amount:"), amount)
+	fmt.Fprintf(v, "%s %s\n", cyan(" Description:"), offer.Description)
+	if offer.SingleUse {
+		fmt.Fprintf(v, "%s yes\n", cyan("  Single use:"))
+	}
+	if offer.Label != "" {
+		fmt.Fprintf(v, "%s %s\n", cyan("       Label:"), offer.Label)
+	}
+	n, received, _ := offer.Paid()
+	fmt.Fprintf(v, "%s %s\n", cyan("    Received:"),
+		p.Sprintf("%d sat for %d of %d requests", received, n, len(offer.Requests)))
+
+	fmt.Fprintln(v, "")
+	fmt.Fprintln(v, green(" [ Bolt12 ]"))
+	fmt.Fprintln(v, offer.Bolt12)
+	switch {
+	case c.err != nil:
+		fmt.Fprintln(v, color.Red()(c.err.Error()))
+	case c.copied:
+		fmt.Fprintln(v, "offer copied to the clipboard")
+	}
+
+	fmt.Fprintln(v, "")
+	fmt.Fprintln(v, green(fmt.Sprintf(" [ Invoice Requests ] %d requests", len(offer.Requests))))
+	for _, r := range offer.Requests {
+		state := color.Yellow()(fmt.Sprintf("%-15s", "unpaid"))
+		if r.Paid {
+			state = color.Green()(fmt.Sprintf("%-15s", r.PaidAt.Format("15:04:05 Jan _2")))
+		}
+		amount := r.Amount
+		if r.Paid {
+			amount = r.AmountPaid
+		}
+		fmt.Fprintf(v, " %s %s %s %s\n",
+			cyan(runewidth.Truncate(r.PaymentHash, 16, "")),
+			color.Yellow()(p.Sprintf("%12d", amount)), state, r.PayerNote)
+	}
+}
+
+func NewOffer(offers *models.Offers) *Offer {
+	return &Offer{offers: offers}
+}
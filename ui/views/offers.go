@@ -0,0 +1,461 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	OFFERS         = "offers"
+	OFFERS_COLUMNS = "offers_columns"
+	OFFERS_FOOTER  = "offers_footer"
+)
+
+var DefaultOffersColumns = []string{
+	"ID",
+	"STATUS",
+	"AMOUNT",
+	"REQUESTS",
+	"PAID",
+	"RECEIVED",
+	"LAST_PAID",
+	"DESCRIPTION",
+}
+
+type Offers struct {
+	cfg *config.View
+
+	columns           []offersColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	offers            *models.Offers
+
+	ox, oy int
+	cx, cy int
+	// rows is the number of offers of the last display, failed is true
+	// if it showed the error of the list instead.
+	rows   int
+	failed bool
+}
+
+type offersColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.OffersSort
+	display func(*netmodels.Offer, ...color.Option) string
+}
+
+func (c Offers) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Offers) Name() string {
+	return OFFERS
+}
+
+func (c *Offers) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Offers) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Offers) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Offers) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Offers) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Offers) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Offers) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.offers.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Offers) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.offers.Len()
+	return
+}
+
+func (c *Offers) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.offers.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Offers) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(OFFERS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(OFFERS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(OFFERS_FOOTER)
+}
+
+func (c *Offers) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(OFFERS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(OFFERS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(OFFERS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("Enter"), "Detail",
+		blackBg("c"), "Create",
+		blackBg("x"), "Disable",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+func (c *Offers) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.offers.List()
+	failed := len(list) == 0 && c.offers.Err() != nil
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once the list shrank or its error is shown or gone.
+	if len(list) < c.rows || failed != c.failed {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	c.failed = failed
+	if failed {
+		fmt.Fprintln(c.view, color.Red()(fmt.Sprintf("cannot list the offers: %s", c.offers.Err())))
+		return
+	}
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func NewOffers(cfg *config.View, offers *models.Offers) *Offers {
+	view := &Offers{
+		cfg:    cfg,
+		offers: offers,
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	columns := DefaultOffersColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]offersColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "ID":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%-16s", columns[i]),
+				width: 16,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						return models.StringSort(o1.ID, o2.ID, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(o.ID, 16, ""), 16))
+				},
+			}
+		case "STATUS":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%-8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						return models.IntSort(o1.Status, o2.Status, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					text := fmt.Sprintf("%-8s", o.StatusName())
+					switch o.Status {
+					case netmodels.OfferActive:
+						return color.Green(opts...)(text)
+					case netmodels.OfferDisabled:
+						return color.Red(opts...)(text)
+					}
+					return color.Yellow(opts...)(text)
+				},
+			}
+		case "AMOUNT":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						return models.Int64Sort(o1.Amount, o2.Amount, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					if o.Amount == 0 {
+						return color.White(opts...)(fmt.Sprintf("%12s", "any"))
+					}
+					return color.White(opts...)(printer.Sprintf("%12d", o.Amount))
+				},
+			}
+		case "REQUESTS":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						return models.IntSort(len(o1.Requests), len(o2.Requests), order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", len(o.Requests)))
+				},
+			}
+		case "PAID":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%5s", columns[i]),
+				width: 5,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						n1, _, _ := o1.Paid()
+						n2, _, _ := o2.Paid()
+						return models.IntSort(n1, n2, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					n, _, _ := o.Paid()
+					return color.White(opts...)(fmt.Sprintf("%5d", n))
+				},
+			}
+		case "RECEIVED":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						_, a1, _ := o1.Paid()
+						_, a2, _ := o2.Paid()
+						return models.Int64Sort(a1, a2, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					_, amount, _ := o.Paid()
+					if amount == 0 {
+						return color.White(opts...)(fmt.Sprintf("%12d", 0))
+					}
+					return color.Green(opts...)(printer.Sprintf("%12d", amount))
+				},
+			}
+		case "LAST_PAID":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						_, _, t1 := o1.Paid()
+						_, _, t2 := o2.Paid()
+						return models.DateSort(&t1, &t2, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					_, _, last := o.Paid()
+					if last.IsZero() {
+						return color.White(opts...)(fmt.Sprintf("%15s", "-"))
+					}
+					return color.Cyan(opts...)(fmt.Sprintf("%15s", last.Format("15:04:05 Jan _2")))
+				},
+			}
+		case "DESCRIPTION":
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.OffersSort {
+					return func(o1, o2 *netmodels.Offer) bool {
+						return models.StringSort(o1.Description, o2.Description, order)
+					}
+				},
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(o.Description, 30, ""), 30))
+				},
+			}
+		default:
+			view.columns[i] = offersColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(o *netmodels.Offer, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+
+	return view
+}
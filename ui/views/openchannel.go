@@ -0,0 +1,237 @@
+package views
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	OPEN_CHANNEL          = "open_channel"
+	OPEN_CHANNEL_PUBKEY   = "open_channel_pubkey"
+	OPEN_CHANNEL_AMOUNT   = "open_channel_amount"
+	OPEN_CHANNEL_FEE_RATE = "open_channel_fee_rate"
+	OPEN_CHANNEL_PRIVATE  = "open_channel_private"
+)
+
+// ChannelRequest is the channel to open of the dialog, the wallet
+// estimates the fee rate if it is zero.
+type ChannelRequest struct {
+	PubKey      string
+	Amount      int64
+	SatPerVbyte uint64
+	Private     bool
+}
+
+// OpenChannel is the dialog opening a channel with a connected peer, the
+// first enter asks for a confirmation and the second one opens it.
+type OpenChannel struct {
+	form
+	visible   bool
+	confirmed *ChannelRequest
+	alias     string
+	// opening is true while the channel is opened.
+	opening bool
+	err     error
+}
+
+func (o *OpenChannel) Visible() bool {
+	return o.visible
+}
+
+// Show opens the dialog with the pubkey of the peer, empty if none is
+// selected, and the fee rate, empty if it is zero.
+func (o *OpenChannel) Show(pubkey string, rate uint64) {
+	o.reset(pubkey, "", formatFeeRate(rate), "no")
+	o.confirmed = nil
+	o.opening = false
+	o.err = nil
+	o.visible = true
+}
+
+func (o *OpenChannel) Hide() {
+	o.visible = false
+	o.confirmed = nil
+	o.opening = false
+	o.err = nil
+}
+
+// Start marks the confirmed channel as opened until its result is set.
+func (o *OpenChannel) Start() {
+	o.opening = true
+}
+
+// Opening returns true while the channel is opened.
+func (o *OpenChannel) Opening() bool {
+	return o.opening
+}
+
+// Value returns the channel of the fields.
+func (o *OpenChannel) Value() (*ChannelRequest, error) {
+	pubkey := o.inputs[0].Value()
+	err := validatePubKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(o.inputs[1].Value())
+	if err != nil {
+		return nil, err
+	}
+	rate := uint64(0)
+	if s := o.inputs[2].Value(); s != "" {
+		rate, err = parseFeeRate(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	private, err := parseYesNo(o.inputs[3].Value())
+	if err != nil {
+		return nil, errors.Errorf("private: %s", err)
+	}
+	return &ChannelRequest{PubKey: pubkey, Amount: amount, SatPerVbyte: rate, Private: private}, nil
+}
+
+// Confirm returns true if the channel was confirmed by the previous enter,
+// otherwise it asks to confirm it, the alias is the one of the peer.
+func (o *OpenChannel) Confirm(req *ChannelRequest, alias string) bool {
+	if o.confirmed != nil && *o.confirmed == *req {
+		return true
+	}
+	o.confirmed = req
+	o.alias = alias
+	o.err = nil
+	return false
+}
+
+// SetError sets the error of the fields or of the opening, the dialog
+// stays open.
+func (o *OpenChannel) SetError(err error) {
+	o.confirmed = nil
+	o.opening = false
+	o.err = err
+}
+
+func (o *OpenChannel) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(o.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := o.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(OPEN_CHANNEL, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " open channel "
+	o.display(v)
+	return nil
+}
+
+func (o *OpenChannel) display(v *gocui.View) {
+	v.Clear()
+	if o.err != nil {
+		fmt.Fprintln(v, color.Red()(o.err.Error()))
+		return
+	}
+	if o.opening {
+		fmt.Fprintln(v, color.Yellow()("opening..."))
+		return
+	}
+	if o.confirmed != nil {
+		peer := o.confirmed.PubKey
+		if o.alias != "" {
+			peer = o.alias
+		}
+		kind := "public"
+		if o.confirmed.Private {
+			kind = "private"
+		}
+		rate := "the fee rate estimated by the wallet"
+		if o.confirmed.SatPerVbyte > 0 {
+			rate = fmt.Sprintf("%d sat/vbyte", o.confirmed.SatPerVbyte)
+		}
+		fmt.Fprintf(v, "open a %s %s channel with %s at %s?\n",
+			kind, color.Yellow(color.Bold)(formatAmount(o.confirmed.Amount)+" sat"),
+			color.Cyan()(peer), rate)
+		fmt.Fprintln(v, "press enter again to open it, esc to close")
+		return
+	}
+	fmt.Fprintln(v, "the peer must be connected, the wallet estimates the fee rate if empty")
+	fmt.Fprintln(v, "tab moves to the next field, enter opens the channel, esc to close")
+}
+
+func (o *OpenChannel) Delete(g *gocui.Gui) error {
+	err := o.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(OPEN_CHANNEL)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func validatePubKey(s string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 33 {
+		return errors.New("not a node public key")
+	}
+	return nil
+}
+
+func parseAmount(s string) (int64, error) {
+	amount, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, errors.New("not an amount in sat")
+	}
+	return amount, nil
+}
+
+func validateAmount(s string) error {
+	_, err := parseAmount(s)
+	return err
+}
+
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "y", "yes":
+		return true, nil
+	case "", "n", "no":
+		return false, nil
+	}
+	return false, errors.New("not yes or no")
+}
+
+func validateYesNo(s string) error {
+	_, err := parseYesNo(s)
+	return err
+}
+
+func NewOpenChannel() *OpenChannel {
+	return &OpenChannel{form: form{inputs: []*Input{
+		NewInput(OPEN_CHANNEL_PUBKEY, " node pubkey ", validatePubKey),
+		NewInput(OPEN_CHANNEL_AMOUNT, " local amount (sat) ", validateAmount),
+		NewInput(OPEN_CHANNEL_FEE_RATE, " fee rate (sat/vbyte) ", validateFeeRate),
+		NewInput(OPEN_CHANNEL_PRIVATE, " private (yes/no) ", validateYesNo),
+	}}}
+}
@@ -0,0 +1,192 @@
+package views
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	PAY       = "pay"
+	PAY_INPUT = "pay_input"
+)
+
+// spinner are the frames of the spinner of a payment in flight.
+var spinner = []string{"-", "\\", "|", "/"}
+
+// Pay is the popup paying a BOLT11 payment request pasted in its input,
+// the first enter decodes it and asks for a confirmation, the second one
+// pays it.
+type Pay struct {
+	input   *Input
+	visible bool
+	payreq  *netmodels.PayReq
+	alias   string
+	// start is the start of the payment, zero until it is sent.
+	start   time.Time
+	payment *netmodels.Payment
+	err     error
+}
+
+func (p *Pay) Visible() bool {
+	return p.visible
+}
+
+func (p *Pay) Show() {
+	p.input.SetValue("")
+	p.payreq = nil
+	p.start = time.Time{}
+	p.payment = nil
+	p.err = nil
+	p.visible = true
+}
+
+func (p *Pay) Hide() {
+	p.visible = false
+}
+
+// Value returns the payment request of the input.
+func (p *Pay) Value() string {
+	return p.input.Value()
+}
+
+// Pasting returns true while a payment request is pasted in the input.
+func (p *Pay) Pasting() bool {
+	return p.input.Pasting()
+}
+
+// Sent returns true once the payment is sent.
+func (p *Pay) Sent() bool {
+	return !p.start.IsZero()
+}
+
+// Confirm returns true if the payment request was confirmed by the
+// previous enter, otherwise it asks to confirm it, the alias is the one
+// of its destination.
+func (p *Pay) Confirm(payreq *netmodels.PayReq, alias string) bool {
+	if p.payreq != nil && p.payreq.String == payreq.String {
+		return true
+	}
+	p.payreq = payreq
+	p.alias = alias
+	p.err = nil
+	if payreq.Amount == 0 {
+		p.SetError(errors.New("invoice without amount"))
+	}
+	return false
+}
+
+// Start displays the payment in flight.
+func (p *Pay) Start() {
+	p.start = time.Now()
+}
+
+// SetResult sets the payment once it succeeded or failed.
+func (p *Pay) SetResult(payment *netmodels.Payment, err error) {
+	p.payment = payment
+	p.err = err
+}
+
+// SetError sets the error of the payment request, the popup stays open.
+func (p *Pay) SetError(err error) {
+	p.payreq = nil
+	p.err = err
+}
+
+func (p *Pay) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 110
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+10 > maxY {
+		y0 = 0
+	}
+
+	err := p.input.Set(g, x0, y0, x0+width, y0+2)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(PAY, x0, y0+3, x0+width, y0+10, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " pay "
+	p.display(v)
+	return nil
+}
+
+func (p *Pay) display(v *gocui.View) {
+	v.Clear()
+	if p.err != nil {
+		fmt.Fprintln(v, color.Red()(p.err.Error()))
+		if p.Sent() {
+			fmt.Fprintln(v, "esc to close")
+		}
+		return
+	}
+	if p.payreq == nil {
+		fmt.Fprintln(v, "paste or type a BOLT11 invoice and press enter, esc to close")
+		return
+	}
+
+	destination := p.payreq.Destination
+	if p.alias != "" {
+		destination = p.alias
+	}
+	amount := color.Yellow(color.Bold)(formatAmount(p.payreq.Amount) + " sat")
+	switch {
+	case p.payment != nil && p.payment.Status == netmodels.PaymentSucceeded:
+		fmt.Fprintf(v, "%s %s to %s, fee %d sat", color.Green(color.Bold)("paid"),
+			amount, color.Cyan()(destination), p.payment.Fee)
+		if p.payment.Route != nil {
+			fmt.Fprintf(v, " over %d hops", len(p.payment.Route.Hops))
+		}
+		fmt.Fprintln(v)
+		fmt.Fprintf(v, "%s %s\n", color.Cyan()("preimage"), hex.EncodeToString(p.payment.PaymentPreimage))
+		fmt.Fprintln(v, "esc to close")
+	case p.payment != nil:
+		fmt.Fprintf(v, "%s %s to %s: %s\n", color.Red(color.Bold)("failed to pay"),
+			amount, color.Cyan()(destination), p.payment.PaymentError)
+		fmt.Fprintln(v, "esc to close")
+	case p.Sent():
+		frame := spinner[int(time.Since(p.start)/(100*time.Millisecond))%len(spinner)]
+		fmt.Fprintf(v, "%s paying %s to %s, %ds\n", color.Yellow()(frame),
+			amount, color.Cyan()(destination), int(time.Since(p.start).Seconds()))
+		fmt.Fprintln(v, "esc closes the popup, the payment goes on")
+	default:
+		fmt.Fprintf(v, "pay %s to %s?\n", amount, color.Cyan()(destination))
+		if p.payreq.Description != "" {
+			fmt.Fprintf(v, "%s %s\n", color.Cyan()("description"), p.payreq.Description)
+		}
+		fmt.Fprintln(v, "press enter again to pay it, esc to cancel")
+	}
+}
+
+func (p *Pay) Delete(g *gocui.Gui) error {
+	err := p.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(PAY)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewPay() *Pay {
+	return &Pay{input: NewInput(PAY_INPUT, " BOLT11 invoice ", validatePayReq)}
+}
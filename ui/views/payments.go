@@ -0,0 +1,465 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	PAYMENTS         = "payments"
+	PAYMENTS_COLUMNS = "payments_columns"
+	PAYMENTS_FOOTER  = "payments_footer"
+)
+
+var DefaultPaymentsColumns = []string{
+	"DATE",
+	"STATUS",
+	"DESTINATION",
+	"AMOUNT",
+	"FEE",
+	"HOPS",
+	"FAILURE",
+}
+
+type Payments struct {
+	cfg *config.View
+
+	columns           []paymentsColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	payments          *models.Payments
+
+	ox, oy int
+	cx, cy int
+}
+
+type paymentsColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.PaymentsSort
+	display func(*netmodels.Payment, ...color.Option) string
+}
+
+func (c Payments) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Payments) Name() string {
+	return PAYMENTS
+}
+
+func (c *Payments) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Payments) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Payments) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Payments) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Payments) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Payments) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Payments) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.payments.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Payments) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.payments.Len()
+	return
+}
+
+func (c *Payments) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.payments.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Payments) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(PAYMENTS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(PAYMENTS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(PAYMENTS_FOOTER)
+}
+
+func (c *Payments) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(PAYMENTS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(PAYMENTS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(PAYMENTS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("p"), "Pay",
+		blackBg("k"), "Keysend",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+func (c *Payments) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	c.view.Rewind()
+	for _, item := range c.payments.List() {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// alias returns the alias of the node, its shortened pubkey if it is
+// unknown.
+func (c *Payments) alias(pubkey string) string {
+	if alias := c.payments.Alias(pubkey); alias != "" {
+		return alias
+	}
+	if len(pubkey) > 16 {
+		return pubkey[:16]
+	}
+	return pubkey
+}
+
+func paymentStatus(p *netmodels.Payment, opts ...color.Option) string {
+	text := fmt.Sprintf("%-9s", p.StatusName())
+	switch p.Status {
+	case netmodels.PaymentSucceeded:
+		return color.Green(opts...)(text)
+	case netmodels.PaymentFailed:
+		return color.Red(opts...)(text)
+	}
+	return color.Yellow(opts...)(text)
+}
+
+func NewPayments(cfg *config.View, payments *models.Payments) *Payments {
+	view := &Payments{
+		cfg:      cfg,
+		payments: payments,
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	columns := DefaultPaymentsColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]paymentsColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "DATE":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-15s", columns[i]),
+				width: 15,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.DateSort(&p1.CreationDate, &p2.CreationDate, order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return color.Cyan(opts...)(
+						fmt.Sprintf("%15s", p.CreationDate.Format("15:04:05 Jan _2")),
+					)
+				},
+			}
+		case "STATUS":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-9s", columns[i]),
+				width: 9,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.IntSort(p1.Status, p2.Status, order)
+					}
+				},
+				display: paymentStatus,
+			}
+		case "DESTINATION":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-25s", columns[i]),
+				width: 25,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.StringSort(view.alias(p1.Destination()), view.alias(p2.Destination()), order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					alias := view.alias(p.Destination())
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(alias, 25, ""), 25))
+				},
+			}
+		case "AMOUNT":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.Int64Sort(p1.Amount, p2.Amount, order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", p.Amount))
+				},
+			}
+		case "FEE":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.Int64Sort(p1.Fee, p2.Fee, order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%8d", p.Fee))
+				},
+			}
+		case "HOPS":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%4s", columns[i]),
+				width: 4,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.IntSort(paymentHops(p1), paymentHops(p2), order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					if p.Route == nil {
+						return color.White(opts...)(fmt.Sprintf("%4s", "-"))
+					}
+					return color.White(opts...)(fmt.Sprintf("%4d", paymentHops(p)))
+				},
+			}
+		case "FAILURE":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-26s", columns[i]),
+				width: 26,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.StringSort(p1.PaymentError, p2.PaymentError, order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return color.Red(opts...)(runewidth.FillRight(runewidth.Truncate(p.PaymentError, 26, ""), 26))
+				},
+			}
+		case "ROUTE":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-60s", columns[i]),
+				width: 60,
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					aliases := []string{}
+					if p.Route != nil {
+						for _, hop := range p.Route.Hops {
+							aliases = append(aliases, view.alias(hop.PubKey))
+						}
+					}
+					route := strings.Join(aliases, " > ")
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(route, 60, "…"), 60))
+				},
+			}
+		case "HASH":
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-64s", columns[i]),
+				width: 64,
+				sort: func(order models.Order) models.PaymentsSort {
+					return func(p1, p2 *netmodels.Payment) bool {
+						return models.StringSort(p1.Hash, p2.Hash, order)
+					}
+				},
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-64s", p.Hash))
+				},
+			}
+		default:
+			view.columns[i] = paymentsColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(p *netmodels.Payment, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+
+	return view
+}
+
+// paymentHops returns the number of hops of the route of the payment.
+func paymentHops(p *netmodels.Payment) int {
+	if p.Route == nil {
+		return 0
+	}
+	return len(p.Route.Hops)
+}
@@ -0,0 +1,509 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	PEERS         = "peers"
+	PEERS_COLUMNS = "peers_columns"
+	PEERS_FOOTER  = "peers_footer"
+)
+
+var DefaultPeersColumns = []string{
+	"SEL",
+	"ALIAS",
+	"ADDRESS",
+	"DIR",
+	"PING",
+	"SAT_SENT",
+	"SAT_RECV",
+	"BYTES_SENT",
+	"BYTES_RECV",
+	"FLAPS",
+	"LAST ERROR",
+}
+
+type Peers struct {
+	cfg *config.View
+
+	columns           []peersColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	peers             *models.Peers
+
+	ox, oy int
+	cx, cy int
+	// rows is the number of peers of the last display.
+	rows int
+}
+
+type peersColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.PeersSort
+	display func(*netmodels.Peer, ...color.Option) string
+}
+
+func (c Peers) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Peers) Name() string {
+	return PEERS
+}
+
+func (c *Peers) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Peers) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Peers) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Peers) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Peers) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Peers) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Peers) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.peers.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Peers) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.peers.Len()
+	return
+}
+
+func (c *Peers) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.peers.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Peers) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(PEERS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(PEERS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(PEERS_FOOTER)
+}
+
+func (c *Peers) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(PEERS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(PEERS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(PEERS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s%s %d selected",
+		blackBg("F2"), "Menu",
+		blackBg("space"), "Select",
+		blackBg("O"), "Batch open",
+		blackBg("c"), "Connect",
+		blackBg("x"), "Disconnect",
+		blackBg("F10"), "Quit",
+		len(c.peers.Selected()),
+	))
+	return nil
+}
+
+func (c *Peers) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.peers.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once a peer disconnects.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func peerAlias(p *netmodels.Peer) string {
+	if p.Node == nil {
+		return ""
+	}
+	if p.Node.ForcedAlias != "" {
+		return p.Node.ForcedAlias
+	}
+	return p.Node.Alias
+}
+
+func NewPeers(cfg *config.View, peers *models.Peers) *Peers {
+	view := &Peers{
+		cfg:   cfg,
+		peers: peers,
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	columns := DefaultPeersColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]peersColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "SEL":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-3s", columns[i]),
+				width: 3,
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					if peers.IsSelected(p.PubKey) {
+						return color.Green(opts...)("[x]")
+					}
+					return color.White(opts...)("[ ]")
+				},
+			}
+		case "ALIAS":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-25s", columns[i]),
+				width: 25,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.StringSort(peerAlias(p1), peerAlias(p2), order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					alias := peerAlias(p)
+					if alias == "" {
+						alias = p.PubKey
+					}
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(alias, 25, ""), 25))
+				},
+			}
+		case "PUBKEY":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-66s", columns[i]),
+				width: 66,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.StringSort(p1.PubKey, p2.PubKey, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-66s", p.PubKey))
+				},
+			}
+		case "ADDRESS":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.StringSort(p1.Address, p2.Address, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(p.Address, 30, "…"), 30))
+				},
+			}
+		case "DIR":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-3s", columns[i]),
+				width: 3,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.BoolSort(p1.Inbound, p2.Inbound, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					if p.Inbound {
+						return color.White(opts...)("in ")
+					}
+					return color.White(opts...)("out")
+				},
+			}
+		case "PING":
+			latency := latencyColumn(cfg, "PING")
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.Int64Sort(int64(p1.PingTime), int64(p2.PingTime), order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return latency(p.PingTime, opts...)
+				},
+			}
+		case "FLAPS":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%5s", columns[i]),
+				width: 5,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.IntSort(int(p1.FlapCount), int(p2.FlapCount), order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					text := fmt.Sprintf("%5d", p.FlapCount)
+					if p.FlapCount > 0 {
+						return color.Yellow(opts...)(text)
+					}
+					return color.White(opts...)(text)
+				},
+			}
+		case "SAT_SENT":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%13s", columns[i]),
+				width: 13,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.Int64Sort(p1.SatSent, p2.SatSent, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%13d", p.SatSent))
+				},
+			}
+		case "SAT_RECV":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%13s", columns[i]),
+				width: 13,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.Int64Sort(p1.SatRecv, p2.SatRecv, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%13d", p.SatRecv))
+				},
+			}
+		case "BYTES_SENT":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.UInt64Sort(p1.BytesSent, p2.BytesSent, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%10s", FormatBytes(p.BytesSent)))
+				},
+			}
+		case "BYTES_RECV":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.UInt64Sort(p1.BytesRecv, p2.BytesRecv, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%10s", FormatBytes(p.BytesRecv)))
+				},
+			}
+		case "LAST ERROR":
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-60s", columns[i]),
+				width: 60,
+				sort: func(order models.Order) models.PeersSort {
+					return func(p1, p2 *netmodels.Peer) bool {
+						return models.DateSort(&p1.LastErrorTime, &p2.LastErrorTime, order)
+					}
+				},
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					if p.LastError == "" {
+						return fmt.Sprintf("%-60s", "")
+					}
+					text := fmt.Sprintf("%s %s", p.LastErrorTime.Format("Jan _2 15:04"), p.LastError)
+					return color.Red(opts...)(runewidth.FillRight(runewidth.Truncate(text, 60, "…"), 60))
+				},
+			}
+		default:
+			view.columns[i] = peersColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(p *netmodels.Peer, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
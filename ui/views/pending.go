@@ -0,0 +1,612 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	PENDING         = "pending"
+	PENDING_COLUMNS = "pending_columns"
+	PENDING_FOOTER  = "pending_footer"
+)
+
+var DefaultPendingColumns = []string{
+	"STATUS",
+	"ALIAS",
+	"CAP",
+	"LOCAL",
+	"CONFS",
+	"EXPIRY",
+	"MATURITY",
+	"BLOCKS_LEFT",
+	"ETA",
+	"LIMBO",
+}
+
+// PendingChannels is the view of the channels opening and closing: the
+// confirmations of the funding of the opening channels with the blocks
+// before it expires, and the blocks before the outputs of the force
+// closed channels mature.
+type PendingChannels struct {
+	cfg     *config.View
+	printer *message.Printer
+
+	columns           []pendingColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	pending           *models.PendingChannels
+	// rows is the number of channels of the last display.
+	rows int
+
+	ox, oy int
+	cx, cy int
+}
+
+type pendingColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.PendingChannelsSort
+	display func(*models.PendingChannel, ...color.Option) string
+}
+
+func (c PendingChannels) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c PendingChannels) Name() string {
+	return PENDING
+}
+
+func (c *PendingChannels) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c PendingChannels) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c PendingChannels) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c PendingChannels) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *PendingChannels) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *PendingChannels) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *PendingChannels) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.pending.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *PendingChannels) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.pending.Len()
+	return
+}
+
+func (c *PendingChannels) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.pending.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c PendingChannels) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(PENDING_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(PENDING)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(PENDING_FOOTER)
+}
+
+func (c *PendingChannels) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(PENDING_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(PENDING, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(PENDING_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("Enter"), "Channel",
+		blackBg("F10"), "Quit",
+		c.summary(),
+	))
+	return nil
+}
+
+// summary returns the counts of the pending channels by status and their
+// balance in limbo.
+func (c *PendingChannels) summary() string {
+	var opening, closing, forceClosing int
+	for _, p := range c.pending.List() {
+		switch p.Status {
+		case netmodels.ChannelOpening:
+			opening++
+		case netmodels.ChannelForceClosing:
+			forceClosing++
+		default:
+			closing++
+		}
+	}
+	return c.printer.Sprintf("%d opening, %d closing, %d force closing, %d sat in limbo",
+		opening, closing, forceClosing, c.pending.Limbo())
+}
+
+func (c *PendingChannels) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.pending.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once a channel is resolved.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// pendingFundingExpiry returns the blocks before the funding of the
+// opening channel expires, false if it is not reported.
+func pendingFundingExpiry(p *models.PendingChannel) (int32, bool) {
+	return p.FundingExpiryBlocks, p.Status == netmodels.ChannelOpening && p.FundingExpiryBlocks != 0
+}
+
+// pendingMaturity returns the blocks before the outputs of the commitment
+// of the force closed channel mature, false for the other channels.
+func pendingMaturity(p *models.PendingChannel) (int32, bool) {
+	return p.BlocksTilMaturity, p.Status == netmodels.ChannelForceClosing && p.Closing != nil
+}
+
+// pendingClosing returns the closing of the channel, empty for an opening
+// one.
+func pendingClosing(p *models.PendingChannel) netmodels.Closing {
+	if p.Closing == nil {
+		return netmodels.Closing{}
+	}
+	return *p.Closing
+}
+
+func NewPendingChannels(cfg *config.View, pending *models.PendingChannels) *PendingChannels {
+	printer := message.NewPrinter(language.English)
+	view := &PendingChannels{
+		cfg:     cfg,
+		printer: printer,
+		pending: pending,
+	}
+
+	columns := DefaultPendingColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]pendingColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "STATUS":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-13s", columns[i]),
+				width: 13,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.IntSort(p1.Status, p2.Status, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return status(p.Channel, opts...)
+				},
+			}
+		case "ALIAS":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-25s", columns[i]),
+				width: 25,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						a1, _ := p1.ShortAlias()
+						a2, _ := p2.ShortAlias()
+						return models.StringSort(a1, a2, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					aliasColor := color.White(opts...)
+					alias, forced := p.ShortAlias()
+					if forced {
+						aliasColor = color.Cyan(opts...)
+					}
+					return aliasColor(fmt.Sprintf("%-25s", alias))
+				},
+			}
+		case "CAP":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.Int64Sort(p1.Capacity, p2.Capacity, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", p.Capacity))
+				},
+			}
+		case "LOCAL":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.Int64Sort(p1.LocalBalance, p2.LocalBalance, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return color.Cyan(opts...)(printer.Sprintf("%12d", p.LocalBalance))
+				},
+			}
+		case "CONFS":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%5s", columns[i]),
+				width: 5,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.Int32Sort(p1.Confirmations, p2.Confirmations, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					if p.Status != netmodels.ChannelOpening || p.Confirmations < 0 {
+						return fmt.Sprintf("%5s", "")
+					}
+					if p.Confirmations == 0 {
+						return color.Yellow(opts...)(fmt.Sprintf("%5d", p.Confirmations))
+					}
+					return color.Green(opts...)(fmt.Sprintf("%5d", p.Confirmations))
+				},
+			}
+		case "EXPIRY":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%6s", columns[i]),
+				width: 6,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						b1, _ := pendingFundingExpiry(p1)
+						b2, _ := pendingFundingExpiry(p2)
+						return models.Int32Sort(b1, b2, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					blocks, ok := pendingFundingExpiry(p)
+					if !ok {
+						return fmt.Sprintf("%6s", "")
+					}
+					// the peer may cancel the funding once expired, it
+					// should be bumped before.
+					if blocks < 144 {
+						return color.Red(opts...)(fmt.Sprintf("%6d", blocks))
+					}
+					return color.White(opts...)(fmt.Sprintf("%6d", blocks))
+				},
+			}
+		case "MATURITY":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.UInt32Sort(pendingClosing(p1).MaturityHeight, pendingClosing(p2).MaturityHeight, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					height := pendingClosing(p).MaturityHeight
+					if height == 0 {
+						return fmt.Sprintf("%8s", "")
+					}
+					return color.White(opts...)(fmt.Sprintf("%8d", height))
+				},
+			}
+		case "BLOCKS_LEFT":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%11s", columns[i]),
+				width: 11,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						b1, _ := pendingMaturity(p1)
+						b2, _ := pendingMaturity(p2)
+						return models.Int32Sort(b1, b2, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					blocks, ok := pendingMaturity(p)
+					if !ok {
+						return fmt.Sprintf("%11s", "")
+					}
+					if blocks <= 0 {
+						return color.Green(opts...)(fmt.Sprintf("%11s", "matured"))
+					}
+					return color.Yellow(opts...)(fmt.Sprintf("%11d", blocks))
+				},
+			}
+		case "ETA":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						b1, _ := pendingMaturity(p1)
+						b2, _ := pendingMaturity(p2)
+						return models.Int32Sort(b1, b2, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					blocks, ok := pendingMaturity(p)
+					if !ok || blocks <= 0 {
+						return fmt.Sprintf("%10s", "")
+					}
+					return color.White(opts...)(fmt.Sprintf("%10s", "~"+FormatAge(uint32(blocks))))
+				},
+			}
+		case "LIMBO":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.Int64Sort(pendingClosing(p1).LimboBalance, pendingClosing(p2).LimboBalance, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					if p.Closing == nil {
+						return fmt.Sprintf("%12s", "")
+					}
+					return color.Yellow(opts...)(printer.Sprintf("%12d", p.Closing.LimboBalance))
+				},
+			}
+		case "RECOVERED":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.Int64Sort(pendingClosing(p1).RecoveredBalance, pendingClosing(p2).RecoveredBalance, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					if p.Closing == nil {
+						return fmt.Sprintf("%12s", "")
+					}
+					return color.Green(opts...)(printer.Sprintf("%12d", p.Closing.RecoveredBalance))
+				},
+			}
+		case "HTLCS":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%5s", columns[i]),
+				width: 5,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.IntSort(len(pendingClosing(p1).PendingHTLCs), len(pendingClosing(p2).PendingHTLCs), order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					n := len(pendingClosing(p).PendingHTLCs)
+					if n == 0 {
+						return fmt.Sprintf("%5s", "")
+					}
+					return color.Yellow(opts...)(fmt.Sprintf("%5d", n))
+				},
+			}
+		case "CLOSE_TYPE":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-16s", columns[i]),
+				width: 16,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.StringSort(netmodels.CloseTypeName(p1.CloseType),
+							netmodels.CloseTypeName(p2.CloseType), order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-16s", netmodels.CloseTypeName(p.CloseType)))
+				},
+			}
+		case "CLOSING_TXID":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-64s", columns[i]),
+				width: 64,
+				sort: func(order models.Order) models.PendingChannelsSort {
+					return func(p1, p2 *models.PendingChannel) bool {
+						return models.StringSort(pendingClosing(p1).ClosingTxID, pendingClosing(p2).ClosingTxID, order)
+					}
+				},
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-64s", pendingClosing(p).ClosingTxID))
+				},
+			}
+		case "CHANNEL_POINT":
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-68s", columns[i]),
+				width: 68,
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-68s", p.ChannelPoint))
+				},
+			}
+		default:
+			view.columns[i] = pendingColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(p *models.PendingChannel, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
@@ -0,0 +1,301 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	PLUGIN_PREFIX   = "plugin_"
+	pluginMaxWidth  = 40
+	pluginMenuWidth = 7
+)
+
+// Plugin displays the table rendered by an external plugin.
+type Plugin struct {
+	name              string
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	plugins           *models.Plugins
+
+	widths []int
+
+	ox, oy int
+	cx, cy int
+}
+
+func (c Plugin) Name() string {
+	return PLUGIN_PREFIX + c.name
+}
+
+// MenuLabel is the label of the plugin in the menu.
+func (c Plugin) MenuLabel() string {
+	label := strings.ToUpper(c.name)
+	if runewidth.StringWidth(label) > pluginMenuWidth {
+		label = runewidth.Truncate(label, pluginMenuWidth, "")
+	}
+	return label
+}
+
+func (c *Plugin) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Plugin) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Plugin) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Plugin) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Plugin) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Plugin) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Plugin) rows() [][]string {
+	result := c.plugins.Result(c.name)
+	if result == nil {
+		return nil
+	}
+	return result.Rows
+}
+
+func (c Plugin) currentColumnIndex() int {
+	x := c.ox + c.cx
+	sum := 0
+	for i := range c.widths {
+		sum += c.widths[i] + 1
+		if x < sum {
+			return i
+		}
+	}
+	return len(c.widths)
+}
+
+func (c *Plugin) Speed() (int, int, int, int) {
+	up, down := 0, 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < len(c.rows())-1 {
+		down = 1
+	}
+	if len(c.widths) == 0 {
+		return 0, 0, down, up
+	}
+	current := c.currentColumnIndex()
+	if current > len(c.widths)-1 {
+		return 0, c.widths[len(c.widths)-1] + 1, down, up
+	}
+	if current == 0 {
+		return c.widths[0] + 1, 0, down, up
+	}
+	return c.widths[current] + 1, c.widths[current-1] + 1, down, up
+}
+
+func (c *Plugin) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = len(c.rows())
+	return
+}
+
+func (c Plugin) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(c.Name() + "_columns")
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(c.Name())
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(c.Name() + "_footer")
+}
+
+func (c *Plugin) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(c.Name()+"_columns", x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(c.Name(), x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(c.Name()+"_footer", x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	title := c.name
+	if result := c.plugins.Result(c.name); result != nil && result.Title != "" {
+		title = result.Title
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("F10"), "Quit",
+		title,
+	))
+	return nil
+}
+
+func (c *Plugin) display() {
+	result := c.plugins.Result(c.name)
+	// the rows change at each render, Clear drops the previous ones
+	// but resets the cursor which is restored afterwards.
+	c.columnHeadersView.Clear()
+	c.view.Clear()
+	defer func() {
+		c.columnHeadersView.SetOrigin(c.ox, 0)
+		c.columnHeadersView.SetCursor(c.cx, 0)
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	}()
+	if result == nil {
+		c.widths = nil
+		fmt.Fprintln(c.view, " waiting for the plugin...")
+		return
+	}
+
+	c.widths = make([]int, len(result.Columns))
+	for i := range result.Columns {
+		c.widths[i] = runewidth.StringWidth(result.Columns[i])
+	}
+	for _, row := range result.Rows {
+		for i := 0; i < len(row) && i < len(c.widths); i++ {
+			if w := runewidth.StringWidth(row[i]); w > c.widths[i] {
+				c.widths[i] = w
+			}
+		}
+	}
+	for i := range c.widths {
+		if c.widths[i] > pluginMaxWidth {
+			c.widths[i] = pluginMaxWidth
+		}
+	}
+
+	current := c.currentColumnIndex()
+	var buffer bytes.Buffer
+	for i := range result.Columns {
+		name := pluginCell(result.Columns[i], c.widths[i])
+		if current == i {
+			name = color.Cyan(color.Background)(name)
+		}
+		buffer.WriteString(name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	for _, row := range result.Rows {
+		var buffer bytes.Buffer
+		for i := range c.widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(color.White(opt)(pluginCell(cell, c.widths[i])))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func pluginCell(s string, width int) string {
+	if runewidth.StringWidth(s) > width {
+		s = runewidth.Truncate(s, width, "")
+	}
+	return runewidth.FillLeft(s, width)
+}
+
+func NewPlugin(name string, plugins *models.Plugins) *Plugin {
+	return &Plugin{name: name, plugins: plugins}
+}
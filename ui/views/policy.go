@@ -0,0 +1,182 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	POLICY           = "policy"
+	POLICY_BASE_FEE  = "policy_base_fee"
+	POLICY_FEE_RATE  = "policy_fee_rate"
+	POLICY_TIME_LOCK = "policy_time_lock"
+	POLICY_MIN_HTLC  = "policy_min_htlc"
+	POLICY_MAX_HTLC  = "policy_max_htlc"
+)
+
+// Policy is the form editing the routing policy of the node for the
+// channel selected in the channels view, tab moves to the next field.
+type Policy struct {
+	form
+	visible bool
+	channel *netmodels.Channel
+	err     error
+}
+
+func (p *Policy) Visible() bool {
+	return p.visible
+}
+
+// Show opens the form filled with the current policy of the channel.
+func (p *Policy) Show(channel *netmodels.Channel) {
+	p.channel = channel
+	p.err = nil
+	p.visible = true
+	policy := channel.LocalPolicy
+	if policy == nil {
+		policy = &netmodels.RoutingPolicy{}
+	}
+	p.reset(
+		strconv.FormatInt(policy.FeeBaseMsat, 10),
+		strconv.FormatInt(policy.FeeRateMilliMsat, 10),
+		strconv.FormatUint(uint64(policy.TimeLockDelta), 10),
+		strconv.FormatInt(policy.MinHtlc, 10),
+		strconv.FormatUint(policy.MaxHtlc/1000, 10),
+	)
+}
+
+func (p *Policy) Hide() {
+	p.visible = false
+	p.channel = nil
+	p.err = nil
+}
+
+// Channel returns the channel of the policy.
+func (p *Policy) Channel() *netmodels.Channel {
+	return p.channel
+}
+
+// Value returns the policy of the fields, the other settings are the ones
+// of the current policy.
+func (p *Policy) Value() (*netmodels.RoutingPolicy, error) {
+	policy := &netmodels.RoutingPolicy{}
+	if p.channel != nil && p.channel.LocalPolicy != nil {
+		*policy = *p.channel.LocalPolicy
+	}
+	values := make([]uint64, len(p.inputs))
+	for i := range p.inputs {
+		v, err := parsePolicyValue(p.inputs[i].Value())
+		if err != nil {
+			return nil, errors.Errorf("%s: %s", strings.TrimSpace(p.inputs[i].title), err)
+		}
+		values[i] = v
+	}
+	policy.FeeBaseMsat = int64(values[0])
+	policy.FeeRateMilliMsat = int64(values[1])
+	policy.TimeLockDelta = uint32(values[2])
+	policy.MinHtlc = int64(values[3])
+	policy.MaxHtlc = values[4] * 1000
+	if policy.TimeLockDelta == 0 {
+		return nil, errors.New("cltv delta must be above 0")
+	}
+	if policy.MaxHtlc == 0 {
+		return nil, errors.New("max htlc must be above 0")
+	}
+	if uint64(policy.MinHtlc) > policy.MaxHtlc {
+		return nil, errors.New("min htlc is above max htlc")
+	}
+	return policy, nil
+}
+
+// SetError sets the error returned by the update, the form stays open to
+// fix the policy.
+func (p *Policy) SetError(err error) {
+	p.err = err
+}
+
+func (p *Policy) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(p.inputs)+6 > maxY {
+		y0 = 0
+	}
+
+	y, err := p.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(POLICY, x0, y, x0+width, y+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " channel policy "
+	p.display(v)
+	return nil
+}
+
+func (p *Policy) display(v *gocui.View) {
+	v.Clear()
+	if p.channel == nil {
+		fmt.Fprintln(v, "no channel selected, esc to close")
+		return
+	}
+	cyan := color.Cyan()
+	alias, _ := p.channel.ShortAlias()
+	fmt.Fprintf(v, "%s %s %s\n", cyan("channel"), alias, p.channel.ChannelPoint)
+	if p.err != nil {
+		fmt.Fprintln(v, color.Red()(p.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "tab moves to the next field, enter updates the policy, esc to close")
+}
+
+func (p *Policy) Delete(g *gocui.Gui) error {
+	err := p.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(POLICY)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func parsePolicyValue(s string) (uint64, error) {
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, errors.New("not a positive number")
+	}
+	return v, nil
+}
+
+func validatePolicyValue(s string) error {
+	_, err := parsePolicyValue(s)
+	return err
+}
+
+func NewPolicy() *Policy {
+	return &Policy{form: form{inputs: []*Input{
+		NewInput(POLICY_BASE_FEE, " base fee (msat) ", validatePolicyValue),
+		NewInput(POLICY_FEE_RATE, " fee rate (ppm) ", validatePolicyValue),
+		NewInput(POLICY_TIME_LOCK, " cltv delta (blocks) ", validatePolicyValue),
+		NewInput(POLICY_MIN_HTLC, " min htlc (msat) ", validatePolicyValue),
+		NewInput(POLICY_MAX_HTLC, " max htlc (sat) ", validatePolicyValue),
+	}}}
+}
@@ -0,0 +1,99 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/edouardparis/lntop/qr"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	QR = "qr"
+)
+
+// QRCode is the popup displaying a string and its QR code, e.g. an
+// invoice or an on-chain address to pay from a mobile wallet.
+type QRCode struct {
+	title   string
+	content string
+	code    []string
+}
+
+func (q *QRCode) Visible() bool {
+	return q.content != ""
+}
+
+// Show renders the QR code of the content, displayed by the next layout.
+func (q *QRCode) Show(title, content string) error {
+	code, err := qr.Render(content)
+	if err != nil {
+		return err
+	}
+	q.title = title
+	q.content = content
+	q.code = strings.Split(strings.TrimRight(code, "\n"), "\n")
+	return nil
+}
+
+func (q *QRCode) Hide() {
+	q.title = ""
+	q.content = ""
+	q.code = nil
+}
+
+func (q *QRCode) Name() string {
+	return QR
+}
+
+func (q *QRCode) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 0
+	for i := range q.code {
+		if w := runewidth.StringWidth(q.code[i]); w > width {
+			width = w
+		}
+	}
+	// the content is wrapped below the code.
+	height := len(q.code) + (len(q.content)+width-1)/width + 1
+
+	x0, y0 := (maxX-width)/2-1, (maxY-height)/2-1
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	v, err := g.SetView(QR, x0, y0, x0+width+1, y0+height+1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = fmt.Sprintf(" %s - enter to close ", q.title)
+
+	v.Clear()
+	for i := range q.code {
+		fmt.Fprintln(v, q.code[i])
+	}
+	fmt.Fprintln(v, color.Cyan()(q.content))
+
+	_, err = g.SetCurrentView(QR)
+	return err
+}
+
+func (q *QRCode) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(QR)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewQRCode() *QRCode {
+	return &QRCode{}
+}
@@ -0,0 +1,246 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	REBALANCE         = "rebalance"
+	REBALANCE_OUT     = "rebalance_out"
+	REBALANCE_IN      = "rebalance_in"
+	REBALANCE_AMOUNT  = "rebalance_amount"
+	REBALANCE_MAX_FEE = "rebalance_max_fee"
+
+	// rebalanceLines is the number of attempts displayed, the last ones.
+	rebalanceLines = 4
+)
+
+// RebalanceRequest is the rebalance of the dialog, the channels are
+// their short channel id, pubkey or alias.
+type RebalanceRequest struct {
+	Out    string
+	In     string
+	Amount int64
+	MaxFee int64
+}
+
+// Rebalance is the dialog moving liquidity from an outgoing channel to an
+// incoming one with a circular payment, the first enter asks for a
+// confirmation and the second one starts the attempts.
+type Rebalance struct {
+	form
+	visible   bool
+	confirmed *RebalanceRequest
+	out       *netmodels.Channel
+	in        *netmodels.Channel
+	// start is the start of the rebalance, zero until it is sent.
+	start    time.Time
+	attempts []*netmodels.RebalanceAttempt
+	payment  *netmodels.Payment
+	err      error
+}
+
+func (r *Rebalance) Visible() bool {
+	return r.visible
+}
+
+// Show opens the dialog with the outgoing channel, empty if none is
+// selected.
+func (r *Rebalance) Show(out string) {
+	r.reset(out, "", "", "")
+	r.confirmed = nil
+	r.start = time.Time{}
+	r.attempts = nil
+	r.payment = nil
+	r.err = nil
+	r.visible = true
+}
+
+func (r *Rebalance) Hide() {
+	r.visible = false
+	r.confirmed = nil
+}
+
+// Sent returns true once the rebalance is started.
+func (r *Rebalance) Sent() bool {
+	return !r.start.IsZero()
+}
+
+// Value returns the rebalance of the fields.
+func (r *Rebalance) Value() (*RebalanceRequest, error) {
+	amount, err := parseAmount(r.inputs[2].Value())
+	if err != nil {
+		return nil, err
+	}
+	maxFee, err := parseAmount(r.inputs[3].Value())
+	if err != nil {
+		return nil, errors.Errorf("max fee: %s", err)
+	}
+	return &RebalanceRequest{
+		Out:    r.inputs[0].Value(),
+		In:     r.inputs[1].Value(),
+		Amount: amount,
+		MaxFee: maxFee,
+	}, nil
+}
+
+// Confirm returns true if the rebalance was confirmed by the previous
+// enter, otherwise it asks to confirm it between the two channels.
+func (r *Rebalance) Confirm(req *RebalanceRequest, out, in *netmodels.Channel) bool {
+	if r.confirmed != nil && *r.confirmed == *req {
+		return true
+	}
+	r.confirmed = req
+	r.out = out
+	r.in = in
+	r.err = nil
+	return false
+}
+
+// Start displays the rebalance in progress.
+func (r *Rebalance) Start() {
+	r.start = time.Now()
+}
+
+// SetAttempt adds the attempt or updates the one of its number.
+func (r *Rebalance) SetAttempt(attempt *netmodels.RebalanceAttempt) {
+	for i := range r.attempts {
+		if r.attempts[i].Number == attempt.Number {
+			r.attempts[i] = attempt
+			return
+		}
+	}
+	r.attempts = append(r.attempts, attempt)
+}
+
+// SetResult sets the payment once it succeeded or failed.
+func (r *Rebalance) SetResult(payment *netmodels.Payment, err error) {
+	r.payment = payment
+	r.err = err
+}
+
+// SetError sets the error of the fields, the dialog stays open.
+func (r *Rebalance) SetError(err error) {
+	r.confirmed = nil
+	r.err = err
+}
+
+func (r *Rebalance) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+3*len(r.inputs)+rebalanceLines+5 > maxY {
+		y0 = 0
+	}
+
+	y, err := r.set(g, x0, y0, x0+width)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.SetView(REBALANCE, x0, y, x0+width, y+rebalanceLines+4, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " rebalance "
+	r.display(v)
+	return nil
+}
+
+func (r *Rebalance) display(v *gocui.View) {
+	v.Clear()
+	if r.err != nil {
+		fmt.Fprintln(v, color.Red()(r.err.Error()))
+		if r.Sent() {
+			fmt.Fprintln(v, "esc to close")
+		}
+		return
+	}
+	if r.confirmed == nil {
+		fmt.Fprintln(v, "the channels are a short channel id, a pubkey or an alias")
+		fmt.Fprintln(v, "tab moves to the next field, enter rebalances, esc to close")
+		return
+	}
+
+	outAlias, _ := r.out.ShortAlias()
+	inAlias, _ := r.in.ShortAlias()
+	amount := color.Yellow(color.Bold)(formatAmount(r.confirmed.Amount) + " sat")
+	if !r.Sent() {
+		fmt.Fprintf(v, "move %s from %s to %s for a fee of at most %d sat?\n",
+			amount, color.Cyan()(outAlias), color.Cyan()(inAlias), r.confirmed.MaxFee)
+		fmt.Fprintln(v, "press enter again to rebalance, esc to cancel")
+		return
+	}
+
+	switch {
+	case r.payment != nil && r.payment.Status == netmodels.PaymentSucceeded:
+		fmt.Fprintf(v, "%s %s from %s to %s, fee %d sat\n", color.Green(color.Bold)("moved"),
+			amount, color.Cyan()(outAlias), color.Cyan()(inAlias), r.payment.Fee)
+	case r.payment != nil:
+		fmt.Fprintf(v, "%s %s from %s to %s: %s\n", color.Red(color.Bold)("failed to move"),
+			amount, color.Cyan()(outAlias), color.Cyan()(inAlias), r.payment.PaymentError)
+	default:
+		frame := spinner[int(time.Since(r.start)/(100*time.Millisecond))%len(spinner)]
+		fmt.Fprintf(v, "%s moving %s from %s to %s, %ds\n", color.Yellow()(frame),
+			amount, color.Cyan()(outAlias), color.Cyan()(inAlias), int(time.Since(r.start).Seconds()))
+	}
+
+	attempts := r.attempts
+	if len(attempts) > rebalanceLines {
+		attempts = attempts[len(attempts)-rebalanceLines:]
+	}
+	for _, a := range attempts {
+		route := ""
+		if a.Route != nil {
+			route = fmt.Sprintf("%d hops, fee %d sat", len(a.Route.Hops), a.Route.Fee)
+		}
+		status := color.Yellow()("in flight")
+		switch a.Status {
+		case netmodels.PaymentSucceeded:
+			status = color.Green()("succeeded")
+		case netmodels.PaymentFailed:
+			status = color.Red()(a.Error)
+		}
+		fmt.Fprintf(v, "%s %s %s\n", color.Cyan()(fmt.Sprintf("#%-2d", a.Number)), route, status)
+	}
+	if r.payment != nil {
+		fmt.Fprintln(v, "esc to close")
+	} else {
+		fmt.Fprintln(v, "esc closes the dialog, the rebalance goes on")
+	}
+}
+
+func (r *Rebalance) Delete(g *gocui.Gui) error {
+	err := r.delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(REBALANCE)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewRebalance() *Rebalance {
+	return &Rebalance{form: form{inputs: []*Input{
+		NewTextInput(REBALANCE_OUT, " outgoing channel "),
+		NewTextInput(REBALANCE_IN, " incoming channel "),
+		NewInput(REBALANCE_AMOUNT, " amount (sat) ", validateAmount),
+		NewInput(REBALANCE_MAX_FEE, " max fee (sat) ", validateAmount),
+	}}}
+}
@@ -0,0 +1,97 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	REMOVE_TOWER = "remove_tower"
+)
+
+// RemoveTower is the popup confirming the removal of the tower selected
+// in the towers view.
+type RemoveTower struct {
+	tower *netmodels.Tower
+	err   error
+}
+
+func (r *RemoveTower) Visible() bool {
+	return r.tower != nil
+}
+
+func (r *RemoveTower) Show(tower *netmodels.Tower) {
+	r.tower = tower
+	r.err = nil
+}
+
+func (r *RemoveTower) Hide() {
+	r.tower = nil
+	r.err = nil
+}
+
+// Tower returns the tower to remove.
+func (r *RemoveTower) Tower() *netmodels.Tower {
+	return r.tower
+}
+
+// SetError sets the error of the removal, the popup stays open.
+func (r *RemoveTower) SetError(err error) {
+	r.err = err
+}
+
+func (r *RemoveTower) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+6 > maxY {
+		y0 = 0
+	}
+
+	v, err := g.SetView(REMOVE_TOWER, x0, y0, x0+width, y0+6, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " remove tower "
+	r.display(v)
+
+	_, err = g.SetCurrentView(REMOVE_TOWER)
+	return err
+}
+
+func (r *RemoveTower) display(v *gocui.View) {
+	v.Clear()
+	cyan := color.Cyan()
+	fmt.Fprintf(v, "%s %s\n", cyan("tower  "), r.tower.PubKey)
+	fmt.Fprintf(v, "%s %s\n", cyan("address"), strings.Join(r.tower.Addresses, ", "))
+	if r.err != nil {
+		fmt.Fprintln(v, color.Red()(r.err.Error()))
+		return
+	}
+	fmt.Fprintln(v, "the channel states are no longer backed up to the tower")
+	fmt.Fprintln(v, "press enter to remove it, esc to cancel")
+}
+
+func (r *RemoveTower) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(REMOVE_TOWER)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewRemoveTower() *RemoveTower {
+	return &RemoveTower{}
+}
@@ -0,0 +1,109 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	RESET_MISSION = "reset_mission"
+)
+
+// ResetMission is the popup confirming the reset of mission control.
+type ResetMission struct {
+	visible bool
+	pairs   int
+	// resetting is true while mission control is reset.
+	resetting bool
+	err       error
+}
+
+func (r *ResetMission) Visible() bool {
+	return r.visible
+}
+
+func (r *ResetMission) Show(pairs int) {
+	r.visible = true
+	r.pairs = pairs
+	r.resetting = false
+	r.err = nil
+}
+
+func (r *ResetMission) Hide() {
+	r.visible = false
+	r.resetting = false
+	r.err = nil
+}
+
+// Start marks mission control as reset until the result is set.
+func (r *ResetMission) Start() {
+	r.resetting = true
+	r.err = nil
+}
+
+// Resetting returns true while mission control is reset.
+func (r *ResetMission) Resetting() bool {
+	return r.resetting
+}
+
+// SetError sets the error of the reset, the popup stays open.
+func (r *ResetMission) SetError(err error) {
+	r.resetting = false
+	r.err = err
+}
+
+func (r *ResetMission) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+5 > maxY {
+		y0 = 0
+	}
+
+	v, err := g.SetView(RESET_MISSION, x0, y0, x0+width, y0+5, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = " reset mission control "
+	r.display(v)
+
+	_, err = g.SetCurrentView(RESET_MISSION)
+	return err
+}
+
+func (r *ResetMission) display(v *gocui.View) {
+	v.Clear()
+	fmt.Fprintf(v, "the history of the %d pairs is cleared, the routes are estimated\n", r.pairs)
+	fmt.Fprintln(v, "with the apriori probability until the next payments")
+	if r.err != nil {
+		fmt.Fprintln(v, color.Red()(r.err.Error()))
+		return
+	}
+	if r.resetting {
+		fmt.Fprintln(v, color.Yellow()("resetting..."))
+		return
+	}
+	fmt.Fprintln(v, "press enter to reset it, esc to cancel")
+}
+
+func (r *ResetMission) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(RESET_MISSION)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewResetMission() *ResetMission {
+	return &ResetMission{}
+}
@@ -0,0 +1,178 @@
+package views
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/pkg/errors"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+)
+
+const (
+	RESOLVE_HTLC          = "resolve_htlc"
+	RESOLVE_HTLC_PREIMAGE = "resolve_htlc_preimage"
+)
+
+// ResolveHTLC is the popup confirming the resume, the fail or the settle
+// of the HTLC held by the firewall, the preimage of a settle is typed in
+// its input.
+type ResolveHTLC struct {
+	input  *Input
+	htlc   *netmodels.InterceptedHTLC
+	action int
+	err    error
+}
+
+func (r *ResolveHTLC) Visible() bool {
+	return r.htlc != nil
+}
+
+func (r *ResolveHTLC) Show(htlc *netmodels.InterceptedHTLC, action int) {
+	r.htlc = htlc
+	r.action = action
+	r.input.SetValue("")
+	r.err = nil
+}
+
+func (r *ResolveHTLC) Hide() {
+	r.htlc = nil
+	r.err = nil
+}
+
+// HTLC returns the HTLC to resolve.
+func (r *ResolveHTLC) HTLC() *netmodels.InterceptedHTLC {
+	return r.htlc
+}
+
+// Action returns the resolution of the HTLC.
+func (r *ResolveHTLC) Action() int {
+	return r.action
+}
+
+// Preimage returns the preimage of the input, nil unless the HTLC is
+// settled.
+func (r *ResolveHTLC) Preimage() ([]byte, error) {
+	if r.action != netmodels.InterceptSettle {
+		return nil, nil
+	}
+	return parsePreimage(r.input.Value())
+}
+
+// Pasting returns true while a preimage is pasted in the input.
+func (r *ResolveHTLC) Pasting() bool {
+	return r.action == netmodels.InterceptSettle && r.input.Pasting()
+}
+
+// SetError sets the error of the preimage or of the resolution, the popup
+// stays open.
+func (r *ResolveHTLC) SetError(err error) {
+	r.err = err
+}
+
+func (r *ResolveHTLC) Set(g *gocui.Gui, maxX, maxY int) error {
+	width := 80
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	x0 := (maxX - width) / 2
+	y0 := 7
+	if y0+10 > maxY {
+		y0 = 0
+	}
+
+	y := y0
+	if r.action == netmodels.InterceptSettle {
+		err := r.input.Set(g, x0, y0, x0+width, y0+2)
+		if err != nil {
+			return err
+		}
+		y = y0 + 3
+	} else {
+		err := r.input.Delete(g)
+		if err != nil {
+			return err
+		}
+	}
+
+	v, err := g.SetView(RESOLVE_HTLC, x0, y, x0+width, y+7, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Wrap = true
+	v.Title = fmt.Sprintf(" %s htlc ", resolveVerb(r.action))
+	r.display(v)
+
+	if r.action == netmodels.InterceptSettle {
+		return nil
+	}
+	_, err = g.SetCurrentView(RESOLVE_HTLC)
+	return err
+}
+
+func (r *ResolveHTLC) display(v *gocui.View) {
+	v.Clear()
+	cyan := color.Cyan()
+	fmt.Fprintf(v, "%s %s\n", cyan("htlc    "), r.htlc.Key())
+	fmt.Fprintf(v, "%s %s -> %s\n", cyan("peers   "), r.htlc.IncomingPeer, r.htlc.OutgoingPeer)
+	fmt.Fprintf(v, "%s %s sat, fee %s msat\n", cyan("amount  "),
+		formatAmount(int64(r.htlc.OutgoingAmountMsat/1000)), formatAmount(int64(r.htlc.FeeMsat())))
+	fmt.Fprintf(v, "%s %x\n", cyan("hash    "), r.htlc.PaymentHash)
+	if r.err != nil {
+		fmt.Fprintln(v, color.Red()(r.err.Error()))
+		return
+	}
+	switch r.action {
+	case netmodels.InterceptSettle:
+		fmt.Fprintln(v, "type the preimage of the payment hash and press enter to settle, esc to cancel")
+	case netmodels.InterceptFail:
+		fmt.Fprintln(v, "press enter to fail it back to the incoming peer, esc to cancel")
+	default:
+		fmt.Fprintln(v, "press enter to forward it to the outgoing peer, esc to cancel")
+	}
+}
+
+func (r *ResolveHTLC) Delete(g *gocui.Gui) error {
+	err := r.input.Delete(g)
+	if err != nil {
+		return err
+	}
+	err = g.DeleteView(RESOLVE_HTLC)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+// resolveVerb returns the verb of the resolution, e.g. "settle".
+func resolveVerb(action int) string {
+	switch action {
+	case netmodels.InterceptFail:
+		return "fail"
+	case netmodels.InterceptSettle:
+		return "settle"
+	}
+	return "resume"
+}
+
+func parsePreimage(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("not a preimage of 32 bytes in hex")
+	}
+	return b, nil
+}
+
+func validatePreimage(s string) error {
+	_, err := parsePreimage(s)
+	return err
+}
+
+func NewResolveHTLC() *ResolveHTLC {
+	return &ResolveHTLC{input: NewInput(RESOLVE_HTLC_PREIMAGE, " preimage ", validatePreimage)}
+}
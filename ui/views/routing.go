@@ -33,8 +33,31 @@ var DefaultRoutingColumns = []string{
 	"DETAIL",
 }
 
+// AllRoutingColumns are the columns of the view, in the order of the
+// column chooser.
+var AllRoutingColumns = []string{
+	"DIR",
+	"STATUS",
+	"IN_ALIAS",
+	"IN_CHANNEL",
+	"IN_SCID",
+	"IN_TIMELOCK",
+	"IN_HTLC",
+	"OUT_ALIAS",
+	"OUT_CHANNEL",
+	"OUT_SCID",
+	"OUT_TIMELOCK",
+	"OUT_HTLC",
+	"AMOUNT",
+	"FEE",
+	"LAST UPDATE",
+	"DETAIL",
+}
+
 type Routing struct {
 	cfg *config.View
+	// names are the columns of the config, columns their display.
+	names []string
 
 	columns []routingColumn
 
@@ -42,6 +65,7 @@ type Routing struct {
 	columnViews       []*gocui.View
 	view              *gocui.View
 	routingEvents     *models.RoutingLog
+	chart             *RoutingChart
 
 	ox, oy int
 	cx, cy int
@@ -183,6 +207,10 @@ func (c *Routing) Delete(g *gocui.Gui) error {
 		}
 	}
 	c.columnViews = c.columnViews[:0]
+	err = c.chart.Delete(g)
+	if err != nil {
+		return err
+	}
 	return g.DeleteView(ROUTING_FOOTER)
 }
 
@@ -197,10 +225,22 @@ func (c *Routing) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		setCursor = true
 	}
 	c.columnHeadersView.Frame = false
-	c.columnHeadersView.BgColor = gocui.ColorGreen
-	c.columnHeadersView.FgColor = gocui.ColorBlack
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	// the chart is between the events and the footer.
+	bottom := y1 - 1
+	if c.chart.Visible(y1 - y0) {
+		bottom -= routingChartHeight
+		err = c.chart.Set(g, x0, bottom-1, x1, y1-1)
+	} else {
+		err = c.chart.Delete(g)
+	}
+	if err != nil {
+		return err
+	}
 
-	c.view, err = g.SetView(ROUTING, x0-1, y0+1, x1+2, y1-1, 0)
+	c.view, err = g.SetView(ROUTING, x0-1, y0+1, x1+2, bottom, 0)
 	if err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
@@ -209,8 +249,8 @@ func (c *Routing) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	}
 	c.view.Frame = false
 	c.view.Autoscroll = false
-	c.view.SelBgColor = gocui.ColorCyan
-	c.view.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
 	c.view.Highlight = true
 	c.display(g)
 
@@ -235,12 +275,13 @@ func (c *Routing) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		}
 	}
 	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
 	footer.Rewind()
 	blackBg := color.Black(color.Background)
-	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s",
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
 		blackBg("F2"), "Menu",
+		blackBg("v"), "Columns",
 		blackBg("F10"), "Quit",
 	))
 	return nil
@@ -276,8 +317,8 @@ func (c *Routing) display(g *gocui.Gui) {
 			cc, _ := g.SetView("routing_content_"+c.columns[i].name, x0, y0, x0+width+2, y1, 0)
 			cc.Frame = false
 			cc.Autoscroll = false
-			cc.SelBgColor = gocui.ColorCyan
-			cc.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+			cc.SelBgColor = color.Attrs().SelectionBg
+			cc.SelFgColor = color.Attrs().SelectionFg
 			cc.Highlight = true
 			c.columnViews[i] = cc
 		}
@@ -305,10 +346,21 @@ func (c *Routing) display(g *gocui.Gui) {
 	}
 }
 
-func NewRouting(cfg *config.View, routingEvents *models.RoutingLog, channels *models.Channels) *Routing {
+// Columns returns the columns of the view and their widths.
+func (c *Routing) Columns() ([]string, []int) {
+	widths := make([]int, len(c.columns))
+	for i := range c.columns {
+		widths[i] = c.columns[i].width
+	}
+	return c.names, widths
+}
+
+func NewRouting(cfg *config.View, routingEvents *models.RoutingLog, channels *models.Channels,
+	chart *models.RoutingChart) *Routing {
 	routing := &Routing{
 		cfg:           cfg,
 		routingEvents: routingEvents,
+		chart:         NewRoutingChart(chart),
 	}
 
 	printer := message.NewPrinter(language.English)
@@ -359,7 +411,7 @@ func NewRouting(cfg *config.View, routingEvents *models.RoutingLog, channels *mo
 					if c.IncomingChannelId == 0 {
 						return fmt.Sprintf("%14s", "")
 					}
-					return color.White(opts...)(fmt.Sprintf("%14s", ToScid(c.IncomingChannelId)))
+					return color.White(opts...)(fmt.Sprintf("%14s", netmodels.ToScid(c.IncomingChannelId)))
 				},
 			}
 		case "IN_TIMELOCK":
@@ -409,7 +461,7 @@ func NewRouting(cfg *config.View, routingEvents *models.RoutingLog, channels *mo
 					if c.OutgoingChannelId == 0 {
 						return fmt.Sprintf("%14s", "")
 					}
-					return color.White(opts...)(fmt.Sprintf("%14s", ToScid(c.OutgoingChannelId)))
+					return color.White(opts...)(fmt.Sprintf("%14s", netmodels.ToScid(c.OutgoingChannelId)))
 				},
 			}
 		case "OUT_TIMELOCK":
@@ -479,6 +531,20 @@ func NewRouting(cfg *config.View, routingEvents *models.RoutingLog, channels *mo
 		}
 	}
 
+	routing.names = columns
+	for i := range routing.columns {
+		w, ok := columnWidth(cfg, columns[i])
+		if !ok {
+			continue
+		}
+		col := &routing.columns[i]
+		display, right := col.display, rightAligned(col.name)
+		col.name, col.width = fitCell(col.name, w, right), w
+		col.display = func(item *netmodels.RoutingEvent, opts ...color.Option) string {
+			return fitCell(display(item, opts...), w, right)
+		}
+	}
+
 	return routing
 }
 
@@ -0,0 +1,166 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	ROUTING_CHART = "routing_chart"
+
+	// routingChartHeight is the number of lines of the chart: the title,
+	// the bars and the time axis.
+	routingChartHeight = 8
+)
+
+// RoutingChart is the panel below the routing view of the forwards
+// settled and of the fees earned by interval, side by side.
+type RoutingChart struct {
+	chart *models.RoutingChart
+}
+
+// Visible returns true if the chart has space below a routing view of
+// height lines.
+func (c *RoutingChart) Visible(height int) bool {
+	return c.chart.Enabled() && height > 2*routingChartHeight
+}
+
+func (c *RoutingChart) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(ROUTING_CHART)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func (c *RoutingChart) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(ROUTING_CHART, x0-1, y0, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = false
+	v.Clear()
+
+	buckets := c.chart.Buckets()
+	if len(buckets) == 0 {
+		return nil
+	}
+	half := (x1 - x0) / 2
+	// a bucket is a bar and a gap if the half of the width allows it,
+	// the oldest buckets are cut otherwise.
+	bw := min(max((half-2)/len(buckets), 1), 3)
+	if n := (half - 2) / bw; n < len(buckets) {
+		buckets = buckets[len(buckets)-n:]
+	}
+
+	p := message.NewPrinter(language.English)
+	forwards := make([]float64, len(buckets))
+	fees := make([]float64, len(buckets))
+	var totalForwards int
+	var totalFees, totalAmount uint64
+	for i, b := range buckets {
+		forwards[i] = float64(b.Forwards)
+		fees[i] = float64(b.FeeMsat)
+		totalForwards += b.Forwards
+		totalFees += b.FeeMsat
+		totalAmount += b.AmountMsat
+	}
+
+	rows := routingChartHeight - 2
+	period := fmt.Sprintf("%s by %s", formatChartDuration(c.chart.Window()),
+		formatChartDuration(c.chart.Interval()))
+	left := chartLines(p.Sprintf(" [ Forwards ] %s, %d forwards, %d sat", period,
+		totalForwards, totalAmount/1000), forwards, rows, bw, half)
+	right := chartLines(p.Sprintf(" [ Fees ] %s, %d sat", period, totalFees/1000),
+		fees, rows, bw, half)
+	for i := range left {
+		l, r := left[i], right[i]
+		switch {
+		case i == 0:
+			l, r = color.Green()(l), color.Green()(r)
+		case i <= rows:
+			l, r = color.Cyan()(l), color.Yellow()(r)
+		}
+		fmt.Fprintln(v, l+r)
+	}
+	fmt.Fprintln(v, chartAxis(buckets, c.chart.Interval(), bw, half)+
+		chartAxis(buckets, c.chart.Interval(), bw, half))
+	return nil
+}
+
+// chartLines returns the title and the rows of the bars of the values,
+// the highest first, each padded to the width.
+func chartLines(title string, values []float64, rows, bw, width int) []string {
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	lines := []string{runewidth.FillRight(runewidth.Truncate(title, width, ""), width)}
+	for r := rows - 1; r >= 0; r-- {
+		var line strings.Builder
+		line.WriteString(" ")
+		for _, v := range values {
+			level := 0
+			if top > 0 {
+				level = int(v/top*float64(rows*len(sparks)) + 0.5)
+			}
+			bar := " "
+			if fill := level - r*len(sparks); fill >= len(sparks) {
+				bar = string(sparks[len(sparks)-1])
+			} else if fill > 0 {
+				bar = string(sparks[fill-1])
+			}
+			line.WriteString(strings.Repeat(bar, max(bw-1, 1)))
+			if bw > 1 {
+				line.WriteString(" ")
+			}
+		}
+		lines = append(lines, runewidth.FillRight(line.String(), width))
+	}
+	return lines
+}
+
+// chartAxis returns the times of the first and the last buckets under
+// their bars, padded to the width.
+func chartAxis(buckets []*models.RoutingBucket, interval time.Duration, bw, width int) string {
+	layout := "15:04"
+	if interval >= 24*time.Hour {
+		layout = "Jan _2"
+	}
+	first := buckets[0].Time.Format(layout)
+	last := buckets[len(buckets)-1].Time.Format(layout)
+	end := 1 + len(buckets)*bw
+	axis := " " + first
+	if end-len(axis) > len(last) {
+		axis += fmt.Sprintf("%*s", end-len(axis), last)
+	}
+	return runewidth.FillRight(axis, width)
+}
+
+// formatChartDuration formats the duration in days, hours or minutes.
+func formatChartDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
+
+func NewRoutingChart(chart *models.RoutingChart) *RoutingChart {
+	return &RoutingChart{chart: chart}
+}
@@ -0,0 +1,71 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const SEARCH = "search"
+
+// Search is the field of the search of the channels view, above its
+// footer. The channels are filtered as the query is typed.
+type Search struct {
+	input    *Input
+	visible  bool
+	channels *models.Channels
+	changed  func(string)
+}
+
+func (s *Search) Visible() bool {
+	return s.visible
+}
+
+// Show opens the field with the query, changed is called with the query
+// each time it is edited.
+func (s *Search) Show(query string, changed func(string)) {
+	s.input.SetValue(query)
+	s.changed = changed
+	s.visible = true
+}
+
+func (s *Search) Hide() {
+	s.visible = false
+	s.changed = nil
+}
+
+// Value returns the query of the field.
+func (s *Search) Value() string {
+	return s.input.Value()
+}
+
+func (s *Search) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	s.input.Edit(v, key, ch, mod)
+	if s.changed != nil {
+		s.changed(s.input.Value())
+	}
+}
+
+func (s *Search) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	err := s.input.Set(g, x0, y0, x1, y1)
+	if err != nil {
+		return err
+	}
+	s.input.view.Editor = s
+	s.input.view.Subtitle = fmt.Sprintf(" %d/%d channels, enter to keep, esc to clear ",
+		s.channels.FilteredLen(), s.channels.Len())
+	return nil
+}
+
+func (s *Search) Delete(g *gocui.Gui) error {
+	return s.input.Delete(g)
+}
+
+func NewSearch(channels *models.Channels) *Search {
+	return &Search{
+		input:    NewTextInput(SEARCH, " search alias, pubkey, channel id or address "),
+		channels: channels,
+	}
+}
@@ -0,0 +1,92 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/config"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	SESSION = "session"
+)
+
+// Session is the bar at the bottom of the screen of the rolling rates of
+// the forwards since lntop started, below the footer of the main view.
+type Session struct {
+	session  *models.Session
+	disabled bool
+}
+
+// Visible returns false if the bar is disabled in the config.
+func (s *Session) Visible() bool {
+	return !s.disabled
+}
+
+func (s *Session) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(SESSION, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = false
+
+	r := s.session.Rates()
+	cyan := color.Cyan()
+	success := "-"
+	if rate, ok := r.Success(); ok {
+		success = formatSuccessRate(rate)
+	}
+	v.Clear()
+	fmt.Fprintf(v, "%s %s %.1f %s %s sat %s %s sat %s %s %s\n",
+		color.Cyan(color.Background)(fmt.Sprintf(" session %s ", formatPeriod(r.Period))),
+		cyan("forwards/min:"), r.ForwardsPerMinute,
+		cyan("routed/h:"), fiatPrinter.Sprintf("%.0f", r.SatsPerHour),
+		cyan("fees/h:"), fiatPrinter.Sprintf("%.0f", r.FeesPerHour),
+		cyan("success:"), success,
+		fmt.Sprintf("(%d/%d)", r.Settled, r.Forwards),
+	)
+	return nil
+}
+
+func (s *Session) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(SESSION)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+// formatPeriod returns the period of the rates in minutes, e.g. 15m or
+// 1h30m.
+func formatPeriod(d time.Duration) string {
+	s := d.Truncate(time.Minute).String()
+	s = strings.TrimSuffix(s, "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatSuccessRate returns the percentage of the forwards settled,
+// colored from red to green.
+func formatSuccessRate(rate float64) string {
+	text := fmt.Sprintf("%.1f%%", rate)
+	switch {
+	case rate <= 50:
+		return color.Red()(text)
+	case rate <= 80:
+		return color.Yellow()(text)
+	}
+	return color.Green()(text)
+}
+
+func NewSession(cfg config.Session, session *models.Session) *Session {
+	return &Session{session: session, disabled: cfg.Disabled}
+}
@@ -0,0 +1,284 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	SORT_MENU      = "sort_menu"
+	SORT_MENU_HELP = "sort_menu_help"
+)
+
+// SortKey is a column the channels are sorted by and its order.
+type SortKey struct {
+	Column string
+	Order  models.Order
+}
+
+func (k SortKey) String() string {
+	if k.Order == models.Desc {
+		return k.Column + " desc"
+	}
+	return k.Column + " asc"
+}
+
+// ParseSortKeys returns the keys of the config, a column followed by asc
+// or desc, asc if omitted. The keys of another order are ignored.
+func ParseSortKeys(keys []string) []SortKey {
+	var parsed []SortKey
+	for _, key := range keys {
+		fields := strings.Fields(key)
+		if len(fields) == 0 || len(fields) > 2 {
+			continue
+		}
+		k := SortKey{Column: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				k.Order = models.Desc
+			default:
+				continue
+			}
+		}
+		parsed = append(parsed, k)
+	}
+	return parsed
+}
+
+// SortMenu is the popup choosing the columns the channels are sorted by,
+// in the order they were chosen.
+type SortMenu struct {
+	view    *gocui.View
+	visible bool
+	columns []string
+	keys    []SortKey
+	err     error
+
+	cy, oy int
+}
+
+func (s *SortMenu) Visible() bool {
+	return s.visible
+}
+
+// Show opens the menu of the columns with the keys of the current sort.
+func (s *SortMenu) Show(columns []string, keys []SortKey) {
+	s.columns = columns
+	s.keys = append([]SortKey{}, keys...)
+	s.cy, s.oy = 0, 0
+	s.err = nil
+	s.visible = true
+}
+
+func (s *SortMenu) Hide() {
+	s.visible = false
+}
+
+// Value returns the keys of the sort.
+func (s *SortMenu) Value() []SortKey {
+	return s.keys
+}
+
+// SetError displays the error, the menu stays open.
+func (s *SortMenu) SetError(err error) {
+	s.err = err
+}
+
+// key returns the index of the key of the current column, -1 if the
+// channels are not sorted by it.
+func (s *SortMenu) key() int {
+	i := s.cy + s.oy
+	if i < 0 || i >= len(s.columns) {
+		return -1
+	}
+	return slices.IndexFunc(s.keys, func(k SortKey) bool {
+		return k.Column == s.columns[i]
+	})
+}
+
+// Cycle sorts by the current column ascending after the other keys, then
+// descending, then not by it.
+func (s *SortMenu) Cycle() {
+	i := s.cy + s.oy
+	if i < 0 || i >= len(s.columns) {
+		return
+	}
+	s.err = nil
+	k := s.key()
+	switch {
+	case k < 0:
+		s.keys = append(s.keys, SortKey{Column: s.columns[i]})
+	case s.keys[k].Order == models.Asc:
+		s.keys[k].Order = models.Desc
+	default:
+		s.keys = slices.Delete(s.keys, k, k+1)
+	}
+}
+
+// Move raises the key of the current column before the previous one if
+// delta is negative, lowers it after the next one otherwise.
+func (s *SortMenu) Move(delta int) {
+	k := s.key()
+	j := k + 1
+	if delta < 0 {
+		j = k - 1
+	}
+	if k < 0 || j < 0 || j >= len(s.keys) {
+		return
+	}
+	s.keys[k], s.keys[j] = s.keys[j], s.keys[k]
+}
+
+func (s SortMenu) Name() string {
+	return SORT_MENU
+}
+
+func (s *SortMenu) Wrap(v *gocui.View) View {
+	s.view = v
+	return s
+}
+
+func (s SortMenu) Origin() (int, int) {
+	return 0, s.oy
+}
+
+func (s SortMenu) Cursor() (int, int) {
+	return 0, s.cy
+}
+
+func (s SortMenu) Speed() (int, int, int, int) {
+	down := 0
+	if s.cy+s.oy < len(s.columns)-1 {
+		down = 1
+	}
+	return 0, 0, down, 1
+}
+
+func (s SortMenu) Limits() (pageSize int, fullSize int) {
+	if s.view != nil {
+		_, pageSize = s.view.Size()
+	}
+	return pageSize, len(s.columns)
+}
+
+func (s *SortMenu) SetCursor(x, y int) error {
+	err := s.view.SetCursor(x, y)
+	if err != nil {
+		return err
+	}
+	s.cy = y
+	return nil
+}
+
+func (s *SortMenu) SetOrigin(x, y int) error {
+	err := s.view.SetOrigin(x, y)
+	if err != nil {
+		return err
+	}
+	s.oy = y
+	return nil
+}
+
+func (s *SortMenu) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	width := 50
+	if width > x1-x0 {
+		width = x1 - x0
+	}
+	x0 = x0 + (x1-x0-width)/2
+	x1 = x0 + width
+	if y0+len(s.columns)+3 < y1 {
+		y1 = y0 + len(s.columns) + 3
+	}
+
+	setCursor := false
+	var err error
+	s.view, err = g.SetView(SORT_MENU, x0, y0, x1, y1-2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	s.view.Frame = true
+	s.view.Title = " sort of channels "
+	s.view.Highlight = true
+	s.view.SelBgColor = color.Attrs().SelectionBg
+	s.view.SelFgColor = color.Attrs().SelectionFg
+	s.view.Clear()
+	for _, column := range s.columns {
+		rank, order := "  ", "    "
+		k := slices.IndexFunc(s.keys, func(k SortKey) bool {
+			return k.Column == column
+		})
+		if k >= 0 {
+			rank = fmt.Sprintf("%2d", k+1)
+			order = color.Green()("asc ")
+			if s.keys[k].Order == models.Desc {
+				order = color.Magenta()("desc")
+			}
+		}
+		fmt.Fprintf(s.view, " %s %-*s %s\n", rank, width-12, column, order)
+	}
+	if setCursor {
+		err = s.SetOrigin(0, s.oy)
+		if err != nil {
+			return err
+		}
+		err = s.SetCursor(0, s.cy)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = g.SetCurrentView(SORT_MENU)
+	if err != nil {
+		return err
+	}
+
+	help, err := g.SetView(SORT_MENU_HELP, x0, y1-2, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	help.Frame = true
+	help.Clear()
+	if s.err != nil {
+		fmt.Fprintln(help, color.Red()(s.err.Error()))
+	} else {
+		fmt.Fprintln(help, "space asc desc off, K J priority, enter saves")
+	}
+	return nil
+}
+
+func (s *SortMenu) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(SORT_MENU)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	err = g.DeleteView(SORT_MENU_HELP)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewSortMenu() *SortMenu {
+	return &SortMenu{}
+}
+
+// sortKeysConfig returns the keys in the format of the config.
+func sortKeysConfig(keys []SortKey) []string {
+	values := make([]string, len(keys))
+	for i := range keys {
+		values[i] = keys[i].String()
+	}
+	return values
+}
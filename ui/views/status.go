@@ -0,0 +1,68 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	STATUS = "status"
+)
+
+// Status replaces the main view while the node is not ready, e.g. its
+// wallet is locked after a restart, instead of displaying stale data.
+type Status struct {
+	state *models.NodeState
+}
+
+func (s *Status) Visible() bool {
+	return !s.state.Ready()
+}
+
+func (s *Status) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	v, err := g.SetView(STATUS, x0, y0, x1, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Frame = true
+	v.Title = " Node "
+
+	state, since := s.state.Get()
+	cyan := color.Cyan()
+	v.Clear()
+	fmt.Fprintln(v)
+	fmt.Fprintf(v, " %s %s since %s\n",
+		cyan("The node is not ready:"),
+		color.Red(color.Bold)(state.String()),
+		since.Format("15:04:05 Jan _2"))
+	fmt.Fprintln(v)
+	switch state {
+	case netmodels.NodeStateLocked:
+		fmt.Fprintln(v, " Unlock the wallet, e.g. with lncli unlock.")
+	case netmodels.NodeStateUnavailable:
+		fmt.Fprintln(v, " The node does not answer, it may be restarting.")
+	}
+	fmt.Fprintln(v, " The state is checked in the background, lntop resumes once the node is ready.")
+
+	_, err = g.SetCurrentView(STATUS)
+	return err
+}
+
+func (s *Status) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(STATUS)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	return nil
+}
+
+func NewStatus(state *models.NodeState) *Status {
+	return &Status{state: state}
+}
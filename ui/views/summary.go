@@ -25,6 +25,7 @@ type Summary struct {
 	channelsBalance *models.ChannelsBalance
 	walletBalance   *models.WalletBalance
 	channels        *models.Channels
+	channelBackup   *models.ChannelBackup
 }
 
 func (s *Summary) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
@@ -83,6 +84,51 @@ func (s *Summary) display() {
 		green(p.Sprintf("%s", formatAmount(s.walletBalance.ConfirmedBalance))),
 		yellow(p.Sprintf("%s", formatAmount(s.walletBalance.UnconfirmedBalance))),
 	))
+	reserve := s.walletBalance.AnchorReserve
+	if reserve == 0 {
+		reserve = netmodels.RequiredAnchorReserve(s.channels.List())
+	}
+	if reserve > 0 {
+		if s.walletBalance.ConfirmedBalance < reserve {
+			fmt.Fprintln(s.right, fmt.Sprintf("%s %s %s",
+				cyan("reserve:"), red(formatAmount(reserve)),
+				red("above confirmed, force closes cannot be fee bumped")))
+		} else {
+			fmt.Fprintln(s.right, fmt.Sprintf("%s %s %s",
+				cyan("reserve:"), formatAmount(reserve),
+				green(fmt.Sprintf("anchors, %s spendable",
+					formatAmount(s.walletBalance.ConfirmedBalance-reserve)))))
+		}
+	}
+	s.displayBackup()
+}
+
+// displayBackup displays the last channel backup of the node and the result
+// of its last export.
+func (s *Summary) displayBackup() {
+	backup, updated := s.channelBackup.Get()
+	if backup == nil {
+		return
+	}
+	green := color.Green()
+	yellow := color.Yellow()
+	cyan := color.Cyan()
+	red := color.Red()
+	line := fmt.Sprintf("%s %d channels, %.1f kB at %s",
+		cyan("backup :"), len(backup.ChanPoints),
+		float64(len(backup.Multi))/1000, updated.Format("15:04:05"))
+	exported, exporting, err := s.channelBackup.Exported()
+	switch {
+	case exporting:
+		line += " " + yellow("exporting")
+	case err != nil:
+		line += " " + red(fmt.Sprintf("export failed at %s",
+			exported.Format("15:04:05")))
+	case !exported.IsZero():
+		line += " " + green(fmt.Sprintf("exported at %s",
+			exported.Format("15:04:05")))
+	}
+	fmt.Fprintln(s.right, line)
 }
 
 func gaugeTotal(balance int64, channels []*netmodels.Channel) string {
@@ -111,11 +157,13 @@ func gaugeTotal(balance int64, channels []*netmodels.Channel) string {
 func NewSummary(info *models.Info,
 	channelsBalance *models.ChannelsBalance,
 	walletBalance *models.WalletBalance,
-	channels *models.Channels) *Summary {
+	channels *models.Channels,
+	channelBackup *models.ChannelBackup) *Summary {
 	return &Summary{
 		info:            info,
 		channelsBalance: channelsBalance,
 		walletBalance:   walletBalance,
 		channels:        channels,
+		channelBackup:   channelBackup,
 	}
 }
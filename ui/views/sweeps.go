@@ -0,0 +1,465 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	SWEEPS         = "sweeps"
+	SWEEPS_COLUMNS = "sweeps_columns"
+	SWEEPS_FOOTER  = "sweeps_footer"
+)
+
+var DefaultSweepsColumns = []string{
+	"OUTPOINT",
+	"WITNESS",
+	"AMOUNT",
+	"FEE_RATE",
+	"ATTEMPTS",
+	"NEXT",
+	"DEADLINE",
+}
+
+type Sweeps struct {
+	cfg  *config.View
+	info *models.Info
+
+	columns           []sweepsColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	sweeps            *models.Sweeps
+
+	ox, oy int
+	cx, cy int
+	// rows is the number of sweeps of the last display.
+	rows int
+}
+
+type sweepsColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.SweepsSort
+	display func(*netmodels.PendingSweep, ...color.Option) string
+}
+
+func (c Sweeps) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Sweeps) Name() string {
+	return SWEEPS
+}
+
+func (c *Sweeps) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Sweeps) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Sweeps) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Sweeps) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Sweeps) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Sweeps) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Sweeps) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.sweeps.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Sweeps) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.sweeps.Len()
+	return
+}
+
+func (c *Sweeps) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.sweeps.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Sweeps) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(SWEEPS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(SWEEPS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(SWEEPS_FOOTER)
+}
+
+func (c *Sweeps) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(SWEEPS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(SWEEPS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(SWEEPS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Rewind()
+	blackBg := color.Black(color.Background)
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
+		blackBg("F2"), "Menu",
+		blackBg("b"), "Bump fee",
+		blackBg("F10"), "Quit",
+	))
+	return nil
+}
+
+func (c *Sweeps) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.sweeps.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once a sweep is confirmed.
+	if len(list) < c.rows {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// blocksTo returns the number of blocks until the height, zero if it is
+// reached or unknown.
+func (c *Sweeps) blocksTo(height uint32) int64 {
+	if height == 0 || c.info == nil || c.info.Info == nil {
+		return 0
+	}
+	return int64(height) - int64(c.info.BlockHeight)
+}
+
+func NewSweeps(cfg *config.View, sweeps *models.Sweeps, info *models.Info) *Sweeps {
+	view := &Sweeps{
+		cfg:    cfg,
+		info:   info,
+		sweeps: sweeps,
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	columns := DefaultSweepsColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]sweepsColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "OUTPOINT":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%-68s", columns[i]),
+				width: 68,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.StringSort(s1.Outpoint, s2.Outpoint, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-68s", s.Outpoint))
+				},
+			}
+		case "WITNESS":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%-26s", columns[i]),
+				width: 26,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.StringSort(s1.WitnessType, s2.WitnessType, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					witness := strings.ToLower(s.WitnessType)
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(witness, 26, ""), 26))
+				},
+			}
+		case "AMOUNT":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.Int64Sort(s1.Amount, s2.Amount, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", s.Amount))
+				},
+			}
+		case "FEE_RATE":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt64Sort(s1.SatPerVbyte, s2.SatPerVbyte, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", s.SatPerVbyte))
+				},
+			}
+		case "REQUESTED":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%9s", columns[i]),
+				width: 9,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt64Sort(s1.RequestedSatPerVbyte, s2.RequestedSatPerVbyte, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					if s.RequestedSatPerVbyte == 0 {
+						return color.White(opts...)(fmt.Sprintf("%9s", "-"))
+					}
+					return color.White(opts...)(fmt.Sprintf("%9d", s.RequestedSatPerVbyte))
+				},
+			}
+		case "BUDGET":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt64Sort(s1.Budget, s2.Budget, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", s.Budget))
+				},
+			}
+		case "ATTEMPTS":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt32Sort(s1.BroadcastAttempts, s2.BroadcastAttempts, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", s.BroadcastAttempts))
+				},
+			}
+		case "NEXT":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt32Sort(s1.NextBroadcastHeight, s2.NextBroadcastHeight, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", s.NextBroadcastHeight))
+				},
+			}
+		case "DEADLINE":
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.SweepsSort {
+					return func(s1, s2 *netmodels.PendingSweep) bool {
+						return models.UInt32Sort(s1.DeadlineHeight, s2.DeadlineHeight, order)
+					}
+				},
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					if s.DeadlineHeight == 0 {
+						return color.White(opts...)(fmt.Sprintf("%8s", "-"))
+					}
+					blocks := view.blocksTo(s.DeadlineHeight)
+					text := fmt.Sprintf("%8d", s.DeadlineHeight)
+					switch {
+					case blocks <= 6:
+						return color.Red(opts...)(text)
+					case blocks <= 36:
+						return color.Yellow(opts...)(text)
+					}
+					return color.White(opts...)(text)
+				},
+			}
+		default:
+			view.columns[i] = sweepsColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(s *netmodels.PendingSweep, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+
+	return view
+}
@@ -0,0 +1,483 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	TOWERS         = "towers"
+	TOWERS_COLUMNS = "towers_columns"
+	TOWERS_FOOTER  = "towers_footer"
+)
+
+var DefaultTowersColumns = []string{
+	"PUBKEY",
+	"ADDRESS",
+	"ACTIVE",
+	"SESSIONS",
+	"BACKUPS",
+	"PENDING",
+	"MAX",
+}
+
+// Towers is the view of the towers of the watchtower client, the footer
+// shows the backup counts and the policies of the new sessions.
+type Towers struct {
+	cfg     *config.View
+	printer *message.Printer
+
+	columns           []towersColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	towers            *models.Towers
+	// rows is the number of towers of the last display.
+	rows int
+
+	ox, oy int
+	cx, cy int
+}
+
+type towersColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.TowersSort
+	display func(*netmodels.Tower, ...color.Option) string
+}
+
+func (c Towers) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c Towers) Name() string {
+	return TOWERS
+}
+
+func (c *Towers) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c Towers) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c Towers) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c Towers) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *Towers) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *Towers) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *Towers) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.towers.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *Towers) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.towers.Len()
+	return
+}
+
+func (c *Towers) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.towers.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c Towers) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(TOWERS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(TOWERS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(TOWERS_FOOTER)
+}
+
+func (c *Towers) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(TOWERS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(TOWERS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(TOWERS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	stats := c.towers.Stats()
+	summary := c.printer.Sprintf("%d backups, %d pending, %d failed, %d sessions, %d exhausted",
+		stats.NumBackups, stats.NumPendingBackups, stats.NumFailedBackups,
+		stats.NumSessionsAcquired, stats.NumSessionsExhausted)
+	for _, p := range c.towers.Policies() {
+		summary += c.printer.Sprintf(" | %s %d updates %d sat/vB", p.Type, p.MaxUpdates, p.SweepSatPerVbyte)
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("c"), "Add",
+		blackBg("x"), "Remove",
+		blackBg("F10"), "Quit",
+		summary,
+	))
+	return nil
+}
+
+func (c *Towers) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.towers.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once a tower is removed.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+// towerPolicies returns the policies of the sessions of the tower, e.g.
+// "anchor,legacy".
+func towerPolicies(t *netmodels.Tower) string {
+	var policies []string
+	for _, s := range t.Sessions {
+		if !slices.Contains(policies, s.Policy) {
+			policies = append(policies, s.Policy)
+		}
+	}
+	slices.Sort(policies)
+	return strings.Join(policies, ",")
+}
+
+func NewTowers(cfg *config.View, towers *models.Towers) *Towers {
+	printer := message.NewPrinter(language.English)
+	view := &Towers{
+		cfg:     cfg,
+		printer: printer,
+		towers:  towers,
+	}
+
+	columns := DefaultTowersColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]towersColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "PUBKEY":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%-66s", columns[i]),
+				width: 66,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.StringSort(t1.PubKey, t2.PubKey, order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.Cyan(opts...)(fmt.Sprintf("%-66s", t.PubKey))
+				},
+			}
+		case "ADDRESS":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.StringSort(strings.Join(t1.Addresses, ","), strings.Join(t2.Addresses, ","), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					addresses := strings.Join(t.Addresses, ",")
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(addresses, 30, "…"), 30))
+				},
+			}
+		case "ACTIVE":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%-6s", columns[i]),
+				width: 6,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.BoolSort(t1.Active, t2.Active, order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					if t.Active {
+						return color.Green(opts...)("yes   ")
+					}
+					return color.Yellow(opts...)("no    ")
+				},
+			}
+		case "POLICY":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%-13s", columns[i]),
+				width: 13,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.StringSort(towerPolicies(t1), towerPolicies(t2), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.White(opts...)(runewidth.FillRight(runewidth.Truncate(towerPolicies(t), 13, "…"), 13))
+				},
+			}
+		case "SESSIONS":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.IntSort(len(t1.Sessions), len(t2.Sessions), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%8d", len(t.Sessions)))
+				},
+			}
+		case "BACKUPS":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.UInt32Sort(t1.Backups(), t2.Backups(), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%10d", t.Backups()))
+				},
+			}
+		case "PENDING":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.UInt32Sort(t1.PendingBackups(), t2.PendingBackups(), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					text := printer.Sprintf("%8d", t.PendingBackups())
+					if t.PendingBackups() > 0 {
+						return color.Yellow(opts...)(text)
+					}
+					return color.White(opts...)(text)
+				},
+			}
+		case "MAX":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.UInt32Sort(t1.MaxBackups(), t2.MaxBackups(), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%10d", t.MaxBackups()))
+				},
+			}
+		case "SWEEP_FEE":
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%9s", columns[i]),
+				width: 9,
+				sort: func(order models.Order) models.TowersSort {
+					return func(t1, t2 *netmodels.Tower) bool {
+						return models.UInt32Sort(towerSweepFee(t1), towerSweepFee(t2), order)
+					}
+				},
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%9d", towerSweepFee(t)))
+				},
+			}
+		default:
+			view.columns[i] = towersColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(t *netmodels.Tower, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+	return view
+}
+
+// towerSweepFee returns the highest fee rate in sat/vbyte of the sweeps
+// of the sessions of the tower.
+func towerSweepFee(t *netmodels.Tower) uint32 {
+	var fee uint32
+	for _, s := range t.Sessions {
+		fee = max(fee, s.SweepSatPerVbyte)
+	}
+	return fee
+}
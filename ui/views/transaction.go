@@ -12,14 +12,29 @@ import (
 )
 
 const (
-	TRANSACTION        = "transaction"
-	TRANSACTION_HEADER = "transaction_header"
-	TRANSACTION_FOOTER = "transaction_footer"
+	TRANSACTION = "transaction"
 )
 
+// Transaction is the popup of the detail of the current transaction,
+// above the transactions view.
 type Transaction struct {
 	view         *gocui.View
 	transactions *models.Transactions
+	info         *models.Info
+	visible      bool
+}
+
+func (c *Transaction) Visible() bool {
+	return c.visible
+}
+
+// Show displays the current transaction from the next layout.
+func (c *Transaction) Show() {
+	c.visible = true
+}
+
+func (c *Transaction) Hide() {
+	c.visible = false
 }
 
 func (c Transaction) Name() string {
@@ -62,59 +77,27 @@ func (c *Transaction) SetOrigin(x, y int) error {
 }
 
 func (c *Transaction) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
-	header, err := g.SetView(TRANSACTION_HEADER, x0-1, y0, x1+2, y0+2, 0)
-	if err != nil {
-		if err != gocui.ErrUnknownView {
-			return err
-		}
-	}
-	header.Frame = false
-	header.BgColor = gocui.ColorGreen
-	header.FgColor = gocui.ColorBlack | gocui.AttrBold
-	header.Rewind()
-	fmt.Fprintln(header, "Transaction")
-
-	v, err := g.SetView(TRANSACTION, x0-1, y0+1, x1+2, y1-1, 0)
+	v, err := g.SetView(TRANSACTION, x0, y0, x1, y1, 0)
 	if err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 	}
-	v.Frame = false
+	v.Frame = true
+	v.Title = " Transaction - esc to close "
 	c.view = v
 	c.display()
 
-	footer, err := g.SetView(TRANSACTION_FOOTER, x0-1, y1-2, x1, y1, 0)
-	if err != nil {
-		if err != gocui.ErrUnknownView {
-			return err
-		}
-	}
-	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
-	footer.Rewind()
-	blackBg := color.Black(color.Background)
-	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
-		blackBg("F2"), "Menu",
-		blackBg("Enter"), "Transactions",
-		blackBg("F10"), "Quit",
-	))
-	return nil
+	_, err = g.SetCurrentView(TRANSACTION)
+	return err
 }
 
 func (c Transaction) Delete(g *gocui.Gui) error {
-	err := g.DeleteView(TRANSACTION_HEADER)
-	if err != nil {
+	err := g.DeleteView(TRANSACTION)
+	if err != nil && err != gocui.ErrUnknownView {
 		return err
 	}
-
-	err = g.DeleteView(TRANSACTION)
-	if err != nil {
-		return err
-	}
-
-	return g.DeleteView(TRANSACTION_FOOTER)
+	return nil
 }
 
 func (c *Transaction) display() {
@@ -139,6 +122,10 @@ func (c *Transaction) display() {
 		cyan("       BlockHash:"), transaction.BlockHash))
 	fmt.Fprintln(v, fmt.Sprintf("%s %s",
 		cyan("         TxHash:"), transaction.TxHash))
+	if url := c.info.TxURL(transaction.TxHash); url != "" {
+		fmt.Fprintln(v, fmt.Sprintf("%s %s",
+			cyan("       Explorer:"), url))
+	}
 	fmt.Fprintln(v, "")
 	fmt.Fprintln(v, green("[ addresses ]"))
 	for i := range transaction.DestAddresses {
@@ -148,6 +135,6 @@ func (c *Transaction) display() {
 
 }
 
-func NewTransaction(transactions *models.Transactions) *Transaction {
-	return &Transaction{transactions: transactions}
+func NewTransaction(transactions *models.Transactions, info *models.Info) *Transaction {
+	return &Transaction{transactions: transactions, info: info}
 }
@@ -29,8 +29,25 @@ var DefaultTransactionsColumns = []string{
 	"ADDRESSES",
 }
 
+// AllTransactionsColumns are the columns of the view, in the order of the
+// column chooser.
+var AllTransactionsColumns = []string{
+	"DATE",
+	"HEIGHT",
+	"CONFIR",
+	"AMOUNT",
+	"FEE",
+	"ADDRESSES",
+	"TXHASH",
+	"BLOCKHASH",
+	"FIAT_AMOUNT",
+	"FIAT_FEE",
+}
+
 type Transactions struct {
 	cfg *config.View
+	// names are the columns of the config, columns their display.
+	names []string
 
 	columns           []transactionsColumn
 	columnHeadersView *gocui.View
@@ -191,8 +208,8 @@ func (c *Transactions) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		setCursor = true
 	}
 	c.columnHeadersView.Frame = false
-	c.columnHeadersView.BgColor = gocui.ColorGreen
-	c.columnHeadersView.FgColor = gocui.ColorBlack
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
 
 	c.view, err = g.SetView(TRANSACTIONS, x0-1, y0+1, x1+2, y1-1, 0)
 	if err != nil {
@@ -203,8 +220,8 @@ func (c *Transactions) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 	}
 	c.view.Frame = false
 	c.view.Autoscroll = false
-	c.view.SelBgColor = gocui.ColorCyan
-	c.view.SelFgColor = gocui.ColorBlack | gocui.AttrDim
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
 	c.view.Highlight = true
 	c.display()
 
@@ -229,13 +246,14 @@ func (c *Transactions) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
 		}
 	}
 	footer.Frame = false
-	footer.BgColor = gocui.ColorCyan
-	footer.FgColor = gocui.ColorBlack
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
 	footer.Rewind()
 	blackBg := color.Black(color.Background)
-	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s",
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s",
 		blackBg("F2"), "Menu",
 		blackBg("Enter"), "Transaction",
+		blackBg("v"), "Columns",
 		blackBg("F10"), "Quit",
 	))
 	return nil
@@ -275,7 +293,16 @@ func (c *Transactions) display() {
 	}
 }
 
-func NewTransactions(cfg *config.View, txs *models.Transactions) *Transactions {
+// Columns returns the columns of the view and their widths.
+func (c *Transactions) Columns() ([]string, []int) {
+	widths := make([]int, len(c.columns))
+	for i := range c.columns {
+		widths[i] = c.columns[i].width
+	}
+	return c.names, widths
+}
+
+func NewTransactions(cfg *config.View, txs *models.Transactions, price *models.Price) *Transactions {
 	transactions := &Transactions{
 		cfg:          cfg,
 		transactions: txs,
@@ -378,6 +405,32 @@ func NewTransactions(cfg *config.View, txs *models.Transactions) *Transactions {
 					return color.White(opts...)(fmt.Sprintf("%13s", tx.TxHash))
 				},
 			}
+		case "FIAT_AMOUNT":
+			transactions.columns[i] = transactionsColumn{
+				name:  fmt.Sprintf("%13s", columns[i]),
+				width: 13,
+				sort: func(order models.Order) models.TransactionsSort {
+					return func(tx1, tx2 *netmodels.Transaction) bool {
+						return models.Int64Sort(tx1.Amount, tx2.Amount, order)
+					}
+				},
+				display: func(tx *netmodels.Transaction, opts ...color.Option) string {
+					return color.Green(opts...)(fiat(price, float64(tx.Amount), 13, 2))
+				},
+			}
+		case "FIAT_FEE":
+			transactions.columns[i] = transactionsColumn{
+				name:  fmt.Sprintf("%10s", columns[i]),
+				width: 10,
+				sort: func(order models.Order) models.TransactionsSort {
+					return func(tx1, tx2 *netmodels.Transaction) bool {
+						return models.Int64Sort(tx1.TotalFees, tx2.TotalFees, order)
+					}
+				},
+				display: func(tx *netmodels.Transaction, opts ...color.Option) string {
+					return color.Green(opts...)(fiat(price, float64(tx.TotalFees), 10, 2))
+				},
+			}
 		case "AMOUNT":
 			transactions.columns[i] = transactionsColumn{
 				name:  fmt.Sprintf("%13s", columns[i]),
@@ -402,5 +455,19 @@ func NewTransactions(cfg *config.View, txs *models.Transactions) *Transactions {
 		}
 
 	}
+	transactions.names = columns
+	for i := range transactions.columns {
+		w, ok := columnWidth(cfg, columns[i])
+		if !ok {
+			continue
+		}
+		col := &transactions.columns[i]
+		display, right := col.display, rightAligned(col.name)
+		col.name, col.width = fitCell(col.name, w, right), w
+		col.display = func(item *netmodels.Transaction, opts ...color.Option) string {
+			return fitCell(display(item, opts...), w, right)
+		}
+	}
+
 	return transactions
 }
@@ -0,0 +1,449 @@
+package views
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/edouardparis/lntop/config"
+	netmodels "github.com/edouardparis/lntop/network/models"
+	"github.com/edouardparis/lntop/ui/color"
+	"github.com/edouardparis/lntop/ui/models"
+)
+
+const (
+	UTXOS         = "utxos"
+	UTXOS_COLUMNS = "utxos_columns"
+	UTXOS_FOOTER  = "utxos_footer"
+)
+
+var DefaultUTXOsColumns = []string{
+	"SEL",
+	"OUTPOINT",
+	"TYPE",
+	"AMOUNT",
+	"CONFS",
+	"LEASE",
+	"LABEL",
+}
+
+type UTXOs struct {
+	cfg     *config.View
+	printer *message.Printer
+
+	columns           []utxosColumn
+	columnHeadersView *gocui.View
+	view              *gocui.View
+	utxos             *models.UTXOs
+
+	ox, oy int
+	cx, cy int
+	// rows is the number of UTXOs of the last display.
+	rows int
+}
+
+type utxosColumn struct {
+	name    string
+	width   int
+	sorted  bool
+	sort    func(models.Order) models.UTXOsSort
+	display func(*netmodels.UTXO, ...color.Option) string
+}
+
+func (c UTXOs) Index() int {
+	_, oy := c.view.Origin()
+	_, cy := c.view.Cursor()
+	return cy + oy
+}
+
+func (c UTXOs) Name() string {
+	return UTXOS
+}
+
+func (c *UTXOs) Wrap(v *gocui.View) View {
+	c.view = v
+	return c
+}
+
+func (c UTXOs) currentColumnIndex() int {
+	x := c.ox + c.cx
+	index := 0
+	sum := 0
+	for i := range c.columns {
+		sum += c.columns[i].width + 1
+		if x < sum {
+			return index
+		}
+		index++
+	}
+	return index
+}
+
+func (c UTXOs) Origin() (int, int) {
+	return c.ox, c.oy
+}
+
+func (c UTXOs) Cursor() (int, int) {
+	return c.cx, c.cy
+}
+
+func (c *UTXOs) SetCursor(cx, cy int) error {
+	if err := cursorCompat(c.columnHeadersView, cx, 0); err != nil {
+		return err
+	}
+	err := c.columnHeadersView.SetCursor(cx, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := cursorCompat(c.view, cx, cy); err != nil {
+		return err
+	}
+	err = c.view.SetCursor(cx, cy)
+	if err != nil {
+		return err
+	}
+
+	c.cx, c.cy = cx, cy
+	return nil
+}
+
+func (c *UTXOs) SetOrigin(ox, oy int) error {
+	err := c.columnHeadersView.SetOrigin(ox, 0)
+	if err != nil {
+		return err
+	}
+	err = c.view.SetOrigin(ox, oy)
+	if err != nil {
+		return err
+	}
+
+	c.ox, c.oy = ox, oy
+	return nil
+}
+
+func (c *UTXOs) Speed() (int, int, int, int) {
+	current := c.currentColumnIndex()
+	up := 0
+	down := 0
+	if c.Index() > 0 {
+		up = 1
+	}
+	if c.Index() < c.utxos.Len()-1 {
+		down = 1
+	}
+	if current > len(c.columns)-1 {
+		return 0, c.columns[current-1].width + 1, down, up
+	}
+	if current == 0 {
+		return c.columns[0].width + 1, 0, down, up
+	}
+	return c.columns[current].width + 1,
+		c.columns[current-1].width + 1,
+		down, up
+}
+
+func (c *UTXOs) Limits() (pageSize int, fullSize int) {
+	_, pageSize = c.view.Size()
+	fullSize = c.utxos.Len()
+	return
+}
+
+func (c *UTXOs) Sort(column string, order models.Order) {
+	if column == "" {
+		index := c.currentColumnIndex()
+		if index >= len(c.columns) {
+			return
+		}
+		col := c.columns[index]
+		if col.sort == nil {
+			return
+		}
+
+		c.utxos.Sort(col.sort(order))
+		for i := range c.columns {
+			c.columns[i].sorted = (i == index)
+		}
+	}
+}
+
+func (c UTXOs) Delete(g *gocui.Gui) error {
+	err := g.DeleteView(UTXOS_COLUMNS)
+	if err != nil {
+		return err
+	}
+
+	err = g.DeleteView(UTXOS)
+	if err != nil {
+		return err
+	}
+
+	return g.DeleteView(UTXOS_FOOTER)
+}
+
+func (c *UTXOs) Set(g *gocui.Gui, x0, y0, x1, y1 int) error {
+	var err error
+	setCursor := false
+	c.columnHeadersView, err = g.SetView(UTXOS_COLUMNS, x0-1, y0, x1+2, y0+2, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.columnHeadersView.Frame = false
+	c.columnHeadersView.BgColor = color.Attrs().HeaderBg
+	c.columnHeadersView.FgColor = color.Attrs().HeaderFg
+
+	c.view, err = g.SetView(UTXOS, x0-1, y0+1, x1+2, y1-1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		setCursor = true
+	}
+	c.view.Frame = false
+	c.view.Autoscroll = false
+	c.view.SelBgColor = color.Attrs().SelectionBg
+	c.view.SelFgColor = color.Attrs().SelectionFg
+	c.view.Highlight = true
+	c.display()
+
+	if setCursor {
+		ox, oy := c.Origin()
+		err := c.SetOrigin(ox, oy)
+		if err != nil {
+			return err
+		}
+
+		cx, cy := c.Cursor()
+		err = c.SetCursor(cx, cy)
+		if err != nil {
+			return err
+		}
+	}
+
+	footer, err := g.SetView(UTXOS_FOOTER, x0-1, y1-2, x1+2, y1, 0)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	footer.Frame = false
+	footer.BgColor = color.Attrs().FooterBg
+	footer.FgColor = color.Attrs().FooterFg
+	footer.Clear()
+	blackBg := color.Black(color.Background)
+	var selected int64
+	utxos := c.utxos.Selected()
+	for _, u := range utxos {
+		selected += u.Amount
+	}
+	summary := c.printer.Sprintf("%d selected, %d sat", len(utxos), selected)
+	var leased int64
+	for _, u := range c.utxos.List() {
+		if u.Leased() {
+			leased += u.Amount
+		}
+	}
+	if leased > 0 {
+		summary += c.printer.Sprintf(", %d sat leased", leased)
+	}
+	fmt.Fprintln(footer, fmt.Sprintf("%s%s %s%s %s%s %s%s %s%s %s",
+		blackBg("F2"), "Menu",
+		blackBg("space"), "Select",
+		blackBg("x"), "Consolidate",
+		blackBg("L"), "Label",
+		blackBg("F10"), "Quit",
+		summary,
+	))
+	return nil
+}
+
+func (c *UTXOs) display() {
+	c.columnHeadersView.Rewind()
+	var buffer bytes.Buffer
+	current := c.currentColumnIndex()
+	for i := range c.columns {
+		if current == i {
+			buffer.WriteString(color.Cyan(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		} else if c.columns[i].sorted {
+			buffer.WriteString(color.Magenta(color.Background)(c.columns[i].name))
+			buffer.WriteString(" ")
+			continue
+		}
+		buffer.WriteString(c.columns[i].name)
+		buffer.WriteString(" ")
+	}
+	fmt.Fprintln(c.columnHeadersView, buffer.String())
+
+	list := c.utxos.List()
+	// Rewind does not drop the lines of the previous display, the view
+	// must be cleared once a UTXO is spent.
+	shrank := len(list) < c.rows
+	if shrank {
+		c.view.Clear()
+		c.view.SetOrigin(c.ox, c.oy)
+		c.view.SetCursor(c.cx, c.cy)
+	} else {
+		c.view.Rewind()
+	}
+	c.rows = len(list)
+	for _, item := range list {
+		var buffer bytes.Buffer
+		for i := range c.columns {
+			var opt color.Option
+			if current == i {
+				opt = color.Bold
+			}
+			buffer.WriteString(c.columns[i].display(item, opt))
+			buffer.WriteString(" ")
+		}
+		fmt.Fprintln(c.view, buffer.String())
+	}
+}
+
+func NewUTXOs(cfg *config.View, utxos *models.UTXOs) *UTXOs {
+	printer := message.NewPrinter(language.English)
+	view := &UTXOs{
+		cfg:     cfg,
+		printer: printer,
+		utxos:   utxos,
+	}
+
+	columns := DefaultUTXOsColumns
+	if cfg != nil && len(cfg.Columns) != 0 {
+		columns = cfg.Columns
+	}
+
+	view.columns = make([]utxosColumn, len(columns))
+
+	for i := range columns {
+		switch columns[i] {
+		case "SEL":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-3s", columns[i]),
+				width: 3,
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					if utxos.IsSelected(u.Outpoint) {
+						return color.Green(opts...)("[x]")
+					}
+					return color.White(opts...)("[ ]")
+				},
+			}
+		case "OUTPOINT":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-68s", columns[i]),
+				width: 68,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.StringSort(u1.Outpoint, u2.Outpoint, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-68s", u.Outpoint))
+				},
+			}
+		case "ADDRESS":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-62s", columns[i]),
+				width: 62,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.StringSort(u1.Address, u2.Address, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-62s", u.Address))
+				},
+			}
+		case "TYPE":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-6s", columns[i]),
+				width: 6,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.StringSort(u1.AddressType, u2.AddressType, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return color.White(opts...)(fmt.Sprintf("%-6s", u.AddressType))
+				},
+			}
+		case "AMOUNT":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%12s", columns[i]),
+				width: 12,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.Int64Sort(u1.Amount, u2.Amount, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return color.White(opts...)(printer.Sprintf("%12d", u.Amount))
+				},
+			}
+		case "CONFS":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%8s", columns[i]),
+				width: 8,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.Int64Sort(u1.Confirmations, u2.Confirmations, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					if u.Confirmations == 0 {
+						return color.Yellow(opts...)(fmt.Sprintf("%8s", "unconf"))
+					}
+					return color.White(opts...)(printer.Sprintf("%8d", u.Confirmations))
+				},
+			}
+		case "LEASE":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-18s", columns[i]),
+				width: 18,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.DateSort(&u1.LeaseExpiration, &u2.LeaseExpiration, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					if !u.Leased() {
+						return fmt.Sprintf("%-18s", "")
+					}
+					return color.Yellow(opts...)(fmt.Sprintf("%-18s", u.LeaseExpiration.Format("until Jan _2 15:04")))
+				},
+			}
+		case "LABEL":
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-30s", columns[i]),
+				width: 30,
+				sort: func(order models.Order) models.UTXOsSort {
+					return func(u1, u2 *netmodels.UTXO) bool {
+						return models.StringSort(u1.Label, u2.Label, order)
+					}
+				},
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return color.Cyan(opts...)(runewidth.FillRight(runewidth.Truncate(u.Label, 30, ""), 30))
+				},
+			}
+		default:
+			view.columns[i] = utxosColumn{
+				name:  fmt.Sprintf("%-21s", columns[i]),
+				width: 21,
+				display: func(u *netmodels.UTXO, opts ...color.Option) string {
+					return "column does not exist"
+				},
+			}
+		}
+	}
+
+	return view
+}
@@ -2,6 +2,8 @@ package views
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/pkg/errors"
@@ -23,15 +25,93 @@ type View interface {
 type Views struct {
 	Main View
 
-	Header       *Header
-	Menu         *Menu
-	Summary      *Summary
-	Channels     *Channels
-	Channel      *Channel
-	Transactions *Transactions
-	Transaction  *Transaction
-	Routing      *Routing
-	FwdingHist   *FwdingHist
+	Header         *Header
+	Banner         *Banner
+	Session        *Session
+	Status         *Status
+	Menu           *Menu
+	Summary        *Summary
+	Channels       *Channels
+	Channel        *Channel
+	Pending        *PendingChannels
+	Transactions   *Transactions
+	Transaction    *Transaction
+	Routing        *Routing
+	Failures       *Failures
+	FwdingHist     *FwdingHist
+	Peers          *Peers
+	Closed         *Closed
+	Sweeps         *Sweeps
+	Payments       *Payments
+	Invoices       *Invoices
+	UTXOs          *UTXOs
+	Towers         *Towers
+	Offers         *Offers
+	Offer          *Offer
+	MissionControl *MissionControl
+	Graph          *Graph
+	Node           *Node
+	Firewall       *Firewall
+	Notifications  *Notifications
+	Dashboard      *Dashboard
+	Toast          *Toast
+	Plugins        []*Plugin
+	QRCode         *QRCode
+	LNDConnect     *LNDConnect
+	Decoder        *Decoder
+	Message        *Message
+	BumpFee        *BumpFee
+	Consolidate    *Consolidate
+	Label          *Label
+	Policy         *Policy
+	Note           *Note
+	OpenChannel    *OpenChannel
+	BatchOpen      *BatchOpen
+	CloseChannel   *CloseChannel
+	Rebalance      *Rebalance
+	Pay            *Pay
+	Keysend        *Keysend
+	CreateInvoice  *CreateInvoice
+	CreateOffer    *CreateOffer
+	DisableOffer   *DisableOffer
+	ConnectPeer    *ConnectPeer
+	DisconnectPeer *DisconnectPeer
+	AddTower       *AddTower
+	RemoveTower    *RemoveTower
+	ResolveHTLC    *ResolveHTLC
+	ResetMission   *ResetMission
+	ImportMission  *ImportMission
+	ColumnChooser  *ColumnChooser
+	SortMenu       *SortMenu
+	Search         *Search
+	Lookup         *Lookup
+	Filter         *Filter
+
+	cfg    config.Views
+	models *models.Models
+	// width is the width of the terminal of the last layout, columns
+	// the columns of the views built from a preset for it.
+	width   int
+	columns map[string][]string
+	// split is the line between the summary and the main view.
+	split int
+}
+
+// Split returns the line between the summary and the main view.
+func (v *Views) Split() int {
+	return v.split
+}
+
+// SetSplit moves the line between the summary and the main view to y,
+// the summary may be empty and the main view keeps a few rows.
+func (v *Views) SetSplit(y, maxY int) {
+	if y > maxY-6 {
+		y = maxY - 6
+	}
+	if y < 2 {
+		y = 2
+	}
+	v.split = y
 }
 
 func (v Views) Get(vi *gocui.View) View {
@@ -43,6 +123,10 @@ func (v Views) Get(vi *gocui.View) View {
 		return v.Channels.Wrap(vi)
 	case MENU:
 		return v.Menu.Wrap(vi)
+	case COLUMN_CHOOSER:
+		return v.ColumnChooser.Wrap(vi)
+	case SORT_MENU:
+		return v.SortMenu.Wrap(vi)
 	case CHANNEL:
 		return v.Channel.Wrap(vi)
 	case TRANSACTIONS:
@@ -51,20 +135,148 @@ func (v Views) Get(vi *gocui.View) View {
 		return v.Transaction.Wrap(vi)
 	case ROUTING:
 		return v.Routing.Wrap(vi)
+	case FAILURES:
+		return v.Failures.Wrap(vi)
 	case FWDINGHIST:
 		return v.FwdingHist.Wrap(vi)
+	case PEERS:
+		return v.Peers.Wrap(vi)
+	case CLOSED:
+		return v.Closed.Wrap(vi)
+	case SWEEPS:
+		return v.Sweeps.Wrap(vi)
+	case PAYMENTS:
+		return v.Payments.Wrap(vi)
+	case INVOICES:
+		return v.Invoices.Wrap(vi)
+	case UTXOS:
+		return v.UTXOs.Wrap(vi)
+	case TOWERS:
+		return v.Towers.Wrap(vi)
+	case OFFERS:
+		return v.Offers.Wrap(vi)
+	case OFFER:
+		return v.Offer.Wrap(vi)
+	case PENDING:
+		return v.Pending.Wrap(vi)
+	case MISSIONCONTROL:
+		return v.MissionControl.Wrap(vi)
+	case GRAPH:
+		return v.Graph.Wrap(vi)
+	case NODE:
+		return v.Node.Wrap(vi)
+	case FIREWALL:
+		return v.Firewall.Wrap(vi)
+	case NOTIFICATIONS:
+		return v.Notifications.Wrap(vi)
+	case DASHBOARD:
+		return v.Dashboard.Wrap(vi)
+	default:
+		for i := range v.Plugins {
+			if v.Plugins[i].Name() == vi.Name() {
+				return v.Plugins[i].Wrap(vi)
+			}
+		}
+		return nil
+	}
+}
+
+// ByName returns the view of the menu with the given name.
+func (v Views) ByName(name string) View {
+	switch name {
+	case CHANNELS:
+		return v.Channels
+	case TRANSACTIONS:
+		return v.Transactions
+	case ROUTING:
+		return v.Routing
+	case FAILURES:
+		return v.Failures
+	case FWDINGHIST:
+		return v.FwdingHist
+	case PEERS:
+		return v.Peers
+	case CLOSED:
+		return v.Closed
+	case SWEEPS:
+		return v.Sweeps
+	case PAYMENTS:
+		return v.Payments
+	case INVOICES:
+		return v.Invoices
+	case UTXOS:
+		return v.UTXOs
+	case TOWERS:
+		return v.Towers
+	case OFFERS:
+		return v.Offers
+	case PENDING:
+		return v.Pending
+	case MISSIONCONTROL:
+		return v.MissionControl
+	case GRAPH:
+		return v.Graph
+	case FIREWALL:
+		return v.Firewall
+	case NOTIFICATIONS:
+		return v.Notifications
+	case DASHBOARD:
+		return v.Dashboard
 	default:
+		for i := range v.Plugins {
+			if v.Plugins[i].Name() == name {
+				return v.Plugins[i]
+			}
+		}
 		return nil
 	}
 }
 
 func (v *Views) Layout(g *gocui.Gui, maxX, maxY int) error {
-	err := v.Header.Set(g, 0, -1, maxX, 1)
+	err := v.resize(g, maxX)
 	if err != nil {
 		return err
 	}
 
-	err = v.Summary.Set(g, 0, 1, maxX, 6)
+	err = v.Header.Set(g, 0, -1, maxX, 1)
+	if err != nil {
+		return err
+	}
+
+	// the session bar takes the last line, below the footer of the main
+	// view and the popups.
+	if v.Session.Visible() {
+		err = v.Session.Set(g, 0, maxY-2, maxX, maxY)
+		if err != nil {
+			return err
+		}
+		maxY--
+	}
+
+	err = v.Summary.Set(g, 0, 1, maxX, v.split)
+	if err != nil {
+		return err
+	}
+
+	// the banner takes the first line of the main view.
+	top := v.split
+	if v.Banner.Visible() {
+		top = v.split + 1
+		err = v.Banner.Set(g, 0, v.split-1, maxX, v.split+1)
+	} else {
+		err = v.Banner.Delete(g)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the notifications are above the views and the popups until they
+	// expire.
+	if v.Toast.Visible() {
+		err = v.Toast.Set(g, maxX, maxY)
+	} else {
+		err = v.Toast.Delete(g)
+	}
 	if err != nil {
 		return err
 	}
@@ -72,12 +284,12 @@ func (v *Views) Layout(g *gocui.Gui, maxX, maxY int) error {
 	current := g.CurrentView()
 	if current != nil {
 		if current.Name() == v.Menu.Name() {
-			err = v.Menu.Set(g, 0, 6, 10, maxY)
+			err = v.Menu.Set(g, 0, top, 10, maxY)
 			if err != nil {
 				return err
 			}
 
-			err = v.Main.Set(g, 11, 6, maxX-1, maxY)
+			err = v.Main.Set(g, 11, top, maxX-1, maxY)
 			if err != nil {
 				return err
 			}
@@ -85,11 +297,263 @@ func (v *Views) Layout(g *gocui.Gui, maxX, maxY int) error {
 		}
 	}
 
-	err = v.Main.Set(g, 0, 6, maxX-1, maxY)
+	err = v.Main.Set(g, 0, top, maxX-1, maxY)
 	if err != nil && err != gocui.ErrUnknownView {
 		return err
 	}
 
+	// the data is stale until the node is ready again.
+	if v.Status.Visible() {
+		return v.Status.Set(g, 0, top, maxX-1, maxY)
+	}
+	err = v.Status.Delete(g)
+	if err != nil {
+		return err
+	}
+
+	// the popups are above the main view until they are closed.
+	if v.QRCode.Visible() {
+		return v.QRCode.Set(g, maxX, maxY)
+	}
+	err = v.QRCode.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.LNDConnect.Visible() {
+		return v.LNDConnect.Set(g, maxX, maxY)
+	}
+	err = v.LNDConnect.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Decoder.Visible() {
+		return v.Decoder.Set(g, maxX, maxY)
+	}
+	err = v.Decoder.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Message.Visible() {
+		return v.Message.Set(g, maxX, maxY)
+	}
+	err = v.Message.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.BumpFee.Visible() {
+		return v.BumpFee.Set(g, maxX, maxY)
+	}
+	err = v.BumpFee.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Consolidate.Visible() {
+		return v.Consolidate.Set(g, maxX, maxY)
+	}
+	err = v.Consolidate.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Label.Visible() {
+		return v.Label.Set(g, maxX, maxY)
+	}
+	err = v.Label.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Policy.Visible() {
+		return v.Policy.Set(g, maxX, maxY)
+	}
+	err = v.Policy.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Note.Visible() {
+		return v.Note.Set(g, maxX, maxY)
+	}
+	err = v.Note.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.OpenChannel.Visible() {
+		return v.OpenChannel.Set(g, maxX, maxY)
+	}
+	err = v.OpenChannel.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.BatchOpen.Visible() {
+		return v.BatchOpen.Set(g, maxX, maxY)
+	}
+	err = v.BatchOpen.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.CloseChannel.Visible() {
+		return v.CloseChannel.Set(g, maxX, maxY)
+	}
+	err = v.CloseChannel.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Rebalance.Visible() {
+		return v.Rebalance.Set(g, maxX, maxY)
+	}
+	err = v.Rebalance.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Pay.Visible() {
+		return v.Pay.Set(g, maxX, maxY)
+	}
+	err = v.Pay.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Keysend.Visible() {
+		return v.Keysend.Set(g, maxX, maxY)
+	}
+	err = v.Keysend.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.CreateInvoice.Visible() {
+		return v.CreateInvoice.Set(g, maxX, maxY)
+	}
+	err = v.CreateInvoice.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.CreateOffer.Visible() {
+		return v.CreateOffer.Set(g, maxX, maxY)
+	}
+	err = v.CreateOffer.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.DisableOffer.Visible() {
+		return v.DisableOffer.Set(g, maxX, maxY)
+	}
+	err = v.DisableOffer.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.ConnectPeer.Visible() {
+		return v.ConnectPeer.Set(g, maxX, maxY)
+	}
+	err = v.ConnectPeer.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.DisconnectPeer.Visible() {
+		return v.DisconnectPeer.Set(g, maxX, maxY)
+	}
+	err = v.DisconnectPeer.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.AddTower.Visible() {
+		return v.AddTower.Set(g, maxX, maxY)
+	}
+	err = v.AddTower.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.RemoveTower.Visible() {
+		return v.RemoveTower.Set(g, maxX, maxY)
+	}
+	err = v.RemoveTower.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.ResolveHTLC.Visible() {
+		return v.ResolveHTLC.Set(g, maxX, maxY)
+	}
+	err = v.ResolveHTLC.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.ResetMission.Visible() {
+		return v.ResetMission.Set(g, maxX, maxY)
+	}
+	err = v.ResetMission.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.ImportMission.Visible() {
+		return v.ImportMission.Set(g, maxX, maxY)
+	}
+	err = v.ImportMission.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.ColumnChooser.Visible() {
+		return v.ColumnChooser.Set(g, 0, top+1, maxX-1, maxY-1)
+	}
+	err = v.ColumnChooser.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.SortMenu.Visible() {
+		return v.SortMenu.Set(g, 0, top+1, maxX-1, maxY-1)
+	}
+	err = v.SortMenu.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Search.Visible() {
+		return v.Search.Set(g, 0, maxY-4, maxX-1, maxY-2)
+	}
+	err = v.Search.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Lookup.Visible() {
+		return v.Lookup.Set(g, 0, maxY-4, maxX-1, maxY-2)
+	}
+	err = v.Lookup.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Filter.Visible() {
+		return v.Filter.Set(g, 0, maxY-4, maxX-1, maxY-2)
+	}
+	err = v.Filter.Delete(g)
+	if err != nil {
+		return err
+	}
+
+	// the details are above the main view, inset so the rows around
+	// stay visible.
+	if v.Channel.Visible() {
+		return v.Channel.Set(g, 4, top+1, maxX-5, maxY-1)
+	}
+	err = v.Channel.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Transaction.Visible() {
+		return v.Transaction.Set(g, 4, top+1, maxX-5, maxY-1)
+	}
+	err = v.Transaction.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Node.Visible() {
+		return v.Node.Set(g, 4, top+1, maxX-5, maxY-1)
+	}
+	err = v.Node.Delete(g)
+	if err != nil {
+		return err
+	}
+	if v.Offer.Visible() {
+		return v.Offer.Set(g, 4, top+1, maxX-5, maxY-1)
+	}
+	err = v.Offer.Delete(g)
+	if err != nil {
+		return err
+	}
+
 	_, err = g.SetCurrentView(v.Main.Name())
 	if err != nil {
 		return errors.WithStack(err)
@@ -98,41 +562,431 @@ func (v *Views) Layout(g *gocui.Gui, maxX, maxY int) error {
 	return nil
 }
 
-func New(cfg config.Views, m *models.Models) *Views {
-	main := NewChannels(cfg.Channels, m.Channels)
-	return &Views{
-		Header:       NewHeader(m.Info),
-		Menu:         NewMenu(),
-		Summary:      NewSummary(m.Info, m.ChannelsBalance, m.WalletBalance, m.Channels),
-		Channels:     main,
-		Channel:      NewChannel(m.Channels),
-		Transactions: NewTransactions(cfg.Transactions, m.Transactions),
-		Transaction:  NewTransaction(m.Transactions),
-		Routing:      NewRouting(cfg.Routing, m.RoutingLog, m.Channels),
-		FwdingHist:   NewFwdingHist(cfg.FwdingHist, m.FwdingHist),
-		Main:         main,
+// HideDetails closes the popups of the details.
+func (v *Views) HideDetails() {
+	v.Channel.Hide()
+	v.Transaction.Hide()
+	v.Node.Hide()
+	v.Offer.Hide()
+}
+
+// resize rebuilds the views with column presets whose columns for the
+// width changed, the main view is displayed again by the layout.
+func (v *Views) resize(g *gocui.Gui, width int) error {
+	if width == v.width {
+		return nil
+	}
+	v.width = width
+
+	for _, p := range v.builders() {
+		if p.cfg == nil || len(p.cfg.Presets) == 0 {
+			continue
+		}
+		columns := p.cfg.ColumnsFor(width)
+		if slices.Equal(columns, v.columns[p.name]) {
+			continue
+		}
+		v.columns[p.name] = columns
+
+		main := v.Main.Name() == p.name
+		if main {
+			err := v.Main.Delete(g)
+			if err != nil && err != gocui.ErrUnknownView {
+				return err
+			}
+		}
+		cfg := *p.cfg
+		cfg.Columns = columns
+		view := p.build(&cfg)
+		if main {
+			v.Main = view
+		}
+	}
+	return nil
+}
+
+// viewBuilder builds the view of the name with a config, replacing the
+// one of the views.
+type viewBuilder struct {
+	name  string
+	cfg   *config.View
+	build func(*config.View) View
+}
+
+// builders returns the builders of the views with columns, with their
+// config.
+func (v *Views) builders() []viewBuilder {
+	m := v.models
+	return []viewBuilder{
+		{CHANNELS, v.cfg.Channels, func(cfg *config.View) View {
+			v.Channels = NewChannels(cfg, m.Channels, m.Plugins, m.Price, m.PeerData)
+			return v.Channels
+		}},
+		{PENDING, v.cfg.Pending, func(cfg *config.View) View {
+			v.Pending = NewPendingChannels(cfg, m.PendingChannels)
+			return v.Pending
+		}},
+		{TRANSACTIONS, v.cfg.Transactions, func(cfg *config.View) View {
+			v.Transactions = NewTransactions(cfg, m.Transactions, m.Price)
+			return v.Transactions
+		}},
+		{ROUTING, v.cfg.Routing, func(cfg *config.View) View {
+			v.Routing = NewRouting(cfg, m.RoutingLog, m.Channels, m.RoutingChart)
+			return v.Routing
+		}},
+		{FWDINGHIST, v.cfg.FwdingHist, func(cfg *config.View) View {
+			v.FwdingHist = NewFwdingHist(cfg, m.FwdingHist, m.Price)
+			return v.FwdingHist
+		}},
+		{PEERS, v.cfg.Peers, func(cfg *config.View) View {
+			v.Peers = NewPeers(cfg, m.Peers)
+			return v.Peers
+		}},
+		{CLOSED, v.cfg.Closed, func(cfg *config.View) View {
+			v.Closed = NewClosed(cfg, m.ClosedChannels)
+			return v.Closed
+		}},
+		{SWEEPS, v.cfg.Sweeps, func(cfg *config.View) View {
+			v.Sweeps = NewSweeps(cfg, m.Sweeps, m.Info)
+			return v.Sweeps
+		}},
+		{PAYMENTS, v.cfg.Payments, func(cfg *config.View) View {
+			v.Payments = NewPayments(cfg, m.Payments)
+			return v.Payments
+		}},
+		{INVOICES, v.cfg.Invoices, func(cfg *config.View) View {
+			v.Invoices = NewInvoices(cfg, m.Invoices)
+			return v.Invoices
+		}},
+		{UTXOS, v.cfg.UTXOs, func(cfg *config.View) View {
+			v.UTXOs = NewUTXOs(cfg, m.UTXOs)
+			return v.UTXOs
+		}},
+		{TOWERS, v.cfg.Towers, func(cfg *config.View) View {
+			v.Towers = NewTowers(cfg, m.Towers)
+			return v.Towers
+		}},
+		{OFFERS, v.cfg.Offers, func(cfg *config.View) View {
+			v.Offers = NewOffers(cfg, m.Offers)
+			return v.Offers
+		}},
+		{MISSIONCONTROL, v.cfg.MissionControl, func(cfg *config.View) View {
+			v.MissionControl = NewMissionControl(cfg, m.MissionControl)
+			return v.MissionControl
+		}},
+		{GRAPH, v.cfg.Graph, func(cfg *config.View) View {
+			v.Graph = NewGraph(cfg, m.Graph)
+			return v.Graph
+		}},
+		{FIREWALL, v.cfg.Firewall, func(cfg *config.View) View {
+			v.Firewall = NewFirewall(cfg, m.Firewall)
+			return v.Firewall
+		}},
+	}
+}
+
+// SetConfig rebuilds the views with columns from the config, with their
+// columns, widths and highlights, the models are kept. g is nil if the
+// views are not displayed.
+func (v *Views) SetConfig(g *gocui.Gui, cfg config.Views) error {
+	v.cfg = cfg
+	// the presets are applied again at the next layout.
+	v.width = 0
+	v.columns = make(map[string][]string)
+	for _, b := range v.builders() {
+		main := v.Main.Name() == b.name
+		if main && g != nil {
+			err := v.Main.Delete(g)
+			if err != nil && err != gocui.ErrUnknownView {
+				return err
+			}
+		}
+		view := b.build(b.cfg)
+		if main {
+			v.Main = view
+		}
 	}
+	return nil
+}
+
+// columnsView is a view whose columns are chosen with the column
+// chooser.
+type columnsView interface {
+	View
+	Columns() ([]string, []int)
+}
+
+// newColumnsView builds the view of the name with the columns of cfg,
+// nil if its columns cannot be chosen.
+func (v *Views) newColumnsView(name string, cfg *config.View) columnsView {
+	m := v.models
+	switch name {
+	case CHANNELS:
+		return NewChannels(cfg, m.Channels, m.Plugins, m.Price, m.PeerData)
+	case TRANSACTIONS:
+		return NewTransactions(cfg, m.Transactions, m.Price)
+	case ROUTING:
+		return NewRouting(cfg, m.RoutingLog, m.Channels, m.RoutingChart)
+	}
+	return nil
+}
+
+// viewConfig returns the config of the view of the name, added to the
+// config if it has none.
+func (v *Views) viewConfig(name string) *config.View {
+	cfg := map[string]**config.View{
+		CHANNELS:     &v.cfg.Channels,
+		TRANSACTIONS: &v.cfg.Transactions,
+		ROUTING:      &v.cfg.Routing,
+	}[name]
+	if cfg == nil {
+		return nil
+	}
+	if *cfg == nil {
+		*cfg = &config.View{}
+	}
+	return *cfg
+}
+
+// ShowColumnChooser opens the column chooser of the main view, false if
+// its columns cannot be chosen.
+func (v *Views) ShowColumnChooser() bool {
+	name := v.Main.Name()
+	main, ok := v.Main.(columnsView)
+	if !ok || v.viewConfig(name) == nil {
+		return false
+	}
+	cfg := *v.viewConfig(name)
+	all := map[string][]string{
+		CHANNELS:     AllChannelsColumns,
+		TRANSACTIONS: AllTransactionsColumns,
+		ROUTING:      AllRoutingColumns,
+	}[name]
+	var computed []string
+	for column := range cfg.Computed {
+		computed = append(computed, column)
+	}
+	slices.Sort(computed)
+	all = append(append([]string{}, all...), computed...)
+
+	// the default widths are the ones of a view of every column.
+	cfg.Columns, cfg.Widths = all, nil
+	names, widths := v.newColumnsView(name, &cfg).Columns()
+	defaults := make(map[string]int, len(names))
+	for i := range names {
+		defaults[names[i]] = widths[i]
+	}
+	columns, widths := main.Columns()
+	v.ColumnChooser.Show(name, columns, widths, all, defaults)
+	return true
+}
+
+// ShowSortMenu opens the sort menu of the channels view.
+func (v *Views) ShowSortMenu() {
+	v.SortMenu.Show(v.Channels.SortableColumns(), v.Channels.SortKeys())
+}
+
+// SetSort sorts the channels by the keys and returns them in the format
+// of the config.
+func (v *Views) SetSort(keys []SortKey) []string {
+	v.Channels.SortBy(keys)
+	values := sortKeysConfig(keys)
+	v.viewConfig(CHANNELS).Sort = values
+	return values
 }
 
-func ToScid(id uint64) string {
-	blocknum := id >> 40
-	txnum := (id >> 16) & 0x00FFFFFF
-	outnum := id & 0xFFFF
+// SetColumns rebuilds the view of the name with the columns and the
+// widths, g is nil if the views are not displayed.
+func (v *Views) SetColumns(g *gocui.Gui, name string, columns []string, widths map[string]int) error {
+	cfg := v.viewConfig(name)
+	if cfg == nil {
+		return nil
+	}
+	cfg.Columns = columns
+	cfg.Widths = widths
+	v.columns[name] = columns
 
-	return fmt.Sprintf("%dx%dx%d", blocknum, txnum, outnum)
+	main := v.Main.Name() == name
+	if main && g != nil {
+		err := v.Main.Delete(g)
+		if err != nil && err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	view := v.newColumnsView(name, cfg)
+	switch view := view.(type) {
+	case *Channels:
+		if keys := v.Channels.SortKeys(); len(keys) > 0 {
+			view.SortBy(keys)
+		}
+		v.Channels = view
+	case *Transactions:
+		v.Transactions = view
+	case *Routing:
+		v.Routing = view
+	}
+	if main {
+		v.Main = view
+	}
+	return nil
+}
+
+func New(cfg config.Views, m *models.Models) *Views {
+	main := NewChannels(cfg.Channels, m.Channels, m.Plugins, m.Price, m.PeerData)
+	menu := NewMenu()
+	if m.Firewall.Enabled() {
+		menu.Add("FIREWAL", FIREWALL)
+	}
+	plugins := make([]*Plugin, len(m.Plugins.List()))
+	for i, p := range m.Plugins.List() {
+		plugins[i] = NewPlugin(p.Name(), m.Plugins)
+		menu.Add(plugins[i].MenuLabel(), plugins[i].Name())
+	}
+	v := &Views{
+		Header:         NewHeader(m.Info, m.Alerts, m.Price, m.FeeRates, m.Connection),
+		Banner:         NewBanner(m.Alerts),
+		Session:        NewSession(cfg.Session, m.Session),
+		Status:         NewStatus(m.NodeState),
+		QRCode:         NewQRCode(),
+		LNDConnect:     NewLNDConnect(),
+		Decoder:        NewDecoder(),
+		Message:        NewMessage(),
+		BumpFee:        NewBumpFee(),
+		Consolidate:    NewConsolidate(m.UTXOs),
+		Label:          NewLabel(),
+		Policy:         NewPolicy(),
+		Note:           NewNote(),
+		OpenChannel:    NewOpenChannel(),
+		BatchOpen:      NewBatchOpen(),
+		CloseChannel:   NewCloseChannel(),
+		Rebalance:      NewRebalance(),
+		Pay:            NewPay(),
+		Keysend:        NewKeysend(),
+		CreateInvoice:  NewCreateInvoice(),
+		CreateOffer:    NewCreateOffer(),
+		DisableOffer:   NewDisableOffer(),
+		ConnectPeer:    NewConnectPeer(),
+		DisconnectPeer: NewDisconnectPeer(),
+		AddTower:       NewAddTower(),
+		RemoveTower:    NewRemoveTower(),
+		ResolveHTLC:    NewResolveHTLC(),
+		ResetMission:   NewResetMission(),
+		ImportMission:  NewImportMission(),
+		ColumnChooser:  NewColumnChooser(),
+		SortMenu:       NewSortMenu(),
+		Search:         NewSearch(m.Channels),
+		Lookup:         NewLookup(m.Graph),
+		Filter:         NewFilter(),
+		Menu:           menu,
+		Summary:        NewSummary(m.Info, m.ChannelsBalance, m.WalletBalance, m.Channels, m.ChannelBackup),
+		Channels:       main,
+		Channel:        NewChannel(m.Channels, m.Sweeps, m.Info),
+		Pending:        NewPendingChannels(cfg.Pending, m.PendingChannels),
+		Transactions:   NewTransactions(cfg.Transactions, m.Transactions, m.Price),
+		Transaction:    NewTransaction(m.Transactions, m.Info),
+		Routing:        NewRouting(cfg.Routing, m.RoutingLog, m.Channels, m.RoutingChart),
+		Failures:       NewFailures(m.RoutingFailures, m.Channels),
+		FwdingHist:     NewFwdingHist(cfg.FwdingHist, m.FwdingHist, m.Price),
+		Peers:          NewPeers(cfg.Peers, m.Peers),
+		Closed:         NewClosed(cfg.Closed, m.ClosedChannels),
+		Sweeps:         NewSweeps(cfg.Sweeps, m.Sweeps, m.Info),
+		Payments:       NewPayments(cfg.Payments, m.Payments),
+		Invoices:       NewInvoices(cfg.Invoices, m.Invoices),
+		UTXOs:          NewUTXOs(cfg.UTXOs, m.UTXOs),
+		Towers:         NewTowers(cfg.Towers, m.Towers),
+		Offers:         NewOffers(cfg.Offers, m.Offers),
+		Offer:          NewOffer(m.Offers),
+		MissionControl: NewMissionControl(cfg.MissionControl, m.MissionControl),
+		Graph:          NewGraph(cfg.Graph, m.Graph),
+		Node:           NewNode(m.Graph, m.Info),
+		Firewall:       NewFirewall(cfg.Firewall, m.Firewall),
+		Notifications:  NewNotifications(m.Notifications),
+		Toast:          NewToast(m.Notifications),
+		Dashboard:      NewDashboard(m.Info, m.ChannelsBalance, m.WalletBalance, m.Channels, m.FeeRates, m.Notifications, m.Alerts),
+		Plugins:        plugins,
+		Main:           main,
+		cfg:            cfg,
+		models:         m,
+		columns:        make(map[string][]string),
+		split:          6,
+	}
+	if cfg.Main != "" {
+		if view := v.ByName(cfg.Main); view != nil {
+			v.Main = view
+		}
+	}
+	return v
 }
 
+// FormatAge formats an age in blocks of ten minutes, e.g. 1y2m10d, the
+// hours are shown below a year and the minutes below an hour.
 func FormatAge(age uint32) string {
 	if age < 6 {
-		return fmt.Sprintf("%02dm", age*10)
+		return fmt.Sprintf("%dmin", age*10)
 	} else if age < 144 {
-		return fmt.Sprintf("%02dh", age/6)
+		return fmt.Sprintf("%dh", age/6)
 	} else if age < 4383 {
-		return fmt.Sprintf("%02dd%02dh", age/144, (age%144)/6)
+		return fmt.Sprintf("%dd%dh", age/144, (age%144)/6)
 	} else if age < 52596 {
-		return fmt.Sprintf("%02dm%02dd%02dh", age/4383, (age%4383)/144, (age%144)/6)
+		return fmt.Sprintf("%dm%dd%dh", age/4383, (age%4383)/144, (age%144)/6)
+	}
+	return fmt.Sprintf("%dy%dm%dd", age/52596, (age%52596)/4383, (age%4383)/144)
+}
+
+// FormatLatency returns the round trip in milliseconds, in seconds above
+// ten seconds.
+func FormatLatency(d time.Duration) string {
+	if d < 10*time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// parseLatency returns the duration of a latency option, def if it is
+// not set or invalid.
+func parseLatency(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// latencyColumn returns the display of a round trip colored with the
+// warn and critical options of the column, empty if it is zero.
+func latencyColumn(cfg *config.View, column string) func(time.Duration, ...color.Option) string {
+	warn, critical := 300*time.Millisecond, time.Second
+	if cfg != nil {
+		warn = parseLatency(cfg.Options.GetOption(column, "warn"), warn)
+		critical = parseLatency(cfg.Options.GetOption(column, "critical"), critical)
+	}
+	return func(d time.Duration, opts ...color.Option) string {
+		if d == 0 {
+			return fmt.Sprintf("%8s", "")
+		}
+		result := fmt.Sprintf("%8s", FormatLatency(d))
+		switch {
+		case d >= critical:
+			return color.Red(opts...)(result)
+		case d >= warn:
+			return color.Yellow(opts...)(result)
+		}
+		return color.Green(opts...)(result)
+	}
+}
+
+// FormatBytes returns the size with a binary unit.
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
 	}
-	return fmt.Sprintf("%02dy%02dm%02dd", age/52596, (age%52596)/4383, (age%4383)/144)
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func interp(a, b [3]float64, r float64) (result [3]float64) {